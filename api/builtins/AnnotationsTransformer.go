@@ -6,6 +6,7 @@ package builtins
 import (
 	"sigs.k8s.io/kustomize/api/filters/annotations"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -14,12 +15,30 @@ import (
 type AnnotationsTransformerPlugin struct {
 	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	FieldSpecs  []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// MetadataValidation modify how the annotations are
+	// checked against the rules of Kubernetes.  They're
+	// always checked.
+	MetadataValidation *types.MetadataValidation `json:"metadataValidation,omitempty" yaml:"metadataValidation,omitempty"`
+
+	summaries  *resmap.ChangeSummaries
+	provenance *resmap.ProvenanceTable
+	source     resmap.Source
 }
 
 func (p *AnnotationsTransformerPlugin) Config(
-	_ *resmap.PluginHelpers, c []byte) (err error) {
+	h *resmap.PluginHelpers, c []byte) (err error) {
 	p.Annotations = nil
 	p.FieldSpecs = nil
+	p.MetadataValidation = nil
+	p.summaries = h.ResmapFactory().ChangeSummaries()
+	p.provenance = h.ResmapFactory().ProvenanceTable()
+	if p.provenance != nil {
+		p.source = resmap.Source{
+			Transformer:       "AnnotationsTransformer",
+			KustomizationRoot: h.Loader().Root(),
+		}
+	}
 	return yaml.Unmarshal(c, p)
 }
 
@@ -27,16 +46,27 @@ func (p *AnnotationsTransformerPlugin) Transform(m resmap.ResMap) error {
 	if len(p.Annotations) == 0 {
 		return nil
 	}
-	for _, r := range m.Resources() {
-		err := r.ApplyFilter(annotations.Filter{
-			Annotations: p.Annotations,
-			FsSlice:     p.FieldSpecs,
-		})
-		if err != nil {
-			return err
+	v := p.MetadataValidation
+	if v == nil {
+		v = &types.MetadataValidation{}
+	}
+	apply := func() error {
+		for _, r := range m.Resources() {
+			err := r.ApplyFilter(annotations.Filter{
+				Annotations: p.Annotations,
+				FsSlice:     p.FieldSpecs,
+				Validation:  v,
+			})
+			if err != nil {
+				return err
+			}
 		}
+		return nil
 	}
-	return nil
+	return p.summaries.Summarize("AnnotationsTransformer", m.Resources(),
+		(*resource.Resource).GetAnnotations, func() error {
+			return p.provenance.RecordChanges(m.Resources(), p.source, apply)
+		})
 }
 
 func NewAnnotationsTransformerPlugin() resmap.TransformerPlugin {
@@ -6,10 +6,16 @@ package builtins
 import (
 	"fmt"
 
+	"sigs.k8s.io/kustomize/api/hasher"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 )
 
+// maxRehashes bounds the attempts to find a free name.
+const maxRehashes = 10
+
 type HashTransformerPlugin struct {
 	hasher ifc.KunstructuredHasher
 }
@@ -28,13 +34,49 @@ func (p *HashTransformerPlugin) Transform(m resmap.ResMap) error {
 			if err != nil {
 				return err
 			}
-			res.SetOriginalName(res.GetName(), false)
-			res.SetName(fmt.Sprintf("%s-%s", res.GetName(), h))
+			name, err := freeHashedName(m, res, h)
+			if err != nil {
+				return err
+			}
+			err = m.Rename(res.CurId(), func(r *resource.Resource) error {
+				if err := r.SetOriginalNameE(r.GetName(), false); err != nil {
+					return err
+				}
+				return r.SetNameE(name)
+			})
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// freeHashedName returns the resource's name with the given
+// hash appended.  Should that name already be taken, e.g. by
+// a resource that was given a hashed name by hand, another
+// hash is derived from the first, deterministically, until
+// the name is free.
+func freeHashedName(
+	m resmap.ResMap, res *resource.Resource, h string) (string, error) {
+	for i := 0; i < maxRehashes; i++ {
+		name := fmt.Sprintf("%s-%s", res.GetName(), h)
+		id := resid.NewResIdWithNamespace(
+			res.GetGvk(), name, res.GetNamespace())
+		if len(m.GetMatchingResourcesByCurrentId(id.Equals)) == 0 {
+			return name, nil
+		}
+		var err error
+		h, err = hasher.Encode(hasher.Hash(h))
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf(
+		"no free hashed name for %s after %d attempts",
+		res.CurId(), maxRehashes)
+}
+
 func NewHashTransformerPlugin() resmap.TransformerPlugin {
 	return &HashTransformerPlugin{}
 }
@@ -5,7 +5,9 @@ package builtins
 
 import (
 	"fmt"
+	"strings"
 
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -14,6 +16,9 @@ import (
 
 type PatchStrategicMergeTransformerPlugin struct {
 	loadedPatches []*resource.Resource
+	provenance    *resmap.ProvenanceTable
+	conflicts     *resmap.PatchConflicts
+	sources       map[resid.ResId]resmap.Source
 	Paths         []types.PatchStrategicMerge `json:"paths,omitempty" yaml:"paths,omitempty"`
 	Patches       string                      `json:"patches,omitempty" yaml:"patches,omitempty"`
 }
@@ -27,6 +32,9 @@ func (p *PatchStrategicMergeTransformerPlugin) Config(
 	if len(p.Paths) == 0 && p.Patches == "" {
 		return fmt.Errorf("empty file path and empty patch content")
 	}
+	p.provenance = h.ResmapFactory().ProvenanceTable()
+	p.conflicts = h.ResmapFactory().PatchConflicts()
+	files := make(map[resid.ResId][]string)
 	if len(p.Paths) != 0 {
 		for _, onePath := range p.Paths {
 			// The following oddly attempts to interpret a path string as an
@@ -44,6 +52,9 @@ func (p *PatchStrategicMergeTransformerPlugin) Config(
 			if err != nil {
 				return err
 			}
+			for _, r := range res {
+				files[r.OrgId()] = append(files[r.OrgId()], string(onePath))
+			}
 			p.loadedPatches = append(p.loadedPatches, res...)
 		}
 	}
@@ -65,6 +76,13 @@ func (p *PatchStrategicMergeTransformerPlugin) Config(
 		return err
 	}
 	p.loadedPatches = m.Resources()
+	p.sources = make(map[resid.ResId]resmap.Source)
+	for _, patch := range p.loadedPatches {
+		p.sources[patch.OrgId()] = resmap.Source{
+			PatchFile:         strings.Join(files[patch.OrgId()], ","),
+			KustomizationRoot: h.Loader().Root(),
+		}
+	}
 	return nil
 }
 
@@ -74,8 +92,16 @@ func (p *PatchStrategicMergeTransformerPlugin) Transform(m resmap.ResMap) error
 		if err != nil {
 			return err
 		}
-		if err = m.ApplySmPatch(
-			resource.MakeIdSet([]*resource.Resource{target}), patch); err != nil {
+		targets := []*resource.Resource{target}
+		err = p.conflicts.Check(targets, patch, p.sources[patch.OrgId()])
+		if err != nil {
+			return err
+		}
+		err = p.provenance.RecordChanges(
+			targets, p.sources[patch.OrgId()], func() error {
+				return m.ApplySmPatch(resource.MakeIdSet(targets), patch)
+			})
+		if err != nil {
 			return err
 		}
 	}
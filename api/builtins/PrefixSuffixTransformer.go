@@ -5,10 +5,12 @@ package builtins
 
 import (
 	"errors"
+	"sort"
 
 	"sigs.k8s.io/kustomize/api/filters/prefixsuffix"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -18,6 +20,8 @@ type PrefixSuffixTransformerPlugin struct {
 	Prefix     string        `json:"prefix,omitempty" yaml:"prefix,omitempty"`
 	Suffix     string        `json:"suffix,omitempty" yaml:"suffix,omitempty"`
 	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	summaries *resmap.ChangeSummaries
 }
 
 // A Gvk skip list for prefix/suffix modification.
@@ -29,10 +33,11 @@ var prefixSuffixFieldSpecsToSkip = types.FsSlice{
 }
 
 func (p *PrefixSuffixTransformerPlugin) Config(
-	_ *resmap.PluginHelpers, c []byte) (err error) {
+	h *resmap.PluginHelpers, c []byte) (err error) {
 	p.Prefix = ""
 	p.Suffix = ""
 	p.FieldSpecs = nil
+	p.summaries = h.ResmapFactory().ChangeSummaries()
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return
@@ -47,40 +52,65 @@ func (p *PrefixSuffixTransformerPlugin) Transform(m resmap.ResMap) error {
 	// Even if both the Prefix and Suffix are empty we want
 	// to proceed with the transformation. This allows to add contextual
 	// information to the resources (AddNamePrefix and AddNameSuffix).
-	for _, r := range m.Resources() {
+	return p.summaries.Summarize("PrefixSuffixTransformer", m.Resources(),
+		nameKey, func() error { return p.rename(m) })
+}
+
+func (p *PrefixSuffixTransformerPlugin) rename(m resmap.ResMap) error {
+	resources := m.Resources()
+	// Rename resources with longer names first.  As every name
+	// grows by the same amount, a new name then never collides
+	// with the old name of a resource yet to be renamed.
+	sort.SliceStable(resources, func(i, j int) bool {
+		return len(resources[i].GetName()) > len(resources[j].GetName())
+	})
+	for _, r := range resources {
 		// TODO: move this test into the filter (i.e. make a better filter)
 		if p.shouldSkip(r.OrgId()) {
 			continue
 		}
-		id := r.OrgId()
-		// current default configuration contains
-		// only one entry: "metadata/name" with no GVK
-		for _, fs := range p.FieldSpecs {
-			// TODO: this is redundant to filter (but needed for now)
-			if !id.IsSelected(&fs.Gvk) {
-				continue
-			}
-			// TODO: move this test into the filter.
-			if smellsLikeANameChange(&fs) {
-				// "metadata/name" is the only field.
-				// this will add a prefix and a suffix
-				// to the resource even if those are
-				// empty
+		if err := m.Rename(r.CurId(), p.transform); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-				r.AddNamePrefix(p.Prefix)
-				r.AddNameSuffix(p.Suffix)
-				if p.Prefix != "" || p.Suffix != "" {
-					r.SetOriginalName(r.GetName(), false)
-				}
+func (p *PrefixSuffixTransformerPlugin) transform(r *resource.Resource) error {
+	id := r.OrgId()
+	// current default configuration contains
+	// only one entry: "metadata/name" with no GVK
+	for _, fs := range p.FieldSpecs {
+		// TODO: this is redundant to filter (but needed for now)
+		if !id.IsSelected(&fs.Gvk) {
+			continue
+		}
+		// TODO: move this test into the filter.
+		if smellsLikeANameChange(&fs) {
+			// "metadata/name" is the only field.
+			// this will add a prefix and a suffix
+			// to the resource even if those are
+			// empty
+
+			if err := r.AddNamePrefix(p.Prefix); err != nil {
+				return err
 			}
-			err := r.ApplyFilter(prefixsuffix.Filter{
-				Prefix:    p.Prefix,
-				Suffix:    p.Suffix,
-				FieldSpec: fs,
-			})
-			if err != nil {
+			if err := r.AddNameSuffix(p.Suffix); err != nil {
 				return err
 			}
+			if p.Prefix != "" || p.Suffix != "" {
+				if err := r.SetOriginalNameE(r.GetName(), false); err != nil {
+					return err
+				}
+			}
+		}
+		err := r.ApplyFilter(prefixsuffix.Filter{
+			Prefix:    p.Prefix,
+			Suffix:    p.Suffix,
+			FieldSpec: fs,
+		})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -99,6 +129,11 @@ func (p *PrefixSuffixTransformerPlugin) shouldSkip(id resid.ResId) bool {
 	return false
 }
 
+// nameKey maps "name" to the name of the resource.
+func nameKey(r *resource.Resource) map[string]string {
+	return map[string]string{"name": r.GetName()}
+}
+
 func NewPrefixSuffixTransformerPlugin() resmap.TransformerPlugin {
 	return &PrefixSuffixTransformerPlugin{}
 }
@@ -19,6 +19,11 @@ type Filter struct {
 
 	// FsSlice contains the FieldSpecs to locate the namespace field
 	FsSlice types.FsSlice
+
+	// Validation modify how the annotations set are
+	// checked against the rules of Kubernetes.  If nil,
+	// they aren't checked.
+	Validation *types.MetadataValidation
 }
 
 var _ kio.Filter = Filter{}
@@ -38,7 +43,10 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 					return nil, err
 				}
 			}
-			return node, nil
+			if f.Validation == nil {
+				return node, nil
+			}
+			return node, filtersutil.CheckAnnotations(node, f.Annotations, f.Validation)
 		})).Filter(nodes)
 	return nodes, err
 }
@@ -52,6 +52,36 @@ metadata:
 				"bean":   "cannellini",
 			}},
 		},
+		"update keeps styles": {
+			input: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: instance
+  annotations:
+    note: |
+      first line
+      second line
+    hero: 'batman'
+    fiend: riddler
+`,
+			expectedOutput: `
+apiVersion: example.com/v1
+kind: Foo
+metadata:
+  name: instance
+  annotations:
+    note: |
+      new line
+    hero: 'superman'
+    fiend: "on"
+`,
+			filter: Filter{Annotations: annoMap{
+				"note":  "new line\n",
+				"hero":  "superman",
+				"fiend": "on",
+			}},
+		},
 		"update": {
 			input: `
 apiVersion: example.com/v1
@@ -0,0 +1,125 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apiversionupgrade
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter applies a Migration, setting the apiVersion
+// and kind of each object to those of Migration.To.
+// It doesn't check the objects' current Gvk.
+type Filter struct {
+	Migration Migration `json:"migration,omitempty" yaml:"migration,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	if f.Migration.Unsupported {
+		return nil, fmt.Errorf(
+			"unsupported migration of %s from %s to %s",
+			f.Migration.From.Kind, f.Migration.From.ApiVersion(),
+			f.Migration.To.ApiVersion())
+	}
+	for _, op := range f.Migration.Ops {
+		err := visit(node, pathSlice(op.At), func(n *yaml.RNode) error {
+			return apply(n, op)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := node.PipeE(yaml.SetField(
+		yaml.APIVersionField, yaml.NewScalarRNode(f.Migration.To.ApiVersion())))
+	if err != nil {
+		return nil, err
+	}
+	err = node.PipeE(yaml.SetField(
+		yaml.KindField, yaml.NewScalarRNode(f.Migration.To.Kind)))
+	return node, err
+}
+
+// visit calls fn on the nodes at path, descending into
+// every element of the sequences along the way.
+func visit(node *yaml.RNode, path []string, fn func(*yaml.RNode) error) error {
+	if node.YNode().Kind == yaml.SequenceNode {
+		elements, err := node.Elements()
+		if err != nil {
+			return err
+		}
+		for _, e := range elements {
+			if err = visit(e, path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(path) == 0 {
+		return fn(node)
+	}
+	if node.YNode().Kind != yaml.MappingNode {
+		return nil
+	}
+	child, err := node.Pipe(yaml.Lookup(path[0]))
+	if err != nil || child == nil {
+		return err
+	}
+	return visit(child, path[1:], fn)
+}
+
+func apply(node *yaml.RNode, op Op) error {
+	path := pathSlice(op.Path)
+	switch op.Op {
+	case OpMove:
+		from := pathSlice(op.From)
+		value, err := node.Pipe(yaml.Lookup(from...))
+		if err != nil || value == nil {
+			return err
+		}
+		if op.Tag != "" && value.YNode().ShortTag() != op.Tag {
+			return nil
+		}
+		if err = set(node, path, value); err != nil {
+			return err
+		}
+		parent, err := node.Pipe(yaml.Lookup(from[:len(from)-1]...))
+		if err != nil {
+			return err
+		}
+		return parent.PipeE(yaml.Clear(from[len(from)-1]))
+	case OpAddIfMissing:
+		value, err := node.Pipe(yaml.Lookup(path...))
+		if err != nil || value != nil {
+			return err
+		}
+		return set(node, path, yaml.NewScalarRNode(op.Value))
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+func set(node *yaml.RNode, path []string, value *yaml.RNode) error {
+	parent, err := node.Pipe(
+		yaml.LookupCreate(yaml.MappingNode, path[:len(path)-1]...))
+	if err != nil {
+		return err
+	}
+	return parent.PipeE(yaml.SetField(path[len(path)-1], value))
+}
+
+func pathSlice(p string) []string {
+	if p == "" {
+		return nil
+	}
+	return types.FieldSpec{Path: p}.PathSlice()
+}
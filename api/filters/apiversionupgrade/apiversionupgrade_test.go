@@ -0,0 +1,105 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apiversionupgrade
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+func TestFilterIngress(t *testing.T) {
+	m, found := Find(DefaultMigrations, resid.Gvk{
+		Group: "extensions", Version: "v1beta1", Kind: "Ingress"})
+	if !assert.True(t, found) {
+		t.FailNow()
+	}
+	input := `
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  backend:
+    serviceName: default
+    servicePort: 80
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: web
+          servicePort: http
+      - path: /api
+        pathType: Prefix
+        backend:
+          serviceName: api
+          servicePort: 8080
+`
+	expected := `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          service:
+            name: web
+            port:
+              name: http
+        pathType: ImplementationSpecific
+      - path: /api
+        pathType: Prefix
+        backend:
+          service:
+            name: api
+            port:
+              number: 8080
+  defaultBackend:
+    service:
+      name: default
+      port:
+        number: 80
+`
+	assert.Equal(t,
+		strings.TrimSpace(expected),
+		strings.TrimSpace(filtertest_test.RunFilter(t, input, Filter{Migration: m})))
+}
+
+func TestFilterVersionOnly(t *testing.T) {
+	m, found := Find(DefaultMigrations, resid.Gvk{
+		Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"})
+	if !assert.True(t, found) {
+		t.FailNow()
+	}
+	assert.Equal(t, `apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: pdb
+spec:
+  minAvailable: 1
+`, filtertest_test.RunFilter(t, `
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: pdb
+spec:
+  minAvailable: 1
+`, Filter{Migration: m}))
+}
+
+func TestFindNothing(t *testing.T) {
+	_, found := Find(DefaultMigrations, resid.Gvk{
+		Group: "apps", Version: "v1", Kind: "Deployment"})
+	assert.False(t, found)
+}
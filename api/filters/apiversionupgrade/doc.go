@@ -0,0 +1,8 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package apiversionupgrade contains a kio.Filter implementation of the
+// kustomize ApiVersionUpgradeTransformer.  The filter rewrites objects
+// using deprecated API versions to their replacements, moving the
+// fields whose layout changed between the two versions.
+package apiversionupgrade
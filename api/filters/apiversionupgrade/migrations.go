@@ -0,0 +1,134 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apiversionupgrade
+
+import "sigs.k8s.io/kustomize/api/resid"
+
+// Migration upgrades objects of a deprecated Gvk.
+type Migration struct {
+	From resid.Gvk
+	To   resid.Gvk
+
+	// Ops rewrite the fields whose layout differs
+	// between the two versions, in order.
+	Ops []Op
+
+	// Unsupported is true if the two versions differ too
+	// much to be migrated automatically.
+	Unsupported bool
+}
+
+// Op names.
+const (
+	// OpMove moves the field at From to Path.
+	OpMove = "move"
+
+	// OpAddIfMissing sets the field at Path to
+	// the scalar Value, unless it's already set.
+	OpAddIfMissing = "addIfMissing"
+)
+
+// Op is a field rewrite, loosely modeled on a JSON 6902 operation.
+// All paths are slash-separated, as in a FieldSpec.
+type Op struct {
+	// Op is OpMove or OpAddIfMissing.
+	Op string
+
+	// At locates the nodes to apply the op to, descending
+	// into sequences along the way; empty means the object.
+	At string
+
+	// From is the field to move, relative to At.
+	From string
+
+	// Path is the field to write, relative to At.
+	Path string
+
+	// Value is the value to add.
+	Value string
+
+	// Tag, if set, restricts a move to fields
+	// with the given YAML tag, e.g. "!!int".
+	Tag string
+}
+
+// Find returns the migration in the list whose From matches gvk.
+func Find(migrations []Migration, gvk resid.Gvk) (Migration, bool) {
+	for _, m := range migrations {
+		if m.From.Equals(gvk) {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// DefaultMigrations covers the APIs removed in kubernetes 1.16,
+// 1.22 and 1.25 that have a replacement.
+var DefaultMigrations = func() []Migration {
+	var result []Migration
+	add := func(kind, from, to string, ops ...Op) {
+		result = append(result, Migration{
+			From: gvk(from, kind), To: gvk(to, kind), Ops: ops})
+	}
+	unsupported := func(kind, from, to string) {
+		result = append(result, Migration{
+			From: gvk(from, kind), To: gvk(to, kind), Unsupported: true})
+	}
+	for _, from := range []string{
+		"extensions/v1beta1", "apps/v1beta1", "apps/v1beta2"} {
+		add("Deployment", from, "apps/v1")
+	}
+	for _, from := range []string{"extensions/v1beta1", "apps/v1beta2"} {
+		add("DaemonSet", from, "apps/v1")
+		add("ReplicaSet", from, "apps/v1")
+	}
+	for _, from := range []string{"apps/v1beta1", "apps/v1beta2"} {
+		add("StatefulSet", from, "apps/v1")
+	}
+	add("NetworkPolicy", "extensions/v1beta1", "networking.k8s.io/v1")
+	for _, from := range []string{
+		"extensions/v1beta1", "networking.k8s.io/v1beta1"} {
+		add("Ingress", from, "networking.k8s.io/v1", ingressOps...)
+	}
+	add("IngressClass", "networking.k8s.io/v1beta1", "networking.k8s.io/v1")
+	add("PodDisruptionBudget", "policy/v1beta1", "policy/v1")
+	add("CronJob", "batch/v1beta1", "batch/v1")
+	add("HorizontalPodAutoscaler", "autoscaling/v2beta2", "autoscaling/v2")
+	unsupported("HorizontalPodAutoscaler", "autoscaling/v2beta1", "autoscaling/v2")
+	for _, kind := range []string{
+		"Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding"} {
+		add(kind, "rbac.authorization.k8s.io/v1beta1", "rbac.authorization.k8s.io/v1")
+	}
+	unsupported("CustomResourceDefinition",
+		"apiextensions.k8s.io/v1beta1", "apiextensions.k8s.io/v1")
+	for _, kind := range []string{
+		"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"} {
+		unsupported(kind,
+			"admissionregistration.k8s.io/v1beta1", "admissionregistration.k8s.io/v1")
+	}
+	return result
+}()
+
+// ingressOps convert the v1beta1 Ingress backend format,
+// which names a service by serviceName and servicePort,
+// to the v1 format.
+var ingressOps = func() []Op {
+	ops := []Op{{Op: OpMove, From: "spec/backend", Path: "spec/defaultBackend"}}
+	for _, at := range []string{
+		"spec/defaultBackend", "spec/rules/http/paths/backend"} {
+		ops = append(ops,
+			Op{Op: OpMove, At: at, From: "serviceName", Path: "service/name"},
+			Op{Op: OpMove, At: at, From: "servicePort", Path: "service/port/number", Tag: "!!int"},
+			Op{Op: OpMove, At: at, From: "servicePort", Path: "service/port/name"},
+		)
+	}
+	return append(ops, Op{
+		Op: OpAddIfMissing, At: "spec/rules/http/paths",
+		Path: "pathType", Value: "ImplementationSpecific"})
+}()
+
+func gvk(apiVersion, kind string) resid.Gvk {
+	g, v := resid.ParseGroupVersion(apiVersion)
+	return resid.Gvk{Group: g, Version: v, Kind: kind}
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package checksum
+
+import (
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/hasher"
+	"sigs.k8s.io/kustomize/api/konfig/builtinpluginconsts"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// DefaultAnnotationName is the annotation written when
+// no other name is configured.
+const DefaultAnnotationName = "kustomize.config.k8s.io/checksum"
+
+// ReferableKinds are the kinds whose content feeds the checksum.
+var ReferableKinds = []string{"ConfigMap", "Secret"}
+
+// DefaultFsSlice locates the pod template annotations
+// of the workload kinds known to kustomize.
+var DefaultFsSlice = types.FsSlice{
+	{Gvk: resid.Gvk{Kind: "Pod"}, Path: "metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "Deployment"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "StatefulSet"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "DaemonSet"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "ReplicaSet"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "ReplicationController"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "Job"}, Path: "spec/template/metadata/annotations", CreateIfNotPresent: true},
+	{Gvk: resid.Gvk{Kind: "CronJob"}, Path: "spec/jobTemplate/spec/template/metadata/annotations", CreateIfNotPresent: true},
+}
+
+// DefaultReferenceFsSlices returns, for each of the ReferableKinds,
+// the fieldspecs of the builtin nameReference configuration that may
+// hold the name of an object of that kind.
+func DefaultReferenceFsSlices() (map[string]types.FsSlice, error) {
+	var c struct {
+		NameReference []struct {
+			resid.Gvk  `json:",inline,omitempty" yaml:",inline,omitempty"`
+			FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+		} `json:"nameReference,omitempty" yaml:"nameReference,omitempty"`
+	}
+	err := k8syaml.Unmarshal([]byte(
+		builtinpluginconsts.GetDefaultFieldSpecsAsMap()["namereference"]), &c)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]types.FsSlice)
+	for _, nbr := range c.NameReference {
+		for _, k := range ReferableKinds {
+			if nbr.Kind == k {
+				result[k] = append(result[k], nbr.FieldSpecs...)
+			}
+		}
+	}
+	return result, nil
+}
+
+// RefKey returns the key under which the hash of a
+// referable object is expected in Filter.Hashes.
+func RefKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// Filter sets the checksum annotation on workloads that
+// refer to at least one object with a known hash.
+type Filter struct {
+	// AnnotationName is the annotation key to set.
+	AnnotationName string `json:"annotationName,omitempty" yaml:"annotationName,omitempty"`
+
+	// Hashes maps RefKey(kind, name) to the content hash of
+	// the referable objects visible to the filtered nodes.
+	// References to objects absent from the map are ignored.
+	Hashes map[string]string
+
+	// References maps a referable kind to the fieldspecs
+	// that may hold a name of an object of that kind.
+	References map[string]types.FsSlice
+
+	// FsSlice locates the annotations to update.
+	FsSlice types.FsSlice
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	sum, err := f.checksum(node)
+	if err != nil || sum == "" {
+		return node, err
+	}
+	name := f.AnnotationName
+	if name == "" {
+		name = DefaultAnnotationName
+	}
+	err = node.PipeE(fsslice.Filter{
+		FsSlice:    f.FsSlice,
+		SetValue:   filtersutil.SetEntry(name, sum, yaml.NodeTagString),
+		CreateKind: yaml.MappingNode,
+		CreateTag:  yaml.NodeTagMap,
+	})
+	return node, err
+}
+
+// checksum returns the digest of the hashes of all objects
+// referred to by the node, or the empty string if the node
+// refers to none of them.
+func (f Filter) checksum(node *yaml.RNode) (string, error) {
+	seen := make(map[string]bool)
+	var entries []string
+	for _, kind := range sortedKinds(f.References) {
+		err := node.PipeE(fsslice.Filter{
+			FsSlice: f.References[kind],
+			SetValue: func(n *yaml.RNode) error {
+				key := RefKey(kind, n.YNode().Value)
+				if h, ok := f.Hashes[key]; ok && !seen[key] {
+					seen[key] = true
+					entries = append(entries, key+"="+h)
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	sort.Strings(entries)
+	return hasher.Hash(strings.Join(entries, "\n")), nil
+}
+
+func sortedKinds(m map[string]types.FsSlice) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
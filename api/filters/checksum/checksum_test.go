@@ -0,0 +1,104 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package checksum
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/hasher"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        envFrom:
+        - configMapRef:
+            name: env
+      volumes:
+      - name: tls
+        secret:
+          secretName: tls
+`
+
+func TestDefaultReferenceFsSlices(t *testing.T) {
+	refs, err := DefaultReferenceFsSlices()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, len(refs))
+	assert.NotEmpty(t, refs["ConfigMap"])
+	assert.NotEmpty(t, refs["Secret"])
+}
+
+func TestFilter(t *testing.T) {
+	refs, err := DefaultReferenceFsSlices()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	testCases := map[string]struct {
+		input    string
+		hashes   map[string]string
+		expected string
+	}{
+		"both": {
+			input: deployment,
+			hashes: map[string]string{
+				"ConfigMap/env": "aaa",
+				"Secret/tls":    "bbb",
+				"Secret/other":  "ccc",
+			},
+			expected: hasher.Hash("ConfigMap/env=aaa\nSecret/tls=bbb"),
+		},
+		"secretOnly": {
+			input: deployment,
+			hashes: map[string]string{
+				"Secret/tls": "bbb",
+			},
+			expected: hasher.Hash("Secret/tls=bbb"),
+		},
+		"kindMatters": {
+			input: deployment,
+			hashes: map[string]string{
+				"Secret/env": "aaa",
+			},
+		},
+		"noReferences": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+			hashes: map[string]string{"ConfigMap/env": "aaa"},
+		},
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			out := filtertest_test.RunFilter(t, tc.input, Filter{
+				AnnotationName: "checksum/config",
+				Hashes:         tc.hashes,
+				References:     refs,
+				FsSlice:        DefaultFsSlice,
+			})
+			if tc.expected == "" {
+				assert.False(t, strings.Contains(out, "checksum/config"), out)
+				return
+			}
+			assert.Contains(t, out, `
+    metadata:
+      annotations:
+        checksum/config: `+tc.expected+"\n")
+		})
+	}
+}
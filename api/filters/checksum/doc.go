@@ -0,0 +1,8 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package checksum contains a kio.Filter implementation of the kustomize
+// ChecksumTransformer.  The filter writes an annotation holding a digest
+// of the ConfigMaps and Secrets a workload refers to, so that a change
+// in their content changes the pod template and triggers a rollout.
+package checksum
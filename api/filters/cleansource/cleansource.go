@@ -0,0 +1,115 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cleansource
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// CleanProfile names a set of server-populated fields to remove.
+type CleanProfile string
+
+const (
+	// ProfileMinimal removes the object metadata that the
+	// server sets on every object and that breaks patches.
+	ProfileMinimal CleanProfile = "minimal"
+
+	// ProfileExport removes all the fields that the server
+	// populates, including status.
+	ProfileExport CleanProfile = "export"
+)
+
+// StatusPath is the path of the status field.
+const StatusPath = "status"
+
+var minimalPaths = []string{
+	"metadata/managedFields",
+	"metadata/uid",
+	"metadata/resourceVersion",
+	"metadata/creationTimestamp",
+	"metadata/generation",
+	"metadata/selfLink",
+}
+
+var exportPaths = append(append([]string{}, minimalPaths...),
+	"metadata/deletionTimestamp",
+	"metadata/deletionGracePeriodSeconds",
+	`metadata/annotations/kubectl.kubernetes.io\/last-applied-configuration`,
+	`metadata/annotations/deployment.kubernetes.io\/revision`,
+	StatusPath,
+)
+
+// Paths returns the paths of the fields the profile removes.
+// The paths are slash-separated, as in a FieldSpec.
+func (p CleanProfile) Paths() ([]string, error) {
+	switch p {
+	case ProfileMinimal:
+		return append([]string{}, minimalPaths...), nil
+	case ProfileExport:
+		return append([]string{}, exportPaths...), nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown clean profile %q; expected %q or %q",
+			p, ProfileExport, ProfileMinimal)
+	}
+}
+
+// CleanForSource removes the fields of the given profile
+// from the resource.
+func CleanForSource(r *resource.Resource, profile CleanProfile) error {
+	paths, err := profile.Paths()
+	if err != nil {
+		return err
+	}
+	return r.ApplyFilter(Filter{Paths: paths})
+}
+
+// Filter removes the fields at the given paths.
+// Mappings emptied by a removal, e.g. metadata.annotations
+// after removing its only annotation, are removed too.
+type Filter struct {
+	// Paths are slash-separated, as in a FieldSpec.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	for _, p := range f.Paths {
+		path := types.FieldSpec{Path: p}.PathSlice()
+		if err := clearField(node, path); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// clearField removes the field at path, then any mapping
+// along the path left empty by the removal.
+func clearField(node *yaml.RNode, path []string) error {
+	for i := len(path); i > 0; i-- {
+		parent, err := node.Pipe(yaml.Lookup(path[:i-1]...))
+		if err != nil || parent == nil {
+			return err
+		}
+		field, err := parent.Pipe(yaml.Clear(path[i-1]))
+		if err != nil || field == nil {
+			return err
+		}
+		if i == 1 || len(parent.Content()) > 0 {
+			return nil
+		}
+		// The parent is now empty; remove it in turn.
+	}
+	return nil
+}
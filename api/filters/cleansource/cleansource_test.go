@@ -0,0 +1,118 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cleansource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/provider"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+const liveDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1"}
+  creationTimestamp: "2021-03-01T10:00:00Z"
+  generation: 3
+  managedFields:
+  - manager: kubectl
+    operation: Update
+  name: web
+  resourceVersion: "12345"
+  uid: 0b4e3f2a-1c1d-4e5f-8a9b-0c1d2e3f4a5b
+spec:
+  replicas: 2
+status:
+  replicas: 2
+`
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		profile  CleanProfile
+		expected string
+	}{
+		"minimal": {
+			profile: ProfileMinimal,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1"}
+  name: web
+spec:
+  replicas: 2
+status:
+  replicas: 2
+`,
+		},
+		"export": {
+			profile: ProfileExport,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			paths, err := tc.profile.Paths()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(filtertest_test.RunFilter(
+					t, liveDeployment, Filter{Paths: paths})))
+		})
+	}
+}
+
+func TestFilterKeepsUnrelatedEmptyMaps(t *testing.T) {
+	input := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  labels: {}
+data: {}
+`
+	assert.Equal(t, strings.TrimSpace(input), strings.TrimSpace(
+		filtertest_test.RunFilter(t, input, Filter{Paths: []string{
+			"metadata/labels/missing", "status"}})))
+}
+
+func TestCleanForSource(t *testing.T) {
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	r, err := rf.FromBytes([]byte(liveDeployment))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, CleanForSource(r, ProfileExport))
+	y, err := r.AsYAML()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`, string(y))
+
+	assert.EqualError(t, CleanForSource(r, "everything"),
+		`unknown clean profile "everything"; expected "export" or "minimal"`)
+}
@@ -0,0 +1,8 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cleansource contains a kio.Filter implementation of the kustomize
+// CleanForSourceTransformer.  The filter removes fields populated by the
+// API server, e.g. metadata.managedFields and status, from objects
+// exported from a live cluster, so they can be kept as source.
+package cleansource
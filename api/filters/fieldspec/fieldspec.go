@@ -143,25 +143,7 @@ func isMatchGVK(fs types.FieldSpec, obj *yaml.RNode) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if fs.Kind != "" && fs.Kind != meta.Kind {
-		// kind doesn't match
-		return false, err
-	}
-
-	// parse the group and version from the apiVersion field
-	group, version := parseGV(meta.APIVersion)
-
-	if fs.Group != "" && fs.Group != group {
-		// group doesn't match
-		return false, nil
-	}
-
-	if fs.Version != "" && fs.Version != version {
-		// version doesn't match
-		return false, nil
-	}
-
-	return true, nil
+	return GetGVK(meta).IsSelected(&fs.Gvk), nil
 }
 
 func splitPath(path string) []string {
@@ -49,6 +49,30 @@ a:
 		},
 	},
 
+	{
+		name: "update-core-group",
+		fieldSpec: `
+path: a/b
+group: core
+kind: Bar
+`,
+		input: `
+apiVersion: v1
+kind: Bar
+a:
+  b: c
+`,
+		expected: `
+apiVersion: v1
+kind: Bar
+a:
+  b: e
+`,
+		filter: fieldspec.Filter{
+			SetValue: filtersutil.SetScalar("e"),
+		},
+	},
+
 	{
 		name: "update-kind-not-match",
 		fieldSpec: `
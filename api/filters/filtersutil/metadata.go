@@ -0,0 +1,220 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filtersutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// The limits of the Kubernetes API server on labels and
+// annotations.
+const (
+	// MaxNameLength bounds the name of a label or annotation
+	// key, and the value of a label.
+	MaxNameLength = 63
+
+	// MaxPrefixLength bounds the prefix of a label or
+	// annotation key, a DNS subdomain.
+	MaxPrefixLength = 253
+
+	// MaxAnnotationsSize bounds the total size of the keys
+	// and values of the annotations of a resource.
+	MaxAnnotationsSize = 256 * 1024
+)
+
+var (
+	nameRegexp = regexp.MustCompile(
+		`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+	prefixRegexp = regexp.MustCompile(
+		`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// CheckLabels checks the labels set in a resource, as
+// ValidateLabel does, unless their keys are ignored.
+// In strict mode all the labels of the resource are
+// checked.  The error names the resource and the label.
+func CheckLabels(
+	node *yaml.RNode, set map[string]string,
+	v *types.MetadataValidation) error {
+	labels, err := metadataToCheck(node, set, v, yaml.LabelsField)
+	if err != nil {
+		return err
+	}
+	for _, k := range yaml.SortedMapKeys(labels) {
+		if err := ValidateLabel(k, labels[k]); err != nil {
+			return fmt.Errorf("label %q of %s: %w", k, describe(node), err)
+		}
+	}
+	return nil
+}
+
+// CheckAnnotations checks the annotations set in a
+// resource, as ValidateAnnotationKey does, unless their
+// keys are ignored, and that the annotations of the
+// resource aren't too big altogether.  In strict mode
+// the keys of all the annotations of the resource are
+// checked.  The error names the resource and the
+// annotation.
+func CheckAnnotations(
+	node *yaml.RNode, set map[string]string,
+	v *types.MetadataValidation) error {
+	annotations, err := metadataToCheck(
+		node, set, v, yaml.AnnotationsField)
+	if err != nil {
+		return err
+	}
+	for _, k := range yaml.SortedMapKeys(annotations) {
+		if err := ValidateAnnotationKey(k); err != nil {
+			return fmt.Errorf(
+				"annotation %q of %s: %w", k, describe(node), err)
+		}
+	}
+	m, err := getMeta(node)
+	if err != nil {
+		return err
+	}
+	size := 0
+	for k, value := range m.Annotations {
+		if !ignored(k, v) {
+			size += len(k) + len(value)
+		}
+	}
+	if size > MaxAnnotationsSize {
+		return fmt.Errorf(
+			"annotations of %s: total size is %d bytes, "+
+				"over the limit of %d", describe(node), size,
+			MaxAnnotationsSize)
+	}
+	return nil
+}
+
+// ValidateLabel returns an error if the label key isn't
+// a qualified name, or its value isn't empty or a name
+// of at most MaxNameLength characters.
+func ValidateLabel(key, value string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if len(value) > MaxNameLength {
+		return fmt.Errorf(
+			"value is %d characters, over the limit of %d",
+			len(value), MaxNameLength)
+	}
+	if value != "" && !nameRegexp.MatchString(value) {
+		return fmt.Errorf("value %q %s", value, nameRule)
+	}
+	return nil
+}
+
+// ValidateAnnotationKey returns an error if the
+// annotation key isn't a qualified name.
+func ValidateAnnotationKey(key string) error {
+	return validateKey(key)
+}
+
+const nameRule = "must begin and end with an alphanumeric character, " +
+	"with dashes, underscores, dots and alphanumerics between"
+
+// validateKey returns an error if the key isn't a
+// qualified name, i.e. a name of at most MaxNameLength
+// characters, maybe after a DNS subdomain prefix and a
+// slash, e.g. app.kubernetes.io/name.
+func validateKey(key string) error {
+	name := key
+	if i := strings.Index(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		switch {
+		case prefix == "":
+			return fmt.Errorf("key prefix must not be empty")
+		case len(prefix) > MaxPrefixLength:
+			return fmt.Errorf(
+				"key prefix is %d characters, over the limit of %d",
+				len(prefix), MaxPrefixLength)
+		case !prefixRegexp.MatchString(prefix):
+			return fmt.Errorf(
+				"key prefix %q must be a lowercase DNS subdomain", prefix)
+		}
+	}
+	switch {
+	case name == "":
+		return fmt.Errorf("key name must not be empty")
+	case len(name) > MaxNameLength:
+		return fmt.Errorf(
+			"key name is %d characters, over the limit of %d",
+			len(name), MaxNameLength)
+	case !nameRegexp.MatchString(name):
+		return fmt.Errorf("key name %q %s", name, nameRule)
+	}
+	return nil
+}
+
+// metadataToCheck returns the entries of the given
+// metadata field that aren't ignored, either those set
+// or, in strict mode, all of them.
+func metadataToCheck(
+	node *yaml.RNode, set map[string]string,
+	v *types.MetadataValidation, field string) (map[string]string, error) {
+	result := make(map[string]string)
+	add := func(entries map[string]string) {
+		for k, value := range entries {
+			if !ignored(k, v) {
+				result[k] = value
+			}
+		}
+	}
+	if v != nil && v.Strict {
+		m, err := getMeta(node)
+		if err != nil {
+			return nil, err
+		}
+		if field == yaml.AnnotationsField {
+			add(m.Annotations)
+		} else {
+			add(m.Labels)
+		}
+	}
+	add(set)
+	return result, nil
+}
+
+// getMeta returns the metadata of the node, which may
+// be missing, e.g. in a node that isn't a resource.
+func getMeta(node *yaml.RNode) (yaml.ResourceMeta, error) {
+	m, err := node.GetMeta()
+	if err == yaml.ErrMissingMetadata {
+		return m, nil
+	}
+	return m, err
+}
+
+func ignored(key string, v *types.MetadataValidation) bool {
+	if v == nil {
+		return false
+	}
+	for _, k := range v.IgnoreKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// describe names the resource in an error.
+func describe(node *yaml.RNode) string {
+	m, err := getMeta(node)
+	if err != nil || m.Kind == "" {
+		return "resource"
+	}
+	name := m.Name
+	if m.Namespace != "" {
+		name = m.Namespace + "/" + name
+	}
+	return m.Kind + " " + name
+}
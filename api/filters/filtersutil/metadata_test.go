@@ -0,0 +1,198 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filtersutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestValidateLabel(t *testing.T) {
+	testCases := map[string]struct {
+		key   string
+		value string
+		err   string
+	}{
+		"valid": {
+			key:   "app",
+			value: "web-1.0_a",
+		},
+		"empty value": {
+			key: "app",
+		},
+		"prefixed key": {
+			key:   "app.kubernetes.io/name",
+			value: "web",
+		},
+		"long value": {
+			key:   "app",
+			value: strings.Repeat("a", 64),
+			err:   "value is 64 characters, over the limit of 63",
+		},
+		"longest value": {
+			key:   "app",
+			value: strings.Repeat("a", 63),
+		},
+		"value with a space": {
+			key:   "app",
+			value: "my app",
+			err:   `value "my app" must begin and end with an alphanumeric character`,
+		},
+		"value ending with a dash": {
+			key:   "app",
+			value: "web-",
+			err:   `value "web-" must begin and end`,
+		},
+		"long key name": {
+			key: "example.com/" + strings.Repeat("a", 64),
+			err: "key name is 64 characters, over the limit of 63",
+		},
+		"long key prefix": {
+			key: strings.Repeat("a", 254) + "/app",
+			err: "key prefix is 254 characters, over the limit of 253",
+		},
+		"uppercase key prefix": {
+			key: "Example.com/app",
+			err: `key prefix "Example.com" must be a lowercase DNS subdomain`,
+		},
+		"empty key prefix": {
+			key: "/app",
+			err: "key prefix must not be empty",
+		},
+		"empty key name": {
+			key: "example.com/",
+			err: "key name must not be empty",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := filtersutil.ValidateLabel(tc.key, tc.value)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    team: a b
+  annotations:
+    Bad/Key: x
+`
+
+func TestCheckLabels(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	testCases := map[string]struct {
+		set map[string]string
+		v   types.MetadataValidation
+		err string
+	}{
+		"only the labels set": {
+			set: map[string]string{"app": "web"},
+		},
+		"long value": {
+			set: map[string]string{"app": long},
+			err: `label "app" of Deployment prod/web: ` +
+				"value is 70 characters, over the limit of 63",
+		},
+		"ignored": {
+			set: map[string]string{"app": long},
+			v:   types.MetadataValidation{IgnoreKeys: []string{"app"}},
+		},
+		"strict": {
+			set: map[string]string{"app": "web"},
+			v:   types.MetadataValidation{Strict: true},
+			err: `label "team" of Deployment prod/web: value "a b" must begin`,
+		},
+		"strict and ignored": {
+			set: map[string]string{"app": "web"},
+			v: types.MetadataValidation{
+				Strict: true, IgnoreKeys: []string{"team"}},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := filtersutil.CheckLabels(
+				yaml.MustParse(deployment), tc.set, &tc.v)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	testCases := map[string]struct {
+		set map[string]string
+		v   types.MetadataValidation
+		err string
+	}{
+		"only the annotations set": {
+			set: map[string]string{"example.com/note": "a b"},
+		},
+		"bad key": {
+			set: map[string]string{"note!": "a"},
+			err: `annotation "note!" of Deployment prod/web: key name "note!"`,
+		},
+		"strict": {
+			v:   types.MetadataValidation{Strict: true},
+			err: `annotation "Bad/Key" of Deployment prod/web: key prefix "Bad"`,
+		},
+		"strict and ignored": {
+			v: types.MetadataValidation{
+				Strict: true, IgnoreKeys: []string{"Bad/Key"}},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := filtersutil.CheckAnnotations(
+				yaml.MustParse(deployment), tc.set, &tc.v)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestCheckAnnotationsTotalSize(t *testing.T) {
+	rn := yaml.MustParse(deployment)
+	big := strings.Repeat("a", filtersutil.MaxAnnotationsSize)
+	if !assert.NoError(t, rn.PipeE(
+		yaml.SetAnnotation("example.com/big", big))) {
+		t.FailNow()
+	}
+	err := filtersutil.CheckAnnotations(
+		rn, nil, &types.MetadataValidation{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"annotations of Deployment prod/web: total size is 262167 bytes, "+
+				"over the limit of 262144")
+	}
+	assert.NoError(t, filtersutil.CheckAnnotations(
+		rn, nil, &types.MetadataValidation{
+			IgnoreKeys: []string{"example.com/big"}}))
+}
@@ -7,14 +7,24 @@ import (
 // SetFn is a function that accepts an RNode to possibly modify.
 type SetFn func(*yaml.RNode) error
 
-// SetScalar returns a SetFn to set a scalar value
+// SetScalar returns a SetFn to set a scalar value.
+// The scalar keeps its style, e.g. quoted or a literal
+// block, unless the value needs another style to keep
+// its type; a string stays a string, e.g. gaining quotes
+// if it's set to 1.0.
 func SetScalar(value string) SetFn {
 	return func(node *yaml.RNode) error {
-		return node.PipeE(yaml.FieldSetter{StringValue: value})
+		v := yaml.NewScalarRNode(value)
+		if node.YNode().Tag == yaml.NodeTagString {
+			v.YNode().Tag = yaml.NodeTagString
+		}
+		return node.PipeE(yaml.FieldSetter{Value: v})
 	}
 }
 
-// SetEntry returns a SetFn to set an entry in a map
+// SetEntry returns a SetFn to set an entry in a map.
+// An existing entry keeps the style of its value, as
+// with SetScalar.
 func SetEntry(key, value, tag string) SetFn {
 	n := &yaml.Node{
 		Kind:  yaml.ScalarNode,
@@ -0,0 +1,58 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filtersutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestSetScalar(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		value    string
+		expected string
+	}{
+		"plain": {
+			input:    "a: b",
+			value:    "c",
+			expected: "a: c",
+		},
+		"quoted": {
+			input:    `a: "b"`,
+			value:    "c",
+			expected: `a: "c"`,
+		},
+		"literal": {
+			input:    "a: |\n  b\n",
+			value:    "c\nd\n",
+			expected: "a: |\n  c\n  d",
+		},
+		"string stays a string": {
+			input:    "a: b",
+			value:    "1.0",
+			expected: `a: "1.0"`,
+		},
+		"int stays an int": {
+			input:    "a: 1",
+			value:    "2",
+			expected: "a: 2",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rn := yaml.MustParse(tc.input)
+			field, err := rn.Pipe(yaml.Lookup("a"))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.NoError(t, filtersutil.SetScalar(tc.value)(field))
+			assert.Equal(t, tc.expected, strings.TrimSpace(rn.MustString()))
+		})
+	}
+}
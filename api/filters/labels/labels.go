@@ -20,6 +20,11 @@ type Filter struct {
 
 	// FsSlice identifies the label fields.
 	FsSlice types.FsSlice
+
+	// Validation modify how the labels set are checked
+	// against the rules of Kubernetes.  If nil, they
+	// aren't checked.
+	Validation *types.MetadataValidation
 }
 
 var _ kio.Filter = Filter{}
@@ -39,7 +44,10 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 					return nil, err
 				}
 			}
-			return node, nil
+			if f.Validation == nil {
+				return node, nil
+			}
+			return node, filtersutil.CheckLabels(node, f.Labels, f.Validation)
 		})).Filter(nodes)
 	return nodes, err
 }
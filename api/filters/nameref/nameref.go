@@ -1,16 +1,15 @@
 package nameref
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
-	kyaml_filtersutil "sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -30,6 +29,17 @@ type Filter struct {
 
 	// Set of resources to hunt through to find the ReferralTarget.
 	ReferralCandidates resmap.ResMap
+
+	// ExternalNames are the ids of resources outside the
+	// build, references to which are never updated, even if
+	// a resource in the build had the name originally.  An
+	// empty Gvk field or namespace matches any.
+	ExternalNames []resid.ResId
+
+	// optional holds the name nodes of the references that
+	// are marked optional, e.g. a configMapKeyRef with
+	// optional: true; see optionalNames.
+	optional map[*yaml.Node]bool
 }
 
 // At time of writing, in practice this is called with a slice with only
@@ -43,13 +53,52 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 // However, this filter still needs the extra methods on Referrer
 // to consult things like the resource Id, its namespace, etc.
 func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
-	err := node.PipeE(fieldspec.Filter{
+	optional, err := f.optionalNames(node)
+	if err != nil {
+		return nil, err
+	}
+	f.optional = optional
+	err = node.PipeE(fieldspec.Filter{
 		FieldSpec: f.NameFieldToUpdate,
 		SetValue:  f.set,
 	})
 	return node, err
 }
 
+// optionalNames returns the name nodes, at the path of
+// NameFieldToUpdate, in maps that mark the reference
+// optional.  An optional reference may well be to a
+// resource outside the build, so it's updated only if
+// it's to one resource in the build, unambiguously.
+func (f Filter) optionalNames(node *yaml.RNode) (map[*yaml.Node]bool, error) {
+	fs := f.NameFieldToUpdate
+	i := strings.LastIndex(fs.Path, "/")
+	if i < 0 {
+		return nil, nil
+	}
+	nameField := fs.Path[i+1:]
+	fs.Path = fs.Path[:i]
+	fs.CreateIfNotPresent = false
+	result := make(map[*yaml.Node]bool)
+	err := node.PipeE(fieldspec.Filter{
+		FieldSpec: fs,
+		SetValue: func(ref *yaml.RNode) error {
+			if ref.YNode().Kind != yaml.MappingNode {
+				return nil
+			}
+			optional := ref.Field("optional")
+			if optional == nil || optional.Value.YNode().Value != "true" {
+				return nil
+			}
+			if name := ref.Field(nameField); name != nil {
+				result[name.Value.YNode()] = true
+			}
+			return nil
+		},
+	})
+	return result, err
+}
+
 func (f Filter) set(node *yaml.RNode) error {
 	if yaml.IsMissingOrNull(node) {
 		return nil
@@ -75,6 +124,9 @@ func (f Filter) setMapping(node *yaml.RNode) error {
 	if node.YNode().Kind != yaml.MappingNode {
 		return fmt.Errorf("expect a mapping node")
 	}
+	if f.NameFieldToUpdate.NameField != "" {
+		return f.setNameField(node)
+	}
 	nameNode, err := node.Pipe(yaml.FieldMatcher{Name: "name"})
 	if err != nil || nameNode == nil {
 		return fmt.Errorf("cannot find field 'name' in node")
@@ -99,7 +151,7 @@ func (f Filter) setMapping(node *yaml.RNode) error {
 	}
 
 	oldName := nameNode.YNode().Value
-	res, err := f.selectReferral(oldName, subset)
+	res, err := f.selectReferral(oldName, subset, nil, false)
 	if err != nil || res == nil {
 		// Nil res means nothing to do.
 		return err
@@ -128,64 +180,100 @@ func (f Filter) setMapping(node *yaml.RNode) error {
 	return err
 }
 
-func (f Filter) setScalar(node *yaml.RNode) error {
+// setNameField replaces the name, in the field named by
+// NameFieldToUpdate.NameField, within a map RNode whose own
+// fields, per the field spec, say what type it refers to.
+// Nothing else in the map is changed.
+func (f Filter) setNameField(node *yaml.RNode) error {
+	fs := f.NameFieldToUpdate
+	nameNode, err := node.Pipe(yaml.FieldMatcher{Name: fs.NameField})
+	if err != nil || nameNode == nil {
+		return fmt.Errorf("cannot find field '%s' in node", fs.NameField)
+	}
+	refGvk, ok, err := f.referredGvk(node)
+	if err != nil || !ok {
+		return err
+	}
+	oldName := nameNode.YNode().Value
 	res, err := f.selectReferral(
-		node.YNode().Value, f.ReferralCandidates.Resources())
+		oldName, f.ReferralCandidates.Resources(), refGvk, false)
 	if err != nil || res == nil {
 		// Nil res means nothing to do.
 		return err
 	}
 	f.recordTheReferral(res)
-	if res.GetName() == node.YNode().Value {
+	if res.GetName() == oldName {
 		// The name has not changed, nothing to do.
 		return nil
 	}
-	return node.PipeE(yaml.FieldSetter{StringValue: res.GetName()})
+	return filtersutil.SetScalar(res.GetName())(nameNode)
 }
 
-// In the resource, make a note that it is referred to by the referrer.
-func (f Filter) recordTheReferral(res *resource.Resource) {
-	res.AppendRefBy(f.Referrer.CurId())
-}
-
-func (f Filter) isRoleRef() bool {
-	return strings.HasSuffix(f.NameFieldToUpdate.Path, "roleRef/name")
-}
-
-// getRoleRefGvk returns a Gvk in the roleRef field. Return error
-// if the roleRef, roleRef/apiGroup or roleRef/kind is missing.
-func getRoleRefGvk(res json.Marshaler) (*resid.Gvk, error) {
-	n, err := kyaml_filtersutil.GetRNode(res)
-	if err != nil {
-		return nil, err
-	}
-	roleRef, err := n.Pipe(yaml.Lookup("roleRef"))
-	if err != nil {
-		return nil, err
+// referredGvk reads the type a map RNode refers to from the
+// map's fields named in the field spec.  It returns false if
+// one of those fields is missing, as the reference can't then
+// be checked.  An empty apiGroup, or an apiVersion without
+// one, stands for the core group.
+func (f Filter) referredGvk(node *yaml.RNode) (*resid.Gvk, bool, error) {
+	fs := f.NameFieldToUpdate
+	var gvk resid.Gvk
+	field := func(name string) (string, bool, error) {
+		if name == "" {
+			return "", true, nil
+		}
+		n, err := node.Pipe(yaml.FieldMatcher{Name: name})
+		if err != nil || n == nil {
+			return "", false, err
+		}
+		return n.YNode().Value, true, nil
 	}
-	if roleRef.IsNil() {
-		return nil, fmt.Errorf("roleRef cannot be found in %s", n.MustString())
+	kind, ok, err := field(fs.KindField)
+	if err != nil || !ok {
+		return nil, false, err
 	}
-	apiGroup, err := roleRef.Pipe(yaml.Lookup("apiGroup"))
-	if err != nil {
-		return nil, err
+	gvk.Kind = kind
+	if fs.ApiVersionField != "" {
+		apiVersion, ok, err := field(fs.ApiVersionField)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		gvk.Group, gvk.Version = resid.ParseGroupVersion(apiVersion)
+		if gvk.Group == "" {
+			gvk.Group = resid.LegacyCoreGroup
+		}
 	}
-	if apiGroup.IsNil() {
-		return nil, fmt.Errorf(
-			"apiGroup cannot be found in roleRef %s", roleRef.MustString())
+	if fs.ApiGroupField != "" {
+		group, ok, err := field(fs.ApiGroupField)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		if group == "" {
+			group = resid.LegacyCoreGroup
+		}
+		gvk.Group = group
 	}
-	kind, err := roleRef.Pipe(yaml.Lookup("kind"))
-	if err != nil {
-		return nil, err
+	return &gvk, true, nil
+}
+
+func (f Filter) setScalar(node *yaml.RNode) error {
+	res, err := f.selectReferral(
+		node.YNode().Value, f.ReferralCandidates.Resources(), nil,
+		f.optional[node.YNode()])
+	if err != nil || res == nil {
+		// Nil res means nothing to do.
+		return err
 	}
-	if kind.IsNil() {
-		return nil, fmt.Errorf(
-			"kind cannot be found in roleRef %s", roleRef.MustString())
+	f.recordTheReferral(res)
+	if res.GetName() == node.YNode().Value {
+		// The name has not changed, nothing to do.
+		return nil
 	}
-	return &resid.Gvk{
-		Group: apiGroup.YNode().Value,
-		Kind:  kind.YNode().Value,
-	}, nil
+	return filtersutil.SetScalar(res.GetName())(node)
+}
+
+// In the resource, make a note that it is referred to by the referrer.
+func (f Filter) recordTheReferral(res *resource.Resource) {
+	res.AppendRefBy(f.Referrer.GetCurIdFast())
 }
 
 func (f Filter) filterReferralCandidates(
@@ -211,16 +299,17 @@ func (f Filter) filterReferralCandidates(
 // identical to the ReferralCandidates resmap. Still in some cases, such
 // as ClusterRoleBinding, the subset only contains the resources of a specific
 // namespace.
+// If refGvk isn't nil, the referral must also be selected by it,
+// e.g. it's the type given in a roleRef or an ownerReferences item.
+// If optional, a name that more than one candidate had originally
+// isn't updated, rather than updated to the nearest of them.
+// Names of ExternalNames are never updated.
 func (f Filter) selectReferral(
 	oldName string,
-	candidateSubset []*resource.Resource) (*resource.Resource, error) {
-	var roleRefGvk *resid.Gvk
-	if f.isRoleRef() {
-		var err error
-		roleRefGvk, err = getRoleRefGvk(f.Referrer)
-		if err != nil {
-			return nil, err
-		}
+	candidateSubset []*resource.Resource,
+	refGvk *resid.Gvk, optional bool) (*resource.Resource, error) {
+	if f.isExternal(oldName) {
+		return nil, nil
 	}
 	for _, res := range candidateSubset {
 		if res.GetOriginalName() != oldName {
@@ -230,15 +319,16 @@ func (f Filter) selectReferral(
 		if !id.IsSelected(&f.ReferralTarget) {
 			continue
 		}
-		// If the we are processing a roleRef, the apiGroup and Kind in the
-		// roleRef are needed to be considered.
-		if f.isRoleRef() && !id.IsSelected(roleRefGvk) {
+		if refGvk != nil && !id.IsSelected(refGvk) {
 			continue
 		}
 		matches := f.ReferralCandidates.GetMatchingResourcesByOriginalId(id.Equals)
 		// If there's more than one match,
 		// filter the matches by prefix and suffix
 		if len(matches) > 1 {
+			if optional {
+				return nil, nil
+			}
 			filteredMatches := f.filterReferralCandidates(matches)
 			if len(filteredMatches) > 1 {
 				return nil, fmt.Errorf(
@@ -252,7 +342,7 @@ func (f Filter) selectReferral(
 		}
 		// In the resource, note that it is referenced
 		// by the referrer.
-		res.AppendRefBy(f.Referrer.CurId())
+		res.AppendRefBy(f.Referrer.GetCurIdFast())
 		// Return transformed name of the object,
 		// complete with prefixes, hashes, etc.
 		return res, nil
@@ -260,10 +350,25 @@ func (f Filter) selectReferral(
 	return nil, nil
 }
 
+// isExternal returns true if the name is that of one of
+// ExternalNames of the ReferralTarget's type, in the
+// Referrer's namespace.
+func (f Filter) isExternal(name string) bool {
+	for _, id := range f.ExternalNames {
+		if id.Name != name || !f.ReferralTarget.IsSelected(&id.Gvk) {
+			continue
+		}
+		if id.Namespace == "" || id.Namespace == f.Referrer.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
+
 func getIds(rs []*resource.Resource) []string {
 	var result []string
 	for _, r := range rs {
-		result = append(result, r.CurId().String()+"\n")
+		result = append(result, r.GetCurIdFast().String()+"\n")
 	}
 	return result
 }
@@ -136,6 +136,69 @@ map:
 				},
 			},
 		},
+		"list of maps with sibling fields": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Secret
+    name: oldName
+    uid: 1234
+  - apiVersion: apps/v1
+    kind: NotSecret
+    name: oldName
+  - apiVersion: apps/v2
+    kind: Secret
+    name: oldName
+  - name: oldName
+`,
+			candidates: `
+apiVersion: apps/v1
+kind: Secret
+metadata:
+  name: newName
+---
+apiVersion: apps/v1
+kind: NotSecret
+metadata:
+  name: newName2
+`,
+			originalNames: []string{"oldName", ""},
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Secret
+    name: newName
+    uid: 1234
+  - apiVersion: apps/v1
+    kind: NotSecret
+    name: oldName
+  - apiVersion: apps/v2
+    kind: Secret
+    name: oldName
+  - name: oldName
+`,
+			filter: Filter{
+				NameFieldToUpdate: types.FieldSpec{
+					Path:            "metadata/ownerReferences",
+					NameField:       "name",
+					KindField:       "kind",
+					ApiVersionField: "apiVersion",
+				},
+				ReferralTarget: resid.Gvk{
+					Group:   "apps",
+					Version: "v1",
+					Kind:    "Secret",
+				},
+			},
+		},
 		"mapping with namespace": {
 			input: `
 apiVersion: apps/v1
@@ -393,6 +456,51 @@ metadata:
   name: dep
 ref:
   name: newName
+`,
+			filter: Filter{
+				NameFieldToUpdate: types.FieldSpec{Path: "ref/name"},
+				ReferralTarget: resid.Gvk{
+					Group:   "apps",
+					Version: "v1",
+					Kind:    "Secret",
+				},
+			},
+			err: false,
+		},
+		"optional ref, prefix match": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+ref:
+  name: oldName
+  optional: true
+`,
+			candidates: `
+apiVersion: apps/v1
+kind: Secret
+metadata:
+  name: newName
+---
+apiVersion: apps/v1
+kind: Secret
+metadata:
+  name: newName2
+`,
+			originalNames: []string{"oldName", "oldName"},
+			prefix:        []string{"prefix1", "prefix2"},
+			suffix:        []string{"", "suffix2"},
+			inputPrefix:   "prefix1",
+			inputSuffix:   "",
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+ref:
+  name: oldName
+  optional: true
 `,
 			filter: Filter{
 				NameFieldToUpdate: types.FieldSpec{Path: "ref/name"},
@@ -9,6 +9,7 @@ import (
 	"sigs.k8s.io/kustomize/api/filters/fsslice"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -18,6 +19,10 @@ type Filter struct {
 
 	// FsSlice contains the FieldSpecs to locate the namespace field
 	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Schemas, if set, are the OpenAPI definitions telling
+	// which kinds are namespaced, rather than the global ones.
+	Schemas *openapi.Schemas `json:"-" yaml:"-"`
 }
 
 var _ kio.Filter = Filter{}
@@ -76,7 +81,7 @@ func (ns Filter) hacks(obj *yaml.RNode) error {
 // or through inlined OpenAPI on the resource as a YAML comment.
 func (ns Filter) metaNamespaceHack(obj *yaml.RNode, meta yaml.ResourceMeta) error {
 	gvk := fieldspec.GetGVK(meta)
-	if !gvk.IsNamespaceableKind() {
+	if !gvk.IsNamespaceableKindIn(ns.Schemas) {
 		return nil
 	}
 	f := fsslice.Filter{
@@ -0,0 +1,9 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package namespaceprefixsuffix contains a kio.Filter implementation of
+// the kustomize NamespacePrefixSuffixTransformer.  The filter adds a
+// prefix and a suffix to namespace names, in the fields naming them:
+// the names of Namespaces, the namespaces of resources, and references
+// to namespaces, e.g. the namespace of a RoleBinding subject.
+package namespaceprefixsuffix
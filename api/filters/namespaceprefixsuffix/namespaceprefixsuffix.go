@@ -0,0 +1,101 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package namespaceprefixsuffix
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// defaultFieldSpecs locate the fields naming namespaces.
+const defaultFieldSpecs = `
+- path: metadata/namespace
+- path: metadata/name
+  kind: Namespace
+- path: subjects/namespace
+  kind: RoleBinding
+- path: subjects/namespace
+  kind: ClusterRoleBinding
+- path: spec/service/namespace
+  group: apiregistration.k8s.io
+  kind: APIService
+- path: webhooks/clientConfig/service/namespace
+  group: admissionregistration.k8s.io
+  kind: MutatingWebhookConfiguration
+- path: webhooks/clientConfig/service/namespace
+  group: admissionregistration.k8s.io
+  kind: ValidatingWebhookConfiguration
+- path: spec/conversion/webhook/clientConfig/service/namespace
+  group: apiextensions.k8s.io
+  kind: CustomResourceDefinition
+- path: spec/conversion/webhookClientConfig/service/namespace
+  group: apiextensions.k8s.io
+  kind: CustomResourceDefinition
+`
+
+// DefaultFsSlice returns the field specs locating the
+// fields that name namespaces: the names of Namespaces,
+// the namespaces of resources, the namespaces of the
+// subjects of role bindings, and the namespaces of the
+// services of APIServices, webhooks and conversion webhooks.
+func DefaultFsSlice() (types.FsSlice, error) {
+	var fss types.FsSlice
+	err := k8syaml.Unmarshal([]byte(defaultFieldSpecs), &fss)
+	return fss, err
+}
+
+// Filter adds a prefix and a suffix to the namespace
+// names in the fields FsSlice locates.  A missing
+// field is never created.
+type Filter struct {
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+
+	// Namespaces are the names to change.  Others are
+	// left as they are, unless IncludeExternal is true.
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// IncludeExternal, if true, changes every name.
+	IncludeExternal bool `json:"includeExternal,omitempty" yaml:"includeExternal,omitempty"`
+
+	FsSlice types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	err := node.PipeE(fsslice.Filter{
+		FsSlice:  f.FsSlice,
+		SetValue: f.evaluateField,
+	})
+	return node, err
+}
+
+func (f Filter) evaluateField(node *yaml.RNode) error {
+	ns := node.YNode().Value
+	if node.YNode().Kind != yaml.ScalarNode || ns == "" || !f.changes(ns) {
+		return nil
+	}
+	return filtersutil.SetScalar(f.Prefix + ns + f.Suffix)(node)
+}
+
+func (f Filter) changes(ns string) bool {
+	if f.IncludeExternal {
+		return true
+	}
+	for _, n := range f.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
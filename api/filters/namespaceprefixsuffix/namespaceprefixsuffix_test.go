@@ -0,0 +1,153 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package namespaceprefixsuffix_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filters/namespaceprefixsuffix"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+const input = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: read
+  namespace: app
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app
+- kind: ServiceAccount
+  name: monitor
+  namespace: monitoring
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: check
+webhooks:
+- name: check.example.com
+  clientConfig:
+    service:
+      name: checker
+      namespace: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unplaced
+`
+
+func TestFilter(t *testing.T) {
+	fss, err := namespaceprefixsuffix.DefaultFsSlice()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	testCases := map[string]struct {
+		filter   namespaceprefixsuffix.Filter
+		expected string
+	}{
+		"namespaces in the build": {
+			filter: namespaceprefixsuffix.Filter{
+				Prefix:     "t1-",
+				Suffix:     "-staging",
+				Namespaces: []string{"app"},
+			},
+			expected: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: t1-app-staging
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: read
+  namespace: t1-app-staging
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: t1-app-staging
+- kind: ServiceAccount
+  name: monitor
+  namespace: monitoring
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: check
+webhooks:
+- name: check.example.com
+  clientConfig:
+    service:
+      name: checker
+      namespace: t1-app-staging
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unplaced
+`,
+		},
+		"include external": {
+			filter: namespaceprefixsuffix.Filter{
+				Suffix:          "-staging",
+				IncludeExternal: true,
+			},
+			expected: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app-staging
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: read
+  namespace: app-staging
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app-staging
+- kind: ServiceAccount
+  name: monitor
+  namespace: monitoring-staging
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: check
+webhooks:
+- name: check.example.com
+  clientConfig:
+    service:
+      name: checker
+      namespace: app-staging
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unplaced
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.filter.FsSlice = fss
+			assert.Equal(t,
+				strings.TrimSpace(tc.expected),
+				strings.TrimSpace(
+					filtertest_test.RunFilter(t, input, tc.filter)))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package normalizelists contains a kio.Filter implementation of the
+// kustomize NormalizeListsTransformer.  The filter sorts lists whose
+// order Kubernetes ignores, e.g. the env vars and volume mounts of a
+// container, so that the output of builds is canonical, and diffs
+// between them show only changes that matter.
+package normalizelists
@@ -0,0 +1,225 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizelists
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/fieldspec"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// List is a list to sort, and how to sort it.
+type List struct {
+	// FieldSpec selects the resources, by a Gvk pattern,
+	// and the path of the list in them.
+	types.FieldSpec `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	// SortKeys are the slash-separated paths, within the
+	// elements of the list, of the fields to sort them by,
+	// compared in turn.  A missing field sorts first.  If
+	// there are none, the elements are sorted by their own
+	// values, which must be scalars.
+	SortKeys []string `json:"sortKeys,omitempty" yaml:"sortKeys,omitempty"`
+
+	// Force allows sorting a list whose order matters,
+	// e.g. the containers of a pod.
+	Force bool `json:"force,omitempty" yaml:"force,omitempty"`
+}
+
+// orderedFields are the names of lists whose order
+// matters, which may be sorted only by force.
+var orderedFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+	"args":                true,
+	"command":             true,
+}
+
+// Validate returns an error if the list's order matters
+// and it isn't forced, or if it has no path.
+func (l List) Validate() error {
+	if l.Path == "" {
+		return fmt.Errorf("list to normalize has no path")
+	}
+	path := l.PathSlice()
+	name := strings.TrimSuffix(path[len(path)-1], "[]")
+	if orderedFields[name] && !l.Force {
+		return fmt.Errorf(
+			"the order of %s in %s is meaningful, so it isn't "+
+				"sorted unless force: true is set on its entry",
+			name, l.Path)
+	}
+	return nil
+}
+
+// podSpecPaths are the paths of the pod specs of the
+// built-in kinds that have them.
+var podSpecPaths = []struct {
+	kind string
+	path string
+}{
+	{kind: "Pod", path: "spec"},
+	{kind: "PodTemplate", path: "template/spec"},
+	{kind: "ReplicationController", path: "spec/template/spec"},
+	{kind: "ReplicaSet", path: "spec/template/spec"},
+	{kind: "Deployment", path: "spec/template/spec"},
+	{kind: "StatefulSet", path: "spec/template/spec"},
+	{kind: "DaemonSet", path: "spec/template/spec"},
+	{kind: "Job", path: "spec/template/spec"},
+	{kind: "CronJob", path: "spec/jobTemplate/spec/template/spec"},
+}
+
+// DefaultLists returns the lists sorted if none are given:
+// the env, envFrom and volumeMounts of each container,
+// and the volumes and imagePullSecrets of each pod spec,
+// of the built-in kinds.  They're sorted by name, but
+// envFrom by the names of its ConfigMaps and Secrets,
+// then by prefix.
+//
+// Note that when two envFrom sources define the same
+// key, the one listed last wins, so sorting them changes
+// the value of the key.
+func DefaultLists() []List {
+	var result []List
+	add := func(kind, path string, keys ...string) {
+		result = append(result, List{
+			FieldSpec: types.FieldSpec{
+				Gvk:  resid.Gvk{Kind: kind},
+				Path: path,
+			},
+			SortKeys: keys,
+		})
+	}
+	for _, p := range podSpecPaths {
+		for _, c := range []string{"initContainers", "containers"} {
+			containers := p.path + "/" + c
+			add(p.kind, containers+"/env", "name")
+			add(p.kind, containers+"/envFrom",
+				"configMapRef/name", "secretRef/name", "prefix")
+			add(p.kind, containers+"/volumeMounts", "name")
+		}
+		add(p.kind, p.path+"/volumes", "name")
+		add(p.kind, p.path+"/imagePullSecrets", "name")
+	}
+	return result
+}
+
+// Filter sorts the given lists.  The sort is stable, so
+// elements with equal keys keep their order.
+//
+// A list of env vars that refer to one another, e.g.
+// via a value of $(OTHER_VAR), keeps its order, since
+// Kubernetes expands such a reference only to a var
+// defined earlier in the list.
+type Filter struct {
+	Lists []List `json:"lists,omitempty" yaml:"lists,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	for _, l := range f.Lists {
+		if err := node.PipeE(fieldspec.Filter{
+			FieldSpec: l.FieldSpec,
+			SetValue:  l.sort,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (l List) sort(node *yaml.RNode) error {
+	if node.YNode().Kind != yaml.SequenceNode {
+		return fmt.Errorf("expected a list at %s", l.Path)
+	}
+	elements := node.YNode().Content
+	keys := make(map[*yaml.Node][]string, len(elements))
+	for _, e := range elements {
+		k, err := l.sortKey(yaml.NewRNode(e))
+		if err != nil {
+			return err
+		}
+		keys[e] = k
+	}
+	if refersToSibling(elements) {
+		return nil
+	}
+	sort.SliceStable(elements, func(i, j int) bool {
+		return lessKeys(keys[elements[i]], keys[elements[j]])
+	})
+	return nil
+}
+
+// sortKey returns the values of the sort keys of the
+// element.
+func (l List) sortKey(e *yaml.RNode) ([]string, error) {
+	if len(l.SortKeys) == 0 {
+		if e.YNode().Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf(
+				"the list at %s has no sortKeys, but an element "+
+					"that isn't a scalar", l.Path)
+		}
+		return []string{e.YNode().Value}, nil
+	}
+	var result []string
+	for _, k := range l.SortKeys {
+		v, err := e.Pipe(yaml.Lookup(
+			types.FieldSpec{Path: k}.PathSlice()...))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, yaml.GetValue(v))
+	}
+	return result, nil
+}
+
+func lessKeys(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// refersToSibling returns true if the value of an
+// element refers to the name of another, as in $(NAME).
+func refersToSibling(elements []*yaml.Node) bool {
+	var names, values []string
+	for _, e := range elements {
+		if e.Kind != yaml.MappingNode {
+			continue
+		}
+		rn := yaml.NewRNode(e)
+		if f := rn.Field("name"); f != nil {
+			names = append(names, yaml.GetValue(f.Value))
+		}
+		if f := rn.Field("value"); f != nil {
+			values = append(values, yaml.GetValue(f.Value))
+		}
+	}
+	for _, v := range values {
+		if !strings.Contains(v, "$(") {
+			continue
+		}
+		for _, n := range names {
+			if strings.Contains(v, "$("+n+")") {
+				return true
+			}
+		}
+	}
+	return false
+}
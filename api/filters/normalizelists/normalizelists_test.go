@@ -0,0 +1,213 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package normalizelists
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	filtertest_test "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestFilterDefaultLists(t *testing.T) {
+	input := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: reg2
+      - name: reg1
+      containers:
+      - name: b
+        image: b
+        args: [--z, --a]
+        env:
+        - name: Z
+          value: z
+        - name: A
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        envFrom:
+        - secretRef:
+            name: s
+        - configMapRef:
+            name: cm2
+        - configMapRef:
+            name: cm1
+        volumeMounts:
+        - name: data
+          mountPath: /data
+        - name: config
+          mountPath: /config
+      - name: a
+        image: a
+        env:
+        - name: HOST
+          value: example.com
+        - name: URL
+          value: https://$(HOST)/
+        - name: B
+          value: b
+      volumes:
+      - name: data
+        emptyDir: {}
+      - name: config
+        configMap:
+          name: cm1
+`
+	expected := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: reg1
+      - name: reg2
+      containers:
+      - name: b
+        image: b
+        args: [--z, --a]
+        env:
+        - name: A
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: Z
+          value: z
+        envFrom:
+        - secretRef:
+            name: s
+        - configMapRef:
+            name: cm1
+        - configMapRef:
+            name: cm2
+        volumeMounts:
+        - name: config
+          mountPath: /config
+        - name: data
+          mountPath: /data
+      - name: a
+        image: a
+        env:
+        - name: HOST
+          value: example.com
+        - name: URL
+          value: https://$(HOST)/
+        - name: B
+          value: b
+      volumes:
+      - name: config
+        configMap:
+          name: cm1
+      - name: data
+        emptyDir: {}
+`
+	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(
+		filtertest_test.RunFilter(t, input, Filter{Lists: DefaultLists()})))
+}
+
+func TestFilterCustomList(t *testing.T) {
+	input := `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  ports:
+  - port: 90
+    protocol: UDP
+  - port: 80
+    protocol: TCP
+  - port: 80
+    protocol: UDP
+  tags: [b, c, a]
+`
+	expected := `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  ports:
+  - port: 80
+    protocol: TCP
+  - port: 80
+    protocol: UDP
+  - port: 90
+    protocol: UDP
+  tags: [a, b, c]
+`
+	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(
+		filtertest_test.RunFilter(t, input, Filter{Lists: []List{
+			{
+				FieldSpec: types.FieldSpec{
+					Gvk:  resid.Gvk{Kind: "Widget"},
+					Path: "spec/ports",
+				},
+				SortKeys: []string{"port", "protocol"},
+			},
+			{FieldSpec: types.FieldSpec{Path: "spec/tags"}},
+			{FieldSpec: types.FieldSpec{
+				Gvk:  resid.Gvk{Kind: "Gadget"},
+				Path: "spec/ports",
+			}},
+		}})))
+}
+
+func TestListValidate(t *testing.T) {
+	testCases := map[string]struct {
+		list List
+		err  string
+	}{
+		"default": {
+			list: DefaultLists()[0],
+		},
+		"containers": {
+			list: List{FieldSpec: types.FieldSpec{
+				Path: "spec/template/spec/containers"}},
+			err: "the order of containers in spec/template/spec/containers " +
+				"is meaningful, so it isn't sorted unless force: true " +
+				"is set on its entry",
+		},
+		"forced args": {
+			list: List{
+				FieldSpec: types.FieldSpec{Path: "spec/containers[]/args"},
+				Force:     true,
+			},
+		},
+		"args": {
+			list: List{FieldSpec: types.FieldSpec{Path: "spec/containers[]/args"}},
+			err:  "the order of args",
+		},
+		"no path": {
+			err: "list to normalize has no path",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.list.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+	for _, l := range DefaultLists() {
+		assert.NoError(t, l.Validate(), l.Path)
+	}
+}
@@ -60,6 +60,39 @@ func (pf Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = node.UnmarshalJSON(res)
-	return node, err
+	original := node.YNode()
+	if err = node.UnmarshalJSON(res); err != nil {
+		return node, err
+	}
+	keepStyles(original, node.YNode())
+	return node, nil
+}
+
+// keepStyles gives the scalars of the patched object the
+// styles, e.g. quoted or literal, of the scalars at the same
+// places in the original object, which the round trip
+// through JSON loses.
+func keepStyles(original, patched *yaml.Node) {
+	if original.Kind != patched.Kind {
+		return
+	}
+	switch patched.Kind {
+	case yaml.ScalarNode:
+		yaml.KeepStyle(original, patched)
+	case yaml.SequenceNode:
+		for i, n := range patched.Content {
+			if i < len(original.Content) {
+				keepStyles(original.Content[i], n)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(patched.Content); i += 2 {
+			for j := 0; j+1 < len(original.Content); j += 2 {
+				if original.Content[j].Value == patched.Content[i].Value {
+					keepStyles(original.Content[j+1], patched.Content[i+1])
+					break
+				}
+			}
+		}
+	}
 }
@@ -156,6 +156,57 @@ spec:
         - arg3
         image: nginx
         name: my-nginx
+`,
+		},
+		{
+			testName: "styles kept",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    script: |
+      echo hello
+    team: 'web'
+  name: myDeploy
+spec:
+  replica: "2"
+  template:
+    spec:
+      containers:
+      - image: "nginx:1.0"
+        name: nginx
+`,
+			filter: Filter{
+				Patch: `
+- op: replace
+  path: /metadata/annotations/script
+  value: |
+    echo bye
+- op: replace
+  path: /metadata/annotations/team
+  value: "on"
+- op: replace
+  path: /spec/replica
+  value: 3
+`,
+			},
+			expectedOutput: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    script: |
+      echo bye
+    team: 'on'
+  name: myDeploy
+spec:
+  replica: 3
+  template:
+    spec:
+      containers:
+      - image: "nginx:1.0"
+        name: nginx
 `,
 		},
 	}
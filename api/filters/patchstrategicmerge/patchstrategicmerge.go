@@ -6,12 +6,29 @@ package patchstrategicmerge
 import (
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
 )
 
 type Filter struct {
 	Patch *yaml.RNode
+
+	// Schemas, if set, are the OpenAPI definitions giving
+	// the merge keys of lists, rather than the global ones.
+	Schemas *openapi.Schemas `json:"-" yaml:"-"`
+
+	// KeepUnmatchedDeletes, if true, keeps the parts of the
+	// patch with the directive $patch: delete that match
+	// nothing in the node patched, e.g. a list element it
+	// lacks, for when that node is itself a patch.
+	// Otherwise they're dropped, deleting nothing.
+	KeepUnmatchedDeletes bool `json:"-" yaml:"-"`
+
+	// Warnings, if not nil, collects the warnings about the
+	// patch, e.g. that a value of a $deleteFromPrimitiveList
+	// directive was equal to no element of its list.
+	Warnings *[]string `json:"-" yaml:"-"`
 }
 
 var _ kio.Filter = Filter{}
@@ -20,18 +37,77 @@ var _ kio.Filter = Filter{}
 func (pf Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	var result []*yaml.RNode
 	for i := range nodes {
-		r, err := merge2.Merge(
+		r, warnings, err := merge2.MergeWithSchemasAndWarnings(
 			pf.Patch, nodes[i],
 			yaml.MergeOptions{
-				ListIncreaseDirection: yaml.MergeOptionsListPrepend,
+				ListIncreaseDirection:    yaml.MergeOptionsListPrepend,
+				DeleteListElementsByName: true,
 			},
+			pf.Schemas,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if pf.Warnings != nil {
+			*pf.Warnings = append(*pf.Warnings, warnings...)
+		}
+		if r != nil && !pf.KeepUnmatchedDeletes {
+			dropDeletes(r.YNode())
+		}
 		if !konfig.FlagEnableKyamlDefaultValue || r != nil {
 			result = append(result, r)
 		}
 	}
 	return result, nil
 }
+
+// dropDeletes removes from the given node the maps, list
+// elements or not, with the directive $patch: delete.  Left
+// by a merge, those deleted nothing.  A list that only
+// held such elements is removed too.
+func dropDeletes(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		var kept []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v := n.Content[i+1]
+			if isDelete(v) {
+				continue
+			}
+			if v.Kind == yaml.SequenceNode && len(v.Content) > 0 {
+				dropDeletes(v)
+				if len(v.Content) == 0 {
+					continue
+				}
+			} else {
+				dropDeletes(v)
+			}
+			kept = append(kept, n.Content[i], v)
+		}
+		n.Content = kept
+	case yaml.SequenceNode:
+		var kept []*yaml.Node
+		for _, elem := range n.Content {
+			if isDelete(elem) {
+				continue
+			}
+			dropDeletes(elem)
+			kept = append(kept, elem)
+		}
+		n.Content = kept
+	}
+}
+
+// isDelete returns true if the node is a map with the
+// directive $patch: delete.
+func isDelete(n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "$patch" {
+			return n.Content[i+1].Value == "delete"
+		}
+	}
+	return false
+}
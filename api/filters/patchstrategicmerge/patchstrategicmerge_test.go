@@ -36,6 +36,43 @@ metadata:
   name: clown
 spec:
   numReplicas: 999
+`,
+		},
+		"block scalar annotations keep their style": {
+			input: `apiVersion: v1
+kind: Deployment
+metadata:
+  name: clown
+  annotations:
+    script: |
+      echo hello
+    description: >
+      a long
+      description
+    enabled: "false"
+    mode: fast
+`,
+			patch: yaml.MustParse(`apiVersion: v1
+kind: Deployment
+metadata:
+  name: clown
+  annotations:
+    script: "echo bye\n"
+    description: a longer description
+    enabled: "true"
+    mode: "on"
+`),
+			expected: `apiVersion: v1
+kind: Deployment
+metadata:
+  name: clown
+  annotations:
+    script: |
+      echo bye
+    description: >-
+      a longer description
+    enabled: "true"
+    mode: "on"
 `,
 		},
 		"nullMapEntry1": {
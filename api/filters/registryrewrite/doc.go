@@ -0,0 +1,9 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package registryrewrite contains a kio.Filter implementation of the
+// kustomize RegistryRewriteTransformer.  The filter moves images from
+// the registries they name to mirrors of those registries, e.g. for
+// clusters that can't reach public registries, keeping the tag or
+// digest of each image.
+package registryrewrite
@@ -0,0 +1,173 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package registryrewrite
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/image"
+	"sigs.k8s.io/kustomize/api/konfig/builtinpluginconsts"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// Mirror moves the images under a registry, or under a
+// path in one, to a mirror.
+type Mirror struct {
+	// From is the registry, maybe followed by a path,
+	// e.g. docker.io or docker.io/library.  It matches
+	// whole path elements, so docker.io/lib doesn't
+	// match docker.io/library/nginx.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+
+	// To replaces From, e.g. registry.corp/dockerhub.
+	To string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// Rewriter computes the rewrites of images.
+type Rewriter struct {
+	// Mirrors move images by the longest From matching them.
+	Mirrors []Mirror
+
+	// DefaultMirror, if set, is prepended to images naming
+	// no registry, e.g. nginx or bitnami/redis.
+	DefaultMirror string
+
+	// AllowedRegistries name registries, maybe followed by
+	// a path as in Mirror.From, whose images are never
+	// rewritten.
+	AllowedRegistries []string
+}
+
+// Rewrite returns the rewrite of the given image, and
+// true, or the image and false if it's not rewritten.
+func (rw Rewriter) Rewrite(img string) (string, bool) {
+	name, tag := image.Split(img)
+	if name == "" {
+		return img, false
+	}
+	for _, allowed := range rw.AllowedRegistries {
+		if underPrefix(name, allowed) {
+			return img, false
+		}
+	}
+	var best *Mirror
+	for i := range rw.Mirrors {
+		m := &rw.Mirrors[i]
+		if underPrefix(name, m.From) &&
+			(best == nil || len(m.From) > len(best.From)) {
+			best = m
+		}
+	}
+	switch {
+	case best != nil:
+		name = best.To + strings.TrimPrefix(name, best.From)
+	case rw.DefaultMirror != "" && Registry(name) == "":
+		name = strings.TrimSuffix(rw.DefaultMirror, "/") + "/" + name
+	default:
+		return img, false
+	}
+	return name + tag, true
+}
+
+// underPrefix returns true if the image name is the
+// prefix, or under it as a path.
+func underPrefix(name, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return false
+	}
+	return name == prefix || strings.HasPrefix(name, prefix+"/")
+}
+
+// Registry returns the registry the given image name
+// names, e.g. gcr.io for gcr.io/project/app, or the empty
+// string if it names none, e.g. for nginx or bitnami/redis.
+// As for docker, the first path element of a name is a
+// registry if it holds a dot or a port, or is localhost.
+func Registry(name string) string {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return ""
+	}
+	first := name[:i]
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return first
+	}
+	return ""
+}
+
+// DefaultFsSlice returns the image fields of the default
+// transformer configuration, e.g. the images of the
+// containers of pod templates.
+func DefaultFsSlice() (types.FsSlice, error) {
+	var c struct {
+		Images types.FsSlice `json:"images,omitempty" yaml:"images,omitempty"`
+	}
+	err := k8syaml.Unmarshal([]byte(
+		builtinpluginconsts.GetDefaultFieldSpecsAsMap()["images"]), &c)
+	return c.Images, err
+}
+
+// Filter rewrites the images in the fields of a resource
+// that FsSlice locates, as Rewriter says.
+type Filter struct {
+	Rewriter
+
+	// FsSlice locates the image fields.  A missing field
+	// is never created.
+	FsSlice types.FsSlice
+
+	// Audit, if true, leaves the images as they are, and
+	// just reports each rewrite to OnRewrite.
+	Audit bool
+
+	// OnRewrite, if set, is called with each image the
+	// filter rewrites, or would rewrite, and its rewrite.
+	OnRewrite func(node *yaml.RNode, from, to string)
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	_, err := kio.FilterAll(yaml.FilterFunc(f.filter)).Filter(nodes)
+	return nodes, err
+}
+
+func (f Filter) filter(node *yaml.RNode) (*yaml.RNode, error) {
+	fss := make(types.FsSlice, len(f.FsSlice))
+	for i, fs := range f.FsSlice {
+		fs.CreateIfNotPresent = false
+		fss[i] = fs
+	}
+	err := node.PipeE(fsslice.Filter{
+		FsSlice: fss,
+		SetValue: func(n *yaml.RNode) error {
+			return f.rewrite(node, n)
+		},
+	})
+	return node, err
+}
+
+func (f Filter) rewrite(resource *yaml.RNode, n *yaml.RNode) error {
+	if err := yaml.ErrorIfInvalid(n, yaml.ScalarNode); err != nil {
+		return err
+	}
+	from := n.YNode().Value
+	to, rewritten := f.Rewrite(from)
+	if !rewritten {
+		return nil
+	}
+	if f.OnRewrite != nil {
+		f.OnRewrite(resource, from, to)
+	}
+	if f.Audit {
+		return nil
+	}
+	return filtersutil.SetScalar(to)(n)
+}
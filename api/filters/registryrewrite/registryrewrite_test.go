@@ -0,0 +1,160 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package registryrewrite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestRewrite(t *testing.T) {
+	rw := Rewriter{
+		Mirrors: []Mirror{
+			{From: "docker.io", To: "registry.corp/dockerhub"},
+			{From: "docker.io/library", To: "registry.corp/official"},
+			{From: "quay.io/", To: "registry.corp/quay/"},
+		},
+		DefaultMirror:     "registry.corp/default/",
+		AllowedRegistries: []string{"registry.corp", "gcr.io/trusted"},
+	}
+	testCases := map[string]struct {
+		image    string
+		expected string
+	}{
+		"prefix": {
+			image:    "docker.io/bitnami/redis:6.0",
+			expected: "registry.corp/dockerhub/bitnami/redis:6.0",
+		},
+		"longest prefix wins": {
+			image:    "docker.io/library/nginx:1.19",
+			expected: "registry.corp/official/nginx:1.19",
+		},
+		"whole path elements": {
+			image:    "docker.io/libraryx/nginx",
+			expected: "registry.corp/dockerhub/libraryx/nginx",
+		},
+		"trailing slashes": {
+			image:    "quay.io/coreos/etcd",
+			expected: "registry.corp/quay/coreos/etcd",
+		},
+		"digest": {
+			image:    "docker.io/library/nginx@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3",
+			expected: "registry.corp/official/nginx@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3",
+		},
+		"default mirror": {
+			image:    "nginx:1.19",
+			expected: "registry.corp/default/nginx:1.19",
+		},
+		"default mirror with path": {
+			image:    "bitnami/redis",
+			expected: "registry.corp/default/bitnami/redis",
+		},
+		"allowed": {
+			image:    "registry.corp/dockerhub/nginx:1.19",
+			expected: "registry.corp/dockerhub/nginx:1.19",
+		},
+		"allowed path": {
+			image:    "gcr.io/trusted/app:1",
+			expected: "gcr.io/trusted/app:1",
+		},
+		"other registry": {
+			image:    "gcr.io/untrusted/app:1",
+			expected: "gcr.io/untrusted/app:1",
+		},
+		"registry with port": {
+			image:    "localhost:5000/app:1",
+			expected: "localhost:5000/app:1",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, rewritten := rw.Rewrite(tc.image)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.expected != tc.image, rewritten)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	testCases := map[string]string{
+		"nginx":                 "",
+		"bitnami/redis":         "",
+		"docker.io/nginx":       "docker.io",
+		"gcr.io/project/app":    "gcr.io",
+		"localhost/app":         "localhost",
+		"myhost:5000/app":       "myhost:5000",
+		"library/nginx.foo/bar": "",
+	}
+	for name, expected := range testCases {
+		assert.Equal(t, expected, Registry(name), name)
+	}
+}
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: app
+        image: docker.io/library/nginx:1.19
+      - name: sidecar
+        image: gcr.io/project/proxy:2
+`
+
+func TestFilter(t *testing.T) {
+	fss, err := DefaultFsSlice()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rw := Rewriter{
+		Mirrors:       []Mirror{{From: "docker.io", To: "registry.corp/dockerhub"}},
+		DefaultMirror: "registry.corp/default",
+	}
+
+	var rewrites []string
+	record := func(_ *yaml.RNode, from, to string) {
+		rewrites = append(rewrites, from+" -> "+to)
+	}
+	actual := filtertest.RunFilter(t, deployment, Filter{
+		Rewriter: rw, FsSlice: fss, OnRewrite: record})
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: registry.corp/default/busybox
+      containers:
+      - name: app
+        image: registry.corp/dockerhub/library/nginx:1.19
+      - name: sidecar
+        image: gcr.io/project/proxy:2
+`), strings.TrimSpace(actual))
+	assert.Equal(t, []string{
+		"docker.io/library/nginx:1.19 -> registry.corp/dockerhub/library/nginx:1.19",
+		"busybox -> registry.corp/default/busybox",
+	}, rewrites)
+
+	rewrites = nil
+	actual = filtertest.RunFilter(t, deployment, Filter{
+		Rewriter: rw, FsSlice: fss, Audit: true, OnRewrite: record})
+	assert.Equal(t,
+		strings.TrimSpace(deployment), strings.TrimSpace(actual))
+	assert.Len(t, rewrites, 2)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/filters/filtersutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -118,7 +119,7 @@ func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 				newValue = filesys.InsertPathPart(
 					n.YNode().Value, f.FilePathPosition-1, newValue)
 			}
-			return n.Pipe(yaml.FieldSetter{StringValue: newValue})
+			return n, filtersutil.SetScalar(newValue)(n)
 		})).Filter(nodes)
 	return nodes, err
 }
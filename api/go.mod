@@ -3,6 +3,7 @@ module sigs.k8s.io/kustomize/api
 go 1.15
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/go-errors/errors v1.0.1
 	github.com/go-openapi/spec v0.19.5
@@ -53,7 +53,16 @@ func Hash(data string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
 }
 
-// HashRNode returns the hash value of input RNode
+// HashRNode returns the hash value of input RNode.
+//
+// For a ConfigMap, that's a hash of its kind, name, data
+// and binaryData; for a Secret, of its kind, name, type,
+// data and stringData.  Nothing else counts, notably not
+// the namespace, labels or annotations, so changing only
+// those, e.g. the labels and annotations of
+// generatorOptions, keeps the name suffix hash of a
+// generated ConfigMap or Secret.  Any other kind is hashed
+// whole.
 func HashRNode(node *yaml.RNode) (string, error) {
 	// get node kind
 	kindNode, err := node.Pipe(yaml.FieldMatcher{Name: "kind"})
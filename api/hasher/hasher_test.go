@@ -170,6 +170,55 @@ data:
 	}
 }
 
+func TestHashIgnoresMetadata(t *testing.T) {
+	hash := func(in string) string {
+		node, err := yaml.Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := HashRNode(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		plain := hash(`
+apiVersion: v1
+kind: ` + kind + `
+metadata:
+  name: app
+data:
+  one: "1"`)
+		withMetadata := hash(`
+apiVersion: v1
+kind: ` + kind + `
+metadata:
+  name: app
+  namespace: shop
+  labels:
+    team: shop
+  annotations:
+    build-id: "42"
+data:
+  one: "1"`)
+		if plain != withMetadata {
+			t.Errorf("%s: labels, annotations or namespace changed "+
+				"the hash from %q to %q", kind, plain, withMetadata)
+		}
+		otherData := hash(`
+apiVersion: v1
+kind: ` + kind + `
+metadata:
+  name: app
+data:
+  one: "2"`)
+		if plain == otherData {
+			t.Errorf("%s: changing data kept the hash %q", kind, plain)
+		}
+	}
+}
+
 func TestUnstructuredHash(t *testing.T) {
 	cases := []struct {
 		desc         string
@@ -46,6 +46,11 @@ type Kunstructured interface {
 	// GetAnnotations returns the k8s annotations.
 	GetAnnotations() map[string]string
 
+	// GetBool returns the value of a bool field, e.g.
+	// spec.template.spec.enableServiceLinks.  A string
+	// such as "true" is an error.
+	GetBool(string) (bool, error)
+
 	// GetData returns a top-level "data" field, as in a ConfigMap.
 	GetDataMap() map[string]string
 
@@ -55,6 +60,10 @@ type Kunstructured interface {
 	// Used by Resource.OrgId
 	GetGvk() resid.Gvk
 
+	// GetInt returns the value of an int field, e.g.
+	// spec.replicas.  A string such as "8080" is an error.
+	GetInt(string) (int64, error)
+
 	// Used by resource.Factory.SliceFromBytes
 	GetKind() string
 
@@ -102,6 +111,27 @@ type Kunstructured interface {
 	// SetNamespace changes the namespace.
 	SetNamespace(string)
 
+	// SetAnnotationsE is SetAnnotations, returning an error
+	// if the annotations can't be set, e.g. as metadata
+	// isn't a map, rather than exiting.
+	SetAnnotationsE(map[string]string) error
+
+	// SetGvkE is SetGvk, returning an error rather than
+	// exiting.
+	SetGvkE(resid.Gvk) error
+
+	// SetLabelsE is SetLabels, returning an error rather
+	// than exiting.
+	SetLabelsE(map[string]string) error
+
+	// SetNameE is SetName, returning an error rather than
+	// exiting.
+	SetNameE(string) error
+
+	// SetNamespaceE is SetNamespace, returning an error
+	// rather than exiting.
+	SetNamespaceE(string) error
+
 	// Needed, for now, by kyaml/filtersutil.ApplyToJSON.
 	UnmarshalJSON([]byte) error
 }
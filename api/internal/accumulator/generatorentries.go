@@ -0,0 +1,99 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// GeneratorEntry describes an entry of a kustomization's
+// configMapGenerator or secretGenerator field.
+type GeneratorEntry struct {
+	// Field is the name of the field, e.g. configMapGenerator.
+	Field string
+
+	// File is the path of the kustomization file.
+	File string
+
+	// Id is that of the resource the entry generates,
+	// before any prefix, suffix or hash is added.
+	Id resid.ResId
+
+	// Behavior is that of the entry, e.g. merge.
+	Behavior types.GenerationBehavior
+}
+
+func (e GeneratorEntry) String() string {
+	ns := ""
+	if e.Id.Namespace != "" {
+		ns = fmt.Sprintf(" (namespace '%s')", e.Id.Namespace)
+	}
+	return fmt.Sprintf("%s entry '%s'%s in %s", e.Field, e.Id.Name, ns, e.File)
+}
+
+// creates is true if the entry makes a new resource,
+// rather than merging into or replacing an existing one.
+func (e GeneratorEntry) creates() bool {
+	return e.Behavior != types.BehaviorMerge &&
+		e.Behavior != types.BehaviorReplace
+}
+
+// AddGeneratorEntries records the generator entries of a
+// kustomization, before they're run.  It's an error if an
+// entry that creates a resource generates the same kind, name
+// and namespace as an earlier entry of the kustomization, or
+// as a generated resource already accumulated, e.g. from a
+// base, since absorbing it would otherwise fail later, with
+// an error that names neither entry.
+func (ra *ResAccumulator) AddGeneratorEntries(entries []GeneratorEntry) error {
+	for i, e := range entries {
+		if !e.creates() {
+			continue
+		}
+		for _, other := range entries[:i] {
+			if other.Id.Equals(e.Id) {
+				return errGeneratorCollision(other, e)
+			}
+		}
+		if other, found := ra.generatedBy(e.Id); found {
+			return errGeneratorCollision(other, e)
+		}
+	}
+	ra.generators = append(ra.generators, entries...)
+	return nil
+}
+
+// generatedBy returns the entry that generated the resource
+// that a new resource with the given id would collide with,
+// if that resource was generated.  Resources are matched as
+// ResMap.AbsorbAll matches them.
+func (ra *ResAccumulator) generatedBy(id resid.ResId) (GeneratorEntry, bool) {
+	matches := ra.resMap.GetMatchingResourcesByOriginalId(id.Equals)
+	if len(matches) == 0 {
+		matches = ra.resMap.GetMatchingResourcesByCurrentId(id.Equals)
+	}
+	for _, r := range matches {
+		if !r.IsGenerated() {
+			continue
+		}
+		for _, e := range ra.generators {
+			if e.Id.Equals(r.OrgId()) {
+				return e, true
+			}
+		}
+	}
+	return GeneratorEntry{}, false
+}
+
+func errGeneratorCollision(first, second GeneratorEntry) error {
+	return fmt.Errorf(
+		"%s generates the same %s as %s; give one of them a "+
+			"different name or namespace, or, if the second is "+
+			"meant to change the first, its behavior must be "+
+			"merge or replace",
+		second, first.Id.Kind, first)
+}
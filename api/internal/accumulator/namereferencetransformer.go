@@ -8,23 +8,28 @@ import (
 
 	"sigs.k8s.io/kustomize/api/filters/nameref"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 )
 
 type nameReferenceTransformer struct {
-	backRefs []builtinconfig.NameBackReferences
+	backRefs      []builtinconfig.NameBackReferences
+	externalNames []resid.ResId
 }
 
 var _ resmap.Transformer = &nameReferenceTransformer{}
 
 // newNameReferenceTransformer constructs a nameReferenceTransformer
-// with a given slice of NameBackReferences.
+// with a given slice of NameBackReferences, leaving references to
+// the given external names alone.
 func newNameReferenceTransformer(
-	br []builtinconfig.NameBackReferences) resmap.Transformer {
+	br []builtinconfig.NameBackReferences,
+	externalNames []resid.ResId) resmap.Transformer {
 	if br == nil {
 		log.Fatal("backrefs not expected to be nil")
 	}
-	return &nameReferenceTransformer{backRefs: br}
+	return &nameReferenceTransformer{
+		backRefs: br, externalNames: externalNames}
 }
 
 // Transform updates name references in resource A that
@@ -99,6 +104,7 @@ func (t *nameReferenceTransformer) Transform(m resmap.ResMap) error {
 						NameFieldToUpdate:  fSpec,
 						ReferralTarget:     referralTarget.Gvk,
 						ReferralCandidates: candidates,
+						ExternalNames:      t.externalNames,
 					})
 					if err != nil {
 						return err
@@ -463,7 +463,7 @@ func TestNameReferenceHappyRun(t *testing.T) {
 			},
 		}).ResMap()
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	err := nrt.Transform(m)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -521,7 +521,7 @@ func TestNameReferenceUnhappyRun(t *testing.T) {
 			expectedErr: "cannot find field 'name' in node"},
 	}
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	for _, test := range tests {
 		err := nrt.Transform(test.resMap)
 		if err == nil {
@@ -578,7 +578,7 @@ func TestNameReferencePersistentVolumeHappyRun(t *testing.T) {
 
 	m1 := resmaptest_test.NewRmBuilder(t, rf).AddR(v1).AddR(c1).ResMap()
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	if err := nrt.Transform(m1); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -715,7 +715,7 @@ func TestNameReferenceNamespace(t *testing.T) {
 		ReplaceResource(deploymentMap(ns1, prefixedname, prefixedname, prefixedname)).
 		ReplaceResource(deploymentMap(ns2, suffixedname, suffixedname, suffixedname)).ResMap()
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	err := nrt.Transform(m)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -877,7 +877,7 @@ func TestNameReferenceClusterWide(t *testing.T) {
 	clusterRole, _ := expected.GetByCurrentId(clusterRoleId)
 	clusterRole.AppendRefBy(clusterRoleBindingId)
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	err := nrt.Transform(m)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -1004,7 +1004,7 @@ func TestNameReferenceNamespaceTransformation(t *testing.T) {
 	clusterRole, _ := expected.GetByCurrentId(clusterRoleId)
 	clusterRole.AppendRefBy(clusterRoleBindingId)
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	err := nrt.Transform(m)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -1041,7 +1041,7 @@ func TestNameReferenceCandidateSelection(t *testing.T) {
 		ReplaceResource(deploymentMap("", "p1-deploy1", "p1-cm1-hash", "p1-secret1-hash")).
 		ResMap()
 
-	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference)
+	nrt := newNameReferenceTransformer(builtinconfig.MakeDefaultConfig().NameReference, nil)
 	err := nrt.Transform(m)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -0,0 +1,87 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// PropagateLabelsToConsumers adds the labels a generated
+// resource is asked to propagate (see
+// types.GeneratorOptions) to the metadata of each resource
+// that refers to it by name, per the nameReference config.
+// Call it after FixBackReferences, so that names are final.
+// Only resources in the accumulator are considered.  As
+// with commonLabels, an existing value is overwritten.
+func (ra *ResAccumulator) PropagateLabelsToConsumers() error {
+	for _, generated := range ra.resMap.Resources() {
+		labels := generated.LabelsToPropagate()
+		if len(labels) == 0 {
+			continue
+		}
+		fss := ra.referringFieldSpecs(generated)
+		if len(fss) == 0 {
+			continue
+		}
+		ns := generated.CurId().EffectiveNamespace()
+		for _, r := range ra.resMap.Resources() {
+			if r == generated || r.CurId().EffectiveNamespace() != ns {
+				continue
+			}
+			found, err := refersToName(r, fss, generated.GetName())
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			merged := r.GetLabels()
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			for k, v := range labels {
+				merged[k] = v
+			}
+			if err := r.SetLabelsE(merged); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// referringFieldSpecs returns the fieldspecs that
+// may hold the name of the given resource.
+func (ra *ResAccumulator) referringFieldSpecs(
+	r *resource.Resource) (result types.FsSlice) {
+	gvk := r.GetGvk()
+	for _, nbr := range ra.tConfig.NameReference {
+		if gvk.IsSelected(&nbr.Gvk) {
+			result = append(result, nbr.FieldSpecs...)
+		}
+	}
+	return result
+}
+
+// refersToName returns true if any of the fields of r
+// located by fss holds the given name.
+func refersToName(
+	r *resource.Resource, fss types.FsSlice, name string) (bool, error) {
+	found := false
+	err := r.ApplyFilter(kio.FilterAll(fsslice.Filter{
+		FsSlice: fss,
+		SetValue: func(n *yaml.RNode) error {
+			if n.YNode().Kind == yaml.ScalarNode &&
+				n.YNode().Value == name {
+				found = true
+			}
+			return nil
+		},
+	}))
+	return found, err
+}
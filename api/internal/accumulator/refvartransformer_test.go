@@ -4,7 +4,6 @@
 package accumulator
 
 import (
-	"reflect"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/konfig"
@@ -191,8 +190,7 @@ metadata:
 				}
 
 				a, e := tc.given.res, tc.expected.res
-				if !reflect.DeepEqual(a, e) {
-					err = e.ErrorIfNotEqualLists(a)
+				if err = e.ErrorIfNotEqualLists(a); err != nil {
 					t.Fatalf("actual doesn't match expected: \nACTUAL:\n%v\nEXPECTED:\n%v\nERR: %v", a, e, err)
 				}
 			}
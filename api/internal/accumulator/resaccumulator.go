@@ -18,9 +18,10 @@ import (
 // used to customize those resources.  It's a ResMap
 // plus stuff needed to modify the ResMap.
 type ResAccumulator struct {
-	resMap  resmap.ResMap
-	tConfig *builtinconfig.TransformerConfig
-	varSet  types.VarSet
+	resMap     resmap.ResMap
+	tConfig    *builtinconfig.TransformerConfig
+	varSet     types.VarSet
+	generators []GeneratorEntry
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -92,6 +93,7 @@ func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 }
 
 func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
+	ra.generators = append(ra.generators, other.generators...)
 	err = ra.AppendAll(other.resMap)
 	if err != nil {
 		return err
@@ -160,10 +162,14 @@ func (ra *ResAccumulator) ResolveVars() error {
 	return err
 }
 
-func (ra *ResAccumulator) FixBackReferences() (err error) {
+// FixBackReferences updates the references to resources
+// whose names changed, except references to resources
+// with the given external names; see nameref.Filter.
+func (ra *ResAccumulator) FixBackReferences(
+	externalNames []resid.ResId) (err error) {
 	if ra.tConfig.NameReference == nil {
 		return nil
 	}
-	return ra.Transform(
-		newNameReferenceTransformer(ra.tConfig.NameReference))
+	return ra.Transform(newNameReferenceTransformer(
+		ra.tConfig.NameReference, externalNames))
 }
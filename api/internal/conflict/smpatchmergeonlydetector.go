@@ -28,6 +28,6 @@ func (c *smPatchMergeOnlyDetector) HasConflict(
 // because null entries are eliminated.
 func (c *smPatchMergeOnlyDetector) MergePatches(
 	r, patch *resource.Resource) (*resource.Resource, error) {
-	err := r.ApplySmPatch(patch)
+	err := r.MergeSmPatch(patch)
 	return r, err
 }
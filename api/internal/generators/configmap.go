@@ -27,19 +27,31 @@ import (
 //
 // In a ConfigMap, any key used in `data` cannot also be used in `binaryData`
 // and vice-versa.  A key must be unique across both maps.
+//
+// The content of a file that the file options of the generator say is
+// binary goes in `binaryData`, even if it's valid UTF-8.
 func MakeConfigMap(
 	ldr ifc.KvLoader, args *types.ConfigMapArgs) (rn *yaml.RNode, err error) {
 	rn, err = makeBaseNode("ConfigMap", args.Name, args.Namespace)
 	if err != nil {
 		return nil, err
 	}
-	m, err := makeValidatedDataMap(ldr, args.Name, args.KvPairSources)
+	m, binaryKeys, err := makeValidatedDataMap(
+		ldr, args.Name, args.KvPairSources)
 	if err != nil {
 		return nil, err
 	}
+	binary := make(map[string]string)
+	for k := range binaryKeys {
+		binary[k] = m[k]
+		delete(m, k)
+	}
 	if err = rn.LoadMapIntoConfigMapData(m); err != nil {
 		return nil, err
 	}
+	if err = rn.LoadMapIntoConfigMapBinaryData(binary); err != nil {
+		return nil, err
+	}
 	copyLabelsAndAnnotations(rn, args.Options)
 	return rn, nil
 }
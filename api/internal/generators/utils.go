@@ -34,25 +34,32 @@ kind: %s
 	return rn, nil
 }
 
+// makeValidatedDataMap returns the data of the pairs the
+// sources give, and the keys of the binary files among them.
 func makeValidatedDataMap(
-	ldr ifc.KvLoader, name string, sources types.KvPairSources) (map[string]string, error) {
+	ldr ifc.KvLoader, name string,
+	sources types.KvPairSources) (map[string]string, map[string]bool, error) {
 	pairs, err := ldr.Load(sources)
 	if err != nil {
-		return nil, errors.WrapPrefix(err, "loading KV pairs", 0)
+		return nil, nil, errors.WrapPrefix(err, "loading KV pairs", 0)
 	}
 	knownKeys := make(map[string]string)
+	binaryKeys := make(map[string]bool)
 	for _, p := range pairs {
 		// legal key: alphanumeric characters, '-', '_' or '.'
 		if err := ldr.Validator().ErrIfInvalidKey(p.Key); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if _, ok := knownKeys[p.Key]; ok {
-			return nil, errors.Errorf(
+			return nil, nil, errors.Errorf(
 				"configmap %s illegally repeats the key `%s`", name, p.Key)
 		}
 		knownKeys[p.Key] = p.Value
+		if p.Binary {
+			binaryKeys[p.Key] = true
+		}
 	}
-	return knownKeys, nil
+	return knownKeys, binaryKeys, nil
 }
 
 // copyLabelsAndAnnotations copies labels and annotations from
@@ -48,8 +48,8 @@ func (f *Factory) addKvToConfigMap(configMap *corev1.ConfigMap, p types.Pair) er
 		return err
 	}
 	// If the configmap data contains byte sequences that are all in the UTF-8
-	// range, we will write it to .Data
-	if utf8.Valid([]byte(p.Value)) {
+	// range, and isn't that of a binary file, we will write it to .Data
+	if !p.Binary && utf8.Valid([]byte(p.Value)) {
 		if _, entryExists := configMap.Data[p.Key]; entryExists {
 			return fmt.Errorf(keyExistsErrorMsg, p.Key, configMap.Data)
 		}
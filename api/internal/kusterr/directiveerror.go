@@ -0,0 +1,29 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import "fmt"
+
+// DirectiveError represents a YAML directive, e.g.
+// %YAML 2.0, that kustomize doesn't support.
+type DirectiveError struct {
+	// Source is the file, or plugin output, holding
+	// the directive; empty if not yet known.
+	Source string
+	// Index is the position, from zero, of the
+	// document the directive precedes in the source.
+	Index     int
+	Directive string
+	Reason    string
+}
+
+func (e DirectiveError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "input"
+	}
+	return fmt.Sprintf(
+		"document %d in %s has the unsupported directive '%s': %s",
+		e.Index, source, e.Directive, e.Reason)
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import "fmt"
+
+// LimitError represents input that exceeds one
+// of the limits in types.InputLimits.
+type LimitError struct {
+	// Limit names the limit, e.g. maxFileSize.
+	Limit string
+	// Source is the file, or plugin output, that
+	// exceeds the limit; empty if not yet known.
+	Source string
+	// Observed is the value seen in the input.  If
+	// AtLeast, the input was only examined until the
+	// limit was exceeded, so the value may be higher.
+	Observed int64
+	AtLeast  bool
+	Max      int64
+}
+
+func (e LimitError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "input"
+	}
+	observed := fmt.Sprintf("%d", e.Observed)
+	if e.AtLeast {
+		observed = "at least " + observed
+	}
+	return fmt.Sprintf(
+		"%s exceeds the %s limit of %d, with %s; "+
+			"the limit may be raised in the build options",
+		source, e.Limit, e.Max, observed)
+}
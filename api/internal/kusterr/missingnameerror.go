@@ -0,0 +1,53 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import "fmt"
+
+// MissingNameError represents a resource lacking the
+// metadata.name needed to identify it.
+type MissingNameError struct {
+	// Source is the file, or plugin output, holding
+	// the resource; empty if not yet known.
+	Source string
+	// Index is the position, from zero, of the
+	// resource's document in the source.
+	Index int
+	Kind  string
+}
+
+func (e MissingNameError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "input"
+	}
+	return fmt.Sprintf(
+		"document %d in %s, of kind %s, has no metadata.name; "+
+			"only a List, or a resource with a metadata.generateName, "+
+			"may omit it", e.Index, source, e.Kind)
+}
+
+// WithSource returns e, with the given source recorded
+// if e is a MissingNameError, LimitError or DirectiveError
+// that lacks one.
+func WithSource(e error, source string) error {
+	switch m := e.(type) {
+	case MissingNameError:
+		if m.Source == "" {
+			m.Source = source
+		}
+		return m
+	case LimitError:
+		if m.Source == "" {
+			m.Source = source
+		}
+		return m
+	case DirectiveError:
+		if m.Source == "" {
+			m.Source = source
+		}
+		return m
+	}
+	return e
+}
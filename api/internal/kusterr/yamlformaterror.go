@@ -19,7 +19,7 @@ func (e YamlFormatError) Error() string {
 	return fmt.Sprintf("YAML file [%s] encounters a format error.\n%s\n", e.Path, e.ErrorMsg)
 }
 
-// Handler handles YamlFormatError
+// Handler handles YamlFormatError and MissingNameError
 func Handler(e error, path string) error {
 	if isYAMLSyntaxError(e) {
 		return YamlFormatError{
@@ -27,7 +27,7 @@ func Handler(e error, path string) error {
 			ErrorMsg: e.Error(),
 		}
 	}
-	return e
+	return WithSource(e, path)
 }
 
 func isYAMLSyntaxError(e error) bool {
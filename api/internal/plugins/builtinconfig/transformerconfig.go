@@ -44,7 +44,13 @@ func MakeDefaultConfig() *TransformerConfig {
 // if any, with default config.
 func MakeTransformerConfig(
 	ldr ifc.Loader, paths []string) (*TransformerConfig, error) {
-	t1 := MakeDefaultConfig()
+	return MakeTransformerConfigOver(MakeDefaultConfig(), ldr, paths)
+}
+
+// MakeTransformerConfigOver returns a merger of custom config,
+// if any, with the given config, which stands in for the default.
+func MakeTransformerConfigOver(t1 *TransformerConfig,
+	ldr ifc.Loader, paths []string) (*TransformerConfig, error) {
 	if len(paths) == 0 {
 		return t1, nil
 	}
@@ -55,6 +61,27 @@ func MakeTransformerConfig(
 	return t1.Merge(t2)
 }
 
+// DeepCopy returns a copy of the config that shares no
+// slices with it, so that merging into the copy, which
+// may append in place, leaves the original unchanged.
+func (t *TransformerConfig) DeepCopy() *TransformerConfig {
+	c := &TransformerConfig{
+		NamePrefix:        append(types.FsSlice(nil), t.NamePrefix...),
+		NameSuffix:        append(types.FsSlice(nil), t.NameSuffix...),
+		NameSpace:         append(types.FsSlice(nil), t.NameSpace...),
+		CommonLabels:      append(types.FsSlice(nil), t.CommonLabels...),
+		CommonAnnotations: append(types.FsSlice(nil), t.CommonAnnotations...),
+		VarReference:      append(types.FsSlice(nil), t.VarReference...),
+		Images:            append(types.FsSlice(nil), t.Images...),
+		Replicas:          append(types.FsSlice(nil), t.Replicas...),
+	}
+	for _, n := range t.NameReference {
+		n.FieldSpecs = append(types.FsSlice(nil), n.FieldSpecs...)
+		c.NameReference = append(c.NameReference, n)
+	}
+	return c
+}
+
 // sortFields provides determinism in logging, tests, etc.
 func (t *TransformerConfig) sortFields() {
 	sort.Sort(t.NamePrefix)
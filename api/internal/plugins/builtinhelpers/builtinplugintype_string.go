@@ -24,11 +24,22 @@ func _() {
 	_ = x[SecretGenerator-13]
 	_ = x[ValueAddTransformer-14]
 	_ = x[HelmChartInflationGenerator-15]
+	_ = x[ChecksumTransformer-16]
+	_ = x[CleanForSourceTransformer-17]
+	_ = x[ApiVersionUpgradeTransformer-18]
+	_ = x[CompanionGenerator-19]
+	_ = x[RegistryRewriteTransformer-20]
+	_ = x[UniquenessValidator-21]
+	_ = x[NamespacePrefixSuffixTransformer-22]
+	_ = x[NormalizeListsTransformer-23]
+	_ = x[RemoveTransformer-24]
+	_ = x[FieldSetterTransformer-25]
+	_ = x[StructuredDataGenerator-26]
 }
 
-const _BuiltinPluginType_name = "UnknownAnnotationsTransformerConfigMapGeneratorHashTransformerImageTagTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplicaCountTransformerSecretGeneratorValueAddTransformerHelmChartInflationGenerator"
+const _BuiltinPluginType_name = "UnknownAnnotationsTransformerConfigMapGeneratorHashTransformerImageTagTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplicaCountTransformerSecretGeneratorValueAddTransformerHelmChartInflationGeneratorChecksumTransformerCleanForSourceTransformerApiVersionUpgradeTransformerCompanionGeneratorRegistryRewriteTransformerUniquenessValidatorNamespacePrefixSuffixTransformerNormalizeListsTransformerRemoveTransformerFieldSetterTransformerStructuredDataGenerator"
 
-var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 47, 62, 81, 97, 119, 139, 163, 193, 209, 232, 255, 270, 289, 316}
+var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 47, 62, 81, 97, 119, 139, 163, 193, 209, 232, 255, 270, 289, 316, 335, 360, 388, 406, 432, 451, 483, 508, 525, 547, 570}
 
 func (i BuiltinPluginType) String() string {
 	if i < 0 || i >= BuiltinPluginType(len(_BuiltinPluginType_index)-1) {
@@ -28,6 +28,17 @@ const (
 	SecretGenerator
 	ValueAddTransformer
 	HelmChartInflationGenerator
+	ChecksumTransformer
+	CleanForSourceTransformer
+	ApiVersionUpgradeTransformer
+	CompanionGenerator
+	RegistryRewriteTransformer
+	UniquenessValidator
+	NamespacePrefixSuffixTransformer
+	NormalizeListsTransformer
+	RemoveTransformer
+	FieldSetterTransformer
+	StructuredDataGenerator
 )
 
 var stringToBuiltinPluginTypeMap map[string]BuiltinPluginType
@@ -56,22 +67,33 @@ func GetBuiltinPluginType(n string) BuiltinPluginType {
 }
 
 var GeneratorFactories = map[BuiltinPluginType]func() resmap.GeneratorPlugin{
+	CompanionGenerator:          builtins.NewCompanionGeneratorPlugin,
 	ConfigMapGenerator:          builtins.NewConfigMapGeneratorPlugin,
 	SecretGenerator:             builtins.NewSecretGeneratorPlugin,
 	HelmChartInflationGenerator: builtins.NewHelmChartInflationGeneratorPlugin,
+	StructuredDataGenerator:     builtins.NewStructuredDataGeneratorPlugin,
 }
 
 var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin{
-	AnnotationsTransformer:         builtins.NewAnnotationsTransformerPlugin,
-	HashTransformer:                builtins.NewHashTransformerPlugin,
-	ImageTagTransformer:            builtins.NewImageTagTransformerPlugin,
-	LabelTransformer:               builtins.NewLabelTransformerPlugin,
-	LegacyOrderTransformer:         builtins.NewLegacyOrderTransformerPlugin,
-	NamespaceTransformer:           builtins.NewNamespaceTransformerPlugin,
-	PatchJson6902Transformer:       builtins.NewPatchJson6902TransformerPlugin,
-	PatchStrategicMergeTransformer: builtins.NewPatchStrategicMergeTransformerPlugin,
-	PatchTransformer:               builtins.NewPatchTransformerPlugin,
-	PrefixSuffixTransformer:        builtins.NewPrefixSuffixTransformerPlugin,
-	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
-	ValueAddTransformer:            builtins.NewValueAddTransformerPlugin,
+	AnnotationsTransformer:           builtins.NewAnnotationsTransformerPlugin,
+	ApiVersionUpgradeTransformer:     builtins.NewApiVersionUpgradeTransformerPlugin,
+	ChecksumTransformer:              builtins.NewChecksumTransformerPlugin,
+	CleanForSourceTransformer:        builtins.NewCleanForSourceTransformerPlugin,
+	FieldSetterTransformer:           builtins.NewFieldSetterTransformerPlugin,
+	HashTransformer:                  builtins.NewHashTransformerPlugin,
+	ImageTagTransformer:              builtins.NewImageTagTransformerPlugin,
+	LabelTransformer:                 builtins.NewLabelTransformerPlugin,
+	LegacyOrderTransformer:           builtins.NewLegacyOrderTransformerPlugin,
+	NamespaceTransformer:             builtins.NewNamespaceTransformerPlugin,
+	NamespacePrefixSuffixTransformer: builtins.NewNamespacePrefixSuffixTransformerPlugin,
+	NormalizeListsTransformer:        builtins.NewNormalizeListsTransformerPlugin,
+	PatchJson6902Transformer:         builtins.NewPatchJson6902TransformerPlugin,
+	PatchStrategicMergeTransformer:   builtins.NewPatchStrategicMergeTransformerPlugin,
+	PatchTransformer:                 builtins.NewPatchTransformerPlugin,
+	PrefixSuffixTransformer:          builtins.NewPrefixSuffixTransformerPlugin,
+	RegistryRewriteTransformer:       builtins.NewRegistryRewriteTransformerPlugin,
+	RemoveTransformer:                builtins.NewRemoveTransformerPlugin,
+	ReplicaCountTransformer:          builtins.NewReplicaCountTransformerPlugin,
+	UniquenessValidator:              builtins.NewUniquenessValidatorPlugin,
+	ValueAddTransformer:              builtins.NewValueAddTransformerPlugin,
 }
@@ -5,18 +5,24 @@ package execplugin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/google/shlex"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/yaml"
 )
 
@@ -40,12 +46,47 @@ type ExecPlugin struct {
 
 	// PluginHelpers
 	h *resmap.PluginHelpers
+
+	// True if a generator's config requests the
+	// accumulated resources on stdin.
+	wantsContext bool
+
+	// The build the plugin runs in.
+	bc BuildContext
+}
+
+// BuildContext describes the build that runs an exec
+// plugin, for the environment the plugin runs in.
+type BuildContext struct {
+	// Root is the root of the top-level kustomization.
+	Root string
+
+	// Features are the enabled features of the build,
+	// named by the konfig Feature constants.
+	Features []string
+
+	// Env says which variables of kustomize's own
+	// environment the plugin inherits.
+	Env types.ExecPluginEnv
+
+	// Limits bound the resources each run of the plugin
+	// may use.
+	Limits types.PluginLimits
+
+	// Context, if not nil, stops the plugin when done,
+	// e.g. when the build is canceled.
+	Context context.Context
 }
 
 func NewExecPlugin(p string) *ExecPlugin {
 	return &ExecPlugin{path: p}
 }
 
+// SetBuildContext sets the build the plugin runs in.
+func (p *ExecPlugin) SetBuildContext(bc BuildContext) {
+	p.bc = bc
+}
+
 func (p *ExecPlugin) ErrIfNotExecutable() error {
 	f, err := os.Stat(p.path)
 	if err != nil {
@@ -79,9 +120,22 @@ func (p *ExecPlugin) Cfg() []byte {
 func (p *ExecPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
 	p.h = h
 	p.cfg = config
+	p.wantsContext = p.configRequestsContext()
 	return p.processOptionalArgsFields()
 }
 
+type metadataConfig struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+func (p *ExecPlugin) configRequestsContext() bool {
+	var c metadataConfig
+	yaml.Unmarshal(p.cfg, &c)
+	return c.Metadata.Annotations[konfig.GeneratorContextAnnotation] == "true"
+}
+
 type argsConfig struct {
 	ArgsOneLiner string `json:"argsOneLiner,omitempty" yaml:"argsOneLiner,omitempty"`
 	ArgsFromFile string `json:"argsFromFile,omitempty" yaml:"argsFromFile,omitempty"`
@@ -109,13 +163,30 @@ func (p *ExecPlugin) processOptionalArgsFields() error {
 }
 
 func (p *ExecPlugin) Generate() (resmap.ResMap, error) {
-	output, err := p.invokePlugin(nil)
+	return p.GenerateWithContext(nil)
+}
+
+// GenerateWithContext implements resmap.ContextualGenerator.
+// The resources are written to the plugin's stdin, framed as
+// for a transformer, only if its config requests them via
+// konfig.GeneratorContextAnnotation; otherwise stdin is empty.
+func (p *ExecPlugin) GenerateWithContext(
+	accumulated resmap.ResMap) (resmap.ResMap, error) {
+	var input []byte
+	if p.wantsContext && accumulated != nil {
+		var err error
+		input, err = accumulated.AsYaml()
+		if err != nil {
+			return nil, err
+		}
+	}
+	output, err := p.invokePlugin(input)
 	if err != nil {
 		return nil, err
 	}
 	rm, err := p.h.ResmapFactory().NewResMapFromBytes(output)
 	if err != nil {
-		return nil, err
+		return nil, kusterr.WithSource(err, "output of plugin "+p.path)
 	}
 	return utils.UpdateResourceOptions(rm)
 }
@@ -165,25 +236,52 @@ func (p *ExecPlugin) invokePlugin(input []byte) ([]byte, error) {
 	//nolint:gosec
 	cmd := exec.Command(
 		p.path, append([]string{f.Name()}, p.args...)...)
-	cmd.Env = p.getEnv()
+	cmd.Env = p.getEnv(f.Name())
 	cmd.Stdin = bytes.NewReader(input)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = os.Stderr
 	if _, err := os.Stat(p.h.Loader().Root()); err == nil {
 		cmd.Dir = p.h.Loader().Root()
 	}
-	result, err := cmd.Output()
+	err = runtimeexec.Run(p.bc.Context, cmd, p.name(), p.limits())
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "failure in plugin configured via %s; %v",
 			f.Name(), err.Error())
 	}
-	return result, os.Remove(f.Name())
+	return stdout.Bytes(), os.Remove(f.Name())
 }
 
-func (p *ExecPlugin) getEnv() []string {
-	env := os.Environ()
+// name identifies the plugin in errors, by its kind.
+func (p *ExecPlugin) name() string {
+	return "plugin " + filepath.Base(p.path)
+}
+
+func (p *ExecPlugin) limits() runtimeexec.Limits {
+	l := p.bc.Limits.Effective()
+	return runtimeexec.Limits{
+		Timeout:        l.Timeout,
+		MaxOutputBytes: l.MaxOutputBytes,
+	}
+}
+
+// getEnv returns the environment of the plugin, given
+// the path of the file holding its config: the inherited
+// variables, and those documented in konfig.
+func (p *ExecPlugin) getEnv(configPath string) []string {
+	var env []string
+	for _, v := range os.Environ() {
+		if p.bc.Env.Inherits(strings.SplitN(v, "=", 2)[0]) {
+			env = append(env, v)
+		}
+	}
 	env = append(env,
-		"KUSTOMIZE_PLUGIN_CONFIG_STRING="+string(p.cfg),
-		"KUSTOMIZE_PLUGIN_CONFIG_ROOT="+p.h.Loader().Root())
+		konfig.ExecPluginConfigPathEnv+"="+configPath,
+		konfig.ExecPluginConfigStringEnv+"="+string(p.cfg),
+		konfig.ExecPluginConfigRootEnv+"="+p.h.Loader().Root(),
+		konfig.ExecPluginBuildRootEnv+"="+p.bc.Root,
+		konfig.ExecPluginFeaturesEnv+"="+strings.Join(p.bc.Features, ","),
+		konfig.ExecPluginProtocolVersionEnv+"="+konfig.ExecPluginProtocolVersion)
 	return env
 }
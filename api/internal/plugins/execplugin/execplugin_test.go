@@ -4,6 +4,8 @@
 package execplugin_test
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -17,6 +19,7 @@ import (
 	fLdr "sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 func TestExecPluginConfig(t *testing.T) {
@@ -60,7 +63,8 @@ s/$BAR/bar baz/g
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	p.Config(resmap.NewPluginHelpers(ldr, pvd.GetFieldValidator(), rf), yaml)
+	p.Config(resmap.NewPluginHelpers(
+		ldr, pvd.GetFieldValidator(), rf, resmap.NewSchema(nil)), yaml)
 
 	expected := "someteam.example.com/v1/sedtransformer/SedTransformer"
 	if !strings.HasSuffix(p.Path(), expected) {
@@ -119,3 +123,213 @@ func TestExecPlugin_ErrIfNotExecutable(t *testing.T) {
 		t.Fatalf("unexpected err: %v", err)
 	}
 }
+
+// A generator that reports the names of the
+// resources it received on stdin.
+const nameListingGenerator = `#!/bin/bash
+names=$(grep '^  name:' | sed 's/^  name: //' | tr '\n' ' ')
+echo "apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: seen
+data:
+  names: '$names'
+  version: '$KUSTOMIZE_PLUGIN_PROTOCOL_VERSION'"
+`
+
+func TestExecPluginGenerateWithContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skipf("plugin is a bash script")
+	}
+	dir, err := ioutil.TempDir("", "kust-exec-plugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "NameLister")
+	err = ioutil.WriteFile(path, []byte(nameListingGenerator), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	accumulated, err := rf.NewResMapFromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		config   string
+		expected string
+	}{
+		"no context by default": {
+			config: `
+apiVersion: someteam.example.com/v1
+kind: NameLister
+metadata:
+  name: lister
+`,
+			expected: "",
+		},
+		"context requested": {
+			config: `
+apiVersion: someteam.example.com/v1
+kind: NameLister
+metadata:
+  name: lister
+  annotations:
+    kustomize.config.k8s.io/generator-context: "true"
+`,
+			expected: "web db ",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := NewExecPlugin(path)
+			err := p.Config(resmap.NewPluginHelpers(
+				ldr, pvd.GetFieldValidator(), rf, resmap.NewSchema(nil)), []byte(tc.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rm, err := p.GenerateWithContext(accumulated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rm.Size() != 1 {
+				t.Fatalf("expected one resource, got %d", rm.Size())
+			}
+			data := rm.GetByIndex(0).GetDataMap()
+			if data["names"] != tc.expected {
+				t.Fatalf("expected names '%s', got '%s'",
+					tc.expected, data["names"])
+			}
+			if data["version"] != konfig.ExecPluginProtocolVersion {
+				t.Fatalf("unexpected version '%s'", data["version"])
+			}
+		})
+	}
+	// The accumulated resources are untouched.
+	if accumulated.Size() != 2 {
+		t.Fatalf("unexpected size %d", accumulated.Size())
+	}
+}
+
+func TestExecPluginGenerateMissingName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skipf("plugin is a bash script")
+	}
+	dir, err := ioutil.TempDir("", "kust-exec-plugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "Nameless")
+	err = ioutil.WriteFile(path, []byte(`#!/bin/bash
+echo "apiVersion: v1
+kind: ConfigMap
+data:
+  a: b"
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	p := NewExecPlugin(path)
+	err = p.Config(resmap.NewPluginHelpers(
+		ldr, pvd.GetFieldValidator(), rf, resmap.NewSchema(nil)), []byte(`
+apiVersion: someteam.example.com/v1
+kind: Nameless
+metadata:
+  name: nameless
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Generate()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	expected := "document 0 in output of plugin " + path +
+		", of kind ConfigMap, has no metadata.name"
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error containing '%s', got '%s'",
+			expected, err.Error())
+	}
+}
+
+func TestExecPluginMaxOutputBytes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skipf("plugin is a bash script")
+	}
+	dir, err := ioutil.TempDir("", "kust-exec-plugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "Chatty")
+	err = ioutil.WriteFile(path, []byte(`#!/bin/bash
+yes
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	p := NewExecPlugin(path)
+	p.SetBuildContext(BuildContext{
+		Limits: types.PluginLimits{MaxOutputBytes: 1000},
+	})
+	err = p.Config(resmap.NewPluginHelpers(
+		ldr, pvd.GetFieldValidator(), rf, resmap.NewSchema(nil)), []byte(`
+apiVersion: someteam.example.com/v1
+kind: Chatty
+metadata:
+  name: chatty
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Generate()
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(), "plugin Chatty exceeded its "+
+		"output limit of 1000 bytes, and was killed; "+
+		"its output ended with:\ny\ny\n") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -5,14 +5,17 @@ package fnplugin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
 
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/utils"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -82,6 +85,19 @@ func NewFnPlugin(o *types.FnPluginLoadingOptions) *FnPlugin {
 	}
 }
 
+// SetLimits bounds the resources that each run of a
+// container or exec function may use, and sets the
+// context, if any, that stops such runs when done.
+func (p *FnPlugin) SetLimits(ctx context.Context, l types.PluginLimits) {
+	l = l.Effective()
+	p.runFns.Context = ctx
+	p.runFns.Limits = runtimeexec.Limits{
+		Timeout:        l.Timeout,
+		MaxOutputBytes: l.MaxOutputBytes,
+		Memory:         l.Memory,
+	}
+}
+
 // Cfg returns function config
 func (p *FnPlugin) Cfg() []byte {
 	return p.cfg
@@ -116,7 +132,8 @@ func (p *FnPlugin) Generate() (resmap.ResMap, error) {
 	}
 	rm, err := p.h.ResmapFactory().NewResMapFromBytes(output)
 	if err != nil {
-		return nil, err
+		return nil, kusterr.WithSource(
+			err, "output of plugin "+p.pluginName)
 	}
 	return utils.UpdateResourceOptions(rm)
 }
@@ -4,6 +4,7 @@
 package loader
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"plugin"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -29,6 +31,15 @@ import (
 type Loader struct {
 	pc *types.PluginConfig
 	rf *resmap.Factory
+
+	// The root of the top-level kustomization, and the
+	// enabled features, of the build, for exec plugins.
+	buildRoot string
+	features  []string
+
+	// The context of the build, which stops exec plugins
+	// and functions when done.
+	ctx context.Context
 }
 
 func NewLoader(
@@ -36,6 +47,18 @@ func NewLoader(
 	return &Loader{pc: pc, rf: rf}
 }
 
+// SetBuildContext sets the context, the root of the
+// top-level kustomization, and the enabled features, of
+// the build the loaded plugins run in.  The context stops
+// exec plugins and functions when done; the root and the
+// features are for the environment of exec plugins.
+func (l *Loader) SetBuildContext(
+	ctx context.Context, root string, features []string) {
+	l.ctx = ctx
+	l.buildRoot = root
+	l.features = features
+}
+
 func (l *Loader) LoadGenerators(
 	ldr ifc.Loader, v ifc.Validator, rm resmap.ResMap) ([]resmap.Generator, error) {
 	var result []resmap.Generator
@@ -148,7 +171,8 @@ func (l *Loader) loadAndConfigurePlugin(
 	if err != nil {
 		return nil, errors.Wrapf(err, "marshalling yaml from res %s", res.OrgId())
 	}
-	err = c.Config(resmap.NewPluginHelpers(ldr, v, l.rf), yaml)
+	err = c.Config(resmap.NewPluginHelpers(
+		ldr, v, l.rf, resmap.NewSchema(l.rf.RF().OpenAPISchemas())), yaml)
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "plugin %s fails configuration", res.OrgId())
@@ -170,7 +194,9 @@ func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 func (l *Loader) loadPlugin(res *resource.Resource) (resmap.Configurable, error) {
 	spec := fnplugin.GetFunctionSpec(res)
 	if spec != nil {
-		return fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions), nil
+		p := fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions)
+		p.SetLimits(l.ctx, l.pc.Limits)
+		return p, nil
 	}
 	return l.loadExecOrGoPlugin(res.OrgId())
 }
@@ -180,6 +206,13 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 	p := execplugin.NewExecPlugin(l.absolutePluginPath(resId))
 	err := p.ErrIfNotExecutable()
 	if err == nil {
+		p.SetBuildContext(execplugin.BuildContext{
+			Root:     l.buildRoot,
+			Features: l.features,
+			Env:      l.pc.ExecPluginEnv,
+			Limits:   l.pc.Limits,
+			Context:  l.ctx,
+		})
 		return p, nil
 	}
 	if !os.IsNotExist(err) {
@@ -206,14 +239,25 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 // but the loaded .so files are in shared memory, so one will get
 // "this plugin already loaded" errors if the registry is maintained
 // as a Loader instance variable.  So make it a package variable.
-var registry = make(map[string]resmap.Configurable)
+// It's keyed by the absolute path of the .so file, so that
+// loaders with different plugin homes, e.g. of builds running
+// at once, don't get each other's plugins, and it's guarded by
+// registryMu, as such builds load plugins concurrently.  The
+// registry holds only the loaded symbols; each load returns a
+// copy, which the loader then configures.
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]resmap.Configurable)
+)
 
 func (l *Loader) loadGoPlugin(id resid.ResId) (resmap.Configurable, error) {
 	regId := relativePluginPath(id)
-	if c, ok := registry[regId]; ok {
+	absPath := l.absolutePluginPath(id) + ".so"
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if c, ok := registry[absPath]; ok {
 		return copyPlugin(c), nil
 	}
-	absPath := l.absolutePluginPath(id) + ".so"
 	if !utils.FileExists(absPath) {
 		return nil, fmt.Errorf(
 			"expected file with Go object code at: %s", absPath)
@@ -233,7 +277,7 @@ func (l *Loader) loadGoPlugin(id resid.ResId) (resmap.Configurable, error) {
 	if !ok {
 		return nil, fmt.Errorf("plugin '%s' not configurable", regId)
 	}
-	registry[regId] = c
+	registry[absPath] = c
 	return copyPlugin(c), nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
@@ -139,7 +140,9 @@ func GetResMapWithIDAnnotation(rm resmap.ResMap) (resmap.ResMap, error) {
 			annotations = make(map[string]string)
 		}
 		annotations[idAnnotation] = string(idString)
-		r.SetAnnotations(annotations)
+		if err = r.SetAnnotationsE(annotations); err != nil {
+			return nil, err
+		}
 	}
 	return inputRM, nil
 }
@@ -149,7 +152,7 @@ func GetResMapWithIDAnnotation(rm resmap.ResMap) (resmap.ResMap, error) {
 func UpdateResMapValues(pluginName string, h *resmap.PluginHelpers, output []byte, rm resmap.ResMap) error {
 	outputRM, err := h.ResmapFactory().NewResMapFromBytes(output)
 	if err != nil {
-		return err
+		return kusterr.WithSource(err, "output of plugin "+pluginName)
 	}
 	for _, r := range outputRM.Resources() {
 		// for each emitted Resource, find the matching Resource in the original ResMap
@@ -174,7 +177,9 @@ func UpdateResMapValues(pluginName string, h *resmap.PluginHelpers, output []byt
 		if len(annotations) == 0 {
 			annotations = nil
 		}
-		r.SetAnnotations(annotations)
+		if err = r.SetAnnotationsE(annotations); err != nil {
+			return err
+		}
 
 		// update the resource value with the transformed object
 		res.ResetPrimaryData(r)
@@ -205,7 +210,9 @@ func UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, error) {
 		if len(annotations) == 0 {
 			annotations = nil
 		}
-		r.SetAnnotations(annotations)
+		if err := r.SetAnnotationsE(annotations); err != nil {
+			return nil, err
+		}
 		r.SetOptions(types.NewGenArgs(
 			&types.GeneratorArgs{
 				Behavior: behavior,
@@ -0,0 +1,238 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filters/fsslice"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// commonMetadataRemover removes the keys that a
+// kustomization's removeCommonLabels and
+// removeCommonAnnotations list from the resources of its
+// bases and components, where the commonLabels and
+// commonAnnotations of those put them.
+type commonMetadataRemover struct {
+	labels           []string
+	annotations      []string
+	labelFields      types.FsSlice
+	annotationFields types.FsSlice
+	rmF              *resmap.Factory
+	kustFile         string
+}
+
+var _ resmap.Transformer = &commonMetadataRemover{}
+
+// Transform removes the keys where the ProvenanceTable of
+// the factory says a LabelTransformer or AnnotationsTransformer
+// wrote them, leaving alone the labels and annotations the
+// resources had already, e.g. one a user wrote with the
+// value a base's commonLabels gives it.  Without a table,
+// it removes the keys from every field the transformers
+// write, with a warning.
+func (o *commonMetadataRemover) Transform(m resmap.ResMap) error {
+	t := o.rmF.ProvenanceTable()
+	if t == nil {
+		o.rmF.AddWarning(fmt.Sprintf(
+			"%s: provenance isn't tracked, so removeCommonLabels and "+
+				"removeCommonAnnotations remove their keys from every "+
+				"resource, not just where a base's commonLabels or "+
+				"commonAnnotations put them", o.kustFile))
+		for _, r := range m.Resources() {
+			if err := removeKeys(r, o.labels, o.labelFields); err != nil {
+				return err
+			}
+			err := removeKeys(r, o.annotations, o.annotationFields)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	removedLabels := make(map[string]bool)
+	for _, r := range m.Resources() {
+		for _, key := range o.labels {
+			removed, err := removeAddedKey(
+				t, r, key, builtinhelpers.LabelTransformer.String())
+			if err != nil {
+				return err
+			}
+			if removed {
+				removedLabels[key] = true
+			}
+		}
+		for _, key := range o.annotations {
+			_, err := removeAddedKey(
+				t, r, key, builtinhelpers.AnnotationsTransformer.String())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return o.checkSelectors(m, removedLabels)
+}
+
+// checkSelectors returns an error if a label removed from
+// some fields stays in a selector, where no base's
+// commonLabels put it, as the selector could then stop
+// matching the pods it's meant to select.
+func (o *commonMetadataRemover) checkSelectors(
+	m resmap.ResMap, removedLabels map[string]bool) error {
+	for _, fs := range o.labelFields {
+		if !strings.Contains(fs.Path, "selector") {
+			continue
+		}
+		fs.CreateIfNotPresent = false
+		for _, r := range m.Resources() {
+			for _, key := range o.labels {
+				if !removedLabels[key] {
+					continue
+				}
+				kept := false
+				err := r.ApplyFilter(kio.FilterAll(fsslice.Filter{
+					FsSlice: types.FsSlice{fs},
+					SetValue: func(node *yaml.RNode) error {
+						if node.YNode().Kind == yaml.MappingNode &&
+							node.Field(key) != nil {
+							kept = true
+						}
+						return nil
+					},
+				}))
+				if err != nil {
+					return err
+				}
+				if kept {
+					return fmt.Errorf(
+						"%s: removeCommonLabels removes label '%s', but %s "+
+							"keeps it in its selector at %s, where no base's "+
+							"commonLabels put it, so the selector could stop "+
+							"matching the pods it selects; remove the label "+
+							"from the selector with a patch, or keep it",
+						o.kustFile, key, r.CurId(), fs.Path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// removeAddedKey removes the key from each map of the
+// resource in which the table says the given builtin
+// transformer wrote it, reporting whether it removed any.
+func removeAddedKey(t *resmap.ProvenanceTable,
+	r *resource.Resource, key, transformer string) (bool, error) {
+	removed := false
+	for _, path := range t.Paths(r) {
+		if !strings.HasSuffix(path, "/"+key) {
+			continue
+		}
+		if src, _ := t.Lookup(r, path); src.Transformer != transformer {
+			continue
+		}
+		parent := strings.Split(strings.TrimSuffix(path, "/"+key), "/")
+		err := r.ApplyFilter(kio.FilterAll(yaml.FilterFunc(
+			func(node *yaml.RNode) (*yaml.RNode, error) {
+				// Paths are slash separated, as may keys be, so
+				// a path may end in the key without its parent
+				// being a map holding it.
+				m, err := node.Pipe(yaml.Lookup(parent...))
+				if err != nil || m == nil ||
+					m.YNode().Kind != yaml.MappingNode {
+					return node, nil
+				}
+				cleared, err := m.Pipe(yaml.Clear(key))
+				if err != nil || cleared == nil {
+					return node, err
+				}
+				removed = true
+				t.Forget(r, path)
+				if len(m.Content()) > 0 || len(parent) < 2 ||
+					!dropsWhenEmpty(parent[len(parent)-1]) {
+					return node, nil
+				}
+				// As if the resource never had the map.
+				_, err = node.Pipe(
+					yaml.Lookup(parent[:len(parent)-1]...),
+					yaml.Clear(parent[len(parent)-1]))
+				return node, err
+			})))
+		if err != nil {
+			return false, err
+		}
+	}
+	return removed, nil
+}
+
+// removeKeys removes the keys from each of the fields of
+// the resource that the FsSlice identifies.  A labels or
+// annotations field the removal empties is removed too.
+func removeKeys(
+	r *resource.Resource, keys []string, fields types.FsSlice) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, fs := range fields {
+		fs.CreateIfNotPresent = false
+		// The name of the field holding the keys, if it's
+		// to be removed once empty, so the filter gets the
+		// map holding it.
+		var field string
+		if i := strings.LastIndex(fs.Path, "/"); i > 0 &&
+			dropsWhenEmpty(fs.Path[i+1:]) {
+			field = fs.Path[i+1:]
+			fs.Path = fs.Path[:i]
+		}
+		err := r.ApplyFilter(kio.FilterAll(fsslice.Filter{
+			FsSlice: types.FsSlice{fs},
+			SetValue: func(node *yaml.RNode) error {
+				m := node
+				if field != "" {
+					if node.YNode().Kind != yaml.MappingNode ||
+						node.Field(field) == nil {
+						return nil
+					}
+					m = node.Field(field).Value
+				}
+				if m.YNode().Kind != yaml.MappingNode {
+					return nil
+				}
+				removed := false
+				for _, key := range keys {
+					cleared, err := m.Pipe(yaml.Clear(key))
+					if err != nil {
+						return err
+					}
+					if cleared != nil {
+						removed = true
+					}
+				}
+				if field == "" || !removed || len(m.Content()) > 0 {
+					return nil
+				}
+				// As if the resource never had the map.
+				_, err := node.Pipe(yaml.Clear(field))
+				return err
+			},
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropsWhenEmpty returns true if a field of the given name,
+// once removing keys empties it, is removed too.
+func dropsWhenEmpty(field string) bool {
+	return field == "labels" || field == "annotations"
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// targetedPatch is a patch whose targets can be selected
+// before it's applied, e.g. a PatchTransformer.
+type targetedPatch interface {
+	Targets(m resmap.ResMap) ([]*resource.Resource, error)
+	TransformTargets(m resmap.ResMap, targets []*resource.Resource) error
+}
+
+// dependencyOrderedPatches applies the entries of a
+// kustomization's patches field in types.DependencyPatchOrder.
+type dependencyOrderedPatches struct {
+	patches []resmap.Transformer
+	rmF     *resmap.Factory
+}
+
+var _ resmap.Transformer = &dependencyOrderedPatches{}
+
+// newDependencyOrderedPatches constructs a dependencyOrderedPatches
+// for the PatchTransformers configured from the patches field,
+// in list order.
+func newDependencyOrderedPatches(
+	patches []resmap.Transformer, rmF *resmap.Factory) resmap.Transformer {
+	return &dependencyOrderedPatches{patches: patches, rmF: rmF}
+}
+
+// Transform selects the targets of all the patches, then
+// applies those that selected some, in order, then applies
+// the rest in order, each to the targets it selects as the
+// patches before it left the resources.
+func (o *dependencyOrderedPatches) Transform(m resmap.ResMap) error {
+	targets := make([][]*resource.Resource, len(o.patches))
+	for i, t := range o.patches {
+		if p, ok := t.(targetedPatch); ok {
+			// An error here, e.g. no match for a patch without
+			// a target, may be cured by the other patches, so
+			// it's reported only if it persists below.
+			targets[i], _ = p.Targets(m)
+		}
+	}
+	var deferred []int
+	for i, t := range o.patches {
+		p, ok := t.(targetedPatch)
+		if !ok || len(targets[i]) == 0 {
+			deferred = append(deferred, i)
+			continue
+		}
+		if err := p.TransformTargets(m, stillIn(m, targets[i])); err != nil {
+			return err
+		}
+	}
+	for _, i := range deferred {
+		p, ok := o.patches[i].(targetedPatch)
+		if !ok {
+			if err := o.patches[i].Transform(m); err != nil {
+				return err
+			}
+			continue
+		}
+		selected, err := p.Targets(m)
+		if err != nil {
+			return err
+		}
+		for _, r := range selected {
+			o.rmF.AddPluginResult(resmap.PluginResult{
+				Plugin:   "PatchTransformer",
+				Resource: r.CurId(),
+				Message: fmt.Sprintf(
+					"patches[%d] selected no resources before the "+
+						"patches applied, so it was applied after the others", i),
+			})
+		}
+		if err = p.TransformTargets(m, selected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stillIn returns those of the resources still in m, as
+// a patch may have deleted some.
+func stillIn(m resmap.ResMap, rs []*resource.Resource) []*resource.Resource {
+	in := make(map[*resource.Resource]bool)
+	for _, r := range m.Resources() {
+		in[r] = true
+	}
+	var result []*resource.Resource
+	for _, r := range rs {
+		if in[r] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
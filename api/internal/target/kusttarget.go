@@ -6,6 +6,9 @@ package target
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
@@ -13,11 +16,15 @@ import (
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/konfig"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -29,6 +36,26 @@ type KustTarget struct {
 	validator     ifc.Validator
 	rFactory      *resmap.Factory
 	pLdr          *loader.Loader
+	defaultConfig *builtinconfig.TransformerConfig
+	capabilities  *Capabilities
+	// References to these are never updated.
+	externalNames []resid.ResId
+	// The path of the kustomization file, once loaded.
+	kustFile string
+	// The resources read on stdin, for the resources entry
+	// types.StdinResourcesPath.  Only the top-level target
+	// has them, and reads them at most once.
+	stdin     io.Reader
+	stdinRead bool
+	// True for the target of a base or component.
+	nested bool
+	// True if a kustomization including this one sets
+	// enforceClusterScopedOnly.
+	clusterScopedOnly bool
+	// Counts the files and generators the build has read
+	// resources from, to record the FilePosition of each.
+	// The targets of bases and components share it.
+	fileCount *int
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -42,15 +69,40 @@ func NewKustTarget(
 		validator: validator,
 		rFactory:  rFactory,
 		pLdr:      pLdr,
+		fileCount: new(int),
 	}
 }
 
+// SetDefaultTransformerConfig sets the config that the
+// target, and every kustomization it recursively includes,
+// uses in place of the builtin default config.  Config
+// files named in a kustomization are merged into it.
+func (kt *KustTarget) SetDefaultTransformerConfig(
+	c *builtinconfig.TransformerConfig) {
+	kt.defaultConfig = c
+}
+
+// SetExternalNames sets the ids of resources outside the
+// build, references to which the target, and every
+// kustomization it recursively includes, never updates,
+// though a resource in the build has the same name.
+func (kt *KustTarget) SetExternalNames(ids []resid.ResId) {
+	kt.externalNames = ids
+}
+
+// SetStdinResources sets the reader of the resources the
+// target's kustomization may list as types.StdinResourcesPath.
+func (kt *KustTarget) SetStdinResources(r io.Reader) {
+	kt.stdin = r
+}
+
 // Load attempts to load the target's kustomization file.
 func (kt *KustTarget) Load() error {
-	content, err := loadKustFile(kt.ldr)
+	content, kustFile, err := loadKustFile(kt.ldr)
 	if err != nil {
 		return err
 	}
+	kt.kustFile = filepath.Join(kt.ldr.Root(), kustFile)
 	content, err = types.FixKustomizationPreUnmarshalling(content)
 	if err != nil {
 		return err
@@ -67,6 +119,15 @@ func (kt *KustTarget) Load() error {
 			"Failed to read kustomization file under %s:\n"+
 				strings.Join(errs, "\n"), kt.ldr.Root())
 	}
+	if err = validateGeneratorArgs(&k, kt.kustFile); err != nil {
+		return err
+	}
+	if err = resolveRepositoryAliases(&k); err != nil {
+		return errors.Wrapf(err, "in %s", kt.kustFile)
+	}
+	if err = fLdr.SetIgnorePatterns(kt.ldr, k.IgnorePatterns); err != nil {
+		return errors.Wrapf(err, "ignorePatterns in %s", kt.kustFile)
+	}
 	kt.kustomization = &k
 	return nil
 }
@@ -79,23 +140,30 @@ func (kt *KustTarget) Kustomization() types.Kustomization {
 	return result
 }
 
-func loadKustFile(ldr ifc.Loader) ([]byte, error) {
+// loadKustFile returns the content and
+// the name of the kustomization file.
+func loadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	var content []byte
+	var name string
 	match := 0
 	for _, kf := range konfig.RecognizedKustomizationFileNames() {
 		c, err := ldr.Load(kf)
 		if err == nil {
 			match += 1
 			content = c
+			name = kf
+		}
+		if _, ok := err.(kusterr.LimitError); ok {
+			return nil, "", err
 		}
 	}
 	switch match {
 	case 0:
-		return nil, NewErrMissingKustomization(ldr.Root())
+		return nil, "", NewErrMissingKustomization(ldr.Root())
 	case 1:
-		return content, nil
+		return content, name, nil
 	default:
-		return nil, fmt.Errorf(
+		return nil, "", fmt.Errorf(
 			"Found multiple kustomization files under: %s\n", ldr.Root())
 	}
 }
@@ -122,7 +190,14 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 
 	// Given that names have changed (prefixs/suffixes added),
 	// fix all the back references to those names.
-	err = ra.FixBackReferences()
+	err = ra.FixBackReferences(kt.externalNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// With names final, generated resources can label the
+	// resources that refer to them.
+	err = ra.PropagateLabelsToConsumers()
 	if err != nil {
 		return nil, err
 	}
@@ -159,16 +234,25 @@ func (kt *KustTarget) AccumulateTarget() (
 // (or empty if the Component does not have a parent).
 func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	resRa *accumulator.ResAccumulator, err error) {
+	if err = kt.checkRequirements(); err != nil {
+		return nil, err
+	}
+	if err = kt.errIfNamespaceFields(); err != nil {
+		return nil, err
+	}
 	ra, err = kt.accumulateResources(ra, kt.kustomization.Resources)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
 	}
+	err = kt.accumulateInstances(ra)
+	if err != nil {
+		return nil, errors.Wrap(err, "accumulating instances")
+	}
 	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating components")
 	}
-	tConfig, err := builtinconfig.MakeTransformerConfig(
-		kt.ldr, kt.kustomization.Configurations)
+	tConfig, err := kt.makeTransformerConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -191,14 +275,29 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	if err != nil {
 		return nil, err
 	}
+	made := ra.ResMap().Size()
 	err = kt.runTransformers(ra)
 	if err != nil {
 		return nil, err
 	}
+	if made > 0 && ra.ResMap().Size() == 0 {
+		// E.g. a patch deleting what it was meant to change,
+		// which the output would otherwise only show as
+		// missing.
+		kt.rFactory.AddWarning(fmt.Sprintf(
+			"the transformers of %s removed all %d of its resources",
+			kt.kustFile, made))
+	}
 	err = kt.runValidators(ra)
 	if err != nil {
 		return nil, err
 	}
+	// Transformer plugins may have set namespaces.
+	err = kt.errIfNamespaced(
+		ra.ResMap(), fmt.Sprintf("the output of %s", kt.kustFile))
+	if err != nil {
+		return nil, err
+	}
 	err = ra.MergeVars(kt.kustomization.Vars)
 	if err != nil {
 		return nil, errors.Wrapf(
@@ -207,8 +306,50 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	return ra, nil
 }
 
+// validateGeneratorArgs checks the entries of the
+// kustomization's configMapGenerator and secretGenerator
+// fields, before any of their files are read, reporting
+// every problem found at once.
+func validateGeneratorArgs(k *types.Kustomization, kustFile string) error {
+	var problems []string
+	check := func(field string, i int, args types.GeneratorArgs) {
+		err := args.Validate()
+		if err == nil {
+			return
+		}
+		for _, p := range err.(*types.GeneratorArgsError).Problems {
+			problems = append(problems, fmt.Sprintf("%s[%d].%s", field, i, p))
+		}
+	}
+	for i, args := range k.ConfigMapGenerator {
+		check("configMapGenerator", i, args.GeneratorArgs)
+	}
+	for i, args := range k.SecretGenerator {
+		check("secretGenerator", i, args.GeneratorArgs)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid generator entries in %s:\n  %s",
+		kustFile, strings.Join(problems, "\n  "))
+}
+
+func (kt *KustTarget) makeTransformerConfig() (
+	*builtinconfig.TransformerConfig, error) {
+	if kt.defaultConfig == nil {
+		return builtinconfig.MakeTransformerConfig(
+			kt.ldr, kt.kustomization.Configurations)
+	}
+	return builtinconfig.MakeTransformerConfigOver(
+		kt.defaultConfig.DeepCopy(), kt.ldr, kt.kustomization.Configurations)
+}
+
 func (kt *KustTarget) runGenerators(
 	ra *accumulator.ResAccumulator) error {
+	err := ra.AddGeneratorEntries(kt.generatorEntries())
+	if err != nil {
+		return err
+	}
 	var generators []resmap.Generator
 	gs, err := kt.configureBuiltinGenerators()
 	if err != nil {
@@ -221,16 +362,56 @@ func (kt *KustTarget) runGenerators(
 	}
 	generators = append(generators, gs...)
 	for _, g := range generators {
-		resMap, err := g.Generate()
+		var resMap resmap.ResMap
+		if cg, ok := g.(resmap.ContextualGenerator); ok {
+			resMap, err = cg.GenerateWithContext(ra.ResMap())
+		} else {
+			resMap, err = g.Generate()
+		}
+		if err != nil {
+			return kusterr.WithSource(
+				err, fmt.Sprintf("output of generator %T", g))
+		}
+		err = kt.errIfNamespaced(resMap, fmt.Sprintf(
+			"the output of generator %T of %s", g, kt.kustFile))
 		if err != nil {
 			return err
 		}
+		kt.recordFilePositions(resMap)
 		err = ra.AbsorbAll(resMap)
 		if err != nil {
 			return errors.Wrapf(err, "merging from generator %v", g)
 		}
 	}
-	return nil
+	return kt.attachPullSecrets(ra.ResMap())
+}
+
+// generatorEntries describes the entries of the
+// kustomization's configMapGenerator and secretGenerator
+// fields, in order.
+func (kt *KustTarget) generatorEntries() []accumulator.GeneratorEntry {
+	var result []accumulator.GeneratorEntry
+	for _, args := range kt.kustomization.ConfigMapGenerator {
+		result = append(result, kt.generatorEntry(
+			"configMapGenerator", "ConfigMap", args.GeneratorArgs))
+	}
+	for _, args := range kt.kustomization.SecretGenerator {
+		result = append(result, kt.generatorEntry(
+			"secretGenerator", "Secret", args.GeneratorArgs))
+	}
+	return result
+}
+
+func (kt *KustTarget) generatorEntry(
+	field, kind string, args types.GeneratorArgs) accumulator.GeneratorEntry {
+	return accumulator.GeneratorEntry{
+		Field: field,
+		File:  kt.kustFile,
+		Id: resid.NewResIdWithNamespace(
+			resid.Gvk{Version: "v1", Kind: kind},
+			args.Name, args.Namespace),
+		Behavior: types.NewGenerationBehavior(args.Behavior),
+	}
 }
 
 func (kt *KustTarget) configureExternalGenerators() ([]resmap.Generator, error) {
@@ -256,6 +437,19 @@ func (kt *KustTarget) configureExternalGenerators() ([]resmap.Generator, error)
 func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 	var r []resmap.Transformer
 	tConfig := ra.GetTransformerConfig()
+	if len(kt.kustomization.RemoveCommonLabels) > 0 ||
+		len(kt.kustomization.RemoveCommonAnnotations) > 0 {
+		// What the bases put there is removed before this
+		// kustomization's own transformers run.
+		r = append(r, &commonMetadataRemover{
+			labels:           kt.kustomization.RemoveCommonLabels,
+			annotations:      kt.kustomization.RemoveCommonAnnotations,
+			labelFields:      tConfig.CommonLabels,
+			annotationFields: tConfig.CommonAnnotations,
+			rmF:              kt.rFactory,
+			kustFile:         kt.kustFile,
+		})
+	}
 	lts, err := kt.configureBuiltinTransformers(tConfig)
 	if err != nil {
 		return err
@@ -287,7 +481,15 @@ func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]re
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	ts, err := kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, err
+	}
+	// The transformers are in the order of their configs.
+	for i, c := range ra.ResMap().Resources() {
+		ts[i] = kt.recordProvenance(c.GetKind(), ts[i])[0]
+	}
+	return ts, nil
 }
 
 func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
@@ -303,7 +505,9 @@ func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
 			return err
 		}
 		new := ra.ResMap().DeepCopy()
-		kt.removeValidatedByLabel(new)
+		if err = kt.removeValidatedByLabel(new); err != nil {
+			return err
+		}
 		if err = orignal.ErrorIfNotEqualSets(new); err != nil {
 			return fmt.Errorf("validator shouldn't modify the resource map: %v", err)
 		}
@@ -311,7 +515,7 @@ func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
 	return nil
 }
 
-func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) {
+func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) error {
 	resources := rm.Resources()
 	for _, r := range resources {
 		labels := r.GetLabels()
@@ -319,12 +523,11 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) {
 			continue
 		}
 		delete(labels, konfig.ValidatedByLabelKey)
-		if len(labels) == 0 {
-			r.SetLabels(nil)
-		} else {
-			r.SetLabels(labels)
+		if err := r.SetLabelsE(labels); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 // accumulateResources fills the given resourceAccumulator
@@ -332,9 +535,20 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) {
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) (*accumulator.ResAccumulator, error) {
 	for _, path := range paths {
+		if path == types.StdinResourcesPath {
+			if err := kt.accumulateStdin(ra); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		// try loading resource as file then as base (directory or git repository)
 		if errF := kt.accumulateFile(ra, path); errF != nil {
-			ldr, errL := kt.ldr.New(path)
+			if _, ok := errF.(*namespacedError); ok {
+				// The file loaded, but its resources aren't allowed.
+				return nil, errF
+			}
+			dir, recursive := peelRecursiveQuery(path)
+			ldr, errL := kt.ldr.New(dir)
 			if errL != nil {
 				return nil, multierror.Append(
 					fmt.Errorf("accumulateFile error: %q", errF),
@@ -342,7 +556,11 @@ func (kt *KustTarget) accumulateResources(
 				)
 			}
 			var errD error
-			ra, errD = kt.accumulateDirectory(ra, ldr, false)
+			if rl, ok := asRemoteManifestLoader(ldr); ok {
+				ra, errD = kt.accumulateRemoteManifests(ra, rl, recursive)
+			} else {
+				ra, errD = kt.accumulateDirectory(ra, ldr, false)
+			}
 			if errD != nil {
 				return nil, multierror.Append(
 					fmt.Errorf("accumulateFile error: %q", errF),
@@ -377,17 +595,19 @@ func (kt *KustTarget) accumulateDirectory(
 	ra *accumulator.ResAccumulator, ldr ifc.Loader, isComponent bool) (*accumulator.ResAccumulator, error) {
 	defer ldr.Cleanup()
 	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	subKt.SetDefaultTransformerConfig(kt.defaultConfig)
+	subKt.SetCapabilities(kt.capabilities)
+	subKt.SetExternalNames(kt.externalNames)
+	subKt.nested = true
+	subKt.clusterScopedOnly = kt.enforcesClusterScopedOnly()
+	subKt.fileCount = kt.fileCount
 	err := subKt.Load()
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "couldn't make target for path '%s'", ldr.Root())
 	}
-	if isComponent && subKt.kustomization.Kind != types.ComponentKind {
-		return nil, fmt.Errorf(
-			"expected kind '%s' for path '%s' but got '%s'", types.ComponentKind, ldr.Root(), subKt.kustomization.Kind)
-	} else if !isComponent && subKt.kustomization.Kind == types.ComponentKind {
-		return nil, fmt.Errorf(
-			"expected kind != '%s' for path '%s'", types.ComponentKind, ldr.Root())
+	if err = subKt.errIfUnexpectedKind(isComponent); err != nil {
+		return nil, err
 	}
 
 	var subRa *accumulator.ResAccumulator
@@ -412,12 +632,31 @@ func (kt *KustTarget) accumulateDirectory(
 	return ra, nil
 }
 
+// errIfUnexpectedKind returns an error if the target is a
+// Component and isComponent is false, or vice versa.
+func (kt *KustTarget) errIfUnexpectedKind(isComponent bool) error {
+	if isComponent && kt.kustomization.Kind != types.ComponentKind {
+		return fmt.Errorf(
+			"expected kind '%s' for path '%s' but got '%s'", types.ComponentKind, kt.ldr.Root(), kt.kustomization.Kind)
+	} else if !isComponent && kt.kustomization.Kind == types.ComponentKind {
+		return fmt.Errorf(
+			"expected kind != '%s' for path '%s'", types.ComponentKind, kt.ldr.Root())
+	}
+	return nil
+}
+
 func (kt *KustTarget) accumulateFile(
 	ra *accumulator.ResAccumulator, path string) error {
 	resources, err := kt.rFactory.FromFile(kt.ldr, path)
 	if err != nil {
 		return errors.Wrapf(err, "accumulating resources from '%s'", path)
 	}
+	err = kt.errIfNamespaced(
+		resources, fmt.Sprintf("'%s', listed in %s", path, kt.kustFile))
+	if err != nil {
+		return err
+	}
+	kt.recordFilePositions(resources)
 	err = ra.AppendAll(resources)
 	if err != nil {
 		return errors.Wrapf(err, "merging resources from '%s'", path)
@@ -425,6 +664,61 @@ func (kt *KustTarget) accumulateFile(
 	return nil
 }
 
+// accumulateStdin fills the given resourceAccumulator with
+// the resources read on stdin.  These are read at most once,
+// and only for the top-level kustomization, since otherwise
+// their place in the output would be ambiguous.
+func (kt *KustTarget) accumulateStdin(ra *accumulator.ResAccumulator) error {
+	if kt.nested {
+		return fmt.Errorf(
+			"resources entry '%s' (stdin) is allowed only in the top-level kustomization, not in %s",
+			types.StdinResourcesPath, kt.kustFile)
+	}
+	if kt.stdinRead {
+		return fmt.Errorf(
+			"resources entry '%s' (stdin) may appear only once, but appears more than once in %s",
+			types.StdinResourcesPath, kt.kustFile)
+	}
+	if kt.stdin == nil {
+		return fmt.Errorf(
+			"resources entry '%s' in %s asks for resources on stdin, but none were given",
+			types.StdinResourcesPath, kt.kustFile)
+	}
+	kt.stdinRead = true
+	in, err := ioutil.ReadAll(kt.stdin)
+	if err != nil {
+		return errors.Wrap(err, "reading resources from stdin")
+	}
+	resources, err := kt.rFactory.NewResMapFromBytes(in)
+	if err != nil {
+		return errors.Wrap(err, "accumulating resources from stdin")
+	}
+	origin := &resource.Origin{Path: types.StdinResourcesPath}
+	for _, r := range resources.Resources() {
+		r.SetOrigin(origin)
+	}
+	if err = kt.errIfNamespaced(resources, "stdin"); err != nil {
+		return err
+	}
+	kt.recordFilePositions(resources)
+	err = ra.AppendAll(resources)
+	if err != nil {
+		return errors.Wrap(err, "merging resources from stdin")
+	}
+	return nil
+}
+
+// recordFilePositions records the position of each of the
+// given resources, all read from the same file or made by
+// the same generator, in the input of the build.
+func (kt *KustTarget) recordFilePositions(m resmap.ResMap) {
+	file := *kt.fileCount
+	*kt.fileCount++
+	for i, r := range m.Resources() {
+		r.SetFilePosition(&resource.FilePosition{File: file, Document: i})
+	}
+}
+
 func (kt *KustTarget) configureBuiltinPlugin(
 	p resmap.Configurable, c interface{}, bpt builtinhelpers.BuiltinPluginType) (err error) {
 	var y []byte
@@ -435,7 +729,8 @@ func (kt *KustTarget) configureBuiltinPlugin(
 				err, "builtin %s marshal", bpt)
 		}
 	}
-	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory), y)
+	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory,
+		resmap.NewSchema(kt.rFactory.RF().OpenAPISchemas())), y)
 	if err != nil {
 		return errors.Wrapf(
 			err, "trouble configuring builtin %s with config: `\n%s`", bpt, string(y))
@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// enforcesClusterScopedOnly returns true if the
+// kustomization, or one including it, sets
+// enforceClusterScopedOnly.
+func (kt *KustTarget) enforcesClusterScopedOnly() bool {
+	return kt.clusterScopedOnly ||
+		(kt.kustomization != nil && kt.kustomization.EnforceClusterScopedOnly)
+}
+
+// errIfNamespaceFields returns an error naming the fields
+// of the kustomization that would put resources in a
+// namespace, if cluster-scoped resources are enforced.
+func (kt *KustTarget) errIfNamespaceFields() error {
+	if !kt.enforcesClusterScopedOnly() {
+		return nil
+	}
+	k := kt.kustomization
+	var fields []string
+	if k.Namespace != "" {
+		fields = append(fields, "namespace")
+	}
+	if k.NamespacePrefix != "" {
+		fields = append(fields, "namespacePrefix")
+	}
+	if k.NamespaceSuffix != "" {
+		fields = append(fields, "namespaceSuffix")
+	}
+	if k.NamespacePrefixSuffixOptions != nil {
+		fields = append(fields, "namespacePrefixSuffixOptions")
+	}
+	for i, mi := range k.MultiInstance {
+		for j, inst := range mi.Instances {
+			if inst.Namespace != "" {
+				fields = append(fields, fmt.Sprintf(
+					"multiInstance[%d].instances[%d].namespace", i, j))
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"enforceClusterScopedOnly: %s sets %s",
+		kt.kustFile, strings.Join(fields, ", "))
+}
+
+// errIfNamespaced returns an error naming each of the
+// resources, read from or made by the given source, that's
+// of a namespaced kind or has a namespace, if
+// cluster-scoped resources are enforced.
+func (kt *KustTarget) errIfNamespaced(m resmap.ResMap, source string) error {
+	if !kt.enforcesClusterScopedOnly() {
+		return nil
+	}
+	return errIfNamespaced(m, source)
+}
+
+func errIfNamespaced(m resmap.ResMap, source string) error {
+	var offenders []string
+	for _, r := range m.Resources() {
+		switch {
+		case r.IsNamespaceableKind():
+			offenders = append(offenders, fmt.Sprintf(
+				"%s is of a namespaced kind", r.CurId()))
+		case r.GetNamespace() != "":
+			offenders = append(offenders, fmt.Sprintf(
+				"%s has namespace '%s'", r.CurId(), r.GetNamespace()))
+		}
+	}
+	if len(offenders) == 0 {
+		return nil
+	}
+	return &namespacedError{source: source, offenders: offenders}
+}
+
+// namespacedError names the namespaced resources of a
+// build enforcing cluster-scoped resources only.
+type namespacedError struct {
+	source    string
+	offenders []string
+}
+
+func (e *namespacedError) Error() string {
+	return fmt.Sprintf("enforceClusterScopedOnly: in %s:\n  %s",
+		e.source, strings.Join(e.offenders, "\n  "))
+}
@@ -51,6 +51,7 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		builtinhelpers.PatchStrategicMergeTransformer,
 		builtinhelpers.PatchTransformer,
 		builtinhelpers.NamespaceTransformer,
+		builtinhelpers.NamespacePrefixSuffixTransformer,
 		builtinhelpers.PrefixSuffixTransformer,
 		builtinhelpers.LabelTransformer,
 		builtinhelpers.AnnotationsTransformer,
@@ -63,7 +64,7 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, r...)
+		result = append(result, kt.recordProvenance(bpt.String(), r...)...)
 	}
 	return result, nil
 }
@@ -155,18 +156,46 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return
 	},
 
+	builtinhelpers.NamespacePrefixSuffixTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		if kt.kustomization.NamespacePrefix == "" &&
+			kt.kustomization.NamespaceSuffix == "" {
+			return
+		}
+		var c struct {
+			Prefix          string
+			Suffix          string
+			IncludeExternal bool
+		}
+		c.Prefix = kt.kustomization.NamespacePrefix
+		c.Suffix = kt.kustomization.NamespaceSuffix
+		if o := kt.kustomization.NamespacePrefixSuffixOptions; o != nil {
+			c.IncludeExternal = o.IncludeExternal
+		}
+		p := f()
+		err = kt.configureBuiltinPlugin(p, c, bpt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+		return
+	},
+
 	builtinhelpers.PatchJson6902Transformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
 		var c struct {
-			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
-			Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
-			JsonOp string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+			Target  *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Path    string          `json:"path,omitempty" yaml:"path,omitempty"`
+			JsonOp  string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+			Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 		}
 		for _, args := range kt.kustomization.PatchesJson6902 {
 			c.Target = args.Target
 			c.Path = args.Path
 			c.JsonOp = args.Patch
+			c.Options = args.Options
 			p := f()
 			err = kt.configureBuiltinPlugin(p, c, bpt)
 			if err != nil {
@@ -195,18 +224,20 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return
 	},
 	builtinhelpers.PatchTransformer: func(
-		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
 		if len(kt.kustomization.Patches) == 0 {
 			return
 		}
 		var c struct {
-			Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
-			Patch  string          `json:"patch,omitempty" yaml:"patch,omitempty"`
-			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Path    string          `json:"path,omitempty" yaml:"path,omitempty"`
+			Patch   string          `json:"patch,omitempty" yaml:"patch,omitempty"`
+			Target  *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 		}
-		for _, pc := range kt.kustomization.Patches {
+		for i, pc := range kt.kustomization.Patches {
 			c.Target = pc.Target
+			c.Options = pc.Options
 			c.Patch = pc.Patch
 			c.Path = pc.Path
 			p := f()
@@ -214,7 +245,16 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			if err != nil {
 				return nil, err
 			}
-			result = append(result, p)
+			t, err := kt.warnIfOverwritten(p, i, pc, tc)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, t)
+		}
+		if po := kt.kustomization.PatchOptions; po != nil &&
+			po.Order == types.DependencyPatchOrder {
+			return []resmap.Transformer{
+				newDependencyOrderedPatches(result, kt.rFactory)}, nil
 		}
 		return
 	},
@@ -222,11 +262,13 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
 		var c struct {
-			Labels     map[string]string
-			FieldSpecs []types.FieldSpec
+			Labels             map[string]string
+			FieldSpecs         []types.FieldSpec
+			MetadataValidation *types.MetadataValidation
 		}
 		c.Labels = kt.kustomization.CommonLabels
 		c.FieldSpecs = tc.CommonLabels
+		c.MetadataValidation = kt.kustomization.MetadataValidation
 		p := f()
 		err = kt.configureBuiltinPlugin(p, c, bpt)
 		if err != nil {
@@ -239,11 +281,13 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
 		var c struct {
-			Annotations map[string]string
-			FieldSpecs  []types.FieldSpec
+			Annotations        map[string]string
+			FieldSpecs         []types.FieldSpec
+			MetadataValidation *types.MetadataValidation
 		}
 		c.Annotations = kt.kustomization.CommonAnnotations
 		c.FieldSpecs = tc.CommonAnnotations
+		c.MetadataValidation = kt.kustomization.MetadataValidation
 		p := f()
 		err = kt.configureBuiltinPlugin(p, c, bpt)
 		if err != nil {
@@ -0,0 +1,494 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/kv"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// flattener merges the kustomizations of a build into
+// one, in the order a build would apply them.
+type flattener struct {
+	r    *inputResolver
+	flat *types.Kustomization
+	prov types.KustomizationProvenance
+}
+
+// Flatten returns one kustomization holding the effective
+// content of this target's kustomization graph, with a
+// record of which kustomization file contributed each
+// entry.  Load must be called first.
+//
+// Local bases and components are inlined, so resources and
+// components list only files, remote refs and "-".  Paths
+// are rewritten relative to this target's root.  List
+// fields are concatenated in the order a build applies
+// them: the kustomizations listed in resources, then the
+// components, then the kustomization listing them.  When
+// layers set the same scalar field, or the same key of a
+// map field, the value set last that way wins, as it
+// would in the output of a build; namePrefix, nameSuffix,
+// namespacePrefix and namespaceSuffix compose instead.
+// Each kustomization's generatorOptions are merged into
+// the options of its own generators.
+//
+// The result is a view for review, not an equivalent
+// build input: a build applies the transformers of a base
+// to that base's resources only, and reads each file
+// relative to its own kustomization.  The ignorePatterns
+// of each kustomization are dropped for the same reason.
+func (kt *KustTarget) Flatten(
+	fSys filesys.FileSystem, lr fLdr.LoadRestrictorFunc) (
+	*types.Kustomization, types.KustomizationProvenance, error) {
+	f := &flattener{
+		r: &inputResolver{
+			fSys: fSys,
+			lr:   lr,
+			root: kt.ldr.Root(),
+			seen: make(map[types.InputRef]bool),
+		},
+		flat: &types.Kustomization{
+			TypeMeta: types.TypeMeta{
+				APIVersion: types.KustomizationVersion,
+				Kind:       types.KustomizationKind,
+			},
+		},
+		prov: make(types.KustomizationProvenance),
+	}
+	if err := kt.flatten(f); err != nil {
+		return nil, nil, err
+	}
+	// As in a build, only the top-level kustomization's
+	// sortOptions count.
+	k := kt.Kustomization()
+	f.flat.MetaData = k.MetaData
+	if k.SortOptions != nil {
+		f.flat.SortOptions = k.SortOptions
+		f.set("sortOptions", kt)
+	}
+	return f.flat, f.prov, nil
+}
+
+// flatten mirrors accumulateTarget.
+func (kt *KustTarget) flatten(f *flattener) error {
+	k := kt.Kustomization()
+	err := kt.flattenResources(f, k.Resources)
+	if err != nil {
+		return errors.Wrap(err, "accumulating resources")
+	}
+	err = kt.flattenComponents(f, k.Components)
+	if err != nil {
+		return errors.Wrap(err, "accumulating components")
+	}
+	return kt.flattenFields(f, &k)
+}
+
+// flattenResources mirrors accumulateResources.
+func (kt *KustTarget) flattenResources(
+	f *flattener, paths []string) error {
+	for _, path := range paths {
+		if path == types.StdinResourcesPath {
+			f.appendResource(kt, path)
+			continue
+		}
+		p, errF := f.r.confirmFile(kt.ldr, path)
+		if errF == nil {
+			f.appendResource(kt, f.r.relative(p))
+			continue
+		}
+		if isRemote(path) {
+			f.appendResource(kt, path)
+			continue
+		}
+		ldr, errL := kt.ldr.New(path)
+		if errL != nil {
+			return multierror.Append(
+				fmt.Errorf("accumulateFile error: %q", errF),
+				fmt.Errorf("loader.New error: %q", errL),
+			)
+		}
+		if errD := kt.flattenDirectory(f, ldr, false); errD != nil {
+			return multierror.Append(
+				fmt.Errorf("accumulateFile error: %q", errF),
+				fmt.Errorf("accumulateDirector error: %q", errD),
+			)
+		}
+	}
+	return nil
+}
+
+// flattenComponents mirrors accumulateComponents.
+func (kt *KustTarget) flattenComponents(
+	f *flattener, paths []string) error {
+	for _, path := range paths {
+		if isRemote(path) {
+			f.set(fmt.Sprintf("components[%d]", len(f.flat.Components)), kt)
+			f.flat.Components = append(f.flat.Components, path)
+			continue
+		}
+		ldr, errL := kt.ldr.New(path)
+		if errL != nil {
+			return fmt.Errorf("loader.New %q", errL)
+		}
+		if errD := kt.flattenDirectory(f, ldr, true); errD != nil {
+			return fmt.Errorf("accumulateDirectory: %q", errD)
+		}
+	}
+	return nil
+}
+
+// flattenDirectory mirrors accumulateDirectory.
+func (kt *KustTarget) flattenDirectory(
+	f *flattener, ldr ifc.Loader, isComponent bool) error {
+	defer ldr.Cleanup()
+	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	err := subKt.Load()
+	if err != nil {
+		return errors.Wrapf(
+			err, "couldn't make target for path '%s'", ldr.Root())
+	}
+	if err = subKt.errIfUnexpectedKind(isComponent); err != nil {
+		return err
+	}
+	if err = subKt.flatten(f); err != nil {
+		return errors.Wrapf(
+			err, "recursed accumulation of path '%s'", ldr.Root())
+	}
+	return nil
+}
+
+// flattenFields adds the fields of the kustomization,
+// other than resources and components, to the result.
+func (kt *KustTarget) flattenFields(
+	f *flattener, k *types.Kustomization) error {
+	if k.Namespace != "" {
+		f.flat.Namespace = k.Namespace
+		f.set("namespace", kt)
+	}
+	if k.NamePrefix != "" {
+		f.flat.NamePrefix = k.NamePrefix + f.flat.NamePrefix
+		f.compose("namePrefix", kt)
+	}
+	if k.NameSuffix != "" {
+		f.flat.NameSuffix = f.flat.NameSuffix + k.NameSuffix
+		f.compose("nameSuffix", kt)
+	}
+	if k.NamespacePrefix != "" {
+		f.flat.NamespacePrefix = k.NamespacePrefix + f.flat.NamespacePrefix
+		f.compose("namespacePrefix", kt)
+	}
+	if k.NamespaceSuffix != "" {
+		f.flat.NamespaceSuffix = f.flat.NamespaceSuffix + k.NamespaceSuffix
+		f.compose("namespaceSuffix", kt)
+	}
+	if k.NamespacePrefixSuffixOptions != nil {
+		f.flat.NamespacePrefixSuffixOptions = k.NamespacePrefixSuffixOptions
+		f.set("namespacePrefixSuffixOptions", kt)
+	}
+	if k.EnforceClusterScopedOnly {
+		// It holds for everything below it, so any layer
+		// setting it sets it.
+		f.flat.EnforceClusterScopedOnly = true
+		f.set("enforceClusterScopedOnly", kt)
+	}
+	if k.Requires != nil {
+		f.flat.Requires = mergeRequirements(f.flat.Requires, k.Requires)
+		f.compose("requires", kt)
+	}
+	if k.PatchOptions != nil {
+		f.flat.PatchOptions = k.PatchOptions
+		f.set("patchOptions", kt)
+	}
+	if k.MetadataValidation != nil {
+		f.flat.MetadataValidation = k.MetadataValidation
+		f.set("metadataValidation", kt)
+	}
+	if k.Inventory != nil {
+		f.flat.Inventory = k.Inventory
+		f.set("inventory", kt)
+	}
+	// As in a build, the removals apply to what the bases
+	// and components set, not to this kustomization's own.
+	f.flat.CommonLabels = f.removeKeys(
+		"commonLabels", f.flat.CommonLabels, k.RemoveCommonLabels)
+	f.flat.CommonAnnotations = f.removeKeys(
+		"commonAnnotations", f.flat.CommonAnnotations, k.RemoveCommonAnnotations)
+	f.flat.CommonLabels = f.mergeMap(
+		kt, "commonLabels", f.flat.CommonLabels, k.CommonLabels)
+	f.flat.CommonAnnotations = f.mergeMap(
+		kt, "commonAnnotations", f.flat.CommonAnnotations, k.CommonAnnotations)
+
+	for _, mi := range k.MultiInstance {
+		if err := f.appendMultiInstance(kt, mi); err != nil {
+			return err
+		}
+	}
+	for _, path := range k.Crds {
+		p, err := f.r.confirmFile(kt.ldr, path)
+		if err != nil {
+			return errors.Wrapf(err, "loading CRDs %v", k.Crds)
+		}
+		f.set(fmt.Sprintf("crds[%d]", len(f.flat.Crds)), kt)
+		f.flat.Crds = append(f.flat.Crds, f.r.relative(p))
+	}
+	for _, path := range k.Configurations {
+		p, err := f.r.confirmFile(kt.ldr, path)
+		if err != nil {
+			return err
+		}
+		f.set(fmt.Sprintf("configurations[%d]", len(f.flat.Configurations)), kt)
+		f.flat.Configurations = append(f.flat.Configurations, f.r.relative(p))
+	}
+	for _, args := range k.ConfigMapGenerator {
+		if err := f.rebaseGeneratorArgs(kt, &args.GeneratorArgs, k); err != nil {
+			return err
+		}
+		f.set(fmt.Sprintf(
+			"configMapGenerator[%d]", len(f.flat.ConfigMapGenerator)), kt)
+		f.flat.ConfigMapGenerator = append(f.flat.ConfigMapGenerator, args)
+	}
+	for _, args := range k.SecretGenerator {
+		if err := f.rebaseGeneratorArgs(kt, &args.GeneratorArgs, k); err != nil {
+			return err
+		}
+		f.set(fmt.Sprintf(
+			"secretGenerator[%d]", len(f.flat.SecretGenerator)), kt)
+		f.flat.SecretGenerator = append(f.flat.SecretGenerator, args)
+	}
+	for _, args := range k.HelmChartInflationGenerator {
+		f.set(fmt.Sprintf("helmChartInflationGenerator[%d]",
+			len(f.flat.HelmChartInflationGenerator)), kt)
+		f.flat.HelmChartInflationGenerator = append(
+			f.flat.HelmChartInflationGenerator, args)
+	}
+	f.flat.Generators = f.appendPluginConfigs(
+		kt, "generators", f.flat.Generators, k.Generators)
+
+	for _, p := range k.PatchesStrategicMerge {
+		// As in the PatchStrategicMergeTransformer, a path
+		// that parses as a resource is an inline patch.
+		if _, err := kt.rFactory.RF().SliceFromBytes([]byte(p)); err != nil {
+			path, err := f.r.confirmFile(kt.ldr, string(p))
+			if err != nil {
+				return err
+			}
+			p = types.PatchStrategicMerge(f.r.relative(path))
+		}
+		f.set(fmt.Sprintf(
+			"patchesStrategicMerge[%d]", len(f.flat.PatchesStrategicMerge)), kt)
+		f.flat.PatchesStrategicMerge = append(f.flat.PatchesStrategicMerge, p)
+	}
+	for _, p := range k.Patches {
+		if err := f.rebasePatch(kt, &p); err != nil {
+			return err
+		}
+		f.set(fmt.Sprintf("patches[%d]", len(f.flat.Patches)), kt)
+		f.flat.Patches = append(f.flat.Patches, p)
+	}
+	for _, p := range k.PatchesJson6902 {
+		if err := f.rebasePatch(kt, &p); err != nil {
+			return err
+		}
+		f.set(fmt.Sprintf("patchesJson6902[%d]", len(f.flat.PatchesJson6902)), kt)
+		f.flat.PatchesJson6902 = append(f.flat.PatchesJson6902, p)
+	}
+	for _, image := range k.Images {
+		f.set(fmt.Sprintf("images[%d]", len(f.flat.Images)), kt)
+		f.flat.Images = append(f.flat.Images, image)
+	}
+	for _, replica := range k.Replicas {
+		f.set(fmt.Sprintf("replicas[%d]", len(f.flat.Replicas)), kt)
+		f.flat.Replicas = append(f.flat.Replicas, replica)
+	}
+	f.flat.Transformers = f.appendPluginConfigs(
+		kt, "transformers", f.flat.Transformers, k.Transformers)
+	f.flat.Validators = f.appendPluginConfigs(
+		kt, "validators", f.flat.Validators, k.Validators)
+	for _, v := range k.Vars {
+		f.set(fmt.Sprintf("vars[%d]", len(f.flat.Vars)), kt)
+		f.flat.Vars = append(f.flat.Vars, v)
+	}
+	return nil
+}
+
+// mergeRequirements returns the requirements of both r
+// and more: the capabilities either requires, and the
+// higher of their minimum versions.
+func mergeRequirements(r, more *types.Requirements) *types.Requirements {
+	if r == nil {
+		c := *more
+		c.Capabilities = append([]string(nil), more.Capabilities...)
+		return &c
+	}
+	r.Helm = r.Helm || more.Helm
+	r.Plugins = r.Plugins || more.Plugins
+	for _, name := range more.Capabilities {
+		if !containsString(r.Capabilities, name) {
+			r.Capabilities = append(r.Capabilities, name)
+		}
+	}
+	if r.MinApiVersion == "" {
+		r.MinApiVersion = more.MinApiVersion
+	} else if more.MinApiVersion != "" {
+		v, errV := version.ParseGeneric(r.MinApiVersion)
+		w, errW := version.ParseGeneric(more.MinApiVersion)
+		if errV == nil && errW == nil && !v.AtLeast(w) {
+			r.MinApiVersion = more.MinApiVersion
+		}
+	}
+	return r
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rebaseGeneratorArgs rewrites the file and env sources
+// of the args relative to the root of the result, and
+// merges the kustomization's generatorOptions into the
+// args' own options, as the generators would.
+func (f *flattener) rebaseGeneratorArgs(
+	kt *KustTarget, args *types.GeneratorArgs, k *types.Kustomization) error {
+	for i, fs := range args.FileSources {
+		key, path, err := kv.ParseFileSource(fs)
+		if err != nil {
+			return err
+		}
+		path = f.rebase(kt.ldr, path)
+		if strings.Contains(fs, "=") {
+			path = key + "=" + path
+		}
+		args.FileSources[i] = path
+	}
+	for i, path := range args.EnvSources {
+		args.EnvSources[i] = f.rebase(kt.ldr, path)
+	}
+	args.Options = types.MergeGlobalOptionsIntoLocal(
+		args.Options, k.GeneratorOptions)
+	return nil
+}
+
+// rebasePatch rewrites the path of the patch, if it
+// isn't inline, relative to the root of the result.
+func (f *flattener) rebasePatch(kt *KustTarget, p *types.Patch) error {
+	if p.Path == "" {
+		return nil
+	}
+	path, err := f.r.confirmFile(kt.ldr, p.Path)
+	if err != nil {
+		return err
+	}
+	p.Path = f.r.relative(path)
+	return nil
+}
+
+// appendMultiInstance appends a copy of the entry, its
+// local base and patch files rewritten relative to the
+// root of the result.  Its instances stay instances of
+// the base, rather than being flattened.
+func (f *flattener) appendMultiInstance(
+	kt *KustTarget, mi types.MultiInstance) error {
+	if !isRemote(mi.Resource) {
+		mi.Resource = f.rebase(kt.ldr, mi.Resource)
+	}
+	instances := make([]types.Instance, len(mi.Instances))
+	for i, inst := range mi.Instances {
+		patches := make([]types.Patch, len(inst.Patches))
+		for j, p := range inst.Patches {
+			if err := f.rebasePatch(kt, &p); err != nil {
+				return err
+			}
+			patches[j] = p
+		}
+		inst.Patches = patches
+		instances[i] = inst
+	}
+	mi.Instances = instances
+	f.set(fmt.Sprintf("multiInstance[%d]", len(f.flat.MultiInstance)), kt)
+	f.flat.MultiInstance = append(f.flat.MultiInstance, mi)
+	return nil
+}
+
+// appendPluginConfigs appends the plugin config entries
+// to the list, rewriting those that aren't inline
+// relative to the root of the result.
+func (f *flattener) appendPluginConfigs(
+	kt *KustTarget, field string, list, entries []string) []string {
+	for _, p := range entries {
+		if _, err := kt.rFactory.NewResMapFromBytes([]byte(p)); err != nil {
+			// not an inline config
+			p = f.rebase(kt.ldr, p)
+		}
+		f.set(fmt.Sprintf("%s[%d]", field, len(list)), kt)
+		list = append(list, p)
+	}
+	return list
+}
+
+func (f *flattener) appendResource(kt *KustTarget, path string) {
+	f.set(fmt.Sprintf("resources[%d]", len(f.flat.Resources)), kt)
+	f.flat.Resources = append(f.flat.Resources, path)
+}
+
+// mergeMap sets the keys of more in m, overriding those
+// already there.
+func (f *flattener) mergeMap(kt *KustTarget,
+	field string, m, more map[string]string) map[string]string {
+	for k, v := range more {
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[k] = v
+		f.set(field+"."+k, kt)
+	}
+	return m
+}
+
+// removeKeys deletes the keys from m, and the record of
+// which kustomization set them.
+func (f *flattener) removeKeys(
+	field string, m map[string]string, keys []string) map[string]string {
+	for _, k := range keys {
+		delete(m, k)
+		delete(f.prov, field+"."+k)
+	}
+	return m
+}
+
+// rebase returns the path, relative to the loader's root,
+// relative to the root of the result instead.
+func (f *flattener) rebase(ldr ifc.Loader, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ldr.Root(), path)
+	}
+	return f.r.relative(path)
+}
+
+// set records that the target's kustomization file
+// contributed the value of the entry.
+func (f *flattener) set(key string, kt *KustTarget) {
+	f.prov[key] = []string{f.r.relative(kt.kustFile)}
+}
+
+// compose records that the target's kustomization file
+// contributed part of the value of the entry.
+func (f *flattener) compose(key string, kt *KustTarget) {
+	f.prov[key] = append(f.prov[key], f.r.relative(kt.kustFile))
+}
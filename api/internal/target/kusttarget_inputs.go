@@ -0,0 +1,354 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kv"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// inputResolver records the inputs of a build, in the
+// order a build would read them, without duplicates.
+type inputResolver struct {
+	fSys filesys.FileSystem
+	lr   fLdr.LoadRestrictorFunc
+	root string
+	refs []types.InputRef
+	seen map[types.InputRef]bool
+}
+
+// ResolveInputs returns the files and remote refs that a
+// build of this target would read.  Resources aren't loaded,
+// plugins aren't run and remote refs aren't fetched, but
+// the kustomization graph is walked with the same loaders
+// a build uses, so missing files and cycles produce the
+// same errors.  Load must be called first.
+func (kt *KustTarget) ResolveInputs(
+	fSys filesys.FileSystem,
+	lr fLdr.LoadRestrictorFunc) ([]types.InputRef, error) {
+	r := &inputResolver{
+		fSys: fSys,
+		lr:   lr,
+		root: kt.ldr.Root(),
+		seen: make(map[types.InputRef]bool),
+	}
+	r.addKustFile(kt.ldr, types.InputRoleKustomization)
+	if err := kt.resolveInputs(r); err != nil {
+		return nil, err
+	}
+	return r.refs, nil
+}
+
+// resolveInputs mirrors accumulateTarget.
+func (kt *KustTarget) resolveInputs(r *inputResolver) error {
+	err := kt.resolvePaths(r, kt.kustomization.Resources, types.InputRoleResource)
+	if err != nil {
+		return errors.Wrap(err, "accumulating resources")
+	}
+	if err = kt.resolveInstances(r); err != nil {
+		return errors.Wrap(err, "accumulating instances")
+	}
+	err = kt.resolveComponents(r, kt.kustomization.Components)
+	if err != nil {
+		return errors.Wrap(err, "accumulating components")
+	}
+	for _, path := range kt.kustomization.Configurations {
+		if err = r.addFile(kt.ldr, path, types.InputRoleConfiguration); err != nil {
+			return err
+		}
+	}
+	for _, path := range kt.kustomization.Crds {
+		if err = r.addFile(kt.ldr, path, types.InputRoleCrd); err != nil {
+			return errors.Wrapf(
+				err, "loading CRDs %v", kt.kustomization.Crds)
+		}
+	}
+	if err = kt.resolveGeneratorSources(r); err != nil {
+		return err
+	}
+	err = kt.resolvePluginConfigs(
+		r, kt.kustomization.Generators, types.InputRoleGenerator)
+	if err != nil {
+		return errors.Wrap(err, "loading generator plugins")
+	}
+	if err = kt.resolvePatches(r); err != nil {
+		return err
+	}
+	err = kt.resolvePluginConfigs(
+		r, kt.kustomization.Transformers, types.InputRoleTransformer)
+	if err != nil {
+		return err
+	}
+	return kt.resolvePluginConfigs(
+		r, kt.kustomization.Validators, types.InputRoleValidator)
+}
+
+// resolvePaths mirrors accumulateResources.
+func (kt *KustTarget) resolvePaths(
+	r *inputResolver, paths []string, role types.InputRole) error {
+	for _, path := range paths {
+		if path == types.StdinResourcesPath {
+			// Not a file; there's nothing to watch.
+			continue
+		}
+		errF := r.addFile(kt.ldr, path, role)
+		if errF == nil {
+			continue
+		}
+		if isRemote(path) {
+			r.addRemote(path, role)
+			continue
+		}
+		ldr, errL := kt.ldr.New(path)
+		if errL != nil {
+			return multierror.Append(
+				fmt.Errorf("accumulateFile error: %q", errF),
+				fmt.Errorf("loader.New error: %q", errL),
+			)
+		}
+		if errD := kt.resolveDirectory(r, ldr, false); errD != nil {
+			return multierror.Append(
+				fmt.Errorf("accumulateFile error: %q", errF),
+				fmt.Errorf("accumulateDirector error: %q", errD),
+			)
+		}
+	}
+	return nil
+}
+
+// resolveComponents mirrors accumulateComponents.
+func (kt *KustTarget) resolveComponents(
+	r *inputResolver, paths []string) error {
+	for _, path := range paths {
+		if isRemote(path) {
+			r.addRemote(path, types.InputRoleComponent)
+			continue
+		}
+		ldr, errL := kt.ldr.New(path)
+		if errL != nil {
+			return fmt.Errorf("loader.New %q", errL)
+		}
+		if errD := kt.resolveDirectory(r, ldr, true); errD != nil {
+			return fmt.Errorf("accumulateDirectory: %q", errD)
+		}
+	}
+	return nil
+}
+
+// resolveDirectory mirrors accumulateDirectory.
+func (kt *KustTarget) resolveDirectory(
+	r *inputResolver, ldr ifc.Loader, isComponent bool) error {
+	defer ldr.Cleanup()
+	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	err := subKt.Load()
+	if err != nil {
+		return errors.Wrapf(
+			err, "couldn't make target for path '%s'", ldr.Root())
+	}
+	if err = subKt.errIfUnexpectedKind(isComponent); err != nil {
+		return err
+	}
+	role := types.InputRoleKustomization
+	if isComponent {
+		role = types.InputRoleComponent
+	}
+	r.addKustFile(ldr, role)
+	if err = subKt.resolveInputs(r); err != nil {
+		return errors.Wrapf(
+			err, "recursed accumulation of path '%s'", ldr.Root())
+	}
+	return nil
+}
+
+// resolveGeneratorSources records the file and env sources
+// of the builtin ConfigMap and Secret generators.
+func (kt *KustTarget) resolveGeneratorSources(r *inputResolver) error {
+	var sources []types.KvPairSources
+	for _, args := range kt.kustomization.ConfigMapGenerator {
+		sources = append(sources, args.KvPairSources)
+	}
+	for _, args := range kt.kustomization.SecretGenerator {
+		sources = append(sources, args.KvPairSources)
+	}
+	for _, s := range sources {
+		for _, fs := range s.FileSources {
+			paths, err := kv.FileSourcePaths(kt.ldr, fs)
+			if err != nil {
+				return err
+			}
+			if _, fPath, _ := kv.ParseFileSource(fs); kv.IsGlob(fPath) {
+				r.addGlob(kt.ldr, fPath, types.InputRoleGeneratorSource)
+			}
+			for _, path := range paths {
+				err = r.addFile(kt.ldr, path, types.InputRoleGeneratorSource)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for _, path := range s.EnvSources {
+			err := r.addFile(kt.ldr, path, types.InputRoleGeneratorSource)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveInstances mirrors accumulateInstances.
+func (kt *KustTarget) resolveInstances(r *inputResolver) error {
+	for _, mi := range kt.kustomization.MultiInstance {
+		err := kt.resolvePaths(r, []string{mi.Resource}, types.InputRoleResource)
+		if err != nil {
+			return err
+		}
+		for _, inst := range mi.Instances {
+			for _, p := range inst.Patches {
+				if p.Path == "" {
+					continue
+				}
+				if err = r.addFile(kt.ldr, p.Path, types.InputRolePatch); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolvePatches records the patch files read by the builtin
+// patch transformers, skipping inline patches.
+func (kt *KustTarget) resolvePatches(r *inputResolver) error {
+	for _, p := range kt.kustomization.PatchesStrategicMerge {
+		// As in the PatchStrategicMergeTransformer, a path
+		// that parses as a resource is an inline patch.
+		if _, err := kt.rFactory.RF().SliceFromBytes([]byte(p)); err == nil {
+			continue
+		}
+		if err := r.addFile(kt.ldr, string(p), types.InputRolePatch); err != nil {
+			return err
+		}
+	}
+	for _, p := range kt.kustomization.Patches {
+		if p.Path == "" {
+			continue
+		}
+		if err := r.addFile(kt.ldr, p.Path, types.InputRolePatch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePluginConfigs mirrors configureExternalTransformers,
+// skipping inline plugin configs.
+func (kt *KustTarget) resolvePluginConfigs(
+	r *inputResolver, entries []string, role types.InputRole) error {
+	var paths []string
+	for _, p := range entries {
+		if _, err := kt.rFactory.NewResMapFromBytes([]byte(p)); err != nil {
+			// not an inline config
+			paths = append(paths, p)
+		}
+	}
+	return kt.resolvePaths(r, paths, role)
+}
+
+// isRemote returns true if the path is something a loader
+// would fetch rather than read from the file system.
+func isRemote(path string) bool {
+	if u, err := url.Parse(path); err == nil &&
+		(u.Scheme == "http" || u.Scheme == "https") {
+		return true
+	}
+	_, err := git.NewRepoSpecFromUrl(path)
+	return err == nil
+}
+
+// addFile records the file at the given path, relative to the
+// loader's root, after applying the same load restrictions and
+// existence checks a build would.
+func (r *inputResolver) addFile(
+	ldr ifc.Loader, path string, role types.InputRole) error {
+	path, err := r.confirmFile(ldr, path)
+	if err != nil {
+		return err
+	}
+	r.add(types.InputRef{Path: r.relative(path), Role: role})
+	return nil
+}
+
+// confirmFile returns the absolute path of the file at the
+// given path, relative to the loader's root, or an error if
+// a build couldn't read it as a file.
+func (r *inputResolver) confirmFile(
+	ldr ifc.Loader, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ldr.Root(), path)
+	}
+	path, err := r.lr(r.fSys, filesys.ConfirmedDir(ldr.Root()), path)
+	if err != nil {
+		return "", err
+	}
+	if !r.fSys.Exists(path) {
+		return "", fmt.Errorf("'%s' doesn't exist", path)
+	}
+	if r.fSys.IsDir(path) {
+		return "", fmt.Errorf("'%s' is a directory", path)
+	}
+	return path, nil
+}
+
+// addKustFile records the kustomization file under the
+// loader's root.  The caller must have loaded it already.
+func (r *inputResolver) addKustFile(ldr ifc.Loader, role types.InputRole) {
+	for _, kf := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(ldr.Root(), kf)
+		if r.fSys.Exists(path) {
+			r.add(types.InputRef{Path: r.relative(path), Role: role})
+			return
+		}
+	}
+}
+
+// addGlob records the glob, relative to the loader's root.
+func (r *inputResolver) addGlob(
+	ldr ifc.Loader, pattern string, role types.InputRole) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(ldr.Root(), pattern)
+	}
+	r.add(types.InputRef{Path: r.relative(pattern), Role: role, Glob: true})
+}
+
+func (r *inputResolver) addRemote(path string, role types.InputRole) {
+	r.add(types.InputRef{Path: path, Role: role, Remote: true})
+}
+
+func (r *inputResolver) add(ref types.InputRef) {
+	if r.seen[ref] {
+		return
+	}
+	r.seen[ref] = true
+	r.refs = append(r.refs, ref)
+}
+
+func (r *inputResolver) relative(path string) string {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
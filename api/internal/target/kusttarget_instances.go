@@ -0,0 +1,174 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// accumulateInstances adds the resources of the instances
+// of each of the kustomization's multiInstance bases to ra.
+//
+// The base is built once.  Each instance gets a copy of its
+// resources, customized by the instance's namePrefix,
+// namespace and patches, and has its name references fixed
+// among its own resources, before being added.  The copies
+// then lose the record of their original names, so that to
+// the rest of the build they're resources with the names
+// the instance gave them.
+func (kt *KustTarget) accumulateInstances(
+	ra *accumulator.ResAccumulator) error {
+	for i, mi := range kt.kustomization.MultiInstance {
+		if err := validateInstances(mi); err != nil {
+			return errors.Wrapf(err, "multiInstance[%d] in %s", i, kt.kustFile)
+		}
+	}
+	for i, mi := range kt.kustomization.MultiInstance {
+		if err := kt.accumulateMultiInstance(ra, mi); err != nil {
+			return errors.Wrapf(
+				err, "multiInstance[%d] '%s'", i, mi.Resource)
+		}
+	}
+	return nil
+}
+
+// validateInstances returns an error if the instances of
+// the base could produce resources with the same id.
+func validateInstances(mi types.MultiInstance) error {
+	if mi.Resource == "" {
+		return fmt.Errorf("missing resource")
+	}
+	if len(mi.Instances) == 0 {
+		return fmt.Errorf("no instances of '%s'", mi.Resource)
+	}
+	type key struct{ prefix, namespace string }
+	seen := make(map[key]int)
+	for i, inst := range mi.Instances {
+		k := key{inst.NamePrefix, inst.Namespace}
+		if j, ok := seen[k]; ok {
+			return fmt.Errorf(
+				"instances[%d] and instances[%d] of '%s' have the same "+
+					"namePrefix '%s' and namespace '%s'",
+				j, i, mi.Resource, inst.NamePrefix, inst.Namespace)
+		}
+		seen[k] = i
+	}
+	return nil
+}
+
+// accumulateMultiInstance builds the base once, and adds
+// the resources of each of its instances to ra.
+func (kt *KustTarget) accumulateMultiInstance(
+	ra *accumulator.ResAccumulator, mi types.MultiInstance) error {
+	ldr, err := kt.ldr.New(mi.Resource)
+	if err != nil {
+		return err
+	}
+	defer ldr.Cleanup()
+	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	subKt.SetDefaultTransformerConfig(kt.defaultConfig)
+	subKt.SetCapabilities(kt.capabilities)
+	subKt.SetExternalNames(kt.externalNames)
+	subKt.nested = true
+	subKt.fileCount = kt.fileCount
+	if err = subKt.Load(); err != nil {
+		return errors.Wrapf(
+			err, "couldn't make target for path '%s'", ldr.Root())
+	}
+	if err = subKt.errIfUnexpectedKind(false); err != nil {
+		return err
+	}
+	baseRa, err := subKt.AccumulateTarget()
+	if err != nil {
+		return errors.Wrapf(
+			err, "recursed accumulation of path '%s'", ldr.Root())
+	}
+	if len(baseRa.Vars()) > 0 {
+		// Each instance would have its own value for a var.
+		return fmt.Errorf("the base declares vars, which instances can't share")
+	}
+	if err = errIfClusterScopedShared(baseRa, mi); err != nil {
+		return err
+	}
+	for i, inst := range mi.Instances {
+		instRa, err := kt.buildInstance(baseRa, inst)
+		if err != nil {
+			return errors.Wrapf(err, "instances[%d]", i)
+		}
+		if err = ra.AppendAll(instRa.ResMap()); err != nil {
+			return errors.Wrapf(err, "merging instances[%d]", i)
+		}
+	}
+	return ra.MergeConfig(baseRa.GetTransformerConfig())
+}
+
+// errIfClusterScopedShared returns an error if instances
+// differing only in their namespace would each have a copy
+// of a cluster-scoped resource of the base, with the same
+// name.
+func errIfClusterScopedShared(
+	baseRa *accumulator.ResAccumulator, mi types.MultiInstance) error {
+	prefixes := make(map[string]bool)
+	shared, found := "", false
+	for _, inst := range mi.Instances {
+		if prefixes[inst.NamePrefix] {
+			shared, found = inst.NamePrefix, true
+			break
+		}
+		prefixes[inst.NamePrefix] = true
+	}
+	if !found {
+		return nil
+	}
+	for _, r := range baseRa.ResMap().Resources() {
+		if !r.IsNamespaceableKind() {
+			return fmt.Errorf(
+				"instances with the namePrefix '%s' would each have "+
+					"the cluster-scoped %s",
+				shared, r.CurId())
+		}
+	}
+	return nil
+}
+
+// buildInstance returns an accumulator holding a copy of
+// the base's resources, customized for the instance.
+func (kt *KustTarget) buildInstance(
+	baseRa *accumulator.ResAccumulator,
+	inst types.Instance) (*accumulator.ResAccumulator, error) {
+	instRa := accumulator.MakeEmptyAccumulator()
+	err := instRa.MergeConfig(baseRa.GetTransformerConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err = instRa.AppendAll(baseRa.ResMap().DeepCopy()); err != nil {
+		return nil, err
+	}
+	// The instance's patches are read like the
+	// kustomization's own, relative to its root.
+	instKt := *kt
+	instKt.kustomization = &types.Kustomization{
+		NamePrefix: inst.NamePrefix,
+		Namespace:  inst.Namespace,
+		Patches:    inst.Patches,
+	}
+	ts, err := instKt.configureBuiltinTransformers(instRa.GetTransformerConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err = instRa.Transform(newMultiTransformer(ts)); err != nil {
+		return nil, err
+	}
+	if err = instRa.FixBackReferences(instKt.externalNames); err != nil {
+		return nil, err
+	}
+	for _, r := range instRa.ResMap().Resources() {
+		r.RemoveBuildAnnotations()
+	}
+	return instRa, nil
+}
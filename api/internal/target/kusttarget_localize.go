@@ -0,0 +1,574 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/kv"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// LocalizedFilesDir is the directory, beside a localized
+// kustomization file, holding the remote refs it vendors.
+const LocalizedFilesDir = "localized-files"
+
+// localizer copies the kustomizations of a build, and the
+// files they read, vendoring the remote refs.
+type localizer struct {
+	fSys filesys.FileSystem
+	// The directories of the kustomizations copied so far,
+	// in the destination.
+	done map[string]bool
+}
+
+// localizeScope maps a tree of files, all of which the
+// kustomizations in it may read, to the directory their
+// copies go to.
+type localizeScope struct {
+	// src is the top of the tree, e.g. of a clone.
+	src string
+	// dst is where the tree is copied to.
+	dst string
+	// name describes the tree in errors.
+	name string
+	// remote is true if the tree is a clone.
+	remote bool
+}
+
+// dest returns the path of the copy of the file at the
+// given absolute path, or false if it's outside the scope.
+func (s localizeScope) dest(path string) (string, bool) {
+	rel, err := filepath.Rel(s.src, path)
+	if err != nil || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(s.dst, rel), true
+}
+
+// errOutside returns the error for a path, in the given
+// kustomization file, that leads outside the scope.
+func (s localizeScope) errOutside(path, kustFile string) error {
+	if !s.remote {
+		return fmt.Errorf(
+			"'%s' in '%s' is outside %s", path, kustFile, s.name)
+	}
+	rel, err := filepath.Rel(s.src, kustFile)
+	if err != nil {
+		rel = kustFile
+	}
+	return fmt.Errorf(
+		"'%s' in '%s' of %s escapes the root of the repository",
+		path, filepath.ToSlash(rel), s.name)
+}
+
+// Localize copies this target's kustomization graph into
+// the dest directory, so that it builds without fetching
+// anything, returning the directory of the copy of this
+// target's kustomization.  Load must be called first.
+//
+// The files under the scope, a local directory holding
+// this target's root, keep their place relative to it in
+// dest; a kustomization or file outside the scope is an
+// error.  If this target's root is remote, the scope is
+// the top of its repository.  Each remote ref is fetched
+// into the LocalizedFilesDir beside the kustomization
+// file referring to it, under a path made from its url,
+// e.g. github.com/org/repo/v1.0/path for a git ref, and
+// the kustomization file is rewritten to refer to that
+// copy.  The files of a remote kustomization must be in
+// its repository, as a build requires.  A remote
+// directory lacking a kustomization file is given one
+// listing the manifests a build would load from it.
+//
+// Kustomization files without remote refs are copied as
+// they are.  Inline patches and plugin configs need no
+// copy; remote generator sources aren't supported.
+func (kt *KustTarget) Localize(
+	fSys filesys.FileSystem, scope, dest string) (string, error) {
+	l := &localizer{fSys: fSys, done: make(map[string]bool)}
+	s := localizeScope{
+		src:  scope,
+		dst:  dest,
+		name: fmt.Sprintf("the scope '%s'", scope),
+	}
+	if rl, ok := kt.ldr.(remoteLoader); ok {
+		if root, ok := rl.RemoteRoot(); ok {
+			s.src = cloneTop(kt.ldr.Root(), root)
+			s.name = fmt.Sprintf("the repository '%s'", root.Repo)
+			s.remote = true
+		}
+	}
+	dir, ok := s.dest(kt.ldr.Root())
+	if !ok {
+		return "", fmt.Errorf(
+			"kustomization '%s' is outside %s", kt.ldr.Root(), s.name)
+	}
+	return dir, l.localizeTarget(kt, s)
+}
+
+// remoteLoader is a loader that may have been
+// fetched from a remote repository.
+type remoteLoader interface {
+	RemoteRoot() (fLdr.RemoteRoot, bool)
+}
+
+// cloneTop returns the top of the clone holding
+// the given root, fetched from the given remote.
+func cloneTop(root string, rr fLdr.RemoteRoot) string {
+	if rr.Path == "" {
+		return root
+	}
+	return strings.TrimSuffix(
+		root, string(filepath.Separator)+filepath.FromSlash(rr.Path))
+}
+
+// localizeTarget mirrors accumulateTarget, copying the
+// target's kustomization file, and what it reads, into
+// the scope's destination.
+func (l *localizer) localizeTarget(kt *KustTarget, s localizeScope) error {
+	dir, ok := s.dest(kt.ldr.Root())
+	if !ok {
+		return fmt.Errorf(
+			"kustomization '%s' is outside %s", kt.ldr.Root(), s.name)
+	}
+	if l.done[dir] {
+		return nil
+	}
+	l.done[dir] = true
+	k := kt.kustomization
+	rewrites := make(map[string]string)
+	for _, path := range k.Resources {
+		if err := l.localizeResource(kt, s, dir, path, rewrites); err != nil {
+			return errors.Wrap(err, "localizing resources")
+		}
+	}
+	for _, mi := range k.MultiInstance {
+		if err := l.localizeResource(kt, s, dir, mi.Resource, rewrites); err != nil {
+			return errors.Wrap(err, "localizing instances")
+		}
+	}
+	for _, path := range k.Components {
+		if err := l.localizeDirectory(kt, s, dir, path, true, rewrites); err != nil {
+			return errors.Wrap(err, "localizing components")
+		}
+	}
+	var files []string
+	files = append(files, k.Crds...)
+	files = append(files, k.Configurations...)
+	for _, p := range k.PatchesStrategicMerge {
+		// As in resolvePatches, skip inline patches.
+		if _, err := kt.rFactory.RF().SliceFromBytes([]byte(p)); err != nil {
+			files = append(files, string(p))
+		}
+	}
+	patches := append(k.Patches, k.PatchesJson6902...)
+	for _, mi := range k.MultiInstance {
+		for _, inst := range mi.Instances {
+			patches = append(patches, inst.Patches...)
+		}
+	}
+	for _, p := range patches {
+		if p.Path != "" {
+			files = append(files, p.Path)
+		}
+	}
+	for _, path := range files {
+		if err := l.localizeFile(kt, s, dir, path, rewrites); err != nil {
+			return err
+		}
+	}
+	if err := l.localizeGeneratorSources(kt, s); err != nil {
+		return err
+	}
+	for _, entries := range [][]string{
+		k.Generators, k.Transformers, k.Validators} {
+		for _, p := range entries {
+			if _, err := kt.rFactory.NewResMapFromBytes([]byte(p)); err == nil {
+				// An inline plugin config.
+				continue
+			}
+			if err := l.localizeResource(kt, s, dir, p, rewrites); err != nil {
+				return errors.Wrap(err, "localizing plugins")
+			}
+		}
+	}
+	return l.writeKustFile(kt, dir, rewrites)
+}
+
+// localizeResource mirrors accumulateResources, copying
+// the file or kustomization at the given path.
+func (l *localizer) localizeResource(
+	kt *KustTarget, s localizeScope, dir, path string,
+	rewrites map[string]string) error {
+	if path == types.StdinResourcesPath {
+		// Not a file; there's nothing to copy.
+		return nil
+	}
+	if !isRemote(path) {
+		if err := l.checkScope(kt, s, path); err != nil {
+			return err
+		}
+	}
+	// As in a build, try the path as a file, then as a
+	// directory or repository.
+	errF := l.localizeResourceFile(kt, s, dir, path, rewrites)
+	if errF == nil {
+		return nil
+	}
+	errD := l.localizeDirectory(kt, s, dir, path, false, rewrites)
+	if errD != nil {
+		return multierror.Append(
+			fmt.Errorf("accumulateFile error: %q", errF),
+			fmt.Errorf("accumulateDirectory error: %q", errD),
+		)
+	}
+	return nil
+}
+
+// localizeResourceFile copies the file at the given
+// path if it holds resources.
+func (l *localizer) localizeResourceFile(
+	kt *KustTarget, s localizeScope, dir, path string,
+	rewrites map[string]string) error {
+	data, err := kt.ldr.Load(path)
+	if err != nil {
+		return err
+	}
+	if _, err = kt.rFactory.RF().SliceFromBytes(data); err != nil {
+		return err
+	}
+	return l.writeFile(kt, s, dir, path, data, rewrites)
+}
+
+// localizeFile copies the file at the given path.
+func (l *localizer) localizeFile(
+	kt *KustTarget, s localizeScope, dir, path string,
+	rewrites map[string]string) error {
+	if !isRemote(path) {
+		if err := l.checkScope(kt, s, path); err != nil {
+			return err
+		}
+	}
+	data, err := kt.ldr.Load(path)
+	if err != nil {
+		return err
+	}
+	return l.writeFile(kt, s, dir, path, data, rewrites)
+}
+
+// writeFile writes the copy of the file at the given
+// path, a url or a path relative to the target's root.
+func (l *localizer) writeFile(
+	kt *KustTarget, s localizeScope, dir, path string, data []byte,
+	rewrites map[string]string) error {
+	if isRemote(path) {
+		to := remoteFileDir(path)
+		rewrites[path] = to
+		return l.write(filepath.Join(dir, filepath.FromSlash(to)), data)
+	}
+	to, _ := s.dest(absPath(kt.ldr.Root(), path))
+	if filepath.IsAbs(path) {
+		l.rewrite(rewrites, path, dir, to)
+	}
+	return l.write(to, data)
+}
+
+// localizeGeneratorSources copies the file and env sources
+// of the builtin ConfigMap and Secret generators.  Globs
+// are left as they are, matching the same files in the
+// copy.
+func (l *localizer) localizeGeneratorSources(
+	kt *KustTarget, s localizeScope) error {
+	var paths []string
+	for _, src := range generatorSources(kt.kustomization) {
+		for _, fs := range src.FileSources {
+			more, err := kv.FileSourcePaths(kt.ldr, fs)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, more...)
+		}
+		paths = append(paths, src.EnvSources...)
+	}
+	for _, path := range paths {
+		if isRemote(path) || filepath.IsAbs(path) {
+			return fmt.Errorf(
+				"generator source '%s' in '%s' can't be localized; "+
+					"use a path relative to the kustomization",
+				path, kt.kustFile)
+		}
+		if err := l.localizeFile(kt, s, "", path, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generatorSources(k *types.Kustomization) []types.KvPairSources {
+	var result []types.KvPairSources
+	for _, args := range k.ConfigMapGenerator {
+		result = append(result, args.KvPairSources)
+	}
+	for _, args := range k.SecretGenerator {
+		result = append(result, args.KvPairSources)
+	}
+	return result
+}
+
+// localizeDirectory mirrors accumulateDirectory, copying
+// the kustomization at the given path, a directory or a
+// remote ref.
+func (l *localizer) localizeDirectory(
+	kt *KustTarget, s localizeScope, dir, path string, isComponent bool,
+	rewrites map[string]string) error {
+	remote := isRemote(path)
+	if !remote {
+		if err := l.checkScope(kt, s, path); err != nil {
+			return err
+		}
+	}
+	dirPath, recursive := peelRecursiveQuery(path)
+	ldr, err := kt.ldr.New(dirPath)
+	if err != nil {
+		return fmt.Errorf("loader.New %q", err)
+	}
+	defer ldr.Cleanup()
+	subScope := s
+	if rl, ok := ldr.(remoteLoader); ok && remote {
+		if root, ok := rl.RemoteRoot(); ok {
+			subScope = localizeScope{
+				src: cloneTop(ldr.Root(), root),
+				dst: filepath.Join(
+					dir, filepath.FromSlash(remoteCloneDir(dirPath))),
+				name:   fmt.Sprintf("the repository '%s'", root.Repo),
+				remote: true,
+			}
+		}
+	}
+	to, ok := subScope.dest(ldr.Root())
+	if !ok {
+		return fmt.Errorf(
+			"kustomization '%s' is outside %s", ldr.Root(), subScope.name)
+	}
+	if remote || filepath.IsAbs(path) {
+		l.rewrite(rewrites, path, dir, to)
+	}
+	if rl, ok := asRemoteManifestLoader(ldr); ok {
+		return l.localizeRemoteManifests(rl, to, recursive)
+	}
+	subKt := NewKustTarget(ldr, kt.validator, kt.rFactory, kt.pLdr)
+	if err = subKt.Load(); err != nil {
+		return errors.Wrapf(
+			err, "couldn't make target for path '%s'", ldr.Root())
+	}
+	if err = subKt.errIfUnexpectedKind(isComponent); err != nil {
+		return err
+	}
+	return l.localizeTarget(subKt, subScope)
+}
+
+// localizeRemoteManifests copies the manifests a build would
+// load from a remote directory lacking a kustomization file,
+// adding one that lists them in the order a build loads them.
+func (l *localizer) localizeRemoteManifests(
+	ldr remoteManifestLoader, dir string, recursive bool) error {
+	files, err := ldr.FindFiles(manifestSuffix, recursive)
+	if err != nil {
+		return err
+	}
+	k := types.Kustomization{
+		TypeMeta: types.TypeMeta{
+			APIVersion: types.KustomizationVersion,
+			Kind:       types.KustomizationKind,
+		},
+	}
+	for _, f := range files {
+		data, err := ldr.Load(f)
+		if err != nil {
+			return err
+		}
+		if err = l.write(filepath.Join(dir, f), data); err != nil {
+			return err
+		}
+		k.Resources = append(k.Resources, filepath.ToSlash(f))
+	}
+	data, err := k8syaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+	return l.write(filepath.Join(
+		dir, konfig.DefaultKustomizationFileName()), data)
+}
+
+// checkScope returns an error if the local path, relative
+// to the target's root, is outside the scope.
+func (l *localizer) checkScope(
+	kt *KustTarget, s localizeScope, path string) error {
+	if _, ok := s.dest(absPath(kt.ldr.Root(), path)); ok {
+		return nil
+	}
+	return s.errOutside(path, kt.kustFile)
+}
+
+// rewrite records that the kustomization in the given
+// directory must refer to the path by its copy.
+func (l *localizer) rewrite(
+	rewrites map[string]string, path, dir, to string) {
+	rel, err := filepath.Rel(dir, to)
+	if err != nil {
+		return
+	}
+	rewrites[path] = filepath.ToSlash(rel)
+}
+
+// writeKustFile writes the copy of the target's kustomization
+// file, rewritten to refer to the copies of remote refs.
+func (l *localizer) writeKustFile(
+	kt *KustTarget, dir string, rewrites map[string]string) error {
+	name := filepath.Base(kt.kustFile)
+	data, err := kt.ldr.Load(name)
+	if err != nil {
+		return err
+	}
+	if len(rewrites) > 0 {
+		if data, err = rewriteKustFile(data, rewrites); err != nil {
+			return errors.Wrapf(err, "rewriting '%s'", kt.kustFile)
+		}
+	}
+	return l.write(filepath.Join(dir, name), data)
+}
+
+// rewriteKustFile replaces the paths in the fields of a
+// kustomization file that refer to files or kustomizations,
+// keeping the rest of the file, e.g. its comments.
+func rewriteKustFile(
+	data []byte, rewrites map[string]string) ([]byte, error) {
+	rn, err := yaml.Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	replace := func(n *yaml.Node) {
+		if n.Kind != yaml.ScalarNode {
+			return
+		}
+		if to, ok := rewrites[n.Value]; ok {
+			n.Value = to
+		}
+	}
+	for _, field := range []string{
+		"resources", "bases", "components", "crds", "configurations",
+		"patchesStrategicMerge", "generators", "transformers",
+		"validators"} {
+		f := rn.Field(field)
+		if f == nil || f.Value.YNode().Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range f.Value.YNode().Content {
+			replace(item)
+		}
+	}
+	replacePatchPaths := func(patches *yaml.MapNode) {
+		if patches == nil || patches.Value.YNode().Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range patches.Value.Content() {
+			if p := yaml.NewRNode(item).Field("path"); p != nil {
+				replace(p.Value.YNode())
+			}
+		}
+	}
+	for _, field := range []string{"patches", "patchesJson6902"} {
+		replacePatchPaths(rn.Field(field))
+	}
+	if f := rn.Field("multiInstance"); f != nil &&
+		f.Value.YNode().Kind == yaml.SequenceNode {
+		for _, item := range f.Value.Content() {
+			mi := yaml.NewRNode(item)
+			if r := mi.Field("resource"); r != nil {
+				replace(r.Value.YNode())
+			}
+			instances := mi.Field("instances")
+			if instances == nil ||
+				instances.Value.YNode().Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, inst := range instances.Value.Content() {
+				replacePatchPaths(yaml.NewRNode(inst).Field("patches"))
+			}
+		}
+	}
+	s, err := rn.String()
+	return []byte(s), err
+}
+
+func (l *localizer) write(path string, data []byte) error {
+	if err := l.fSys.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return l.fSys.WriteFile(path, data)
+}
+
+// remoteCloneDir returns the path, relative to a kustomization,
+// of the copy of the top of the repository at the given url,
+// e.g. localized-files/github.com/org/repo/v1.0.
+func remoteCloneDir(u string) string {
+	rs, err := git.NewRepoSpecFromUrl(u)
+	if err != nil {
+		return remoteFileDir(u)
+	}
+	host := rs.Host
+	for _, p := range []string{
+		"https://", "http://", "ssh://", "file://", "git::", "git@"} {
+		host = strings.TrimPrefix(host, p)
+	}
+	host = strings.Trim(strings.Replace(host, ":", "/", -1), "/")
+	ref := rs.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return path.Join(LocalizedFilesDir, host,
+		cleanUrlPath(rs.OrgRepo), cleanUrlPath(ref))
+}
+
+// remoteFileDir returns the path, relative to a kustomization,
+// of the copy of the file, or archive, at the given url,
+// e.g. localized-files/example.com_8080/path/file.yaml.
+func remoteFileDir(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return path.Join(LocalizedFilesDir, cleanUrlPath(u))
+	}
+	// A port is kept apart from the host by an underscore,
+	// as a colon isn't allowed in every file name.
+	return path.Join(LocalizedFilesDir,
+		strings.Replace(parsed.Host, ":", "_", -1),
+		cleanUrlPath(parsed.Path))
+}
+
+// cleanUrlPath keeps the path of a url from escaping
+// the directory it's joined to.
+func cleanUrlPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+func absPath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(root, path)
+}
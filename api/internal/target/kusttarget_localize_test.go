@@ -0,0 +1,208 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	"sigs.k8s.io/kustomize/api/konfig"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+)
+
+const (
+	baseUrl      = "github.com/someOrg/someRepo//base?ref=v1"
+	manifestsUrl = "github.com/someOrg/someRepo//manifests?ref=v1"
+)
+
+// makeLocalizeTarget returns a target at /app whose
+// kustomization refers to a fake clone at /clone, by
+// baseUrl and manifestsUrl.
+func makeLocalizeTarget(
+	t *testing.T, fSys filesys.FileSystem) *target.KustTarget {
+	remoteLoader := func(path string, files []string) ifc.Loader {
+		ldr, err := fLdr.NewLoader(
+			fLdr.RestrictionRootOnly, "/clone/"+path, fSys)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return fakeRemoteLoader{
+			Loader: ldr,
+			root: fLdr.RemoteRoot{
+				Repo: "https://github.com/someOrg/someRepo.git",
+				Ref:  "v1",
+				Path: path,
+			},
+			files: files,
+		}
+	}
+	appLdr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/app", fSys)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	kt := target.NewKustTarget(
+		fakeLocalLoader{
+			Loader: appLdr,
+			remotes: map[string]ifc.Loader{
+				baseUrl:      remoteLoader("base", nil),
+				manifestsUrl: remoteLoader("manifests", []string{"a.yaml"}),
+			},
+		},
+		valtest_test.MakeFakeValidator(),
+		rf,
+		pLdr.NewLoader(konfig.DisabledPluginConfig(), rf))
+	if !assert.NoError(t, kt.Load()) {
+		t.FailNow()
+	}
+	return kt
+}
+
+func writeLocalizeClone(t *testing.T, fSys filesys.FileSystem) {
+	write := func(path, content string) {
+		assert.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	write("/clone/base/kustomization.yaml", `resources:
+- service.yaml
+- ../common
+`)
+	write("/clone/base/service.yaml", `apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+	write("/clone/common/kustomization.yaml", `resources:
+- cm.yaml
+`)
+	write("/clone/common/cm.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	write("/clone/manifests/a.yaml", `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: a
+`)
+	write("/app/local.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: s
+`)
+}
+
+func TestLocalizeRemote(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeLocalizeClone(t, fSys)
+	assert.NoError(t, fSys.WriteFile("/app/kustomization.yaml", []byte(`
+# Remote bases are vendored.
+namePrefix: p-
+resources:
+- `+baseUrl+`
+- `+manifestsUrl+`
+- local.yaml
+`)))
+	kt := makeLocalizeTarget(t, fSys)
+	dir, err := kt.Localize(fSys, "/app", "/out")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "/out", dir)
+
+	const vendored = "/out/localized-files/github.com/someOrg/someRepo/v1"
+	for path, expected := range map[string]string{
+		"/out/kustomization.yaml": `# Remote bases are vendored.
+namePrefix: p-
+resources:
+- localized-files/github.com/someOrg/someRepo/v1/base
+- localized-files/github.com/someOrg/someRepo/v1/manifests
+- local.yaml
+`,
+		"/out/local.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: s
+`,
+		vendored + "/base/kustomization.yaml": `resources:
+- service.yaml
+- ../common
+`,
+		vendored + "/base/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`,
+		vendored + "/common/cm.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+		vendored + "/manifests/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- a.yaml
+`,
+	} {
+		content, err := fSys.ReadFile(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, string(content), path)
+		}
+	}
+
+	// The copy builds to the same resources.
+	expected, err := kt.MakeCustomizedResMap()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ldr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/out", fSys)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	copied := target.NewKustTarget(ldr, valtest_test.MakeFakeValidator(),
+		rf, pLdr.NewLoader(konfig.DisabledPluginConfig(), rf))
+	assert.NoError(t, copied.Load())
+	actual, err := copied.MakeCustomizedResMap()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expectedYaml, err := expected.AsYaml()
+	assert.NoError(t, err)
+	actualYaml, err := actual.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, string(expectedYaml), string(actualYaml))
+}
+
+func TestLocalizeRemoteEscapingItsRepository(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeLocalizeClone(t, fSys)
+	assert.NoError(t, fSys.WriteFile("/clone/base/kustomization.yaml", []byte(`
+resources:
+- service.yaml
+- ../../app/local.yaml
+`)))
+	assert.NoError(t, fSys.WriteFile("/app/kustomization.yaml", []byte(`
+resources:
+- `+baseUrl+`
+`)))
+	kt := makeLocalizeTarget(t, fSys)
+	_, err := kt.Localize(fSys, "/app", "/out")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"'../../app/local.yaml' in 'base/kustomization.yaml' of the "+
+				"repository 'https://github.com/someOrg/someRepo.git' "+
+				"escapes the root of the repository")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// recursiveQuery, in the url of a remote directory lacking
+// a kustomization file, loads the manifests below it too.
+const recursiveQuery = "recursive=true"
+
+// manifestSuffix is the suffix of the manifest files
+// loaded from remote directories lacking a kustomization file.
+const manifestSuffix = ".yaml"
+
+// remoteManifestLoader is a loader whose root
+// was fetched from a remote repository.
+type remoteManifestLoader interface {
+	ifc.Loader
+	RemoteRoot() (fLdr.RemoteRoot, bool)
+	FindFiles(suffix string, recursive bool) ([]string, error)
+}
+
+// asRemoteManifestLoader returns the loader as a
+// remoteManifestLoader if its root was fetched from
+// a remote repository and lacks a kustomization file.
+// Such a directory, e.g. a third party's published
+// manifests, is loaded as a list of resources.
+func asRemoteManifestLoader(ldr ifc.Loader) (remoteManifestLoader, bool) {
+	rl, ok := ldr.(remoteManifestLoader)
+	if !ok {
+		return nil, false
+	}
+	if _, ok = rl.RemoteRoot(); !ok {
+		return nil, false
+	}
+	_, _, err := loadKustFile(ldr)
+	return rl, IsMissingKustomizationFileError(err)
+}
+
+// peelRecursiveQuery removes recursiveQuery from
+// the given url, returning true if it was there.
+func peelRecursiveQuery(url string) (string, bool) {
+	for _, sep := range []string{"?", "&"} {
+		i := strings.Index(url, sep+recursiveQuery)
+		if i < 0 {
+			continue
+		}
+		rest := url[i+len(sep+recursiveQuery):]
+		if rest != "" && rest[0] != '&' {
+			continue
+		}
+		if sep == "?" && rest != "" {
+			// Keep the remaining query.
+			rest = "?" + rest[1:]
+		}
+		return url[:i] + rest, true
+	}
+	return url, false
+}
+
+// accumulateRemoteManifests loads the manifest files in the
+// root of the given loader, in lexical order, recording the
+// remote origin of each resource.
+func (kt *KustTarget) accumulateRemoteManifests(
+	ra *accumulator.ResAccumulator, ldr remoteManifestLoader,
+	recursive bool) (*accumulator.ResAccumulator, error) {
+	defer ldr.Cleanup()
+	root, _ := ldr.RemoteRoot()
+	files, err := ldr.FindFiles(manifestSuffix, recursive)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "listing manifests in '%s'", root.Repo)
+	}
+	if len(files) == 0 {
+		return nil, errors.Wrapf(
+			NewErrMissingKustomization(ldr.Root()),
+			"no kustomization file nor %s files", manifestSuffix)
+	}
+	for _, f := range files {
+		resources, err := kt.rFactory.FromFile(ldr, f)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "accumulating resources from '%s' in '%s'", f, root.Repo)
+		}
+		origin := &resource.Origin{
+			Repo: root.Repo,
+			Ref:  root.Ref,
+			Path: path.Join(root.Path, filepath.ToSlash(f)),
+		}
+		for _, r := range resources.Resources() {
+			r.SetOrigin(origin)
+		}
+		err = kt.errIfNamespaced(
+			resources, fmt.Sprintf("'%s' in '%s'", f, root.Repo))
+		if err != nil {
+			return nil, err
+		}
+		kt.recordFilePositions(resources)
+		if err = ra.AppendAll(resources); err != nil {
+			return nil, errors.Wrapf(
+				err, "merging resources from '%s' in '%s'", f, root.Repo)
+		}
+	}
+	return ra, nil
+}
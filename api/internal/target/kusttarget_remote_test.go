@@ -0,0 +1,156 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	"sigs.k8s.io/kustomize/api/konfig"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+)
+
+// fakeLocalLoader hands out the given loaders,
+// standing in for remote clones, by url.
+type fakeLocalLoader struct {
+	ifc.Loader
+	remotes map[string]ifc.Loader
+}
+
+func (l fakeLocalLoader) New(path string) (ifc.Loader, error) {
+	if r, ok := l.remotes[path]; ok {
+		return r, nil
+	}
+	return l.Loader.New(path)
+}
+
+// fakeRemoteLoader is a loader rooted in a fake clone.
+type fakeRemoteLoader struct {
+	ifc.Loader
+	root fLdr.RemoteRoot
+	// The files found with and without recursion.
+	files, allFiles []string
+}
+
+func (l fakeRemoteLoader) RemoteRoot() (fLdr.RemoteRoot, bool) {
+	return l.root, true
+}
+
+func (l fakeRemoteLoader) FindFiles(
+	_ string, recursive bool) ([]string, error) {
+	if recursive {
+		return l.allFiles, nil
+	}
+	return l.files, nil
+}
+
+func makeRemoteManifestsTarget(
+	t *testing.T, kustomization string) *target.KustTarget {
+	fSys := filesys.MakeFsInMemory()
+	write := func(path, content string) {
+		assert.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	write("/app/kustomization.yaml", kustomization)
+	write("/clone/manifests/a.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: a
+`)
+	write("/clone/manifests/b.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b2
+`)
+	write("/clone/manifests/crds/c.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: c
+`)
+	appLdr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/app", fSys)
+	assert.NoError(t, err)
+	cloneLdr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, "/clone/manifests", fSys)
+	assert.NoError(t, err)
+	remote := fakeRemoteLoader{
+		Loader: cloneLdr,
+		root: fLdr.RemoteRoot{
+			Repo: "https://github.com/someOrg/someRepo.git",
+			Ref:  "v1",
+			Path: "manifests",
+		},
+		files:    []string{"a.yaml", "b.yaml"},
+		allFiles: []string{"a.yaml", "b.yaml", "crds/c.yaml"},
+	}
+	url := "github.com/someOrg/someRepo//manifests?ref=v1"
+	pvd := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(
+		pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory())
+	kt := target.NewKustTarget(
+		fakeLocalLoader{
+			Loader:  appLdr,
+			remotes: map[string]ifc.Loader{url: remote},
+		},
+		valtest_test.MakeFakeValidator(),
+		rf,
+		pLdr.NewLoader(konfig.DisabledPluginConfig(), rf))
+	assert.NoError(t, kt.Load())
+	return kt
+}
+
+func TestRemoteDirectoryWithoutKustomization(t *testing.T) {
+	kt := makeRemoteManifestsTarget(t, `
+namePrefix: p-
+resources:
+- github.com/someOrg/someRepo//manifests?ref=v1
+`)
+	m, err := kt.MakeCustomizedResMap()
+	assert.NoError(t, err)
+	var names []string
+	var origins []resource.Origin
+	for _, r := range m.Resources() {
+		names = append(names, r.GetName())
+		origins = append(origins, *r.GetOrigin())
+	}
+	assert.Equal(t, []string{"p-a", "p-b1", "p-b2"}, names)
+	repo := "https://github.com/someOrg/someRepo.git"
+	assert.Equal(t, []resource.Origin{
+		{Repo: repo, Ref: "v1", Path: "manifests/a.yaml"},
+		{Repo: repo, Ref: "v1", Path: "manifests/b.yaml"},
+		{Repo: repo, Ref: "v1", Path: "manifests/b.yaml"},
+	}, origins)
+}
+
+func TestRemoteDirectoryWithoutKustomizationRecursive(t *testing.T) {
+	for _, url := range []string{
+		"github.com/someOrg/someRepo//manifests?ref=v1&recursive=true",
+		"github.com/someOrg/someRepo//manifests?recursive=true&ref=v1",
+	} {
+		kt := makeRemoteManifestsTarget(t, `
+resources:
+- `+url+`
+`)
+		m, err := kt.MakeCustomizedResMap()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, m.Size())
+		c := m.GetByIndex(3)
+		assert.Equal(t, "c", c.GetName())
+		assert.Equal(t, "manifests/crds/c.yaml", c.GetOrigin().Path)
+	}
+}
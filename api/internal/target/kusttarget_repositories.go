@@ -0,0 +1,101 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// repositoryAlias matches an entry using an alias of the
+// repositories field, capturing the alias and subpath.
+var repositoryAlias = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):(.*)$`)
+
+// reservedAliases are the prefixes of remote urls that
+// would otherwise read as aliases, e.g. https: or gh:.
+var reservedAliases = map[string]bool{
+	"file": true, "gh": true, "git": true,
+	"http": true, "https": true, "ssh": true,
+}
+
+// resolveRepositoryAliases replaces the aliases of the
+// kustomization's repositories field, in its resources,
+// components and patches entries, with the urls they
+// stand for, so that all that reads those entries, e.g.
+// the loading of remote bases, sees the resolved url.
+func resolveRepositoryAliases(k *types.Kustomization) error {
+	if len(k.Repositories) == 0 {
+		return nil
+	}
+	var declared []string
+	for alias, repo := range k.Repositories {
+		if !repositoryAlias.MatchString(alias+":") || reservedAliases[alias] {
+			return fmt.Errorf("repositories: invalid alias '%s'", alias)
+		}
+		if repo.URL == "" {
+			return fmt.Errorf("repositories: alias '%s' has no url", alias)
+		}
+		if strings.Contains(repo.URL, "?") {
+			return fmt.Errorf(
+				"repositories: url of alias '%s' has a query; give the ref as ref",
+				alias)
+		}
+		declared = append(declared, alias)
+	}
+	sort.Strings(declared)
+	resolve := func(field, entry string) (string, error) {
+		if filepath.IsAbs(entry) {
+			return entry, nil
+		}
+		match := repositoryAlias.FindStringSubmatch(entry)
+		if match == nil || reservedAliases[match[1]] {
+			return entry, nil
+		}
+		repo, found := k.Repositories[match[1]]
+		if !found {
+			return "", fmt.Errorf(
+				"%s entry '%s' uses unknown repository alias '%s'; declared: %s",
+				field, entry, match[1], strings.Join(declared, ", "))
+		}
+		return resolvedUrl(repo, match[2]), nil
+	}
+	var err error
+	for i, entry := range k.Resources {
+		if k.Resources[i], err = resolve("resources", entry); err != nil {
+			return err
+		}
+	}
+	for i, entry := range k.Components {
+		if k.Components[i], err = resolve("components", entry); err != nil {
+			return err
+		}
+	}
+	for i, p := range k.Patches {
+		if p.Path == "" {
+			continue
+		}
+		if k.Patches[i].Path, err = resolve("patches", p.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvedUrl returns the url of the given subpath of the
+// repository, at its ref, as a remote resources entry.
+func resolvedUrl(repo types.Repository, subpath string) string {
+	url := strings.TrimSuffix(repo.URL, "/")
+	if subpath = strings.Trim(subpath, "/"); subpath != "" {
+		url += "/" + subpath
+	}
+	if repo.Ref != "" {
+		url += "?ref=" + repo.Ref
+	}
+	return url
+}
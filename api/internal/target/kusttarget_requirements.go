@@ -0,0 +1,75 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Capabilities are those of a build, against which the
+// requires field of each kustomization is checked.
+type Capabilities struct {
+	// Names are the names of the capabilities the build
+	// has, e.g. types.CapabilityHelm.
+	Names map[string]bool
+
+	// ApiVersion is the version of kustomize running the
+	// build, e.g. "v4.1.0", or empty if it's unknown, in
+	// which case minApiVersion isn't checked.
+	ApiVersion string
+}
+
+// SetCapabilities sets the capabilities of the build that
+// the target, and every kustomization it recursively
+// includes, checks its requires field against.  If they
+// aren't set, requires fields aren't checked.
+func (kt *KustTarget) SetCapabilities(c *Capabilities) {
+	kt.capabilities = c
+}
+
+// checkRequirements returns an error naming what the
+// build lacks of what the kustomization requires.
+func (kt *KustTarget) checkRequirements() error {
+	r := kt.kustomization.Requires
+	if r == nil || kt.capabilities == nil {
+		return nil
+	}
+	var missing []string
+	need := func(name string) {
+		if !kt.capabilities.Names[name] {
+			missing = append(missing, fmt.Sprintf("'%s'", name))
+		}
+	}
+	if r.Helm {
+		need(types.CapabilityHelm)
+	}
+	if r.Plugins {
+		need(types.CapabilityPlugins)
+	}
+	for _, name := range r.Capabilities {
+		need(name)
+	}
+	if r.MinApiVersion != "" {
+		min, err := version.ParseGeneric(r.MinApiVersion)
+		if err != nil {
+			return errors.Wrapf(err, "requires.minApiVersion in %s", kt.kustFile)
+		}
+		v, err := version.ParseGeneric(kt.capabilities.ApiVersion)
+		if err == nil && !v.AtLeast(min) {
+			missing = append(missing, fmt.Sprintf(
+				"kustomize %s or later, not %s",
+				r.MinApiVersion, kt.capabilities.ApiVersion))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s requires %s, which the build lacks",
+		kt.kustFile, strings.Join(missing, ", "))
+}
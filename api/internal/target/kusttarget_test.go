@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/target"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
@@ -74,6 +75,71 @@ commonLabels:
 				CommonLabels: map[string]string{"app": "nginx"},
 			},
 		},
+		"repositories": {
+			content: `
+repositories:
+  infra:
+    url: https://github.com/someOrg/infra/
+    ref: v1.2.0
+  apps:
+    url: github.com/someOrg/apps
+resources:
+- infra:base/rbac
+- apps:/web/
+- https://github.com/someOrg/other?ref=v1
+- deployment.yaml
+components:
+- infra:components/tls
+patches:
+- path: infra:patches/replicas.yaml
+- patch: |-
+    kind: Deployment
+`,
+			k: types.Kustomization{
+				TypeMeta: expectedTypeMeta,
+				Repositories: map[string]types.Repository{
+					"infra": {
+						URL: "https://github.com/someOrg/infra/",
+						Ref: "v1.2.0",
+					},
+					"apps": {URL: "github.com/someOrg/apps"},
+				},
+				Resources: []string{
+					"https://github.com/someOrg/infra/base/rbac?ref=v1.2.0",
+					"github.com/someOrg/apps/web",
+					"https://github.com/someOrg/other?ref=v1",
+					"deployment.yaml",
+				},
+				Components: []string{
+					"https://github.com/someOrg/infra/components/tls?ref=v1.2.0",
+				},
+				Patches: []types.Patch{
+					{Path: "https://github.com/someOrg/infra/patches/replicas.yaml?ref=v1.2.0"},
+					{Patch: "kind: Deployment"},
+				},
+			},
+		},
+		"unknownRepositoryAlias": {
+			errContains: "resources entry 'infra:base' uses unknown " +
+				"repository alias 'infra'; declared: apps, infrastructure",
+			content: `
+repositories:
+  infrastructure:
+    url: github.com/someOrg/infra
+  apps:
+    url: github.com/someOrg/apps
+resources:
+- infra:base
+`,
+		},
+		"reservedRepositoryAlias": {
+			errContains: "repositories: invalid alias 'https'",
+			content: `
+repositories:
+  https:
+    url: github.com/someOrg/infra
+`,
+		},
 	}
 
 	kt := makeKustTargetWithRf(
@@ -114,7 +180,7 @@ resources:
 generatorOptions:
   disableNameSuffixHash: false
 configMapGenerator:
-- name: literalConfigMap
+- name: literal-config-map
   literals:
   - DB_USERNAME=admin
   - DB_PASSWORD=somepw
@@ -197,12 +263,12 @@ metadata:
 				},
 			},
 		}),
-		resFactory.FromMapWithName("literalConfigMap",
+		resFactory.FromMapWithName("literal-config-map",
 			map[string]interface{}{
 				"apiVersion": "v1",
 				"kind":       "ConfigMap",
 				"metadata": map[string]interface{}{
-					"name":      "foo-literalConfigMap-bar-g5f6t456f5",
+					"name":      "foo-literal-config-map-bar-g5f6t456f5",
 					"namespace": "ns1",
 					"labels": map[string]interface{}{
 						"app": "nginx",
@@ -257,3 +323,30 @@ metadata:
 	assert.NoError(t, err)
 	assert.Equal(t, expYaml, actYaml)
 }
+
+func TestRequiresMinApiVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+requires:
+  minApiVersion: v4.1
+`)
+	for version, expectedErr := range map[string]string{
+		"v4.0.5": "/app/kustomization.yaml requires kustomize v4.1 " +
+			"or later, not v4.0.5, which the build lacks",
+		"v4.1.0": "",
+		"v4.2.3": "",
+		// Unknown versions aren't checked.
+		"": "",
+	} {
+		kt := makeKustTargetWithRf(
+			t, th.GetFSys(), "/app", provider.NewDefaultDepProvider())
+		kt.SetCapabilities(&target.Capabilities{ApiVersion: version})
+		assert.NoError(t, kt.Load())
+		_, err := kt.MakeCustomizedResMap()
+		if expectedErr == "" {
+			assert.NoError(t, err, version)
+		} else if assert.Error(t, err, version) {
+			assert.Equal(t, expectedErr, err.Error())
+		}
+	}
+}
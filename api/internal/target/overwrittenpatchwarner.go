@@ -0,0 +1,267 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// allowOverwriteOption is the option of a patches entry
+// that turns off the warnings of overwrittenPatchWarner.
+const allowOverwriteOption = "allowOverwrite"
+
+// overwrittenPatchWarner applies an entry of the patches
+// field, recording a PluginResult for each field of a
+// target that the patch sets and that a builtin transformer
+// configured by the same kustomization, which runs after
+// the patches, then sets to another value, e.g. a label of
+// commonLabels.  Users otherwise take such a patch to have
+// been ignored.
+type overwrittenPatchWarner struct {
+	patch  targetedPatch
+	writes []patchWrite
+	source string
+	kt     *KustTarget
+	tc     *builtinconfig.TransformerConfig
+}
+
+var _ resmap.Transformer = &overwrittenPatchWarner{}
+var _ targetedPatch = &overwrittenPatchWarner{}
+
+// patchWrite is a field of its targets that a patch sets.
+type patchWrite struct {
+	// path holds the segments of the field path, e.g.
+	// metadata, labels and app.
+	path  []string
+	value interface{}
+}
+
+// warnIfOverwritten returns the transformer applying the
+// given entry of the patches field, as the given patch
+// does, but warning of the fields it sets that the later
+// builtin transformers overwrite.  If the patch sets none
+// of the fields those may overwrite, or the entry has the
+// allowOverwrite option, the patch is returned as is.
+func (kt *KustTarget) warnIfOverwritten(
+	p resmap.Transformer, i int, pc types.Patch,
+	tc *builtinconfig.TransformerConfig) (resmap.Transformer, error) {
+	tp, ok := p.(targetedPatch)
+	if !ok || pc.Options[allowOverwriteOption] {
+		return p, nil
+	}
+	content := pc.Patch
+	if pc.Path != "" {
+		b, err := kt.ldr.Load(pc.Path)
+		if err != nil {
+			return nil, err
+		}
+		content = string(b)
+	}
+	writes := patchWrites(content)
+	if len(writes) == 0 {
+		return p, nil
+	}
+	source := fmt.Sprintf("patches[%d]", i)
+	if pc.Path != "" {
+		source += fmt.Sprintf(" (%s)", pc.Path)
+	}
+	return &overwrittenPatchWarner{
+		patch: tp, writes: writes, source: source, kt: kt, tc: tc}, nil
+}
+
+// patchWrites returns the metadata fields that the given
+// patch sets, which are those a later builtin transformer
+// may overwrite.  A strategic merge patch can't set the
+// name or namespace, which select what it patches.
+func patchWrites(content string) []patchWrite {
+	// It's read as yaml 1.2, as the PatchTransformer reads
+	// it, so that e.g. a value y isn't taken as true.
+	var patch interface{}
+	if err := yaml.Unmarshal([]byte(content), &patch); err != nil {
+		// The PatchTransformer reports it.
+		return nil
+	}
+	var result []patchWrite
+	switch p := patch.(type) {
+	case []interface{}:
+		// A JSON patch.
+		for _, o := range p {
+			op, _ := o.(map[string]interface{})
+			if op["op"] != "add" && op["op"] != "replace" {
+				continue
+			}
+			path, _ := op["path"].(string)
+			result = appendWrites(result, jsonPointerSegments(path), op["value"])
+		}
+	case map[string]interface{}:
+		md, _ := p["metadata"].(map[string]interface{})
+		for _, field := range []string{"labels", "annotations"} {
+			result = appendWrites(result,
+				[]string{"metadata", field}, md[field])
+		}
+	}
+	return result
+}
+
+// appendWrites appends the writes of the given value at
+// the given path: the value itself if the path is that of
+// a label, annotation, name or namespace, or else those of
+// its fields that are.
+func appendWrites(
+	writes []patchWrite, path []string, value interface{}) []patchWrite {
+	if len(path) == 0 || path[0] != "metadata" {
+		return writes
+	}
+	switch {
+	case len(path) == 3 && (path[1] == "labels" || path[1] == "annotations"),
+		len(path) == 2 && (path[1] == "name" || path[1] == "namespace"):
+		if value != nil {
+			writes = append(writes, patchWrite{path: path, value: value})
+		}
+		return writes
+	case len(path) > 2:
+		return writes
+	}
+	m, _ := value.(map[string]interface{})
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writes = appendWrites(writes, append(path[:len(path):len(path)], k), m[k])
+	}
+	return writes
+}
+
+// jsonPointerSegments returns the unescaped segments of
+// a JSON pointer, e.g. metadata and app.kubernetes.io/name
+// of /metadata/app.kubernetes.io~1name.
+func jsonPointerSegments(p string) []string {
+	if !strings.HasPrefix(p, "/") {
+		return nil
+	}
+	segments := strings.Split(p[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+	}
+	return segments
+}
+
+// Targets implements targetedPatch.
+func (w *overwrittenPatchWarner) Targets(
+	m resmap.ResMap) ([]*resource.Resource, error) {
+	return w.patch.Targets(m)
+}
+
+// TransformTargets implements targetedPatch.
+func (w *overwrittenPatchWarner) TransformTargets(
+	m resmap.ResMap, targets []*resource.Resource) error {
+	for _, r := range targets {
+		for _, write := range w.writes {
+			w.warnIfOverwrites(r, write)
+		}
+	}
+	return w.patch.TransformTargets(m, targets)
+}
+
+// Transform implements resmap.Transformer.
+func (w *overwrittenPatchWarner) Transform(m resmap.ResMap) error {
+	targets, err := w.Targets(m)
+	if err != nil {
+		return err
+	}
+	return w.TransformTargets(m, targets)
+}
+
+// warnIfOverwrites records a PluginResult if a builtin
+// transformer that runs after the patch sets the field of
+// the write, in the resource, to another value.
+func (w *overwrittenPatchWarner) warnIfOverwrites(
+	r *resource.Resource, write patchWrite) {
+	k := w.kt.kustomization
+	value := fmt.Sprintf("%v", write.value)
+	var transformer, field, later string
+	var fieldSpecs types.FsSlice
+	switch write.path[1] {
+	case "labels":
+		var found bool
+		if later, found = k.CommonLabels[write.path[2]]; !found {
+			return
+		}
+		transformer, field = "LabelTransformer", "commonLabels"
+		fieldSpecs = w.tc.CommonLabels
+	case "annotations":
+		var found bool
+		if later, found = k.CommonAnnotations[write.path[2]]; !found {
+			return
+		}
+		transformer, field = "AnnotationsTransformer", "commonAnnotations"
+		fieldSpecs = w.tc.CommonAnnotations
+	case "namespace":
+		if k.Namespace == "" || !r.IsNamespaceableKind() {
+			return
+		}
+		transformer, field = "NamespaceTransformer", "namespace"
+		later = k.Namespace
+		fieldSpecs = w.tc.NameSpace
+	case "name":
+		if (k.NamePrefix == "" && k.NameSuffix == "") ||
+			isPrefixSuffixSkipped(r) {
+			return
+		}
+		transformer, field = "PrefixSuffixTransformer", "namePrefix"
+		if k.NamePrefix == "" {
+			field = "nameSuffix"
+		} else if k.NameSuffix != "" {
+			field = "namePrefix and nameSuffix"
+		}
+		later = k.NamePrefix + value + k.NameSuffix
+		fieldSpecs = w.tc.NamePrefix
+	}
+	if later == value || !writesField(fieldSpecs, r, write.path[:2]) {
+		return
+	}
+	w.kt.rFactory.AddPluginResult(resmap.PluginResult{
+		Plugin:   "PatchTransformer",
+		Resource: r.CurId(),
+		Message: fmt.Sprintf(
+			"%s sets %s, which the %s of %s in %s then sets to %q; "+
+				"give the patch the %s option to allow it",
+			w.source, strings.Join(write.path, "/"), transformer, field,
+			w.kt.kustFile, later, allowOverwriteOption),
+		CurrentValue:   value,
+		SuggestedValue: later,
+	})
+}
+
+// writesField returns true if one of the field specs is
+// that of the given field path, e.g. metadata/labels, and
+// selects the resource.
+func writesField(fieldSpecs types.FsSlice, r *resource.Resource, path []string) bool {
+	for _, fs := range fieldSpecs {
+		if fs.Path == strings.Join(path, "/") && r.GetGvk().IsSelected(&fs.Gvk) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrefixSuffixSkipped returns true if the resource is
+// of a kind whose name the PrefixSuffixTransformer leaves
+// alone.
+func isPrefixSuffixSkipped(r *resource.Resource) bool {
+	gvk := r.OrgId().Gvk
+	return gvk.Kind == "CustomResourceDefinition" ||
+		gvk.Kind == "Namespace" ||
+		(gvk.Group == "apiregistration.k8s.io" && gvk.Kind == "APIService")
+}
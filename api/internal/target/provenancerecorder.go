@@ -0,0 +1,49 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// provenanceRecorder runs a transformer, recording the
+// fields it changes in a ProvenanceTable, and so in the
+// Provenance of the resources.
+type provenanceRecorder struct {
+	t      resmap.Transformer
+	source resmap.Source
+	table  *resmap.ProvenanceTable
+}
+
+var _ resmap.Transformer = &provenanceRecorder{}
+
+// Transform runs the transformer.
+func (p *provenanceRecorder) Transform(m resmap.ResMap) error {
+	return p.table.RecordChanges(m.Resources(), p.source, func() error {
+		return p.t.Transform(m)
+	})
+}
+
+// recordProvenance returns the transformers, of the given
+// kind, each wrapped to record the fields it changes, if
+// the build tracks provenance.
+func (kt *KustTarget) recordProvenance(
+	kind string, ts ...resmap.Transformer) []resmap.Transformer {
+	table := kt.rFactory.ProvenanceTable()
+	if table == nil {
+		return ts
+	}
+	result := make([]resmap.Transformer, len(ts))
+	for i, t := range ts {
+		result[i] = &provenanceRecorder{
+			t: t,
+			source: resmap.Source{
+				Transformer:       kind,
+				KustomizationRoot: kt.ldr.Root(),
+			},
+			table: table,
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var serviceAccountGvk = resid.Gvk{Version: "v1", Kind: "ServiceAccount"}
+
+// attachPullSecrets adds the secrets of the secretGenerator
+// entries with attachToServiceAccounts to the
+// imagePullSecrets of the ServiceAccounts they list.  It
+// runs once the secrets are generated, so adds the names
+// they have then; the hashes, prefixes and suffixes added
+// later are added to the imagePullSecrets too, as to any
+// reference to the secrets.
+func (kt *KustTarget) attachPullSecrets(m resmap.ResMap) error {
+	for _, args := range kt.kustomization.SecretGenerator {
+		if len(args.AttachToServiceAccounts) == 0 {
+			continue
+		}
+		entry := kt.generatorEntry("secretGenerator", "Secret", args.GeneratorArgs)
+		accounts, err := serviceAccountsToAttach(
+			m, entry.Id.EffectiveNamespace(), args.AttachToServiceAccounts)
+		if err != nil {
+			return fmt.Errorf("%s: %v", entry, err)
+		}
+		for _, sa := range accounts {
+			if err = addImagePullSecret(sa, args.Name); err != nil {
+				return fmt.Errorf("%s: %v", entry, err)
+			}
+		}
+	}
+	return nil
+}
+
+// serviceAccountsToAttach returns the ServiceAccounts, in
+// the given namespace, that the attachments select.
+func serviceAccountsToAttach(m resmap.ResMap, ns string,
+	attachments []types.ServiceAccountAttachment) ([]*resource.Resource, error) {
+	var candidates []*resource.Resource
+	for _, r := range m.Resources() {
+		if r.GetGvk().Equals(serviceAccountGvk) &&
+			r.CurId().EffectiveNamespace() == ns {
+			candidates = append(candidates, r)
+		}
+	}
+	var result []*resource.Resource
+	seen := make(map[*resource.Resource]bool)
+	add := func(r *resource.Resource) {
+		if !seen[r] {
+			seen[r] = true
+			result = append(result, r)
+		}
+	}
+	for _, a := range attachments {
+		if a.Selector != nil {
+			matcher, err := resmap.NewSelectorMatcher(*a.Selector)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range candidates {
+				if matcher.Matches(r) {
+					add(r)
+				}
+			}
+		}
+		if a.Name == "" {
+			continue
+		}
+		found := false
+		for _, r := range candidates {
+			if r.GetName() == a.Name {
+				add(r)
+				found = true
+			}
+		}
+		if !found && !a.Optional {
+			return nil, fmt.Errorf(
+				"no ServiceAccount '%s' to attach the secret to", a.Name)
+		}
+	}
+	return result, nil
+}
+
+// addImagePullSecret adds the name to the imagePullSecrets
+// of the ServiceAccount, unless it's there already.
+func addImagePullSecret(sa *resource.Resource, name string) error {
+	return sa.ApplyFilter(kio.FilterAll(yaml.FilterFunc(
+		func(node *yaml.RNode) (*yaml.RNode, error) {
+			secrets, err := node.Pipe(
+				yaml.LookupCreate(yaml.SequenceNode, "imagePullSecrets"))
+			if err != nil {
+				return nil, err
+			}
+			if secrets.Element("name", name) != nil {
+				return node, nil
+			}
+			return node, secrets.PipeE(yaml.Append(
+				yaml.NewMapRNode(&map[string]string{"name": name}).YNode()))
+		})))
+}
@@ -42,10 +42,15 @@ func (k *WNodeFactory) SliceFromBytes(bs []byte) ([]ifc.Kunstructured, error) {
 	var result []ifc.Kunstructured
 	for i := range yamlRNodes {
 		rn := yamlRNodes[i]
-		meta, err := rn.GetValidatedMetadata()
+		// The name is checked by resource.Factory,
+		// which knows which resources may omit it.
+		meta, err := rn.GetMeta()
 		if err != nil {
 			return nil, err
 		}
+		if meta.Kind == "" {
+			return nil, fmt.Errorf("missing kind in object %v", meta)
+		}
 		if !shouldDropObject(meta) {
 			if foundNil, path := rn.HasNilEntryInList(); foundNil {
 				return nil, fmt.Errorf("empty item at %v in object %v", path, rn)
@@ -200,6 +200,7 @@ WOOOOOOOOOOOOOOOOOOOOOOOOT:  woot
 				out: []map[string]interface{}{},
 			},
 		},
+		// The name is checked by resource.Factory.
 		"Missing .metadata.name in object": {
 			input: []byte(`
 apiVersion: v1
@@ -209,7 +210,17 @@ metadata:
     foo: bar
 `),
 			exp: expected{
-				isErr: true,
+				out: []map[string]interface{}{
+					{
+						"apiVersion": "v1",
+						"kind":       "Namespace",
+						"metadata": map[string]interface{}{
+							"annotations": map[string]interface{}{
+								"foo": "bar",
+							},
+						},
+					},
+				},
 			},
 		},
 		"nil value in list": {
@@ -0,0 +1,277 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wrappy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// NonStringKeyWarning reports a map key that isn't a string
+// in the YAML, e.g. the 8080 in data: {8080: foo}.  JSON keys,
+// and so those of kubernetes objects, must be strings, so
+// the key is converted to its string form, "8080".
+type NonStringKeyWarning struct {
+	// Path is the dotted path of the field the key names,
+	// e.g. data.8080.
+	Path string
+	// Tag is the tag the key resolved to, e.g. !!int.
+	Tag string
+}
+
+func (w NonStringKeyWarning) String() string {
+	return fmt.Sprintf(
+		"the %s key of field '%s' was converted to a string", w.Tag, w.Path)
+}
+
+// nodeWarnings collects the warnings made about a node and
+// its copies, e.g. NonStringKeyWarnings, each once, for the
+// caller to report, e.g. as warnings of the build.
+type nodeWarnings struct {
+	mu   sync.Mutex
+	seen map[string]bool
+	list []string
+}
+
+func (k *nodeWarnings) add(ws ...string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, w := range ws {
+		if k.seen[w] {
+			continue
+		}
+		if k.seen == nil {
+			k.seen = make(map[string]bool)
+		}
+		k.seen[w] = true
+		k.list = append(k.list, w)
+	}
+}
+
+func (k *nodeWarnings) strings() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string(nil), k.list...)
+}
+
+// NonFiniteFloatError reports a NaN or infinite float,
+// e.g. .nan or -.inf, which JSON can't represent.
+type NonFiniteFloatError struct {
+	// Path is the dotted path of the field holding it.
+	Path  string
+	Value string
+}
+
+func (e NonFiniteFloatError) Error() string {
+	return fmt.Sprintf(
+		"field '%s' holds %s, but JSON has no NaN or infinite numbers",
+		e.Path, e.Value)
+}
+
+// maxAliasedNodes bounds the nodes copied by expanding
+// aliases, where an error can be returned, so that a small
+// document whose aliases refer to other aliases can't
+// expand to fill memory.  Input is bounded before that,
+// as the resource factory builds resources, by the
+// configurable types.InputLimits.MaxAliasExpansion, whose
+// default is the same.
+const maxAliasedNodes = 100000
+
+// jsonCompatible copies nodes so that the copies decode to
+// values JSON can hold.  Aliases are expanded, and map keys
+// that aren't strings are converted to their string form,
+// each recorded as a warning.  If rejectNonFinite, a NaN or
+// infinite float is an error.
+type jsonCompatible struct {
+	rejectNonFinite bool
+	// maxAliased bounds the nodes copied by expanding
+	// aliases, if it's positive.
+	maxAliased int
+	warnings   []NonStringKeyWarning
+	// the anchored nodes being copied, to catch cycles
+	anchored map[*yaml.Node]bool
+	// the depth of alias expansion, and the nodes copied in it
+	aliasDepth int
+	aliased    int
+}
+
+func (c *jsonCompatible) copy(n *yaml.Node, path []string) (*yaml.Node, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Anchor != "" {
+		if c.anchored == nil {
+			c.anchored = make(map[*yaml.Node]bool)
+		}
+		c.anchored[n] = true
+		defer delete(c.anchored, n)
+	}
+	if c.aliasDepth > 0 {
+		c.aliased++
+		if c.maxAliased > 0 && c.aliased > c.maxAliased {
+			return nil, fmt.Errorf(
+				"field '%s': document contains excessive aliasing",
+				joinFieldPath(path))
+		}
+	}
+	switch n.Kind {
+	case yaml.AliasNode:
+		return c.copyAlias(n, path)
+	case yaml.MappingNode:
+		return c.copyMap(n, path)
+	case yaml.ScalarNode:
+		if c.rejectNonFinite && n.ShortTag() == yaml.NodeTagFloat {
+			var f float64
+			if n.Decode(&f) == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+				return nil, NonFiniteFloatError{
+					Path: joinFieldPath(path), Value: n.Value}
+			}
+		}
+		result := *n
+		return &result, nil
+	default:
+		result := *n
+		result.Content = make([]*yaml.Node, len(n.Content))
+		for i, item := range n.Content {
+			p := path
+			if n.Kind == yaml.SequenceNode {
+				p = appendIndex(path, i)
+			}
+			var err error
+			if result.Content[i], err = c.copy(item, p); err != nil {
+				return nil, err
+			}
+		}
+		return &result, nil
+	}
+}
+
+func (c *jsonCompatible) copyAlias(
+	n *yaml.Node, path []string) (*yaml.Node, error) {
+	if n.Alias == nil {
+		return nil, fmt.Errorf(
+			"field '%s': unknown anchor '%s'", joinFieldPath(path), n.Value)
+	}
+	if c.anchored[n.Alias] {
+		return nil, fmt.Errorf(
+			"field '%s': anchor '%s' value contains itself",
+			joinFieldPath(path), n.Value)
+	}
+	c.aliasDepth++
+	defer func() { c.aliasDepth-- }()
+	return c.copy(n.Alias, path)
+}
+
+func (c *jsonCompatible) copyMap(n *yaml.Node, path []string) (*yaml.Node, error) {
+	result := *n
+	result.Content = make([]*yaml.Node, 0, len(n.Content))
+	seen := make(map[string]bool)
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if key.Kind == yaml.AliasNode {
+			key = key.Alias
+		}
+		if key == nil {
+			continue
+		}
+		k := *key
+		if k.Kind != yaml.ScalarNode {
+			// A map or list key, e.g. ? [a, b]
+			k = yaml.Node{Kind: yaml.ScalarNode, Tag: k.ShortTag(),
+				Value: flowString(key)}
+		}
+		keyPath := append(append([]string{}, path...), k.Value)
+		v, err := c.copy(n.Content[i+1], keyPath)
+		if err != nil {
+			return nil, err
+		}
+		tag := k.ShortTag()
+		if tag == mergeTag && isMergeable(v) {
+			if v.Kind == yaml.MappingNode {
+				merged = append(merged, v)
+			} else {
+				merged = append(merged, v.Content...)
+			}
+			continue
+		}
+		// A << that merges nothing, e.g. <<: null,
+		// can only be meant as a key.
+		if tag != yaml.NodeTagString {
+			c.warnings = append(c.warnings, NonStringKeyWarning{
+				Path: joinFieldPath(keyPath), Tag: tag})
+			k.Tag = yaml.NodeTagString
+		}
+		// e.g. both 8080 and "8080"
+		if seen[k.Value] {
+			return nil, fmt.Errorf(
+				"field '%s' is defined twice", joinFieldPath(keyPath))
+		}
+		seen[k.Value] = true
+		result.Content = append(result.Content, &k, v)
+	}
+	// Merge here rather than leave it to Decode, which
+	// decodes a map with a << key to a map of interface{}
+	// keys.  Keys given in the map, or in an earlier merged
+	// map, win.
+	for _, m := range merged {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if !seen[m.Content[i].Value] {
+				seen[m.Content[i].Value] = true
+				result.Content = append(result.Content, m.Content[i], m.Content[i+1])
+			}
+		}
+	}
+	return &result, nil
+}
+
+// mergeTag is that of the << key, which merges
+// the keys of an anchored map into another.
+const mergeTag = "!!merge"
+
+// isMergeable returns true if the value of a << key
+// is a map or a list of maps, as it must be.
+func isMergeable(v *yaml.Node) bool {
+	if v.Kind == yaml.MappingNode {
+		return true
+	}
+	if v.Kind != yaml.SequenceNode {
+		return false
+	}
+	for _, item := range v.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// flowString returns the YAML of the given node, on one line.
+func flowString(n *yaml.Node) string {
+	c := *yaml.CopyYNode(n)
+	c.Style = yaml.FlowStyle
+	s, err := yaml.NewRNode(&c).String()
+	if err != nil {
+		return fmt.Sprintf("%v", n.Value)
+	}
+	return strings.TrimSpace(s)
+}
+
+func appendIndex(path []string, i int) []string {
+	result := append([]string{}, path...)
+	if len(result) == 0 {
+		return []string{"[" + strconv.Itoa(i) + "]"}
+	}
+	result[len(result)-1] += "[" + strconv.Itoa(i) + "]"
+	return result
+}
+
+func joinFieldPath(path []string) string {
+	return strings.Join(path, ".")
+}
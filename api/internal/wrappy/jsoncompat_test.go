@@ -0,0 +1,275 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wrappy
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestMapConvertsNonStringKeys(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+kind: ConfigMap
+data:
+  8080: foo
+  true: bar
+  nested:
+    1.5: baz
+  ? [a, b]
+  : qux
+`))
+	m := wn.Map()
+	assert.Equal(t, map[string]interface{}{
+		"kind": "ConfigMap",
+		"data": map[string]interface{}{
+			"8080":   "foo",
+			"true":   "bar",
+			"nested": map[string]interface{}{"1.5": "baz"},
+			"[a, b]": "qux",
+		},
+	}, m)
+	expected := []string{
+		"the !!int key of field 'data.8080' was converted to a string",
+		"the !!bool key of field 'data.true' was converted to a string",
+		"the !!float key of field 'data.nested.1.5' was converted to a string",
+		"the !!seq key of field 'data.[a, b]' was converted to a string",
+	}
+	assert.ElementsMatch(t, expected, wn.Warnings())
+
+	// Warned just once per node, including its copies.
+	wn.Map()
+	wn.Copy().Map()
+	assert.ElementsMatch(t, expected, wn.Warnings())
+
+	j, err := wn.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"data":{"8080":"foo","[a, b]":"qux","nested":{"1.5":"baz"},"true":"bar"},"kind":"ConfigMap"}`,
+		string(j))
+}
+
+func TestMapMergeKeys(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+base: &b
+  x: 1
+merged:
+  <<: *b
+  y: 2
+mergedList:
+  <<: [*b, {x: 3, z: 4}]
+notMerged:
+  <<: null
+`))
+	m := wn.Map()
+	assert.Equal(t, map[string]interface{}{
+		"base":       map[string]interface{}{"x": 1},
+		"merged":     map[string]interface{}{"x": 1, "y": 2},
+		"mergedList": map[string]interface{}{"x": 1, "z": 4},
+		"notMerged":  map[string]interface{}{"<<": nil},
+	}, m)
+	assert.Equal(t, []string{
+		"the !!merge key of field 'notMerged.<<' was converted to a string",
+	}, wn.Warnings())
+}
+
+func TestMapOfUndecodableWarns(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+kind: ConfigMap
+data:
+  a: b
+  a: c
+`))
+	// Decoded as far as can be, rather than exiting.
+	assert.Equal(t, map[string]interface{}{"kind": "ConfigMap"}, wn.Map())
+	assert.Equal(t, []string{
+		"the node was only partly decoded: field 'data.a' is defined twice",
+	}, wn.Warnings())
+}
+
+func TestMarshalJSONRejectsUndecodable(t *testing.T) {
+	testCases := map[string]string{
+		`
+data:
+  8080: a
+  "8080": b
+`: "field 'data.8080' is defined twice",
+		`
+a: &x
+  b: *x
+`: "field 'a.b': anchor 'x' value contains itself",
+		`
+a: &a [x, x, x, x, x, x, x, x, x, x]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: [*e, *e, *e, *e, *e, *e, *e, *e, *e, *e]
+`: "document contains excessive aliasing",
+	}
+	for doc, expected := range testCases {
+		_, err := FromRNode(kyaml.MustParse(doc)).MarshalJSON()
+		if assert.Error(t, err, doc) {
+			assert.Contains(t, err.Error(), expected)
+		}
+	}
+}
+
+func TestGetFieldValueConvertsNonStringKeys(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+spec:
+  ports:
+  - 80: http
+  byPort:
+    443: https
+`))
+	v, err := wn.GetFieldValue("spec.byPort")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"443": "https"}, v)
+	if assert.Len(t, wn.Warnings(), 1) {
+		assert.Contains(t, wn.Warnings()[0], "field 'spec.byPort.443'")
+	}
+
+	v, err = wn.GetFieldValue("spec.ports")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"80": "http"}}, v)
+}
+
+func TestMarshalJSONRejectsNonFiniteFloats(t *testing.T) {
+	testCases := map[string]string{
+		`
+data:
+  ratio: .nan
+`: "field 'data.ratio' holds .nan",
+		`
+spec:
+  values:
+  - 1.5
+  - -.Inf
+`: "field 'spec.values[1]' holds -.Inf",
+		`
+base: &b
+  limit: +.inf
+copy: *b
+`: "field 'base.limit' holds +.inf",
+	}
+	for doc, expected := range testCases {
+		_, err := FromRNode(kyaml.MustParse(doc)).MarshalJSON()
+		if assert.Error(t, err, doc) {
+			assert.IsType(t, NonFiniteFloatError{}, err)
+			assert.Contains(t, err.Error(), expected)
+		}
+	}
+
+	// A string isn't a float, and neither is a quoted .nan.
+	j, err := FromRNode(kyaml.MustParse(`
+data:
+  ratio: ".nan"
+  name: nan
+`)).MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":{"name":"nan","ratio":".nan"}}`, string(j))
+}
+
+// yamlFuzzer writes random YAML documents in flow style,
+// heavy on the things JSON can't hold as such.
+type yamlFuzzer struct {
+	r *rand.Rand
+}
+
+var fuzzScalars = []string{
+	"a", "data", "kind", `"quoted"`, `'single'`, "8080", "-1", "0x1F",
+	"1.5", "1e3", ".nan", ".NaN", "-.inf", "+.Inf", "true", "no", "~",
+	"null", "2001-12-14", "!!binary aGVsbG8=", "!!str 12", "!custom x",
+	"*anchor", "&anchor v", "<<",
+}
+
+func (f yamlFuzzer) scalar() string {
+	return fuzzScalars[f.r.Intn(len(fuzzScalars))]
+}
+
+func (f yamlFuzzer) value(depth int) string {
+	if depth > 3 {
+		return f.scalar()
+	}
+	switch f.r.Intn(4) {
+	case 0:
+		return f.mapping(depth + 1)
+	case 1:
+		var items []string
+		for i := f.r.Intn(4); i > 0; i-- {
+			items = append(items, f.value(depth+1))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case 2:
+		return "&anchor " + f.mapping(depth+1)
+	default:
+		return f.scalar()
+	}
+}
+
+func (f yamlFuzzer) mapping(depth int) string {
+	var fields []string
+	for i := f.r.Intn(5); i > 0; i-- {
+		key := f.scalar()
+		if f.r.Intn(8) == 0 {
+			key = "? " + f.value(depth+1)
+		}
+		fields = append(fields, key+": "+f.value(depth))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// TestAccessorsDoNotPanic feeds arbitrary YAML documents
+// to the accessors that mustn't panic or exit on odd input.
+func TestAccessorsDoNotPanic(t *testing.T) {
+	docs := []string{
+		`[1, 2]`, `just a string`, `~`, `{}`, `[]`,
+		`{8080: foo}`, `{data: {1: x, 2: [.nan]}}`,
+		`{? {a: b} : c}`, `{? [a, b] : c}`, `{a: &x {b: 1}, c: *x, d: {<<: *x}}`,
+		`{data: {<<: [{a: 1}, {b: 2}]}}`, `{data: !!binary aGVsbG8=}`,
+		`&x {a: *x}`, `{a: &x [*x]}`,
+		`{a: &a [x, x, x, x], b: &b [*a, *a, *a, *a], c: &c [*b, *b, *b, *b],
+		  d: &d [*c, *c, *c, *c], e: &e [*d, *d, *d, *d], f: &f [*e, *e, *e, *e],
+		  g: &g [*f, *f, *f, *f], h: &h [*g, *g, *g, *g], i: [*h, *h, *h, *h]}`,
+	}
+	f := yamlFuzzer{r: rand.New(rand.NewSource(1))}
+	for i := 0; i < 2000; i++ {
+		docs = append(docs, f.mapping(0))
+	}
+	parsed := 0
+	for _, doc := range docs {
+		rn, err := kyaml.Parse(doc)
+		if err != nil {
+			continue
+		}
+		parsed++
+		wn := FromRNode(rn)
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("panic on %s: %v", doc, r)
+				}
+			}()
+			// Map logs and exits on documents that can't be
+			// decoded, e.g. those with an anchor inside itself.
+			if _, err := wn.jsonCompatible(rn.YNode(), nil, false); err == nil {
+				wn.Map()
+				wn.Copy().Map()
+			}
+			_, _ = wn.MarshalJSON()
+			for _, path := range []string{"data", "a", "8080", "data.a", "a[0]"} {
+				_, _ = wn.GetFieldValue(path)
+				_, _ = wn.GetSlice(path)
+				_, _ = wn.GetString(path)
+			}
+		}()
+	}
+	// Most of the random documents should be valid YAML,
+	// or this tests little.
+	assert.Greater(t, parsed, len(docs)/2)
+}
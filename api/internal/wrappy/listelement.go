@@ -0,0 +1,422 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wrappy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// NoListElementError reports that no element of a list
+// has the field value sought.
+type NoListElementError struct {
+	// Path is the path of the list.
+	Path  string
+	Key   string
+	Value string
+}
+
+func (e NoListElementError) Error() string {
+	return fmt.Sprintf(
+		"no element of list '%s' has %s", e.Path, keyEqualsValue(e.Key, e.Value))
+}
+
+// MultipleListElementsError reports that more than one
+// element of a list has the field value sought.
+type MultipleListElementsError struct {
+	// Path is the path of the list.
+	Path  string
+	Key   string
+	Value string
+	Count int
+}
+
+func (e MultipleListElementsError) Error() string {
+	return fmt.Sprintf(
+		"%d elements of list '%s' have %s, so which is meant is ambiguous",
+		e.Count, e.Path, keyEqualsValue(e.Key, e.Value))
+}
+
+func keyEqualsValue(key, value string) string {
+	if key == "" {
+		return fmt.Sprintf("the value '%s'", value)
+	}
+	return fmt.Sprintf("%s=%s", key, value)
+}
+
+// LookupListElement returns the element of the list at the
+// given path whose field key has the given value, e.g. the
+// container named sidecar with
+//
+//   LookupListElement("spec.template.spec.containers", "name", "sidecar")
+//
+// If key is empty, it's the merge key of the list in the
+// OpenAPI schema of the resource, e.g. name for containers,
+// or, if the list has none and holds scalars, the element
+// equal to the value.  The path may itself select list
+// elements, as in GetFieldValue.
+//
+// The error is a NoFieldError if there's no list at the
+// path, a NoListElementError if no element matches, and
+// a MultipleListElementsError if several do.
+func (wn *WNode) LookupListElement(
+	path string, key string, value string) (*yaml.RNode, error) {
+	segments := fieldPathSegments(path)
+	list, err := wn.lookup(segments)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, NoFieldError{Field: path}
+	}
+	return wn.listElement(list, segments, key, value)
+}
+
+// wildcard is the path segment, written * or [*], that
+// selects every element of a list or every value of a map.
+const wildcard = "*"
+
+// lookup returns the node at the given path segments,
+// or nil if there's none.
+func (wn *WNode) lookup(segments []string) (*yaml.RNode, error) {
+	return wn.lookupFrom(wn.node, nil, segments, false)
+}
+
+// lookupFrom returns the node at the given path segments
+// below rn, which is at the path segments prefix, or nil
+// if there's none.  If create is true, missing fields are
+// created.  The segments can't hold a wildcard.
+func (wn *WNode) lookupFrom(rn *yaml.RNode,
+	prefix []string, segments []string, create bool) (*yaml.RNode, error) {
+	for i := 0; i < len(segments); {
+		j := i
+		for j < len(segments) && !yaml.IsListIndex(segments[j]) {
+			if segments[j] == wildcard {
+				return nil, fmt.Errorf(
+					"field path '%s' has a wildcard, which isn't allowed here",
+					joinFieldPathSegments(concat(prefix, segments)))
+			}
+			j++
+		}
+		if j > i {
+			get := yaml.Lookup(segments[i:j]...)
+			if create {
+				get = yaml.LookupCreate(yaml.MappingNode, segments[i:j]...)
+			}
+			var err error
+			rn, err = rn.Pipe(get)
+			if err != nil && create {
+				// A field on the way is e.g. a scalar.
+				return nil, fmt.Errorf(
+					"can't create the fields of '%s', as one isn't a map: %v",
+					joinFieldPathSegments(concat(prefix, segments[:j])), err)
+			}
+			if err != nil || rn == nil {
+				return nil, err
+			}
+		}
+		if j == len(segments) {
+			break
+		}
+		key, value, err := yaml.SplitIndexNameValue(segments[j])
+		if err != nil {
+			return nil, err
+		}
+		rn, err = wn.listElement(rn, concat(prefix, segments[:j]), key, value)
+		if err != nil {
+			return nil, err
+		}
+		i = j + 1
+	}
+	return rn, nil
+}
+
+// lookupAll returns the nodes at the given path segments
+// below rn, which is at the path segments prefix.  A
+// wildcard segment selects every element of a list, or
+// every value of a map, that can hold the rest of the
+// path, e.g. the maps among the values of spec for
+// spec.*.replicas, skipping the others, such as the
+// scalar spec.replicas.  The nodes below those that lack
+// the rest of the path are skipped too, so the result may
+// be empty.  If create is true, missing fields are created
+// below the last wildcard.
+func (wn *WNode) lookupAll(rn *yaml.RNode,
+	prefix []string, segments []string, create bool) ([]*yaml.RNode, error) {
+	i := 0
+	for i < len(segments) && segments[i] != wildcard {
+		i++
+	}
+	if i == len(segments) {
+		n, err := wn.lookupFrom(rn, prefix, segments, create)
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return []*yaml.RNode{n}, nil
+	}
+	n, err := wn.lookupFrom(rn, prefix, segments[:i], false)
+	if err != nil || n == nil {
+		return nil, err
+	}
+	prefix = concat(prefix, segments[:i])
+	var result []*yaml.RNode
+	add := func(child *yaml.Node, segment string) error {
+		rest := segments[i+1:]
+		if !canHold(child, rest) {
+			return nil
+		}
+		childPrefix := append(prefix[:len(prefix):len(prefix)], segment)
+		if k := lastListSegment(rest); create && k >= 0 && !hasWildcard(rest) {
+			// List elements can't be created, so a value
+			// lacking them is skipped, rather than given
+			// fields in their place.
+			n, err := wn.lookupFrom(
+				yaml.NewRNode(child), childPrefix, rest[:k+1], false)
+			if err != nil || n == nil {
+				return err
+			}
+		}
+		matches, err := wn.lookupAll(
+			yaml.NewRNode(child), childPrefix, rest, create)
+		result = append(result, matches...)
+		return err
+	}
+	content := n.YNode().Content
+	switch n.YNode().Kind {
+	case yaml.SequenceNode:
+		for k, child := range content {
+			if err = add(child, strconv.Itoa(k)); err != nil {
+				return nil, err
+			}
+		}
+	case yaml.MappingNode:
+		for k := 0; k+1 < len(content); k += 2 {
+			if err = add(content[k+1], content[k].Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// canHold returns true if the given node is of the kind
+// the first of the given path segments needs, a list for
+// an index or element selector, a map for a field, and
+// either for a wildcard.  Any node can hold no segments.
+func canHold(n *yaml.Node, segments []string) bool {
+	if len(segments) == 0 {
+		return true
+	}
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	switch s := segments[0]; {
+	case s == wildcard:
+		return n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode
+	case isIndex(s), yaml.IsListIndex(s):
+		return n.Kind == yaml.SequenceNode
+	default:
+		return n.Kind == yaml.MappingNode
+	}
+}
+
+// lastListSegment returns the index of the last of the
+// given path segments that's a list index or element
+// selector, or -1 if none is.
+func lastListSegment(segments []string) int {
+	for k := len(segments) - 1; k >= 0; k-- {
+		if isIndex(segments[k]) || yaml.IsListIndex(segments[k]) {
+			return k
+		}
+	}
+	return -1
+}
+
+// hasWildcard returns true if a path segment is a wildcard.
+func hasWildcard(segments []string) bool {
+	for _, s := range segments {
+		if s == wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+func concat(a, b []string) []string {
+	result := make([]string, 0, len(a)+len(b))
+	return append(append(result, a...), b...)
+}
+
+// listElement returns the element of the given list,
+// at the given path segments, whose field key has the
+// given value.
+func (wn *WNode) listElement(
+	list *yaml.RNode, segments []string,
+	key string, value string) (*yaml.RNode, error) {
+	path := joinFieldPathSegments(segments)
+	if list.YNode().Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("field '%s' is not a list", path)
+	}
+	if key == "" {
+		var err error
+		if key, err = wn.mergeKey(segments); err != nil {
+			return nil, err
+		}
+		if key == "" && !holdsScalars(list) {
+			return nil, fmt.Errorf(
+				"the schema gives no merge key for list '%s', so a key is needed", path)
+		}
+	}
+	var matches []*yaml.RNode
+	for _, e := range list.Content() {
+		n := yaml.NewRNode(e)
+		if key != "" {
+			f := n.Field(key)
+			if f == nil {
+				continue
+			}
+			n = f.Value
+		}
+		if n.YNode().Kind == yaml.ScalarNode && n.YNode().Value == value {
+			matches = append(matches, yaml.NewRNode(e))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, NoListElementError{Path: path, Key: key, Value: value}
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, MultipleListElementsError{
+			Path: path, Key: key, Value: value, Count: len(matches)}
+	}
+}
+
+// mergeKey returns the merge key, if any, of the list at
+// the given path segments, per the OpenAPI schema of the
+// resource, i.e. its patch merge key.
+func (wn *WNode) mergeKey(segments []string) (string, error) {
+	meta, err := wn.node.GetMeta()
+	if err != nil {
+		return "", err
+	}
+	schema := openapi.SchemaForResourceType(meta.TypeMeta)
+	if schema == nil {
+		return "", nil
+	}
+	var schemaPath []string
+	for _, s := range segments {
+		if yaml.IsListIndex(s) || isIndex(s) {
+			s = openapi.Elements
+		}
+		schemaPath = append(schemaPath, s)
+	}
+	schema = schema.Lookup(schemaPath...)
+	if schema == nil {
+		return "", nil
+	}
+	_, key := schema.PatchStrategyAndKey()
+	return key, nil
+}
+
+func holdsScalars(list *yaml.RNode) bool {
+	for _, e := range list.Content() {
+		if e.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldPathSegments splits a field path into the segments
+// that yaml.Lookup takes, e.g.
+//
+//   spec.containers[name=app].ports[0].containerPort
+//
+// into spec, containers, [name=app], ports, 0 and
+// containerPort.  Dots within brackets don't split, and
+// brackets that hold neither an index, a key=value
+// selector nor the wildcard * are part of the field name.
+// Both [*] and a * field are the wildcard segment.
+func fieldPathSegments(path string) []string {
+	var result []string
+	var field strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '.':
+			result = append(result, field.String())
+			field.Reset()
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				field.WriteString(path[i:])
+				i = len(path)
+				continue
+			}
+			inside := path[i+1 : i+end]
+			switch {
+			case isIndex(inside), inside == wildcard:
+			case strings.Contains(inside, "="):
+				inside = "[" + inside + "]"
+			default:
+				field.WriteString(path[i : i+end+1])
+				i += end
+				continue
+			}
+			if field.Len() > 0 {
+				result = append(result, field.String())
+				field.Reset()
+			}
+			result = append(result, inside)
+			i += end
+			// Skip the dot, if any, that ends the segment.
+			if i+1 < len(path) && path[i+1] == '.' {
+				i++
+			}
+		default:
+			field.WriteByte(c)
+		}
+	}
+	if field.Len() > 0 || len(result) == 0 ||
+		path[len(path)-1] == '.' {
+		result = append(result, field.String())
+	}
+	return result
+}
+
+// isIndex returns true if s is a list index, e.g. 0.
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// joinFieldPathSegments undoes fieldPathSegments.
+func joinFieldPathSegments(segments []string) string {
+	var b strings.Builder
+	for i, s := range segments {
+		switch {
+		case yaml.IsListIndex(s):
+			b.WriteString(s)
+		case isIndex(s):
+			b.WriteString("[" + s + "]")
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,382 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wrappy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const deploymentWithSidecar = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  finalizers:
+  - a.example.com
+  - b.example.com
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+        ports:
+        - containerPort: 80
+          name: http
+        - containerPort: 443
+          name: https
+      - name: sidecar
+        image: proxy:2
+        args: [--verbose, --port=9090]
+      - name: twin
+        image: a
+      - name: twin
+        image: b
+`
+
+func TestLookupListElement(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deploymentWithSidecar))
+	const containers = "spec.template.spec.containers"
+
+	e, err := wn.LookupListElement(containers, "name", "sidecar")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "proxy:2", e.Field("image").Value.YNode().Value)
+	}
+	// The merge key of containers is name.
+	e, err = wn.LookupListElement(containers, "", "sidecar")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "proxy:2", e.Field("image").Value.YNode().Value)
+	}
+	// The element is that of the resource.
+	e.Field("image").Value.YNode().Value = "proxy:3"
+	image, err := wn.GetString(containers + "[name=sidecar].image")
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy:3", image)
+
+	// The merge key of ports is containerPort.
+	e, err = wn.LookupListElement(containers+"[name=app].ports", "", "443")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https", e.Field("name").Value.YNode().Value)
+	}
+	// A list of scalars with no merge key.
+	e, err = wn.LookupListElement("metadata.finalizers", "", "b.example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b.example.com", e.YNode().Value)
+	}
+
+	_, err = wn.LookupListElement("spec.template.spec.volumes", "name", "v")
+	assert.Equal(t, NoFieldError{Field: "spec.template.spec.volumes"}, err)
+
+	_, err = wn.LookupListElement(containers, "name", "missing")
+	assert.Equal(t, NoListElementError{
+		Path: containers, Key: "name", Value: "missing"}, err)
+	assert.EqualError(t, err,
+		"no element of list 'spec.template.spec.containers' has name=missing")
+
+	_, err = wn.LookupListElement(containers, "", "twin")
+	assert.Equal(t, MultipleListElementsError{
+		Path: containers, Key: "name", Value: "twin", Count: 2}, err)
+	assert.EqualError(t, err,
+		"2 elements of list 'spec.template.spec.containers' have name=twin, so which is meant is ambiguous")
+
+	_, err = wn.LookupListElement("spec.template", "name", "app")
+	assert.EqualError(t, err, "field 'spec.template' is not a list")
+
+	_, err = wn.LookupListElement(containers+"[name=sidecar].args", "", "--verbose")
+	assert.NoError(t, err)
+
+	_, err = FromRNode(kyaml.MustParse(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  parts:
+  - name: a
+`)).LookupListElement("spec.parts", "", "a")
+	assert.EqualError(t, err,
+		"the schema gives no merge key for list 'spec.parts', so a key is needed")
+}
+
+func TestGetFieldValueListSelectors(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deploymentWithSidecar))
+	testCases := map[string]interface{}{
+		"spec.template.spec.containers[name=sidecar].image":             "proxy:2",
+		"spec.template.spec.containers[=sidecar].image":                 "proxy:2",
+		"spec.template.spec.containers[name=app].ports[1].name":         "https",
+		"spec.template.spec.containers[0].ports[containerPort=80].name": "http",
+		"spec.template.spec.containers[name=sidecar].args[1]":           "--port=9090",
+		"spec.template.spec.containers[name=sidecar]": map[string]interface{}{
+			"name":  "sidecar",
+			"image": "proxy:2",
+			"args":  []interface{}{"--verbose", "--port=9090"},
+		},
+	}
+	for path, expected := range testCases {
+		v, err := wn.GetFieldValue(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, v, path)
+		}
+	}
+
+	_, err := wn.GetFieldValue("spec.template.spec.containers[name=twin].image")
+	assert.IsType(t, MultipleListElementsError{}, err)
+	_, err = wn.GetFieldValue("spec.template.spec.containers[name=none].image")
+	assert.IsType(t, NoListElementError{}, err)
+	_, err = wn.GetFieldValue("spec.template.spec.containers[name=app].env")
+	assert.Equal(t, NoFieldError{
+		Field:    "spec.template.spec.containers[name=app].env",
+		Resolved: "spec.template.spec.containers[name=app]",
+		Missing:  "env"}, err)
+}
+
+// deployment is a Deployment as written in practice, its
+// spec holding scalars, e.g. replicas, beside maps.
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 3
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      app: web
+  strategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: app:1
+      - name: sidecar
+        image: proxy:2
+        args: [--verbose, --port=9090]
+`
+
+func TestGetFieldValueWildcards(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deploymentWithSidecar))
+	testCases := map[string]interface{}{
+		"spec.template.spec.containers[*].image": []interface{}{
+			"app:1", "proxy:2", "a", "b"},
+		"spec.template.spec.containers.*.name": []interface{}{
+			"app", "sidecar", "twin", "twin"},
+		// Containers without ports are skipped.
+		"spec.template.spec.containers[*].ports[*].containerPort": []interface{}{
+			"80", "443"},
+		"spec.template.spec.containers[*].ports[0].name": []interface{}{"http"},
+		"spec.template.spec.containers[name=app].ports[*].name": []interface{}{
+			"http", "https"},
+		"metadata.finalizers[*]": []interface{}{
+			"a.example.com", "b.example.com"},
+		"spec.template.spec.containers[*].env[0].name": []interface{}{},
+	}
+	for path, expected := range testCases {
+		v, err := wn.GetFieldValue(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, v, path)
+		}
+	}
+
+	// A map wildcard skips the values, e.g. spec.replicas,
+	// that can't hold the rest of the path.
+	wn = FromRNode(kyaml.MustParse(deployment))
+	testCases = map[string]interface{}{
+		"spec.*.maxSurge":                     []interface{}{},
+		"spec.*.rollingUpdate.maxSurge":       []interface{}{"1"},
+		"spec.*.matchLabels.app":              []interface{}{"web"},
+		"spec.*.spec.containers[1].name":      []interface{}{"sidecar"},
+		"spec.*.spec.containers[*].image":     []interface{}{"app:1", "proxy:2"},
+		"spec.*.*.containers[*].args":         []interface{}{[]interface{}{"--verbose", "--port=9090"}},
+		"spec.*.replicas":                     []interface{}{},
+		"spec.*[0]":                           []interface{}{},
+		"spec.template.spec.*[name=app].name": []interface{}{"app"},
+		"spec.strategy.*": []interface{}{
+			"RollingUpdate",
+			map[string]interface{}{"maxSurge": 1, "maxUnavailable": 0}},
+	}
+	for path, expected := range testCases {
+		v, err := wn.GetFieldValue(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, v, path)
+		}
+	}
+
+	_, err := wn.LookupListElement("spec.template.spec.containers[*].ports", "", "80")
+	assert.EqualError(t, err, "field path 'spec.template.spec.containers.*.ports' "+
+		"has a wildcard, which isn't allowed here")
+}
+
+func TestSetFieldValue(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deploymentWithSidecar))
+	const containers = "spec.template.spec.containers"
+
+	assert.NoError(t, wn.SetFieldValue(containers+"[*].image", "nginx"))
+	images, err := wn.GetFieldValue(containers + "[*].image")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]interface{}{"nginx", "nginx", "nginx", "nginx"}, images)
+
+	assert.NoError(t, wn.SetFieldValue(containers+"[*].ports[*].protocol", "TCP"))
+	assert.NoError(t, wn.SetFieldValue(containers+"[name=app].ports[0].containerPort", 8080))
+	ports, err := wn.GetFieldValue(containers + "[name=app].ports")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"containerPort": 8080, "name": "http", "protocol": "TCP"},
+		map[string]interface{}{"containerPort": 443, "name": "https", "protocol": "TCP"},
+	}, ports)
+
+	assert.NoError(t, wn.SetFieldValue("metadata.finalizers[*]", "c.example.com"))
+	assert.NoError(t, wn.SetFieldValue("metadata.labels.app", "web"))
+	assert.NoError(t, wn.SetFieldValue(containers+"[name=sidecar].args",
+		[]string{"--quiet"}))
+	assert.NoError(t, wn.SetFieldValue(containers+"[*].env[*].value", "x"))
+	s, err := wn.node.String()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  finalizers:
+  - c.example.com
+  - c.example.com
+  labels:
+    app: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx
+        ports:
+        - containerPort: 8080
+          name: http
+          protocol: TCP
+        - containerPort: 443
+          name: https
+          protocol: TCP
+      - name: sidecar
+        image: nginx
+        args: [--quiet]
+      - name: twin
+        image: nginx
+      - name: twin
+        image: nginx
+`, s)
+
+	err = wn.SetFieldValue(containers+"[name=app].ports[5].name", "x")
+	assert.Equal(t, NoFieldError{
+		Field: containers + "[name=app].ports[5].name"}, err)
+	err = wn.SetFieldValue(containers+"[name=none].image", "x")
+	assert.IsType(t, NoListElementError{}, err)
+	err = wn.SetFieldValue("metadata.name.first", "x")
+	assert.EqualError(t, err,
+		"can't set field 'first' of 'metadata.name', which isn't a map")
+	err = wn.SetFieldValue("metadata.name.first.second", "x")
+	assert.EqualError(t, err,
+		"can't create the fields of 'metadata.name.first', as one isn't "+
+			"a map: wrong Node Kind for metadata.name expected: "+
+			"MappingNode was ScalarNode: value: {web}")
+}
+
+func TestSetFieldValueMapWildcard(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deployment))
+	assert.NoError(t, wn.SetFieldValue("spec.*.rollingUpdate.maxSurge", 2))
+	assert.NoError(t, wn.SetFieldValue("spec.*.spec.containers[1].name", "proxy"))
+	assert.NoError(t, wn.SetFieldValueNoCreate("spec.*.matchLabels.tier", "front"))
+	v, err := wn.GetFieldValue("spec.strategy.rollingUpdate.maxSurge")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", v)
+	v, err = wn.GetFieldValue("spec.template.spec.containers[1].name")
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy", v)
+	v, err = wn.GetFieldValue("spec.selector.matchLabels")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"app": "web", "tier": "front"}, v)
+	v, err = wn.GetFieldValue("spec.replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", v)
+}
+
+func TestSetFieldValueNoCreate(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(deploymentWithSidecar))
+
+	// Missing parents aren't created, and nothing is set.
+	assert.NoError(t, wn.SetFieldValueNoCreate("spec.strategy.type", "Recreate"))
+	_, err := wn.GetFieldValue("spec.strategy")
+	assert.Equal(t, NoFieldError{
+		Field: "spec.strategy", Resolved: "spec", Missing: "strategy"}, err)
+	assert.NoError(t, wn.SetFieldValueNoCreate(
+		"spec.template.spec.containers[*].resources.limits", "x"))
+	_, err = wn.GetFieldValue("spec.template.spec.containers[0].resources")
+	assert.Equal(t, NoFieldError{
+		Field:    "spec.template.spec.containers[0].resources",
+		Resolved: "spec.template.spec.containers[0]",
+		Missing:  "resources"}, err)
+
+	// A missing field of an existing map is set.
+	assert.NoError(t, wn.SetFieldValueNoCreate("spec.replicas", 3))
+	replicas, err := wn.GetFieldValue("spec.replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", replicas)
+
+	err = wn.SetFieldValueNoCreate("metadata.name.first", "x")
+	assert.EqualError(t, err,
+		"can't set field 'first' of 'metadata.name', which isn't a map")
+}
+
+func TestSetFieldValueKeepsComments(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+kind: Deployment
+spec:
+  # Scaled by the autoscaler.
+  replicas: 1
+  paused: false # For now.
+`))
+	assert.NoError(t, wn.SetFieldValue("spec.replicas", 2))
+	s, err := wn.AsRNode().String()
+	assert.NoError(t, err)
+	assert.Equal(t, `kind: Deployment
+spec:
+  # Scaled by the autoscaler.
+  replicas: 2
+  paused: false # For now.
+`, s)
+}
+
+func TestFieldPathSegments(t *testing.T) {
+	testCases := map[string][]string{
+		"":                          {""},
+		"a":                         {"a"},
+		"a.b":                       {"a", "b"},
+		"a[0]":                      {"a", "0"},
+		"a[0].b":                    {"a", "0", "b"},
+		"[0]":                       {"0"},
+		"a[name=x.y].b":             {"a", "[name=x.y]", "b"},
+		"a[=x][k=v]":                {"a", "[=x]", "[k=v]"},
+		"metadata.annotations.a[b]": {"metadata", "annotations", "a[b]"},
+		"a[unclosed.b":              {"a[unclosed.b"},
+		"a.*.b":                     {"a", "*", "b"},
+	}
+	for path, expected := range testCases {
+		segments := fieldPathSegments(path)
+		assert.Equal(t, expected, segments, path)
+		if path != "a[unclosed.b" {
+			assert.Equal(t, path, joinFieldPathSegments(segments), path)
+		}
+	}
+	assert.Equal(t, []string{"a", "*", "b", "0"}, fieldPathSegments("a[*].b[0]"))
+}
@@ -4,10 +4,12 @@
 package wrappy
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
-	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
@@ -24,6 +26,9 @@ import (
 // and want its use to be obvious.
 type WNode struct {
 	node *yaml.RNode
+	// warnings are the warnings about the node, e.g. about
+	// map keys that aren't strings, shared with its copies.
+	warnings *nodeWarnings
 }
 
 var _ ifc.Kunstructured = (*WNode)(nil)
@@ -41,7 +46,7 @@ func FromMap(m map[string]interface{}) (*WNode, error) {
 }
 
 func FromRNode(node *yaml.RNode) *WNode {
-	return &WNode{node: node}
+	return &WNode{node: node, warnings: &nodeWarnings{}}
 }
 
 func (wn *WNode) AsRNode() *yaml.RNode {
@@ -59,7 +64,7 @@ func (wn *WNode) demandMetaData(label string) yaml.ResourceMeta {
 
 // Copy implements ifc.Kunstructured.
 func (wn *WNode) Copy() ifc.Kunstructured {
-	return &WNode{node: wn.node.Copy()}
+	return &WNode{node: wn.node.Copy(), warnings: wn.warnings}
 }
 
 // GetAnnotations implements ifc.Kunstructured.
@@ -67,42 +72,53 @@ func (wn *WNode) GetAnnotations() map[string]string {
 	return wn.demandMetaData("GetAnnotations").Annotations
 }
 
-// convertSliceIndex traverses the items in `fields` and find
-// if there is a slice index in the item and change it to a
-// valid Lookup field path. For example, 'ports[0]' will be
-// converted to 'ports' and '0'.
-func convertSliceIndex(fields []string) []string {
-	var res []string
-	for _, s := range fields {
-		if !strings.HasSuffix(s, "]") {
-			res = append(res, s)
-			continue
-		}
-		re := regexp.MustCompile(`^(.*)\[(\d+)\]$`)
-		groups := re.FindStringSubmatch(s)
-		if len(groups) == 0 {
-			// no match, add to result
-			res = append(res, s)
-			continue
+// GetFieldValue implements ifc.Kunstructured.
+// Besides fields and list indices, e.g. ports[0], the path
+// may select list elements by the value of a field, e.g.
+// containers[name=sidecar].image, or, with no field given,
+// by that of the list's merge key, e.g. containers[=sidecar],
+// as LookupListElement does.
+//
+// A wildcard, written [*] or as a field *, selects every
+// element of a list or every value of a map, e.g.
+// containers[*].image or spec.*.replicas.  A path with one
+// returns a []interface{} holding the value at each match
+// that has the rest of the path, which is empty, not an
+// error, if none does.  Without one, a missing field is a
+// NoFieldError telling how much of the path resolved, e.g.
+//
+//	no field "limits" at "spec.containers[2].resources"
+//	(full path: "spec.containers[2].resources.limits.cpu")
+func (wn *WNode) GetFieldValue(path string) (interface{}, error) {
+	fields := fieldPathSegments(path)
+	if hasWildcard(fields) {
+		nodes, err := wn.lookupAll(wn.node, nil, fields, false)
+		if err != nil {
+			return nil, err
 		}
-		if groups[1] != "" {
-			res = append(res, groups[1])
+		result := []interface{}{}
+		for _, rn := range nodes {
+			v, err := wn.value(rn, path)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v)
 		}
-		res = append(res, groups[2])
+		return result, nil
 	}
-	return res
-}
-
-// GetFieldValue implements ifc.Kunstructured.
-func (wn *WNode) GetFieldValue(path string) (interface{}, error) {
-	fields := convertSliceIndex(strings.Split(path, "."))
-	rn, err := wn.node.Pipe(yaml.Lookup(fields...))
+	rn, err := wn.lookup(fields)
 	if err != nil {
 		return nil, err
 	}
 	if rn == nil {
-		return nil, NoFieldError{path}
+		return nil, wn.noFieldError(path, fields)
 	}
+	return wn.value(rn, path)
+}
+
+// value returns the value of the given node, at the given
+// field path, as GetFieldValue does.
+func (wn *WNode) value(rn *yaml.RNode, path string) (interface{}, error) {
 	yn := rn.YNode()
 
 	// If this is an alias node, resolve it
@@ -112,6 +128,10 @@ func (wn *WNode) GetFieldValue(path string) (interface{}, error) {
 
 	// Return value as map for DocumentNode and MappingNode kinds
 	if yn.Kind == yaml.DocumentNode || yn.Kind == yaml.MappingNode {
+		yn, err := wn.jsonCompatible(yn, []string{path}, false)
+		if err != nil {
+			return nil, err
+		}
 		var result map[string]interface{}
 		if err := yn.Decode(&result); err != nil {
 			return nil, err
@@ -121,6 +141,10 @@ func (wn *WNode) GetFieldValue(path string) (interface{}, error) {
 
 	// Return value as slice for SequenceNode kind
 	if yn.Kind == yaml.SequenceNode {
+		yn, err := wn.jsonCompatible(yn, []string{path}, false)
+		if err != nil {
+			return nil, err
+		}
 		var result []interface{}
 		if err := yn.Decode(&result); err != nil {
 			return nil, err
@@ -132,6 +156,78 @@ func (wn *WNode) GetFieldValue(path string) (interface{}, error) {
 	return yn.Value, nil
 }
 
+// SetFieldValue sets the value at the given field path,
+// which may hold the segments GetFieldValue takes, so
+// containers[*].image sets the image of every container.
+// Missing fields are created, e.g. metadata.labels.app on
+// a resource without labels, except above a wildcard, and
+// a list index or element selector must match.  A path
+// without a wildcard matching nothing is a NoFieldError;
+// one with a wildcard sets nothing.  A wildcard just above
+// the field set selects only the maps, so spec.*.paused
+// skips a scalar spec.replicas.
+func (wn *WNode) SetFieldValue(path string, value interface{}) error {
+	return wn.setFieldValue(path, value, true)
+}
+
+// SetFieldValueNoCreate is SetFieldValue, but creates none
+// of the fields above the last segment of the path; where
+// one is missing, it sets nothing, rather than returning
+// a NoFieldError.
+func (wn *WNode) SetFieldValueNoCreate(path string, value interface{}) error {
+	return wn.setFieldValue(path, value, false)
+}
+
+func (wn *WNode) setFieldValue(
+	path string, value interface{}, create bool) error {
+	fields := fieldPathSegments(path)
+	last := fields[len(fields)-1]
+	parentFields := fields[:len(fields)-1]
+	var err error
+	var targets []*yaml.RNode
+	isField := last != wildcard && !isIndex(last) && !yaml.IsListIndex(last)
+	if isField {
+		targets, err = wn.lookupAll(wn.node, nil, parentFields, create)
+	} else {
+		targets, err = wn.lookupAll(wn.node, nil, fields, false)
+	}
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 && create && !hasWildcard(fields) {
+		return NoFieldError{Field: path}
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		// Each target gets its own copy of the value.
+		rn, err := yaml.Parse(string(data))
+		if err != nil {
+			return err
+		}
+		if !isField {
+			*t.YNode() = *rn.YNode()
+			continue
+		}
+		if t.YNode().Kind != yaml.MappingNode {
+			if len(parentFields) > 0 &&
+				parentFields[len(parentFields)-1] == wildcard {
+				// E.g. spec.replicas, for spec.*.paused.
+				continue
+			}
+			return fmt.Errorf(
+				"can't set field '%s' of '%s', which isn't a map",
+				last, joinFieldPathSegments(parentFields))
+		}
+		if err = t.PipeE(yaml.SetField(last, rn)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetGvk implements ifc.Kunstructured.
 func (wn *WNode) GetGvk() resid.Gvk {
 	meta := wn.demandMetaData("GetGvk")
@@ -149,6 +245,70 @@ func (wn *WNode) SetDataMap(m map[string]string) {
 	wn.node.SetDataMap(m)
 }
 
+// GetDataValue returns the value of the key in the data
+// of the node, as of a ConfigMap, or else the decoded value
+// of the key in its binaryData.  The key isn't parsed as a
+// field path, so may hold dots and slashes, e.g. nginx.conf
+// or settings/prod.yaml.  The error is a NoFieldError if
+// neither holds the key.
+func (wn *WNode) GetDataValue(key string) (string, error) {
+	if v, ok := wn.node.GetDataMap()[key]; ok {
+		return v, nil
+	}
+	m, err := wn.node.Pipe(yaml.Lookup(yaml.BinaryDataField))
+	if err != nil {
+		return "", err
+	}
+	var f *yaml.MapNode
+	if m != nil {
+		// Lookup would parse a key like [a=b] as a list element.
+		f = m.Field(key)
+	}
+	if f == nil {
+		return "", NoFieldError{Field: yaml.DataField + "." + key}
+	}
+	// Long values are split over lines.
+	b, err := base64.StdEncoding.DecodeString(
+		strings.Join(strings.Fields(yaml.GetValue(f.Value)), ""))
+	if err != nil {
+		return "", fmt.Errorf(
+			"can't decode key '%s' of %s: %v", key, yaml.BinaryDataField, err)
+	}
+	return string(b), nil
+}
+
+// SetDataValue sets the key in the data of the node, as of
+// a ConfigMap, to the value, or, if the value isn't valid
+// UTF-8, sets the key in its binaryData to the encoded
+// value, removing the key from the other field.  As in
+// GetDataValue, the key isn't parsed as a field path.
+func (wn *WNode) SetDataValue(key, value string) error {
+	other := yaml.DataField
+	if utf8.ValidString(value) {
+		other = yaml.BinaryDataField
+	}
+	if err := wn.clearDataKey(other, key); err != nil {
+		return err
+	}
+	return wn.node.LoadMapIntoConfigMapData(map[string]string{key: value})
+}
+
+// clearDataKey removes the key from the given field,
+// removing the field too if it's left empty.
+func (wn *WNode) clearDataKey(field, key string) error {
+	m, err := wn.node.Pipe(yaml.Lookup(field))
+	if err != nil || m == nil {
+		return err
+	}
+	if _, err = m.Pipe(yaml.Clear(key)); err != nil {
+		return err
+	}
+	if len(m.Content()) == 0 {
+		_, err = wn.node.Pipe(yaml.Clear(field))
+	}
+	return err
+}
+
 // GetKind implements ifc.Kunstructured.
 func (wn *WNode) GetKind() string {
 	return wn.demandMetaData("GetKind").Kind
@@ -188,14 +348,167 @@ func (wn *WNode) GetString(path string) (string, error) {
 	return "", fmt.Errorf("node %s is not a string: %v", path, value)
 }
 
+// GetBool implements ifc.Kunstructured.
+// The node must be tagged a bool, e.g. true, or be a plain
+// (unquoted) yes, no, on or off, which yaml 1.1, as read
+// by the apiserver, takes as a bool.  A string such as
+// "true" is an error.
+func (wn *WNode) GetBool(path string) (bool, error) {
+	yn, err := wn.scalar(path)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case yn.ShortTag() == yaml.NodeTagBool:
+		var result bool
+		if err := yn.Decode(&result); err != nil {
+			return false, fmt.Errorf("node %s is not a bool: %v", path, err)
+		}
+		return result, nil
+	case yn.ShortTag() == yaml.NodeTagString && yn.Style == 0:
+		if b, found := yaml1_1Bools[yn.Value]; found {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf(
+		"node %s is not a bool: %s %q", path, yn.ShortTag(), yn.Value)
+}
+
+// yaml1_1Bools are the plain scalars that yaml 1.1 takes as
+// bools, and yaml 1.2 as strings.
+var yaml1_1Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"on": true, "On": true, "ON": true,
+	"off": false, "Off": false, "OFF": false,
+}
+
+// GetInt implements ifc.Kunstructured.
+// The node must be tagged an int, e.g. 8080 or 0x1F; a
+// string such as "8080" is an error.
+func (wn *WNode) GetInt(path string) (int64, error) {
+	yn, err := wn.scalar(path)
+	if err != nil {
+		return 0, err
+	}
+	if yn.ShortTag() != yaml.NodeTagInt {
+		return 0, fmt.Errorf(
+			"node %s is not an int: %s %q", path, yn.ShortTag(), yn.Value)
+	}
+	var result int64
+	if err := yn.Decode(&result); err != nil {
+		return 0, fmt.Errorf("node %s is not an int: %v", path, err)
+	}
+	return result, nil
+}
+
+// scalar returns the scalar node at the given field path,
+// which can't hold a wildcard, resolving an alias.  The
+// error is a NoFieldError if there's no such node.
+func (wn *WNode) scalar(path string) (*yaml.Node, error) {
+	segments := fieldPathSegments(path)
+	rn, err := wn.lookup(segments)
+	if err != nil {
+		return nil, err
+	}
+	if rn == nil {
+		return nil, wn.noFieldError(path, segments)
+	}
+	yn := rn.YNode()
+	if yn.Kind == yaml.AliasNode {
+		yn = yn.Alias
+	}
+	if yn.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("node %s is not a scalar", path)
+	}
+	return yn, nil
+}
+
 // Map implements ifc.Kunstructured.
+// Map keys that aren't strings are converted to strings,
+// as they would be by MarshalJSON.  The node of anything
+// but a map, which no resource should have, maps to an
+// empty map.  Aliases are expanded without a bound, as the
+// resource factory bounds them with its InputLimits.  As
+// Map can't return an error, a node it can't convert, e.g.
+// one with a field defined twice, is decoded as far as it
+// can be, with a warning; see Warnings.
 func (wn *WNode) Map() map[string]interface{} {
-	return wn.node.Map()
+	result := make(map[string]interface{})
+	yn := wn.node.YNode()
+	if yn != nil && yn.Kind == yaml.DocumentNode && len(yn.Content) > 0 {
+		yn = yn.Content[0]
+	}
+	if yn == nil || yn.Kind != yaml.MappingNode {
+		return result
+	}
+	compatible, err := wn.jsonCompatibleCopy(yn, nil, jsonCompatible{})
+	if err == nil {
+		err = compatible.Decode(&result)
+	} else {
+		// The decoder keeps what it can decode.
+		_ = yn.Decode(&result)
+	}
+	if err != nil {
+		wn.warnings.add(fmt.Sprintf("the node was only partly decoded: %v", err))
+	}
+	return result
 }
 
 // MarshalJSON implements ifc.Kunstructured.
+// Map keys that aren't strings are converted to strings,
+// and a NaN or infinite float is a NonFiniteFloatError.
 func (wn *WNode) MarshalJSON() ([]byte, error) {
-	return wn.node.MarshalJSON()
+	yn := wn.node.YNode()
+	if yn == nil {
+		return wn.node.MarshalJSON()
+	}
+	yn, err := wn.jsonCompatible(yn, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if yn.Kind == yaml.SequenceNode {
+		var a []interface{}
+		if err := yn.Decode(&a); err != nil {
+			return nil, err
+		}
+		return json.Marshal(a)
+	}
+	m := map[string]interface{}{}
+	if err := yn.Decode(&m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// jsonCompatible returns a copy of the given node, at the
+// given field path, that decodes to values JSON can hold.
+// Map keys that aren't strings are recorded as warnings;
+// see Warnings.
+func (wn *WNode) jsonCompatible(
+	yn *yaml.Node, path []string, rejectNonFinite bool) (*yaml.Node, error) {
+	return wn.jsonCompatibleCopy(yn, path, jsonCompatible{
+		rejectNonFinite: rejectNonFinite, maxAliased: maxAliasedNodes})
+}
+
+// jsonCompatibleCopy copies the node, at the given field
+// path, with c, recording the warnings it makes.
+func (wn *WNode) jsonCompatibleCopy(
+	yn *yaml.Node, path []string, c jsonCompatible) (*yaml.Node, error) {
+	result, err := c.copy(yn, path)
+	for _, w := range c.warnings {
+		wn.warnings.add(w.String())
+	}
+	return result, err
+}
+
+// Warnings returns the warnings made about the node and its
+// copies, each once, in the order made: that map keys that
+// aren't strings were converted to strings, as Map and
+// MarshalJSON must for JSON, and that Map couldn't convert
+// the node fully.
+func (wn *WNode) Warnings() []string {
+	return wn.warnings.strings()
 }
 
 // MatchesAnnotationSelector implements ifc.Kunstructured.
@@ -210,41 +523,75 @@ func (wn *WNode) MatchesLabelSelector(selector string) (bool, error) {
 
 // SetAnnotations implements ifc.Kunstructured.
 func (wn *WNode) SetAnnotations(annotations map[string]string) {
-	if err := wn.node.SetAnnotations(annotations); err != nil {
+	if err := wn.SetAnnotationsE(annotations); err != nil {
 		log.Fatal(err) // interface doesn't allow error.
 	}
 }
 
+// SetAnnotationsE implements ifc.Kunstructured.
+func (wn *WNode) SetAnnotationsE(annotations map[string]string) error {
+	return wn.node.SetAnnotations(annotations)
+}
+
 // SetGvk implements ifc.Kunstructured.
 func (wn *WNode) SetGvk(gvk resid.Gvk) {
-	wn.setMapField(yaml.NewScalarRNode(gvk.Kind), yaml.KindField)
-	wn.setMapField(yaml.NewScalarRNode(gvk.ApiVersion()), yaml.APIVersionField)
+	if err := wn.SetGvkE(gvk); err != nil {
+		log.Fatal(err) // interface doesn't allow error.
+	}
+}
+
+// SetGvkE implements ifc.Kunstructured.
+func (wn *WNode) SetGvkE(gvk resid.Gvk) error {
+	err := wn.setMapField(yaml.NewScalarRNode(gvk.Kind), yaml.KindField)
+	if err != nil {
+		return err
+	}
+	return wn.setMapField(
+		yaml.NewScalarRNode(gvk.ApiVersion()), yaml.APIVersionField)
 }
 
 // SetLabels implements ifc.Kunstructured.
 func (wn *WNode) SetLabels(labels map[string]string) {
-	if err := wn.node.SetLabels(labels); err != nil {
+	if err := wn.SetLabelsE(labels); err != nil {
 		log.Fatal(err) // interface doesn't allow error.
 	}
 }
 
+// SetLabelsE implements ifc.Kunstructured.
+func (wn *WNode) SetLabelsE(labels map[string]string) error {
+	return wn.node.SetLabels(labels)
+}
+
 // SetName implements ifc.Kunstructured.
 func (wn *WNode) SetName(name string) {
-	wn.setMapField(yaml.NewScalarRNode(name), yaml.MetadataField, yaml.NameField)
+	if err := wn.SetNameE(name); err != nil {
+		log.Fatal(err) // interface doesn't allow error.
+	}
+}
+
+// SetNameE implements ifc.Kunstructured.
+func (wn *WNode) SetNameE(name string) error {
+	return wn.setMapField(
+		yaml.NewScalarRNode(name), yaml.MetadataField, yaml.NameField)
 }
 
 // SetNamespace implements ifc.Kunstructured.
 func (wn *WNode) SetNamespace(ns string) {
-	if err := wn.node.SetNamespace(ns); err != nil {
+	if err := wn.SetNamespaceE(ns); err != nil {
 		log.Fatal(err) // interface doesn't allow error.
 	}
 }
 
-func (wn *WNode) setMapField(value *yaml.RNode, path ...string) {
+// SetNamespaceE implements ifc.Kunstructured.
+func (wn *WNode) SetNamespaceE(ns string) error {
+	return wn.node.SetNamespace(ns)
+}
+
+func (wn *WNode) setMapField(value *yaml.RNode, path ...string) error {
 	if err := wn.node.SetMapField(value, path...); err != nil {
-		// Log and die since interface doesn't allow error.
-		log.Fatalf("failed to set field %v: %v", path, err)
+		return fmt.Errorf("failed to set field %v: %v", path, err)
 	}
+	return nil
 }
 
 // UnmarshalJSON implements ifc.Kunstructured.
@@ -252,10 +599,47 @@ func (wn *WNode) UnmarshalJSON(data []byte) error {
 	return wn.node.UnmarshalJSON(data)
 }
 
+// NoFieldError is returned when a field is expected, but
+// missing.  Where it's known, it tells how much of the
+// path resolved.
 type NoFieldError struct {
+	// Field is the full field path.
 	Field string
+
+	// Resolved is the longest prefix of the path that
+	// resolved, empty if its first segment didn't.
+	Resolved string
+
+	// Missing is the segment of the path after Resolved,
+	// that didn't resolve, e.g. limits or [2].
+	Missing string
 }
 
 func (e NoFieldError) Error() string {
-	return fmt.Sprintf("no field named '%s'", e.Field)
+	switch {
+	case e.Missing == "":
+		return fmt.Sprintf("no field named '%s'", e.Field)
+	case e.Resolved == "":
+		return fmt.Sprintf(
+			"no field %q at the top (full path: %q)", e.Missing, e.Field)
+	}
+	return fmt.Sprintf("no field %q at %q (full path: %q)",
+		e.Missing, e.Resolved, e.Field)
+}
+
+// noFieldError returns the NoFieldError of the given path,
+// whose segments don't resolve, finding how much of it
+// does by looking up ever shorter prefixes.
+func (wn *WNode) noFieldError(path string, segments []string) error {
+	i := len(segments) - 1
+	for ; i > 0; i-- {
+		if rn, err := wn.lookup(segments[:i]); err == nil && rn != nil {
+			break
+		}
+	}
+	return NoFieldError{
+		Field:    path,
+		Resolved: joinFieldPathSegments(segments[:i]),
+		Missing:  joinFieldPathSegments(segments[i : i+1]),
+	}
 }
@@ -446,6 +446,50 @@ bar: *a
 	}
 }
 
+func TestGetFieldValueNoFieldError(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+`))
+	for path, expected := range map[string]NoFieldError{
+		"spec.template.spec.containers[0].resources.limits.cpu": {
+			Resolved: "spec.template.spec.containers[0].resources",
+			Missing:  "limits",
+		},
+		"spec.template.spec.containers[2].resources.limits.cpu": {
+			Resolved: "spec.template.spec.containers",
+			Missing:  "[2]",
+		},
+		"status.replicas": {Missing: "status"},
+	} {
+		expected.Field = path
+		_, err := wn.GetFieldValue(path)
+		assert.Equal(t, expected, err, path)
+		_, err = wn.GetString(path)
+		assert.Equal(t, expected, err, path)
+		_, err = wn.GetSlice(path)
+		assert.Equal(t, expected, err, path)
+	}
+	_, err := wn.GetFieldValue(
+		"spec.template.spec.containers[0].resources.limits.cpu")
+	assert.EqualError(t, err, `no field "limits" at `+
+		`"spec.template.spec.containers[0].resources" (full path: `+
+		`"spec.template.spec.containers[0].resources.limits.cpu")`)
+	_, err = wn.GetFieldValue("status.replicas")
+	assert.EqualError(t, err,
+		`no field "status" at the top (full path: "status.replicas")`)
+}
+
 func TestGetString(t *testing.T) {
 	wn := NewWNode()
 	if err := wn.UnmarshalJSON([]byte(deploymentBiggerJson)); err != nil {
@@ -461,6 +505,80 @@ func TestGetString(t *testing.T) {
 	}
 }
 
+const typedFields = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  replicas: &replicas 3
+  mask: 0x1F
+  copies: *replicas
+  port: "8080"
+  large: 12345678901234567890
+  enabled: true
+  tagged: !!bool false
+  legacy: yes
+  quoted: "true"
+  ports:
+  - port: 80
+`
+
+func TestGetInt(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(typedFields))
+	for path, expected := range map[string]int64{
+		"spec.replicas":      3,
+		"spec.mask":          31,
+		"spec.copies":        3,
+		"spec.ports[0].port": 80,
+	} {
+		actual, err := wn.GetInt(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, actual, path)
+		}
+	}
+	for path, expected := range map[string]string{
+		"spec.port":    `node spec.port is not an int: !!str "8080"`,
+		"spec.enabled": `node spec.enabled is not an int: !!bool "true"`,
+		"spec.ports":   "node spec.ports is not a scalar",
+		"spec.large": "node spec.large is not an int: yaml: " +
+			"unmarshal errors:\n  line 11: cannot unmarshal " +
+			"!!int `1234567...` into int64",
+	} {
+		_, err := wn.GetInt(path)
+		assert.EqualError(t, err, expected, path)
+	}
+	_, err := wn.GetInt("spec.missing")
+	assert.Equal(t, NoFieldError{
+		Field: "spec.missing", Resolved: "spec", Missing: "missing"}, err)
+	_, err = wn.GetInt("spec.ports[*].port")
+	assert.Error(t, err)
+}
+
+func TestGetBool(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(typedFields))
+	for path, expected := range map[string]bool{
+		"spec.enabled": true,
+		"spec.tagged":  false,
+		"spec.legacy":  true,
+	} {
+		actual, err := wn.GetBool(path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, expected, actual, path)
+		}
+	}
+	for path, expected := range map[string]string{
+		"spec.quoted":   `node spec.quoted is not a bool: !!str "true"`,
+		"spec.replicas": `node spec.replicas is not a bool: !!int "3"`,
+	} {
+		_, err := wn.GetBool(path)
+		assert.EqualError(t, err, expected, path)
+	}
+	_, err := wn.GetBool("spec.missing")
+	assert.Equal(t, NoFieldError{
+		Field: "spec.missing", Resolved: "spec", Missing: "missing"}, err)
+}
+
 func TestGetSlice(t *testing.T) {
 	bytes, err := yaml.Marshal(makeBigMap())
 	if err != nil {
@@ -517,6 +635,73 @@ func TestSetName(t *testing.T) {
 	}
 }
 
+func TestGetDataValue(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  nginx.conf: |
+    worker_processes 1;
+  settings/prod.yaml: "a: b"
+  "[a=b]": c
+binaryData:
+  logo.png: iVBORw==
+`))
+	v, err := wn.GetDataValue("nginx.conf")
+	assert.NoError(t, err)
+	assert.Equal(t, "worker_processes 1;\n", v)
+	v, err = wn.GetDataValue("settings/prod.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "a: b", v)
+	v, err = wn.GetDataValue("[a=b]")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", v)
+	v, err = wn.GetDataValue("logo.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "\x89PNG", v)
+	_, err = wn.GetDataValue("missing")
+	assert.Equal(t, NoFieldError{Field: "data.missing"}, err)
+}
+
+func TestSetDataValue(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  nginx.conf: old
+  logo.png: text
+`))
+	assert.NoError(t, wn.SetDataValue("nginx.conf", "new"))
+	assert.NoError(t, wn.SetDataValue("settings/prod.yaml", "true"))
+	// A value that isn't UTF-8 moves to binaryData.
+	assert.NoError(t, wn.SetDataValue("logo.png", "\x89PNG"))
+	s, err := wn.AsRNode().String()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  nginx.conf: new
+  settings/prod.yaml: "true"
+binaryData:
+  logo.png: iVBORw==
+`, s)
+
+	// And back, leaving no empty binaryData.
+	assert.NoError(t, wn.SetDataValue("logo.png", "text"))
+	v, err := wn.GetDataValue("logo.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "text", v)
+	_, err = wn.GetFieldValue("binaryData")
+	assert.Equal(t, NoFieldError{
+		Field: "binaryData", Missing: "binaryData"}, err)
+}
+
 func TestSetNamespace(t *testing.T) {
 	wn := NewWNode()
 	if err := wn.UnmarshalJSON([]byte(deploymentBiggerJson)); err != nil {
@@ -588,3 +773,26 @@ func TestSetGvk(t *testing.T) {
 		t.Fatalf("expected '%s', got '%s'", expected, actual)
 	}
 }
+
+func TestSettersReturnErrorOnScalarMetadata(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata: foo
+`))
+	assert.Error(t, wn.SetAnnotationsE(map[string]string{"a": "b"}))
+	assert.Error(t, wn.SetLabelsE(map[string]string{"a": "b"}))
+	assert.Error(t, wn.SetNameE("bar"))
+	assert.Error(t, wn.SetNamespaceE("bar"))
+}
+
+func TestSetGvkE(t *testing.T) {
+	wn := FromRNode(kyaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`))
+	assert.NoError(t, wn.SetGvkE(resid.GvkFromString("grp_ver_knd")))
+	assert.Equal(t, resid.GvkFromString("grp_ver_knd"), wn.GetGvk())
+}
@@ -98,8 +98,9 @@ func (kf *KunstructuredFactoryImpl) MakeSecret(
 	return NewKunstructuredFromObject(o)
 }
 
-// validate validates that u has kind and name
-// except for kind `List`, which doesn't require a name
+// validate validates that u has a kind.
+// The name is checked by resource.Factory, which
+// knows which resources may omit it.
 func (kf *KunstructuredFactoryImpl) validate(u unstructured.Unstructured) error {
 	kind := u.GetKind()
 	if kind == "" {
@@ -107,9 +108,6 @@ func (kf *KunstructuredFactoryImpl) validate(u unstructured.Unstructured) error
 	} else if strings.HasSuffix(kind, "List") {
 		return nil
 	}
-	if u.GetName() == "" {
-		return fmt.Errorf("missing metadata.name in object %v", u)
-	}
 
 	if result, path := checkListItemNil(u.Object); result {
 		return fmt.Errorf("empty item at %v in object %v", path, u)
@@ -150,6 +150,7 @@ WOOOOOOOOOOOOOOOOOOOOOOOOT:  woot
 			expectedErr: false,
 		},
 		{
+			// The name is checked by resource.Factory.
 			name: "Missing .metadata.name in object",
 			input: []byte(`
 apiVersion: v1
@@ -158,8 +159,17 @@ metadata:
   annotations:
     foo: bar
 `),
-			expectedOut: nil,
-			expectedErr: true,
+			expectedOut: []ifc.Kunstructured{factory.FromMap(
+				map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Namespace",
+					"metadata": map[string]interface{}{
+						"annotations": map[string]interface{}{
+							"foo": "bar",
+						},
+					},
+				})},
+			expectedErr: false,
 		},
 		{
 			name: "nil value in list",
@@ -70,6 +70,37 @@ func (fs *UnstructAdapter) Map() map[string]interface{} {
 	return fs.Object
 }
 
+// SetGvkE implements ifc.Kunstructured.  Setting the
+// fields of an unstructured object can't fail.
+func (fs *UnstructAdapter) SetGvkE(g resid.Gvk) error {
+	fs.SetGvk(g)
+	return nil
+}
+
+// SetAnnotationsE implements ifc.Kunstructured.
+func (fs *UnstructAdapter) SetAnnotationsE(m map[string]string) error {
+	fs.SetAnnotations(m)
+	return nil
+}
+
+// SetLabelsE implements ifc.Kunstructured.
+func (fs *UnstructAdapter) SetLabelsE(m map[string]string) error {
+	fs.SetLabels(m)
+	return nil
+}
+
+// SetNameE implements ifc.Kunstructured.
+func (fs *UnstructAdapter) SetNameE(name string) error {
+	fs.SetName(name)
+	return nil
+}
+
+// SetNamespaceE implements ifc.Kunstructured.
+func (fs *UnstructAdapter) SetNamespaceE(ns string) error {
+	fs.SetNamespace(ns)
+	return nil
+}
+
 // SetMap overrides the unstructured content map.
 func (fs *UnstructAdapter) SetMap(m map[string]interface{}) {
 	fs.Object = m
@@ -220,6 +251,11 @@ func (fs *UnstructAdapter) GetInt64(path string) (int64, error) {
 	return 0, NoFieldError{Field: path}
 }
 
+// GetInt returns value at the given fieldpath.
+func (fs *UnstructAdapter) GetInt(path string) (int64, error) {
+	return fs.GetInt64(path)
+}
+
 // GetSlice returns value at the given fieldpath.
 func (fs *UnstructAdapter) GetSlice(path string) ([]interface{}, error) {
 	content, fields, found, err := fs.selectSubtree(path)
@@ -0,0 +1,33 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builtinpluginconsts
+
+// The fields in which a resource names the namespaces of the
+// resources it refers to, so that those can be referenced
+// across namespaces, e.g. a ServiceAccount in another
+// namespace by a RoleBinding subject.  A path may go through
+// lists, and end at a string or a list of strings.  Ingress
+// backends aren't listed, as they name Services in the
+// Ingress's own namespace.
+const (
+	crossNamespaceReferenceFieldSpecs = `
+crossNamespaceReference:
+- path: subjects/namespace
+  kind: RoleBinding
+  group: rbac.authorization.k8s.io
+- path: subjects/namespace
+  kind: ClusterRoleBinding
+  group: rbac.authorization.k8s.io
+- path: spec/namespaceSelector/matchNames
+  kind: ServiceMonitor
+  group: monitoring.coreos.com
+`
+)
+
+// GetCrossNamespaceReferenceFieldSpecs returns the fields
+// in which resources name the namespaces of the resources
+// they refer to.
+func GetCrossNamespaceReferenceFieldSpecs() []byte {
+	return []byte(crossNamespaceReferenceFieldSpecs)
+}
@@ -292,17 +292,26 @@ nameReference:
 - kind: Role
   group: rbac.authorization.k8s.io
   fieldSpecs:
-  - path: roleRef/name
+  - path: roleRef
+    nameField: name
+    kindField: kind
+    apiGroupField: apiGroup
     kind: RoleBinding
     group: rbac.authorization.k8s.io
 
 - kind: ClusterRole
   group: rbac.authorization.k8s.io
   fieldSpecs:
-  - path: roleRef/name
+  - path: roleRef
+    nameField: name
+    kindField: kind
+    apiGroupField: apiGroup
     kind: RoleBinding
     group: rbac.authorization.k8s.io
-  - path: roleRef/name
+  - path: roleRef
+    nameField: name
+    kindField: kind
+    apiGroupField: apiGroup
     kind: ClusterRoleBinding
     group: rbac.authorization.k8s.io
 
@@ -20,6 +20,39 @@ const (
 	// See that variable for an explanation.
 	KustomizePluginHomeEnv = "KUSTOMIZE_PLUGIN_HOME"
 
+	// Name of environment variable holding ExecPluginProtocolVersion,
+	// set when running exec plugins so they can detect capabilities.
+	ExecPluginProtocolVersionEnv = "KUSTOMIZE_PLUGIN_PROTOCOL_VERSION"
+
+	// Names of the other environment variables set when
+	// running exec plugins.  They're a stable contract with
+	// the plugins; see the plugin README.
+	//
+	// ExecPluginConfigPathEnv is the path of the temporary
+	// file holding the plugin's config, the plugin's first
+	// argument, and ExecPluginConfigStringEnv the config.
+	// ExecPluginConfigRootEnv is the root of the loader of
+	// the kustomization listing the plugin, and
+	// ExecPluginBuildRootEnv the root of the top-level
+	// kustomization of the build, empty if there's none.
+	// ExecPluginFeaturesEnv lists the enabled features of
+	// the build, named by the Feature constants, separated
+	// by commas.
+	ExecPluginConfigPathEnv   = "KUSTOMIZE_PLUGIN_CONFIG_PATH"
+	ExecPluginConfigStringEnv = "KUSTOMIZE_PLUGIN_CONFIG_STRING"
+	ExecPluginConfigRootEnv   = "KUSTOMIZE_PLUGIN_CONFIG_ROOT"
+	ExecPluginBuildRootEnv    = "KUSTOMIZE_BUILD_ROOT"
+	ExecPluginFeaturesEnv     = "KUSTOMIZE_FEATURES"
+
+	// Version of the exec plugin protocol.
+	// 1: transformers get resources on stdin, generators get none.
+	// 2: generators may request resources via GeneratorContextAnnotation.
+	ExecPluginProtocolVersion = "2"
+
+	// Annotation on the config of an exec generator plugin that,
+	// if "true", requests the resources accumulated so far on stdin.
+	GeneratorContextAnnotation = "kustomize.config.k8s.io/generator-context"
+
 	// Relative path below XDG_CONFIG_HOME/kustomize to find plugins.
 	// e.g. AbsPluginHome = XDG_CONFIG_HOME/kustomize/plugin
 	RelPluginHome = "plugin"
@@ -41,6 +74,31 @@ const (
 	NoPluginHomeSentinal = "/No/non-builtin/plugins!"
 )
 
+// Names of the features of a build that
+// ExecPluginFeaturesEnv lists when they're enabled.
+const (
+	// Plugins other than the builtin ones may run.
+	FeaturePlugins = "plugins"
+
+	// Exec and starlark functions may run, and
+	// functions may use the network.
+	FeatureExecFunctions     = "exec_functions"
+	FeatureStarlarkFunctions = "starlark_functions"
+	FeatureFunctionNetwork   = "function_network"
+
+	// Resources are manipulated with kyaml.
+	FeatureKyaml = "kyaml"
+
+	// The managed-by label is added to the output.
+	FeatureManagedbyLabel = "managedby_label"
+
+	// Patches may change the names and kinds of resources.
+	FeatureResourceIdChanges = "resource_id_changes"
+
+	// The patch writing each patched field is recorded.
+	FeatureProvenance = "provenance"
+)
+
 func EnabledPluginConfig(b types.BuiltinPluginLoadingOptions) (*types.PluginConfig, error) {
 	dir, err := DefaultAbsPluginHome(filesys.MakeFsOnDisk())
 	if err != nil {
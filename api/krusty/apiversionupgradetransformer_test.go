@@ -0,0 +1,83 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// Transformers listed after the upgrade see the new Gvk.
+func TestApiVersionUpgradeTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- ingress.yaml
+transformers:
+- upgrade.yaml
+- patch.yaml
+`)
+	th.WriteF("/app/upgrade.yaml", `
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: upgrade
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: patch
+patch: |-
+  - op: add
+    path: /spec/ingressClassName
+    value: nginx
+target:
+  group: networking.k8s.io
+  version: v1
+  kind: Ingress
+`)
+	th.WriteF("/app/ingress.yaml", `
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  backend:
+    serviceName: web
+    servicePort: 80
+  rules:
+  - http:
+      paths:
+      - path: /
+        backend:
+          serviceName: web
+          servicePort: http
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  defaultBackend:
+    service:
+      name: web
+      port:
+        number: 80
+  ingressClassName: nginx
+  rules:
+  - http:
+      paths:
+      - backend:
+          service:
+            name: web
+            port:
+              name: http
+        path: /
+        pathType: ImplementationSpecific
+`)
+}
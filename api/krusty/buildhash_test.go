@@ -0,0 +1,77 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// The digests of this build are pinned, as they must be
+// stable across patch releases.  Don't change them unless
+// the normalization is meant to change.
+func TestBuildHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+commonLabels:
+  app: web
+resources:
+- service.yaml
+- deploy.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - a=b
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`)
+	th.WriteF("/app/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.21
+        envFrom:
+        - configMapRef:
+            name: config
+`)
+	for _, legacySort := range []bool{false, true} {
+		opts := th.MakeDefaultOptions()
+		opts.DoLegacyResourceSort = legacySort
+		m := th.Run("/app", opts)
+		h, err := m.Hash()
+		assert.NoError(t, err)
+		assert.Equal(t,
+			"78e026e66f4d5ca9d1182d2ce5e95b1baba963eb4a646e6216730ec85b80de39", h)
+		hashes, err := m.PerResourceHashes()
+		assert.NoError(t, err)
+		assert.Equal(t, map[resid.ResId]string{
+			resid.NewResId(
+				resid.Gvk{Version: "v1", Kind: "Service"},
+				"p-web"): "6169e5ff2dadc176b3120fc3d98f3b621829634991693460db25d17ed093ed51",
+			resid.NewResId(
+				resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"},
+				"p-web"): "c135f1194befeec270bc6ecefed4dda6e3155bf247b8b7d15c5a586363c8252d",
+			resid.NewResId(
+				resid.Gvk{Version: "v1", Kind: "ConfigMap"},
+				"p-config-4h2mbtbbt6"): "57fd135e40eadd67ba6699c7bf5b6418c523d7fceefe3ec026e313c07c2155b8",
+		}, hashes)
+	}
+}
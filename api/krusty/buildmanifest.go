@@ -0,0 +1,66 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// BuildManifest records what a build made, for a later
+// build to be compared to, e.g. via HashMigrationCheck.
+// It marshals to JSON and YAML, so callers can keep it
+// alongside the output of the build.
+type BuildManifest struct {
+	// Generated are the objects made by generators, in
+	// the order of the output.
+	Generated []GeneratedObject `json:"generated,omitempty" yaml:"generated,omitempty"`
+}
+
+// GeneratedObject is an object made by a generator,
+// e.g. a ConfigMap of a configMapGenerator.
+type GeneratedObject struct {
+	// Id is the id the generator gave the object, before
+	// any prefix, suffix or hash was added to its name.
+	Id resid.ResId `json:"id" yaml:"id"`
+
+	// Name and Namespace are those of the object in the
+	// output.
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Hashed is true if a hash of the content was added to
+	// the name.
+	Hashed bool `json:"hashed,omitempty" yaml:"hashed,omitempty"`
+}
+
+// makeBuildManifest returns the manifest of the given
+// output, which must still have the build annotations
+// recording the original ids of its resources.
+func makeBuildManifest(m resmap.ResMap) *BuildManifest {
+	result := &BuildManifest{}
+	for _, r := range m.Resources() {
+		if !r.IsGenerated() {
+			continue
+		}
+		result.Generated = append(result.Generated, GeneratedObject{
+			Id:        r.OrgId(),
+			Name:      r.GetName(),
+			Namespace: r.GetNamespace(),
+			Hashed:    r.NeedHashSuffix(),
+		})
+	}
+	return result
+}
+
+// find returns the object of the manifest with the given
+// id and namespace, or nil if there's none.
+func (bm *BuildManifest) find(id resid.ResId, namespace string) *GeneratedObject {
+	for i, o := range bm.Generated {
+		if o.Id.Equals(id) && o.Namespace == namespace {
+			return &bm.Generated[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/yaml"
+)
+
+func TestChangeSummaries(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  labels:
+    tier: old
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+  namespace: default
+`)
+	th.WriteK("/app", `
+namePrefix: p-
+namespace: prod
+commonLabels:
+  app: web
+  tier: front
+commonAnnotations:
+  owner: team
+resources:
+- resources.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err := k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, k.ChangeSummaries())
+
+	opts.SummarizeChanges = true
+	_, err = k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	out, err := yaml.Marshal(k.ChangeSummaries())
+	assert.NoError(t, err)
+	assert.Equal(t, `- keysAdded:
+  - namespace
+  keysModified:
+  - namespace
+  resources: 2
+  sampleIds:
+  - kind: ConfigMap
+    name: cm
+    namespace: prod
+    version: v1
+  - kind: Service
+    name: svc
+    namespace: prod
+    version: v1
+  transformer: NamespaceTransformer
+- keysModified:
+  - name
+  resources: 2
+  sampleIds:
+  - kind: ConfigMap
+    name: p-cm
+    namespace: prod
+    version: v1
+  - kind: Service
+    name: p-svc
+    namespace: prod
+    version: v1
+  transformer: PrefixSuffixTransformer
+- keysAdded:
+  - app
+  - tier
+  keysModified:
+  - tier
+  resources: 2
+  sampleIds:
+  - kind: ConfigMap
+    name: p-cm
+    namespace: prod
+    version: v1
+  - kind: Service
+    name: p-svc
+    namespace: prod
+    version: v1
+  transformer: LabelTransformer
+- keysAdded:
+  - owner
+  resources: 2
+  sampleIds:
+  - kind: ConfigMap
+    name: p-cm
+    namespace: prod
+    version: v1
+  - kind: Service
+    name: p-svc
+    namespace: prod
+    version: v1
+  transformer: AnnotationsTransformer
+`, string(out))
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeChecksumApp(th kusttest_test.Harness, disableHash bool) {
+	opts := ""
+	if disableHash {
+		opts = `
+generatorOptions:
+  disableNameSuffixHash: true
+`
+	}
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: app-config
+  literals:
+  - mode=fast
+transformers:
+- checksum.yaml
+`+opts)
+	th.WriteF("/app/checksum.yaml", `
+apiVersion: builtin
+kind: ChecksumTransformer
+metadata:
+  name: checksum
+annotationName: checksum/config
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: app-config
+`)
+}
+
+func checksumOf(t *testing.T, th kusttest_test.Harness) string {
+	m := th.Run("/app", th.MakeDefaultOptions())
+	for _, r := range m.Resources() {
+		if r.GetKind() != "Deployment" {
+			continue
+		}
+		s, err := r.GetString(
+			"spec.template.metadata.annotations.checksum/config")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	t.Fatalf("no deployment in output")
+	return ""
+}
+
+func TestChecksumTransformerIgnoresNameSuffixHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeChecksumApp(th, false)
+	hashed := checksumOf(t, th)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	yml, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(yml), "name: app-config-") {
+		t.Fatalf("expected a hash suffixed reference:\n%s", yml)
+	}
+
+	th = kusttest_test.MakeHarness(t)
+	writeChecksumApp(th, true)
+	if unhashed := checksumOf(t, th); unhashed != hashed {
+		t.Fatalf("checksum depends on naming mode: %s != %s", unhashed, hashed)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestCleanForSourceTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- exported.yaml
+transformers:
+- clean.yaml
+`)
+	th.WriteF("/app/clean.yaml", `
+apiVersion: builtin
+kind: CleanForSourceTransformer
+metadata:
+  name: clean
+`)
+	th.WriteF("/app/exported.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  creationTimestamp: "2021-03-01T10:00:00Z"
+  resourceVersion: "12345"
+  uid: 0b4e3f2a-1c1d-4e5f-8a9b-0c1d2e3f4a5b
+spec:
+  ports:
+  - port: 80
+status:
+  loadBalancer: {}
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`)
+}
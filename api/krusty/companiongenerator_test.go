@@ -0,0 +1,124 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeMonitoredServices(th kusttest_test.Harness) {
+	th.WriteF("/app/services.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    monitoring: "on"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+`)
+}
+
+func TestCompanionGenerator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMonitoredServices(th)
+	th.WriteF("/app/monitors.yaml", `
+apiVersion: builtin
+kind: CompanionGenerator
+metadata:
+  name: serviceMonitors
+target:
+  kind: Service
+  labelSelector: monitoring=on
+template:
+  apiVersion: monitoring.coreos.com/v1
+  kind: ServiceMonitor
+  metadata:
+    name: $(TARGET_NAME)
+  spec:
+    endpoints:
+    - port: metrics
+`)
+	th.WriteK("/app", `
+namePrefix: prod-
+commonLabels:
+  env: prod
+resources:
+- services.yaml
+generators:
+- monitors.yaml
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: prod
+    monitoring: "on"
+  name: prod-web
+spec:
+  selector:
+    env: prod
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: prod
+  name: prod-db
+spec:
+  selector:
+    env: prod
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  labels:
+    env: prod
+  name: prod-web
+spec:
+  endpoints:
+  - port: metrics
+`)
+}
+
+func TestCompanionGeneratorMissingLabel(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMonitoredServices(th)
+	th.WriteF("/app/policies.yaml", `
+apiVersion: builtin
+kind: CompanionGenerator
+metadata:
+  name: networkPolicies
+target:
+  kind: Service
+template:
+  apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata:
+    name: $(TARGET_NAME)
+  spec:
+    podSelector:
+      matchLabels:
+        monitoring: $(TARGET_LABEL:monitoring)
+`)
+	th.WriteK("/app", `
+resources:
+- services.yaml
+generators:
+- policies.yaml
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"target ~G_v1_Service|~X|db has no label 'monitoring'")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// compatibilityFlags hold the behaviors of a build known to
+// differ between kustomize minor versions, so that a
+// CompatibilityLevel can pin them.
+type compatibilityFlags struct {
+	// legacySort sorts the output by kind, as per
+	// DoLegacyResourceSort.
+	legacySort bool
+
+	// apiMachineryYaml reads and writes YAML via apimachinery
+	// rather than kyaml, as per UseKyaml.  It governs three
+	// of the differences: apimachinery quotes label and
+	// annotation values that look like numbers or booleans,
+	// keeps "creationTimestamp: null", and encodes the data
+	// of generated ConfigMaps and Secrets, from which their
+	// name hashes are computed, differently.
+	apiMachineryYaml bool
+}
+
+// compatibilityLevels maps each supported
+// CompatibilityLevel to the behaviors it pins.
+var compatibilityLevels = map[string]compatibilityFlags{
+	"v3.8": {legacySort: true, apiMachineryYaml: true},
+	"v3.9": {legacySort: true, apiMachineryYaml: false},
+}
+
+// SupportedCompatibilityLevels returns the values
+// Options.CompatibilityLevel may take, sorted.
+func SupportedCompatibilityLevels() []string {
+	var result []string
+	for level := range compatibilityLevels {
+		result = append(result, level)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// compatibility returns the flags pinned by the options'
+// CompatibilityLevel, or nil if it's empty.
+func (o Options) compatibility() (*compatibilityFlags, error) {
+	if o.CompatibilityLevel == "" {
+		return nil, nil
+	}
+	flags, found := compatibilityLevels[o.CompatibilityLevel]
+	if !found {
+		return nil, fmt.Errorf(
+			"unknown compatibility level '%s'; supported levels are %s",
+			o.CompatibilityLevel,
+			strings.Join(SupportedCompatibilityLevels(), ", "))
+	}
+	return &flags, nil
+}
+
+// useKyaml is UseKyaml, unless the CompatibilityLevel pins it.
+func (o Options) useKyaml() bool {
+	if flags, err := o.compatibility(); err == nil && flags != nil {
+		return !flags.apiMachineryYaml
+	}
+	return o.UseKyaml
+}
+
+// doLegacyResourceSort is DoLegacyResourceSort,
+// unless the CompatibilityLevel pins it.
+func (o Options) doLegacyResourceSort() bool {
+	if flags, err := o.compatibility(); err == nil && flags != nil {
+		return flags.legacySort
+	}
+	return o.DoLegacyResourceSort
+}
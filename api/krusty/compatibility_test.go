@@ -0,0 +1,224 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestCompatibilityLevelUnknown(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources: []
+`)
+	opts := th.MakeDefaultOptions()
+	opts.CompatibilityLevel = "v2.0"
+	err := th.RunWithErr(".", opts)
+	if assert.Error(t, err) {
+		assert.Equal(t,
+			"unknown compatibility level 'v2.0'; "+
+				"supported levels are v3.8, v3.9", err.Error())
+	}
+	assert.Equal(t,
+		[]string{"v3.8", "v3.9"}, krusty.SupportedCompatibilityLevels())
+}
+
+func runAtCompatibilityLevels(
+	th kusttest_test.Harness, levels ...string) map[string]string {
+	result := make(map[string]string)
+	for _, level := range levels {
+		opts := th.MakeDefaultOptions()
+		opts.CompatibilityLevel = level
+		yml, err := th.Run(".", opts).AsYaml()
+		if err != nil {
+			th.GetT().Fatal(err)
+		}
+		result[level] = string(yml)
+	}
+	return result
+}
+
+func TestCompatibilitySortOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("resources.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+`)
+	out := runAtCompatibilityLevels(th, "v3.8", "v3.9")
+	for _, level := range []string{"v3.8", "v3.9"} {
+		assert.Equal(t, `apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`, out[level], level)
+	}
+
+	// Without a level, the options decide.
+	opts := th.MakeDefaultOptions()
+	opts.DoLegacyResourceSort = false
+	th.AssertActualEqualsExpected(th.Run(".", opts), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+`)
+}
+
+func TestCompatibilityQuoting(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- service.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    port: "8080"
+    happy: "true"
+`)
+	th.WriteF("patch.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  clusterIP: None
+`)
+	out := runAtCompatibilityLevels(th, "v3.8", "v3.9")
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    happy: "true"
+    port: "8080"
+  name: web
+spec:
+  clusterIP: None
+`, out["v3.8"])
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    happy: true
+    port: 8080
+  name: web
+spec:
+  clusterIP: None
+`, out["v3.9"])
+}
+
+// The supported levels agree on keeping the "null" of an
+// unpatched creationTimestamp; this locks that in.
+func TestCompatibilityCreationTimestamp(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      creationTimestamp: null
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`)
+	out := runAtCompatibilityLevels(th, "v3.8", "v3.9")
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      creationTimestamp: null
+    spec:
+      containers:
+      - image: nginx
+        name: web
+`, out["v3.8"])
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      creationTimestamp: null
+    spec:
+      containers:
+      - image: nginx
+        name: web
+`, out["v3.9"])
+}
+
+func TestCompatibilityGeneratedNameHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+secretGenerator:
+- name: passwords
+  files:
+  - poem.txt
+`)
+	th.WriteF("poem.txt", `
+Life is short.
+But the years are long.
+Not while the evil days come not.
+`)
+	out := runAtCompatibilityLevels(th, "v3.8", "v3.9")
+	assert.Equal(t, `apiVersion: v1
+data:
+  poem.txt: CkxpZmUgaXMgc2hvcnQuCkJ1dCB0aGUgeWVhcnMgYXJlIGxvbmcuCk5vdCB3aGlsZSB0aGUgZXZpbCBkYXlzIGNvbWUgbm90Lgo=
+kind: Secret
+metadata:
+  name: passwords-8g4764bk77
+type: Opaque
+`, out["v3.8"])
+	assert.Equal(t, `apiVersion: v1
+data:
+  poem.txt: |
+    CkxpZmUgaXMgc2hvcnQuCkJ1dCB0aGUgeWVhcnMgYXJlIGxvbmcuCk5vdCB3aGlsZSB0aG
+    UgZXZpbCBkYXlzIGNvbWUgbm90Lgo=
+kind: Secret
+metadata:
+  name: passwords-5mh5694tcm
+type: Opaque
+`, out["v3.9"])
+}
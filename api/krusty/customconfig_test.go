@@ -314,3 +314,74 @@ spec:
   location: Arizona
 `)
 }
+
+func TestCustomConfigNameReferenceWithSiblingFields(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: x-
+resources:
+- resources.yaml
+configurations:
+- config.yaml
+`)
+	th.WriteF("/app/config.yaml", `
+nameReference:
+- kind: Gorilla
+  fieldSpecs:
+  - path: metadata/ownerReferences
+    nameField: name
+    kindField: kind
+    apiVersionField: apiVersion
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: zoo/v1
+kind: Gorilla
+metadata:
+  name: koko
+---
+apiVersion: zoo/v1
+kind: Giraffe
+metadata:
+  name: koko
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: diet
+  ownerReferences:
+  - apiVersion: zoo/v1
+    kind: Gorilla
+    name: koko
+    uid: 0c2e
+  - apiVersion: zoo/v1
+    kind: Giraffe
+    name: koko
+    uid: 4f1a
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: zoo/v1
+kind: Gorilla
+metadata:
+  name: x-koko
+---
+apiVersion: zoo/v1
+kind: Giraffe
+metadata:
+  name: x-koko
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: x-diet
+  ownerReferences:
+  - apiVersion: zoo/v1
+    kind: Gorilla
+    name: x-koko
+    uid: 0c2e
+  - apiVersion: zoo/v1
+    kind: Giraffe
+    name: koko
+    uid: 4f1a
+`)
+}
@@ -41,7 +41,7 @@ resources:
 generatorOptions:
   disableNameSuffixHash: false
 configMapGenerator:
-- name: literalConfigMap
+- name: literal-config-map
   literals:
   - DB_USERNAME=admin
   - DB_PASSWORD=somepw
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// errEmptyOutput returns the error of a build, of the
+// given target, that made no resources while the options
+// say it must make some.  It counts what the build read,
+// as ResolveInputs lists it, since e.g. one kustomization
+// and no resource files suggests a mistyped path.  The
+// build's BuildManifest lists only what was generated, so
+// the inputs are listed again.
+func (b *Kustomizer) errEmptyOutput(kt *target.KustTarget) error {
+	inputs, err := kt.ResolveInputs(b.fSys, b.loadRestrictor())
+	if err != nil {
+		return errors.Wrap(err,
+			"the build made no resources, and listing what it read failed")
+	}
+	var kustomizations, resources, generators, others int
+	for _, in := range inputs {
+		switch in.Role {
+		case types.InputRoleKustomization:
+			kustomizations++
+		case types.InputRoleResource:
+			resources++
+		case types.InputRoleGenerator, types.InputRoleGeneratorSource:
+			generators++
+		default:
+			others++
+		}
+	}
+	// The kustomization built isn't a base or component.
+	bases := kustomizations - 1
+	if bases < 0 {
+		bases = 0
+	}
+	return fmt.Errorf(
+		"the build made no resources, having read kustomizations: %d "+
+			"(bases and components: %d), resource files: %d, "+
+			"generator configs and sources: %d, other files: %d",
+		kustomizations, bases, resources, generators, others)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestErrorOnEmptyOutput(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namePrefix: p-
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+	opts := th.MakeDefaultOptions()
+	m := th.Run("/app/overlay", opts)
+	assert.Equal(t, 0, m.Size())
+
+	opts.ErrorOnEmptyOutput = true
+	err := th.RunWithErr("/app/overlay", opts)
+	if assert.Error(t, err) {
+		assert.Equal(t,
+			"the build made no resources, having read kustomizations: 2 "+
+				"(bases and components: 1), resource files: 0, "+
+				"generator configs and sources: 0, other files: 0",
+			err.Error())
+	}
+}
+
+func TestWarningWhenTransformersRemoveAll(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteF("/app/delete.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+$patch: delete
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- delete.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, m.Size())
+		assert.Equal(t, []string{
+			"the transformers of /app/kustomization.yaml " +
+				"removed all 1 of its resources",
+		}, k.Warnings())
+	}
+
+	opts.ErrorOnEmptyOutput = true
+	_, err = krusty.MakeKustomizer(th.GetFSys(), &opts).Run("/app")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"the build made no resources, having read kustomizations: 1 "+
+				"(bases and components: 0), resource files: 1, "+
+				"generator configs and sources: 0, other files: 1")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeClusterScopedBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/base/resources.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: widget-reader
+`)
+}
+
+func TestEnforceClusterScopedOnly(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeClusterScopedBase(th)
+	th.WriteK("/app", `
+enforceClusterScopedOnly: true
+namePrefix: acme-
+resources:
+- base
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: acme-widget-reader
+`)
+}
+
+func TestEnforceClusterScopedOnlyNamespacedResources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeClusterScopedBase(th)
+	th.WriteK("/app", `
+enforceClusterScopedOnly: true
+resources:
+- base
+- more.yaml
+`)
+	th.WriteF("/app/more.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: widget-controller
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: widget-writer
+  namespace: widgets
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"enforceClusterScopedOnly: in 'more.yaml', "+
+				"listed in /app/kustomization.yaml:\n"+
+				"  ~G_v1_ServiceAccount|~X|widget-controller "+
+				"is of a namespaced kind\n"+
+				"  rbac.authorization.k8s.io_v1_ClusterRole|widgets|widget-writer "+
+				"has namespace 'widgets'")
+	}
+}
+
+func TestEnforceClusterScopedOnlyGenerators(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+enforceClusterScopedOnly: true
+configMapGenerator:
+- name: settings
+  literals:
+  - a=b
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"enforceClusterScopedOnly: in the output of generator "+
+				"*builtins.ConfigMapGeneratorPlugin of /app/kustomization.yaml:\n"+
+				"  ~G_v1_ConfigMap|~X|settings is of a namespaced kind")
+	}
+}
+
+func TestEnforceClusterScopedOnlyNamespaceFields(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeClusterScopedBase(th)
+	th.WriteK("/app/base", `
+namespace: widgets
+namespaceSuffix: -prod
+resources:
+- resources.yaml
+`)
+	th.WriteK("/app", `
+enforceClusterScopedOnly: true
+resources:
+- base
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"enforceClusterScopedOnly: /app/base/kustomization.yaml "+
+				"sets namespace, namespaceSuffix")
+	}
+
+	// Only the kustomization setting it, and those it
+	// includes, are held to it.
+	th.WriteK("/app/base", `
+enforceClusterScopedOnly: true
+resources:
+- resources.yaml
+`)
+	th.WriteK("/app", `
+namespace: widgets
+resources:
+- base
+- serviceaccount.yaml
+`)
+	th.WriteF("/app/serviceaccount.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: widget-controller
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	assert.Equal(t, 3, m.Size())
+}
+
+func TestEnforceClusterScopedOnlyTransformedNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeClusterScopedBase(th)
+	th.WriteK("/app", `
+enforceClusterScopedOnly: true
+resources:
+- base
+patches:
+- target:
+    kind: ClusterRole
+  patch: |-
+    - op: add
+      path: /metadata/namespace
+      value: sneaky
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"enforceClusterScopedOnly: in the output of /app/kustomization.yaml:\n"+
+				"  rbac.authorization.k8s.io_v1_ClusterRole|sneaky|widget-reader "+
+				"has namespace 'sneaky'")
+	}
+}
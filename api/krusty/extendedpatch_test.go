@@ -4,6 +4,7 @@
 package krusty_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -1213,3 +1214,90 @@ spec:
     app: busybox
 `)
 }
+
+func TestExtendedPatchWithoutName(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+resources:
+- deployments.yaml
+patches:
+- target:
+    kind: Deployment
+    labelSelector: tier=web
+  options:
+    strict: true
+  patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    spec:
+      replicas: 3
+`)
+	th.WriteF("/app/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+  labels:
+    tier: db
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: p-web
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: p-db
+`)
+}
+
+func TestExtendedPatchWithoutNameStrictNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+patches:
+- target:
+    kind: Deployment
+    labelSelector: tier=cache
+  options:
+    strict: true
+  patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    spec:
+      replicas: 3
+`)
+	th.WriteF("/app/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(), "strict patch target matches no resources") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
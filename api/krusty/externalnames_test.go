@@ -0,0 +1,163 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeOptionalRefs(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+configMapGenerator:
+- name: settings
+  literals:
+  - level=debug
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        env:
+        - name: LEVEL
+          valueFrom:
+            configMapKeyRef:
+              name: settings
+              key: level
+        - name: REGION
+          valueFrom:
+            configMapKeyRef:
+              name: settings
+              key: region
+              optional: true
+`)
+}
+
+// An optional ref to a ConfigMap in the build is updated
+// like any other.
+func TestOptionalConfigMapKeyRef(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOptionalRefs(th)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: LEVEL
+          valueFrom:
+            configMapKeyRef:
+              key: level
+              name: settings-ck5fk26hc4
+        - name: REGION
+          valueFrom:
+            configMapKeyRef:
+              key: region
+              name: settings-ck5fk26hc4
+              optional: true
+        image: web
+        name: web
+---
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: settings-ck5fk26hc4
+`)
+}
+
+// A ref to an external ConfigMap sharing its name with one
+// in the build is left alone.
+func TestExternalNames(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOptionalRefs(th)
+	opts := th.MakeDefaultOptions()
+	opts.ExternalNames = []resid.ResId{
+		resid.NewResId(resid.Gvk{Kind: "ConfigMap"}, "settings")}
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: LEVEL
+          valueFrom:
+            configMapKeyRef:
+              key: level
+              name: settings
+        - name: REGION
+          valueFrom:
+            configMapKeyRef:
+              key: region
+              name: settings
+              optional: true
+        image: web
+        name: web
+---
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: settings-ck5fk26hc4
+`)
+
+	opts.ExternalNames = []resid.ResId{
+		resid.NewResIdWithNamespace(
+			resid.Gvk{Kind: "ConfigMap"}, "settings", "elsewhere")}
+	m = th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: LEVEL
+          valueFrom:
+            configMapKeyRef:
+              key: level
+              name: settings-ck5fk26hc4
+        - name: REGION
+          valueFrom:
+            configMapKeyRef:
+              key: region
+              name: settings-ck5fk26hc4
+              optional: true
+        image: web
+        name: web
+---
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: settings-ck5fk26hc4
+`)
+}
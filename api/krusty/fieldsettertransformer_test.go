@@ -0,0 +1,92 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeFieldSetterBase(th kusttest_test.Harness) {
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+`)
+}
+
+func TestFieldSetterTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFieldSetterBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+transformers:
+- setter.yaml
+`)
+	th.WriteF("/app/setter.yaml", `
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: resources
+target:
+  kind: Deployment
+fieldPath: spec.template.spec.containers[name=app].resources
+value:
+  limits:
+    memory: 1Gi
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: app:1
+        name: app
+        resources:
+          limits:
+            memory: 1Gi
+`)
+}
+
+func TestFieldSetterTransformerIntoScalar(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeFieldSetterBase(th)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+transformers:
+- setter.yaml
+`)
+	th.WriteF("/app/setter.yaml", `
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: replicas
+fieldPath: spec.replicas.count
+value: 2
+create: true
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"can't set field 'count' of 'spec.replicas', which isn't a map")
+	}
+}
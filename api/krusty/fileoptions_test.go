@@ -0,0 +1,89 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const fileOptionsKustomization = `
+configMapGenerator:
+- name: app
+  files:
+  - app.conf
+  - logo.png
+  - cert.der
+  fileOptions:
+    newlineNormalization: lf
+    ensureTrailingNewline: true
+    binaryFiles:
+    - cert.der
+`
+
+// A checkout with CRLF line endings and no trailing
+// newline generates the same ConfigMap, with the same
+// hash, as one with LF line endings.  The hashes are
+// locked, as a change to them renames the ConfigMaps
+// of every build using file options.
+func TestFileOptionsNormalizeHash(t *testing.T) {
+	const expected = `
+apiVersion: v1
+binaryData:
+  cert.der: REVSCg==
+  logo.png: UE5HDQoAAQ==
+data:
+  app.conf: |
+    port=8080
+    host=example.com
+kind: ConfigMap
+metadata:
+  name: app-m855k5tdhc
+`
+	for name, files := range map[string]map[string]string{
+		"lf": {
+			"app.conf": "port=8080\nhost=example.com\n",
+		},
+		"crlf": {
+			"app.conf": "port=8080\r\nhost=example.com",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteK("/app", fileOptionsKustomization)
+			th.WriteF("/app/app.conf", files["app.conf"])
+			th.WriteF("/app/logo.png", "PNG\r\n\x00\x01")
+			th.WriteF("/app/cert.der", "DER\n")
+			for _, useKyaml := range []bool{true, false} {
+				opts := th.MakeDefaultOptions()
+				opts.UseKyaml = useKyaml
+				m := th.Run("/app", opts)
+				th.AssertActualEqualsExpected(m, expected)
+			}
+		})
+	}
+}
+
+// Without file options, line endings are data like any
+// other, so the hash differs.
+func TestNoFileOptionsHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: app
+  files:
+  - app.conf
+`)
+	th.WriteF("/app/app.conf", "port=8080\r\nhost=example.com")
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  app.conf: "port=8080\r\nhost=example.com"
+kind: ConfigMap
+metadata:
+  name: app-dhkc68fm2h
+`)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// FlattenKustomization returns the effective kustomization
+// of the kustomization at the given path: one kustomization
+// with its local bases and components inlined, listing
+// only files and remote refs as resources, and every path
+// relative to the given path.  Resources aren't loaded,
+// plugins aren't run and remote refs aren't fetched.
+//
+// It's meant for documentation and review; see
+// FlattenKustomizationWithProvenance for how the
+// layers are merged.
+func FlattenKustomization(
+	fSys filesys.FileSystem, path string) (*types.Kustomization, error) {
+	k, _, err := FlattenKustomizationWithProvenance(fSys, path)
+	return k, err
+}
+
+// FlattenKustomizationWithProvenance is FlattenKustomization,
+// also returning which kustomization file contributed each
+// entry of the result.
+//
+// The list fields of the layers, e.g. patches and images,
+// are concatenated in the order a build applies them: the
+// kustomizations listed as resources, then the components,
+// then the kustomization listing them.  When layers set the
+// same scalar field, e.g. namespace, or the same key of
+// commonLabels or commonAnnotations, the value a build
+// would leave in its output wins, i.e. that of the
+// kustomization applied last, and the provenance names
+// it.  The name and namespace prefixes and suffixes of
+// the layers are composed as a build composes them.
+//
+// The graph is loaded as a build with default options
+// would load it, so missing files and cycles are reported
+// as such a build would report them.
+func FlattenKustomizationWithProvenance(
+	fSys filesys.FileSystem, path string) (
+	*types.Kustomization, types.KustomizationProvenance, error) {
+	b := MakeKustomizer(fSys, MakeDefaultOptions())
+	kt, ldr, err := b.loadTarget(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ldr.Cleanup()
+	return kt.Flatten(fSys, b.loadRestrictor())
+}
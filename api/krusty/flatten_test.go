@@ -0,0 +1,169 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestFlattenKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namespace: base
+namePrefix: b-
+commonLabels:
+  app: base
+  team: x
+resources:
+- deploy.yaml
+configMapGenerator:
+- name: cm
+  files:
+  - conf=app.conf
+  envs:
+  - app.env
+generatorOptions:
+  disableNameSuffixHash: true
+patches:
+- path: patch.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `this is never parsed`)
+	th.WriteF("/app/base/app.conf", `a`)
+	th.WriteF("/app/base/app.env", `b=c`)
+	th.WriteF("/app/base/patch.yaml", `p`)
+	th.WriteC("/app/comp", `
+images:
+- name: nginx
+  newTag: "1.21"
+`)
+	th.WriteK("/app/overlay", `
+namespace: prod
+namePrefix: o-
+commonLabels:
+  app: prod
+resources:
+- ../base
+- https://example.com/service.yaml
+components:
+- ../comp
+patchesStrategicMerge:
+- |-
+  apiVersion: v1
+  kind: Service
+  metadata:
+    name: inline
+- smp.yaml
+images:
+- name: nginx
+  newTag: "1.22"
+`)
+	th.WriteF("/app/overlay/smp.yaml", `s`)
+
+	k, prov, err := krusty.FlattenKustomizationWithProvenance(
+		th.GetFSys(), "/app/overlay")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "prod", k.Namespace)
+	assert.Equal(t, "o-b-", k.NamePrefix)
+	assert.Equal(t, map[string]string{"app": "prod", "team": "x"},
+		k.CommonLabels)
+	assert.Equal(t, []string{
+		"../base/deploy.yaml",
+		"https://example.com/service.yaml",
+	}, k.Resources)
+	assert.Empty(t, k.Components)
+	assert.Equal(t, []types.ConfigMapArgs{{GeneratorArgs: types.GeneratorArgs{
+		Name: "cm",
+		KvPairSources: types.KvPairSources{
+			FileSources: []string{"conf=../base/app.conf"},
+			EnvSources:  []string{"../base/app.env"},
+		},
+		Options: &types.GeneratorOptions{DisableNameSuffixHash: true},
+	}}}, k.ConfigMapGenerator)
+	assert.Nil(t, k.GeneratorOptions)
+	assert.Equal(t, []types.Patch{{Path: "../base/patch.yaml"}}, k.Patches)
+	assert.Equal(t, []types.PatchStrategicMerge{
+		`apiVersion: v1
+kind: Service
+metadata:
+  name: inline`,
+		"smp.yaml",
+	}, k.PatchesStrategicMerge)
+	assert.Equal(t, []types.Image{
+		{Name: "nginx", NewTag: "1.21"},
+		{Name: "nginx", NewTag: "1.22"},
+	}, k.Images)
+
+	base := "../base/kustomization.yaml"
+	comp := "../comp/kustomization.yaml"
+	overlay := "kustomization.yaml"
+	assert.Equal(t, types.KustomizationProvenance{
+		"namespace":                {overlay},
+		"namePrefix":               {base, overlay},
+		"commonLabels.app":         {overlay},
+		"commonLabels.team":        {base},
+		"resources[0]":             {base},
+		"resources[1]":             {overlay},
+		"configMapGenerator[0]":    {base},
+		"patches[0]":               {base},
+		"images[0]":                {comp},
+		"images[1]":                {overlay},
+		"patchesStrategicMerge[0]": {overlay},
+		"patchesStrategicMerge[1]": {overlay},
+	}, prov)
+
+	flat, err := krusty.FlattenKustomization(th.GetFSys(), "/app/overlay")
+	assert.NoError(t, err)
+	assert.Equal(t, k, flat)
+}
+
+func TestFlattenKustomizationMissingFile(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- base
+`)
+	th.WriteK("/app/base", `
+patchesStrategicMerge:
+- missing.yaml
+`)
+	_, err := krusty.FlattenKustomization(th.GetFSys(), "/app")
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "missing.yaml' doesn't exist")
+}
+
+func TestFlattenKustomizationRemoveCommonLabels(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+commonLabels:
+  app: base
+  team: x
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+removeCommonLabels:
+- app
+- team
+commonLabels:
+  app: overlay
+`)
+	flat, prov, err := krusty.FlattenKustomizationWithProvenance(
+		th.GetFSys(), "/app/overlay")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, map[string]string{"app": "overlay"}, flat.CommonLabels)
+	assert.Empty(t, flat.RemoveCommonLabels)
+	assert.Equal(t, []string{"kustomization.yaml"}, prov["commonLabels.app"])
+	assert.NotContains(t, prov, "commonLabels.team")
+}
@@ -0,0 +1,4 @@
+#!/bin/sh
+
+echo "still working" >&2
+sleep 30
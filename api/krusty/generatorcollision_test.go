@@ -0,0 +1,257 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestGeneratorCollisionAcrossBaseAndOverlay(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+configMapGenerator:
+- name: settings
+  literals:
+  - color=red
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"configMapGenerator entry 'settings' in "+
+				"/app/overlay/kustomization.yaml generates the same "+
+				"ConfigMap as configMapGenerator entry 'settings' in "+
+				"/app/base/kustomization.yaml")
+	}
+}
+
+func TestGeneratorCollisionInNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+secretGenerator:
+- name: creds
+  namespace: a
+  literals:
+  - user=x
+- name: creds
+  namespace: b
+  literals:
+  - user=y
+- name: creds
+  namespace: a
+  literals:
+  - user=z
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"secretGenerator entry 'creds' (namespace 'a') in "+
+				"/app/kustomization.yaml generates the same Secret as "+
+				"secretGenerator entry 'creds' (namespace 'a')")
+	}
+}
+
+func TestGeneratedNameAlreadyTaken(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- taken.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - color=red
+`)
+	// The name the generated ConfigMap would get.
+	th.WriteF("/app/taken.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings-d7568b82h8
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings-d7568b82h8
+---
+apiVersion: v1
+data:
+  color: red
+kind: ConfigMap
+metadata:
+  name: settings-7c4k6g2gk7
+`)
+}
+
+func TestMergeIntoRenamedBaseGeneratedResource(t *testing.T) {
+	for name, tc := range map[string]struct {
+		base     string
+		overlay  string
+		expected string
+		err      string
+	}{
+		"base namespace": {
+			base: `
+configMapGenerator:
+- name: settings
+  namespace: a
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: settings
+  behavior: merge
+  literals:
+  - size=big
+`,
+			err: "the ConfigMap named 'settings' is in namespace 'a', " +
+				"so give the merge or replace that namespace",
+		},
+		"base prefix and namespace": {
+			base: `
+namePrefix: p-
+namespace: a
+configMapGenerator:
+- name: settings
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: p-settings
+  namespace: b
+  behavior: replace
+  literals:
+  - size=big
+`,
+			err: "the ConfigMap named 'p-settings' is in namespace 'a', " +
+				"so give the merge or replace that namespace",
+		},
+		"base prefix and namespace, original name": {
+			base: `
+namePrefix: p-
+namespace: a
+configMapGenerator:
+- name: settings
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: settings
+  namespace: b
+  behavior: merge
+  literals:
+  - size=big
+`,
+			err: "the ConfigMap originally named 'settings' in namespace 'default' " +
+				"is now called 'p-settings' in namespace 'a', " +
+				"so give the merge or replace that name and namespace",
+		},
+		"base prefix": {
+			base: `
+namePrefix: p-
+configMapGenerator:
+- name: settings
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: p-settings
+  behavior: merge
+  literals:
+  - size=big
+`,
+			expected: `
+apiVersion: v1
+data:
+  color: red
+  size: big
+kind: ConfigMap
+metadata:
+  name: p-settings-dd6b4cmk5c
+`,
+		},
+		"base without hash": {
+			base: `
+namespace: a
+configMapGenerator:
+- name: settings
+  options:
+    disableNameSuffixHash: true
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: settings
+  behavior: merge
+  literals:
+  - size=big
+`,
+			expected: `
+apiVersion: v1
+data:
+  color: red
+  size: big
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: a
+`,
+		},
+		"overlay without hash": {
+			base: `
+configMapGenerator:
+- name: settings
+  namespace: a
+  literals:
+  - color=red
+`,
+			overlay: `
+configMapGenerator:
+- name: settings
+  namespace: b
+  behavior: merge
+  options:
+    disableNameSuffixHash: true
+  literals:
+  - size=big
+`,
+			err: "the ConfigMap named 'settings' is in namespace 'a', " +
+				"so give the merge or replace that namespace",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteK("/app/base", tc.base)
+			th.WriteK("/app/overlay", `
+resources:
+- ../base
+`+tc.overlay)
+			if tc.err != "" {
+				err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			m := th.Run("/app/overlay", th.MakeDefaultOptions())
+			th.AssertActualEqualsExpected(m, tc.expected)
+		})
+	}
+}
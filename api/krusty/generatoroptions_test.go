@@ -6,6 +6,8 @@ package krusty_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 )
 
@@ -55,7 +57,7 @@ generatorOptions:
   labels:
     foo: bar
 configMapGenerator:
-- name: shouldNotHaveHash
+- name: should-not-have-hash
   literals:
   - foo=bar
 `)
@@ -69,7 +71,7 @@ generatorOptions:
   labels:
     fruit: apple
 configMapGenerator:
-- name: shouldHaveHash
+- name: should-have-hash
   literals:
   - fruit=apple
 `)
@@ -82,7 +84,7 @@ kind: ConfigMap
 metadata:
   labels:
     foo: bar
-  name: shouldNotHaveHash
+  name: should-not-have-hash
 ---
 apiVersion: v1
 data:
@@ -91,6 +93,118 @@ kind: ConfigMap
 metadata:
   labels:
     fruit: apple
-  name: shouldHaveHash-c9867f8446
+  name: should-have-hash-c9867f8446
+`)
+}
+
+func TestGeneratorOptionsPropagateLabelsToConsumers(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+generatorOptions:
+  labels:
+    uses-config: app-config
+    owner: platform
+  propagateLabelsToConsumers:
+  - uses-config
+configMapGenerator:
+- name: app-config
+  literals:
+  - color=blue
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    uses-config: stale
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: app-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - name: db
+        image: db
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    uses-config: app-config
+  name: prod-web
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-app-config-747dfcb89d
+        image: app
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-db
+spec:
+  template:
+    spec:
+      containers:
+      - image: db
+        name: db
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  labels:
+    owner: platform
+    uses-config: app-config
+  name: prod-app-config-747dfcb89d
+`)
+}
+
+func TestGeneratorOptionsMetadataKeepsHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	build := func(buildID, color string) string {
+		th.WriteK("/app", `
+generatorOptions:
+  labels:
+    team: shop
+  annotations:
+    build-id: "`+buildID+`"
+configMapGenerator:
+- name: app-config
+  literals:
+  - color=`+color+`
 `)
+		m := th.Run("/app", th.MakeDefaultOptions())
+		return m.Resources()[0].GetName()
+	}
+	first := build("1", "blue")
+	assert.Equal(t, first, build("2", "blue"))
+	assert.NotEqual(t, first, build("2", "red"))
 }
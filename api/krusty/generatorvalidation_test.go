@@ -0,0 +1,42 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// All the problems are reported at once, before any of
+// the files, none of which exist, are read.
+func TestGeneratorEntriesValidatedUpFront(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: fine
+  files:
+  - missing.properties
+- name: Not_Fine
+  files:
+  - a=b=c
+secretGenerator:
+- name: ""
+  envs:
+  - "*.env"
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Equal(t, `invalid generator entries in /app/kustomization.yaml:
+  configMapGenerator[1].name: "Not_Fine" is not an RFC 1123 subdomain; `+
+			`use lower case alphanumerics, '-' and '.', starting and ending `+
+			`with an alphanumeric
+  configMapGenerator[1].files[0]: "a=b=c" has 2 '='; `+
+			`neither key names nor file paths may contain '='
+  secretGenerator[0].name: must not be empty
+  secretGenerator[0].envs[0]: "*.env" is a glob, which envs don't support`,
+			err.Error())
+	}
+}
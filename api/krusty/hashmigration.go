@@ -0,0 +1,104 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/checksum"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// HashMigrationCheck configures a check, for teams moving
+// generated ConfigMaps and Secrets from hashed names to
+// stable ones (disableNameSuffixHash), of the objects that
+// the previous build gave a hashed name and this one
+// doesn't.  Changing the content of such an object no
+// longer changes the name its consumers refer to, so no
+// longer rolls them out.  The check can warn of them and
+// annotate their consumers with a checksum of them, as the
+// ChecksumTransformer does, so that rollouts still happen.
+type HashMigrationCheck struct {
+	// Previous is the BuildManifest of the earlier build,
+	// e.g. of the last release.  When nil, the check does
+	// nothing.
+	Previous *BuildManifest
+
+	// When true, each such object is reported via
+	// Kustomizer.Warnings.
+	Warn bool
+
+	// When true, the workloads referring to such objects
+	// get an annotation holding a checksum of them.
+	Annotate bool
+
+	// AnnotationName is the annotation to set; defaults
+	// to checksum.DefaultAnnotationName.
+	AnnotationName string
+}
+
+// check runs the check on the given output, which must
+// still have its build annotations, returning the warnings
+// it makes.
+func (c *HashMigrationCheck) check(
+	m resmap.ResMap, hasher ifc.KunstructuredHasher) ([]string, error) {
+	if c.Previous == nil {
+		return nil, nil
+	}
+	var warnings []string
+	// Hashes of the objects no longer hashed, by namespace.
+	hashes := make(map[string]map[string]string)
+	for _, r := range m.Resources() {
+		if !r.IsGenerated() || r.NeedHashSuffix() {
+			continue
+		}
+		prev := c.Previous.find(r.OrgId(), r.GetNamespace())
+		if prev == nil || !prev.Hashed {
+			continue
+		}
+		if c.Warn {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s/%s was generated as %s by the previous build, but "+
+					"its name is no longer hashed, so changing it won't "+
+					"roll out the workloads using it",
+				r.GetKind(), r.GetName(), prev.Name))
+		}
+		if !c.Annotate {
+			continue
+		}
+		h, err := hasher.Hash(r)
+		if err != nil {
+			return nil, err
+		}
+		ns := r.CurId().EffectiveNamespace()
+		if hashes[ns] == nil {
+			hashes[ns] = make(map[string]string)
+		}
+		hashes[ns][checksum.RefKey(r.GetKind(), r.GetName())] = h
+	}
+	if len(hashes) == 0 {
+		return warnings, nil
+	}
+	references, err := checksum.DefaultReferenceFsSlices()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range m.Resources() {
+		h := hashes[r.CurId().EffectiveNamespace()]
+		if h == nil {
+			continue
+		}
+		err = r.ApplyFilter(checksum.Filter{
+			AnnotationName: c.AnnotationName,
+			Hashes:         h,
+			References:     references,
+			FsSlice:        checksum.DefaultFsSlice,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeHashedApp(th kusttest_test.Harness, generatorOptions string) {
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        envFrom:
+        - configMapRef:
+            name: settings
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`+generatorOptions)
+}
+
+func buildManifestOf(t *testing.T, th kusttest_test.Harness) *krusty.BuildManifest {
+	t.Helper()
+	writeHashedApp(th, "")
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err := k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return k.BuildManifest()
+}
+
+func TestBuildManifest(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	bm := buildManifestOf(t, th)
+	if assert.Len(t, bm.Generated, 1) {
+		o := bm.Generated[0]
+		assert.Equal(t, "ConfigMap", o.Id.Kind)
+		assert.Equal(t, "settings", o.Id.Name)
+		assert.Equal(t, "settings-747dfcb89d", o.Name)
+		assert.True(t, o.Hashed)
+	}
+}
+
+func TestHashMigrationCheck(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	previous := buildManifestOf(t, th)
+	writeHashedApp(th, `
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	opts := th.MakeDefaultOptions()
+	opts.HashMigrationCheck = &krusty.HashMigrationCheck{
+		Previous: previous,
+		Warn:     true,
+		Annotate: true,
+	}
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"ConfigMap/settings was generated as settings-747dfcb89d " +
+			"by the previous build, but its name is no longer hashed, " +
+			"so changing it won't roll out the workloads using it",
+	}, k.Warnings())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      annotations:
+        kustomize.config.k8s.io/checksum: ed059a696627ee14385d9ab38723e9c0d7662f2860c3cd17303d03c93d285819
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: settings
+        image: nginx
+        name: web
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+	assert.False(t, k.BuildManifest().Generated[0].Hashed)
+}
+
+func TestHashMigrationCheckOptIn(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	previous := buildManifestOf(t, th)
+	writeHashedApp(th, `
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	expected := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: settings
+        image: nginx
+        name: web
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  name: settings
+`
+	for name, check := range map[string]*krusty.HashMigrationCheck{
+		"noPrevious": {Warn: true, Annotate: true},
+		"noActions":  {Previous: previous},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := th.MakeDefaultOptions()
+			opts.HashMigrationCheck = check
+			k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+			m, err := k.Run("/app")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Empty(t, k.Warnings())
+			th.AssertActualEqualsExpected(m, expected)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// InputIndex maps the inputs of many builds back to the
+// roots of those builds, so that a file watcher can tell
+// which of them to run again when a file changes.
+//
+// Fill it with the output of ResolveInputs for each root.
+// Since ResolveInputs follows bases and components, a file
+// of a base affects every root that includes the base,
+// however indirectly.  Remote inputs are never affected by
+// a local change.  Roots and changed paths must be given
+// in the same form, e.g. both absolute.
+type InputIndex struct {
+	inputs map[string]rootInputs
+}
+
+// rootInputs holds the local inputs of one root,
+// joined to the root and cleaned.
+type rootInputs struct {
+	files map[string]bool
+	globs []string
+}
+
+// NewInputIndex returns an empty InputIndex.
+func NewInputIndex() *InputIndex {
+	return &InputIndex{inputs: make(map[string]rootInputs)}
+}
+
+// Add records the inputs of the build at the given root,
+// as returned by ResolveInputs for that root, replacing
+// any recorded for it before.
+func (x *InputIndex) Add(root string, refs []types.InputRef) {
+	root = filepath.Clean(root)
+	in := rootInputs{files: make(map[string]bool)}
+	for _, ref := range refs {
+		if ref.Remote {
+			continue
+		}
+		path := filepath.Clean(filepath.Join(root, ref.Path))
+		if ref.Glob {
+			in.globs = append(in.globs, path)
+			continue
+		}
+		in.files[path] = true
+	}
+	x.inputs[root] = in
+}
+
+// Remove forgets the given root.
+func (x *InputIndex) Remove(root string) {
+	delete(x.inputs, filepath.Clean(root))
+}
+
+// Roots returns the sorted roots in the index.
+func (x *InputIndex) Roots() []string {
+	var result []string
+	for root := range x.inputs {
+		result = append(result, root)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AffectedRoots returns the sorted roots whose builds read
+// the file at the given path, or would read it if it were
+// created, as it matches one of their globs.  If the path
+// is a directory, e.g. one that was removed or renamed,
+// the roots that read any file in it are affected.
+func (x *InputIndex) AffectedRoots(changedPath string) []string {
+	changedPath = filepath.Clean(changedPath)
+	var result []string
+	for root, in := range x.inputs {
+		if in.affectedBy(changedPath) {
+			result = append(result, root)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (in rootInputs) affectedBy(path string) bool {
+	if in.files[path] {
+		return true
+	}
+	for _, g := range in.globs {
+		if ok, _ := filepath.Match(g, path); ok {
+			return true
+		}
+	}
+	dir := path + string(filepath.Separator)
+	for f := range in.files {
+		if strings.HasPrefix(f, dir) {
+			return true
+		}
+	}
+	for _, g := range in.globs {
+		if strings.HasPrefix(g, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeOverlaysWithSharedBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+- https://example.com/service.yaml
+configMapGenerator:
+- name: conf
+  files:
+  - conf/*.properties
+`)
+	th.WriteF("/app/base/deploy.yaml", `d`)
+	th.WriteF("/app/base/conf/a.properties", `a=b`)
+	th.WriteK("/app/mid", `
+resources:
+- ../base
+`)
+	th.WriteK("/app/dev", `
+resources:
+- ../mid
+- dev.yaml
+`)
+	th.WriteF("/app/dev/dev.yaml", `d`)
+	th.WriteK("/app/prod", `
+resources:
+- prod.yaml
+`)
+	th.WriteF("/app/prod/prod.yaml", `p`)
+}
+
+func makeInputIndex(
+	t *testing.T, fSys filesys.FileSystem, roots ...string) *krusty.InputIndex {
+	o := krusty.MakeDefaultOptions()
+	x := krusty.NewInputIndex()
+	for _, root := range roots {
+		refs, err := krusty.ResolveInputs(fSys, root, o)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		x.Add(root, refs)
+	}
+	return x
+}
+
+func TestInputIndex(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOverlaysWithSharedBase(th)
+	x := makeInputIndex(t, th.GetFSys(), "/app/dev", "/app/prod", "/app/mid")
+	assert.Equal(t, []string{"/app/dev", "/app/mid", "/app/prod"}, x.Roots())
+
+	testCases := map[string][]string{
+		// transitively included base
+		"/app/base/deploy.yaml":        {"/app/dev", "/app/mid"},
+		"/app/base/kustomization.yaml": {"/app/dev", "/app/mid"},
+		"/app/mid/kustomization.yaml":  {"/app/dev", "/app/mid"},
+		"/app/dev/dev.yaml":            {"/app/dev"},
+		"/app/prod/prod.yaml":          {"/app/prod"},
+		// a file in a globbed dir, existing or new
+		"/app/base/conf/a.properties": {"/app/dev", "/app/mid"},
+		"/app/base/conf/b.properties": {"/app/dev", "/app/mid"},
+		"/app/base/conf/b.yaml":       nil,
+		// a directory holding inputs
+		"/app/base":      {"/app/dev", "/app/mid"},
+		"/app/base/conf": {"/app/dev", "/app/mid"},
+		// not inputs
+		"/app/base/other.yaml": nil,
+		"/app/prod/dev.yaml":   nil,
+		// paths are cleaned
+		"/app/dev/../prod/prod.yaml": {"/app/prod"},
+	}
+	for path, expected := range testCases {
+		assert.Equal(t, expected, x.AffectedRoots(path), path)
+	}
+}
+
+func TestInputIndexRemoteNeverAffected(t *testing.T) {
+	x := krusty.NewInputIndex()
+	x.Add("/app", []types.InputRef{
+		{Path: "kustomization.yaml", Role: types.InputRoleKustomization},
+		{Path: "https://example.com/service.yaml", Role: types.InputRoleResource, Remote: true},
+	})
+	assert.Nil(t, x.AffectedRoots("/app/https:/example.com/service.yaml"))
+	assert.Nil(t, x.AffectedRoots("https://example.com/service.yaml"))
+	assert.Equal(t, []string{"/app"}, x.AffectedRoots("/app/kustomization.yaml"))
+}
+
+func TestInputIndexAddReplacesAndRemove(t *testing.T) {
+	x := krusty.NewInputIndex()
+	x.Add("/app", []types.InputRef{{Path: "a.yaml", Role: types.InputRoleResource}})
+	x.Add("/app", []types.InputRef{{Path: "b.yaml", Role: types.InputRoleResource}})
+	assert.Nil(t, x.AffectedRoots("/app/a.yaml"))
+	assert.Equal(t, []string{"/app"}, x.AffectedRoots("/app/b.yaml"))
+	x.Remove("/app/")
+	assert.Nil(t, x.AffectedRoots("/app/b.yaml"))
+	assert.Nil(t, x.Roots())
+}
+
+// fileEvent stands in for fsnotify.Event, so that the
+// example needs no file watching library.
+type fileEvent struct {
+	Name string
+}
+
+// This example wires an InputIndex to a file watcher, e.g.
+// github.com/fsnotify/fsnotify, which would call onEvent for
+// each event received on its watcher.Events channel.  Each
+// event rebuilds the affected roots, and indexes their inputs
+// again, since the change may have added or removed some.
+// A real watcher would also Add the directory of each input.
+func ExampleInputIndex() {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/app/base/kustomization.yaml", []byte(`
+resources:
+- deploy.yaml
+`))
+	fSys.WriteFile("/app/base/deploy.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`))
+	for _, env := range []string{"dev", "prod"} {
+		fSys.WriteFile("/app/"+env+"/kustomization.yaml", []byte(`
+namePrefix: `+env+`-
+resources:
+- ../base
+`))
+	}
+	fSys.WriteFile("/app/dev/extra.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+`))
+
+	o := krusty.MakeDefaultOptions()
+	index := krusty.NewInputIndex()
+	reindex := func(root string) {
+		refs, err := krusty.ResolveInputs(fSys, root, o)
+		if err != nil {
+			log.Fatal(err)
+		}
+		index.Add(root, refs)
+	}
+	reindex("/app/dev")
+	reindex("/app/prod")
+
+	onEvent := func(event fileEvent) {
+		for _, root := range index.AffectedRoots(event.Name) {
+			m, err := krusty.MakeKustomizer(fSys, o).Run(root)
+			if err != nil {
+				log.Fatal(err)
+			}
+			reindex(root)
+			fmt.Printf("%s changed: rebuilt %s, %d resources\n",
+				event.Name, root, m.Size())
+		}
+	}
+	// With fsnotify:
+	//   for event := range watcher.Events {
+	//     onEvent(fileEvent{Name: event.Name})
+	//   }
+	fSys.WriteFile("/app/base/deploy.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    changed: "true"
+`))
+	onEvent(fileEvent{Name: "/app/base/deploy.yaml"})
+	// dev starts to use extra.yaml
+	fSys.WriteFile("/app/dev/kustomization.yaml", []byte(`
+namePrefix: dev-
+resources:
+- ../base
+- extra.yaml
+`))
+	onEvent(fileEvent{Name: "/app/dev/kustomization.yaml"})
+	onEvent(fileEvent{Name: "/app/dev/unrelated.txt"})
+	fmt.Println(index.AffectedRoots("/app/dev/extra.yaml"))
+
+	// Output:
+	// /app/base/deploy.yaml changed: rebuilt /app/dev, 1 resources
+	// /app/base/deploy.yaml changed: rebuilt /app/prod, 1 resources
+	// /app/dev/kustomization.yaml changed: rebuilt /app/dev, 2 resources
+	// [/app/dev]
+}
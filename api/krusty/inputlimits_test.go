@@ -0,0 +1,75 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestInputLimitsStopBillionLaughs(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- laughs.yaml
+`)
+	th.WriteF("/app/laughs.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: lol
+data:
+  a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+  b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+  c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+  d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+  e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+  f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+  g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+  h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+  i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"laughs.yaml exceeds the maxAliasExpansion limit of 100000")
+	}
+}
+
+func TestInputLimitsCanBeChanged(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deep.yaml
+`)
+	th.WriteF("/app/deep.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: deep
+data:
+  x: `+strings.Repeat("[", 5)+strings.Repeat("]", 5)+`
+`)
+	o := th.MakeDefaultOptions()
+	// The document's map, data, and five lists.
+	o.InputLimits.MaxNodeDepth = 6
+	err := th.RunWithErr("/app", o)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"deep.yaml exceeds the maxNodeDepth limit of 6, with at least 7")
+	}
+
+	o.InputLimits.MaxNodeDepth = 7
+	th.Run("/app", o)
+
+	o.InputLimits.MaxFileSize = 10
+	err = th.RunWithErr("/app", o)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"kustomization.yaml exceeds the maxFileSize limit of 10")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConfDir(th kusttest_test.Harness) {
+	th.WriteF("/app/conf/app.properties", "color=blue\n")
+	th.WriteF("/app/conf/db.properties", "host=db\n")
+	th.WriteF("/app/conf/README.md", "Docs.\n")
+	th.WriteF("/app/conf/OWNERS", "- alice\n")
+}
+
+func TestGeneratorFileGlobSkipsIgnoredFiles(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfDir(th)
+	th.WriteF("/app/.krmignore", `
+*.md
+OWNERS
+`)
+	th.WriteF("/app/conf/.krmignore", `
+!README.md
+`)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: conf
+  files:
+  - conf/*
+ignorePatterns:
+- db.properties
+generatorOptions:
+  disableNameSuffixHash: true
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  README.md: |
+    Docs.
+  app.properties: |
+    color=blue
+kind: ConfigMap
+metadata:
+  name: conf
+`)
+}
+
+func TestGeneratorFileGlobMatchesNothing(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfDir(th)
+	th.WriteK("/app", `
+configMapGenerator:
+- name: conf
+  files:
+  - conf/*.md
+ignorePatterns:
+- "*.md"
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no files match the glob conf/*.md")
+	}
+}
+
+func TestGeneratorFileGlobWithKeyName(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfDir(th)
+	th.WriteK("/app", `
+secretGenerator:
+- name: conf
+  files:
+  - props=conf/*.properties
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			`secretGenerator[0].files[0]: "props=conf/*.properties" `+
+				`gives a key name to a glob`)
+	}
+}
@@ -4,8 +4,10 @@
 package krusty
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/filesys"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
@@ -28,6 +30,10 @@ type Kustomizer struct {
 	fSys        filesys.FileSystem
 	options     *Options
 	depProvider *provider.DepProvider
+	warnings    []string
+	results     []resmap.PluginResult
+	summaries   []resmap.ChangeSummary
+	manifest    *BuildManifest
 }
 
 // MakeKustomizer returns an instance of Kustomizer.
@@ -35,7 +41,7 @@ func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
 	return &Kustomizer{
 		fSys:        fSys,
 		options:     o,
-		depProvider: provider.NewDepProvider(o.UseKyaml),
+		depProvider: provider.NewDepProvider(o.useKyaml()),
 	}
 }
 
@@ -51,24 +57,57 @@ func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
 // on any number of internal paths (e.g. the filesystem may contain
 // multiple overlays, and Run can be called on each of them).
 func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
+	return b.RunWithContext(context.Background(), path)
+}
+
+// RunWithContext is Run with a context that, when done,
+// stops the exec plugins and functions of the build,
+// failing it.
+func (b *Kustomizer) RunWithContext(
+	ctx context.Context, path string) (resmap.ResMap, error) {
+	b.warnings = nil
+	b.results = nil
+	b.summaries = nil
+	b.manifest = nil
+	if _, err := b.options.compatibility(); err != nil {
+		return nil, err
+	}
 	resmapFactory := resmap.NewFactory(
 		b.depProvider.GetResourceFactory(),
 		b.depProvider.GetConflictDetectorFactory())
-	lr := fLdr.RestrictionNone
-	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
-		lr = fLdr.RestrictionRootOnly
+	if b.options.TrackProvenance {
+		resmapFactory.SetProvenanceTable(resmap.NewProvenanceTable())
 	}
-	ldr, err := fLdr.NewLoader(lr, path, b.fSys)
+	if b.options.SummarizeChanges {
+		resmapFactory.SetChangeSummaries(resmap.NewChangeSummaries())
+	}
+	if c := b.options.PatchConflictCheck; c != nil {
+		resmapFactory.SetPatchConflicts(resmap.NewPatchConflicts(c.Strict))
+	}
+	resmapFactory.RF().SetInputLimits(b.options.InputLimits)
+	resmapFactory.RF().SetOpenAPISchemas(b.options.OpenAPISchemas)
+	ldr, err := fLdr.NewLoader(b.loadRestrictor(), path, b.fSys)
 	if err != nil {
 		return nil, err
 	}
 	defer ldr.Cleanup()
+	fLdr.SetInputLimits(ldr, b.options.InputLimits)
+	pl := pLdr.NewLoader(b.options.PluginConfig, resmapFactory)
+	pl.SetBuildContext(ctx, ldr.Root(), b.options.enabledFeatures())
 	kt := target.NewKustTarget(
 		ldr,
 		b.depProvider.GetFieldValidator(),
 		resmapFactory,
-		pLdr.NewLoader(b.options.PluginConfig, resmapFactory),
+		pl,
 	)
+	tConfig, err := b.options.defaultTransformerConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "merging transformer config options")
+	}
+	kt.SetDefaultTransformerConfig(tConfig)
+	kt.SetStdinResources(b.options.StdinResources)
+	kt.SetCapabilities(b.options.capabilities())
+	kt.SetExternalNames(b.options.ExternalNames)
 	err = kt.Load()
 	if err != nil {
 		return nil, err
@@ -78,8 +117,11 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 	if err != nil {
 		return nil, err
 	}
-	if b.options.DoLegacyResourceSort {
-		builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+	if b.options.ErrorOnEmptyOutput && m.Size() == 0 {
+		return nil, b.errEmptyOutput(kt)
+	}
+	if err = b.sort(m, kt.Kustomization().SortOptions); err != nil {
+		return nil, err
 	}
 	if b.options.AddManagedbyLabel {
 		t := builtins.LabelTransformerPlugin{
@@ -93,6 +135,107 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 		}
 		t.Transform(m)
 	}
+	var warnings []string
+	if b.options.HashMigrationCheck != nil {
+		warnings, err = b.options.HashMigrationCheck.check(
+			m, resmapFactory.RF().Hasher())
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range m.Resources() {
+		for _, w := range r.Warnings() {
+			resmapFactory.AddWarning(fmt.Sprintf("%s: %s", r.CurId(), w))
+		}
+	}
+	b.manifest = makeBuildManifest(m)
 	m.RemoveIdAnnotations()
+	m.SetProvenance(resmapFactory.ProvenanceTable())
+	b.results = resmapFactory.PluginResults()
+	b.summaries = resmapFactory.ChangeSummaries().List()
+	b.warnings = append(resmapFactory.Warnings(), warnings...)
+	if b.options.PatchConflictCheck != nil {
+		b.warnings = append(b.warnings,
+			b.options.PatchConflictCheck.warnings(
+				resmapFactory.PatchConflicts())...)
+	}
+	if b.options.NamespaceCheck != nil {
+		warnings, err = b.options.NamespaceCheck.checkNamespaces(m)
+		if err != nil {
+			return nil, err
+		}
+		b.warnings = append(b.warnings, warnings...)
+	}
+	if b.options.UniquenessCheck != nil {
+		var warnings []string
+		warnings, err = b.options.UniquenessCheck.checkUniqueness(m)
+		if err != nil {
+			return nil, err
+		}
+		b.warnings = append(b.warnings, warnings...)
+	}
 	return m, nil
 }
+
+// sort orders the output as the given sortOptions of the
+// top-level kustomization say, or else as the options say,
+// then moves the resources with a SortOrderAnnotation.
+func (b *Kustomizer) sort(m resmap.ResMap, so *types.SortOptions) error {
+	if err := b.sortByMode(m, so); err != nil {
+		return err
+	}
+	return resmap.SortByOrderAnnotation(m)
+}
+
+func (b *Kustomizer) sortByMode(m resmap.ResMap, so *types.SortOptions) error {
+	order := types.FIFOSortOrder
+	if b.options.doLegacyResourceSort() {
+		order = types.LegacySortOrder
+	}
+	if so != nil && so.Order != "" {
+		order = so.Order
+	}
+	switch order {
+	case types.LegacySortOrder:
+		return builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+	case types.PreserveFileOrderSortOrder:
+		return resmap.SortByFilePosition(m)
+	}
+	return nil
+}
+
+// Warnings returns the warnings made by the last Run,
+// e.g. by Options.NamespaceCheck or Options.UniquenessCheck,
+// for the caller to report.
+func (b *Kustomizer) Warnings() []string {
+	return b.warnings
+}
+
+// Results returns the results that plugins recorded in
+// the last Run, e.g. the rewrites a RegistryRewriteTransformer
+// in audit mode would have made, for the caller to report.
+func (b *Kustomizer) Results() []resmap.PluginResult {
+	return b.results
+}
+
+// ChangeSummaries returns, if Options.SummarizeChanges is
+// set, a summary of each run of the builtin transformers
+// in the last Run, in the order they ran, e.g. for a UI to
+// show what an overlay changes.
+func (b *Kustomizer) ChangeSummaries() []resmap.ChangeSummary {
+	return b.summaries
+}
+
+// BuildManifest returns the manifest of the last Run, for
+// the caller to keep and supply to a later build, e.g. as
+// the previous manifest of a HashMigrationCheck.
+func (b *Kustomizer) BuildManifest() *BuildManifest {
+	return b.manifest
+}
+
+func (b *Kustomizer) loadRestrictor() fLdr.LoadRestrictorFunc {
+	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
+		return fLdr.RestrictionRootOnly
+	}
+	return fLdr.RestrictionNone
+}
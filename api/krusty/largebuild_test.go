@@ -0,0 +1,70 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+// BenchmarkLargeBuild builds 5000 resources, half of them
+// Deployments referring to the other half, ConfigMaps, so
+// that prefixing their names makes many lookups by id.
+func BenchmarkLargeBuild(b *testing.B) {
+	const pairs = 2500
+	fSys := filesys.MakeFsInMemory()
+	var sb strings.Builder
+	for i := 0; i < pairs; i++ {
+		fmt.Fprintf(&sb, `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm%[1]d
+data:
+  k: v%[1]d
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deploy%[1]d
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: cm%[1]d
+`, i)
+	}
+	if err := fSys.WriteFile("/app/resources.yaml", []byte(sb.String())); err != nil {
+		b.Fatal(err)
+	}
+	if err := fSys.WriteFile("/app/kustomization.yaml", []byte(`
+namePrefix: p-
+namespace: bench
+commonLabels:
+  app: bench
+resources:
+- resources.yaml
+`)); err != nil {
+		b.Fatal(err)
+	}
+	k := krusty.MakeKustomizer(fSys, krusty.MakeDefaultOptions())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := k.Run("/app")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if m.Size() != 2*pairs {
+			b.Fatalf("expected %d resources, got %d", 2*pairs, m.Size())
+		}
+	}
+}
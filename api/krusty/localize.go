@@ -0,0 +1,116 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+// LocalizeOptions modify Localize.
+type LocalizeOptions struct {
+	// Scope is a local directory, holding the root, whose
+	// files the kustomization graph may read; its layout is
+	// kept in the destination.  If empty, it's the root.
+	Scope string
+
+	// SkipVerify, if true, skips checking that the copy
+	// builds to the same output as the original, e.g. when
+	// the remote refs can't be fetched twice.
+	SkipVerify bool
+
+	// Options are those of the builds.  If nil, the
+	// defaults are used.
+	Options *Options
+}
+
+// Localize copies the kustomization at the given root, and
+// everything a build of it reads, into destDir, fetching
+// the remote refs, e.g. git bases and resources served
+// over http, so that the copy builds without a network,
+// e.g. in an air-gapped environment.
+//
+// The copy of the root is destDir joined with the root's
+// path relative to the scope.  Each remote ref is put in
+// a localized-files directory beside the kustomization
+// file referring to it, under a path made from its url,
+// and the kustomization file refers to that instead.  A
+// local file or kustomization outside the scope, or a
+// file of a remote kustomization outside its repository,
+// is an error naming it.  destDir must not exist.
+//
+// Unless SkipVerify is set, the copy is built and its
+// output compared to that of the original before Localize
+// returns; if they differ, the copy is left for inspection
+// and an error returned.
+func Localize(
+	fSys filesys.FileSystem, root, destDir string, o *LocalizeOptions) error {
+	if o == nil {
+		o = &LocalizeOptions{}
+	}
+	opts := o.Options
+	if opts == nil {
+		opts = MakeDefaultOptions()
+	}
+	dest, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	if fSys.Exists(dest) {
+		return fmt.Errorf("localize destination '%s' already exists", dest)
+	}
+	b := MakeKustomizer(fSys, opts)
+	kt, ldr, err := b.loadTarget(root)
+	if err != nil {
+		return err
+	}
+	defer ldr.Cleanup()
+	scope := ldr.Root()
+	if o.Scope != "" {
+		dir, f, err := fSys.CleanedAbs(o.Scope)
+		if err != nil {
+			return errors.Wrap(err, "localize scope")
+		}
+		if f != "" {
+			return fmt.Errorf("localize scope '%s' isn't a directory", o.Scope)
+		}
+		scope = dir.String()
+	}
+	copied, err := kt.Localize(fSys, scope, dest)
+	if err != nil || o.SkipVerify {
+		return err
+	}
+	return verifyLocalized(fSys, root, copied, opts)
+}
+
+// verifyLocalized returns an error if the copy of the
+// root doesn't build to the same output as the root.
+func verifyLocalized(
+	fSys filesys.FileSystem, root, copied string, o *Options) error {
+	build := func(path string) ([]byte, error) {
+		m, err := MakeKustomizer(fSys, o).Run(path)
+		if err != nil {
+			return nil, err
+		}
+		return m.AsYaml()
+	}
+	expected, err := build(root)
+	if err != nil {
+		return errors.Wrapf(err, "verifying localize: building '%s'", root)
+	}
+	actual, err := build(copied)
+	if err != nil {
+		return errors.Wrapf(err, "verifying localize: building '%s'", copied)
+	}
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf(
+			"verifying localize: '%s' doesn't build to the same output as '%s'",
+			copied, root)
+	}
+	return nil
+}
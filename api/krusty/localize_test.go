@@ -0,0 +1,171 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeLocalizeApp(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: conf
+  files:
+  - conf/*.properties
+  envs:
+  - env.txt
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:v1
+`)
+	th.WriteF("/app/base/conf/a.properties", "a=1\n")
+	th.WriteF("/app/base/env.txt", "B=2\n")
+	th.WriteF("/app/overlay/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
+
+func TestLocalize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/manifests/sa.yaml" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: runner
+`))
+		}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th := kusttest_test.MakeHarness(t)
+	writeLocalizeApp(th)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+- `+server.URL+`/manifests/sa.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	o := th.MakeDefaultOptions()
+	err = krusty.Localize(th.GetFSys(), "/app/overlay", "/out",
+		&krusty.LocalizeOptions{Scope: "/app", Options: &o})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kustFile, err := th.GetFSys().ReadFile("/out/overlay/kustomization.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vendored := "localized-files/" +
+		strings.Replace(u.Host, ":", "_", 1) + "/manifests/sa.yaml"
+	if !strings.Contains(string(kustFile), "- "+vendored+"\n") {
+		t.Fatalf("the remote ref isn't rewritten:\n%s", kustFile)
+	}
+	for _, f := range []string{
+		"/out/overlay/" + vendored,
+		"/out/overlay/patch.yaml",
+		"/out/base/kustomization.yaml",
+		"/out/base/deployment.yaml",
+		"/out/base/conf/a.properties",
+		"/out/base/env.txt",
+	} {
+		if !th.GetFSys().Exists(f) {
+			t.Fatalf("expected %s", f)
+		}
+	}
+
+	// The copy builds without the server.
+	server.Close()
+	m := th.Run("/out/overlay", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: web:v1
+        name: web
+---
+apiVersion: v1
+data:
+  B: "2"
+  a.properties: |
+    a=1
+kind: ConfigMap
+metadata:
+  name: prod-conf-8tc9dgf48c
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: prod-runner
+`)
+}
+
+func TestLocalizeOutsideTheScope(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeLocalizeApp(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+	err := krusty.Localize(th.GetFSys(), "/app/overlay", "/out", nil)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"'../base' in '/app/overlay/kustomization.yaml' "+
+			"is outside the scope '/app/overlay'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLocalizeDestinationExists(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeLocalizeApp(th)
+	th.WriteF("/out/x.yaml", "")
+	err := krusty.Localize(th.GetFSys(), "/app/base", "/out", nil)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"localize destination '/out' already exists") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
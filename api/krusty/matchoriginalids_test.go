@@ -0,0 +1,71 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// A patch can target a base resource by the name and
+// apiVersion it was loaded with, in an overlay that
+// renames it, of a base that upgrades its apiVersion.
+func TestPatchTargetMatchOriginalIds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+transformers:
+- upgrade.yaml
+`)
+	th.WriteF("/app/base/upgrade.yaml", `
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: upgrade
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+namespace: shop
+resources:
+- ../base
+patches:
+- target:
+    version: v1beta2
+    kind: Deployment
+    name: web
+    matchOriginalIds: true
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+- target:
+    kind: Deployment
+    name: prod-web
+    matchOriginalIds: true
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 5
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+  namespace: shop
+spec:
+  replicas: 3
+`)
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeMetadataValidationBase(th kusttest_test.Harness) {
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    owner: Team A
+`)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+`)
+}
+
+func TestCommonLabelsOverTheLengthLimit(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMetadataValidationBase(th)
+	th.WriteK("/app/overlay", `
+commonLabels:
+  release: `+strings.Repeat("r", 64)+`
+resources:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		`label "release" of Deployment web: `+
+			"value is 64 characters, over the limit of 63") {
+		t.Fatalf("unexpected error: %q", err)
+	}
+}
+
+func TestMetadataValidationIgnoreKeys(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMetadataValidationBase(th)
+	th.WriteK("/app/overlay", `
+commonAnnotations:
+  Other!: hi
+metadataValidation:
+  ignoreKeys:
+  - Note!
+resources:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), `annotation "Other!" of Deployment web`) {
+		t.Fatalf("unexpected error: %q", err)
+	}
+
+	th.WriteK("/app/overlay", `
+commonAnnotations:
+  Note!: hi
+metadataValidation:
+  ignoreKeys:
+  - Note!
+resources:
+- ../base
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    Note!: hi
+  labels:
+    owner: Team A
+  name: web
+spec:
+  template:
+    metadata:
+      annotations:
+        Note!: hi
+`)
+}
+
+func TestMetadataValidationStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMetadataValidationBase(th)
+	th.WriteK("/app/overlay", `
+commonLabels:
+  app: web
+metadataValidation:
+  strict: true
+resources:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		`label "owner" of Deployment web: value "Team A" must begin`) {
+		t.Fatalf("unexpected error: %q", err)
+	}
+}
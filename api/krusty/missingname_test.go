@@ -0,0 +1,57 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestMissingNameNamesFileAndDocument(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 1
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"document 1 in resources.yaml, of kind Deployment, "+
+				"has no metadata.name")
+	}
+}
+
+func TestGenerateNameNeedsNoName(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- job.yaml
+`)
+	th.WriteF("/app/job.yaml", `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`)
+}
@@ -0,0 +1,300 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeTenantBase(th kusttest_test.Harness) {
+	th.WriteK("/app/tenant", `
+resources:
+- deployment.yaml
+- service.yaml
+configMapGenerator:
+- name: conf
+  literals:
+  - LEVEL=info
+`)
+	th.WriteF("/app/tenant/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:v1
+        envFrom:
+        - configMapRef:
+            name: conf
+`)
+	th.WriteF("/app/tenant/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+`)
+}
+
+func TestMultiInstance(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("/app/prod/blue.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+	th.WriteK("/app/prod", `
+namePrefix: prod-
+commonLabels:
+  env: prod
+multiInstance:
+- resource: ../tenant
+  instances:
+  - namePrefix: blue-
+    namespace: blue
+    patches:
+    - path: blue.yaml
+  - namePrefix: green-
+    namespace: green
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: prod-blue-web
+  namespace: blue
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-blue-conf-2748f727mg
+        image: web:v1
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: prod
+  name: prod-blue-web
+  namespace: blue
+spec:
+  ports:
+  - port: 80
+  selector:
+    env: prod
+---
+apiVersion: v1
+data:
+  LEVEL: info
+kind: ConfigMap
+metadata:
+  labels:
+    env: prod
+  name: prod-blue-conf-2748f727mg
+  namespace: blue
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: prod-green-web
+  namespace: green
+spec:
+  selector:
+    matchLabels:
+      env: prod
+  template:
+    metadata:
+      labels:
+        env: prod
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-green-conf-2748f727mg
+        image: web:v1
+        name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    env: prod
+  name: prod-green-web
+  namespace: green
+spec:
+  ports:
+  - port: 80
+  selector:
+    env: prod
+---
+apiVersion: v1
+data:
+  LEVEL: info
+kind: ConfigMap
+metadata:
+  labels:
+    env: prod
+  name: prod-green-conf-2748f727mg
+  namespace: green
+`)
+}
+
+// Instances differing only in their namespace keep the
+// references of each to its own resources.
+func TestMultiInstanceSamePrefix(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteK("/app/prod", `
+multiInstance:
+- resource: ../tenant
+  instances:
+  - namespace: blue
+  - namespace: green
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	for _, r := range m.Resources() {
+		if r.GetKind() != "Deployment" {
+			continue
+		}
+		ns := r.GetNamespace()
+		cms := m.GetMatchingResourcesByCurrentId(func(id resid.ResId) bool {
+			return id.Kind == "ConfigMap" && id.Namespace == ns
+		})
+		if len(cms) != 1 {
+			t.Fatalf("expected a ConfigMap in %s", ns)
+		}
+		ref, err := r.GetString(
+			"spec.template.spec.containers[0].envFrom[0].configMapRef.name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref != cms[0].GetName() {
+			t.Fatalf("the Deployment in %s refers to %s, not %s",
+				ns, ref, cms[0].GetName())
+		}
+	}
+}
+
+func TestMultiInstanceErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		instances string
+		extra     string
+		expected  string
+	}{
+		"same prefix and namespace": {
+			instances: `
+  - namePrefix: a-
+    namespace: x
+  - namePrefix: a-
+    namespace: x
+`,
+			expected: "instances[0] and instances[1] of '../tenant' " +
+				"have the same namePrefix 'a-' and namespace 'x'",
+		},
+		"no instances": {
+			instances: " []\n",
+			expected:  "no instances of '../tenant'",
+		},
+		"cluster-scoped": {
+			instances: `
+  - namespace: blue
+  - namespace: green
+`,
+			extra: "- role.yaml\n",
+			expected: "instances with the namePrefix '' would each have " +
+				"the cluster-scoped rbac.authorization.k8s.io_v1_ClusterRole|~X|reader",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeTenantBase(th)
+			th.WriteF("/app/tenant/role.yaml", `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+			th.WriteK("/app/tenant", `
+resources:
+- deployment.yaml
+`+tc.extra)
+			th.WriteK("/app/prod", `
+multiInstance:
+- resource: ../tenant
+  instances:`+tc.instances)
+			err := th.RunWithErr("/app/prod", th.MakeDefaultOptions())
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLocalizeMultiInstance(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeTenantBase(th)
+	th.WriteF("/app/prod/blue.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+	th.WriteK("/app/prod", `
+multiInstance:
+- resource: ../tenant
+  instances:
+  - namespace: blue
+    patches:
+    - path: blue.yaml
+  - namespace: green
+`)
+	err := krusty.Localize(th.GetFSys(), "/app/prod", "/out",
+		&krusty.LocalizeOptions{Scope: "/app"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range []string{
+		"/out/prod/blue.yaml",
+		"/out/tenant/deployment.yaml",
+	} {
+		if !th.GetFSys().Exists(f) {
+			t.Fatalf("expected %s", f)
+		}
+	}
+}
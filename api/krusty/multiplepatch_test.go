@@ -113,7 +113,7 @@ func TestVolumeRemoveEmptyDirInOverlay(t *testing.T) {
 resources:
 - deployment.yaml
 configMapGenerator:
-- name: baseCm
+- name: base-cm
   literals:
   - foo=bar
 `)
@@ -135,8 +135,8 @@ spec:
       - name: fancyDisk
         emptyDir: {}
       - configMap:
-          name: baseCm
-        name: baseCm
+          name: base-cm
+        name: base-cm
 `)
 	m := th.Run("base", th.MakeDefaultOptions())
 	th.AssertActualEqualsExpected(m, `
@@ -157,15 +157,15 @@ spec:
       - emptyDir: {}
         name: fancyDisk
       - configMap:
-          name: baseCm-798k5k7g9f
-        name: baseCm
+          name: base-cm-798k5k7g9f
+        name: base-cm
 ---
 apiVersion: v1
 data:
   foo: bar
 kind: ConfigMap
 metadata:
-  name: baseCm-798k5k7g9f
+  name: base-cm-798k5k7g9f
 `)
 
 	th.WriteK("overlay", `
@@ -174,7 +174,7 @@ patchesStrategicMerge:
 resources:
 - ../base
 configMapGenerator:
-- name: overlayCm
+- name: overlay-cm
   literals:
   - hello=world
 `)
@@ -192,8 +192,8 @@ spec:
         gcePersistentDisk:
           pdName: fancyDisk
       - configMap:
-          name: overlayCm
-        name: overlayCm
+          name: overlay-cm
+        name: overlay-cm
 `)
 	m = th.Run("overlay", th.MakeDefaultOptions())
 	th.AssertActualEqualsExpected(m, `
@@ -215,25 +215,25 @@ spec:
           pdName: fancyDisk
         name: fancyDisk
       - configMap:
-          name: overlayCm-dc6fm46dhm
-        name: overlayCm
+          name: overlay-cm-dc6fm46dhm
+        name: overlay-cm
       - configMap:
-          name: baseCm-798k5k7g9f
-        name: baseCm
+          name: base-cm-798k5k7g9f
+        name: base-cm
 ---
 apiVersion: v1
 data:
   foo: bar
 kind: ConfigMap
 metadata:
-  name: baseCm-798k5k7g9f
+  name: base-cm-798k5k7g9f
 ---
 apiVersion: v1
 data:
   hello: world
 kind: ConfigMap
 metadata:
-  name: overlayCm-dc6fm46dhm
+  name: overlay-cm-dc6fm46dhm
 `)
 }
 
@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// DefaultExternalNamespaces are the namespaces a cluster has
+// from the start, which a build needn't create.
+var DefaultExternalNamespaces = []string{
+	"default", "kube-node-lease", "kube-public", "kube-system",
+}
+
+// maxMissingNamespaceExamples bounds the number of resources
+// listed as examples in a MissingNamespace.
+const maxMissingNamespaceExamples = 3
+
+// NamespaceCheck configures a check, after all
+// transformations, that each namespace used by a namespaced
+// resource in the build output is either created by a
+// Namespace resource in it, or known to exist already.
+type NamespaceCheck struct {
+	// ExternalNamespaces are known to exist outside the
+	// build.  When nil, DefaultExternalNamespaces are used.
+	ExternalNamespaces []string
+
+	// When true, a missing namespace fails the build,
+	// rather than being reported via Kustomizer.Warnings.
+	Strict bool
+}
+
+// MissingNamespace describes a namespace used by resources
+// in the build output that nothing creates.
+type MissingNamespace struct {
+	Namespace string
+
+	// Count is the number of resources in the namespace.
+	Count int
+
+	// Examples are the ids of the first few of them.
+	Examples []resid.ResId
+}
+
+func (mn MissingNamespace) String() string {
+	var examples []string
+	for _, id := range mn.Examples {
+		examples = append(examples, id.Kind+"/"+id.Name)
+	}
+	if mn.Count > len(mn.Examples) {
+		examples = append(examples, "...")
+	}
+	return fmt.Sprintf(
+		"namespace '%s' is used by %d resource(s) (%s), "+
+			"but is neither created by the build nor known to exist",
+		mn.Namespace, mn.Count, strings.Join(examples, ", "))
+}
+
+// findMissingNamespaces returns, sorted by namespace, the
+// namespaces of the namespaced resources in m that aren't
+// created by a Namespace in m, nor among the external ones.
+func findMissingNamespaces(
+	m resmap.ResMap, external []string) []MissingNamespace {
+	if external == nil {
+		external = DefaultExternalNamespaces
+	}
+	known := make(map[string]bool)
+	for _, ns := range external {
+		known[ns] = true
+	}
+	for _, r := range m.Resources() {
+		gvk := r.GetGvk()
+		if gvk.Group == "" && gvk.Kind == "Namespace" {
+			known[r.GetName()] = true
+		}
+	}
+	missing := make(map[string]*MissingNamespace)
+	for _, r := range m.Resources() {
+		id := r.CurId()
+		if !r.IsNamespaceableKind() {
+			continue
+		}
+		ns := id.EffectiveNamespace()
+		if known[ns] {
+			continue
+		}
+		mn, found := missing[ns]
+		if !found {
+			mn = &MissingNamespace{Namespace: ns}
+			missing[ns] = mn
+		}
+		mn.Count++
+		if len(mn.Examples) < maxMissingNamespaceExamples {
+			mn.Examples = append(mn.Examples, id)
+		}
+	}
+	var result []MissingNamespace
+	for _, mn := range missing {
+		result = append(result, *mn)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
+
+// checkNamespaces runs the check, returning the
+// warnings it makes, or, if it's strict, an error.
+func (c *NamespaceCheck) checkNamespaces(
+	m resmap.ResMap) ([]string, error) {
+	var problems []string
+	for _, mn := range findMissingNamespaces(m, c.ExternalNamespaces) {
+		problems = append(problems, mn.String())
+	}
+	if c.Strict && len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"missing namespaces:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return problems, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeHalfMigratedOverlay(th kusttest_test.Harness) {
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: shop-v2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: shop-v2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+  namespace: shop-v2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: d
+  namespace: shop-v2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+  namespace: monitoring
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: default-sa
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: system-sa
+  namespace: kube-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+`)
+}
+
+func TestNamespaceCheckWarns(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeHalfMigratedOverlay(th)
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{}
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, m.Size())
+	assert.Equal(t, []string{
+		"namespace 'monitoring' is used by 1 resource(s) " +
+			"(Secret/token), but is neither created by the " +
+			"build nor known to exist",
+		"namespace 'shop-v2' is used by 4 resource(s) " +
+			"(ConfigMap/a, ConfigMap/b, ConfigMap/c, ...), but is " +
+			"neither created by the build nor known to exist",
+	}, k.Warnings())
+
+	// The allowlist replaces the default one.
+	opts.NamespaceCheck.ExternalNamespaces = []string{
+		"default", "monitoring", "shop-v2"}
+	k = krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err = k.Run("/app")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"namespace 'kube-system' is used by 1 resource(s) " +
+			"(ServiceAccount/system-sa), but is neither created by " +
+			"the build nor known to exist",
+	}, k.Warnings())
+}
+
+func TestNamespaceCheckStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeHalfMigratedOverlay(th)
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{Strict: true}
+	err := th.RunWithErr("/app", opts)
+	if assert.Error(t, err) {
+		assert.Equal(t, `missing namespaces:
+  namespace 'monitoring' is used by 1 resource(s) (Secret/token), `+
+			`but is neither created by the build nor known to exist
+  namespace 'shop-v2' is used by 4 resource(s) `+
+			`(ConfigMap/a, ConfigMap/b, ConfigMap/c, ...), `+
+			`but is neither created by the build nor known to exist`,
+			err.Error())
+	}
+}
+
+func TestNamespaceCheckAfterTransformations(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeHalfMigratedOverlay(th)
+	th.WriteK("/overlay", `
+namespace: shop
+resources:
+- ../app
+`)
+	opts := th.MakeDefaultOptions()
+	opts.NamespaceCheck = &krusty.NamespaceCheck{Strict: true}
+	_, err := krusty.MakeKustomizer(th.GetFSys(), &opts).Run("/overlay")
+	assert.NoError(t, err)
+}
@@ -78,7 +78,7 @@ func TestNamespacedGeneratorWithOverlays(t *testing.T) {
 namespace: base
 
 configMapGenerator:
-- name: testCase
+- name: test-case
   literals:
     - base=apple
 `)
@@ -89,7 +89,7 @@ resources:
 namespace: overlay
 
 configMapGenerator:
-  - name: testCase
+  - name: test-case
     behavior: merge
     literals:
       - overlay=peach
@@ -102,7 +102,7 @@ data:
   overlay: peach
 kind: ConfigMap
 metadata:
-  name: testCase-gmfch8gkbt
+  name: test-case-gmfch8gkbt
   namespace: overlay
 `)
 }
@@ -0,0 +1,127 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeNamespacePrefixSuffixBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+namespace: app
+resources:
+- namespace.yaml
+- resources.yaml
+`)
+	th.WriteF("/app/base/namespace.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+`)
+	th.WriteF("/app/base/resources.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app
+- kind: ServiceAccount
+  name: prometheus
+  namespace: monitoring
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+`)
+}
+
+func TestNamespaceSuffix(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeNamespacePrefixSuffixBase(th)
+	th.WriteK("/app/staging", `
+namespaceSuffix: -staging
+resources:
+- ../base
+`)
+	m := th.Run("/app/staging", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app-staging
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+  namespace: app-staging
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app-staging
+- kind: ServiceAccount
+  name: prometheus
+  namespace: monitoring
+`)
+}
+
+func TestNamespacePrefixIncludeExternal(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeNamespacePrefixSuffixBase(th)
+	th.WriteK("/app/tenant", `
+namespacePrefix: t1-
+namespacePrefixSuffixOptions:
+  includeExternal: true
+resources:
+- ../base
+`)
+	m := th.Run("/app/tenant", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: t1-app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+  namespace: t1-app
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: t1-app
+- kind: ServiceAccount
+  name: prometheus
+  namespace: t1-monitoring
+`)
+}
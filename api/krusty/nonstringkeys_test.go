@@ -0,0 +1,52 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestNonStringKeysWarn(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/ports.yaml", `
+apiVersion: example.com/v1
+kind: PortMap
+metadata:
+  name: ports
+spec:
+  byPort:
+    8080: web
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: example.com/v1
+kind: PortMap
+metadata:
+  name: ports
+spec:
+  byPort:
+    8443: tls
+`)
+	th.WriteK("/app", `
+namePrefix: p-
+resources:
+- ports.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err := k.Run("/app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			"example.com_v1_PortMap|~X|p-ports: the !!int key of field " +
+				"'spec.byPort.8080' was converted to a string",
+			"example.com_v1_PortMap|~X|p-ports: the !!int key of field " +
+				"'spec.byPort.8443' was converted to a string",
+		}, k.Warnings())
+	}
+}
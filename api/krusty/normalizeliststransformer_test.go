@@ -0,0 +1,81 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// The transformer, listed in the top kustomization, sees
+// the env vars that the patches of the overlay add.
+func TestNormalizeListsTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/app/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        env:
+        - name: LOG_LEVEL
+          value: info
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+transformers:
+- normalize.yaml
+`)
+	th.WriteF("/app/overlay/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        env:
+        - name: DEBUG
+          value: "true"
+`)
+	th.WriteF("/app/overlay/normalize.yaml", `
+apiVersion: builtin
+kind: NormalizeListsTransformer
+metadata:
+  name: normalize
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: DEBUG
+          value: "true"
+        - name: LOG_LEVEL
+          value: info
+        image: web
+        name: web
+`)
+}
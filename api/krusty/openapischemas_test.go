@@ -0,0 +1,180 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+// namespacedWidgetSchema declares the Widget kind namespaced,
+// and merges the items of its spec by name.
+const namespacedWidgetSchema = `{
+  "definitions": {
+    "com.example.v1.Widget": {
+      "type": "object",
+      "properties": {
+        "spec": {
+          "type": "object",
+          "properties": {
+            "items": {
+              "type": "array",
+              "items": {"type": "object"},
+              "x-kubernetes-patch-merge-key": "name",
+              "x-kubernetes-patch-strategy": "merge"
+            }
+          }
+        }
+      },
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.com", "version": "v1", "kind": "Widget"}
+      ]
+    }
+  },
+  "paths": {
+    "/apis/example.com/v1/namespaces/{namespace}/widgets/{name}": {
+      "get": {
+        "x-kubernetes-group-version-kind":
+          {"group": "example.com", "version": "v1", "kind": "Widget"}
+      }
+    }
+  }
+}`
+
+// clusterWidgetSchema declares the Widget kind cluster-scoped,
+// with no merge key for the items of its spec.
+const clusterWidgetSchema = `{
+  "definitions": {
+    "com.example.v1.Widget": {
+      "type": "object",
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.com", "version": "v1", "kind": "Widget"}
+      ]
+    }
+  },
+  "paths": {
+    "/apis/example.com/v1/widgets/{name}": {
+      "get": {
+        "x-kubernetes-group-version-kind":
+          {"group": "example.com", "version": "v1", "kind": "Widget"}
+      }
+    }
+  }
+}`
+
+func makeWidgetSchemas(t *testing.T, schema string) *openapi.Schemas {
+	t.Helper()
+	s := openapi.NewSchemas()
+	if !assert.NoError(t, s.AddSchema([]byte(schema))) {
+		t.FailNow()
+	}
+	return s
+}
+
+func writeWidgetApp(th kusttest_test.Harness) {
+	th.WriteF("/app/widget.yaml", `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  items:
+  - name: a
+    size: 1
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  items:
+  - name: b
+    size: 2
+`)
+	th.WriteK("/app", `
+namespace: prod
+resources:
+- widget.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+}
+
+func TestConcurrentBuildsWithOwnOpenAPISchemas(t *testing.T) {
+	builds := []struct {
+		schemas  *openapi.Schemas
+		expected string
+	}{
+		{
+			schemas: makeWidgetSchemas(t, namespacedWidgetSchema),
+			expected: `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+  namespace: prod
+spec:
+  items:
+  - name: b
+    size: 2
+  - name: a
+    size: 1
+`,
+		},
+		{
+			schemas: makeWidgetSchemas(t, clusterWidgetSchema),
+			expected: `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  items:
+  - name: b
+    size: 2
+`,
+		},
+	}
+	const rounds = 4
+	actual := make([][]string, len(builds))
+	errs := make([][]error, len(builds))
+	var wg sync.WaitGroup
+	for i := range builds {
+		actual[i] = make([]string, rounds)
+		errs[i] = make([]error, rounds)
+		for j := 0; j < rounds; j++ {
+			// The in-memory file systems aren't safe for
+			// concurrent use, so each build gets its own.
+			th := kusttest_test.MakeHarness(t)
+			writeWidgetApp(th)
+			wg.Add(1)
+			go func(i, j int) {
+				defer wg.Done()
+				opts := th.MakeDefaultOptions()
+				opts.OpenAPISchemas = builds[i].schemas
+				m, err := krusty.MakeKustomizer(th.GetFSys(), &opts).Run("/app")
+				if err != nil {
+					errs[i][j] = err
+					return
+				}
+				y, err := m.AsYaml()
+				actual[i][j], errs[i][j] = string(y), err
+			}(i, j)
+		}
+	}
+	wg.Wait()
+	for i, b := range builds {
+		for j := 0; j < rounds; j++ {
+			if assert.NoError(t, errs[i][j]) {
+				assert.Equal(t, b.expected[1:], actual[i][j])
+			}
+		}
+	}
+}
@@ -4,11 +4,32 @@
 package krusty
 
 import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/internal/target"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 )
 
+// TransformerConfig holds the field specs that tell the
+// builtin transformers where to find names, labels, etc.,
+// i.e. the data held in the files a kustomization lists in
+// its configurations field.
+type TransformerConfig = builtinconfig.TransformerConfig
+
+// MakeDefaultTransformerConfig returns a copy of the
+// default TransformerConfig, e.g. to edit and use as
+// Options.ReplaceTransformerConfig.
+func MakeDefaultTransformerConfig() *TransformerConfig {
+	return builtinconfig.MakeDefaultConfig()
+}
+
 // Options holds high-level kustomize configuration options,
 // e.g. are plugins enabled, should the loader be restricted
 // to the kustomization root, etc.
@@ -41,6 +62,108 @@ type Options struct {
 	// When true, allow name and kind changing via a patch
 	// When false, patch name/kind don't overwrite target name/kind
 	AllowResourceIdChanges bool
+
+	// When true, record which patch or transformer, of which
+	// kustomization, last wrote each field, available via the
+	// Provenance method of the build output, and each
+	// transformer run that changed a resource, available via
+	// the Provenance method of the resource.  It costs memory,
+	// so is off by default; nothing it records is written to
+	// the output YAML.  The removeCommonLabels and
+	// removeCommonAnnotations of a kustomization use it to
+	// leave alone what a base's transformers didn't add.
+	TrackProvenance bool
+
+	// When true, the builtin label, annotation, namespace
+	// and prefix/suffix transformers summarize what each
+	// of their runs changed, available via the
+	// ChangeSummaries method of the Kustomizer.
+	SummarizeChanges bool
+
+	// Field specs merged into the default config, as though
+	// every kustomization in the build, bases included, listed
+	// them in its configurations field.  As with such files,
+	// a conflict with the defaults is an error.
+	ExtraTransformerConfig *TransformerConfig
+
+	// When not nil, used instead of the default config,
+	// e.g. for hermetic tests.  ExtraTransformerConfig, if
+	// any, is merged into it.
+	ReplaceTransformerConfig *TransformerConfig
+
+	// Limits on the size and complexity of the YAML read
+	// from files, and from the output of plugins.  Zero
+	// fields take their defaults; see types.InputLimits.
+	InputLimits types.InputLimits
+
+	// The OpenAPI definitions giving the merge keys of
+	// lists and the scope of kinds, e.g. with those of
+	// custom resources added.  Nil means the global ones of
+	// the openapi package.  Programs running several builds
+	// at once should give each build that needs definitions
+	// of its own its own openapi.Schemas, rather than adding
+	// them to the global ones.
+	OpenAPISchemas *openapi.Schemas
+
+	// When not empty, a kustomize minor version, e.g. "v3.8",
+	// whose output the build should reproduce.  It pins the
+	// behaviors known to differ between versions, e.g. the
+	// sort order and the quoting of label values, overriding
+	// DoLegacyResourceSort and UseKyaml.  An unknown level is
+	// an error; see SupportedCompatibilityLevels.
+	CompatibilityLevel string
+
+	// When not nil, check that the namespace of each
+	// namespaced resource in the output is created by the
+	// build or known to exist; see NamespaceCheck.
+	NamespaceCheck *NamespaceCheck
+
+	// When not nil, check that no two resources in the
+	// output share values that must be unique, e.g. a
+	// Service nodePort; see UniquenessCheck.
+	UniquenessCheck *UniquenessCheck
+
+	// When not nil, check that the strategic merge patches
+	// of each kustomization don't write different values to
+	// the same field; see PatchConflictCheck.
+	PatchConflictCheck *PatchConflictCheck
+
+	// When not nil, the resources read for the resources
+	// entry "-" (see types.StdinResourcesPath), e.g. the
+	// output of an upstream generator piped to the build.
+	// Only the top-level kustomization may list "-", once.
+	StdinResources io.Reader
+
+	// Capabilities resolve, against these options, the
+	// capabilities that kustomizations may list in the
+	// capabilities of their requires field, e.g. those of
+	// a platform running kustomize as a library.  They may
+	// also override how the builtin ones, named by the
+	// types.Capability constants, resolve, e.g. to forbid
+	// helm.  A kustomization requiring a capability that
+	// doesn't resolve to true fails the build.
+	Capabilities map[string]func(Options) bool
+
+	// The ids of resources outside the build, e.g. a
+	// ConfigMap made by the cluster's operators, that
+	// references are to even though a resource in the build
+	// has the same name, so that a name reference to one is
+	// never updated to the name the build gives its own.
+	// An empty Gvk field or namespace matches any.
+	ExternalNames []resid.ResId
+
+	// When not nil, compare the generated objects of the
+	// build to those of a previous build, to bridge the
+	// move of ConfigMaps and Secrets from hashed names to
+	// stable ones; see HashMigrationCheck.
+	HashMigrationCheck *HashMigrationCheck
+
+	// When true, a build whose output has no resources
+	// fails, e.g. so that a continuous delivery system
+	// doesn't prune everything after a typo in the path of
+	// a resources entry.  The error says what the build
+	// read, to help find out why nothing was made.
+	ErrorOnEmptyOutput bool
 }
 
 // MakeDefaultOptions returns a default instance of Options.
@@ -56,8 +179,66 @@ func MakeDefaultOptions() *Options {
 	}
 }
 
+// defaultTransformerConfig returns the config that replaces
+// the builtin default in the build, or nil if there's none.
+func (o Options) defaultTransformerConfig() (*TransformerConfig, error) {
+	if o.ExtraTransformerConfig == nil && o.ReplaceTransformerConfig == nil {
+		return nil, nil
+	}
+	c := o.ReplaceTransformerConfig
+	if c == nil {
+		c = builtinconfig.MakeDefaultConfig()
+	}
+	return c.DeepCopy().Merge(o.ExtraTransformerConfig)
+}
+
+// enabledFeatures returns the names of the enabled
+// features of the build, as listed for exec plugins
+// in konfig.ExecPluginFeaturesEnv.
+func (o Options) enabledFeatures() []string {
+	var result []string
+	add := func(enabled bool, feature string) {
+		if enabled {
+			result = append(result, feature)
+		}
+	}
+	if pc := o.PluginConfig; pc != nil {
+		add(pc.PluginRestrictions == types.PluginRestrictionsNone,
+			konfig.FeaturePlugins)
+		add(pc.FnpLoadingOptions.EnableExec, konfig.FeatureExecFunctions)
+		add(pc.FnpLoadingOptions.EnableStar, konfig.FeatureStarlarkFunctions)
+		add(pc.FnpLoadingOptions.Network, konfig.FeatureFunctionNetwork)
+	}
+	add(o.useKyaml(), konfig.FeatureKyaml)
+	add(o.AddManagedbyLabel, konfig.FeatureManagedbyLabel)
+	add(o.AllowResourceIdChanges, konfig.FeatureResourceIdChanges)
+	add(o.TrackProvenance, konfig.FeatureProvenance)
+	return result
+}
+
+// capabilities returns the capabilities of the build, for
+// checking the requires fields of the kustomizations.
+func (o Options) capabilities() *target.Capabilities {
+	c := &target.Capabilities{
+		Names: map[string]bool{
+			types.CapabilityHelm: true,
+			types.CapabilityPlugins: o.PluginConfig != nil &&
+				o.PluginConfig.PluginRestrictions == types.PluginRestrictionsNone,
+		},
+	}
+	for name, resolve := range o.Capabilities {
+		c.Names[name] = resolve(o)
+	}
+	// Development builds have no version to check.
+	v := provenance.GetProvenance().Semver()
+	if _, err := version.ParseGeneric(v); err == nil {
+		c.ApiVersion = v
+	}
+	return c
+}
+
 func (o Options) IfApiMachineryElseKyaml(s1, s2 string) string {
-	if !o.UseKyaml {
+	if !o.useKyaml() {
 		return s1
 	}
 	return s2
@@ -0,0 +1,156 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestOverwrittenPatchWarnings(t *testing.T) {
+	deployment := resid.NewResIdWithNamespace(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}, "web", "")
+	for name, tc := range map[string]struct {
+		kustomization string
+		results       []resmap.PluginResult
+	}{
+		"label": {
+			kustomization: `
+commonLabels:
+  app: web
+  tier: front
+patches:
+- path: patch.yaml
+`,
+			results: []resmap.PluginResult{{
+				Plugin:   "PatchTransformer",
+				Resource: deployment,
+				Message: "patches[0] (patch.yaml) sets metadata/labels/app, " +
+					"which the LabelTransformer of commonLabels in " +
+					"/app/kustomization.yaml then sets to \"web\"; " +
+					"give the patch the allowOverwrite option to allow it",
+				CurrentValue:   "api",
+				SuggestedValue: "web",
+			}},
+		},
+		"same value": {
+			kustomization: `
+commonLabels:
+  app: api
+patches:
+- path: patch.yaml
+`,
+		},
+		"other keys": {
+			kustomization: `
+commonLabels:
+  team: x
+commonAnnotations:
+  reviewer: x
+patches:
+- path: patch.yaml
+`,
+		},
+		"suppressed": {
+			kustomization: `
+commonLabels:
+  app: web
+patches:
+- path: patch.yaml
+  options:
+    allowOverwrite: true
+`,
+		},
+		"json patch": {
+			kustomization: `
+namespace: prod
+namePrefix: p-
+commonAnnotations:
+  note: b
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: add
+      path: /metadata/annotations
+      value:
+        note: a
+    - op: replace
+      path: /metadata/namespace
+      value: dev
+`,
+			results: []resmap.PluginResult{{
+				Plugin:   "PatchTransformer",
+				Resource: deployment,
+				Message: "patches[0] sets metadata/annotations/note, " +
+					"which the AnnotationsTransformer of commonAnnotations in " +
+					"/app/kustomization.yaml then sets to \"b\"; " +
+					"give the patch the allowOverwrite option to allow it",
+				CurrentValue:   "a",
+				SuggestedValue: "b",
+			}, {
+				Plugin:   "PatchTransformer",
+				Resource: deployment,
+				Message: "patches[0] sets metadata/namespace, " +
+					"which the NamespaceTransformer of namespace in " +
+					"/app/kustomization.yaml then sets to \"prod\"; " +
+					"give the patch the allowOverwrite option to allow it",
+				CurrentValue:   "dev",
+				SuggestedValue: "prod",
+			}},
+		},
+		"cluster-scoped": {
+			kustomization: `
+namespace: prod
+patches:
+- target:
+    kind: ClusterRole
+  patch: |-
+    - op: add
+      path: /metadata/namespace
+      value: dev
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+			th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: api
+  annotations:
+    owner: bob
+`)
+			th.WriteK("/app", `
+resources:
+- resources.yaml
+`+tc.kustomization)
+			opts := th.MakeDefaultOptions()
+			k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+			_, err := k.Run("/app")
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.results, k.Results())
+			}
+		})
+	}
+}
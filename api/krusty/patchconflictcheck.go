@@ -0,0 +1,31 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// PatchConflictCheck configures a check, as the patches
+// of each kustomization are applied, that no two of its
+// strategic merge patches write different values to one
+// field of a resource, e.g. two patches setting the
+// resources of the same container, of which the last
+// would silently win.  Writing the same value isn't a
+// conflict; see resmap.PatchConflicts.
+type PatchConflictCheck struct {
+	// When true, a conflict fails the build, rather than
+	// being reported via Kustomizer.Warnings.
+	Strict bool
+}
+
+// warnings returns the conflicts recorded in the build.
+func (c *PatchConflictCheck) warnings(
+	conflicts *resmap.PatchConflicts) []string {
+	var result []string
+	for _, conflict := range conflicts.List() {
+		result = append(result, conflict.String())
+	}
+	return result
+}
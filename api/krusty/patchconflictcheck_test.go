@@ -0,0 +1,136 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConflictingPatches(th kusttest_test.Harness) {
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`)
+	th.WriteF("/app/small.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          limits:
+            memory: 256Mi
+`)
+	th.WriteF("/app/large.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          limits:
+            memory: 1Gi
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patches:
+- path: small.yaml
+- path: large.yaml
+`)
+	th.WriteK("/overlay", `
+resources:
+- ../app
+patches:
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+    spec:
+      replicas: 5
+`)
+}
+
+func TestPatchConflictCheckWarns(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConflictingPatches(th)
+	opts := th.MakeDefaultOptions()
+	opts.PatchConflictCheck = &krusty.PatchConflictCheck{}
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/overlay")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// The same replicas isn't a conflict, nor is the
+	// overlay overriding them.
+	assert.Equal(t, []string{
+		"patches 'small.yaml' and 'large.yaml' in /app set " +
+			"spec/template/spec/containers/[name=web]/resources/limits/memory " +
+			"of Deployment/web to different values",
+	}, k.Warnings())
+	// The last patch still wins.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - image: nginx
+        name: web
+        resources:
+          limits:
+            memory: 1Gi
+`)
+
+	k = krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err = k.Run("/app")
+	assert.NoError(t, err)
+	assert.Len(t, k.Warnings(), 1)
+
+	// The check is opt-in.
+	opts.PatchConflictCheck = nil
+	k = krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err = k.Run("/overlay")
+	assert.NoError(t, err)
+	assert.Empty(t, k.Warnings())
+}
+
+func TestPatchConflictCheckStrict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConflictingPatches(th)
+	opts := th.MakeDefaultOptions()
+	opts.PatchConflictCheck = &krusty.PatchConflictCheck{Strict: true}
+	err := th.RunWithErr("/overlay", opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"conflicting patches: patches 'small.yaml' and 'large.yaml' in /app")
+	}
+}
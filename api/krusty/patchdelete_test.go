@@ -3,6 +3,8 @@ package krusty_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 )
 
@@ -69,11 +71,14 @@ spec:
         image: helloworld
         name: whatever
 `
-	// Allow expected variable to be unused
-	_ = expected
 
-	// Currently, kustomize inserts $patch: delete elements into the resulting resources
-	erroneousActual := `
+	m := th.Run(".", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, expected)
+}
+
+func TestPatchDeleteFromPrimitiveList(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/deployment.yaml", `
 apiVersion: apps/v1
 kind: Deployment
 metadata:
@@ -82,15 +87,115 @@ spec:
   template:
     spec:
       containers:
-      - env:
-        - $patch: delete
-          name: NOT_EXISTING_FOR_REMOVAL
-        - name: EXISTING
-          value: EXISTING_VALUE
-        image: helloworld
-        name: whatever
-`
+      - name: server
+        image: server
+        args:
+        - --verbose
+        - --greeting=hello world
+        - --verbose=2
+        - --verbose
+      - name: sidecar
+        image: sidecar
+        args:
+        - --verbose
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: whatever
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        $deleteFromPrimitiveList/args:
+        - --verbose
+        - --greeting=hello world
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: whatever
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --verbose=2
+        image: server
+        name: server
+      - args:
+        - --verbose
+        image: sidecar
+        name: sidecar
+`)
+}
 
-	m := th.Run(".", th.MakeDefaultOptions())
-	th.AssertActualEqualsExpected(m, erroneousActual)
+func TestPatchDeleteFromPrimitiveListWarnsOfNoMatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: whatever
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        image: server
+        args:
+        - --flag=value
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: whatever
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        $deleteFromPrimitiveList/args:
+        - --flag
+`)
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app")
+	if assert.NoError(t, err) {
+		th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: whatever
+spec:
+  template:
+    spec:
+      containers:
+      - args:
+        - --flag=value
+        image: server
+        name: server
+`)
+		assert.Equal(t, []string{
+			"apps_v1_Deployment|~X|whatever: $deleteFromPrimitiveList/args: " +
+				`no element equal to "--flag" to delete`,
+		}, k.Warnings())
+	}
 }
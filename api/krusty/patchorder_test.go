@@ -0,0 +1,136 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const (
+	renamePatch = `
+- target:
+    kind: Deployment
+    name: web
+  patch: |-
+    - op: replace
+      path: /metadata/name
+      value: web2
+`
+	// Addresses the Deployment by the name it has before
+	// the rename.
+	scaleOldNamePatch = `
+- target:
+    kind: Deployment
+    name: web
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 3
+`
+	// Addresses the Deployment by the name it has after
+	// the rename.
+	scaleNewNamePatch = `
+- target:
+    kind: Deployment
+    name: web2
+  patch: |-
+    - op: add
+      path: /spec/replicas
+      value: 3
+`
+	scaledWeb2 = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web2
+spec:
+  replicas: 3
+`
+	unscaledWeb2 = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web2
+spec: {}
+`
+)
+
+func TestPatchOrder(t *testing.T) {
+	web2 := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}, "web2")
+	for name, tc := range map[string]struct {
+		options  string
+		patches  string
+		expected string
+		results  []resmap.PluginResult
+	}{
+		"list order, rename then patch": {
+			patches:  renamePatch + scaleOldNamePatch,
+			expected: scaledWeb2,
+		},
+		"list order, patch then rename": {
+			options:  "patchOptions:\n  order: list\n",
+			patches:  scaleNewNamePatch + renamePatch,
+			expected: unscaledWeb2,
+		},
+		"dependency order, rename then patch": {
+			options:  "patchOptions:\n  order: dependency\n",
+			patches:  renamePatch + scaleOldNamePatch,
+			expected: scaledWeb2,
+		},
+		"dependency order, patch then rename": {
+			options:  "patchOptions:\n  order: dependency\n",
+			patches:  scaleNewNamePatch + renamePatch,
+			expected: scaledWeb2,
+			results: []resmap.PluginResult{{
+				Plugin:   "PatchTransformer",
+				Resource: web2,
+				Message: "patches[0] selected no resources before the " +
+					"patches applied, so it was applied after the others",
+			}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec: {}
+`)
+			th.WriteK("/app", tc.options+`
+resources:
+- deployment.yaml
+patches:`+tc.patches)
+			opts := th.MakeDefaultOptions()
+			k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+			m, err := k.Run("/app")
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			th.AssertActualEqualsExpected(m, tc.expected)
+			assert.Equal(t, tc.results, k.Results())
+		})
+	}
+}
+
+func TestPatchOrderInvalid(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+patchOptions:
+  order: random
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"patchOptions.order should be list or dependency")
+	}
+}
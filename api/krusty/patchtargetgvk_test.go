@@ -0,0 +1,172 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeDeploymentsAtTwoVersions(th kusttest_test.Harness) {
+	th.WriteF("/app/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: current
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: legacy
+`)
+}
+
+const replicasJsonPatch = `
+- op: add
+  path: /spec
+  value:
+    replicas: 3
+`
+
+// A target without a version selects every version,
+// for strategic merge and JSON 6902 patches alike.
+func TestPatchTargetVersionWildcard(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDeploymentsAtTwoVersions(th)
+	th.WriteF("/app/patch.yaml", replicasJsonPatch)
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+patches:
+- target:
+    group: apps
+    kind: Deployment
+  patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: any
+      labels:
+        patched: sm
+patchesJson6902:
+- target:
+    kind: Deployment
+    name: legacy
+  path: patch.yaml
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    patched: sm
+  name: current
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  labels:
+    patched: sm
+  name: legacy
+spec:
+  replicas: 3
+`)
+}
+
+func TestPatchTargetStrictGvk(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDeploymentsAtTwoVersions(th)
+	th.WriteF("/app/patch.yaml", replicasJsonPatch)
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: legacy
+  path: patch.yaml
+  options:
+    strictGvk: true
+- target:
+    group: apps
+    version: v1beta2
+    kind: Deployment
+    name: legacy
+  path: patch.yaml
+  options:
+    strictGvk: true
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: current
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: legacy
+spec:
+  replicas: 3
+`)
+}
+
+func TestPatchTargetStrictGvkNeedsVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeDeploymentsAtTwoVersions(th)
+	th.WriteF("/app/patch.yaml", replicasJsonPatch)
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+patches:
+- target:
+    group: apps
+    kind: Deployment
+  path: patch.yaml
+  options:
+    strictGvk: true
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"the strictGvk option needs a target with a version and kind")
+	}
+}
+
+func TestPatchTargetVersionAmbiguity(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/deployments.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: web
+`)
+	th.WriteF("/app/patch.yaml", replicasJsonPatch)
+	th.WriteK("/app", `
+resources:
+- deployments.yaml
+patches:
+- target:
+    kind: Deployment
+    name: web
+  path: patch.yaml
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "selector is ambiguous")
+		assert.Contains(t, err.Error(),
+			"apps_v1_Deployment|~X|web and apps_v1beta2_Deployment|~X|web")
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/konfig"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 // The PrintPluginEnv plugin is a toy plugin that emits
@@ -59,6 +62,7 @@ apiVersion: v1
 env:
   kustomize_plugin_config_root: `+dir+`
   kustomize_plugin_home: `+pHome+`
+  kustomize_plugin_protocol_version: "`+konfig.ExecPluginProtocolVersion+`"
   pwd: `+dir+`
 kind: GeneratedEnv
 metadata:
@@ -66,6 +70,73 @@ metadata:
 `)
 }
 
+// Exec plugins get variables describing the build, and
+// inherit only the variables the options let them.
+func TestPluginEnvironmentBuildContext(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepExecPlugin(
+			"someteam.example.com", "v1", "PrintPluginEnv")
+	defer th.Reset()
+
+	for k, v := range map[string]string{
+		"KUSTOMIZE_TEST_CI_TOKEN": "secret",
+		"KUSTOMIZE_TEST_PUBLIC":   "public",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	th.WriteK("/app", `
+generators:
+- config.yaml
+`)
+	th.WriteF("/app/config.yaml", `
+apiVersion: someteam.example.com/v1
+kind: PrintPluginEnv
+metadata:
+  name: irrelevantHere
+argsOneLiner: >-
+  KUSTOMIZE_BUILD_ROOT KUSTOMIZE_FEATURES
+  KUSTOMIZE_PLUGIN_CONFIG_PATH
+  KUSTOMIZE_TEST_CI_TOKEN KUSTOMIZE_TEST_PUBLIC
+`)
+	opts := th.MakeOptionsPluginsEnabled()
+	opts.TrackProvenance = true
+	opts.PluginConfig.ExecPluginEnv = types.ExecPluginEnv{
+		Deny: []string{"KUSTOMIZE_TEST_CI_*"},
+	}
+	m := th.Run("/app", opts)
+	r := m.Resources()[0]
+	get := func(name string) string {
+		s, err := r.GetString("env." + name)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		return s
+	}
+	assert.Equal(t, "/app", get(konfig.ExecPluginBuildRootEnv))
+	features := []string{konfig.FeaturePlugins, konfig.FeatureProvenance}
+	if opts.UseKyaml {
+		features = []string{
+			konfig.FeaturePlugins, konfig.FeatureKyaml, konfig.FeatureProvenance}
+	}
+	assert.Equal(t,
+		strings.Join(features, ","), get(konfig.ExecPluginFeaturesEnv))
+	assert.Contains(t,
+		filepath.Base(get(konfig.ExecPluginConfigPathEnv)), "kust-plugin-config-")
+	assert.Equal(t, "", get("KUSTOMIZE_TEST_CI_TOKEN"))
+	assert.Equal(t, "public", get("KUSTOMIZE_TEST_PUBLIC"))
+
+	// With an allowlist, only the variables on it are inherited.
+	opts.PluginConfig.ExecPluginEnv = types.ExecPluginEnv{
+		Allow: []string{"KUSTOMIZE_TEST_CI_TOKEN"},
+	}
+	m = th.Run("/app", opts)
+	r = m.Resources()[0]
+	assert.Equal(t, "secret", get("KUSTOMIZE_TEST_CI_TOKEN"))
+	assert.Equal(t, "", get("KUSTOMIZE_TEST_PUBLIC"))
+	assert.Equal(t, "/app", get(konfig.ExecPluginBuildRootEnv))
+}
+
 func makeTmpDir(t *testing.T) string {
 	base, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,64 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeSleepingFn(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+generators:
+- gener.yaml
+`)
+	th.WriteF("/app/gener.yaml", `
+kind: executable
+metadata:
+  name: demo
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./fnplugin_test/fnexecsleep.sh
+`)
+}
+
+func TestPluginLimitsTimeout(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t)
+	defer th.Reset()
+	writeSleepingFn(th.Harness)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	o.PluginConfig.Limits.Timeout = 200 * time.Millisecond
+	start := time.Now()
+	err := th.RunWithErr("/app", o)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+	assert.Contains(t, err.Error(),
+		"./fnplugin_test/fnexecsleep.sh exceeded its timeout of 200ms, "+
+			"and was killed; its output ended with:\nstill working\n")
+}
+
+func TestPluginLimitsContextCanceled(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t)
+	defer th.Reset()
+	writeSleepingFn(th.Harness)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	_, err := krusty.MakeKustomizer(th.GetFSys(), &o).RunWithContext(ctx, "/app")
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(),
+		"./fnplugin_test/fnexecsleep.sh: context canceled")
+}
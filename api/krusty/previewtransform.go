@@ -0,0 +1,100 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// PreviewTransform runs one transformer on a copy of the
+// given ResMap, e.g. for an editor to show what a patch
+// would do without building the kustomization, and
+// returns the resources before and after it ran, and what
+// it changed.
+//
+// The config is either that of a builtin or custom
+// transformer plugin, as a transformers entry would give
+// it, or an entry of the patches field, e.g.
+//
+//	path: patch.yaml
+//	target:
+//	  kind: Deployment
+//
+// The plugin is loaded as the Kustomizer's options allow,
+// and configured with the given helpers, whose loader
+// reads the files the config refers to.  The given ResMap
+// isn't changed, even if the transformer fails.
+func (b *Kustomizer) PreviewTransform(
+	m resmap.ResMap, config []byte, h *resmap.PluginHelpers) (
+	before, after resmap.ResMap, diff *resmap.ResMapDiff, err error) {
+	config, err = transformerConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	configs, err := h.ResmapFactory().NewResMapFromBytes(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if configs.Size() != 1 {
+		return nil, nil, nil, fmt.Errorf(
+			"expected the config of one transformer, got %d", configs.Size())
+	}
+	t, err := pLdr.NewLoader(b.options.PluginConfig, h.ResmapFactory()).
+		LoadTransformer(h.Loader(), h.Validator(), configs.GetByIndex(0))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	before = m.DeepCopy()
+	after = m.DeepCopy()
+	if err = t.Transform(after); err != nil {
+		return nil, nil, nil, err
+	}
+	diff, err = before.Diff(after)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return before, after, diff, nil
+}
+
+// transformerConfig returns the given config if it's that
+// of a plugin, i.e. has a kind, or else reads it as an
+// entry of the patches field, returning the config of the
+// builtin PatchTransformer that a build would apply it
+// with.
+func transformerConfig(config []byte) ([]byte, error) {
+	var tm types.TypeMeta
+	if err := yaml.Unmarshal(config, &tm); err != nil {
+		return nil, err
+	}
+	if tm.Kind != "" {
+		return config, nil
+	}
+	var p types.Patch
+	if err := yaml.UnmarshalStrict(config, &p); err != nil {
+		return nil, errors.Wrap(err, "reading patches entry")
+	}
+	if (p.Path == "") == (p.Patch == "") {
+		return nil, fmt.Errorf(
+			"patches entry must set exactly one of path and patch")
+	}
+	return yaml.Marshal(struct {
+		types.TypeMeta `json:",inline"`
+		Metadata       types.ObjectMeta `json:"metadata"`
+		types.Patch    `json:",inline"`
+	}{
+		TypeMeta: types.TypeMeta{
+			APIVersion: konfig.BuiltinPluginApiVersion,
+			Kind:       "PatchTransformer",
+		},
+		Metadata: types.ObjectMeta{Name: "preview"},
+		Patch:    p,
+	})
+}
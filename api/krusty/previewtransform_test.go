@@ -0,0 +1,153 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+)
+
+func TestPreviewTransform(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF("/app/patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	original, err := m.AsYaml()
+	require.NoError(t, err)
+
+	ldr, err := loader.NewLoader(
+		loader.RestrictionRootOnly, "/app", th.GetFSys())
+	require.NoError(t, err)
+	pvd := provider.NewDefaultDepProvider()
+	h := resmap.NewPluginHelpers(ldr, valtest_test.MakeFakeValidator(),
+		resmap.NewFactory(pvd.GetResourceFactory(), pvd.GetConflictDetectorFactory()),
+		resmap.NewSchema(nil))
+	o := th.MakeDefaultOptions()
+	b := krusty.MakeKustomizer(th.GetFSys(), &o)
+
+	tests := map[string]struct {
+		config   string
+		expected string
+		fields   []string
+		err      string
+	}{
+		"patches entry": {
+			config: `
+path: patch.yaml
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`,
+			fields: []string{"spec/replicas"},
+		},
+		"builtin plugin": {
+			config: `
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: labels
+labels:
+  app: web
+fieldSpecs:
+- path: metadata/labels
+  create: true
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+spec:
+  replicas: 1
+`,
+			fields: []string{"metadata/labels/app"},
+		},
+		"failing transformer": {
+			config: `
+patch: |-
+  - op: replace
+    path: /spec/replicas
+    value: 3
+target:
+  kind: StatefulSet
+options:
+  strict: true
+`,
+			err: "strict patch target matches no resources",
+		},
+		"bad patches entry": {
+			config: `
+target:
+  kind: Deployment
+`,
+			err: "patches entry must set exactly one of path and patch",
+		},
+		"two configs": {
+			config: `
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: a
+---
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: b
+`,
+			err: "expected the config of one transformer, got 2",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			before, after, diff, err := b.PreviewTransform(m, []byte(tc.config), h)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+			} else if assert.NoError(t, err) {
+				th.AssertActualEqualsExpected(before, string(original))
+				th.AssertActualEqualsExpected(after, tc.expected)
+				if assert.Len(t, diff.Modified, 1) {
+					assert.Equal(t, tc.fields, diff.Modified[0].Fields)
+				}
+			}
+			actual, err := m.AsYaml()
+			assert.NoError(t, err)
+			assert.Equal(t, string(original), string(actual))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestTrackProvenance(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+patchesStrategicMerge:
+- image.yaml
+patches:
+- path: replicas.yaml
+  target:
+    kind: Deployment
+- patch: |-
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: web
+      labels:
+        tier: front
+`)
+	th.WriteF("/app/overlay/image.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:2
+`)
+	th.WriteF("/app/overlay/replicas.yaml", `
+- op: replace
+  path: /spec/replicas
+  value: 3
+`)
+	o := th.MakeDefaultOptions()
+	o.TrackProvenance = true
+	m := th.Run("/app/overlay", o)
+
+	// Patched fields are found by the output's CurId.
+	id := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"},
+		"prod-web")
+	src, found := m.Provenance(id, "spec/template/spec/containers/0/image")
+	assert.True(t, found)
+	assert.Equal(t, resmap.Source{
+		PatchFile:         "image.yaml",
+		KustomizationRoot: "/app/overlay",
+	}, src)
+	src, found = m.Provenance(id, "spec/replicas")
+	assert.True(t, found)
+	assert.Equal(t, "replicas.yaml", src.PatchFile)
+	src, found = m.Provenance(id, "metadata/labels/tier")
+	assert.True(t, found)
+	assert.Equal(t, "", src.PatchFile)
+	// As are the fields other transformers wrote, but
+	// not those none wrote.
+	src, found = m.Provenance(id, "metadata/name")
+	assert.True(t, found)
+	assert.Equal(t, "PrefixSuffixTransformer", src.Transformer)
+	_, found = m.Provenance(id, "spec/template/spec/containers/0/name")
+	assert.False(t, found)
+
+	var b bytes.Buffer
+	assert.NoError(t, m.DebugTo(&b, "overlay", resmap.DebugOptions{}))
+	assert.Equal(t, `# resmap "overlay" size=1
+# resource index=0 curId=apps_v1_Deployment|~X|prod-web orgId=apps_v1_Deployment|~X|prod-web origin=loaded
+# provenance path=metadata/labels/tier patch="" root="/app/overlay"
+# provenance path=metadata/name transformer=PrefixSuffixTransformer root="/app/overlay"
+# provenance path=spec/replicas patch="replicas.yaml" root="/app/overlay"
+# provenance path=spec/template/spec/containers/0/image patch="image.yaml" root="/app/overlay"
+`, b.String())
+
+	// Nothing is tracked by default.
+	m = th.Run("/app/overlay", th.MakeDefaultOptions())
+	_, found = m.Provenance(id, "spec/replicas")
+	assert.False(t, found)
+}
+
+func TestProvenanceOfLayers(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+`)
+	th.WriteK("/app/staging", `
+namespace: staging
+commonLabels:
+  env: staging
+resources:
+- ../base
+`)
+	th.WriteK("/app/prod", `
+namespace: prod
+images:
+- name: app
+  newTag: "2"
+resources:
+- ../staging
+`)
+	o := th.MakeDefaultOptions()
+	o.TrackProvenance = true
+	m := th.Run("/app/prod", o)
+	r := m.Resources()[0]
+
+	// Each layer's runs are recorded, in the order they ran.
+	var ran []string
+	for _, rec := range r.Provenance() {
+		ran = append(ran, rec.KustomizationRoot+" "+rec.Transformer)
+	}
+	assert.Equal(t, []string{
+		"/app/staging NamespaceTransformer",
+		"/app/staging LabelTransformer",
+		"/app/prod NamespaceTransformer",
+		"/app/prod ImageTagTransformer",
+	}, ran)
+	src, found := m.Provenance(r.CurId(), "metadata/namespace")
+	assert.True(t, found)
+	assert.Equal(t, resmap.Source{
+		Transformer:       "NamespaceTransformer",
+		KustomizationRoot: "/app/prod",
+	}, src)
+
+	var b bytes.Buffer
+	assert.NoError(t, m.DebugTo(&b, "prod", resmap.DebugOptions{History: true}))
+	assert.Contains(t, b.String(), `
+# history transformer=NamespaceTransformer patch="" root="/app/prod" fields=metadata/namespace
+# history transformer=ImageTagTransformer patch="" root="/app/prod" fields=spec/template/spec/containers/0/image
+`)
+
+	// None of it is in the output.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: staging
+  name: web
+  namespace: prod
+spec:
+  selector:
+    matchLabels:
+      env: staging
+  template:
+    metadata:
+      labels:
+        env: staging
+    spec:
+      containers:
+      - image: app:2
+        name: app
+`)
+}
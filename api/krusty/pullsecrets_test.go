@@ -0,0 +1,120 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writePullSecretBase(th kusttest_test.Harness) {
+	th.WriteF("/app/base/config.json", `{"auths":{}}`)
+	th.WriteF("/app/base/accounts.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: default
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: builder
+  labels:
+    pulls: private
+imagePullSecrets:
+- name: other
+- name: regcred
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: unrelated
+`)
+}
+
+func TestAttachPullSecretToServiceAccounts(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePullSecretBase(th)
+	th.WriteK("/app/base", `
+resources:
+- accounts.yaml
+secretGenerator:
+- name: regcred
+  type: kubernetes.io/dockerconfigjson
+  files:
+  - .dockerconfigjson=config.json
+  attachToServiceAccounts:
+  - default
+  - name: deployer
+    optional: true
+  - selector:
+      labelSelector: pulls=private
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: prod-
+resources:
+- ../base
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	// The builder has the secret already, so isn't given
+	// it twice.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+imagePullSecrets:
+- name: prod-regcred-bm4mtdbm8c
+kind: ServiceAccount
+metadata:
+  name: prod-default
+---
+apiVersion: v1
+imagePullSecrets:
+- name: other
+- name: prod-regcred-bm4mtdbm8c
+kind: ServiceAccount
+metadata:
+  labels:
+    pulls: private
+  name: prod-builder
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: prod-unrelated
+---
+apiVersion: v1
+data:
+  .dockerconfigjson: eyJhdXRocyI6e319
+kind: Secret
+metadata:
+  name: prod-regcred-bm4mtdbm8c
+type: kubernetes.io/dockerconfigjson
+`)
+}
+
+func TestAttachPullSecretToMissingServiceAccount(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writePullSecretBase(th)
+	th.WriteK("/app/base", `
+resources:
+- accounts.yaml
+secretGenerator:
+- name: regcred
+  namespace: ci
+  type: kubernetes.io/dockerconfigjson
+  files:
+  - .dockerconfigjson=config.json
+  attachToServiceAccounts:
+  - default
+`)
+	// The ServiceAccount must be in the secret's namespace.
+	err := th.RunWithErr("/app/base", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"secretGenerator entry 'regcred' (namespace 'ci') in "+
+				"/app/base/kustomization.yaml: no ServiceAccount "+
+				"'default' to attach the secret to")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeRegistryRewriteApp(th kusttest_test.Harness, audit bool) {
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+transformers:
+- rewrite.yaml
+`)
+	config := `
+apiVersion: builtin
+kind: RegistryRewriteTransformer
+metadata:
+  name: rewrite
+mirrors:
+- from: docker.io
+  to: registry.corp/dockerhub
+defaultMirror: registry.corp/dockerhub/library
+allowedRegistries:
+- registry.corp
+`
+	if audit {
+		config += "audit: true\n"
+	}
+	th.WriteF("/app/rewrite.yaml", config)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.33
+      containers:
+      - name: app
+        image: registry.corp/web:3
+      - name: proxy
+        image: docker.io/envoyproxy/envoy:v1.17.0
+`)
+}
+
+func TestRegistryRewriteTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRegistryRewriteApp(th, false)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.corp/web:3
+        name: app
+      - image: registry.corp/dockerhub/envoyproxy/envoy:v1.17.0
+        name: proxy
+      initContainers:
+      - image: registry.corp/dockerhub/library/busybox:1.33
+        name: init
+`)
+}
+
+// In audit mode, the Kustomizer reports the rewrites
+// as results instead of making them.
+func TestRegistryRewriteTransformerAudit(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRegistryRewriteApp(th, true)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.corp/web:3
+        name: app
+      - image: docker.io/envoyproxy/envoy:v1.17.0
+        name: proxy
+      initContainers:
+      - image: busybox:1.33
+        name: init
+`)
+	web := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}, "web")
+	assert.Equal(t, []resmap.PluginResult{
+		{
+			Plugin:         "RegistryRewriteTransformer",
+			Resource:       web,
+			Message:        "would rewrite image 'docker.io/envoyproxy/envoy:v1.17.0' to 'registry.corp/dockerhub/envoyproxy/envoy:v1.17.0'",
+			CurrentValue:   "docker.io/envoyproxy/envoy:v1.17.0",
+			SuggestedValue: "registry.corp/dockerhub/envoyproxy/envoy:v1.17.0",
+		},
+		{
+			Plugin:         "RegistryRewriteTransformer",
+			Resource:       web,
+			Message:        "would rewrite image 'busybox:1.33' to 'registry.corp/dockerhub/library/busybox:1.33'",
+			CurrentValue:   "busybox:1.33",
+			SuggestedValue: "registry.corp/dockerhub/library/busybox:1.33",
+		},
+	}, k.Results())
+
+	// Results are those of the last Run.
+	writeRegistryRewriteApp(th, false)
+	_, err = k.Run("/app")
+	assert.NoError(t, err)
+	assert.Empty(t, k.Results())
+}
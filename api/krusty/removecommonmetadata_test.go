@@ -0,0 +1,248 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeCommonMetadataBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+commonLabels:
+  app: web
+  team: shop
+commonAnnotations:
+  owner: shop@example.com
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/base/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  labels:
+    team: shop
+data:
+  a: b
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+removeCommonLabels:
+- team
+removeCommonAnnotations:
+- owner
+commonAnnotations:
+  tier: front
+`)
+}
+
+func TestRemoveCommonMetadataWithProvenance(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeCommonMetadataBase(th)
+	opts := th.MakeDefaultOptions()
+	opts.TrackProvenance = true
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app/overlay")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, k.Warnings())
+	// The label the ConfigMap had already stays.
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    tier: front
+  labels:
+    app: web
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      annotations:
+        tier: front
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: app:1
+        name: app
+---
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  annotations:
+    tier: front
+  labels:
+    app: web
+    team: shop
+  name: config
+`)
+}
+
+func TestRemoveCommonMetadataWithoutProvenance(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeCommonMetadataBase(th)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, err := k.Run("/app/overlay")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"/app/overlay/kustomization.yaml: provenance isn't tracked, " +
+			"so removeCommonLabels and removeCommonAnnotations remove " +
+			"their keys from every resource, not just where a base's " +
+			"commonLabels or commonAnnotations put them",
+	}, k.Warnings())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    tier: front
+  labels:
+    app: web
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      annotations:
+        tier: front
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: app:1
+        name: app
+---
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  annotations:
+    tier: front
+  labels:
+    app: web
+  name: config
+`)
+}
+
+func TestRemoveAllCommonMetadataDropsEmptiedMaps(t *testing.T) {
+	for _, trackProvenance := range []bool{true, false} {
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK("/app/base", `
+commonLabels:
+  team: shop
+commonAnnotations:
+  owner: shop@example.com
+resources:
+- resources.yaml
+`)
+		th.WriteF("/app/base/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  a: b
+`)
+		th.WriteK("/app/overlay", `
+resources:
+- ../base
+removeCommonLabels:
+- team
+removeCommonAnnotations:
+- owner
+`)
+		opts := th.MakeDefaultOptions()
+		opts.TrackProvenance = trackProvenance
+		m, err := krusty.MakeKustomizer(th.GetFSys(), &opts).Run("/app/overlay")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		for _, r := range m.Resources() {
+			for _, path := range []string{
+				"metadata.labels", "metadata.annotations",
+				"spec.template.metadata.labels",
+				"spec.template.metadata.annotations",
+			} {
+				_, err := r.GetFieldValue(path)
+				assert.Error(t, err, "%s of %s, provenance %v",
+					path, r.CurId(), trackProvenance)
+			}
+		}
+	}
+}
+
+func TestRemoveCommonLabelsKeptInSelector(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+commonLabels:
+  team: shop
+resources:
+- service.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    team: shop
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+removeCommonLabels:
+- team
+`)
+	opts := th.MakeDefaultOptions()
+	opts.TrackProvenance = true
+	err := th.RunWithErr("/app/overlay", opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"removeCommonLabels removes label 'team', but "+
+				"~G_v1_Service|~X|web keeps it in its selector at spec/selector")
+	}
+}
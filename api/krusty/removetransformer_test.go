@@ -0,0 +1,58 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestRemoveTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/base", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("/base/resources.yaml", `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: debug
+  labels:
+    environment: dev
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	th.WriteK("/app", `
+resources:
+- ../base
+transformers:
+- remove.yaml
+`)
+	th.WriteF("/app/remove.yaml", `
+apiVersion: builtin
+kind: RemoveTransformer
+metadata:
+  name: remove
+selectors:
+- kind: NetworkPolicy
+- labelSelector: environment=dev
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
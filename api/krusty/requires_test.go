@@ -0,0 +1,90 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeRequiringBase(th kusttest_test.Harness, requires string) {
+	th.WriteK("/app/base", `
+requires:
+`+requires+`
+resources:
+- cm.yaml
+`)
+	th.WriteF("/app/base/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+}
+
+func TestRequiresPlugins(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRequiringBase(th, "  plugins: true\n  helm: true")
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"/app/base/kustomization.yaml requires 'plugins', which the build lacks")
+	}
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig = konfig.DisabledPluginConfig()
+	opts.PluginConfig.PluginRestrictions = types.PluginRestrictionsNone
+	th.AssertActualEqualsExpected(th.Run("/app/overlay", opts), `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+}
+
+func TestRequiresCapabilities(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRequiringBase(th, `  helm: true
+  capabilities:
+  - corp-registry
+  - corp-secrets`)
+	opts := th.MakeDefaultOptions()
+	opts.Capabilities = map[string]func(krusty.Options) bool{
+		"corp-registry": func(krusty.Options) bool { return true },
+		"helm":          func(o krusty.Options) bool { return o.DoPrune },
+	}
+	err := th.RunWithErr("/app/overlay", opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"/app/base/kustomization.yaml requires 'helm', 'corp-secrets', "+
+				"which the build lacks")
+	}
+
+	opts.DoPrune = true
+	opts.Capabilities["corp-secrets"] = func(krusty.Options) bool { return true }
+	th.AssertActualEqualsExpected(th.Run("/app/overlay", opts), `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+}
+
+func TestRequiresBadMinApiVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeRequiringBase(th, "  minApiVersion: latest")
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"requires.minApiVersion in /app/base/kustomization.yaml")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/ifc"
+	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/target"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ResolveInputs returns the files and remote refs that a
+// Kustomizer made with the given filesystem and options
+// would read when run on the given path.
+//
+// This is a dry resolve, e.g. for file watchers that want
+// to know which files affect a build.  The kustomization
+// graph is walked, but resources aren't loaded, plugins
+// aren't run, and remote refs are reported without being
+// fetched.  File paths are relative to the given path.
+// Missing files and cycles are reported as a build would
+// report them.
+func ResolveInputs(
+	fSys filesys.FileSystem, path string, o *Options) ([]types.InputRef, error) {
+	b := MakeKustomizer(fSys, o)
+	kt, ldr, err := b.loadTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	defer ldr.Cleanup()
+	return kt.ResolveInputs(fSys, b.loadRestrictor())
+}
+
+// loadTarget loads the kustomization at the given path,
+// without building it.  The caller must clean up the
+// returned loader.
+func (b *Kustomizer) loadTarget(
+	path string) (*target.KustTarget, ifc.Loader, error) {
+	resmapFactory := resmap.NewFactory(
+		b.depProvider.GetResourceFactory(),
+		b.depProvider.GetConflictDetectorFactory())
+	ldr, err := fLdr.NewLoader(b.loadRestrictor(), path, b.fSys)
+	if err != nil {
+		return nil, nil, err
+	}
+	fLdr.SetInputLimits(ldr, b.options.InputLimits)
+	kt := target.NewKustTarget(
+		ldr,
+		b.depProvider.GetFieldValidator(),
+		resmapFactory,
+		pLdr.NewLoader(b.options.PluginConfig, resmapFactory),
+	)
+	if err = kt.Load(); err != nil {
+		ldr.Cleanup()
+		return nil, nil, err
+	}
+	return kt, ldr, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestResolveInputs(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+- https://example.com/service.yaml
+configMapGenerator:
+- name: cm
+  files:
+  - conf=app.conf
+  envs:
+  - app.env
+configurations:
+- config.yaml
+crds:
+- crd.json
+`)
+	th.WriteF("/app/base/deploy.yaml", `this is never parsed`)
+	th.WriteF("/app/base/app.conf", `a`)
+	th.WriteF("/app/base/app.env", `b=c`)
+	th.WriteF("/app/base/config.yaml", `namePrefix: []`)
+	th.WriteF("/app/base/crd.json", `{}`)
+	th.WriteC("/app/comp", `
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/comp/patch.yaml", `p`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+- github.com/example/repo//base?ref=v1
+- "-"
+components:
+- ../comp
+patchesStrategicMerge:
+- |-
+  apiVersion: v1
+  kind: Service
+  metadata:
+    name: inline
+patches:
+- path: json.yaml
+  target:
+    kind: Deployment
+transformers:
+- |-
+  apiVersion: builtin
+  kind: LabelTransformer
+  metadata:
+    name: inline
+- transformer.yaml
+validators:
+- validator.yaml
+`)
+	th.WriteF("/app/overlay/json.yaml", `j`)
+	th.WriteF("/app/overlay/transformer.yaml", `t`)
+	th.WriteF("/app/overlay/validator.yaml", `v`)
+
+	o := th.MakeDefaultOptions()
+	o.LoadRestrictions = types.LoadRestrictionsNone
+	refs, err := krusty.ResolveInputs(th.GetFSys(), "/app/overlay", &o)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.InputRef{
+		{Path: "kustomization.yaml", Role: types.InputRoleKustomization},
+		{Path: "../base/kustomization.yaml", Role: types.InputRoleKustomization},
+		{Path: "../base/deploy.yaml", Role: types.InputRoleResource},
+		{Path: "https://example.com/service.yaml", Role: types.InputRoleResource, Remote: true},
+		{Path: "../base/config.yaml", Role: types.InputRoleConfiguration},
+		{Path: "../base/crd.json", Role: types.InputRoleCrd},
+		{Path: "../base/app.conf", Role: types.InputRoleGeneratorSource},
+		{Path: "../base/app.env", Role: types.InputRoleGeneratorSource},
+		{Path: "github.com/example/repo//base?ref=v1", Role: types.InputRoleResource, Remote: true},
+		{Path: "../comp/kustomization.yaml", Role: types.InputRoleComponent},
+		{Path: "../comp/patch.yaml", Role: types.InputRolePatch},
+		{Path: "json.yaml", Role: types.InputRolePatch},
+		{Path: "transformer.yaml", Role: types.InputRoleTransformer},
+		{Path: "validator.yaml", Role: types.InputRoleValidator},
+	}, refs)
+}
+
+func TestResolveInputsErrors(t *testing.T) {
+	testCases := map[string]struct {
+		files         map[string]string
+		expectedError string
+	}{
+		"missing resource": {
+			files: map[string]string{
+				"/app/kustomization.yaml": `
+resources:
+- missing.yaml
+`,
+			},
+			expectedError: "accumulateFile error",
+		},
+		"missing patch": {
+			files: map[string]string{
+				"/app/kustomization.yaml": `
+patches:
+- path: missing.yaml
+`,
+			},
+			expectedError: "missing.yaml' doesn't exist",
+		},
+		"cycle": {
+			files: map[string]string{
+				"/app/kustomization.yaml": `
+resources:
+- sub
+`,
+				"/app/sub/kustomization.yaml": `
+resources:
+- ..
+`,
+			},
+			expectedError: "cycle detected",
+		},
+		"file outside root": {
+			files: map[string]string{
+				"/app/kustomization.yaml": `
+configurations:
+- ../config.yaml
+`,
+				"/config.yaml": `namePrefix: []`,
+			},
+			expectedError: "is not in or below",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			for path, content := range tc.files {
+				th.WriteF(path, content)
+			}
+			o := th.MakeDefaultOptions()
+			_, err := krusty.ResolveInputs(th.GetFSys(), "/app", &o)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// A real build fails too.
+			if th.RunWithErr("/app", o) == nil {
+				t.Fatalf("expected build to fail")
+			}
+		})
+	}
+}
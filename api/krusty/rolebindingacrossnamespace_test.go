@@ -342,3 +342,55 @@ subjects:
   namespace: namespace-1
 `)
 }
+
+func TestRoleBindingSubjectInOtherNamespaceWithPrefix(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: dev-
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: deployer
+  namespace: ns-b
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: deployer
+  namespace: ns-a
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: edit
+subjects:
+- kind: ServiceAccount
+  name: deployer
+  namespace: ns-b
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: dev-deployer
+  namespace: ns-b
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: dev-deployer
+  namespace: ns-a
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: edit
+subjects:
+- kind: ServiceAccount
+  name: dev-deployer
+  namespace: ns-b
+`)
+}
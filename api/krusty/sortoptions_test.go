@@ -0,0 +1,151 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeSortOptionsBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- widget.yaml
+configMapGenerator:
+- name: base-conf
+  literals:
+  - a=b
+generatorOptions:
+  disableNameSuffixHash: true
+# ignored, as only the top-level kustomization's count
+sortOptions:
+  order: fifo
+`)
+	th.WriteF("/app/base/widget.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+`)
+	th.WriteF("/app/overlay/team.yaml", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa
+  namespace: team
+`)
+	th.WriteF("/app/overlay/svc.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`)
+}
+
+func kindsAndNames(m resmap.ResMap) []string {
+	var result []string
+	for _, r := range m.Resources() {
+		result = append(result, r.GetKind()+"/"+r.GetName())
+	}
+	return result
+}
+
+func TestSortOptionsPreserveFileOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsBase(th)
+	th.WriteK("/app/overlay", `
+namePrefix: p-
+resources:
+- team.yaml
+- ../base
+- svc.yaml
+configMapGenerator:
+- name: overlay-conf
+  literals:
+  - c=d
+generatorOptions:
+  disableNameSuffixHash: true
+sortOptions:
+  order: preserveFileOrder
+`)
+	o := th.MakeDefaultOptions()
+	o.DoLegacyResourceSort = true
+	m := th.Run("/app/overlay", o)
+	assert.Equal(t, []string{
+		"Namespace/team",
+		"ServiceAccount/p-sa",
+		"CustomResourceDefinition/widgets.example.com",
+		"Widget/p-w",
+		"ConfigMap/p-base-conf",
+		"Service/p-svc",
+		"ConfigMap/p-overlay-conf",
+	}, kindsAndNames(m))
+}
+
+func TestSortOptionsOverrideOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsBase(th)
+	th.WriteK("/app/overlay", `
+resources:
+- team.yaml
+- ../base
+- svc.yaml
+sortOptions:
+  order: legacy
+`)
+	o := th.MakeDefaultOptions()
+	o.DoLegacyResourceSort = false
+	assert.Equal(t, []string{
+		"Namespace/team",
+		"CustomResourceDefinition/widgets.example.com",
+		"ServiceAccount/sa",
+		"ConfigMap/base-conf",
+		"Service/svc",
+		"Widget/w",
+	}, kindsAndNames(th.Run("/app/overlay", o)))
+
+	th.WriteK("/app/overlay", `
+resources:
+- team.yaml
+- ../base
+- svc.yaml
+sortOptions:
+  order: fifo
+`)
+	o.DoLegacyResourceSort = true
+	assert.Equal(t, []string{
+		"Namespace/team",
+		"ServiceAccount/sa",
+		"CustomResourceDefinition/widgets.example.com",
+		"Widget/w",
+		"ConfigMap/base-conf",
+		"Service/svc",
+	}, kindsAndNames(th.Run("/app/overlay", o)))
+}
+
+func TestSortOptionsUnknownOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+sortOptions:
+  order: byName
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"sortOptions.order should be legacy, fifo or preserveFileOrder")
+	}
+}
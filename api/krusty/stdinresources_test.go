@@ -0,0 +1,113 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const pipedResources = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: piped
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: piped
+`
+
+func TestStdinResources(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+resources:
+- cm.yaml
+- "-"
+`)
+	th.WriteF("/app/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	o := th.MakeDefaultOptions()
+	o.StdinResources = strings.NewReader(pipedResources)
+	m := th.Run("/app", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-cm
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: p-piped
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: p-piped
+`)
+	assert.Nil(t, m.GetByIndex(0).GetOrigin())
+	for _, i := range []int{1, 2} {
+		if assert.NotNil(t, m.GetByIndex(i).GetOrigin()) {
+			assert.Equal(t,
+				types.StdinResourcesPath, m.GetByIndex(i).GetOrigin().Path)
+		}
+	}
+}
+
+func TestStdinResourcesNotGiven(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- "-"
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "asks for resources on stdin, but none were given")
+	}
+}
+
+func TestStdinResourcesTwice(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- "-"
+- "-"
+`)
+	o := th.MakeDefaultOptions()
+	o.StdinResources = strings.NewReader(pipedResources)
+	err := th.RunWithErr("/app", o)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "may appear only once")
+	}
+}
+
+func TestStdinResourcesInBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+resources:
+- "-"
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+`)
+	o := th.MakeDefaultOptions()
+	o.StdinResources = strings.NewReader(pipedResources)
+	err := th.RunWithErr("/app/overlay", o)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"is allowed only in the top-level kustomization, not in /app/base/kustomization.yaml")
+	}
+}
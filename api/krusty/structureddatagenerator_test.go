@@ -0,0 +1,71 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestStructuredDataGenerator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/config.toml", `
+[server]
+port = 8080
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        envFrom:
+        - configMapRef:
+            name: settings
+`)
+	th.WriteF("/app/settings.yaml", `
+apiVersion: builtin
+kind: StructuredDataGenerator
+metadata:
+  name: settings
+sources:
+- file: config.toml
+  flatten: true
+  keyPrefix: app_
+`)
+	th.WriteK("/app", `
+namePrefix: prod-
+resources:
+- deployment.yaml
+generators:
+- settings.yaml
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-web
+spec:
+  template:
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: prod-settings-m78g4hhb7h
+        name: web
+---
+apiVersion: v1
+data:
+  app_server.port: "8080"
+kind: ConfigMap
+metadata:
+  name: prod-settings-m78g4hhb7h
+`)
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resid"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeGorillaBaseAndOverlay(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+commonLabels:
+  zoo: sandiego
+resources:
+- gorilla.yaml
+`)
+	th.WriteF("/app/base/gorilla.yaml", `
+kind: Gorilla
+metadata:
+  name: koko
+spec:
+  diet: bambooshoots
+`)
+	th.WriteK("/app/overlay", `
+commonLabels:
+  env: prod
+resources:
+- ../base
+`)
+}
+
+func TestExtraTransformerConfig(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGorillaBaseAndOverlay(th)
+	o := th.MakeDefaultOptions()
+	o.ExtraTransformerConfig = &krusty.TransformerConfig{
+		CommonLabels: types.FsSlice{{
+			Gvk:                resid.Gvk{Kind: "Gorilla"},
+			Path:               "spec/selector",
+			CreateIfNotPresent: true,
+		}},
+	}
+	m := th.Run("/app/overlay", o)
+	th.AssertActualEqualsExpected(m, `
+kind: Gorilla
+metadata:
+  labels:
+    env: prod
+    zoo: sandiego
+  name: koko
+spec:
+  diet: bambooshoots
+  selector:
+    env: prod
+    zoo: sandiego
+`)
+}
+
+func TestReplaceTransformerConfig(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGorillaBaseAndOverlay(th)
+	o := th.MakeDefaultOptions()
+	c := krusty.MakeDefaultTransformerConfig()
+	c.CommonLabels = types.FsSlice{{
+		Path:               "spec/selector",
+		CreateIfNotPresent: true,
+	}}
+	o.ReplaceTransformerConfig = c
+	m := th.Run("/app/overlay", o)
+	th.AssertActualEqualsExpected(m, `
+kind: Gorilla
+metadata:
+  name: koko
+spec:
+  diet: bambooshoots
+  selector:
+    env: prod
+    zoo: sandiego
+`)
+}
+
+func TestExtraTransformerConfigConflict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeGorillaBaseAndOverlay(th)
+	o := th.MakeDefaultOptions()
+	o.ExtraTransformerConfig = &krusty.TransformerConfig{
+		CommonLabels: types.FsSlice{{
+			Path:               "metadata/labels",
+			CreateIfNotPresent: false,
+		}},
+	}
+	err := th.RunWithErr("/app/overlay", o)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "conflicting fieldspecs")
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// UniquenessCheck configures a check, after all
+// transformations, that no two resources in the build
+// output share values that must be unique, e.g. a Service
+// nodePort, which would fail, or misroute traffic, once
+// the output is applied.  Kustomizations can check more
+// rules with a UniquenessValidator in their validators.
+type UniquenessCheck struct {
+	// Rules are the rules to check.  When nil,
+	// resmap.DefaultUniquenessRules are used.
+	Rules []types.UniquenessRule
+
+	// When true, shared values fail the build, rather
+	// than being reported via Kustomizer.Warnings.
+	Strict bool
+}
+
+// checkUniqueness runs the check, returning the
+// warnings it makes, or, if it's strict, an error.
+func (c *UniquenessCheck) checkUniqueness(
+	m resmap.ResMap) ([]string, error) {
+	rules := c.Rules
+	if rules == nil {
+		rules = resmap.DefaultUniquenessRules
+	}
+	violations, err := resmap.FindUniquenessViolations(m, rules)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, v := range violations {
+		problems = append(problems, v.String())
+	}
+	if c.Strict && len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"values that must be unique are shared:\n  %s",
+			strings.Join(problems, "\n  "))
+	}
+	return problems, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeUniquenessApp(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+- ingress.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30080
+`)
+	th.WriteF("/app/base/ingress.yaml", `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+`)
+	th.WriteK("/app", `
+resources:
+- base
+- ./staging
+`)
+	th.WriteK("/app/staging", `
+namespace: staging
+nameSuffix: -staging
+resources:
+- ../base
+`)
+}
+
+func TestUniquenessCheck(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeUniquenessApp(th)
+	opts := th.MakeDefaultOptions()
+	opts.UniquenessCheck = &krusty.UniquenessCheck{}
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err := k.Run("/app")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"rule 'service-node-port': Service/web, Service/web-staging " +
+			"in namespace 'staging' share spec/ports/nodePort=30080",
+		"rule 'ingress-host-path': Ingress/web, Ingress/web-staging " +
+			"in namespace 'staging' share spec/rules/host=example.com, " +
+			"spec/rules/http/paths/path=/",
+	}, k.Warnings())
+
+	opts.UniquenessCheck.Strict = true
+	_, err = krusty.MakeKustomizer(th.GetFSys(), &opts).Run("/app")
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, strings.HasPrefix(err.Error(),
+		"values that must be unique are shared:\n"+
+			"  rule 'service-node-port':"), err.Error())
+
+	// Without the check, the build is silent.
+	opts.UniquenessCheck = nil
+	k = krusty.MakeKustomizer(th.GetFSys(), &opts)
+	_, err = k.Run("/app")
+	assert.NoError(t, err)
+	assert.Empty(t, k.Warnings())
+}
+
+func TestUniquenessValidator(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeUniquenessApp(th)
+	th.WriteK("/app", `
+resources:
+- base
+- ./staging
+validators:
+- |-
+  apiVersion: builtin
+  kind: UniquenessValidator
+  metadata:
+    name: hosts
+  rules:
+  - name: ingress-host
+    kind: Ingress
+    paths:
+    - spec/rules/host
+    scope: cluster
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(),
+		"rule 'ingress-host': Ingress/web, Ingress/web-staging "+
+			"in namespace 'staging' share spec/rules/host=example.com")
+	assert.NotContains(t, err.Error(), "service-node-port")
+}
@@ -2044,7 +2044,7 @@ func TestDeploymentAnnotations(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	th.WriteK(".", `
 configMapGenerator:
-- name: theConfigMap
+- name: the-config-map
   envs:
   - test.properties
 
@@ -2052,7 +2052,7 @@ vars:
 - name: SOMERIVER
   objref:
     kind: ConfigMap
-    name: theConfigMap
+    name: the-config-map
     apiVersion: v1
   fieldref:
     fieldpath: data.waterway
@@ -2100,6 +2100,6 @@ kind: ConfigMap
 metadata:
   annotations:
     river: mississippi
-  name: theConfigMap-hdd8h8cgdt
+  name: the-config-map-hdd8h8cgdt
 `)
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -54,7 +55,8 @@ func (kvl *loader) Load(
 	}
 	all = append(all, pairs...)
 
-	pairs, err = kvl.keyValuesFromFileSources(args.FileSources)
+	pairs, err = kvl.keyValuesFromFileSources(
+		args.FileSources, args.FileOptions)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"file sources: %v", args.FileSources))
@@ -74,22 +76,82 @@ func keyValuesFromLiteralSources(sources []string) ([]types.Pair, error) {
 	return kvs, nil
 }
 
-func (kvl *loader) keyValuesFromFileSources(sources []string) ([]types.Pair, error) {
+// globber is a loader that can expand a file glob,
+// relative to its root, skipping ignored files.
+type globber interface {
+	Glob(pattern string) ([]string, error)
+}
+
+// keyValuesFromFileSources reads the files of the given
+// sources.  With file options, binary files are marked as
+// such, and text files are normalized.
+func (kvl *loader) keyValuesFromFileSources(
+	sources []string, opts *types.FileOptions) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, s := range sources {
-		k, fPath, err := parseFileSource(s)
+		k, fPath, err := ParseFileSource(s)
 		if err != nil {
 			return nil, err
 		}
-		content, err := kvl.ldr.Load(fPath)
+		paths, err := FileSourcePaths(kvl.ldr, s)
 		if err != nil {
 			return nil, err
 		}
-		kvs = append(kvs, types.Pair{Key: k, Value: string(content)})
+		for _, p := range paths {
+			content, err := kvl.ldr.Load(p)
+			if err != nil {
+				return nil, err
+			}
+			if p != fPath {
+				k = filepath.Base(p)
+			}
+			pair := types.Pair{Key: k, Value: string(content)}
+			if opts != nil {
+				if opts.IsBinary(p, content) {
+					pair.Binary = true
+				} else {
+					pair.Value = opts.Normalize(pair.Value)
+				}
+			}
+			kvs = append(kvs, pair)
+		}
 	}
 	return kvs, nil
 }
 
+// FileSourcePaths returns the paths of the files the given
+// file source reads: its path or, if that's a glob, e.g.
+// conf/*.properties, and the loader can expand globs, the
+// matching files, each keyed by its base name.
+func FileSourcePaths(ldr ifc.Loader, source string) ([]string, error) {
+	k, fPath, err := ParseFileSource(source)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := ldr.(globber)
+	if !ok || !IsGlob(fPath) {
+		return []string{fPath}, nil
+	}
+	if k != path.Base(fPath) {
+		return nil, fmt.Errorf(
+			"cannot give the key name %s to the glob %s", k, fPath)
+	}
+	paths, err := g.Glob(fPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match the glob %s", fPath)
+	}
+	return paths, nil
+}
+
+// IsGlob returns true if the given path, e.g. that of a
+// file source, is a glob, i.e. holds any of *, ? or [.
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, `*?[`)
+}
+
 func (kvl *loader) keyValuesFromEnvFiles(paths []string) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, p := range paths {
@@ -179,7 +241,7 @@ func (kvl *loader) keyValuesFromLine(line []byte, currentLine int) (types.Pair,
 //       source-path is the path to the key file.
 //
 // Key names cannot include '='.
-func parseFileSource(source string) (keyName, filePath string, err error) {
+func ParseFileSource(source string) (keyName, filePath string, err error) {
 	numSeparators := strings.Count(source, "=")
 	switch {
 	case numSeparators == 0:
@@ -68,6 +68,7 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 	tests := []struct {
 		description string
 		sources     []string
+		options     *types.FileOptions
 		expected    []types.Pair
 	}{
 		{
@@ -80,13 +81,59 @@ func TestKeyValuesFromFileSources(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "normalize text files",
+			sources:     []string{"files/app-init.ini", "files/crlf.conf"},
+			options: &types.FileOptions{
+				NewlineNormalization:  types.LfNewlineNormalization,
+				EnsureTrailingNewline: types.EnsureTrailingNewline,
+			},
+			expected: []types.Pair{
+				{Key: "app-init.ini", Value: "FOO=bar\n"},
+				{Key: "crlf.conf", Value: "a=1\nb=2\n"},
+			},
+		},
+		{
+			description: "remove trailing newlines",
+			sources:     []string{"files/crlf.conf"},
+			options: &types.FileOptions{
+				EnsureTrailingNewline: types.RemoveTrailingNewline,
+			},
+			expected: []types.Pair{
+				{Key: "crlf.conf", Value: "a=1\r\nb=2"},
+			},
+		},
+		{
+			description: "leave binary files as they are",
+			sources:     []string{"files/logo.png", "files/blob.txt", "files/crlf.conf"},
+			options: &types.FileOptions{
+				NewlineNormalization: types.LfNewlineNormalization,
+				BinaryFiles:          []string{"files/*.txt"},
+			},
+			expected: []types.Pair{
+				{Key: "logo.png", Value: "PNG\r\n\x00", Binary: true},
+				{Key: "blob.txt", Value: "a\r\n", Binary: true},
+				{Key: "crlf.conf", Value: "a=1\nb=2\n"},
+			},
+		},
+		{
+			description: "no options",
+			sources:     []string{"files/logo.png", "files/crlf.conf"},
+			expected: []types.Pair{
+				{Key: "logo.png", Value: "PNG\r\n\x00"},
+				{Key: "crlf.conf", Value: "a=1\r\nb=2\r\n"},
+			},
+		},
 	}
 
 	fSys := filesys.MakeFsInMemory()
 	fSys.WriteFile("/files/app-init.ini", []byte("FOO=bar"))
+	fSys.WriteFile("/files/crlf.conf", []byte("a=1\r\nb=2\r\n"))
+	fSys.WriteFile("/files/logo.png", []byte("PNG\r\n\x00"))
+	fSys.WriteFile("/files/blob.txt", []byte("a\r\n"))
 	kvl := makeKvLoader(fSys)
 	for _, tc := range tests {
-		kvs, err := kvl.keyValuesFromFileSources(tc.sources)
+		kvs, err := kvl.keyValuesFromFileSources(tc.sources, tc.options)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
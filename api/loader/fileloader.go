@@ -5,6 +5,7 @@ package loader
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -15,6 +16,8 @@ import (
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 // fileLoader is a kustomization's interface to files.
@@ -104,6 +107,14 @@ type fileLoader struct {
 
 	// Used to clean up, as needed.
 	cleaner func() error
+
+	// Bounds what Load will read; only
+	// MaxFileSize applies here.
+	limits types.InputLimits
+
+	// Patterns of files to ignore, set from
+	// the kustomization; see SetIgnorePatterns.
+	ignorePatterns *ignoreRules
 }
 
 // NewFileLoaderAtCwd returns a loader that loads from PWD.
@@ -172,9 +183,29 @@ func demandDirectoryRoot(
 	return d, nil
 }
 
+// SetInputLimits sets the limits on what the given loader,
+// and the loaders it makes via New, will Load.  The zero
+// value, the initial one, means the defaults; see
+// types.InputLimits.  Loaders not from this package are
+// left as they are.
+func SetInputLimits(ldr ifc.Loader, l types.InputLimits) {
+	if fl, ok := ldr.(*fileLoader); ok {
+		fl.limits = l
+	}
+}
+
 // New returns a new Loader, rooted relative to current loader,
 // or rooted in a temp directory holding a git repo clone.
 func (fl *fileLoader) New(path string) (ifc.Loader, error) {
+	ldr, err := fl.newChild(path)
+	if err != nil {
+		return nil, err
+	}
+	SetInputLimits(ldr, fl.limits)
+	return ldr, nil
+}
+
+func (fl *fileLoader) newChild(path string) (ifc.Loader, error) {
 	if path == "" {
 		return nil, fmt.Errorf("new root cannot be empty")
 	}
@@ -329,11 +360,7 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 			return nil, err
 		}
 		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		return body, nil
+		return fl.readLimited(resp.Body, path)
 	}
 
 	if !filepath.IsAbs(path) {
@@ -343,9 +370,61 @@ func (fl *fileLoader) Load(path string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := fl.errIfTooBig(path); err != nil {
+		return nil, err
+	}
 	return fl.fSys.ReadFile(path)
 }
 
+// errIfTooBig returns a kusterr.LimitError if the file at
+// the given path is bigger than the MaxFileSize limit, so
+// that it's never read.
+func (fl *fileLoader) errIfTooBig(path string) error {
+	max := fl.limits.Effective().MaxFileSize
+	if max < 0 {
+		return nil
+	}
+	f, err := fl.fSys.Open(path)
+	if err != nil {
+		// Let ReadFile report it.
+		return nil
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() || fi.Size() <= max {
+		return nil
+	}
+	return kusterr.LimitError{
+		Limit:    "maxFileSize",
+		Source:   path,
+		Observed: fi.Size(),
+		Max:      max,
+	}
+}
+
+// readLimited reads r, returning a kusterr.LimitError
+// if it holds more than the MaxFileSize limit allows.
+func (fl *fileLoader) readLimited(r io.Reader, path string) ([]byte, error) {
+	max := fl.limits.Effective().MaxFileSize
+	if max < 0 {
+		return ioutil.ReadAll(r)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, kusterr.LimitError{
+			Limit:    "maxFileSize",
+			Source:   path,
+			Observed: int64(len(body)),
+			AtLeast:  true,
+			Max:      max,
+		}
+	}
+	return body, nil
+}
+
 // Cleanup runs the cleaner.
 func (fl *fileLoader) Cleanup() error {
 	return fl.cleaner()
@@ -17,7 +17,9 @@ import (
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 type testData struct {
@@ -118,6 +120,29 @@ func TestLoaderNewSubDir(t *testing.T) {
 	}
 }
 
+func TestLoaderInputLimits(t *testing.T) {
+	l1 := makeLoader()
+	SetInputLimits(l1, types.InputLimits{MaxFileSize: 5})
+	l2, err := l1.New("foo/project")
+	if err != nil {
+		t.Fatalf("unexpected err: %v\n", err)
+	}
+	_, err = l2.Load("fileA.yaml")
+	expected := kusterr.LimitError{
+		Limit:    "maxFileSize",
+		Source:   "/foo/project/fileA.yaml",
+		Observed: int64(len(testCases[0].expectedContent)),
+		Max:      5,
+	}
+	if err != expected {
+		t.Fatalf("expected %v, but got %v", expected, err)
+	}
+	SetInputLimits(l2, types.InputLimits{MaxFileSize: -1})
+	if _, err = l2.Load("fileA.yaml"); err != nil {
+		t.Fatalf("unexpected load error %v", err)
+	}
+}
+
 func TestLoaderBadRelative(t *testing.T) {
 	l1, err := makeLoader().New("foo/project/subdir1")
 	if err != nil {
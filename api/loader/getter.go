@@ -91,11 +91,27 @@ func getRemoteTarget(rs *remoteTargetSpec) error {
 			new(getter.GitDetector),
 			new(getter.BitBucketDetector),
 		},
+		Getters: newGetters(),
 		Options: opts,
 	}
 	return utils.TimedCall("go-getter client.Get", 21*time.Second, client.Get)
 }
 
+// newGetters returns getters for the protocols of the
+// default getter.Getters.  A client configures its getters,
+// so clients sharing the default ones, e.g. those of builds
+// running at once, would race.
+func newGetters() map[string]getter.Getter {
+	httpGetter := &getter.HttpGetter{Netrc: true}
+	return map[string]getter.Getter{
+		"file":  new(getter.FileGetter),
+		"git":   new(getter.GitGetter),
+		"hg":    new(getter.HgGetter),
+		"http":  httpGetter,
+		"https": httpGetter,
+	}
+}
+
 func getNothing(rs *remoteTargetSpec) error {
 	var err error
 	rs.Dir, err = filesys.NewTmpConfirmedDir()
@@ -0,0 +1,302 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/kyaml/ext"
+)
+
+// ignorePattern is a line of an ignore file, in gitignore
+// syntax, compiled to match slash separated paths relative
+// to the directory of the file.
+type ignorePattern struct {
+	// negate, for a pattern starting with '!',
+	// includes again what an earlier one ignored.
+	negate bool
+
+	// dirOnly, for a pattern ending with '/',
+	// matches only directories.
+	dirOnly bool
+
+	re *regexp.Regexp
+}
+
+// ignoreRules are the patterns of an ignore
+// file, or of a kustomization's ignorePatterns.
+type ignoreRules struct {
+	// dir is the absolute path of the
+	// directory the patterns are relative to.
+	dir      string
+	patterns []ignorePattern
+}
+
+// parseIgnoreRules compiles the given lines, in
+// gitignore syntax; blank lines and comments are skipped.
+func parseIgnoreRules(dir string, lines []string) (*ignoreRules, error) {
+	rules := &ignoreRules{dir: dir}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, err := parseIgnorePattern(line)
+		if err != nil {
+			return nil, err
+		}
+		rules.patterns = append(rules.patterns, p)
+	}
+	return rules, nil
+}
+
+func parseIgnorePattern(line string) (ignorePattern, error) {
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A pattern with a slash, other than a trailing one,
+	// is anchored to the directory; otherwise it matches
+	// at any depth below it.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return p, fmt.Errorf("empty ignore pattern")
+	}
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return p, fmt.Errorf("bad ignore pattern '%s': %v", line, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// globToRegexp converts a gitignore glob to a regular
+// expression; "**" matches any number of directories.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			b.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			j := strings.IndexByte(glob[i:], ']')
+			if j < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// match reports whether the last of the patterns matching
+// the given slash separated path, relative to the rules'
+// directory, ignores it, and whether any pattern matched.
+func (r *ignoreRules) match(rel string, isDir bool) (ignored, matched bool) {
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			ignored, matched = !p.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// SetIgnorePatterns sets patterns, in the syntax of an
+// ignore file, that the given loader applies as though they
+// ended the ignore file in its root; see IsIgnored.  Unlike
+// the input limits, they don't pass to the loaders it makes
+// via New.  Loaders not from this package are left as they are.
+func SetIgnorePatterns(ldr ifc.Loader, patterns []string) error {
+	fl, ok := ldr.(*fileLoader)
+	if !ok {
+		return nil
+	}
+	rules, err := parseIgnoreRules(fl.root.String(), patterns)
+	if err != nil {
+		return err
+	}
+	fl.ignorePatterns = rules
+	return nil
+}
+
+// ignoreMatcher decides which paths below a loader's root
+// are ignored, reading each directory's ignore file once.
+type ignoreMatcher struct {
+	fl    *fileLoader
+	files map[string]*ignoreRules
+}
+
+func (fl *fileLoader) newIgnoreMatcher() *ignoreMatcher {
+	return &ignoreMatcher{fl: fl, files: make(map[string]*ignoreRules)}
+}
+
+// IsIgnored reports whether the file or directory at the
+// given path, relative to the loader's root or absolute,
+// is ignored.  See ext.IgnoreFileName for the name of the
+// ignore files, which are in gitignore syntax.  An ignore
+// file in the root or in any directory below it applies to
+// the paths below its directory, and the patterns of a
+// deeper file, where they match, take precedence.  As in
+// git, nothing in an ignored directory can be included
+// again.  Paths outside the root are never ignored.
+func (fl *fileLoader) IsIgnored(path string, isDir bool) (bool, error) {
+	return fl.newIgnoreMatcher().isIgnored(path, isDir)
+}
+
+func (m *ignoreMatcher) isIgnored(path string, isDir bool) (bool, error) {
+	root := m.fl.root.String()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false, nil
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		ignored, err := m.decide(parts[:i+1], isDir || i < len(parts)-1)
+		if err != nil || ignored {
+			return ignored, err
+		}
+	}
+	return false, nil
+}
+
+// decide applies the rules of the directories from the root
+// down to the parent of the path given by parts, in order.
+func (m *ignoreMatcher) decide(parts []string, isDir bool) (bool, error) {
+	ignored := false
+	dir := m.fl.root.String()
+	for i := 0; i < len(parts); i++ {
+		if i > 0 {
+			dir = filepath.Join(dir, parts[i-1])
+		}
+		rel := strings.Join(parts[i:], "/")
+		rulesList, err := m.rulesIn(dir)
+		if err != nil {
+			return false, err
+		}
+		for _, rules := range rulesList {
+			if x, matched := rules.match(rel, isDir); matched {
+				ignored = x
+			}
+		}
+	}
+	return ignored, nil
+}
+
+// rulesIn returns the rules that apply at the given
+// directory: its ignore file, if any, and the loader's
+// ignorePatterns if it's the root.
+func (m *ignoreMatcher) rulesIn(dir string) ([]*ignoreRules, error) {
+	rules, found := m.files[dir]
+	if !found {
+		path := filepath.Join(dir, ext.IgnoreFileName())
+		if m.fl.fSys.Exists(path) && !m.fl.fSys.IsDir(path) {
+			content, err := m.fl.fSys.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			rules, err = parseIgnoreRules(
+				dir, strings.Split(string(content), "\n"))
+			if err != nil {
+				return nil, fmt.Errorf("in '%s': %v", path, err)
+			}
+		}
+		m.files[dir] = rules
+	}
+	var result []*ignoreRules
+	if rules != nil {
+		result = append(result, rules)
+	}
+	if dir == m.fl.root.String() && m.fl.ignorePatterns != nil {
+		result = append(result, m.fl.ignorePatterns)
+	}
+	return result, nil
+}
+
+// Glob returns the paths, relative to the root and in
+// lexical order, of the files matching the given pattern,
+// which is relative to the root, except for those that are
+// ignored, and the ignore files themselves; see IsIgnored.
+// Directories aren't returned, and
+// it's an error if a match violates the load restrictions.
+func (fl *fileLoader) Glob(pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(fl.root.String(), pattern)
+	}
+	matches, err := fl.fSys.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	m := fl.newIgnoreMatcher()
+	var result []string
+	for _, path := range matches {
+		if fl.fSys.IsDir(path) ||
+			filepath.Base(path) == ext.IgnoreFileName() {
+			continue
+		}
+		path, err = fl.loadRestrictor(fl.fSys, fl.root, path)
+		if err != nil {
+			return nil, err
+		}
+		ignored, err := m.isIgnored(path, false)
+		if err != nil {
+			return nil, err
+		}
+		if ignored {
+			continue
+		}
+		rel, err := filepath.Rel(fl.root.String(), path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rel)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+func makeIgnoreTestLoader(t *testing.T) *fileLoader {
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"/app/.krmignore": `
+# Docs and backups.
+*.md
+*.bak
+!KEEP.md
+build/
+`,
+		"/other/b.yaml":                   "x",
+		"/app/a.yaml":                     "x",
+		"/app/a.yaml.bak":                 "x",
+		"/app/README.md":                  "x",
+		"/app/KEEP.md":                    "x",
+		"/app/build/out.yaml":             "x",
+		"/app/conf/.krmignore":            "!NOTES.md\nlocal.properties\n",
+		"/app/conf/NOTES.md":              "x",
+		"/app/conf/OTHER.md":              "x",
+		"/app/conf/app.properties":        "x",
+		"/app/conf/local.properties":      "x",
+		"/app/conf/deep/local.properties": "x",
+		"/app/conf/deep/x.properties":     "x",
+	} {
+		assert.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+	return newLoaderOrDie(RestrictionRootOnly, fSys, "/app")
+}
+
+func TestIsIgnored(t *testing.T) {
+	l := makeIgnoreTestLoader(t)
+	for path, expected := range map[string]bool{
+		"a.yaml":                     false,
+		"a.yaml.bak":                 true,
+		"README.md":                  true,
+		"KEEP.md":                    false,
+		"build":                      true,
+		"build/out.yaml":             true,
+		"conf/NOTES.md":              false,
+		"conf/OTHER.md":              true,
+		"conf/app.properties":        false,
+		"conf/local.properties":      true,
+		"conf/deep/local.properties": true,
+		"conf/deep/x.properties":     false,
+		"/app/README.md":             true,
+		"/elsewhere/README.md":       false,
+	} {
+		ignored, err := l.IsIgnored(path, path == "build")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	l := makeIgnoreTestLoader(t)
+	assert.NoError(t, SetIgnorePatterns(l, []string{
+		"/a.yaml", "!README.md", "**/x.properties"}))
+	for path, expected := range map[string]bool{
+		"a.yaml":                 true,
+		"README.md":              false,
+		"conf/OTHER.md":          true,
+		"conf/deep/x.properties": true,
+	} {
+		ignored, err := l.IsIgnored(path, false)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+
+	// They don't pass to child loaders.
+	child, err := l.New("conf")
+	assert.NoError(t, err)
+	ignored, err := child.(*fileLoader).IsIgnored("deep/x.properties", false)
+	assert.NoError(t, err)
+	assert.False(t, ignored)
+
+	err = SetIgnorePatterns(l, []string{"/"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "empty ignore pattern")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	l := makeIgnoreTestLoader(t)
+	paths, err := l.Glob("*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"KEEP.md", "a.yaml"}, paths)
+
+	paths, err = l.Glob("conf/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"conf/NOTES.md", "conf/app.properties"}, paths)
+
+	_, err = l.Glob("../other/*")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is not in or below")
+	}
+}
+
+func TestFindFilesSkipsIgnored(t *testing.T) {
+	l := makeIgnoreTestLoader(t)
+	paths, err := l.FindFiles(".properties", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"conf/app.properties", "conf/deep/x.properties"}, paths)
+	paths, err = l.FindFiles(".yaml", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.yaml"}, paths)
+}
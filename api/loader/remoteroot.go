@@ -0,0 +1,102 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+)
+
+// RemoteRoot describes the remote repository, and the
+// directory in it, that a loader's root was fetched from.
+type RemoteRoot struct {
+	// Repo is the url of the repository.
+	Repo string
+
+	// Ref is the branch, tag or commit, or empty
+	// for the repository's default branch.
+	Ref string
+
+	// Path is the path of the root in the repository,
+	// empty for the top of the repository.
+	Path string
+}
+
+// RemoteRoot returns where the loader's root was fetched
+// from, or false if the root is local.
+func (fl *fileLoader) RemoteRoot() (RemoteRoot, bool) {
+	repoSpec := fl.repoSpec
+	if repoSpec == nil && fl.rscSpec != nil {
+		rs, err := git.NewRepoSpecFromUrl(fl.rscSpec.Raw)
+		if err != nil {
+			// Not a git url, e.g. an archive over http.
+			return RemoteRoot{Repo: fl.rscSpec.Raw}, true
+		}
+		repoSpec = rs
+	}
+	if repoSpec == nil {
+		return RemoteRoot{}, false
+	}
+	return RemoteRoot{
+		Repo: repoSpec.CloneSpec(),
+		Ref:  repoSpec.Ref,
+		Path: strings.Trim(repoSpec.Path, "/"),
+	}, true
+}
+
+// FindFiles returns the paths, relative to the root and in
+// lexical order, of the files with the given suffix in the
+// root or, if recursive, anywhere below it.  Directories
+// whose names start with a dot, e.g. .git, are skipped, as
+// are the files and directories that are ignored; see
+// IsIgnored.
+func (fl *fileLoader) FindFiles(
+	suffix string, recursive bool) ([]string, error) {
+	var result []string
+	m := fl.newIgnoreMatcher()
+	err := fl.fSys.Walk(fl.root.String(),
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path == fl.root.String() {
+					return nil
+				}
+				if !recursive || strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				ignored, err := m.isIgnored(path, true)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(info.Name(), suffix) {
+				return nil
+			}
+			ignored, err := m.isIgnored(path, false)
+			if err != nil || ignored {
+				return err
+			}
+			rel, err := filepath.Rel(fl.root.String(), path)
+			if err != nil {
+				return err
+			}
+			result = append(result, rel)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+)
+
+func TestRemoteRootAndFindFiles(t *testing.T) {
+	coRoot := "/tmp"
+	fSys := filesys.MakeFsInMemory()
+	for _, f := range []string{
+		"manifests/b.yaml",
+		"manifests/a.yaml",
+		"manifests/README.md",
+		"manifests/crds/c.yaml",
+		"manifests/.hidden/d.yaml",
+	} {
+		assert.NoError(t, fSys.WriteFile(coRoot+"/"+f, []byte("x")))
+	}
+	repoSpec, err := git.NewRepoSpecFromUrl(
+		"github.com/someOrg/someRepo//manifests?ref=v1.2.3")
+	assert.NoError(t, err)
+	l, err := newLoaderAtGitClone(
+		repoSpec, fSys, nil,
+		git.DoNothingCloner(filesys.ConfirmedDir(coRoot)), getNothing)
+	assert.NoError(t, err)
+
+	root, ok := l.(*fileLoader).RemoteRoot()
+	assert.True(t, ok)
+	assert.Equal(t, RemoteRoot{
+		Repo: "https://github.com/someOrg/someRepo.git",
+		Ref:  "v1.2.3",
+		Path: "manifests",
+	}, root)
+
+	files, err := l.(*fileLoader).FindFiles(".yaml", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.yaml", "b.yaml"}, files)
+	files, err = l.(*fileLoader).FindFiles(".yaml", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.yaml", "b.yaml", "crds/c.yaml"}, files)
+
+	// Local roots have no remote root.
+	_, ok = NewFileLoaderAtRoot(fSys).RemoteRoot()
+	assert.False(t, ok)
+}
@@ -104,11 +104,73 @@ func (x Gvk) StringWoEmptyField() string {
 	return strings.Join(s, fieldSep)
 }
 
-// Equals returns true if the Gvk's have equal fields.
+// LegacyCoreGroup is a name sometimes used for the core
+// API group, whose actual name is the empty string, e.g.
+// apiVersion "v1" rather than "core/v1".
+const LegacyCoreGroup = "core"
+
+// NormalizeGroup returns the canonical name of a group,
+// mapping LegacyCoreGroup to the empty string.
+func NormalizeGroup(g string) string {
+	if g == LegacyCoreGroup {
+		return ""
+	}
+	return g
+}
+
+// Normalized returns a copy of x with a canonical group.
+func (x Gvk) Normalized() Gvk {
+	x.Group = NormalizeGroup(x.Group)
+	return x
+}
+
+// Equals returns true if the Gvk's have equal fields,
+// after normalization, so "core/v1" equals "v1".
 func (x Gvk) Equals(o Gvk) bool {
+	return x.Normalized().ExactlyEquals(o.Normalized())
+}
+
+// ExactlyEquals returns true if the Gvk's have equal fields,
+// without normalization.
+func (x Gvk) ExactlyEquals(o Gvk) bool {
 	return x.Group == o.Group && x.Version == o.Version && x.Kind == o.Kind
 }
 
+// GvkMatchOptions tunes how a selector matches a Gvk.
+// The zero value gives the default behavior.
+type GvkMatchOptions struct {
+	// Strict compares groups exactly, without normalization,
+	// so a selector for group "core" doesn't select a "v1".
+	Strict bool
+
+	// CaseInsensitiveKind ignores case when comparing kinds,
+	// so a selector for "deployment" selects a "Deployment".
+	CaseInsensitiveKind bool
+
+	// ExactGvk compares group, version and kind exactly, as
+	// plain strings.  Empty selector fields are no longer
+	// wildcards, e.g. an empty group selects only the core
+	// group, and groups aren't normalized.  It overrides
+	// the other options.
+	ExactGvk bool
+}
+
+// GroupMatches returns true if group g matches selector group sg.
+func (o GvkMatchOptions) GroupMatches(g, sg string) bool {
+	if o.Strict {
+		return g == sg
+	}
+	return NormalizeGroup(g) == NormalizeGroup(sg)
+}
+
+// KindMatches returns true if kind k matches selector kind sk.
+func (o GvkMatchOptions) KindMatches(k, sk string) bool {
+	if o.CaseInsensitiveKind {
+		return strings.EqualFold(k, sk)
+	}
+	return k == sk
+}
+
 // An attempt to order things to help k8s, e.g.
 // a Service should come before things that refer to it.
 // Namespace should be first.
@@ -152,6 +214,14 @@ var typeOrders = func() map[string]int {
 	return m
 }()
 
+// OrderRank returns the rank of the kind in the order that
+// IsLessThan uses: negative for kinds that come first, e.g.
+// Namespace, positive for those that come last, e.g.
+// ValidatingWebhookConfiguration, and zero for the rest.
+func (x Gvk) OrderRank() int {
+	return typeOrders[x.Kind]
+}
+
 // IsLessThan returns true if self is less than the argument.
 func (x Gvk) IsLessThan(o Gvk) bool {
 	indexI := typeOrders[x.Kind]
@@ -177,12 +247,25 @@ func (x Gvk) IsLessThan(o Gvk) bool {
 // but rejected by
 //       <Group: "apps",       Version: "",        Kind: "Deployment">
 //
+// Groups are normalized, so a selector with group "core" selects
+// a "v1" item.  See IsSelectedBy.
 func (x Gvk) IsSelected(selector *Gvk) bool {
 	if selector == nil {
 		return true
 	}
+	return x.IsSelectedBy(*selector, GvkMatchOptions{})
+}
+
+// IsSelectedBy returns true if `selector` selects `x` under
+// the given options.  Unless the options ask for ExactGvk,
+// empty selector fields are wildcards, so a selector must
+// use LegacyCoreGroup to select only the core group.
+func (x Gvk) IsSelectedBy(selector Gvk, opts GvkMatchOptions) bool {
+	if opts.ExactGvk {
+		return x.ExactlyEquals(selector)
+	}
 	if len(selector.Group) > 0 {
-		if x.Group != selector.Group {
+		if !opts.GroupMatches(x.Group, selector.Group) {
 			return false
 		}
 	}
@@ -192,7 +275,7 @@ func (x Gvk) IsSelected(selector *Gvk) bool {
 		}
 	}
 	if len(selector.Kind) > 0 {
-		if x.Kind != selector.Kind {
+		if !opts.KindMatches(x.Kind, selector.Kind) {
 			return false
 		}
 	}
@@ -216,6 +299,17 @@ func (x Gvk) toKyamlTypeMeta() yaml.TypeMeta {
 // IsNamespaceableKind returns true if x is a namespaceable Gvk
 // Implements https://kubernetes.io/docs/concepts/overview/working-with-objects/namespaces/#not-all-objects-are-in-a-namespace
 func (x Gvk) IsNamespaceableKind() bool {
-	isNamespaceScoped, found := openapi.IsNamespaceScoped(x.toKyamlTypeMeta())
+	return x.IsNamespaceableKindIn(nil)
+}
+
+// IsNamespaceableKindIn is IsNamespaceableKind, looking the
+// Gvk up in the given schemas, or the global ones if nil.
+func (x Gvk) IsNamespaceableKindIn(schemas *openapi.Schemas) bool {
+	var isNamespaceScoped, found bool
+	if schemas == nil {
+		isNamespaceScoped, found = openapi.IsNamespaceScoped(x.toKyamlTypeMeta())
+	} else {
+		isNamespaceScoped, found = schemas.IsNamespaceScoped(x.toKyamlTypeMeta())
+	}
 	return !found || isNamespaceScoped
 }
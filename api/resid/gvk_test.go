@@ -253,6 +253,73 @@ func TestSelectByGVK(t *testing.T) {
 	}
 }
 
+func TestEqualsNormalizesGroup(t *testing.T) {
+	core := Gvk{Group: "core", Version: "v1", Kind: "ConfigMap"}
+	empty := Gvk{Version: "v1", Kind: "ConfigMap"}
+	assert.True(t, core.Equals(empty))
+	assert.True(t, empty.Equals(core))
+	assert.False(t, core.ExactlyEquals(empty))
+	assert.Equal(t, empty, core.Normalized())
+	assert.False(t, empty.Equals(
+		Gvk{Group: "apps", Version: "v1", Kind: "ConfigMap"}))
+}
+
+// TestIsSelectedByMatrix pins down how selectors match
+// across groups, versions and kinds, by option.
+func TestIsSelectedByMatrix(t *testing.T) {
+	var (
+		appsDeploy       = Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}
+		extensionsDeploy = Gvk{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}
+		v1Service        = Gvk{Version: "v1", Kind: "Service"}
+		coreService      = Gvk{Group: "core", Version: "v1", Kind: "Service"}
+	)
+	var (
+		lenient         = GvkMatchOptions{}
+		strict          = GvkMatchOptions{Strict: true}
+		caseInsensitive = GvkMatchOptions{CaseInsensitiveKind: true}
+		exact           = GvkMatchOptions{ExactGvk: true}
+	)
+	testCases := []struct {
+		in       Gvk
+		selector Gvk
+		opts     GvkMatchOptions
+		expected bool
+	}{
+		// apps/v1 vs extensions/v1beta1
+		{appsDeploy, Gvk{Kind: "Deployment"}, lenient, true},
+		{extensionsDeploy, Gvk{Kind: "Deployment"}, lenient, true},
+		{appsDeploy, Gvk{Group: "apps", Kind: "Deployment"}, lenient, true},
+		{extensionsDeploy, Gvk{Group: "apps", Kind: "Deployment"}, lenient, false},
+		{extensionsDeploy, Gvk{Group: "extensions", Kind: "Deployment"}, strict, true},
+		// version wildcarding
+		{appsDeploy, Gvk{Version: "v1"}, lenient, true},
+		{extensionsDeploy, Gvk{Version: "v1"}, lenient, false},
+		{extensionsDeploy, Gvk{Group: "extensions"}, lenient, true},
+		// core vs empty group
+		{v1Service, Gvk{Group: "core", Kind: "Service"}, lenient, true},
+		{v1Service, Gvk{Group: "core", Kind: "Service"}, strict, false},
+		{coreService, Gvk{Group: "core", Kind: "Service"}, strict, true},
+		{coreService, Gvk{Kind: "Service"}, lenient, true},
+		{coreService, Gvk{Version: "v1", Kind: "Service"}, strict, true},
+		{appsDeploy, Gvk{Group: "core"}, lenient, false},
+		// kind case
+		{appsDeploy, Gvk{Kind: "deployment"}, lenient, false},
+		{appsDeploy, Gvk{Kind: "deployment"}, caseInsensitive, true},
+		{appsDeploy, Gvk{Kind: "deploy"}, caseInsensitive, false},
+		// exact gvk
+		{appsDeploy, appsDeploy, exact, true},
+		{appsDeploy, Gvk{Group: "apps", Kind: "Deployment"}, exact, false},
+		{appsDeploy, Gvk{Group: "apps", Version: "v1beta2", Kind: "Deployment"}, exact, false},
+		{v1Service, Gvk{Version: "v1", Kind: "Service"}, exact, true},
+		{v1Service, Gvk{Group: "core", Version: "v1", Kind: "Service"}, exact, false},
+		{coreService, Gvk{Version: "v1", Kind: "Service"}, exact, false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.in.IsSelectedBy(tc.selector, tc.opts),
+			"%s selected by %s with %+v", tc.in, tc.selector, tc.opts)
+	}
+}
+
 func TestIsNamespaceableKind(t *testing.T) {
 	testCases := []struct {
 		name     string
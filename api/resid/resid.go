@@ -115,6 +115,8 @@ func (id ResId) isPutativelyDefaultNs() bool {
 
 // EffectiveNamespace returns a non-ambiguous, non-empty
 // namespace for use in reporting and equality tests.
+// Whether the kind is namespaced is per the global OpenAPI
+// definitions; see resource.Resource.IsNamespaceableKind.
 func (id ResId) EffectiveNamespace() string {
 	// The order of these checks matters.
 	if !id.IsNamespaceableKind() {
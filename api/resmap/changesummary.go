@@ -0,0 +1,120 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// MaxSampleIds is the most ids a ChangeSummary samples.
+const MaxSampleIds = 5
+
+// ChangeSummary is a compact account of what one run of a
+// transformer changed, e.g. for a UI to show that an
+// overlay adds the labels app and tier to 14 resources
+// without diffing the output.
+type ChangeSummary struct {
+	// Transformer is the kind of the transformer,
+	// e.g. LabelTransformer.
+	Transformer string `json:"transformer" yaml:"transformer"`
+
+	// KeysAdded are the sorted keys the run gave a value
+	// on a resource lacking one, e.g. label keys, or
+	// "namespace" for the NamespaceTransformer.
+	KeysAdded []string `json:"keysAdded,omitempty" yaml:"keysAdded,omitempty"`
+
+	// KeysModified are the sorted keys whose value the run
+	// changed on a resource that had one.  A key added to
+	// some resources and changed on others is in both.
+	KeysModified []string `json:"keysModified,omitempty" yaml:"keysModified,omitempty"`
+
+	// Resources is the number of resources the run changed.
+	Resources int `json:"resources" yaml:"resources"`
+
+	// SampleIds are the ids, as of after the run, of the
+	// first resources it changed, at most MaxSampleIds.
+	SampleIds []resid.ResId `json:"sampleIds,omitempty" yaml:"sampleIds,omitempty"`
+}
+
+// ChangeSummaries records a ChangeSummary of each run of
+// the transformers that summarize their changes, in the
+// order they ran.
+type ChangeSummaries struct {
+	summaries []ChangeSummary
+}
+
+// NewChangeSummaries returns an empty ChangeSummaries.
+func NewChangeSummaries() *ChangeSummaries {
+	return &ChangeSummaries{}
+}
+
+// Summarize calls apply, which is expected to change the
+// given resources, and records how it changed the values
+// that keys maps each resource's keys to, e.g. its labels.
+// On nil summaries, it just calls apply, so callers
+// needn't check whether changes are summarized.
+func (s *ChangeSummaries) Summarize(
+	transformer string, resources []*resource.Resource,
+	keys func(*resource.Resource) map[string]string,
+	apply func() error) error {
+	if s == nil {
+		return apply()
+	}
+	before := make([]map[string]string, len(resources))
+	for i, r := range resources {
+		before[i] = keys(r)
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	added := make(map[string]bool)
+	modified := make(map[string]bool)
+	summary := ChangeSummary{Transformer: transformer}
+	for i, r := range resources {
+		changed := false
+		for k, v := range keys(r) {
+			old, found := before[i][k]
+			switch {
+			case !found:
+				added[k] = true
+			case old != v:
+				modified[k] = true
+			default:
+				continue
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		summary.Resources++
+		if len(summary.SampleIds) < MaxSampleIds {
+			summary.SampleIds = append(summary.SampleIds, r.CurId())
+		}
+	}
+	summary.KeysAdded = sortedSet(added)
+	summary.KeysModified = sortedSet(modified)
+	s.summaries = append(s.summaries, summary)
+	return nil
+}
+
+// List returns the summaries in the order recorded.
+func (s *ChangeSummaries) List() []ChangeSummary {
+	if s == nil {
+		return nil
+	}
+	return s.summaries
+}
+
+func sortedSet(m map[string]bool) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
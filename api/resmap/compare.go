@@ -0,0 +1,210 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// CompareOptions shape the errors of CompareSets and
+// CompareLists, which list the fields that differ rather
+// than printing whole resources.
+type CompareOptions struct {
+	// MaxFields is the most differing fields listed for a
+	// pair of resources; the others are only counted.  If
+	// it's not positive, all are listed.
+	MaxFields int
+
+	// MaxValueLength is the most characters of a value
+	// shown; a longer one is cut short.  If it's not
+	// positive, values are shown whole.
+	MaxValueLength int
+}
+
+// DefaultCompareOptions are the options of the
+// ErrorIfNotEqualSets and ErrorIfNotEqualLists methods.
+var DefaultCompareOptions = CompareOptions{
+	MaxFields:      10,
+	MaxValueLength: 80,
+}
+
+// CompareSets returns an error if the ResMaps don't have
+// the same resources, in any order, as ErrorIfNotEqualSets.
+// It lists the ids of the resources on only one side, then,
+// for each resource on both that differs, the paths of the
+// fields that differ with their values, self's first.
+func CompareSets(m, other ResMap, opts CompareOptions) error {
+	var selfOnly, ambiguous []string
+	var pairs []string
+	matched := make(map[*resource.Resource]bool)
+	for _, r1 := range m.Resources() {
+		id := r1.CurId()
+		others := other.GetMatchingResourcesByCurrentId(id.Equals)
+		switch len(others) {
+		case 0:
+			selfOnly = append(selfOnly, id.String())
+			continue
+		case 1:
+		default:
+			ambiguous = append(ambiguous, fmt.Sprintf(
+				"%s matches %d resources of other", id, len(others)))
+			continue
+		}
+		r2 := others[0]
+		matched[r2] = true
+		if !r1.KunstructEqual(r2) {
+			pairs = append(pairs,
+				describeDifference(id.String(), r1, r2, opts))
+		}
+	}
+	var otherOnly []string
+	for _, r2 := range other.Resources() {
+		if !matched[r2] {
+			otherOnly = append(otherOnly, r2.CurId().String())
+		}
+	}
+	if len(m.Resources()) != len(other.Resources()) &&
+		len(selfOnly)+len(otherOnly)+len(ambiguous) == 0 {
+		// Not expected, as a ResMap's ids are unique.
+		ambiguous = append(ambiguous, fmt.Sprintf(
+			"self has %d resources, other %d", m.Size(), other.Size()))
+	}
+	return compareError(selfOnly, otherOnly, ambiguous, pairs)
+}
+
+// CompareLists returns an error if the ResMaps don't have
+// the same resources in the same order, as
+// ErrorIfNotEqualLists, listing what differs at each
+// position as CompareSets does.
+func CompareLists(m, other ResMap, opts CompareOptions) error {
+	rs1, rs2 := m.Resources(), other.Resources()
+	var selfOnly, otherOnly, problems, pairs []string
+	if len(rs1) != len(rs2) {
+		problems = append(problems, fmt.Sprintf(
+			"self has %d resources, other %d", len(rs1), len(rs2)))
+	}
+	for i := range rs1 {
+		if i >= len(rs2) {
+			selfOnly = append(selfOnly, rs1[i].CurId().String())
+			continue
+		}
+		r1, r2 := rs1[i], rs2[i]
+		if r1.ErrIfNotEquals(r2) == nil {
+			continue
+		}
+		name := fmt.Sprintf("%d: %s", i, r1.CurId())
+		if id := r2.CurId(); !id.Equals(r1.CurId()) {
+			name += fmt.Sprintf(" (%s in other)", id)
+		}
+		pairs = append(pairs, describeDifference(name, r1, r2, opts))
+	}
+	for i := len(rs1); i < len(rs2); i++ {
+		otherOnly = append(otherOnly, rs2[i].CurId().String())
+	}
+	return compareError(selfOnly, otherOnly, problems, pairs)
+}
+
+func compareError(selfOnly, otherOnly, problems, pairs []string) error {
+	if len(selfOnly)+len(otherOnly)+len(problems)+len(pairs) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("resources not equal")
+	for _, p := range problems {
+		fmt.Fprintf(&sb, "\n%s", p)
+	}
+	writeIds := func(side string, ids []string) {
+		if len(ids) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\nonly in %s:", side)
+		for _, id := range ids {
+			fmt.Fprintf(&sb, "\n  %s", id)
+		}
+	}
+	writeIds("self", selfOnly)
+	writeIds("other", otherOnly)
+	for _, p := range pairs {
+		fmt.Fprintf(&sb, "\n%s", p)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+// describeDifference returns the paths of the fields that
+// differ between the given resources, with their values.
+func describeDifference(
+	name string, r1, r2 *resource.Resource, opts CompareOptions) string {
+	v1, v2 := make(map[string]interface{}), make(map[string]interface{})
+	flattenInto(v1, "", r1.Map())
+	flattenInto(v2, "", r2.Map())
+	var paths []string
+	for path, v := range v1 {
+		if w, found := v2[path]; !found || !sameLeaf(v, w) {
+			paths = append(paths, path)
+		}
+	}
+	for path := range v2 {
+		if _, found := v1[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	var sb strings.Builder
+	switch len(paths) {
+	case 0:
+		// E.g. the resources are referred to by others.
+		fmt.Fprintf(&sb, "%s differs, though not in its fields", name)
+		return sb.String()
+	case 1:
+		fmt.Fprintf(&sb, "%s differs at 1 field (self -> other):", name)
+	default:
+		fmt.Fprintf(&sb, "%s differs at %d fields (self -> other):",
+			name, len(paths))
+	}
+	shown := paths
+	if opts.MaxFields > 0 && len(shown) > opts.MaxFields {
+		shown = shown[:opts.MaxFields]
+	}
+	for _, path := range shown {
+		fmt.Fprintf(&sb, "\n  %s: %s -> %s", path,
+			showLeaf(v1, path, opts), showLeaf(v2, path, opts))
+	}
+	if more := len(paths) - len(shown); more > 0 {
+		fmt.Fprintf(&sb, "\n  ... and %d more", more)
+	}
+	return sb.String()
+}
+
+func sameLeaf(v, w interface{}) bool {
+	return fmt.Sprintf("%T:%v", v, v) == fmt.Sprintf("%T:%v", w, w)
+}
+
+// showLeaf prints the value of the leaf at the given path,
+// if any, cut short per the options.
+func showLeaf(
+	leaves map[string]interface{}, path string, opts CompareOptions) string {
+	v, found := leaves[path]
+	if !found {
+		return "(none)"
+	}
+	var s string
+	switch x := v.(type) {
+	case map[string]interface{}:
+		s = "{}"
+	case []interface{}:
+		s = "[]"
+	case string:
+		s = fmt.Sprintf("%q", x)
+	default:
+		s = fmt.Sprintf("%v", x)
+	}
+	if opts.MaxValueLength > 0 && len(s) > opts.MaxValueLength {
+		s = s[:opts.MaxValueLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+)
+
+func deployment(t *testing.T, replicas int, image string) ResMap {
+	m, err := rmF.NewResMapFromBytes([]byte(fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: %d
+  template:
+    spec:
+      containers:
+      - name: web
+        image: %s
+        env:
+        - name: A
+          value: a%d
+        - name: B
+          value: b%d
+        - name: C
+          value: c%d
+        - name: D
+          value: d%d
+        - name: E
+          value: e%d
+        - name: F
+          value: f%d
+`, replicas, image, replicas, replicas, replicas, replicas, replicas, replicas)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestCompareSetsFields(t *testing.T) {
+	m1 := deployment(t, 1, "nginx:1.19")
+	m2 := deployment(t, 3, "nginx:1.20")
+	assert.NoError(t, m1.ErrorIfNotEqualSets(m1.DeepCopy()))
+	err := m1.ErrorIfNotEqualSets(m2)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	msg := err.Error()
+	assert.Contains(t, msg,
+		"apps_v1_Deployment|~X|web differs at 8 fields (self -> other):")
+	assert.Contains(t, msg, "spec/replicas: 1 -> 3")
+	assert.Contains(t, msg,
+		`spec/template/spec/containers/0/env/0/value: "a1" -> "a3"`)
+	assert.Contains(t, msg,
+		`spec/template/spec/containers/0/image: "nginx:1.19" -> "nginx:1.20"`)
+	assert.NotContains(t, msg, "apiVersion: apps/v1\nkind")
+	assert.NotContains(t, msg, "metadata/name")
+
+	err = CompareSets(m1, m2, CompareOptions{MaxFields: 2, MaxValueLength: 4})
+	if assert.Error(t, err) {
+		msg = err.Error()
+		assert.Contains(t, msg,
+			`spec/template/spec/containers/0/env/0/value: "a1" -> "a3"`)
+		assert.Contains(t, msg, "spec/replicas: 1 -> 3")
+		assert.Contains(t, msg, "  ... and 6 more")
+		assert.NotContains(t, msg, "env/1")
+	}
+	err = CompareSets(m1, m2, CompareOptions{MaxValueLength: 4})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			`spec/template/spec/containers/0/image: "ngi... -> "ngi...`)
+		assert.NotContains(t, err.Error(), "more")
+	}
+}
+
+func TestCompareSetsIds(t *testing.T) {
+	m1 := New()
+	doAppend(t, m1, makeCm(1))
+	doAppend(t, m1, makeCm(2))
+	m2 := New()
+	doAppend(t, m2, makeCm(2))
+	doAppend(t, m2, makeCm(3))
+	doAppend(t, m2, makeCm(4))
+	assert.EqualError(t, m1.ErrorIfNotEqualSets(m2), `resources not equal
+only in self:
+  ~G_v1_ConfigMap|~X|cm001
+only in other:
+  ~G_v1_ConfigMap|~X|cm003
+  ~G_v1_ConfigMap|~X|cm004`)
+}
+
+func TestCompareLists(t *testing.T) {
+	m1 := New()
+	doAppend(t, m1, makeCm(1))
+	doAppend(t, m1, makeCm(2))
+	m2 := New()
+	doAppend(t, m2, makeCm(2))
+	assert.NoError(t, m1.ErrorIfNotEqualLists(m1.DeepCopy()))
+	err := m1.ErrorIfNotEqualLists(m2)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	msg := err.Error()
+	assert.Contains(t, msg, "self has 2 resources, other 1")
+	assert.Contains(t, msg, "only in self:\n  ~G_v1_ConfigMap|~X|cm002")
+	assert.Contains(t, msg, "0: ~G_v1_ConfigMap|~X|cm001 "+
+		"(~G_v1_ConfigMap|~X|cm002 in other) differs at 1 field")
+	assert.Contains(t, msg, `metadata/name: "cm001" -> "cm002"`)
+	assert.Equal(t, 1, strings.Count(msg, "differs"))
+}
@@ -0,0 +1,470 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"io"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// concurrentResMap is a ResMap safe for concurrent use,
+// guarding another with a lock.  The lock is a plain mutex,
+// since even the methods that only read the ResMap may
+// update the index of its ids.
+type concurrentResMap struct {
+	mu sync.Mutex
+	m  ResMap
+}
+
+var _ ResMap = &concurrentResMap{}
+
+// NewConcurrent returns a ResMap wrapping the given one that
+// is safe for concurrent use, e.g. to append the resources
+// made by many goroutines.  Each method holds a lock on it
+// for its duration, so the functions given to Rename and
+// WithRNodes mustn't use it.  The wrapped ResMap mustn't be
+// used directly any more, nor its resources changed
+// concurrently with the wrapper's methods.  The copies the
+// wrapper makes aren't wrapped.
+func NewConcurrent(m ResMap) ResMap {
+	if c, ok := m.(*concurrentResMap); ok {
+		return c
+	}
+	return &concurrentResMap{m: m}
+}
+
+// unwrap returns the ResMap wrapped by the given one, if
+// it's from NewConcurrent, or else the given one.
+func unwrap(m ResMap) ResMap {
+	if c, ok := m.(*concurrentResMap); ok {
+		return c.m
+	}
+	return m
+}
+
+// Size implements ResMap.
+func (c *concurrentResMap) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Size()
+}
+
+// Resources implements ResMap.
+func (c *concurrentResMap) Resources() []*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Resources()
+}
+
+// Append implements ResMap.
+func (c *concurrentResMap) Append(r *resource.Resource) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Append(r)
+}
+
+// AppendAll implements ResMap.
+func (c *concurrentResMap) AppendAll(other ResMap) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AppendAll(unwrap(other))
+}
+
+// AbsorbAll implements ResMap.
+func (c *concurrentResMap) AbsorbAll(other ResMap) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AbsorbAll(unwrap(other))
+}
+
+// AbsorbAllWith implements ResMap.
+func (c *concurrentResMap) AbsorbAllWith(other ResMap, s ConflictStrategy) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AbsorbAllWith(unwrap(other), s)
+}
+
+// AsYaml implements ResMap.
+func (c *concurrentResMap) AsYaml() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AsYaml()
+}
+
+// AsYamlWithDirectives implements ResMap.
+func (c *concurrentResMap) AsYamlWithDirectives() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AsYamlWithDirectives()
+}
+
+// WriteYaml implements ResMap.
+func (c *concurrentResMap) WriteYaml(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.WriteYaml(w)
+}
+
+// WriteYamlWithDirectives implements ResMap.
+func (c *concurrentResMap) WriteYamlWithDirectives(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.WriteYamlWithDirectives(w)
+}
+
+// AsJson implements ResMap.
+func (c *concurrentResMap) AsJson() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AsJson()
+}
+
+// Hash implements ResMap.
+func (c *concurrentResMap) Hash() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Hash()
+}
+
+// PerResourceHashes implements ResMap.
+func (c *concurrentResMap) PerResourceHashes() (map[resid.ResId]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.PerResourceHashes()
+}
+
+// GetByIndex implements ResMap.
+func (c *concurrentResMap) GetByIndex(i int) *resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetByIndex(i)
+}
+
+// GetIndexOfCurrentId implements ResMap.
+func (c *concurrentResMap) GetIndexOfCurrentId(id resid.ResId) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetIndexOfCurrentId(id)
+}
+
+// GetMatchingResourcesByCurrentId implements ResMap.
+func (c *concurrentResMap) GetMatchingResourcesByCurrentId(matches IdMatcher) []*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetMatchingResourcesByCurrentId(matches)
+}
+
+// GetMatchingResourcesByOriginalId implements ResMap.
+func (c *concurrentResMap) GetMatchingResourcesByOriginalId(matches IdMatcher) []*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetMatchingResourcesByOriginalId(matches)
+}
+
+// GetByCurrentId implements ResMap.
+func (c *concurrentResMap) GetByCurrentId(id resid.ResId) (*resource.Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetByCurrentId(id)
+}
+
+// GetByOriginalId implements ResMap.
+func (c *concurrentResMap) GetByOriginalId(id resid.ResId) (*resource.Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetByOriginalId(id)
+}
+
+// GetById implements ResMap.
+func (c *concurrentResMap) GetById(id resid.ResId) (*resource.Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GetById(id)
+}
+
+// GroupedByCurrentNamespace implements ResMap.
+func (c *concurrentResMap) GroupedByCurrentNamespace() map[string][]*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GroupedByCurrentNamespace()
+}
+
+// CurrentNamespaces implements ResMap.
+func (c *concurrentResMap) CurrentNamespaces() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.CurrentNamespaces()
+}
+
+// GroupedByOriginalNamespace implements ResMap.
+func (c *concurrentResMap) GroupedByOriginalNamespace() map[string][]*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GroupedByOriginalNamespace()
+}
+
+// OriginalNamespaces implements ResMap.
+func (c *concurrentResMap) OriginalNamespaces() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.OriginalNamespaces()
+}
+
+// NonNamespaceable implements ResMap.
+func (c *concurrentResMap) NonNamespaceable() []*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.NonNamespaceable()
+}
+
+// GroupedByKind implements ResMap.
+func (c *concurrentResMap) GroupedByKind() map[string][]*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GroupedByKind()
+}
+
+// GroupedByCurrentGvk implements ResMap.
+func (c *concurrentResMap) GroupedByCurrentGvk() map[resid.Gvk][]*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GroupedByCurrentGvk()
+}
+
+// GroupedByOriginalGvk implements ResMap.
+func (c *concurrentResMap) GroupedByOriginalGvk() map[resid.Gvk][]*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.GroupedByOriginalGvk()
+}
+
+// AllIds implements ResMap.
+func (c *concurrentResMap) AllIds() []resid.ResId {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AllIds()
+}
+
+// AllOriginalIds implements ResMap.
+func (c *concurrentResMap) AllOriginalIds() []resid.ResId {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.AllOriginalIds()
+}
+
+// IdPairs implements ResMap.
+func (c *concurrentResMap) IdPairs() []IdPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.IdPairs()
+}
+
+// Replace implements ResMap.
+func (c *concurrentResMap) Replace(r *resource.Resource) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Replace(r)
+}
+
+// ReplaceMatchingId implements ResMap.
+func (c *concurrentResMap) ReplaceMatchingId(matches IdMatcher, r *resource.Resource) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ReplaceMatchingId(matches, r)
+}
+
+// Rename implements ResMap.
+func (c *concurrentResMap) Rename(id resid.ResId, mutate func(*resource.Resource) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Rename(id, mutate)
+}
+
+// Remove implements ResMap.
+func (c *concurrentResMap) Remove(id resid.ResId) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Remove(id)
+}
+
+// RemoveMatchingId implements ResMap.
+func (c *concurrentResMap) RemoveMatchingId(matches IdMatcher) ([]resid.ResId, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.RemoveMatchingId(matches)
+}
+
+// RemoveBySelector implements ResMap.
+func (c *concurrentResMap) RemoveBySelector(s types.Selector) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.RemoveBySelector(s)
+}
+
+// Clear implements ResMap.
+func (c *concurrentResMap) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Clear()
+}
+
+// Sort implements ResMap.
+func (c *concurrentResMap) Sort(ordering ResourceOrdering) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Sort(ordering)
+}
+
+// SplitBy implements ResMap.
+func (c *concurrentResMap) SplitBy(fn GroupFunc) ([]Group, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.SplitBy(fn)
+}
+
+// PartitionByNamespace implements ResMap.
+func (c *concurrentResMap) PartitionByNamespace(policy ClusterScopedPolicy) (map[string]ResMap, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.PartitionByNamespace(policy)
+}
+
+// SubsetThatCouldBeReferencedByResource implements ResMap.
+func (c *concurrentResMap) SubsetThatCouldBeReferencedByResource(r *resource.Resource) ResMap {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.SubsetThatCouldBeReferencedByResource(r)
+}
+
+// DeepCopy implements ResMap.
+func (c *concurrentResMap) DeepCopy() ResMap {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.DeepCopy()
+}
+
+// LazyCopy implements ResMap.
+func (c *concurrentResMap) LazyCopy() ResMap {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.LazyCopy()
+}
+
+// ShallowCopy implements ResMap.
+func (c *concurrentResMap) ShallowCopy() ResMap {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ShallowCopy()
+}
+
+// Provenance implements ResMap.
+func (c *concurrentResMap) Provenance(id resid.ResId, path string) (Source, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Provenance(id, path)
+}
+
+// SetProvenance implements ResMap.
+func (c *concurrentResMap) SetProvenance(t *ProvenanceTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.SetProvenance(t)
+}
+
+// ErrorIfNotEqualSets implements ResMap.
+func (c *concurrentResMap) ErrorIfNotEqualSets(other ResMap) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ErrorIfNotEqualSets(unwrap(other))
+}
+
+// ErrorIfNotEqualLists implements ResMap.
+func (c *concurrentResMap) ErrorIfNotEqualLists(other ResMap) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ErrorIfNotEqualLists(unwrap(other))
+}
+
+// Diff implements ResMap.
+func (c *concurrentResMap) Diff(other ResMap) (*ResMapDiff, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Diff(unwrap(other))
+}
+
+// Debug implements ResMap.
+func (c *concurrentResMap) Debug(title string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Debug(title)
+}
+
+// DebugTo implements ResMap.
+func (c *concurrentResMap) DebugTo(w io.Writer, title string, opts DebugOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.DebugTo(w, title, opts)
+}
+
+// Select implements ResMap.
+func (c *concurrentResMap) Select(s types.Selector) ([]*resource.Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.Select(s)
+}
+
+// SelectWithOptions implements ResMap.
+func (c *concurrentResMap) SelectWithOptions(s types.Selector, opts resid.GvkMatchOptions) ([]*resource.Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.SelectWithOptions(s, opts)
+}
+
+// SelectMatching implements ResMap.
+func (c *concurrentResMap) SelectMatching(matcher *Matcher) []*resource.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.SelectMatching(matcher)
+}
+
+// ToRNodeSlice implements ResMap.
+func (c *concurrentResMap) ToRNodeSlice() ([]*yaml.RNode, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ToRNodeSlice()
+}
+
+// WithRNodes implements ResMap.
+func (c *concurrentResMap) WithRNodes(fn func([]*yaml.RNode) ([]*yaml.RNode, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.WithRNodes(fn)
+}
+
+// ApplyFilter implements ResMap.
+func (c *concurrentResMap) ApplyFilter(f kio.Filter) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ApplyFilter(f)
+}
+
+// ApplySmPatch implements ResMap.
+func (c *concurrentResMap) ApplySmPatch(selectedSet *resource.IdSet, patch *resource.Resource) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m.ApplySmPatch(selectedSet, patch)
+}
+
+// RemoveIdAnnotations implements ResMap.
+func (c *concurrentResMap) RemoveIdAnnotations() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.RemoveIdAnnotations()
+}
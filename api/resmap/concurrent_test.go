@@ -0,0 +1,148 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/filters/labels"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestNewConcurrent(t *testing.T) {
+	m := NewConcurrent(New())
+	assert.Equal(t, m, NewConcurrent(m))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, m.Append(makeCm(i)))
+			if i%2 == 0 {
+				_, err := m.Replace(makeCm(i))
+				assert.NoError(t, err)
+			} else {
+				assert.NoError(t, m.Remove(makeCm(i).CurId()))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 50, m.Size())
+	for _, r := range m.Resources() {
+		var i int
+		_, err := fmt.Sscanf(r.GetName(), "cm%d", &i)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, i%2)
+	}
+	assert.NoError(t, m.ErrorIfNotEqualSets(m.DeepCopy()))
+}
+
+// Methods given the map itself mustn't wait on its lock.
+func TestConcurrentWithItself(t *testing.T) {
+	m := NewConcurrent(makeCms(t, 3))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d, err := m.Diff(m)
+		if assert.NoError(t, err) {
+			assert.Empty(t, d.Added)
+			assert.Empty(t, d.Removed)
+			assert.Empty(t, d.Modified)
+		}
+		assert.Error(t, m.AppendAll(m))
+		assert.Error(t, m.AbsorbAll(m))
+		assert.Error(t, m.AbsorbAllWith(m, DefaultConflictStrategy))
+		assert.NoError(t, m.ErrorIfNotEqualLists(m))
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlocked")
+	}
+	assert.Equal(t, 3, m.Size())
+}
+
+func makeCms(t testing.TB, n int) ResMap {
+	m := New()
+	for i := 0; i < n; i++ {
+		if err := m.Append(makeCm(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestParallelTransform(t *testing.T) {
+	m := makeCms(t, 200)
+	expected := m.AllIds()
+	err := ParallelTransform(m, func(r *resource.Resource) error {
+		r.SetLabels(map[string]string{"name": r.GetName()})
+		return nil
+	}, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, m.AllIds())
+	for _, r := range m.Resources() {
+		assert.Equal(t, r.GetName(), r.GetLabels()["name"])
+	}
+}
+
+func TestParallelTransformErrors(t *testing.T) {
+	m := makeCms(t, 10)
+	var calls int32
+	err := ParallelTransform(m, func(r *resource.Resource) error {
+		atomic.AddInt32(&calls, 1)
+		return fmt.Errorf("no")
+	}, 1)
+	// The first error cancels the rest.
+	assert.EqualError(t, err, "no")
+	assert.Equal(t, int32(1), calls)
+
+	err = ParallelTransform(m, func(r *resource.Resource) error {
+		if r.GetName() == "cm003" || r.GetName() == "cm007" {
+			return fmt.Errorf("no %s", r.GetName())
+		}
+		return nil
+	}, 0)
+	if assert.Error(t, err) {
+		// Which errors are met depends on the workers.
+		assert.Contains(t, err.Error(), "no cm")
+	}
+}
+
+func BenchmarkParallelTransform(b *testing.B) {
+	m := makeCms(b, 5000)
+	f := labels.Filter{
+		Labels: map[string]string{"app": "bench"},
+		FsSlice: types.FsSlice{
+			{Path: "metadata/labels", CreateIfNotPresent: true},
+		},
+		Validation: &types.MetadataValidation{},
+	}
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, r := range m.Resources() {
+				if err := r.ApplyFilter(f); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := ParallelTransform(m, func(r *resource.Resource) error {
+				return r.ApplyFilter(f)
+			}, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ConflictAction is what AbsorbAllWith does with an
+// incoming resource whose id is already in the ResMap.
+type ConflictAction int
+
+const (
+	// ConflictMerge merges the existing resource into the
+	// incoming one, as a generator's merge behavior does:
+	// the metadata, e.g. labels, and the data maps of
+	// ConfigMaps and Secrets are merged, with the incoming
+	// values winning, and the result replaces the existing
+	// resource.
+	ConflictMerge ConflictAction = iota
+
+	// ConflictReplace replaces the existing resource with
+	// the incoming one, keeping the existing one's merged
+	// metadata, as a generator's replace behavior does.
+	ConflictReplace
+
+	// ConflictKeep keeps the existing resource and drops
+	// the incoming one.
+	ConflictKeep
+
+	// ConflictError fails the absorption.
+	ConflictError
+)
+
+// ConflictStrategy decides what AbsorbAllWith does when an
+// incoming resource has the id of an existing one.  An
+// error it returns fails the absorption.
+type ConflictStrategy func(
+	existing, incoming *resource.Resource) (ConflictAction, error)
+
+// DefaultConflictStrategy is the strategy of AbsorbAll: it
+// merges or replaces as the behavior of the incoming
+// resource says, and fails for any other behavior, as only
+// generated resources are expected to collide.
+func DefaultConflictStrategy(
+	existing, incoming *resource.Resource) (ConflictAction, error) {
+	switch incoming.Behavior() {
+	case types.BehaviorReplace:
+		return ConflictReplace, nil
+	case types.BehaviorMerge:
+		return ConflictMerge, nil
+	default:
+		return ConflictError, fmt.Errorf(
+			"id %#v exists; behavior must be merge or replace",
+			incoming.GetCurIdFast())
+	}
+}
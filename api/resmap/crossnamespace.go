@@ -0,0 +1,71 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/konfig/builtinpluginconsts"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	crossNamespaceReferencesOnce sync.Once
+	crossNamespaceReferences     types.FsSlice
+)
+
+// crossNamespaceReferenceFields returns the fields in which
+// resources name the namespaces of the resources they refer
+// to, per builtinpluginconsts.
+func crossNamespaceReferenceFields() types.FsSlice {
+	crossNamespaceReferencesOnce.Do(func() {
+		var config struct {
+			CrossNamespaceReference types.FsSlice `json:"crossNamespaceReference"`
+		}
+		err := yaml.Unmarshal(
+			builtinpluginconsts.GetCrossNamespaceReferenceFieldSpecs(), &config)
+		if err != nil {
+			panic(err)
+		}
+		crossNamespaceReferences = config.CrossNamespaceReference
+	})
+	return crossNamespaceReferences
+}
+
+// referencedNamespaces returns the namespaces that the
+// given resource names in its cross namespace reference
+// fields, e.g. those of the subjects of a RoleBinding.
+func referencedNamespaces(r *resource.Resource) map[string]bool {
+	result := make(map[string]bool)
+	gvk := r.GetGvk()
+	for _, fs := range crossNamespaceReferenceFields() {
+		if !gvk.IsSelected(&fs.Gvk) {
+			continue
+		}
+		collectNamespaces(result, r.Map(), fs.PathSlice())
+	}
+	return result
+}
+
+// collectNamespaces adds the strings at the given path of
+// v to result, going through lists.
+func collectNamespaces(
+	result map[string]bool, v interface{}, path []string) {
+	switch x := v.(type) {
+	case []interface{}:
+		for _, item := range x {
+			collectNamespaces(result, item, path)
+		}
+	case map[string]interface{}:
+		if len(path) > 0 {
+			collectNamespaces(result, x[path[0]], path[1:])
+		}
+	case string:
+		if len(path) == 0 {
+			result[x] = true
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// DebugVerbosity controls how much of each resource DebugTo writes.
+type DebugVerbosity int
+
+const (
+	// DebugIdsOnly writes one header line per resource.
+	DebugIdsOnly DebugVerbosity = iota
+
+	// DebugIdsAndLabels adds the labels to the header line.
+	DebugIdsAndLabels
+
+	// DebugFullYaml writes the header line followed by the
+	// resource as YAML.
+	DebugFullYaml
+)
+
+// DebugOptions configures DebugTo.
+type DebugOptions struct {
+	Verbosity DebugVerbosity
+
+	// History adds, after each header, a line per record
+	// of the resource's Provenance, i.e. per transformer
+	// run that changed it, in the order they ran.
+	History bool
+}
+
+// Origins reported in debug headers.
+const (
+	debugOriginGenerated = "generated"
+	debugOriginLoaded    = "loaded"
+)
+
+// DebugTo implements ResMap.
+//
+// The output starts with a title line, followed by one
+// header line per resource, e.g.
+//
+//	# resmap "title" size=2
+//	# resource index=0 curId=~G_v1_ConfigMap|~X|cm-abc orgId=~G_v1_ConfigMap|~X|cm origin=generated
+//
+// If a ProvenanceTable is set, each header is followed by
+// one line per field a patch or transformer wrote, e.g.
+//
+//	# provenance path=spec/replicas patch="patch.yaml" root="/app"
+//
+// With DebugOptions.History, these are followed by one line
+// per transformer run that changed the resource, e.g.
+//
+//	# history transformer=NamespaceTransformer patch="" root="/app/prod" fields=metadata/namespace
+//
+// The fields of the header lines are stable, so the output
+// can be searched with grep and compared across runs.
+func (m *resWrangler) DebugTo(
+	w io.Writer, title string, opts DebugOptions) error {
+	_, err := fmt.Fprintf(w, "# resmap %q size=%d\n", title, m.Size())
+	if err != nil {
+		return err
+	}
+	for i, r := range m.rList {
+		origin := debugOriginLoaded
+		if r.IsGenerated() {
+			origin = debugOriginGenerated
+		}
+		header := fmt.Sprintf(
+			"# resource index=%d curId=%s orgId=%s origin=%s",
+			i, r.CurId(), r.OrgId(), origin)
+		if opts.Verbosity == DebugIdsAndLabels {
+			header += " labels=" + debugLabels(r.GetLabels())
+		}
+		if _, err = fmt.Fprintln(w, header); err != nil {
+			return err
+		}
+		for _, path := range m.provenance.Paths(r) {
+			src, _ := m.provenance.Lookup(r, path)
+			if src.Transformer != "" {
+				_, err = fmt.Fprintf(w,
+					"# provenance path=%s transformer=%s root=%q\n",
+					path, src.Transformer, src.KustomizationRoot)
+			} else {
+				_, err = fmt.Fprintf(w, "# provenance path=%s patch=%q root=%q\n",
+					path, src.PatchFile, src.KustomizationRoot)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if opts.History {
+			if err = debugHistory(w, r.Provenance()); err != nil {
+				return err
+			}
+		}
+		if opts.Verbosity != DebugFullYaml {
+			continue
+		}
+		blob, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s---\n", blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debugHistory writes a line per record.
+func debugHistory(w io.Writer, records []resource.ProvenanceRecord) error {
+	for _, rec := range records {
+		_, err := fmt.Fprintf(w,
+			"# history transformer=%s patch=%q root=%q fields=%s\n",
+			rec.Transformer, rec.PatchFile, rec.KustomizationRoot,
+			strings.Join(rec.Fields, ","))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debugLabels formats labels as sorted, comma-separated
+// key=value pairs.
+func debugLabels(labels map[string]string) string {
+	var pairs []string
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestDebugTo(t *testing.T) {
+	m := New()
+	doAppend(t, m, rf.FromMap(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"tier": "web", "app": "shop"},
+		},
+	}))
+	doAppend(t, m, rf.FromMapAndOption(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "cm",
+		},
+	}, &types.GeneratorArgs{}))
+	const (
+		title  = "# resmap \"mine\" size=2\n"
+		deploy = "# resource index=0 curId=apps_v1_Deployment|~X|web orgId=apps_v1_Deployment|~X|web origin=loaded"
+		cm     = "# resource index=1 curId=~G_v1_ConfigMap|~X|cm orgId=~G_v1_ConfigMap|~X|cm origin=generated"
+	)
+	testCases := map[string]struct {
+		verbosity DebugVerbosity
+		expected  string
+	}{
+		"ids": {
+			verbosity: DebugIdsOnly,
+			expected:  title + deploy + "\n" + cm + "\n",
+		},
+		"labels": {
+			verbosity: DebugIdsAndLabels,
+			expected: title +
+				deploy + " labels=app=shop,tier=web\n" +
+				cm + " labels=\n",
+		},
+		"yaml": {
+			verbosity: DebugFullYaml,
+			expected: title + deploy + `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: shop
+    tier: web
+  name: web
+---
+` + cm + `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := m.DebugTo(&buf, "mine", DebugOptions{Verbosity: tc.verbosity})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
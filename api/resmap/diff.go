@@ -0,0 +1,298 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ResMapDiff is what changed from one ResMap to another,
+// e.g. from the output of a base to that of an overlay,
+// as returned by ResMap.Diff.
+type ResMapDiff struct {
+	// Added are the ids of the resources only in the
+	// second ResMap, in its order.
+	Added []resid.ResId
+
+	// Removed are the ids of the resources only in the
+	// first ResMap, in its order.
+	Removed []resid.ResId
+
+	// Modified are the resources in both that differ, in
+	// the order of the second ResMap.
+	Modified []ResourceDiff
+
+	// entries hold the printouts of the changes, for String.
+	entries []diffEntry
+}
+
+// ResourceDiff is how a resource differs between two
+// ResMaps.
+type ResourceDiff struct {
+	// OldId and NewId are the CurIds of the resource in
+	// the first and second ResMap; they differ if it was
+	// renamed.
+	OldId resid.ResId
+	NewId resid.ResId
+
+	// Fields are the sorted paths of the fields that were
+	// added, removed or changed.  Paths are slash separated,
+	// with list items given by index, as in Provenance,
+	// e.g. spec/template/spec/containers/0/image
+	Fields []string
+}
+
+type diffEntry struct {
+	oldName, newName string
+	oldYaml, newYaml string
+}
+
+// IsEmpty returns true if the ResMaps had the same resources.
+func (d *ResMapDiff) IsEmpty() bool {
+	return len(d.entries) == 0
+}
+
+// String returns the changes in the style of a unified
+// diff, with one file per resource, named by its id; an
+// added or removed resource is diffed against /dev/null.
+func (d *ResMapDiff) String() string {
+	var sb strings.Builder
+	for _, e := range d.entries {
+		writeUnifiedDiff(&sb, e.oldName, e.newName,
+			splitLines(e.oldYaml), splitLines(e.newYaml))
+	}
+	return sb.String()
+}
+
+// Diff implements ResMap.
+func (m *resWrangler) Diff(other ResMap) (*ResMapDiff, error) {
+	before := m.Resources()
+	after := other.Resources()
+	// matches maps the index of each resource of after to
+	// that of its counterpart in before, if any.
+	matches := make(map[int]int)
+	matched := make(map[int]bool)
+	match := func(id func(*resource.Resource) resid.ResId) {
+		for i, r := range after {
+			if _, found := matches[i]; found {
+				continue
+			}
+			for j, old := range before {
+				if !matched[j] && id(old).Equals(id(r)) {
+					matches[i] = j
+					matched[j] = true
+					break
+				}
+			}
+		}
+	}
+	match((*resource.Resource).CurId)
+	// A resource renamed by a transformer keeps its OrgId.
+	match((*resource.Resource).OrgId)
+
+	result := &ResMapDiff{}
+	for i, r := range after {
+		j, found := matches[i]
+		if !found {
+			y, err := diffYaml(r)
+			if err != nil {
+				return nil, err
+			}
+			result.Added = append(result.Added, r.CurId())
+			result.entries = append(result.entries, diffEntry{
+				oldName: devNull, newName: r.CurId().String(), newYaml: y})
+			continue
+		}
+		old := before[j]
+		fields := diffFields(flattenFields(old), flattenFields(r))
+		if len(fields) == 0 {
+			continue
+		}
+		oldYaml, err := diffYaml(old)
+		if err != nil {
+			return nil, err
+		}
+		newYaml, err := diffYaml(r)
+		if err != nil {
+			return nil, err
+		}
+		result.Modified = append(result.Modified, ResourceDiff{
+			OldId: old.CurId(), NewId: r.CurId(), Fields: fields})
+		result.entries = append(result.entries, diffEntry{
+			oldName: old.CurId().String(), newName: r.CurId().String(),
+			oldYaml: oldYaml, newYaml: newYaml})
+	}
+	for j, old := range before {
+		if matched[j] {
+			continue
+		}
+		y, err := diffYaml(old)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, old.CurId())
+		result.entries = append(result.entries, diffEntry{
+			oldName: old.CurId().String(), newName: devNull, oldYaml: y})
+	}
+	return result, nil
+}
+
+// diffFields returns the sorted paths whose values differ
+// between the given flattenFields results.
+func diffFields(before, after map[string]string) []string {
+	var result []string
+	for path, value := range after {
+		if old, found := before[path]; !found || old != value {
+			result = append(result, path)
+		}
+	}
+	for path := range before {
+		if _, found := after[path]; !found {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// diffYaml returns the resource as YAML, without the
+// annotations kustomize uses to track ids.
+func diffYaml(r *resource.Resource) (string, error) {
+	c := r.DeepCopy()
+	c.RemoveIdAnnotations()
+	y, err := c.AsYAML()
+	if err != nil {
+		return "", err
+	}
+	return string(y), nil
+}
+
+const (
+	devNull = "/dev/null"
+	// diffContext is the number of unchanged lines shown
+	// around each change.
+	diffContext = 3
+)
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLine is a line of a diff; op is ' ' for a line both
+// sides have, '-' for one only the old side has and '+' for
+// one only the new side has.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines returns the lines of a shortest edit from a
+// to b, found via their longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common
+	// subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var result []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = append(result, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			result = append(result, diffLine{'-', a[i]})
+			i++
+		default:
+			result = append(result, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return result
+}
+
+// writeUnifiedDiff writes the changes from a to b as a
+// unified diff with diffContext lines of context, merging
+// changes whose contexts would touch into one hunk.
+func writeUnifiedDiff(sb *strings.Builder, oldName, newName string, a, b []string) {
+	lines := diffLines(a, b)
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", oldName, newName)
+	// count returns the number of lines of each side
+	// in lines[from:to].
+	count := func(from, to int) (int, int) {
+		o, n := 0, 0
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				o++
+			}
+			if l.op != '-' {
+				n++
+			}
+		}
+		return o, n
+	}
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i + 1
+		for j := end; j < len(lines) && j-end <= 2*diffContext; j++ {
+			if lines[j].op != ' ' {
+				end = j + 1
+			}
+		}
+		stop := end + diffContext
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		oldBefore, newBefore := count(0, start)
+		o, n := count(start, stop)
+		fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n",
+			hunkStart(oldBefore, o), o, hunkStart(newBefore, n), n)
+		for _, l := range lines[start:stop] {
+			sb.WriteByte(l.op)
+			sb.WriteString(l.text)
+			sb.WriteByte('\n')
+		}
+		i = stop
+	}
+}
+
+// hunkStart returns the number a hunk header gives the
+// first line of a side; as in diff, an empty side is
+// numbered by the line before it.
+func hunkStart(before, count int) int {
+	if count == 0 {
+		return before
+	}
+	return before + 1
+}
@@ -0,0 +1,216 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	. "sigs.k8s.io/kustomize/api/resmap"
+)
+
+func resMapFromYaml(t *testing.T, y string) ResMap {
+	t.Helper()
+	m, err := rmF.NewResMapFromBytes([]byte(y))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return m
+}
+
+func TestDiff(t *testing.T) {
+	before := resMapFromYaml(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.19
+        ports:
+        - containerPort: 80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  a: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: old
+`)
+	after := resMapFromYaml(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.20
+        ports:
+        - containerPort: 80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  a: b
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: new
+`)
+	d, err := before.Diff(after)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.False(t, d.IsEmpty())
+	assert.Equal(t, []resid.ResId{
+		resid.NewResId(resid.Gvk{Version: "v1", Kind: "Secret"}, "new")}, d.Added)
+	assert.Equal(t, []resid.ResId{
+		resid.NewResId(resid.Gvk{Version: "v1", Kind: "Service"}, "old")}, d.Removed)
+	deployment := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}, "web")
+	assert.Equal(t, []ResourceDiff{{
+		OldId: deployment,
+		NewId: deployment,
+		Fields: []string{
+			"spec/replicas",
+			"spec/template/spec/containers/0/image",
+		},
+	}}, d.Modified)
+	assert.Equal(t, `--- apps_v1_Deployment|~X|web
++++ apps_v1_Deployment|~X|web
+@@ -3,11 +3,11 @@
+ metadata:
+   name: web
+ spec:
+-  replicas: 1
++  replicas: 3
+   template:
+     spec:
+       containers:
+-      - image: nginx:1.19
++      - image: nginx:1.20
+         name: web
+         ports:
+         - containerPort: 80
+--- /dev/null
++++ ~G_v1_Secret|~X|new
+@@ -0,0 +1,4 @@
++apiVersion: v1
++kind: Secret
++metadata:
++  name: new
+--- ~G_v1_Service|~X|old
++++ /dev/null
+@@ -1,4 +0,0 @@
+-apiVersion: v1
+-kind: Service
+-metadata:
+-  name: old
+`, d.String())
+
+	d, err = before.Diff(before.DeepCopy())
+	assert.NoError(t, err)
+	assert.True(t, d.IsEmpty())
+	assert.Empty(t, d.String())
+}
+
+func TestDiffSeparateHunks(t *testing.T) {
+	before := resMapFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  a: "1"
+  b: "2"
+  c: "3"
+  d: "4"
+  e: "5"
+  f: "6"
+  g: "7"
+  h: "8"
+  i: "9"
+`)
+	after := resMapFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  a: "one"
+  b: "2"
+  c: "3"
+  d: "4"
+  e: "5"
+  f: "6"
+  g: "7"
+  h: "8"
+  i: "nine"
+`)
+	d, err := before.Diff(after)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `--- ~G_v1_ConfigMap|~X|cm
++++ ~G_v1_ConfigMap|~X|cm
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  a: "1"
++  a: one
+   b: "2"
+   c: "3"
+   d: "4"
+@@ -8,7 +8,7 @@
+   f: "6"
+   g: "7"
+   h: "8"
+-  i: "9"
++  i: nine
+ kind: ConfigMap
+ metadata:
+   name: cm
+`, d.String())
+}
+
+func TestDiffMatchesRenamedByOrgId(t *testing.T) {
+	before := resMapFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+	after := before.DeepCopy()
+	r := after.GetByIndex(0)
+	r.SetOriginalName(r.GetName(), false)
+	r.SetName("prod-settings")
+	d, err := before.Diff(after)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, d.Added)
+	assert.Empty(t, d.Removed)
+	if assert.Len(t, d.Modified, 1) {
+		assert.Equal(t, "settings", d.Modified[0].OldId.Name)
+		assert.Equal(t, "prod-settings", d.Modified[0].NewId.Name)
+		assert.Equal(t, []string{"metadata/name"}, d.Modified[0].Fields)
+	}
+}
@@ -18,6 +18,17 @@ type Factory struct {
 	resF *resource.Factory
 	// Makes ConflictDetectors.
 	cdf resource.ConflictDetectorFactory
+	// Optional record of the fields patches wrote.
+	provenance *ProvenanceTable
+	// The results plugins recorded.
+	pluginResults []PluginResult
+	// The warnings transformers made about the build.
+	warnings []string
+	// Optional summaries of the changes transformers made.
+	changeSummaries *ChangeSummaries
+	// Optional record of the fields patches wrote, to
+	// detect conflicting patches.
+	patchConflicts *PatchConflicts
 }
 
 // NewFactory returns a new resmap.Factory.
@@ -31,6 +42,43 @@ func (rmF *Factory) RF() *resource.Factory {
 	return rmF.resF
 }
 
+// SetProvenanceTable sets the table in which patch
+// plugins using this factory record the fields they write.
+func (rmF *Factory) SetProvenanceTable(t *ProvenanceTable) {
+	rmF.provenance = t
+}
+
+// ProvenanceTable returns the table set via
+// SetProvenanceTable, or nil if provenance isn't tracked.
+func (rmF *Factory) ProvenanceTable() *ProvenanceTable {
+	return rmF.provenance
+}
+
+// SetChangeSummaries sets the record in which builtin
+// transformers using this factory summarize their changes.
+func (rmF *Factory) SetChangeSummaries(s *ChangeSummaries) {
+	rmF.changeSummaries = s
+}
+
+// ChangeSummaries returns the record set via
+// SetChangeSummaries, or nil if changes aren't summarized.
+func (rmF *Factory) ChangeSummaries() *ChangeSummaries {
+	return rmF.changeSummaries
+}
+
+// SetPatchConflicts sets the record in which patch
+// plugins using this factory check their strategic merge
+// patches for conflicts.
+func (rmF *Factory) SetPatchConflicts(c *PatchConflicts) {
+	rmF.patchConflicts = c
+}
+
+// PatchConflicts returns the record set via
+// SetPatchConflicts, or nil if conflicts aren't detected.
+func (rmF *Factory) PatchConflicts() *PatchConflicts {
+	return rmF.patchConflicts
+}
+
 func New() ResMap {
 	return newOne()
 }
@@ -0,0 +1,31 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"sort"
+)
+
+// SortByFilePosition sorts the resources of the given map
+// by their resource.FilePosition, so that the resources of
+// each file are together, in the order of their documents,
+// and the files are in the order they were read.  Resources
+// without a position follow, in their current order.
+func SortByFilePosition(m ResMap) error {
+	resources := m.Resources()
+	sort.SliceStable(resources, func(i, j int) bool {
+		pi, pj := resources[i].GetFilePosition(), resources[j].GetFilePosition()
+		if pi == nil || pj == nil {
+			return pi != nil && pj == nil
+		}
+		return pi.Less(*pj)
+	})
+	m.Clear()
+	for _, r := range resources {
+		if err := m.Append(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestSortByFilePosition(t *testing.T) {
+	m := New()
+	add := func(name string, p *resource.FilePosition) {
+		r := rf.FromMap(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name},
+		})
+		r.SetFilePosition(p)
+		doAppend(t, m, r)
+	}
+	add("made1", nil)
+	add("b1", &resource.FilePosition{File: 1, Document: 1})
+	add("a0", &resource.FilePosition{File: 0, Document: 0})
+	add("made2", nil)
+	add("b0", &resource.FilePosition{File: 1, Document: 0})
+	add("a1", &resource.FilePosition{File: 0, Document: 1})
+
+	assert.NoError(t, SortByFilePosition(m))
+	var names []string
+	for _, r := range m.Resources() {
+		names = append(names, r.GetName())
+	}
+	assert.Equal(t,
+		[]string{"a0", "a1", "b0", "b1", "made1", "made2"}, names)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// SyncWaveAnnotation orders the resources Argo CD applies;
+// it's the default annotation of AnnotationGroupFunc.
+const SyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+// GroupFunc returns the key of the group of a resource,
+// and the order of the group among the others; see
+// ResMap.SplitBy.
+type GroupFunc func(r *resource.Resource) (key string, order int)
+
+// Group is a group of the resources of a ResMap, as
+// returned by SplitBy.
+type Group struct {
+	// Key is the key the GroupFunc gave the resources.
+	Key string
+
+	// Order is the order the GroupFunc gave the group.
+	Order int
+
+	// Resources are the resources of the group, in the
+	// order of the ResMap that was split.  They're the
+	// resources of that ResMap, not copies.
+	Resources ResMap
+}
+
+// AnnotationGroupFunc returns a GroupFunc grouping the
+// resources by the integer value of the given annotation,
+// or of SyncWaveAnnotation if it's empty, e.g. for each
+// wave to be applied in turn.  A group's order is its
+// value and its key the value as formatted by strconv,
+// so "01" and "1" are one group.  A resource lacking the
+// annotation is in group "0", as Argo CD treats it.  One
+// whose value isn't an integer is in a group of its own
+// value after all the others, so it's applied last rather
+// than early.
+func AnnotationGroupFunc(annotation string) GroupFunc {
+	if annotation == "" {
+		annotation = SyncWaveAnnotation
+	}
+	return func(r *resource.Resource) (string, int) {
+		value, found := r.GetAnnotations()[annotation]
+		if !found {
+			return "0", 0
+		}
+		order, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return value, math.MaxInt32
+		}
+		return strconv.Itoa(order), order
+	}
+}
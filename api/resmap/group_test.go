@@ -0,0 +1,122 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestSplitByAnnotation(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  annotations:
+    argocd.argoproj.io/sync-wave: "2"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+  annotations:
+    argocd.argoproj.io/sync-wave: "-1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: d
+  annotations:
+    argocd.argoproj.io/sync-wave: "02"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: e
+  annotations:
+    argocd.argoproj.io/sync-wave: soon
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: f
+  annotations:
+    argocd.argoproj.io/sync-wave: "0"
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	groups, err := m.SplitBy(AnnotationGroupFunc(""))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	type group struct {
+		key   string
+		names []string
+	}
+	var actual []group
+	var all []*resource.Resource
+	for _, g := range groups {
+		var names []string
+		for _, r := range g.Resources.Resources() {
+			names = append(names, r.GetName())
+		}
+		actual = append(actual, group{key: g.Key, names: names})
+		all = append(all, g.Resources.Resources()...)
+	}
+	assert.Equal(t, []group{
+		{key: "-1", names: []string{"c"}},
+		{key: "0", names: []string{"b", "f"}},
+		{key: "2", names: []string{"a", "d"}},
+		{key: "soon", names: []string{"e"}},
+	}, actual)
+	assert.ElementsMatch(t, m.Resources(), all)
+}
+
+func TestSplitBy(t *testing.T) {
+	m := New()
+	for i := 1; i <= 4; i++ {
+		doAppend(t, m, makeCm(i))
+	}
+	groups, err := m.SplitBy(func(r *resource.Resource) (string, int) {
+		if r.GetName() == "cm003" {
+			return "late", 1
+		}
+		return "early", 1
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if assert.Len(t, groups, 2) {
+		assert.Equal(t, "early", groups[0].Key)
+		assert.Equal(t, 3, groups[0].Resources.Size())
+		assert.Equal(t, "late", groups[1].Key)
+		r, err := groups[1].Resources.GetByCurrentId(makeCm(3).CurId())
+		assert.NoError(t, err)
+		orig, _ := m.GetByCurrentId(makeCm(3).CurId())
+		assert.True(t, r == orig)
+	}
+
+	_, err = m.SplitBy(func(r *resource.Resource) (string, int) {
+		return "all", len(r.GetName())
+	})
+	assert.NoError(t, err)
+	_, err = m.SplitBy(func(r *resource.Resource) (string, int) {
+		return "all", int(r.GetName()[4] - '0')
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "group 'all' given orders 1 and 2")
+	}
+	_, err = m.SplitBy(nil)
+	assert.Error(t, err)
+}
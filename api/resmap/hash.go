@@ -0,0 +1,60 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/hasher"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Hash implements ResMap.  It's the hex form of the sha256
+// of a line per resource, "<id> <digest>", in the order of
+// the ids, so that the order of the output, e.g. as a sort
+// mode leaves it, doesn't matter.
+func (m *resWrangler) Hash() (string, error) {
+	hashes, err := m.PerResourceHashes()
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, 0, len(hashes))
+	for id, h := range hashes {
+		lines = append(lines, fmt.Sprintf("%s %s\n", id, h))
+	}
+	sort.Strings(lines)
+	return hasher.Hash(strings.Join(lines, "")), nil
+}
+
+// PerResourceHashes implements ResMap.  Each digest is the
+// hex form of the sha256 of the resource as JSON, which
+// encoding/json writes with sorted map keys.
+func (m *resWrangler) PerResourceHashes() (map[resid.ResId]string, error) {
+	result := make(map[resid.ResId]string, len(m.rList))
+	for _, r := range m.rList {
+		h, err := hashContent(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hashing %s", r.CurId())
+		}
+		result[r.CurId()] = h
+	}
+	return result, nil
+}
+
+// hashContent returns the digest of the normalized
+// content of the resource.
+func hashContent(r *resource.Resource) (string, error) {
+	c := r.DeepCopy()
+	c.RemoveBuildAnnotations()
+	out, err := json.Marshal(c.Map())
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(string(out)), nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+func TestHash(t *testing.T) {
+	m := resMapFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  x: "1"
+  y: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`)
+	h, err := m.Hash()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, h, 64)
+
+	// Neither the order of the resources nor that of their
+	// fields matter, nor do the build annotations.
+	reordered := resMapFromYaml(t, `
+kind: Service
+apiVersion: v1
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  annotations:
+    config.kubernetes.io/originalName: a
+data:
+  y: "2"
+  x: "1"
+`)
+	h2, err := reordered.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, h, h2)
+
+	hashes, err := m.PerResourceHashes()
+	assert.NoError(t, err)
+	hashes2, err := reordered.PerResourceHashes()
+	assert.NoError(t, err)
+	assert.Equal(t, hashes, hashes2)
+	cm := resid.NewResId(resid.Gvk{Version: "v1", Kind: "ConfigMap"}, "a")
+	svc := resid.NewResId(resid.Gvk{Version: "v1", Kind: "Service"}, "b")
+	assert.Len(t, hashes, 2)
+	assert.NotEqual(t, hashes[cm], hashes[svc])
+
+	// A change of content changes the digests of the
+	// resource and the ResMap, not of the others.
+	changed := resMapFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  x: "1"
+  y: "3"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`)
+	h3, err := changed.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, h, h3)
+	hashes3, err := changed.PerResourceHashes()
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashes[cm], hashes3[cm])
+	assert.Equal(t, hashes[svc], hashes3[svc])
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// CollisionPolicy is what Merge does with a resource of
+// src whose CurId is that of a resource of dst.
+type CollisionPolicy int
+
+const (
+	// ErrorOnCollision fails the merge, as AppendAll does.
+	ErrorOnCollision CollisionPolicy = iota
+
+	// SkipIncoming keeps the resource of dst and drops
+	// that of src.
+	SkipIncoming
+
+	// OverwriteExisting replaces the resource of dst with
+	// that of src, in dst's place.
+	OverwriteExisting
+
+	// SmDeepMerge applies the resource of src to that of
+	// dst as a strategic merge patch, so the fields of src
+	// win, and lists with merge keys, e.g. containers,
+	// are merged by key.
+	SmDeepMerge
+)
+
+func (p CollisionPolicy) String() string {
+	switch p {
+	case ErrorOnCollision:
+		return "ErrorOnCollision"
+	case SkipIncoming:
+		return "SkipIncoming"
+	case OverwriteExisting:
+		return "OverwriteExisting"
+	case SmDeepMerge:
+		return "SmDeepMerge"
+	default:
+		return fmt.Sprintf("CollisionPolicy(%d)", int(p))
+	}
+}
+
+// MergePolicy says what Merge does with colliding
+// resources.
+type MergePolicy struct {
+	// Collision is the policy for the resources whose Gvk
+	// isn't in ByGvk.
+	Collision CollisionPolicy
+
+	// ByGvk overrides Collision for the resources of the
+	// given Gvks, e.g. to merge Namespaces both builds
+	// declare, but fail on any other collision.  A Gvk
+	// must match in full.
+	ByGvk map[resid.Gvk]CollisionPolicy
+}
+
+func (p MergePolicy) forGvk(gvk resid.Gvk) CollisionPolicy {
+	if c, found := p.ByGvk[gvk]; found {
+		return c
+	}
+	return p.Collision
+}
+
+// MergeReport says what Merge did.
+type MergeReport struct {
+	// Added are the ids of the resources of src appended
+	// to dst, in order.
+	Added []resid.ResId
+
+	// Collisions are the resources of src that collided
+	// with resources of dst, in the order of src.
+	Collisions []MergeCollision
+}
+
+// MergeCollision is a resource of src that collided with
+// one of dst, and the policy followed.
+type MergeCollision struct {
+	Id     resid.ResId
+	Policy CollisionPolicy
+}
+
+// Merge merges src, e.g. the ResMap of an application's
+// kustomization, into dst, e.g. that of the infrastructure
+// it runs on, both built independently.  The resources of
+// dst keep their order, and those of src not in dst are
+// appended in theirs.  A resource of src whose CurId is
+// that of a resource of dst is handled per the policy.
+//
+// On error, dst is left as it was.
+func Merge(dst, src ResMap, policy MergePolicy) (*MergeReport, error) {
+	report := &MergeReport{}
+	if src == nil {
+		return report, nil
+	}
+	// Everything is worked out before dst is changed, so
+	// a failure changes nothing.
+	var replacements, additions []*resource.Resource
+	var problems []string
+	for _, r := range src.Resources() {
+		id := r.CurId()
+		existing := dst.GetMatchingResourcesByCurrentId(id.Equals)
+		if len(existing) == 0 {
+			additions = append(additions, r)
+			report.Added = append(report.Added, id)
+			continue
+		}
+		if len(existing) > 1 {
+			// Not expected, as a ResMap's ids are unique.
+			problems = append(problems, fmt.Sprintf(
+				"%s matches %d resources of dst", id, len(existing)))
+			continue
+		}
+		p := policy.forGvk(id.Gvk)
+		report.Collisions = append(
+			report.Collisions, MergeCollision{Id: id, Policy: p})
+		switch p {
+		case ErrorOnCollision:
+			problems = append(problems, fmt.Sprintf(
+				"%s is in both", id))
+		case SkipIncoming:
+		case OverwriteExisting:
+			replacements = append(replacements, r)
+		case SmDeepMerge:
+			merged := existing[0].DeepCopy()
+			if err := merged.ApplySmPatch(r); err != nil {
+				problems = append(problems, fmt.Sprintf(
+					"%s can't be merged: %v", id, err))
+				continue
+			}
+			replacements = append(replacements, merged)
+		default:
+			problems = append(problems, fmt.Sprintf(
+				"%s has unknown collision policy %s", id, p))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"can't merge resources: %s", strings.Join(problems, "; "))
+	}
+	for _, r := range replacements {
+		if _, err := dst.Replace(r); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range additions {
+		if err := dst.Append(r); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
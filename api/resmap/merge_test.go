@@ -0,0 +1,274 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	. "sigs.k8s.io/kustomize/api/resmap"
+)
+
+const infrastructure = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+  labels:
+    team: platform
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1
+      - name: proxy
+        image: proxy:1
+`
+
+const application = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+  labels:
+    app: web
+`
+
+func resMaps(t *testing.T) (ResMap, ResMap) {
+	dst, err := rmF.NewResMapFromBytes([]byte(infrastructure))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	src, err := rmF.NewResMapFromBytes([]byte(application))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return dst, src
+}
+
+var (
+	namespaceGvk = resid.Gvk{Version: "v1", Kind: "Namespace"}
+	deploymentId = resid.NewResIdWithNamespace(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"},
+		"web", "shop")
+	configMapId = resid.NewResIdWithNamespace(
+		resid.Gvk{Version: "v1", Kind: "ConfigMap"}, "settings", "shop")
+	namespaceId = resid.NewResId(namespaceGvk, "shop")
+)
+
+func TestMerge(t *testing.T) {
+	testCases := map[string]struct {
+		policy     MergePolicy
+		collisions []MergeCollision
+		expected   string
+	}{
+		"skipIncoming": {
+			policy: MergePolicy{Collision: SkipIncoming},
+			collisions: []MergeCollision{
+				{Id: deploymentId, Policy: SkipIncoming},
+				{Id: namespaceId, Policy: SkipIncoming},
+			},
+			expected: `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    team: platform
+  name: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web:1
+        name: web
+      - image: proxy:1
+        name: proxy
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+`,
+		},
+		"overwriteExisting": {
+			policy: MergePolicy{Collision: OverwriteExisting},
+			collisions: []MergeCollision{
+				{Id: deploymentId, Policy: OverwriteExisting},
+				{Id: namespaceId, Policy: OverwriteExisting},
+			},
+			expected: `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    app: web
+  name: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: web:2
+        name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+`,
+		},
+		"smDeepMerge": {
+			policy: MergePolicy{Collision: SmDeepMerge},
+			collisions: []MergeCollision{
+				{Id: deploymentId, Policy: SmDeepMerge},
+				{Id: namespaceId, Policy: SmDeepMerge},
+			},
+			expected: `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    app: web
+    team: platform
+  name: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: web:2
+        name: web
+      - image: proxy:1
+        name: proxy
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+`,
+		},
+		"byGvk": {
+			policy: MergePolicy{
+				Collision: SkipIncoming,
+				ByGvk: map[resid.Gvk]CollisionPolicy{
+					namespaceGvk: SmDeepMerge,
+				},
+			},
+			collisions: []MergeCollision{
+				{Id: deploymentId, Policy: SkipIncoming},
+				{Id: namespaceId, Policy: SmDeepMerge},
+			},
+			expected: `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    app: web
+    team: platform
+  name: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: web:1
+        name: web
+      - image: proxy:1
+        name: proxy
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dst, src := resMaps(t)
+			report, err := Merge(dst, src, tc.policy)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, []resid.ResId{configMapId}, report.Added)
+			assert.Equal(t, tc.collisions, report.Collisions)
+			yml, err := dst.AsYaml()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(yml))
+		})
+	}
+}
+
+func TestMergeErrorChangesNothing(t *testing.T) {
+	dst, src := resMaps(t)
+	before := dst.DeepCopy()
+	_, err := Merge(dst, src, MergePolicy{
+		Collision: OverwriteExisting,
+		ByGvk: map[resid.Gvk]CollisionPolicy{
+			namespaceGvk: ErrorOnCollision,
+		},
+	})
+	assert.EqualError(t, err,
+		"can't merge resources: ~G_v1_Namespace|~X|shop is in both")
+	assert.NoError(t, dst.ErrorIfNotEqualLists(before))
+
+	_, err = Merge(dst, src, MergePolicy{})
+	assert.EqualError(t, err, "can't merge resources: "+
+		"apps_v1_Deployment|shop|web is in both; "+
+		"~G_v1_Namespace|~X|shop is in both")
+	assert.NoError(t, dst.ErrorIfNotEqualLists(before))
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ResourceOrdering is an order of the resources of a
+// ResMap; see ResMap.Sort.
+type ResourceOrdering interface {
+	// Less returns true if a comes before b.
+	Less(a, b *resource.Resource) bool
+}
+
+// ResourceOrderingFunc is a ResourceOrdering whose Less
+// is the function.
+type ResourceOrderingFunc func(a, b *resource.Resource) bool
+
+// Less implements ResourceOrdering.
+func (f ResourceOrderingFunc) Less(a, b *resource.Resource) bool {
+	return f(a, b)
+}
+
+var (
+	// LegacyOrdering puts the kinds that others depend on
+	// first, e.g. Namespace and CustomResourceDefinition,
+	// and webhook configurations last, as the
+	// LegacyOrderTransformer does.  Among the kinds that
+	// order doesn't rank, cluster-scoped ones come before
+	// namespaced ones.
+	LegacyOrdering ResourceOrdering = ResourceOrderingFunc(legacyLess)
+
+	// FIFOOrdering keeps the resources in the order they
+	// were appended.
+	FIFOOrdering ResourceOrdering = ResourceOrderingFunc(
+		func(_, _ *resource.Resource) bool { return false })
+
+	// GvkThenNameOrdering orders the resources by the
+	// string form of their Gvk, then by name, then by
+	// namespace.
+	GvkThenNameOrdering ResourceOrdering = ResourceOrderingFunc(gvkThenNameLess)
+)
+
+func legacyLess(a, b *resource.Resource) bool {
+	ga, gb := a.GetGvk(), b.GetGvk()
+	if ra, rb := ga.OrderRank(), gb.OrderRank(); ra != rb {
+		return ra < rb
+	}
+	if ca, cb := !a.IsNamespaceableKind(), !b.IsNamespaceableKind(); ca != cb {
+		return ca
+	}
+	if !ga.Equals(gb) {
+		return ga.String() < gb.String()
+	}
+	return a.GetCurIdFast().String() < b.GetCurIdFast().String()
+}
+
+func gvkThenNameLess(a, b *resource.Resource) bool {
+	ga, gb := a.GetGvk().String(), b.GetGvk().String()
+	if ga != gb {
+		return ga < gb
+	}
+	if a.GetName() != b.GetName() {
+		return a.GetName() < b.GetName()
+	}
+	return a.GetNamespace() < b.GetNamespace()
+}
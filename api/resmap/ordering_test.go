@@ -0,0 +1,131 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const unsortedYaml = `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: hook
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: ns
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+  namespace: ns
+---
+apiVersion: example.com/v1
+kind: ClusterWidget
+metadata:
+  name: cw
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: ns
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+
+func sortedNames(t *testing.T, ordering ResourceOrdering) []string {
+	t.Helper()
+	m, err := rmF.NewResMapFromBytes([]byte(unsortedYaml))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, m.Sort(ordering))
+	var names []string
+	for _, r := range m.Resources() {
+		names = append(names, r.GetName())
+	}
+	return names
+}
+
+func TestSortOrderings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ordering ResourceOrdering
+		expected []string
+	}{
+		"legacy": {
+			ordering: LegacyOrdering,
+			expected: []string{
+				"ns", "widgets.example.com", "a", "b", "cw", "w", "hook"},
+		},
+		"fifo": {
+			ordering: FIFOOrdering,
+			expected: []string{
+				"hook", "b", "w", "cw", "a", "ns", "widgets.example.com"},
+		},
+		"gvk then name": {
+			ordering: GvkThenNameOrdering,
+			expected: []string{
+				"hook", "widgets.example.com", "cw", "w", "a", "b", "ns"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sortedNames(t, tc.ordering))
+		})
+	}
+}
+
+func TestSortIsStable(t *testing.T) {
+	byKind := ResourceOrderingFunc(func(a, b *resource.Resource) bool {
+		return a.GetKind() < b.GetKind()
+	})
+	assert.Equal(t,
+		[]string{"cw", "b", "a", "widgets.example.com", "ns", "hook", "w"},
+		sortedNames(t, byKind))
+}
+
+func TestSortAsYaml(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, m.Sort(LegacyOrdering))
+	out, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`, string(out))
+	assert.Error(t, m.Sort(nil))
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ParallelTransform calls perResource on each resource of
+// the ResMap, from a pool of the given number of workers,
+// or of runtime.GOMAXPROCS(0) if that's not positive, for
+// transformations independent from one resource to the
+// next, e.g. adding labels.  perResource changes the
+// resource in place, so the order of the ResMap stays that
+// of its resources, whatever order they're done in.
+//
+// The first error cancels the calls not yet begun.  The
+// errors of the calls made are returned together, in the
+// order of their resources.
+func ParallelTransform(
+	m ResMap, perResource func(*resource.Resource) error,
+	workers int) error {
+	rs := m.Resources()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(rs) {
+		workers = len(rs)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make([]error, len(rs))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if ctx.Err() != nil {
+					continue
+				}
+				if errs[i] = perResource(rs[i]); errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+feed:
+	for i := range rs {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+	var messages []string
+	var first error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+		messages = append(messages,
+			fmt.Sprintf("%s: %v", rs[i].CurId(), err))
+	}
+	switch len(messages) {
+	case 0:
+		return nil
+	case 1:
+		return first
+	default:
+		return fmt.Errorf("%d resources failed to transform: %s",
+			len(messages), strings.Join(messages, "; "))
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// ClusterScopedPlacement is where PartitionByNamespace
+// puts the resources that aren't namespaceable, e.g.
+// ClusterRoles and Namespaces.
+type ClusterScopedPlacement int
+
+const (
+	// ClusterScopedInEvery puts them in every partition,
+	// e.g. for each namespace to be applied on its own.
+	ClusterScopedInEvery ClusterScopedPlacement = iota
+
+	// ClusterScopedInOne puts them only in the partition
+	// of ClusterScopedPolicy.Partition, a namespace, e.g.
+	// the one applied with cluster-wide credentials.
+	ClusterScopedInOne
+
+	// ClusterScopedSeparate puts them in a partition of
+	// their own, that of ClusterScopedPolicy.Partition, or
+	// of resid.TotallyNotANamespace if that's empty.
+	ClusterScopedSeparate
+)
+
+// ClusterScopedPolicy says where PartitionByNamespace puts
+// the resources that aren't namespaceable.
+type ClusterScopedPolicy struct {
+	Placement ClusterScopedPlacement
+
+	// Partition is the key of the partition for
+	// ClusterScopedInOne and ClusterScopedSeparate.
+	Partition string
+}
+
+// PartitionByNamespace implements ResMap.
+func (m *resWrangler) PartitionByNamespace(
+	policy ClusterScopedPolicy) (map[string]ResMap, error) {
+	namespaces := sortedKeys(m.GroupedByCurrentNamespace())
+	clusterScopedKeys, err := policy.keys(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]ResMap)
+	add := func(key string, r *resource.Resource) error {
+		p, found := result[key]
+		if !found {
+			p = newOne()
+			result[key] = p
+		}
+		return p.Append(r)
+	}
+	// Adding the resources in order keeps it in each
+	// partition, the cluster-scoped resources included.
+	for _, r := range m.rList {
+		ns := r.GetCurIdFast().EffectiveNamespace()
+		if ns != resid.TotallyNotANamespace {
+			if err = add(ns, r); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, key := range clusterScopedKeys {
+			if err = add(key, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// keys returns the keys of the partitions to hold the
+// cluster-scoped resources, given the namespaces of the
+// others.
+func (p ClusterScopedPolicy) keys(namespaces []string) ([]string, error) {
+	switch p.Placement {
+	case ClusterScopedInEvery:
+		if len(namespaces) == 0 {
+			// With no partition to go in, they'd be lost.
+			return []string{resid.DefaultNamespace}, nil
+		}
+		return namespaces, nil
+	case ClusterScopedInOne:
+		if p.Partition == "" {
+			return nil, fmt.Errorf(
+				"no partition given for the cluster-scoped resources")
+		}
+		return []string{p.Partition}, nil
+	case ClusterScopedSeparate:
+		key := p.Partition
+		if key == "" {
+			key = resid.TotallyNotANamespace
+		}
+		for _, ns := range namespaces {
+			if ns == key {
+				return nil, fmt.Errorf(
+					"partition '%s' of the cluster-scoped resources "+
+						"is a namespace of the others", key)
+			}
+		}
+		return []string{key}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown cluster-scoped placement %d", p.Placement)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestPartitionByNamespace(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: shop
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+  namespace: shop
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	names := func(p map[string]ResMap) map[string][]string {
+		result := make(map[string][]string)
+		var all []*resource.Resource
+		for key, rm := range p {
+			for _, r := range rm.Resources() {
+				result[key] = append(result[key], r.GetName())
+				all = append(all, r)
+			}
+		}
+		// The union of the partitions is the ResMap.
+		assert.Subset(t, all, m.Resources())
+		assert.Subset(t, m.Resources(), all)
+		return result
+	}
+	testCases := map[string]struct {
+		policy   ClusterScopedPolicy
+		expected map[string][]string
+	}{
+		"inEvery": {
+			policy: ClusterScopedPolicy{Placement: ClusterScopedInEvery},
+			expected: map[string][]string{
+				"shop":    {"a", "shop", "reader", "c"},
+				"default": {"shop", "b", "reader"},
+			},
+		},
+		"inOne": {
+			policy: ClusterScopedPolicy{
+				Placement: ClusterScopedInOne, Partition: "shop"},
+			expected: map[string][]string{
+				"shop":    {"a", "shop", "reader", "c"},
+				"default": {"b"},
+			},
+		},
+		"inOneOfItsOwn": {
+			policy: ClusterScopedPolicy{
+				Placement: ClusterScopedInOne, Partition: "admin"},
+			expected: map[string][]string{
+				"shop":    {"a", "c"},
+				"default": {"b"},
+				"admin":   {"shop", "reader"},
+			},
+		},
+		"separate": {
+			policy: ClusterScopedPolicy{Placement: ClusterScopedSeparate},
+			expected: map[string][]string{
+				"shop":                     {"a", "c"},
+				"default":                  {"b"},
+				resid.TotallyNotANamespace: {"shop", "reader"},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p, err := m.PartitionByNamespace(tc.policy)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expected, names(p))
+		})
+	}
+}
+
+func shopNamespace() *resource.Resource {
+	return rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": "shop",
+		},
+	})
+}
+
+func TestPartitionByNamespaceOnlyClusterScoped(t *testing.T) {
+	m := New()
+	doAppend(t, m, shopNamespace())
+	p, err := m.PartitionByNamespace(
+		ClusterScopedPolicy{Placement: ClusterScopedInEvery})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if assert.Len(t, p, 1) {
+		assert.Equal(t, 1, p[resid.DefaultNamespace].Size())
+	}
+}
+
+func TestPartitionByNamespaceErrors(t *testing.T) {
+	m := New()
+	doAppend(t, m, makeCm(1))
+	doAppend(t, m, shopNamespace())
+	_, err := m.PartitionByNamespace(
+		ClusterScopedPolicy{Placement: ClusterScopedInOne})
+	assert.EqualError(t, err,
+		"no partition given for the cluster-scoped resources")
+	_, err = m.PartitionByNamespace(ClusterScopedPolicy{
+		Placement: ClusterScopedSeparate, Partition: "default"})
+	assert.EqualError(t, err,
+		"partition 'default' of the cluster-scoped resources "+
+			"is a namespace of the others")
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// PatchConflict is a field that two strategic merge patches
+// of one kustomization write with different values on one
+// resource, so that the later one silently wins.
+type PatchConflict struct {
+	// Id is the CurId of the resource when the second
+	// patch was applied.
+	Id resid.ResId
+
+	// Path is the path of the field, as given by PatchFields.
+	Path string
+
+	// First and Second are the patches, in the order they
+	// were applied.
+	First  Source
+	Second Source
+}
+
+func (c PatchConflict) String() string {
+	return fmt.Sprintf(
+		"patches %s and %s in %s set %s of %s/%s to different values",
+		describePatch(c.First), describePatch(c.Second),
+		c.First.KustomizationRoot, c.Path, c.Id.Kind, c.Id.Name)
+}
+
+func describePatch(s Source) string {
+	if s.PatchFile == "" {
+		return "(inline)"
+	}
+	return "'" + s.PatchFile + "'"
+}
+
+// PatchConflicts records the fields that the strategic
+// merge patches of a build write on each resource, to find
+// the PatchConflicts between them.  Patches of different
+// kustomizations don't conflict, as an overlay is expected
+// to override the patches of its bases.
+type PatchConflicts struct {
+	// strict makes a conflict an error.
+	strict bool
+	// written maps each patched resource, then each field
+	// path, to the value and source of the last write.
+	written   map[*resource.Resource]map[string]patchWrite
+	conflicts []PatchConflict
+}
+
+type patchWrite struct {
+	value  string
+	source Source
+}
+
+// NewPatchConflicts returns an empty PatchConflicts.  If
+// strict, Check fails on a conflict, rather than only
+// recording it.
+func NewPatchConflicts(strict bool) *PatchConflicts {
+	return &PatchConflicts{
+		strict:  strict,
+		written: make(map[*resource.Resource]map[string]patchWrite),
+	}
+}
+
+// Check is to be called before the given strategic merge
+// patch, from src, is applied to the given targets.  It
+// records the fields the patch writes, per PatchFields,
+// and the conflicts with the fields earlier patches of the
+// same kustomization wrote; writing the same value isn't a
+// conflict.  On nil PatchConflicts, it does nothing, so
+// callers needn't check whether conflicts are detected.
+func (c *PatchConflicts) Check(
+	targets []*resource.Resource, patch *resource.Resource, src Source) error {
+	if c == nil {
+		return nil
+	}
+	fields := PatchFields(patch)
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var found []PatchConflict
+	for _, target := range targets {
+		if c.written[target] == nil {
+			c.written[target] = make(map[string]patchWrite)
+		}
+		for _, path := range paths {
+			old, wasWritten := c.written[target][path]
+			if wasWritten && old.value == fields[path] {
+				// The first patch writing the value keeps it.
+				continue
+			}
+			if wasWritten &&
+				old.source.KustomizationRoot == src.KustomizationRoot {
+				found = append(found, PatchConflict{
+					Id: target.CurId(), Path: path,
+					First: old.source, Second: src,
+				})
+			}
+			c.written[target][path] = patchWrite{value: fields[path], source: src}
+		}
+	}
+	c.conflicts = append(c.conflicts, found...)
+	if c.strict && len(found) > 0 {
+		return fmt.Errorf("conflicting patches: %s", found[0])
+	}
+	return nil
+}
+
+// List returns the conflicts found, in the order found.
+func (c *PatchConflicts) List() []PatchConflict {
+	if c == nil {
+		return nil
+	}
+	return c.conflicts
+}
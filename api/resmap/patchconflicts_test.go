@@ -0,0 +1,117 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func makePatch(t *testing.T, y string) *resource.Resource {
+	t.Helper()
+	r, err := rf.FromBytes([]byte(y))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return r
+}
+
+func TestPatchFields(t *testing.T) {
+	patch := makePatch(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: frontend
+spec:
+  replicas: 3
+  selector: null
+  template:
+    spec:
+      $setElementOrder/containers:
+      - name: web
+      containers:
+      - name: web
+        args: [--quiet]
+        resources:
+          limits:
+            cpu: "1"
+      - name: sidecar
+        $patch: delete
+      volumes:
+      - emptyDir: {}
+`)
+	assert.Equal(t, map[string]string{
+		"metadata/labels/tier": "string:frontend",
+		"spec/replicas":        "int:3",
+		"spec/selector":        "<nil>:<nil>",
+		"spec/template/spec/containers/[name=web]/name":                 "string:web",
+		"spec/template/spec/containers/[name=web]/args":                 "[]interface {}:[--quiet]",
+		"spec/template/spec/containers/[name=web]/resources/limits/cpu": "string:1",
+		"spec/template/spec/containers/[name=sidecar]":                  "<nil>:<nil>",
+		"spec/template/spec/volumes":                                    "[]interface {}:[map[emptyDir:map[]]]",
+	}, PatchFields(patch))
+}
+
+func TestPatchConflicts(t *testing.T) {
+	target := makePatch(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	targets := []*resource.Resource{target}
+	cpu := func(value string) *resource.Resource {
+		return makePatch(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          limits:
+            cpu: "`+value+`"
+`)
+	}
+	a := Source{PatchFile: "a.yaml", KustomizationRoot: "/app"}
+	b := Source{PatchFile: "b.yaml", KustomizationRoot: "/app"}
+	overlay := Source{KustomizationRoot: "/overlay"}
+
+	c := NewPatchConflicts(false)
+	assert.NoError(t, c.Check(targets, cpu("1"), a))
+	// The same value isn't a conflict.
+	assert.NoError(t, c.Check(targets, cpu("1"), b))
+	assert.Empty(t, c.List())
+	assert.NoError(t, c.Check(targets, cpu("2"), b))
+	// Nor is overriding the patches of another kustomization.
+	assert.NoError(t, c.Check(targets, cpu("3"), overlay))
+	if assert.Len(t, c.List(), 1) {
+		assert.Equal(t,
+			"patches 'a.yaml' and 'b.yaml' in /app set "+
+				"spec/template/spec/containers/[name=web]/resources/limits/cpu "+
+				"of Deployment/web to different values",
+			c.List()[0].String())
+	}
+
+	c = NewPatchConflicts(true)
+	assert.NoError(t, c.Check(targets, cpu("1"), a))
+	err := c.Check(targets, cpu("2"), Source{KustomizationRoot: "/app"})
+	assert.EqualError(t, err,
+		"conflicting patches: patches 'a.yaml' and (inline) in /app set "+
+			"spec/template/spec/containers/[name=web]/resources/limits/cpu "+
+			"of Deployment/web to different values")
+
+	// Nil PatchConflicts check nothing.
+	c = nil
+	assert.NoError(t, c.Check(targets, cpu("1"), a))
+	assert.Empty(t, c.List())
+}
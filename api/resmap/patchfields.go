@@ -0,0 +1,105 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// PatchFields returns the fields that the given strategic
+// merge patch writes, mapping the path of each to a
+// printout of the value it writes, e.g. to compare what two
+// patches of a resource write before applying them.
+//
+// Paths are slash separated, as in Provenance, but as the
+// patch doesn't know where in the target its list items
+// land, the items of lists merged by name are given by
+// name, e.g. spec/template/spec/containers/[name=web]/image.
+// Other lists replace the target's, so they're one field.
+// A field the patch deletes, via null or a $patch: delete
+// directive, is written with nil; the other directives are
+// skipped, as are the fields naming the target.
+func PatchFields(patch *resource.Resource) map[string]string {
+	result := make(map[string]string)
+	patchFieldsInto(result, "", patch.Map())
+	return result
+}
+
+// patchFieldIdentifiers are the fields of a patch that
+// identify its target, rather than being written to it.
+var patchFieldIdentifiers = map[string]bool{
+	"apiVersion":         true,
+	"kind":               true,
+	"metadata/name":      true,
+	"metadata/namespace": true,
+}
+
+func patchFieldsInto(result map[string]string, path string, v interface{}) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "/" + key
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		switch x["$patch"] {
+		case "delete":
+			result[path] = printPatchValue(nil)
+			return
+		case "replace":
+			result[path] = printPatchValue(x)
+			return
+		}
+		if len(x) == 0 && path != "" {
+			result[path] = printPatchValue(x)
+		}
+		for k, item := range x {
+			if strings.HasPrefix(k, "$") || patchFieldIdentifiers[join(k)] {
+				continue
+			}
+			patchFieldsInto(result, join(k), item)
+		}
+	case []interface{}:
+		names, merged := itemNames(x)
+		if !merged {
+			result[path] = printPatchValue(x)
+			return
+		}
+		for i, item := range x {
+			patchFieldsInto(result, join("[name="+names[i]+"]"), item)
+		}
+	default:
+		result[path] = printPatchValue(v)
+	}
+}
+
+// itemNames returns the names of the items of the given
+// list, and whether it's merged by name, i.e. all its items
+// are maps with a name.
+func itemNames(list []interface{}) ([]string, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	names := make([]string, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if names[i], ok = m["name"].(string); !ok {
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+// printPatchValue prints a value in the form flattenFields
+// uses, with maps printed in key order.
+func printPatchValue(v interface{}) string {
+	return fmt.Sprintf("%T:%v", v, v)
+}
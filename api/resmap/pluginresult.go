@@ -0,0 +1,59 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// PluginResult is a finding a plugin reports without
+// failing the build, e.g. a change that a transformer in
+// an audit mode would have made.
+type PluginResult struct {
+	// Plugin is the kind of the plugin,
+	// e.g. RegistryRewriteTransformer.
+	Plugin string
+
+	// Resource is the resource the finding is about.
+	Resource resid.ResId
+
+	// Message says what was found.
+	Message string
+
+	// CurrentValue is the value of the field the finding
+	// is about, if any, and SuggestedValue the value the
+	// plugin would give it.
+	CurrentValue   string
+	SuggestedValue string
+}
+
+func (r PluginResult) String() string {
+	return fmt.Sprintf("%s: %s: %s", r.Plugin, r.Resource, r.Message)
+}
+
+// AddPluginResult records a result of a plugin using
+// this factory.
+func (rmF *Factory) AddPluginResult(r PluginResult) {
+	rmF.pluginResults = append(rmF.pluginResults, r)
+}
+
+// PluginResults returns the results that plugins using
+// this factory recorded, in the order recorded.
+func (rmF *Factory) PluginResults() []PluginResult {
+	return rmF.pluginResults
+}
+
+// AddWarning records a warning about the build made by a
+// transformer using this factory, for the caller to report.
+func (rmF *Factory) AddWarning(w string) {
+	rmF.warnings = append(rmF.warnings, w)
+}
+
+// Warnings returns the warnings that transformers using
+// this factory made, in the order made.
+func (rmF *Factory) Warnings() []string {
+	return rmF.warnings
+}
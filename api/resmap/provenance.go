@@ -0,0 +1,213 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// Source identifies the patch, or the builtin transformer,
+// that wrote a field.
+type Source struct {
+	// PatchFile is the file holding the patch, relative
+	// to KustomizationRoot, or empty for an inline patch.
+	// Strategic merge patches from several files that
+	// target the same resource are merged before they're
+	// applied, so for these it lists each of the files,
+	// comma separated.
+	PatchFile string
+
+	// Transformer is the kind of the builtin transformer
+	// that wrote the field, e.g. LabelTransformer, or empty
+	// for a patch.
+	Transformer string
+
+	// KustomizationRoot is the directory of the
+	// kustomization file that declared the patch, or
+	// configured the transformer.
+	KustomizationRoot string
+}
+
+// ProvenanceTable records which patch, or transformer, last
+// wrote each field of each resource in a build.  It's a
+// side table, keyed by resource and field path, that holds
+// only the paths these wrote, never whole documents.  Paths
+// are slash separated, with list items given by index, e.g.
+// spec/template/spec/containers/0/image
+type ProvenanceTable struct {
+	fields map[*resource.Resource]map[string]Source
+}
+
+// NewProvenanceTable returns an empty ProvenanceTable.
+func NewProvenanceTable() *ProvenanceTable {
+	return &ProvenanceTable{
+		fields: make(map[*resource.Resource]map[string]Source),
+	}
+}
+
+// RecordChanges calls apply, which is expected to patch the
+// given resources, and records src as the source of every
+// field apply added or changed, in the table and in the
+// Provenance of each resource it changed.  A field that a
+// RecordChanges nested in apply recorded keeps the source
+// that one gave it, e.g. the patch file a PatchTransformer
+// applied.  On a nil table, it just calls apply, so callers
+// needn't check whether provenance is tracked.
+func (t *ProvenanceTable) RecordChanges(
+	resources []*resource.Resource, src Source, apply func() error) error {
+	if t == nil {
+		return apply()
+	}
+	before := make([]map[string]string, len(resources))
+	sources := make([]map[string]Source, len(resources))
+	for i, r := range resources {
+		before[i] = flattenFields(r)
+		sources[i] = make(map[string]Source, len(t.fields[r]))
+		for path, s := range t.fields[r] {
+			sources[i][path] = s
+		}
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	for i, r := range resources {
+		var paths []string
+		for path, value := range flattenFields(r) {
+			if old, found := before[i][path]; found && old == value {
+				continue
+			}
+			if s, found := t.fields[r][path]; found && s != sources[i][path] {
+				continue
+			}
+			t.record(r, path, src)
+			paths = append(paths, path)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		sort.Strings(paths)
+		r.AppendProvenance(resource.ProvenanceRecord{
+			Transformer:       src.Transformer,
+			PatchFile:         src.PatchFile,
+			KustomizationRoot: src.KustomizationRoot,
+			Fields:            paths,
+		})
+	}
+	return nil
+}
+
+func (t *ProvenanceTable) record(
+	r *resource.Resource, path string, src Source) {
+	if t.fields[r] == nil {
+		t.fields[r] = make(map[string]Source)
+	}
+	t.fields[r][path] = src
+}
+
+// Forget drops the record of the field at the given path
+// of the given resource, e.g. once the field is removed.
+func (t *ProvenanceTable) Forget(r *resource.Resource, path string) {
+	if t == nil {
+		return
+	}
+	delete(t.fields[r], path)
+}
+
+// Lookup returns the source of the patch, or transformer,
+// that last wrote the field at the given path of the given
+// resource.
+func (t *ProvenanceTable) Lookup(
+	r *resource.Resource, path string) (Source, bool) {
+	if t == nil {
+		return Source{}, false
+	}
+	src, found := t.fields[r][path]
+	return src, found
+}
+
+// Paths returns the sorted paths of the given
+// resource's fields that the table has a source of.
+func (t *ProvenanceTable) Paths(r *resource.Resource) []string {
+	if t == nil {
+		return nil
+	}
+	var paths []string
+	for path := range t.fields[r] {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SetProvenance implements ResMap.
+func (m *resWrangler) SetProvenance(t *ProvenanceTable) {
+	m.provenance = t
+}
+
+// Provenance implements ResMap.
+func (m *resWrangler) Provenance(
+	id resid.ResId, path string) (Source, bool) {
+	r, err := m.GetByCurrentId(id)
+	if err != nil {
+		return Source{}, false
+	}
+	return m.provenance.Lookup(r, path)
+}
+
+// flattenFields maps the path of every leaf of the given
+// resource, i.e. every scalar, empty map and empty list, to
+// a printout of its value.  The annotations kustomize uses
+// to track ids are skipped, as no patch writes them.
+func flattenFields(r *resource.Resource) map[string]string {
+	c := r.DeepCopy()
+	c.RemoveIdAnnotations()
+	leaves := make(map[string]interface{})
+	flattenInto(leaves, "", c.Map())
+	result := make(map[string]string, len(leaves))
+	for path, v := range leaves {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			result[path] = "{}"
+		case []interface{}:
+			result[path] = "[]"
+		default:
+			result[path] = fmt.Sprintf("%T:%v", x, x)
+		}
+	}
+	return result
+}
+
+// flattenInto maps the path of every leaf of v, below the
+// given path, to its value in result.
+func flattenInto(result map[string]interface{}, path string, v interface{}) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "/" + key
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 && path != "" {
+			result[path] = x
+		}
+		for k, item := range x {
+			flattenInto(result, join(k), item)
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			result[path] = x
+		}
+		for i, item := range x {
+			flattenInto(result, join(strconv.Itoa(i)), item)
+		}
+	default:
+		result[path] = v
+	}
+}
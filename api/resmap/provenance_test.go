@@ -0,0 +1,106 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestProvenanceTableRecordChanges(t *testing.T) {
+	r := rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "cm",
+		},
+		"data": map[string]interface{}{
+			"a": "x",
+			"b": "y",
+		},
+	})
+	src := Source{PatchFile: "patch.yaml", KustomizationRoot: "/app"}
+	tbl := NewProvenanceTable()
+	err := tbl.RecordChanges([]*resource.Resource{r}, src, func() error {
+		r.SetDataMap(map[string]string{"a": "x", "b": "z", "c": "w"})
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data/b", "data/c"}, tbl.Paths(r))
+	found, ok := tbl.Lookup(r, "data/b")
+	assert.True(t, ok)
+	assert.Equal(t, src, found)
+	_, ok = tbl.Lookup(r, "data/a")
+	assert.False(t, ok)
+
+	// A failed patch records nothing.
+	err = tbl.RecordChanges([]*resource.Resource{r}, src, func() error {
+		r.SetDataMap(map[string]string{"a": "v"})
+		return fmt.Errorf("oops")
+	})
+	assert.Error(t, err)
+	_, ok = tbl.Lookup(r, "data/a")
+	assert.False(t, ok)
+}
+
+func TestProvenanceTableNestedRecordChanges(t *testing.T) {
+	r := rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "cm",
+		},
+	})
+	outer := Source{Transformer: "PatchTransformer", KustomizationRoot: "/app"}
+	inner := Source{PatchFile: "patch.yaml", KustomizationRoot: "/app"}
+	tbl := NewProvenanceTable()
+	rs := []*resource.Resource{r}
+	err := tbl.RecordChanges(rs, outer, func() error {
+		r.SetNamespace("ns")
+		return tbl.RecordChanges(rs, inner, func() error {
+			r.SetDataMap(map[string]string{"a": "x"})
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	// The inner source, knowing the patch file, is kept.
+	found, _ := tbl.Lookup(r, "data/a")
+	assert.Equal(t, inner, found)
+	found, _ = tbl.Lookup(r, "metadata/namespace")
+	assert.Equal(t, outer, found)
+	assert.Equal(t, []resource.ProvenanceRecord{
+		{
+			PatchFile:         "patch.yaml",
+			KustomizationRoot: "/app",
+			Fields:            []string{"data/a"},
+		},
+		{
+			Transformer:       "PatchTransformer",
+			KustomizationRoot: "/app",
+			Fields:            []string{"metadata/namespace"},
+		},
+	}, r.Provenance())
+	assert.Equal(t, r.Provenance(), r.DeepCopy().Provenance())
+}
+
+func TestProvenanceTableNil(t *testing.T) {
+	var tbl *ProvenanceTable
+	called := false
+	err := tbl.RecordChanges(nil, Source{}, func() error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+	_, ok := tbl.Lookup(nil, "data/a")
+	assert.False(t, ok)
+
+	m := rmF.FromResource(makeCm(1))
+	_, ok = m.Provenance(makeCm(1).CurId(), "metadata/name")
+	assert.False(t, ok)
+}
@@ -6,10 +6,13 @@
 package resmap
 
 import (
+	"io"
+
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -25,6 +28,13 @@ type Generator interface {
 	Generate() (ResMap, error)
 }
 
+// A ContextualGenerator is a Generator that can see the
+// resources accumulated so far.  It must not modify them;
+// like that of any Generator, its output is purely additive.
+type ContextualGenerator interface {
+	GenerateWithContext(m ResMap) (ResMap, error)
+}
+
 // Something that's configurable accepts an
 // instance of PluginHelpers and a raw config
 // object (YAML in []byte form).
@@ -33,8 +43,11 @@ type Configurable interface {
 }
 
 // NewPluginHelpers makes an instance of PluginHelpers.
-func NewPluginHelpers(ldr ifc.Loader, v ifc.Validator, rf *Factory) *PluginHelpers {
-	return &PluginHelpers{ldr: ldr, v: v, rf: rf}
+// The schema is that of the build's OpenAPI definitions,
+// e.g. NewSchema of those of the factory's resources.
+func NewPluginHelpers(
+	ldr ifc.Loader, v ifc.Validator, rf *Factory, s Schema) *PluginHelpers {
+	return &PluginHelpers{ldr: ldr, v: v, rf: rf, s: s}
 }
 
 // PluginHelpers holds things that any or all plugins might need.
@@ -44,6 +57,7 @@ type PluginHelpers struct {
 	ldr ifc.Loader
 	v   ifc.Validator
 	rf  *Factory
+	s   Schema
 }
 
 func (c *PluginHelpers) Loader() ifc.Loader {
@@ -58,6 +72,13 @@ func (c *PluginHelpers) Validator() ifc.Validator {
 	return c.v
 }
 
+// Schema returns the OpenAPI definitions of the build,
+// e.g. to merge lists by their merge keys as the builtin
+// strategic merge patches do.
+func (c *PluginHelpers) Schema() Schema {
+	return c.s
+}
+
 type GeneratorPlugin interface {
 	Generator
 	Configurable
@@ -68,6 +89,12 @@ type TransformerPlugin interface {
 	Configurable
 }
 
+// IdPair holds the ids of a resource.
+type IdPair struct {
+	Org resid.ResId
+	Cur resid.ResId
+}
+
 // ResMap is an interface describing operations on the
 // core kustomize data structure, a list of Resources.
 //
@@ -125,9 +152,55 @@ type ResMap interface {
 	// self, then its behavior _cannot_ be merge or replace.
 	AbsorbAll(ResMap) error
 
+	// AbsorbAllWith is AbsorbAll, with the given strategy
+	// deciding what to do with each resource of the other
+	// ResMap whose id is in self, e.g. merging ConfigMaps
+	// but replacing Deployments.  AbsorbAll uses the
+	// DefaultConflictStrategy.  Whatever the strategy, a
+	// resource whose behavior is merge or replace must
+	// collide.
+	AbsorbAllWith(ResMap, ConflictStrategy) error
+
 	// AsYaml returns the yaml form of resources.
 	AsYaml() ([]byte, error)
 
+	// AsYamlWithDirectives is like AsYaml, but writes the
+	// YAML directives of each resource that has any, e.g.
+	// %YAML 1.1, before it; see Resource.GetDirectives.
+	AsYamlWithDirectives() ([]byte, error)
+
+	// WriteYaml writes what AsYaml returns to the given
+	// writer, a resource at a time, flushing the writer
+	// between them if it has a Flush() error method, so
+	// that the output needn't all be in memory at once.
+	// It stops at the first resource that fails to
+	// serialize, returning the error.
+	WriteYaml(io.Writer) error
+
+	// WriteYamlWithDirectives is WriteYaml, writing what
+	// AsYamlWithDirectives returns.
+	WriteYamlWithDirectives(io.Writer) error
+
+	// AsJson returns the resources as a JSON array of
+	// objects, in order, e.g. for jq or a policy engine.
+	// Values keep their types, and YAML aliases are
+	// resolved, as in AsYaml.
+	AsJson() ([]byte, error)
+
+	// Hash returns a digest of the content of the resources,
+	// for telling cheaply whether the output of a build
+	// changed; see PerResourceHashes.  It doesn't depend on
+	// the order of the resources.
+	Hash() (string, error)
+
+	// PerResourceHashes returns a digest of the content of
+	// each resource, keyed by its CurId.  The content is
+	// normalized first: the annotations kustomize uses
+	// during a build are dropped, and map keys are sorted,
+	// so fields may be reordered without changing the
+	// digest.  The digests are stable across patch releases.
+	PerResourceHashes() (map[resid.ResId]string, error)
+
 	// GetByIndex returns a resource at the given index,
 	// nil if out of range.
 	GetByIndex(int) *resource.Resource
@@ -168,48 +241,178 @@ type ResMap interface {
 	// are not included at all (see NonNamespaceable).
 	// Resources with an empty namespace are placed
 	// in the resid.DefaultNamespace entry.
+	// Each slice keeps the order of the ResMap.  Ranging
+	// over the map gives the namespaces in no fixed order;
+	// range over CurrentNamespaces instead, e.g. to write a
+	// file per namespace that doesn't change between runs.
 	GroupedByCurrentNamespace() map[string][]*resource.Resource
 
-	// GroupByOrginalNamespace performs as GroupByNamespace
-	// but use the original namespace instead of the current
-	// one to perform the grouping.
+	// CurrentNamespaces returns the keys of
+	// GroupedByCurrentNamespace, sorted.
+	CurrentNamespaces() []string
+
+	// GroupedByOriginalNamespace performs as
+	// GroupedByCurrentNamespace but uses the original
+	// namespace instead of the current one to perform
+	// the grouping.
 	GroupedByOriginalNamespace() map[string][]*resource.Resource
 
+	// OriginalNamespaces returns the keys of
+	// GroupedByOriginalNamespace, sorted.
+	OriginalNamespaces() []string
+
 	// NonNamespaceable returns a slice of resources that
 	// cannot be placed in a namespace, e.g.
 	// Node, ClusterRole, Namespace itself, etc.
+	// The slice keeps the order of the ResMap, so it's the
+	// same for the same ResMap.
 	NonNamespaceable() []*resource.Resource
 
-	// AllIds returns all CurrentIds.
+	// GroupedByKind returns a map of kind to a slice
+	// of *Resource of that kind, namespaced or not.
+	// Each slice keeps the order of the ResMap.
+	GroupedByKind() map[string][]*resource.Resource
+
+	// GroupedByCurrentGvk returns a map of normalized
+	// current Gvk to a slice of *Resource of that Gvk,
+	// e.g. to write a file per kind.
+	// Each slice keeps the order of the ResMap.
+	GroupedByCurrentGvk() map[resid.Gvk][]*resource.Resource
+
+	// GroupedByOriginalGvk performs as GroupedByCurrentGvk
+	// but uses the original Gvk (see Resource.OrgGvk), which
+	// differs from the current one if e.g. a patch changed
+	// the apiVersion.
+	GroupedByOriginalGvk() map[resid.Gvk][]*resource.Resource
+
+	// AllIds returns the CurIds of the resources,
+	// in the order the resources were appended.
 	AllIds() []resid.ResId
 
+	// AllOriginalIds returns the OrgIds of the resources,
+	// in the order the resources were appended.  Unlike
+	// CurIds, two of them may be equal.
+	AllOriginalIds() []resid.ResId
+
+	// IdPairs returns the OrgId and CurId of each of the
+	// resources, in the order the resources were appended,
+	// e.g. to map the ids of a base to those of an overlay.
+	IdPairs() []IdPair
+
 	// Replace replaces the resource with the matching CurId.
 	// Error if there's no match or more than one match.
 	// Returns the index where the replacement happened.
 	Replace(*resource.Resource) (int, error)
 
+	// ReplaceMatchingId replaces the resource whose CurId
+	// matches with the given one, which may have another id,
+	// e.g. to replace a resource found by a name pattern.
+	// Error if there's no match or more than one match, or
+	// if the given resource has the CurId of another.
+	ReplaceMatchingId(matches IdMatcher, r *resource.Resource) error
+
+	// Rename calls mutate on the resource with the given CurId,
+	// which may change its identity (name, namespace or Gvk).
+	// Since a resource's CurId is read from its data, code that
+	// changes the identity of a resource in a ResMap should do
+	// so via Rename, so that a collision of the new CurId with
+	// that of another resource is an error here rather than a
+	// mystery later.  On a collision, or an error from mutate,
+	// the resource's name, namespace and Gvk are restored, but
+	// not other changes mutate made.  Error if there's no match
+	// or more than one.
+	Rename(id resid.ResId, mutate func(*resource.Resource) error) error
+
 	// Remove removes the resource whose CurId matches the argument.
 	// Error if not found.
 	Remove(resid.ResId) error
 
+	// RemoveMatchingId removes every resource whose CurId
+	// matches, in one step, returning their CurIds in the
+	// order they were in.  Matching no resource isn't an
+	// error; callers requiring a match check what's removed.
+	RemoveMatchingId(matches IdMatcher) (removed []resid.ResId, err error)
+
+	// RemoveBySelector removes every resource the selector
+	// selects, as Select would, returning how many were
+	// removed.  Selecting none isn't an error; a selector
+	// that doesn't parse, e.g. a bad labelSelector, is.
+	// Unlike Select, resources differing only by version
+	// are all removed rather than being an error.
+	RemoveBySelector(types.Selector) (int, error)
+
 	// Clear removes all resources and Ids.
 	Clear()
 
+	// Sort orders the resources per the ordering, e.g.
+	// LegacyOrdering.  The sort is stable: resources the
+	// ordering doesn't order keep their current order.
+	// Until a ResMap is sorted, its resources are in the
+	// order they were appended.
+	Sort(ordering ResourceOrdering) error
+
+	// SplitBy partitions the resources into groups, each
+	// of the resources fn gives the same key, e.g. to apply
+	// them in waves; see AnnotationGroupFunc.  The groups
+	// are sorted by the order fn gives them, groups of equal
+	// order by their first resource in the ResMap, and each
+	// keeps the order of the ResMap.  So concatenated, they
+	// hold each resource once.  Error if fn gives one key
+	// two orders.
+	SplitBy(fn GroupFunc) ([]Group, error)
+
+	// PartitionByNamespace partitions the resources by
+	// their current namespace, as GroupedByCurrentNamespace
+	// groups them, e.g. for each namespace to be applied
+	// with its own credentials.  So a namespaceable resource
+	// with an empty namespace is in the resid.DefaultNamespace
+	// partition.  The policy says where the resources that
+	// aren't namespaceable go.  Each partition keeps the
+	// order of the ResMap, holding its resources, not
+	// copies, so a cluster-scoped resource in several
+	// partitions is one resource.  Under every policy, the
+	// union of the partitions is the resources of the ResMap,
+	// with no id twice in a partition.
+	PartitionByNamespace(
+		policy ClusterScopedPolicy) (map[string]ResMap, error)
+
 	// SubsetThatCouldBeReferencedByResource returns a ResMap subset
 	// of self with resources that could be referenced by the
 	// resource argument.
 	// This is a filter; it excludes things that cannot be
 	// referenced by the resource, e.g. objects in other
-	// namespaces. Cluster wide objects are never excluded.
+	// namespaces. Cluster wide objects are never excluded,
+	// nor are objects in the namespaces the resource names
+	// in a cross namespace reference field, e.g. a
+	// RoleBinding subject's namespace; see
+	// builtinpluginconsts.GetCrossNamespaceReferenceFieldSpecs.
 	SubsetThatCouldBeReferencedByResource(*resource.Resource) ResMap
 
 	// DeepCopy copies the ResMap and underlying resources.
 	DeepCopy() ResMap
 
+	// LazyCopy is DeepCopy, but each resource of the copy
+	// shares its data with the resource it copies until
+	// either is changed, and only then is the data copied.
+	// It's much cheaper than DeepCopy if few resources
+	// are changed, e.g. by a transformer selecting a few.
+	LazyCopy() ResMap
+
 	// ShallowCopy copies the ResMap but
 	// not the underlying resources.
+	// The copy shares the ProvenanceTable, if any.
 	ShallowCopy() ResMap
 
+	// Provenance returns the source of the patch, or the
+	// builtin label or annotation transformer, that last
+	// wrote the field at the given path (e.g. spec/replicas)
+	// of the resource with the given CurId.  It finds nothing
+	// unless a ProvenanceTable was set via SetProvenance.
+	Provenance(id resid.ResId, path string) (Source, bool)
+
+	// SetProvenance sets the table consulted by Provenance.
+	SetProvenance(t *ProvenanceTable)
+
 	// ErrorIfNotEqualSets returns an error if the
 	// argument doesn't have the same resources as self.
 	// Ordering is _not_ taken into account,
@@ -220,6 +423,8 @@ type ResMap interface {
 	// fail spuriously.
 	// TODO: modify tests to not use resmap.FromMap,
 	// TODO: - and replace this with a stricter equals.
+	// The error lists the ids found on only one side, and
+	// the paths of the fields that differ; see CompareSets.
 	ErrorIfNotEqualSets(ResMap) error
 
 	// ErrorIfNotEqualLists returns an error if the
@@ -227,25 +432,83 @@ type ResMap interface {
 	// data as self, in the same order.
 	// Meta information is ignored; this is similar
 	// to comparing the AsYaml() strings, but allows
-	// for more informed errors on not equals.
+	// for more informed errors on not equals; see
+	// CompareLists.
 	ErrorIfNotEqualLists(ResMap) error
 
-	// Debug prints the ResMap.
+	// Diff returns what changed from self to the argument:
+	// the resources added, removed and modified, with the
+	// paths of the fields that changed.  Resources are
+	// matched by CurId, then by OrgId, so that one renamed
+	// by a transformer, e.g. by a name prefix, shows as
+	// modified rather than as removed and added; the OrgId
+	// is only known before RemoveIdAnnotations.
+	Diff(ResMap) (*ResMapDiff, error)
+
+	// Debug writes the ResMap to stderr as YAML; see DebugTo.
+	// An error doing so, e.g. a resource that can't be made
+	// YAML, ends the output with a line giving the error.
 	Debug(title string)
 
+	// DebugTo writes the ResMap to w, with a stable header
+	// line per resource holding its index, CurId, OrgId
+	// and origin, followed by as much of the resource as
+	// the options ask for.
+	DebugTo(w io.Writer, title string, opts DebugOptions) error
+
 	// Select returns a list of resources that
-	// are selected by a Selector
+	// are selected by a Selector.  It's an error
+	// to select resources that differ only by
+	// version; see ErrIfVersionAmbiguous.
 	Select(types.Selector) ([]*resource.Resource, error)
 
+	// SelectWithOptions is like Select, but matches
+	// group, version and kind per the given options.
+	SelectWithOptions(
+		types.Selector, resid.GvkMatchOptions) ([]*resource.Resource, error)
+
+	// SelectMatching returns the resources, in order, that
+	// are selected by a Matcher; see NewSelectorMatcher.
+	// Unlike Select, it doesn't check them for ambiguity.
+	SelectMatching(*Matcher) []*resource.Resource
+
 	// ToRNodeSlice converts the resources in the resmp
 	// to a list of RNodes
 	ToRNodeSlice() ([]*yaml.RNode, error)
 
+	// WithRNodes calls fn with the resources as RNodes,
+	// e.g. to run kio filters on them, and updates the
+	// resources to match the RNodes fn returns, in their
+	// order.  A returned node that was exported from a
+	// resource updates that resource, which keeps its
+	// bookkeeping, e.g. its options, origin and OrgId.
+	// Any other returned node, including a second copy
+	// of an exported node, is added as a new resource,
+	// and a resource whose node isn't returned is removed.
+	//
+	// The nodes are matched via an annotation that's set
+	// on the exported nodes, and removed from the returned
+	// ones; fn must leave it alone.  The annotations that
+	// track the ids of the resources during the build are
+	// left off the exported nodes, and kept as they were
+	// on the updated resources.  If fn fails, or its
+	// nodes can't be resources with unique ids, the
+	// resources aren't changed.
+	WithRNodes(fn func([]*yaml.RNode) ([]*yaml.RNode, error)) error
+
+	// ApplyFilter runs the kio filter on the resources,
+	// as WithRNodes does fn, so that a filter that
+	// changes, drops or adds nodes changes, removes or
+	// adds resources likewise.
+	ApplyFilter(f kio.Filter) error
+
 	// ApplySmPatch applies a strategic-merge patch to the
 	// selected set of resources.
 	ApplySmPatch(
 		selectedSet *resource.IdSet, patch *resource.Resource) error
 
-	// Remove annotations used exclusively by the kustomize build process.
+	// RemoveIdAnnotations calls RemoveBuildAnnotations on
+	// each resource, removing the annotations used exclusively
+	// by the kustomize build process.  It's idempotent.
 	RemoveIdAnnotations()
 }
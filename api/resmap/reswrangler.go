@@ -5,13 +5,20 @@ package resmap
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/wrappy"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	kyaml_yaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
 )
@@ -25,6 +32,17 @@ type resWrangler struct {
 	// specify in kustomizations to be maintained and
 	// available as an option for final YAML rendering.
 	rList []*resource.Resource
+
+	// index maps the indexKey of each CurId to the
+	// positions in rList of the resources having it, as
+	// of the count of changes of watch in indexedAt.
+	// It's nil if rList changed since it was made.
+	index     map[resid.ResId][]int
+	watch     *resource.IdWatch
+	indexedAt uint64
+
+	// Optional record of the fields patches wrote.
+	provenance *ProvenanceTable
 }
 
 func newOne() *resWrangler {
@@ -36,6 +54,50 @@ func newOne() *resWrangler {
 // Clear implements ResMap.
 func (m *resWrangler) Clear() {
 	m.rList = nil
+	m.index = nil
+	if m.watch != nil {
+		m.watch.Close()
+	}
+}
+
+// Sort implements ResMap.
+func (m *resWrangler) Sort(ordering ResourceOrdering) error {
+	if ordering == nil {
+		return fmt.Errorf("no ordering to sort by")
+	}
+	sort.SliceStable(m.rList, func(i, j int) bool {
+		return ordering.Less(m.rList[i], m.rList[j])
+	})
+	m.index = nil
+	return nil
+}
+
+// SplitBy implements ResMap.
+func (m *resWrangler) SplitBy(fn GroupFunc) ([]Group, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("no function to split by")
+	}
+	var groups []Group
+	byKey := make(map[string]int)
+	for _, r := range m.rList {
+		key, order := fn(r)
+		i, found := byKey[key]
+		if !found {
+			i = len(groups)
+			byKey[key] = i
+			groups = append(groups, Group{
+				Key: key, Order: order, Resources: newOne()})
+		} else if groups[i].Order != order {
+			return nil, fmt.Errorf(
+				"group '%s' given orders %d and %d, by %s",
+				key, groups[i].Order, order, r.CurId())
+		}
+		groups[i].Resources.(*resWrangler).append(r)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Order < groups[j].Order
+	})
+	return groups, nil
 }
 
 // Size implements ResMap.
@@ -61,69 +123,207 @@ func (m *resWrangler) Resources() []*resource.Resource {
 
 // Append implements ResMap.
 func (m *resWrangler) Append(res *resource.Resource) error {
-	id := res.CurId()
-	if r := m.GetMatchingResourcesByCurrentId(id.Equals); len(r) > 0 {
+	id := res.GetCurIdFast()
+	if len(m.positionsOfCurrentId(id)) > 0 {
 		return fmt.Errorf(
 			"may not add resource with an already registered id: %s", id)
 	}
 	m.rList = append(m.rList, res)
+	res.Watch(m.watch)
+	k := indexKey(id)
+	m.index[k] = append(m.index[k], len(m.rList)-1)
 	return nil
 }
 
 // Remove implements ResMap.
 func (m *resWrangler) Remove(adios resid.ResId) error {
-	tmp := newOne()
-	for _, r := range m.rList {
-		if r.CurId() != adios {
-			tmp.Append(r)
-		}
+	removed, err := m.RemoveMatchingId(func(id resid.ResId) bool {
+		return id == adios
+	})
+	if err != nil {
+		return err
 	}
-	if tmp.Size() != m.Size()-1 {
+	if len(removed) != 1 {
 		return fmt.Errorf("id %s not found in removal", adios)
 	}
-	m.rList = tmp.rList
 	return nil
 }
 
+// RemoveMatchingId implements ResMap.
+func (m *resWrangler) RemoveMatchingId(
+	matches IdMatcher) (removed []resid.ResId, err error) {
+	if matches == nil {
+		return nil, fmt.Errorf("no matcher given for removal")
+	}
+	var kept []*resource.Resource
+	for _, r := range m.rList {
+		if id := r.GetCurIdFast(); matches(id) {
+			removed = append(removed, id)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	m.rList = kept
+	m.index = nil
+	return removed, nil
+}
+
+// RemoveBySelector implements ResMap.
+func (m *resWrangler) RemoveBySelector(s types.Selector) (int, error) {
+	matcher, err := NewSelectorMatcher(s)
+	if err != nil {
+		return 0, err
+	}
+	var kept []*resource.Resource
+	for _, r := range m.rList {
+		if !matcher.Matches(r) {
+			kept = append(kept, r)
+		}
+	}
+	count := len(m.rList) - len(kept)
+	if count > 0 {
+		m.rList = kept
+		m.index = nil
+	}
+	return count, nil
+}
+
 // Replace implements ResMap.
 func (m *resWrangler) Replace(res *resource.Resource) (int, error) {
-	id := res.CurId()
-	i, err := m.GetIndexOfCurrentId(id)
-	if err != nil {
-		return -1, errors.Wrap(err, "in Replace")
+	id := res.GetCurIdFast()
+	positions := m.positionsOfCurrentId(id)
+	if len(positions) != 1 {
+		return -1, fmt.Errorf(
+			"cannot replace resource with id %s: replacement matched %d resources",
+			id, len(positions))
+	}
+	// The replacement has the id of the resource it
+	// replaces, so the index holds.
+	m.rList[positions[0]] = res
+	res.Watch(m.watch)
+	return positions[0], nil
+}
+
+// ReplaceMatchingId implements ResMap.
+func (m *resWrangler) ReplaceMatchingId(
+	matches IdMatcher, res *resource.Resource) error {
+	if matches == nil {
+		return fmt.Errorf("no matcher given for replacement")
+	}
+	count := 0
+	i := -1
+	for j, r := range m.rList {
+		if matches(r.GetCurIdFast()) {
+			count++
+			i = j
+		}
 	}
-	if i < 0 {
-		return -1, fmt.Errorf("cannot find resource with id %s to replace", id)
+	if count != 1 {
+		return fmt.Errorf("replacement matched %d resources", count)
+	}
+	newId := res.GetCurIdFast()
+	for _, j := range m.positionsOfCurrentId(newId) {
+		if j != i {
+			return fmt.Errorf(
+				"replacement has id %s, that of another resource", newId)
+		}
 	}
 	m.rList[i] = res
-	return i, nil
+	m.index = nil
+	return nil
+}
+
+// Rename implements ResMap.
+func (m *resWrangler) Rename(
+	id resid.ResId, mutate func(*resource.Resource) error) error {
+	res, err := m.GetByCurrentId(id)
+	if err != nil {
+		return errors.Wrap(err, "in Rename")
+	}
+	// The index is current, having just found res.
+	i := m.positionsOfCurrentId(id)[0]
+	oldId := res.GetCurIdFast()
+	n, v := m.watch.Changes(), res.IdVersion()
+	if err = mutate(res); err != nil {
+		rErr := restoreId(res, oldId)
+		m.moveInIndex(i, oldId, res.GetCurIdFast(), n, v)
+		if rErr != nil {
+			return errors.Wrapf(err, "restoring %s: %v", oldId, rErr)
+		}
+		return err
+	}
+	newId := res.GetCurIdFast()
+	m.moveInIndex(i, oldId, newId, n, v)
+	for _, j := range m.positionsOfCurrentId(newId) {
+		if j != i {
+			n, v = m.watch.Changes(), res.IdVersion()
+			rErr := restoreId(res, oldId)
+			m.moveInIndex(i, newId, res.GetCurIdFast(), n, v)
+			if rErr != nil {
+				return errors.Wrapf(rErr, "restoring %s", oldId)
+			}
+			return fmt.Errorf(
+				"renaming %s to %s produces an ID conflict",
+				id, newId)
+		}
+	}
+	res.SetOrgGvk(oldId.Gvk)
+	return nil
+}
+
+// restoreId gives the resource back the name, namespace
+// and Gvk of the given id, where they differ.
+func restoreId(res *resource.Resource, id resid.ResId) error {
+	if !res.GetGvk().ExactlyEquals(id.Gvk) {
+		if err := res.SetGvkE(id.Gvk); err != nil {
+			return err
+		}
+	}
+	if res.GetName() != id.Name {
+		if err := res.SetNameE(id.Name); err != nil {
+			return err
+		}
+	}
+	if res.GetNamespace() != id.Namespace {
+		return res.SetNamespaceE(id.Namespace)
+	}
+	return nil
 }
 
 // AllIds implements ResMap.
 func (m *resWrangler) AllIds() (ids []resid.ResId) {
 	ids = make([]resid.ResId, m.Size())
 	for i, r := range m.rList {
-		ids[i] = r.CurId()
+		ids[i] = r.GetCurIdFast()
+	}
+	return
+}
+
+// AllOriginalIds implements ResMap.
+func (m *resWrangler) AllOriginalIds() (ids []resid.ResId) {
+	ids = make([]resid.ResId, m.Size())
+	for i, r := range m.rList {
+		ids[i] = r.OrgId()
+	}
+	return
+}
+
+// IdPairs implements ResMap.
+func (m *resWrangler) IdPairs() (pairs []IdPair) {
+	pairs = make([]IdPair, m.Size())
+	for i, r := range m.rList {
+		pairs[i] = IdPair{Org: r.OrgId(), Cur: r.GetCurIdFast()}
 	}
 	return
 }
 
 // Debug implements ResMap.
 func (m *resWrangler) Debug(title string) {
-	fmt.Println("--------------------------- " + title)
-	firstObj := true
-	for i, r := range m.rList {
-		if firstObj {
-			firstObj = false
-		} else {
-			fmt.Println("---")
-		}
-		fmt.Printf("# %d  %s\n", i, r.OrgId())
-		blob, err := yaml.Marshal(r.Map())
-		if err != nil {
-			panic(err)
-		}
-		fmt.Println(string(blob))
+	err := m.DebugTo(os.Stderr, title, DebugOptions{Verbosity: DebugFullYaml})
+	if err != nil {
+		// Debug can't return it, so it's written too,
+		// rather than silently cutting the output short.
+		fmt.Fprintf(os.Stderr, "# resmap %q error=%q\n", title, err.Error())
 	}
 }
 
@@ -139,24 +339,107 @@ func (m *resWrangler) GetByIndex(i int) *resource.Resource {
 
 // GetIndexOfCurrentId implements ResMap.
 func (m *resWrangler) GetIndexOfCurrentId(id resid.ResId) (int, error) {
-	count := 0
-	result := -1
+	positions := m.positionsOfCurrentId(id)
+	switch len(positions) {
+	case 0:
+		return -1, nil
+	case 1:
+		return positions[0], nil
+	}
+	return -1, fmt.Errorf("id matched %d resources", len(positions))
+}
+
+// positionsOfCurrentId returns the positions in rList of
+// the resources whose CurId Equals the given id, making
+// the index afresh if it's stale.
+func (m *resWrangler) positionsOfCurrentId(id resid.ResId) []int {
+	if m.index == nil || m.watch.Changes() != m.indexedAt {
+		m.reindex()
+	}
+	return m.index[indexKey(id)]
+}
+
+// reindex makes the index afresh, with a new watch, so
+// resources removed since the last one was made stop
+// counting their changes in the index's.
+func (m *resWrangler) reindex() {
+	if m.watch != nil {
+		m.watch.Close()
+	}
+	m.watch = resource.NewIdWatch()
+	m.index = make(map[resid.ResId][]int, len(m.rList))
 	for i, r := range m.rList {
-		if id.Equals(r.CurId()) {
-			count++
-			result = i
+		r.Watch(m.watch)
+		k := indexKey(r.GetCurIdFast())
+		m.index[k] = append(m.index[k], i)
+	}
+	m.indexedAt = m.watch.Changes()
+}
+
+// moveInIndex moves the position i in the index from the
+// key of one id to that of another, after the resource
+// at i changed its id, if the index was current before.
+// The watch counted changes to n, and the resource to v,
+// before; so if no other resource changed its id since,
+// the index is current again after.
+func (m *resWrangler) moveInIndex(
+	i int, from, to resid.ResId, n, v uint64) {
+	if m.index == nil || m.indexedAt != n ||
+		m.watch.Changes()-n != m.rList[i].IdVersion()-v {
+		return
+	}
+	m.indexedAt = m.watch.Changes()
+	if fk, tk := indexKey(from), indexKey(to); fk != tk {
+		m.index[fk] = removePosition(m.index[fk], i)
+		m.index[tk] = insertPosition(m.index[tk], i)
+	}
+}
+
+// removePosition removes i from the ascending positions.
+func removePosition(positions []int, i int) []int {
+	var result []int
+	for _, p := range positions {
+		if p != i {
+			result = append(result, p)
 		}
 	}
-	if count > 1 {
-		return -1, fmt.Errorf("id matched %d resources", count)
+	return result
+}
+
+// insertPosition inserts i in the ascending positions.
+func insertPosition(positions []int, i int) []int {
+	j := sort.SearchInts(positions, i)
+	result := make([]int, 0, len(positions)+1)
+	result = append(result, positions[:j]...)
+	result = append(result, i)
+	return append(result, positions[j:]...)
+}
+
+// resourcesWithCurrentId is GetMatchingResourcesByCurrentId
+// with the matcher id.Equals, looked up in the index.
+func (m *resWrangler) resourcesWithCurrentId(
+	id resid.ResId) []*resource.Resource {
+	positions := m.positionsOfCurrentId(id)
+	result := make([]*resource.Resource, len(positions))
+	for i, p := range positions {
+		result[i] = m.rList[p]
+	}
+	return result
+}
+
+// indexKey returns the same key for ids that are Equals.
+func indexKey(id resid.ResId) resid.ResId {
+	return resid.ResId{
+		Gvk:       id.Gvk.Normalized(),
+		Name:      id.Name,
+		Namespace: id.EffectiveNamespace(),
 	}
-	return result, nil
 }
 
 type IdFromResource func(r *resource.Resource) resid.ResId
 
 func GetOriginalId(r *resource.Resource) resid.ResId { return r.OrgId() }
-func GetCurrentId(r *resource.Resource) resid.ResId  { return r.CurId() }
+func GetCurrentId(r *resource.Resource) resid.ResId  { return r.GetCurIdFast() }
 
 // GetMatchingResourcesByCurrentId implements ResMap.
 func (m *resWrangler) GetMatchingResourcesByCurrentId(
@@ -184,13 +467,14 @@ func (m *resWrangler) filteredById(
 // GetByCurrentId implements ResMap.
 func (m *resWrangler) GetByCurrentId(
 	id resid.ResId) (*resource.Resource, error) {
-	return demandOneMatch(m.GetMatchingResourcesByCurrentId, id, "Current")
+	return demandOneMatch(m.resourcesWithCurrentId(id), id, "Current")
 }
 
 // GetByOriginalId implements ResMap.
 func (m *resWrangler) GetByOriginalId(
 	id resid.ResId) (*resource.Resource, error) {
-	return demandOneMatch(m.GetMatchingResourcesByOriginalId, id, "Original")
+	return demandOneMatch(
+		m.GetMatchingResourcesByOriginalId(id.Equals), id, "Original")
 }
 
 // GetById implements ResMap.
@@ -209,11 +493,8 @@ func (m *resWrangler) GetById(
 		err1.Error(), err2.Error(), id.GvknString())
 }
 
-type resFinder func(IdMatcher) []*resource.Resource
-
 func demandOneMatch(
-	f resFinder, id resid.ResId, s string) (*resource.Resource, error) {
-	r := f(id.Equals)
+	r []*resource.Resource, id resid.ResId, s string) (*resource.Resource, error) {
 	if len(r) == 1 {
 		return r[0], nil
 	}
@@ -230,21 +511,20 @@ func (m *resWrangler) GroupedByCurrentNamespace() map[string][]*resource.Resourc
 	return items
 }
 
+// CurrentNamespaces implements ResMap.
+func (m *resWrangler) CurrentNamespaces() []string {
+	return sortedKeys(m.GroupedByCurrentNamespace())
+}
+
 // NonNamespaceable implements ResMap.NonNamespaceable
 func (m *resWrangler) NonNamespaceable() []*resource.Resource {
 	return m.groupedByCurrentNamespace()[resid.TotallyNotANamespace]
 }
 
 func (m *resWrangler) groupedByCurrentNamespace() map[string][]*resource.Resource {
-	byNamespace := make(map[string][]*resource.Resource)
-	for _, res := range m.rList {
-		namespace := res.CurId().EffectiveNamespace()
-		if _, found := byNamespace[namespace]; !found {
-			byNamespace[namespace] = []*resource.Resource{}
-		}
-		byNamespace[namespace] = append(byNamespace[namespace], res)
-	}
-	return byNamespace
+	return m.groupedBy(func(r *resource.Resource) string {
+		return r.GetCurIdFast().EffectiveNamespace()
+	})
 }
 
 // GroupedByNamespace implements ResMap.GroupByOrginalNamespace
@@ -254,107 +534,175 @@ func (m *resWrangler) GroupedByOriginalNamespace() map[string][]*resource.Resour
 	return items
 }
 
+// OriginalNamespaces implements ResMap.
+func (m *resWrangler) OriginalNamespaces() []string {
+	return sortedKeys(m.GroupedByOriginalNamespace())
+}
+
 func (m *resWrangler) groupedByOriginalNamespace() map[string][]*resource.Resource {
-	byNamespace := make(map[string][]*resource.Resource)
+	return m.groupedBy(func(r *resource.Resource) string {
+		return r.OrgId().EffectiveNamespace()
+	})
+}
+
+// GroupedByKind implements ResMap.
+func (m *resWrangler) GroupedByKind() map[string][]*resource.Resource {
+	return m.groupedBy(func(r *resource.Resource) string {
+		return r.GetCurIdFast().Kind
+	})
+}
+
+// GroupedByCurrentGvk implements ResMap.
+func (m *resWrangler) GroupedByCurrentGvk() map[resid.Gvk][]*resource.Resource {
+	return m.groupedByGvk((*resource.Resource).GetGvk)
+}
+
+// GroupedByOriginalGvk implements ResMap.
+func (m *resWrangler) GroupedByOriginalGvk() map[resid.Gvk][]*resource.Resource {
+	return m.groupedByGvk((*resource.Resource).OrgGvk)
+}
+
+func (m *resWrangler) groupedByGvk(
+	gvkOf func(*resource.Resource) resid.Gvk) map[resid.Gvk][]*resource.Resource {
+	groups := make(map[resid.Gvk][]*resource.Resource)
 	for _, res := range m.rList {
-		namespace := res.OrgId().EffectiveNamespace()
-		if _, found := byNamespace[namespace]; !found {
-			byNamespace[namespace] = []*resource.Resource{}
-		}
-		byNamespace[namespace] = append(byNamespace[namespace], res)
+		k := gvkOf(res).Normalized()
+		groups[k] = append(groups[k], res)
+	}
+	return groups
+}
+
+// groupedBy groups resources by the given key.  Since it
+// walks rList, each group keeps the order of the ResMap.
+func (m *resWrangler) groupedBy(
+	key func(*resource.Resource) string) map[string][]*resource.Resource {
+	groups := make(map[string][]*resource.Resource)
+	for _, res := range m.rList {
+		k := key(res)
+		groups[k] = append(groups[k], res)
 	}
-	return byNamespace
+	return groups
+}
+
+func sortedKeys(groups map[string][]*resource.Resource) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // AsYaml implements ResMap.
 func (m *resWrangler) AsYaml() ([]byte, error) {
+	return m.asYaml(false)
+}
+
+// AsYamlWithDirectives implements ResMap.
+func (m *resWrangler) AsYamlWithDirectives() ([]byte, error) {
+	return m.asYaml(true)
+}
+
+// AsJson implements ResMap.
+func (m *resWrangler) AsJson() ([]byte, error) {
+	objects := make([]json.RawMessage, len(m.rList))
+	for i, res := range m.rList {
+		out, err := res.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshalling %s", res.CurId())
+		}
+		objects[i] = out
+	}
+	return json.Marshal(objects)
+}
+
+func (m *resWrangler) asYaml(withDirectives bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.writeYaml(&buf, withDirectives); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteYaml implements ResMap.
+func (m *resWrangler) WriteYaml(w io.Writer) error {
+	return m.writeYaml(w, false)
+}
+
+// WriteYamlWithDirectives implements ResMap.
+func (m *resWrangler) WriteYamlWithDirectives(w io.Writer) error {
+	return m.writeYaml(w, true)
+}
+
+// flusher is a writer that buffers, e.g. a bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// writeYaml writes the yaml form of the resources to w, one
+// document at a time, flushing w after each if it buffers,
+// rather than holding all of it in memory.
+func (m *resWrangler) writeYaml(w io.Writer, withDirectives bool) error {
 	firstObj := true
-	var b []byte
-	buf := bytes.NewBuffer(b)
+	f, isFlusher := w.(flusher)
 	for _, res := range m.Resources() {
 		out, err := yaml.Marshal(res.Map())
 		if err != nil {
-			return nil, err
+			return errors.Wrapf(err, "marshalling %s", res.CurId())
 		}
-		if firstObj {
-			firstObj = false
-		} else {
-			if _, err = buf.WriteString("---\n"); err != nil {
-				return nil, err
+		var directives []string
+		if withDirectives {
+			directives = res.GetDirectives()
+		}
+		var sep string
+		switch {
+		case len(directives) > 0:
+			// Directives must follow the end of any
+			// previous document, and precede a ---.
+			if !firstObj {
+				sep = "...\n"
 			}
+			sep += strings.Join(directives, "\n") + "\n---\n"
+		case !firstObj:
+			sep = "---\n"
 		}
-		if _, err = buf.Write(out); err != nil {
-			return nil, err
+		firstObj = false
+		if _, err = io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err = w.Write(out); err != nil {
+			return err
+		}
+		if isFlusher {
+			if err = f.Flush(); err != nil {
+				return err
+			}
 		}
 	}
-	return buf.Bytes(), nil
+	return nil
 }
 
 // ErrorIfNotEqualSets implements ResMap.
 func (m *resWrangler) ErrorIfNotEqualSets(other ResMap) error {
-	m2, ok := other.(*resWrangler)
-	if !ok {
-		panic("bad cast")
-	}
-	if m.Size() != m2.Size() {
-		return fmt.Errorf(
-			"lists have different number of entries: %#v doesn't equal %#v",
-			m.rList, m2.rList)
-	}
-	seen := make(map[int]bool)
-	for _, r1 := range m.rList {
-		id := r1.CurId()
-		others := m2.GetMatchingResourcesByCurrentId(id.Equals)
-		if len(others) == 0 {
-			return fmt.Errorf(
-				"id in self missing from other; id: %s", id)
-		}
-		if len(others) > 1 {
-			return fmt.Errorf(
-				"id in self matches %d in other; id: %s", len(others), id)
-		}
-		r2 := others[0]
-		if !r1.KunstructEqual(r2) {
-			return fmt.Errorf(
-				"kunstruct not equal: \n -- %s,\n -- %s\n\n--\n%#v\n------\n%#v\n",
-				r1, r2, r1, r2)
-		}
-		seen[m2.indexOfResource(r2)] = true
-	}
-	if len(seen) != m.Size() {
-		return fmt.Errorf("counting problem %d != %d", len(seen), m.Size())
-	}
-	return nil
+	return CompareSets(m, other, DefaultCompareOptions)
 }
 
-// ErrorIfNotEqualList implements ResMap.
+// ErrorIfNotEqualLists implements ResMap.
 func (m *resWrangler) ErrorIfNotEqualLists(other ResMap) error {
-	m2, ok := other.(*resWrangler)
-	if !ok {
-		panic("bad cast")
-	}
-	if m.Size() != m2.Size() {
-		return fmt.Errorf(
-			"lists have different number of entries: %#v doesn't equal %#v",
-			m.rList, m2.rList)
-	}
-	for i, r1 := range m.rList {
-		r2 := m2.rList[i]
-		if err := r1.ErrIfNotEquals(r2); err != nil {
-			return err
-		}
-	}
-	return nil
+	return CompareLists(m, other, DefaultCompareOptions)
 }
 
 type resCopier func(r *resource.Resource) *resource.Resource
 
 // ShallowCopy implements ResMap.
 func (m *resWrangler) ShallowCopy() ResMap {
-	return m.makeCopy(
+	result := m.makeCopy(
 		func(r *resource.Resource) *resource.Resource {
 			return r
 		})
+	// The resources are shared, so their provenance is too.
+	result.SetProvenance(m.provenance)
+	return result
 }
 
 // DeepCopy implements ResMap.
@@ -365,6 +713,14 @@ func (m *resWrangler) DeepCopy() ResMap {
 		})
 }
 
+// LazyCopy implements ResMap.
+func (m *resWrangler) LazyCopy() ResMap {
+	return m.makeCopy(
+		func(r *resource.Resource) *resource.Resource {
+			return r.LazyCopy()
+		})
+}
+
 // makeCopy copies the ResMap.
 func (m *resWrangler) makeCopy(copier resCopier) ResMap {
 	result := &resWrangler{}
@@ -379,54 +735,25 @@ func (m *resWrangler) makeCopy(copier resCopier) ResMap {
 func (m *resWrangler) SubsetThatCouldBeReferencedByResource(
 	inputRes *resource.Resource) ResMap {
 	result := newOne()
-	inputId := inputRes.CurId()
-	isInputIdNamespaceable := inputId.IsNamespaceableKind()
-	subjectNamespaces := getNamespacesForRoleBinding(inputRes)
+	inputId := inputRes.GetCurIdFast()
+	isInputIdNamespaceable := inputRes.IsNamespaceableKind()
+	namespaces := referencedNamespaces(inputRes)
 	for _, r := range m.Resources() {
 		// Need to match more accuratly both at the time of selection and transformation.
 		// OutmostPrefixSuffixEquals is not accurate enough since it is only using
 		// the outer most suffix and the last prefix. Use PrefixedSuffixesEquals instead.
-		resId := r.CurId()
-		if !isInputIdNamespaceable || !resId.IsNamespaceableKind() || resId.IsNsEquals(inputId) ||
-			isRoleBindingNamespace(&subjectNamespaces, r.GetNamespace()) {
+		resId := r.GetCurIdFast()
+		if !isInputIdNamespaceable || !r.IsNamespaceableKind() || resId.IsNsEquals(inputId) ||
+			namespaces[r.GetNamespace()] {
 			result.append(r)
 		}
 	}
 	return result
 }
 
-// isRoleBindingNamespace returns true is the namespace `ns` is in role binding
-// namespaces `m`
-func isRoleBindingNamespace(m *map[string]bool, ns string) bool {
-	return (*m)[ns]
-}
-
-// getNamespacesForRoleBinding returns referenced ServiceAccount namespaces if the inputRes is
-// a RoleBinding
-func getNamespacesForRoleBinding(inputRes *resource.Resource) map[string]bool {
-	res := make(map[string]bool)
-	if inputRes.GetKind() != "RoleBinding" {
-		return res
-	}
-	subjects, err := inputRes.GetSlice("subjects")
-	if err != nil || subjects == nil {
-		return res
-	}
-
-	for _, s := range subjects {
-		subject := s.(map[string]interface{})
-		if subject["namespace"] == nil || subject["kind"] == nil ||
-			subject["kind"].(string) != "ServiceAccount" {
-			continue
-		}
-		res[subject["namespace"].(string)] = true
-	}
-
-	return res
-}
-
 func (m *resWrangler) append(res *resource.Resource) {
 	m.rList = append(m.rList, res)
+	m.index = nil
 }
 
 // AppendAll implements ResMap.
@@ -444,11 +771,17 @@ func (m *resWrangler) AppendAll(other ResMap) error {
 
 // AbsorbAll implements ResMap.
 func (m *resWrangler) AbsorbAll(other ResMap) error {
+	return m.AbsorbAllWith(other, DefaultConflictStrategy)
+}
+
+// AbsorbAllWith implements ResMap.
+func (m *resWrangler) AbsorbAllWith(
+	other ResMap, strategy ConflictStrategy) error {
 	if other == nil {
 		return nil
 	}
 	for _, r := range other.Resources() {
-		err := m.appendReplaceOrMerge(r)
+		err := m.appendReplaceOrMerge(r, strategy)
 		if err != nil {
 			return err
 		}
@@ -456,18 +789,18 @@ func (m *resWrangler) AbsorbAll(other ResMap) error {
 	return nil
 }
 
-func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
-	id := res.CurId()
+func (m *resWrangler) appendReplaceOrMerge(
+	res *resource.Resource, strategy ConflictStrategy) error {
+	id := res.GetCurIdFast()
 	matches := m.GetMatchingResourcesByOriginalId(id.Equals)
 	if len(matches) == 0 {
-		matches = m.GetMatchingResourcesByCurrentId(id.Equals)
+		matches = m.resourcesWithCurrentId(id)
 	}
 	switch len(matches) {
 	case 0:
 		switch res.Behavior() {
 		case types.BehaviorMerge, types.BehaviorReplace:
-			return fmt.Errorf(
-				"id %#v does not exist; cannot merge or replace", id)
+			return m.errNoMergeTarget(id)
 		default:
 			// presumably types.BehaviorCreate
 			return m.Append(res)
@@ -481,15 +814,27 @@ func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
 		if index < 0 {
 			return fmt.Errorf("indexing problem")
 		}
-		switch res.Behavior() {
-		case types.BehaviorReplace:
-			res.CopyMergeMetaDataFieldsFrom(old)
-		case types.BehaviorMerge:
-			res.CopyMergeMetaDataFieldsFrom(old)
+		action, err := strategy(old, res)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case ConflictReplace:
+			if err = res.CopyMergeMetaDataFieldsFrom(old); err != nil {
+				return err
+			}
+		case ConflictMerge:
+			if err = res.CopyMergeMetaDataFieldsFrom(old); err != nil {
+				return err
+			}
 			res.MergeDataMapFrom(old)
+		case ConflictKeep:
+			return nil
+		case ConflictError:
+			return fmt.Errorf("id %#v exists", id)
 		default:
 			return fmt.Errorf(
-				"id %#v exists; behavior must be merge or replace", id)
+				"id %#v exists; unknown conflict action %d", id, action)
 		}
 		i, err := m.Replace(res)
 		if err != nil {
@@ -506,59 +851,94 @@ func (m *resWrangler) appendReplaceOrMerge(res *resource.Resource) error {
 	}
 }
 
-// Select returns a list of resources that
-// are selected by a Selector
-func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
-	var result []*resource.Resource
-	sr, err := types.NewSelectorRegex(&s)
-	if err != nil {
-		return nil, err
-	}
-	for _, r := range m.Resources() {
-		curId := r.CurId()
-		orgId := r.OrgId()
-
-		// It first tries to match with the original namespace
-		// then matches with the current namespace
-		if !sr.MatchNamespace(orgId.EffectiveNamespace()) &&
-			!sr.MatchNamespace(curId.EffectiveNamespace()) {
+// errNoMergeTarget returns the error of merging into or
+// replacing the resource with the given id, which doesn't
+// exist.  If a resource of the same kind has its name,
+// originally or now, though perhaps in another namespace,
+// e.g. as a base's namePrefix and namespace left it, the
+// error says how that resource differs, by name or by
+// namespace, as it's likely the one meant.
+func (m *resWrangler) errNoMergeTarget(id resid.ResId) error {
+	var near []string
+	var nameDiffers, nsDiffers bool
+	for _, r := range m.rList {
+		if !r.GetGvk().Equals(id.Gvk) {
 			continue
 		}
-
-		// It first tries to match with the original name
-		// then matches with the current name
-		if !sr.MatchName(orgId.Name) &&
-			!sr.MatchName(curId.Name) {
+		if r.GetName() == id.Name {
+			// Then only the namespace differs.
+			nsDiffers = true
+			near = append(near, fmt.Sprintf(
+				"the %s named '%s' is in namespace '%s'",
+				r.GetKind(), r.GetName(), r.CurId().EffectiveNamespace()))
 			continue
 		}
-
-		// matches the GVK
-		if !sr.MatchGvk(r.GetGvk()) {
+		if r.GetOriginalName() != id.Name {
 			continue
 		}
-
-		// matches the label selector
-		matched, err := r.MatchesLabelSelector(s.LabelSelector)
-		if err != nil {
-			return nil, err
-		}
-		if !matched {
-			continue
+		nameDiffers = true
+		if !r.CurId().IsNsEquals(id) {
+			nsDiffers = true
 		}
+		near = append(near, fmt.Sprintf(
+			"the %s originally named '%s'%s is now called '%s'%s",
+			r.GetKind(), r.GetOriginalName(), inNamespace(r.GetOriginalNs()),
+			r.GetName(), inNamespace(r.GetNamespace())))
+	}
+	if len(near) == 0 {
+		return fmt.Errorf(
+			"id %#v does not exist; cannot merge or replace", id)
+	}
+	fix := "name and namespace"
+	if !nsDiffers {
+		fix = "name"
+	} else if !nameDiffers {
+		fix = "namespace"
+	}
+	return fmt.Errorf(
+		"id %#v does not exist; cannot merge or replace; %s, so give "+
+			"the merge or replace that %s",
+		id, strings.Join(near, ", and "), fix)
+}
 
-		// matches the annotation selector
-		matched, err = r.MatchesAnnotationSelector(s.AnnotationSelector)
-		if err != nil {
-			return nil, err
-		}
-		if !matched {
-			continue
-		}
-		result = append(result, r)
+func inNamespace(ns string) string {
+	if ns == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in namespace '%s'", ns)
+}
+
+// Select returns a list of resources that
+// are selected by a Selector
+func (m *resWrangler) Select(s types.Selector) ([]*resource.Resource, error) {
+	return m.SelectWithOptions(s, resid.GvkMatchOptions{})
+}
+
+// SelectWithOptions is like Select, matching Gvks per the options.
+func (m *resWrangler) SelectWithOptions(
+	s types.Selector, opts resid.GvkMatchOptions) ([]*resource.Resource, error) {
+	matcher, err := NewSelectorMatcherWithOptions(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := m.SelectMatching(matcher)
+	if err = ErrIfVersionAmbiguous(result); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
+// SelectMatching implements ResMap.
+func (m *resWrangler) SelectMatching(matcher *Matcher) []*resource.Resource {
+	var result []*resource.Resource
+	for _, r := range m.Resources() {
+		if matcher.Matches(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 // ToRNodeSlice converts the resources in the resmp
 // to a list of RNodes
 func (m *resWrangler) ToRNodeSlice() ([]*kyaml_yaml.RNode, error) {
@@ -577,19 +957,159 @@ func (m *resWrangler) ToRNodeSlice() ([]*kyaml_yaml.RNode, error) {
 	return rnodes, nil
 }
 
+// rnodeIndexAnnotation marks each node that WithRNodes
+// exports with the index of its resource, so that the
+// nodes the callback returns can be matched to them.
+const rnodeIndexAnnotation = "kustomize.config.k8s.io/rnode-index"
+
+// WithRNodes implements ResMap.
+func (m *resWrangler) WithRNodes(
+	fn func([]*kyaml_yaml.RNode) ([]*kyaml_yaml.RNode, error)) error {
+	nodes, err := m.ToRNodeSlice()
+	if err != nil {
+		return err
+	}
+	for i, n := range nodes {
+		// Hide the build annotations from fn, so that
+		// it can't lose or change them.
+		for k := range m.rList[i].BuildAnnotations() {
+			if err = n.PipeE(kyaml_yaml.ClearAnnotation(k)); err != nil {
+				return err
+			}
+		}
+		if err = n.PipeE(kyaml_yaml.SetAnnotation(
+			rnodeIndexAnnotation, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	nodes, err = fn(nodes)
+	if err != nil {
+		return err
+	}
+	// Stage the new contents, so that the resources
+	// change only if they all can.
+	staged := New()
+	var matches []*resource.Resource
+	matched := make(map[int]bool)
+	for _, n := range nodes {
+		i, err := m.rnodeIndex(n)
+		if err != nil {
+			return err
+		}
+		if err = n.PipeE(kyaml_yaml.ClearAnnotation(rnodeIndexAnnotation)); err != nil {
+			return err
+		}
+		if err = kyaml_yaml.ClearEmptyAnnotations(n); err != nil {
+			return err
+		}
+		json, err := n.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var res, match *resource.Resource
+		switch {
+		case i >= 0 && !matched[i]:
+			// A copy of a node that's already matched is
+			// an addition.
+			matched[i] = true
+			match = m.rList[i]
+			res = match.DeepCopy()
+			if err = res.UnmarshalJSON(json); err == nil {
+				err = res.SetBuildAnnotations(match.BuildAnnotations())
+			}
+			if err == nil {
+				err = keepOriginalNameAndNs(res, match)
+			}
+		case len(m.rList) > 0:
+			res, err = m.rList[0].NewLike(json)
+		default:
+			res = resource.NewFactory(&wrappy.WNodeFactory{}).
+				FromKunstructured(wrappy.FromRNode(n))
+		}
+		if err != nil {
+			return err
+		}
+		if err = staged.Append(res); err != nil {
+			return err
+		}
+		matches = append(matches, match)
+	}
+	result := staged.Resources()
+	for i, match := range matches {
+		if match != nil {
+			gvk := match.GetGvk()
+			match.ResetPrimaryData(result[i])
+			match.SetOrgGvk(gvk)
+			result[i] = match
+		}
+	}
+	m.rList = result
+	m.index = nil
+	return nil
+}
+
+// ApplyFilter implements ResMap.
+func (m *resWrangler) ApplyFilter(f kio.Filter) error {
+	return m.WithRNodes(f.Filter)
+}
+
+// keepOriginalNameAndNs records the original name and
+// namespace of the resource on its updated copy, if the
+// update changed them, so that its OrgId is unchanged.
+func keepOriginalNameAndNs(updated, res *resource.Resource) error {
+	if n := res.GetOriginalName(); updated.GetOriginalName() != n {
+		if err := updated.SetOriginalNameE(n, true); err != nil {
+			return err
+		}
+	}
+	if ns := res.GetOriginalNs(); updated.GetOriginalNs() != ns {
+		return updated.SetOriginalNsE(ns, true)
+	}
+	return nil
+}
+
+// rnodeIndex returns the index of the resource that the
+// node was exported from by WithRNodes, or -1 if the
+// node is new.
+func (m *resWrangler) rnodeIndex(n *kyaml_yaml.RNode) (int, error) {
+	v, err := n.Pipe(kyaml_yaml.GetAnnotation(rnodeIndexAnnotation))
+	if err != nil {
+		return -1, err
+	}
+	if v == nil {
+		return -1, nil
+	}
+	i, err := strconv.Atoi(kyaml_yaml.GetValue(v))
+	if err != nil || i < 0 || i >= len(m.rList) {
+		return -1, fmt.Errorf(
+			"invalid value %q of annotation %s",
+			kyaml_yaml.GetValue(v), rnodeIndexAnnotation)
+	}
+	return i, nil
+}
+
 func (m *resWrangler) ApplySmPatch(
 	selectedSet *resource.IdSet, patch *resource.Resource) error {
 	newRm := New()
 	for _, res := range m.Resources() {
-		if !selectedSet.Contains(res.CurId()) {
+		if !selectedSet.Contains(res.GetCurIdFast()) {
 			newRm.Append(res)
 			continue
 		}
 		patchCopy := patch.DeepCopy()
-		patchCopy.SetName(res.GetName())
-		patchCopy.SetNamespace(res.GetNamespace())
-		patchCopy.SetGvk(res.GetGvk())
-		patchCopy.SetOriginalName(res.GetOriginalName(), true)
+		if err := patchCopy.SetNameE(res.GetName()); err != nil {
+			return err
+		}
+		if err := patchCopy.SetNamespaceE(res.GetNamespace()); err != nil {
+			return err
+		}
+		if err := patchCopy.SetGvkE(res.GetGvk()); err != nil {
+			return err
+		}
+		if err := patchCopy.SetOriginalNameE(
+			res.GetOriginalName(), true); err != nil {
+			return err
+		}
 		err := res.ApplySmPatch(patchCopy)
 		if err != nil {
 			// Check for an error string from UnmarshalJSON that's indicative
@@ -623,6 +1143,6 @@ func (m *resWrangler) ApplySmPatch(
 
 func (m *resWrangler) RemoveIdAnnotations() {
 	for _, r := range m.Resources() {
-		r.RemoveIdAnnotations()
+		r.RemoveBuildAnnotations()
 	}
 }
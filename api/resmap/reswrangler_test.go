@@ -5,10 +5,15 @@ package resmap_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/provider"
@@ -17,6 +22,8 @@ import (
 	"sigs.k8s.io/kustomize/api/resource"
 	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 var depProvider = provider.NewDefaultDepProvider()
@@ -191,6 +198,179 @@ metadata:
 	}
 }
 
+func TestEncodeAsJson(t *testing.T) {
+	input, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  script: |
+    #!/bin/sh
+    echo "a\tb"
+  other: x
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+  labels: &labels
+    app: web
+spec:
+  replicas: 3
+  paused: false
+  selector:
+    matchLabels: *labels
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	out, err := input.AsJson()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `[{"apiVersion":"v1","data":{"other":"x",`+
+		`"script":"#!/bin/sh\necho \"a\\tb\"\n"},"kind":"ConfigMap",`+
+		`"metadata":{"name":"cm"}},{"apiVersion":"apps/v1",`+
+		`"kind":"Deployment","metadata":{"labels":{"app":"web"},`+
+		`"name":"dep"},"spec":{"paused":false,"replicas":3,`+
+		`"selector":{"matchLabels":{"app":"web"}}}}]`, string(out))
+
+	var objects []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out, &objects))
+	assert.Equal(t, "#!/bin/sh\necho \"a\\tb\"\n",
+		objects[0]["data"].(map[string]interface{})["script"])
+
+	out, err = New().AsJson()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(out))
+}
+
+func TestEncodeAsYamlWithDirectives(t *testing.T) {
+	input, err := rmF.NewResMapFromBytes([]byte(`%YAML 1.1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+...
+%YAML 1.2
+%TAG !e! tag:example.com,2021:
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm3
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	out, err := input.AsYamlWithDirectives()
+	assert.NoError(t, err)
+	assert.Equal(t, `%YAML 1.1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+...
+%YAML 1.2
+%TAG !e! tag:example.com,2021:
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm3
+`, string(out))
+
+	// The directives are left out by default.
+	out, err = input.AsYaml()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "%")
+}
+
+// flushRecorder is a buffering writer recording what was
+// written at each flush.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed []string
+}
+
+func (f *flushRecorder) Flush() error {
+	f.flushed = append(f.flushed, f.String())
+	return nil
+}
+
+func TestWriteYaml(t *testing.T) {
+	input, err := rmF.NewResMapFromBytes([]byte(`%YAML 1.1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, withDirectives := range []bool{false, true} {
+		asYaml, writeYaml := input.AsYaml, input.WriteYaml
+		if withDirectives {
+			asYaml, writeYaml = input.AsYamlWithDirectives, input.WriteYamlWithDirectives
+		}
+		expected, err := asYaml()
+		assert.NoError(t, err)
+		var w flushRecorder
+		assert.NoError(t, writeYaml(&w))
+		assert.Equal(t, string(expected), w.String())
+		// A flush follows each document.
+		if assert.Len(t, w.flushed, 2) {
+			assert.True(t, strings.HasSuffix(w.flushed[0], "name: cm1\n"))
+			assert.Equal(t, w.String(), w.flushed[1])
+		}
+	}
+
+	var w bytes.Buffer
+	assert.NoError(t, New().WriteYaml(&w))
+	assert.Empty(t, w.String())
+
+	// Writing stops at the first resource that can't be
+	// serialized.
+	bad := rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "bad",
+		},
+		"spec": map[string]interface{}{
+			"f": math.Inf(1),
+		},
+	})
+	assert.NoError(t, input.Append(bad))
+	assert.NoError(t, input.Append(makeCm(3)))
+	w.Reset()
+	err = input.WriteYaml(&w)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "bad")
+	}
+	assert.Contains(t, w.String(), "name: cm2")
+	assert.NotContains(t, w.String(), "cm003")
+}
+
 func TestGetMatchingResourcesByCurrentId(t *testing.T) {
 	cmap := resid.Gvk{Version: "v1", Kind: "ConfigMap"}
 
@@ -441,6 +621,72 @@ func TestSubsetThatCouldBeReferencedByResource(t *testing.T) {
 	}
 }
 
+func TestSubsetThatCouldBeReferencedAcrossNamespaces(t *testing.T) {
+	m := resMapFromYaml(t, `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa
+  namespace: ns-a
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa
+  namespace: ns-b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: metrics
+  namespace: ns-c
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: binding
+  namespace: ns-a
+subjects:
+- kind: ServiceAccount
+  name: sa
+  namespace: ns-b
+- kind: User
+  name: jane
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: monitor
+  namespace: ns-a
+spec:
+  namespaceSelector:
+    matchNames:
+    - ns-c
+`)
+	names := func(filter int) []string {
+		var result []string
+		for _, r := range m.SubsetThatCouldBeReferencedByResource(
+			m.GetByIndex(filter)).Resources() {
+			result = append(result, r.GetKind()+"/"+r.GetNamespace())
+		}
+		return result
+	}
+	assert.Equal(t, []string{
+		"ServiceAccount/ns-a",
+		"ServiceAccount/ns-b",
+		"RoleBinding/ns-a",
+		"ServiceMonitor/ns-a",
+	}, names(3))
+	assert.Equal(t, []string{
+		"ServiceAccount/ns-a",
+		"Service/ns-c",
+		"RoleBinding/ns-a",
+		"ServiceMonitor/ns-a",
+	}, names(4))
+	// Only the referrer's fields count.
+	assert.Equal(t, []string{"ServiceAccount/ns-b"}, names(1))
+}
+
 func TestDeepCopy(t *testing.T) {
 	rm1 := resmaptest_test.NewRmBuilder(t, rf).Add(
 		map[string]interface{}{
@@ -469,6 +715,40 @@ func TestDeepCopy(t *testing.T) {
 	}
 }
 
+func TestLazyCopy(t *testing.T) {
+	w := New()
+	for i := 1; i <= 3; i++ {
+		doAppend(t, w, makeCm(i))
+	}
+	original, err := w.AsYaml()
+	assert.NoError(t, err)
+
+	c := w.LazyCopy()
+	assert.NoError(t, c.ErrorIfNotEqualLists(w))
+	c.GetByIndex(0).SetLabels(map[string]string{"a": "b"})
+	patch := rf.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "cm002",
+		},
+		"data": map[string]interface{}{
+			"k": "v",
+		},
+	})
+	assert.NoError(t, c.ApplySmPatch(
+		resource.MakeIdSet([]*resource.Resource{c.GetByIndex(1)}), patch))
+	actual, err := w.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(actual))
+	assert.Equal(t, map[string]string{"a": "b"}, c.GetByIndex(0).GetLabels())
+	assert.Equal(t, map[string]string{"k": "v"}, c.GetByIndex(1).GetDataMap())
+
+	// Nor do changes to the original reach the copy.
+	w.GetByIndex(2).SetName("cm100")
+	assert.Equal(t, "cm003", c.GetByIndex(2).GetName())
+}
+
 func TestErrorIfNotEqualSets(t *testing.T) {
 	r1 := rf.FromMap(
 		map[string]interface{}{
@@ -689,6 +969,23 @@ func makeMap2(b types.GenerationBehavior) ResMap {
 		}))
 }
 
+func TestAbsorbAllKeepsNamePrefixesAndSuffixes(t *testing.T) {
+	for _, b := range []types.GenerationBehavior{
+		types.BehaviorMerge, types.BehaviorReplace} {
+		w := makeMap1()
+		r := w.GetByIndex(0)
+		r.AddNamePrefix("a-")
+		r.AddNamePrefix("b-")
+		r.AddNameSuffix("-z")
+		assert.NoError(t, w.AbsorbAll(makeMap2(b)))
+		for _, r := range []*resource.Resource{
+			w.GetByIndex(0), w.DeepCopy().GetByIndex(0)} {
+			assert.Equal(t, []string{"a-", "b-"}, r.GetNamePrefixes(), b)
+			assert.Equal(t, []string{"-z"}, r.GetNameSuffixes(), b)
+		}
+	}
+}
+
 func TestAbsorbAll(t *testing.T) {
 	metadata := map[string]interface{}{
 		"name": "cmap",
@@ -726,6 +1023,121 @@ func TestAbsorbAll(t *testing.T) {
 		t, strings.Contains(err.Error(), "behavior must be merge or replace"))
 }
 
+func TestAbsorbAllWith(t *testing.T) {
+	existing := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    from: existing
+data:
+  a: existing
+  b: existing
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    from: existing
+spec:
+  replicas: 1
+`
+	incoming := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  b: incoming
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+	byKind := func(
+		existing, incoming *resource.Resource) (ConflictAction, error) {
+		if incoming.GetKind() == "ConfigMap" {
+			return ConflictMerge, nil
+		}
+		return ConflictReplace, nil
+	}
+	m := resMapFromYaml(t, existing)
+	assert.NoError(t, m.AbsorbAllWith(resMapFromYaml(t, incoming), byKind))
+	yml, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+data:
+  a: existing
+  b: incoming
+kind: ConfigMap
+metadata:
+  labels:
+    from: existing
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    from: existing
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`, string(yml))
+
+	keep := func(
+		existing, incoming *resource.Resource) (ConflictAction, error) {
+		return ConflictKeep, nil
+	}
+	m = resMapFromYaml(t, existing)
+	assert.NoError(t, m.AbsorbAllWith(resMapFromYaml(t, incoming), keep))
+	expected := resMapFromYaml(t, existing)
+	assert.NoError(t, expected.Append(m.GetByIndex(2)))
+	assert.NoError(t, expected.ErrorIfNotEqualLists(m))
+
+	refuse := func(
+		existing, incoming *resource.Resource) (ConflictAction, error) {
+		return ConflictError, nil
+	}
+	m = resMapFromYaml(t, existing)
+	err = m.AbsorbAllWith(resMapFromYaml(t, incoming), refuse)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "exists")
+	}
+
+	// The strategy's own error wins.
+	fail := func(
+		existing, incoming *resource.Resource) (ConflictAction, error) {
+		return ConflictMerge, fmt.Errorf("no %s", incoming.GetName())
+	}
+	m = resMapFromYaml(t, existing)
+	assert.EqualError(t,
+		m.AbsorbAllWith(resMapFromYaml(t, incoming), fail), "no settings")
+
+	// The default strategy is AbsorbAll's.
+	m = resMapFromYaml(t, existing)
+	err = m.AbsorbAllWith(
+		resMapFromYaml(t, incoming), DefaultConflictStrategy)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "behavior must be merge or replace")
+	}
+}
+
 func TestToRNodeSlice(t *testing.T) {
 	input := `apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRole
@@ -768,6 +1180,181 @@ rules:
 	}
 }
 
+func TestWithRNodes(t *testing.T) {
+	w := New()
+	doAppend(t, w, makeCm(1))
+	doAppend(t, w, makeCm(2))
+	doAppend(t, w, makeCm(3))
+	w.GetByIndex(0).SetOptions(types.NewGenArgs(
+		&types.GeneratorArgs{Behavior: "merge"}))
+	w.GetByIndex(0).SetOrigin(&resource.Origin{Path: "cm1.yaml"})
+
+	var seen []string
+	assert.NoError(t, w.WithRNodes(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+		for _, n := range nodes {
+			meta, err := n.GetMeta()
+			if err != nil {
+				return nil, err
+			}
+			seen = append(seen, meta.Name)
+		}
+		// Rename the first, drop the second, reorder,
+		// and add a copy of the third.
+		if err := nodes[0].PipeE(kyaml.SetK8sName("cm100")); err != nil {
+			return nil, err
+		}
+		added := nodes[2].Copy()
+		if err := added.PipeE(kyaml.SetK8sName("cm004")); err != nil {
+			return nil, err
+		}
+		return []*kyaml.RNode{nodes[2], nodes[0], added}, nil
+	}))
+	assert.Equal(t, []string{"cm001", "cm002", "cm003"}, seen)
+
+	assert.Equal(t, 3, w.Size())
+	r3, r1, r4 := w.GetByIndex(0), w.GetByIndex(1), w.GetByIndex(2)
+	assert.Equal(t, "cm003", r3.GetName())
+	assert.Empty(t, r3.GetAnnotations())
+
+	assert.Equal(t, "cm100", r1.GetName())
+	assert.Equal(t, makeCm(1).OrgId(), r1.OrgId())
+	assert.Equal(t, r1.CurId(), r1.GetCurIdFast())
+	assert.True(t, r1.Behavior() == types.BehaviorMerge)
+	assert.Equal(t, "cm1.yaml", r1.GetOrigin().Path)
+
+	assert.Equal(t, "cm004", r4.GetName())
+	assert.Equal(t, "cm004", r4.OrgId().Name)
+	assert.Nil(t, r4.GetOrigin())
+	assert.Empty(t, r4.GetAnnotations())
+}
+
+func TestWithRNodesAddsToEmptyResMap(t *testing.T) {
+	w := New()
+	assert.NoError(t, w.WithRNodes(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+		assert.Empty(t, nodes)
+		n, err := kyaml.Parse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm001
+`)
+		return []*kyaml.RNode{n}, err
+	}))
+	assert.Equal(t, []resid.ResId{makeCm(1).CurId()}, w.AllIds())
+}
+
+func TestWithRNodesErrors(t *testing.T) {
+	tests := map[string]struct {
+		fn  func([]*kyaml.RNode) ([]*kyaml.RNode, error)
+		err string
+	}{
+		"callback error": {
+			fn: func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+				_ = nodes[0].PipeE(kyaml.SetK8sName("cm100"))
+				return nil, fmt.Errorf("oops")
+			},
+			err: "oops",
+		},
+		"id conflict": {
+			fn: func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+				err := nodes[0].PipeE(kyaml.SetK8sName("cm002"))
+				return nodes, err
+			},
+			err: "may not add resource with an already registered id",
+		},
+		"bad annotation": {
+			fn: func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+				err := nodes[1].PipeE(kyaml.SetAnnotation(
+					"kustomize.config.k8s.io/rnode-index", "7"))
+				return nodes, err
+			},
+			err: `invalid value "7" of annotation kustomize.config.k8s.io/rnode-index`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := New()
+			doAppend(t, w, makeCm(1))
+			doAppend(t, w, makeCm(2))
+			err := w.WithRNodes(tc.fn)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+			assert.Equal(t, []string{"cm001", "cm002"},
+				[]string{w.GetByIndex(0).GetName(), w.GetByIndex(1).GetName()})
+			assert.Empty(t, w.GetByIndex(0).GetAnnotations())
+		})
+	}
+}
+
+func TestApplyFilter(t *testing.T) {
+	w := New()
+	r1 := makeCm(1)
+	r1.SetAnnotations(map[string]string{"a": "b"})
+	r1.SetOriginalName("cm001", true)
+	r1.AddNamePrefix("p-")
+	r1.AddNameSuffix("-s")
+	r1.SetOriginalNs("ns1", true)
+	doAppend(t, w, r1)
+	doAppend(t, w, makeCm(2))
+	built := w.GetByIndex(0).BuildAnnotations()
+	assert.Len(t, built, 4)
+
+	// Rename both, clear the annotation, drop the second
+	// and add one.
+	assert.NoError(t, w.ApplyFilter(kio.FilterFunc(
+		func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			for _, n := range nodes {
+				annotations, err := n.GetAnnotations()
+				if err != nil {
+					return nil, err
+				}
+				for k := range annotations {
+					assert.NotContains(t, built, k)
+				}
+				meta, err := n.GetMeta()
+				if err != nil {
+					return nil, err
+				}
+				if err = n.PipeE(kyaml.SetK8sName(meta.Name + "x")); err != nil {
+					return nil, err
+				}
+			}
+			if err := nodes[0].PipeE(kyaml.ClearAnnotation("a")); err != nil {
+				return nil, err
+			}
+			added, err := kyaml.Parse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm003
+`)
+			return []*kyaml.RNode{nodes[0], added}, err
+		})))
+	assert.Equal(t, 2, w.Size())
+	r := w.GetByIndex(0)
+	assert.Equal(t, "cm001x", r.GetName())
+	assert.Equal(t, built, r.BuildAnnotations())
+	assert.Equal(t, []string{"p-"}, r.GetNamePrefixes())
+	assert.Equal(t, []string{"-s"}, r.GetNameSuffixes())
+	assert.Equal(t, "ns1", r.GetOriginalNs())
+	assert.Equal(t, "cm001", r.OrgId().Name)
+	assert.NotContains(t, r.GetAnnotations(), "a")
+	assert.Equal(t, "cm003", w.GetByIndex(1).GetName())
+
+	// A node colliding with another changes nothing.
+	err := w.ApplyFilter(kio.FilterFunc(
+		func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			return append(nodes, nodes[1].Copy()), nil
+		}))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"may not add resource with an already registered id")
+	}
+	assert.Equal(t, 2, w.Size())
+	assert.Equal(t, built, w.GetByIndex(0).BuildAnnotations())
+}
+
 func TestApplySmPatch_General(t *testing.T) {
 	const (
 		myDeployment      = "Deployment"
@@ -1221,3 +1808,602 @@ spec:
         name: nginx
 `, imagename)
 }
+
+func makeGroupingResources() []*resource.Resource {
+	var result []*resource.Resource
+	for i := 0; i < 24; i++ {
+		kind := [...]string{"ConfigMap", "Deployment", "ClusterRole"}[i%3]
+		obj := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": fmt.Sprintf("r%02d", i),
+			},
+		}
+		if kind != "ClusterRole" && i%2 == 0 {
+			obj["metadata"].(map[string]interface{})["namespace"] =
+				fmt.Sprintf("ns%d", i%4)
+		}
+		result = append(result, rf.FromMap(obj))
+	}
+	return result
+}
+
+// The groups must hold resources in the order of the
+// ResMap, no matter how the ResMap was built.
+func TestGroupingKeepsResMapOrder(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	rnd := rand.New(rand.NewSource(seed))
+	resources := makeGroupingResources()
+	var expected string
+	for i := 0; i < 20; i++ {
+		// Build the same list via a random mix of Append,
+		// AppendAll and AbsorbAll of random-sized chunks.
+		w := New()
+		for start := 0; start < len(resources); {
+			end := start + 1 + rnd.Intn(len(resources)-start)
+			chunk := New()
+			for _, r := range resources[start:end] {
+				doAppend(t, chunk, r)
+			}
+			switch rnd.Intn(3) {
+			case 0:
+				for _, r := range chunk.Resources() {
+					doAppend(t, w, r)
+				}
+			case 1:
+				assert.NoError(t, w.AppendAll(chunk))
+			default:
+				assert.NoError(t, w.AbsorbAll(chunk))
+			}
+			start = end
+		}
+		actual := describeGroups(t, w)
+		if i == 0 {
+			expected = actual
+		}
+		assert.Equal(t, expected, actual)
+	}
+	// Shuffle the input; the groups follow the new order.
+	rnd.Shuffle(len(resources), func(i, j int) {
+		resources[i], resources[j] = resources[j], resources[i]
+	})
+	w := New()
+	for _, r := range resources {
+		doAppend(t, w, r)
+	}
+	describeGroups(t, w)
+}
+
+// describeGroups checks that each group is a subsequence of
+// the ResMap's resources and returns a printout of them.
+func describeGroups(t *testing.T, w ResMap) string {
+	t.Helper()
+	index := make(map[*resource.Resource]int)
+	for i, r := range w.Resources() {
+		index[r] = i
+	}
+	var b strings.Builder
+	check := func(title string, group []*resource.Resource) {
+		for i := 1; i < len(group); i++ {
+			if index[group[i-1]] >= index[group[i]] {
+				t.Fatalf("%s: %s precedes %s in the ResMap",
+					title, group[i].CurId(), group[i-1].CurId())
+			}
+		}
+		b.WriteString(title + ":")
+		for _, r := range group {
+			b.WriteString(" " + r.GetName())
+		}
+		b.WriteString("\n")
+	}
+	byNamespace := w.GroupedByCurrentNamespace()
+	namespaces := w.CurrentNamespaces()
+	assert.Equal(t, len(byNamespace), len(namespaces))
+	assert.True(t, sort.StringsAreSorted(namespaces))
+	for _, ns := range namespaces {
+		check("namespace "+ns, byNamespace[ns])
+	}
+	byOriginalNamespace := w.GroupedByOriginalNamespace()
+	namespaces = w.OriginalNamespaces()
+	assert.Equal(t, len(byOriginalNamespace), len(namespaces))
+	assert.True(t, sort.StringsAreSorted(namespaces))
+	for _, ns := range namespaces {
+		check("original namespace "+ns, byOriginalNamespace[ns])
+	}
+	check("non-namespaceable", w.NonNamespaceable())
+	byKind := w.GroupedByKind()
+	var kinds []string
+	for k := range byKind {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		check("kind "+k, byKind[k])
+	}
+	return b.String()
+}
+
+func TestGroupedByGvk(t *testing.T) {
+	w, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: d
+---
+apiVersion: core/v1
+kind: ConfigMap
+metadata:
+  name: e
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	patch, err := rf.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, w.GetByIndex(0).ApplySmPatch(patch))
+
+	names := func(groups map[resid.Gvk][]*resource.Resource) map[string][]string {
+		result := make(map[string][]string)
+		for gvk, rs := range groups {
+			for _, r := range rs {
+				result[gvk.String()] = append(result[gvk.String()], r.GetName())
+			}
+		}
+		return result
+	}
+	assert.Equal(t, map[string][]string{
+		"apps_v1_Deployment":            {"a", "c"},
+		"extensions_v1beta1_Deployment": {"d"},
+		"~G_v1_ConfigMap":               {"b", "e"},
+	}, names(w.GroupedByCurrentGvk()))
+	assert.Equal(t, map[string][]string{
+		"apps_v1_Deployment":            {"c"},
+		"extensions_v1beta1_Deployment": {"a", "d"},
+		"~G_v1_ConfigMap":               {"b", "e"},
+	}, names(w.GroupedByOriginalGvk()))
+}
+
+func TestRename(t *testing.T) {
+	w := New()
+	doAppend(t, w, makeCm(1))
+	doAppend(t, w, makeCm(2))
+
+	assert.NoError(t, w.Rename(makeCm(1).CurId(), func(r *resource.Resource) error {
+		r.SetName("cm003")
+		return nil
+	}))
+	assert.Equal(t, []string{"cm003", "cm002"},
+		[]string{w.GetByIndex(0).GetName(), w.GetByIndex(1).GetName()})
+	assert.Equal(t, w.GetByIndex(0).CurId(), w.GetByIndex(0).GetCurIdFast())
+
+	// The collision is caught by the rename, which is undone.
+	err := w.Rename(makeCm(3).CurId(), func(r *resource.Resource) error {
+		r.SetName("cm002")
+		return nil
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "produces an ID conflict")
+	}
+	assert.Equal(t, "cm003", w.GetByIndex(0).GetName())
+	assert.Equal(t, w.GetByIndex(0).CurId(), w.GetByIndex(0).GetCurIdFast())
+
+	// Errors from the mutator are passed on, and the id
+	// it changed is restored.
+	err = w.Rename(makeCm(3).CurId(), func(r *resource.Resource) error {
+		r.SetName("cm004")
+		r.SetNamespace("ns")
+		return fmt.Errorf("oops")
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, "oops", err.Error())
+	}
+	assert.Equal(t, makeCm(3).CurId(), w.GetByIndex(0).GetCurIdFast())
+	i, err := w.GetIndexOfCurrentId(makeCm(3).CurId())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i)
+	_, err = w.GetByCurrentId(makeCm(4).CurId())
+	assert.Error(t, err)
+
+	err = w.Rename(makeCm(1).CurId(), func(r *resource.Resource) error {
+		return nil
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no matches")
+	}
+}
+
+func TestAllIdsKeepAppendOrder(t *testing.T) {
+	w := New()
+	for _, i := range []int{3, 1, 2} {
+		doAppend(t, w, makeCm(i))
+	}
+	assert.NoError(t, w.Rename(makeCm(1).CurId(), func(r *resource.Resource) error {
+		r.SetOriginalName(r.GetName(), false).SetName("cm004")
+		return nil
+	}))
+	assert.NoError(t, w.Remove(makeCm(2).CurId()))
+	doAppend(t, w, makeCm(2))
+
+	assert.Equal(t,
+		[]resid.ResId{makeCm(3).CurId(), makeCm(4).CurId(), makeCm(2).CurId()},
+		w.AllIds())
+	assert.Equal(t,
+		[]resid.ResId{makeCm(3).OrgId(), makeCm(1).OrgId(), makeCm(2).OrgId()},
+		w.AllOriginalIds())
+	assert.Equal(t, []IdPair{
+		{Org: makeCm(3).OrgId(), Cur: makeCm(3).CurId()},
+		{Org: makeCm(1).OrgId(), Cur: makeCm(4).CurId()},
+		{Org: makeCm(2).OrgId(), Cur: makeCm(2).CurId()},
+	}, w.IdPairs())
+	assert.Empty(t, New().AllOriginalIds())
+}
+
+func TestRemoveIdAnnotationsIsIdempotent(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  annotations:
+    config.kubernetes.io/originalName: cm
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+  annotations:
+    config.kubernetes.io/prefixes: p-
+    team: blue
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    team: blue
+  name: cm2
+`
+	m.RemoveIdAnnotations()
+	once, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(once))
+	m.RemoveIdAnnotations()
+	twice, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, string(once), string(twice))
+}
+
+func TestRemoveMatchingId(t *testing.T) {
+	w := New()
+	for i := 1; i <= 4; i++ {
+		doAppend(t, w, makeCm(i))
+	}
+	removed, err := w.RemoveMatchingId(func(id resid.ResId) bool {
+		return id.Name == "cm009"
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.Equal(t, 4, w.Size())
+
+	removed, err = w.RemoveMatchingId(makeCm(2).CurId().Equals)
+	assert.NoError(t, err)
+	assert.Equal(t, []resid.ResId{makeCm(2).CurId()}, removed)
+
+	removed, err = w.RemoveMatchingId(func(id resid.ResId) bool {
+		return id.Name != "cm003"
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]resid.ResId{makeCm(1).CurId(), makeCm(4).CurId()}, removed)
+	assert.Equal(t, []resid.ResId{makeCm(3).CurId()}, w.AllIds())
+	_, err = w.GetByCurrentId(makeCm(1).CurId())
+	assert.Error(t, err)
+	doAppend(t, w, makeCm(1))
+
+	_, err = w.RemoveMatchingId(nil)
+	assert.Error(t, err)
+}
+
+func TestRemoveBySelector(t *testing.T) {
+	w, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  labels:
+    environment: dev
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: b
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: NetworkPolicy
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+  labels:
+    environment: prod
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	count, err := w.RemoveBySelector(types.Selector{
+		Gvk: resid.Gvk{Kind: "Secret"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 4, w.Size())
+
+	_, err = w.RemoveBySelector(types.Selector{LabelSelector: "environment in dev"})
+	assert.Error(t, err)
+	assert.Equal(t, 4, w.Size())
+
+	count, err = w.RemoveBySelector(types.Selector{
+		Gvk: resid.Gvk{Kind: "NetworkPolicy"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = w.RemoveBySelector(types.Selector{LabelSelector: "environment=dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	var names []string
+	for _, r := range w.Resources() {
+		names = append(names, r.GetName())
+	}
+	assert.Equal(t, []string{"c"}, names)
+	r, err := w.GetByCurrentId(w.AllIds()[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "c", r.GetName())
+}
+
+func TestReplaceMatchingId(t *testing.T) {
+	w := New()
+	for i := 1; i <= 3; i++ {
+		doAppend(t, w, makeCm(i))
+	}
+	err := w.ReplaceMatchingId(func(id resid.ResId) bool {
+		return id.Name == "cm009"
+	}, makeCm(9))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "matched 0 resources")
+	}
+
+	err = w.ReplaceMatchingId(func(id resid.ResId) bool {
+		return id.Name != "cm002"
+	}, makeCm(9))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "matched 2 resources")
+	}
+
+	err = w.ReplaceMatchingId(makeCm(2).CurId().Equals, makeCm(3))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "that of another resource")
+	}
+
+	cm9 := makeCm(9)
+	assert.NoError(t, w.ReplaceMatchingId(makeCm(2).CurId().Equals, cm9))
+	assert.Equal(t,
+		[]resid.ResId{makeCm(1).CurId(), cm9.CurId(), makeCm(3).CurId()},
+		w.AllIds())
+	r, err := w.GetByCurrentId(cm9.CurId())
+	assert.NoError(t, err)
+	assert.True(t, r == cm9)
+	assert.Error(t, w.Append(makeCm(9)))
+	doAppend(t, w, makeCm(2))
+}
+
+func TestCurrentIdIndexFollowsIdChanges(t *testing.T) {
+	w := New()
+	for i := 1; i <= 3; i++ {
+		doAppend(t, w, makeCm(i))
+	}
+	r, err := w.GetByCurrentId(makeCm(2).CurId())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// Changes made without the ResMap.
+	r.SetName("cm009")
+	_, err = w.GetByCurrentId(makeCm(2).CurId())
+	assert.Error(t, err)
+	found, err := w.GetByCurrentId(makeCm(9).CurId())
+	assert.NoError(t, err)
+	assert.True(t, found == r)
+
+	err = r.ApplyFilter(kio.FilterFunc(
+		func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			return nodes, nodes[0].PipeE(kyaml.SetK8sName("cm008"))
+		}))
+	assert.NoError(t, err)
+	i, err := w.GetIndexOfCurrentId(makeCm(8).CurId())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.Error(t, w.Append(makeCm(8)))
+	doAppend(t, w, makeCm(9))
+
+	// Ids equal after normalization share an entry.
+	ns := resid.NewResIdWithNamespace(
+		resid.Gvk{Group: "core", Version: "v1", Kind: "ConfigMap"},
+		"cm001", "default")
+	i, err = w.GetIndexOfCurrentId(ns)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, i)
+
+	assert.NoError(t, w.Sort(GvkThenNameOrdering))
+	i, err = w.GetIndexOfCurrentId(makeCm(9).CurId())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, i)
+}
+
+func TestCurrentIdIndexesOfMapsSharingResources(t *testing.T) {
+	w1, w2 := New(), New()
+	for i := 1; i <= 3; i++ {
+		r := makeCm(i)
+		doAppend(t, w1, r)
+		doAppend(t, w2, r)
+	}
+	assert.NoError(t, w1.Rename(makeCm(2).CurId(), func(r *resource.Resource) error {
+		r.SetName("cm009")
+		return nil
+	}))
+	for _, w := range []ResMap{w1, w2} {
+		i, err := w.GetIndexOfCurrentId(makeCm(9).CurId())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, i)
+		i, err = w.GetIndexOfCurrentId(makeCm(2).CurId())
+		assert.NoError(t, err)
+		assert.Equal(t, -1, i)
+	}
+
+	// More maps sharing a resource than it keeps watches
+	// for make the earlier ones index afresh.
+	r := w1.GetByIndex(0)
+	for i := 0; i < 10; i++ {
+		doAppend(t, New(), r)
+	}
+	r.SetName("cm008")
+	for _, w := range []ResMap{w1, w2} {
+		found, err := w.GetByCurrentId(makeCm(8).CurId())
+		assert.NoError(t, err)
+		assert.True(t, found == r)
+	}
+
+	// A resource removed from a map no longer changes it.
+	assert.NoError(t, w2.Remove(makeCm(3).CurId()))
+	w1.GetByIndex(2).SetName("cm007")
+	assert.Error(t, w2.Append(makeCm(8)))
+	doAppend(t, w2, makeCm(7))
+}
+
+func BenchmarkRename(b *testing.B) {
+	w := New()
+	for i := 0; i < 5000; i++ {
+		if err := w.Append(makeCm(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := w.GetByIndex(i % w.Size())
+		if err := w.Rename(r.CurId(), func(r *resource.Resource) error {
+			r.SetName(fmt.Sprintf("x%d", i))
+			return nil
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetByCurrentId(b *testing.B) {
+	w := New()
+	var ids []resid.ResId
+	for i := 0; i < 10000; i++ {
+		r := makeCm(i)
+		ids = append(ids, r.CurId())
+		if err := w.Append(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			id := ids[i%len(ids)]
+			if len(w.GetMatchingResourcesByCurrentId(id.Equals)) != 1 {
+				b.Fatalf("no match for %s", id)
+			}
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := w.GetByCurrentId(ids[i%len(ids)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCopy(b *testing.B) {
+	w := New()
+	for i := 0; i < 4000; i++ {
+		r := rf.FromMap(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":   fmt.Sprintf("deploy%04d", i),
+				"labels": map[string]interface{}{"app": "web"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": 1,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": "app:v1",
+								"ports": []interface{}{
+									map[string]interface{}{"containerPort": 8080},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		if err := w.Append(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+	// A transformer changing 5% of the resources.
+	transform := func(m ResMap) {
+		for i, r := range m.Resources() {
+			if i%20 == 0 {
+				r.SetLabels(map[string]string{"app": "web", "tier": "front"})
+			}
+		}
+	}
+	b.Run("DeepCopy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transform(w.DeepCopy())
+		}
+	})
+	b.Run("LazyCopy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transform(w.LazyCopy())
+		}
+	})
+}
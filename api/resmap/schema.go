@@ -0,0 +1,82 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Schema answers questions about the OpenAPI definitions
+// of a build, those the builtin strategic merge patches
+// use, so that plugins can e.g. merge lists by their merge
+// keys rather than guess them.
+type Schema interface {
+	// LookupResource returns the definition of the given
+	// kind, or nil if there's none.
+	LookupResource(gvk resid.Gvk) *openapi.ResourceSchema
+
+	// IsAssociativeList returns the merge keys of the list
+	// at the given field path, e.g. spec, template, spec
+	// and containers, of the given kind, and true, if the
+	// list is merged by its keys, e.g. containers by name.
+	// The elements of lists along the path are looked
+	// into, as in the paths of field specs.  Lists of
+	// scalars, e.g. finalizers, have no keys, so aren't.
+	IsAssociativeList(gvk resid.Gvk, path ...string) ([]string, bool)
+}
+
+// NewSchema returns the Schema of the given OpenAPI
+// definitions, e.g. the built-in ones of Kubernetes with
+// those of custom resources added, or, if it's nil, of
+// the global ones of the openapi package.
+func NewSchema(s *openapi.Schemas) Schema {
+	return &openAPISchema{schemas: s}
+}
+
+type openAPISchema struct {
+	schemas *openapi.Schemas
+}
+
+// LookupResource implements Schema.
+func (s *openAPISchema) LookupResource(gvk resid.Gvk) *openapi.ResourceSchema {
+	t := yaml.TypeMeta{APIVersion: gvk.ApiVersion(), Kind: gvk.Kind}
+	if s.schemas == nil {
+		return openapi.SchemaForResourceType(t)
+	}
+	return s.schemas.SchemaForResourceType(t)
+}
+
+// IsAssociativeList implements Schema.
+func (s *openAPISchema) IsAssociativeList(
+	gvk resid.Gvk, path ...string) ([]string, bool) {
+	rs := s.LookupResource(gvk)
+	for _, field := range path {
+		if rs == nil {
+			return nil, false
+		}
+		if isArray(rs) {
+			rs = rs.Elements()
+			if rs == nil {
+				return nil, false
+			}
+		}
+		rs = rs.Field(field)
+	}
+	if rs == nil || !isArray(rs) {
+		return nil, false
+	}
+	strategy, keys := rs.PatchStrategyAndKeyList()
+	if !strings.Contains(strategy, "merge") || len(keys) == 0 {
+		return nil, false
+	}
+	return keys, true
+}
+
+func isArray(rs *openapi.ResourceSchema) bool {
+	return len(rs.Schema.Type) == 1 && rs.Schema.Type[0] == "array"
+}
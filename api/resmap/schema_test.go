@@ -0,0 +1,201 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/yaml"
+)
+
+// listMerger is a sample transformer plugin that merges
+// items into a list of each resource, by the merge keys
+// the OpenAPI definitions give the list, e.g. containers
+// by name, rather than guessing them.
+type listMerger struct {
+	h     *PluginHelpers
+	Path  []string                 `json:"path"`
+	Items []map[string]interface{} `json:"items"`
+}
+
+func (p *listMerger) Config(h *PluginHelpers, c []byte) error {
+	p.h = h
+	return yaml.Unmarshal(c, p)
+}
+
+func (p *listMerger) Transform(m ResMap) error {
+	path := strings.Join(p.Path, ".")
+	for _, r := range m.Resources() {
+		keys, ok := p.h.Schema().IsAssociativeList(r.GetGvk(), p.Path...)
+		if !ok {
+			continue
+		}
+		v, err := r.GetFieldValue(path)
+		if err != nil {
+			return err
+		}
+		list, _ := v.([]interface{})
+		for _, item := range p.Items {
+			list = mergeItem(list, item, keys)
+		}
+		if err = r.SetFieldValue(path, list, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeItem merges the item into the element of the list
+// with the same keys, or appends it if there's none.
+func mergeItem(
+	list []interface{}, item map[string]interface{},
+	keys []string) []interface{} {
+	for _, e := range list {
+		element, _ := e.(map[string]interface{})
+		same := true
+		for _, k := range keys {
+			same = same && reflect.DeepEqual(element[k], item[k])
+		}
+		if same {
+			for k, v := range item {
+				element[k] = v
+			}
+			return list
+		}
+	}
+	return append(list, item)
+}
+
+func TestSchemaSamplePlugin(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1
+      - name: proxy
+        image: proxy:1
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  containers:
+  - name: web
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	p := &listMerger{}
+	assert.NoError(t, p.Config(
+		NewPluginHelpers(nil, valtest_test.MakeFakeValidator(), rmF, NewSchema(nil)),
+		[]byte(`
+path: [spec, template, spec, containers]
+items:
+- name: proxy
+  image: proxy:2
+- name: sidecar
+  image: sidecar:1
+`)))
+	assert.NoError(t, p.Transform(m))
+	actual, err := m.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimLeft(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: web:1
+        name: web
+      - image: proxy:2
+        name: proxy
+      - image: sidecar:1
+        name: sidecar
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  containers:
+  - name: web
+`, "\n"), string(actual))
+}
+
+// widgetSchema merges the items of a Widget's spec by name.
+const widgetSchema = `{
+  "definitions": {
+    "com.example.v1.Widget": {
+      "type": "object",
+      "properties": {
+        "spec": {
+          "type": "object",
+          "properties": {
+            "items": {
+              "type": "array",
+              "items": {"type": "object"},
+              "x-kubernetes-patch-merge-key": "name",
+              "x-kubernetes-patch-strategy": "merge"
+            }
+          }
+        }
+      },
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.com", "version": "v1", "kind": "Widget"}
+      ]
+    }
+  }
+}`
+
+func TestSchemaIsAssociativeList(t *testing.T) {
+	deployment := resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}
+	widget := resid.Gvk{Group: "example.com", Version: "v1", Kind: "Widget"}
+	s := NewSchema(nil)
+	assert.NotNil(t, s.LookupResource(deployment))
+	assert.Nil(t, s.LookupResource(widget))
+	for _, tc := range []struct {
+		path []string
+		keys []string
+	}{
+		{path: []string{"spec", "template", "spec", "containers"},
+			keys: []string{"name"}},
+		{path: []string{"spec", "template", "spec", "containers", "ports"},
+			keys: []string{"containerPort", "protocol"}},
+		{path: []string{"spec", "template", "spec", "containers", "args"}},
+		{path: []string{"metadata", "finalizers"}},
+		{path: []string{"spec", "replicas"}},
+		{path: []string{"spec", "nothing", "here"}},
+	} {
+		keys, ok := s.IsAssociativeList(deployment, tc.path...)
+		assert.Equal(t, tc.keys != nil, ok, tc.path)
+		assert.Equal(t, tc.keys, keys, tc.path)
+	}
+	_, ok := s.IsAssociativeList(widget, "spec", "items")
+	assert.False(t, ok)
+
+	schemas := openapi.NewSchemas()
+	if !assert.NoError(t, schemas.AddSchema([]byte(widgetSchema))) {
+		t.FailNow()
+	}
+	keys, ok := NewSchema(schemas).IsAssociativeList(widget, "spec", "items")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"name"}, keys)
+}
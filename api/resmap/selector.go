@@ -0,0 +1,203 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	kyaml_yaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Matcher is a types.Selector that has been validated and
+// compiled, so it can be matched against any number of
+// resources without being parsed again.  Plugins that
+// select resources more than once should make one in
+// their Config method, and pass it to ResMap.SelectMatching.
+type Matcher struct {
+	regex       *types.SelectorRegex
+	labels      *kyaml_yaml.LabelSelector
+	annotations *kyaml_yaml.LabelSelector
+	// original is true if the ids are matched against
+	// only the original ids of resources.
+	original bool
+	fields   []types.FieldValueSelector
+}
+
+// NewSelectorMatcher validates and compiles the given
+// selector.  The error, if any, reports every problem
+// with the selector, not just the first one.
+func NewSelectorMatcher(s types.Selector) (*Matcher, error) {
+	return NewSelectorMatcherWithOptions(s, resid.GvkMatchOptions{})
+}
+
+// NewSelectorMatcherWithOptions is like NewSelectorMatcher,
+// but matches Gvks per the given options.
+func NewSelectorMatcherWithOptions(
+	s types.Selector, opts resid.GvkMatchOptions) (*Matcher, error) {
+	var problems []string
+	var err error
+	m := &Matcher{original: s.MatchOriginalIds}
+	m.regex, err = types.NewSelectorRegexWithOptions(&s, opts)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	m.labels, err = kyaml_yaml.ParseLabelSelector(s.LabelSelector)
+	if err != nil {
+		problems = append(problems, "bad labelSelector: "+err.Error())
+	}
+	m.annotations, err = kyaml_yaml.ParseLabelSelector(s.AnnotationSelector)
+	if err != nil {
+		problems = append(problems, "bad annotationSelector: "+err.Error())
+	}
+	for _, f := range s.FieldSelectors {
+		if f.Path == "" {
+			problems = append(problems, "bad fieldSelector: no path")
+			continue
+		}
+		switch f.Value.(type) {
+		case map[string]interface{}, []interface{}:
+			problems = append(problems, fmt.Sprintf(
+				"bad fieldSelector: value of %s isn't a scalar", f.Path))
+			continue
+		}
+		f.Value = scalarValue(f.Value)
+		m.fields = append(m.fields, f)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"invalid selector: %s", strings.Join(problems, "; "))
+	}
+	return m, nil
+}
+
+// Matches returns true if the resource is selected.
+// The namespace and name are matched against those of
+// both the original and the current id of the resource,
+// and the Gvk against the current one, unless the selector
+// matches original ids, in which case the namespace, name
+// and Gvk are matched against just the original ones.
+func (m *Matcher) Matches(r *resource.Resource) bool {
+	if !m.matchesId(r) {
+		return false
+	}
+	return m.labels.Matches(r.GetLabels()) &&
+		m.annotations.Matches(r.GetAnnotations()) &&
+		m.matchesFields(r)
+}
+
+func (m *Matcher) matchesFields(r *resource.Resource) bool {
+	for _, f := range m.fields {
+		// A missing field doesn't match.
+		v, err := r.GetFieldValue(f.Path)
+		if err != nil {
+			return false
+		}
+		values, isList := v.([]interface{})
+		if !isList || !strings.Contains(f.Path, "*") {
+			values = []interface{}{v}
+		}
+		found := false
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				v = decodeScalar(s)
+			}
+			if scalarValue(v) == f.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeScalar returns the value of the given YAML scalar,
+// as GetFieldValue returns a scalar's text, unless kyaml
+// isn't in use, e.g. 3 for "3" and true for "true".
+func decodeScalar(s string) interface{} {
+	var v interface{}
+	if err := kyaml_yaml.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	switch v.(type) {
+	case bool, int, float64:
+		return v
+	}
+	return s
+}
+
+// scalarValue returns the given scalar with any number as
+// a float64, so numbers compare by value.
+func scalarValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case uint64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	}
+	return v
+}
+
+func (m *Matcher) matchesId(r *resource.Resource) bool {
+	orgId := r.OrgId()
+	if m.original {
+		return m.regex.MatchNamespace(orgId.EffectiveNamespace()) &&
+			m.regex.MatchName(orgId.Name) &&
+			m.regex.MatchGvk(r.OrgGvk())
+	}
+	curId := r.GetCurIdFast()
+	if !m.regex.MatchNamespace(orgId.EffectiveNamespace()) &&
+		!m.regex.MatchNamespace(curId.EffectiveNamespace()) {
+		return false
+	}
+	if !m.regex.MatchName(orgId.Name) &&
+		!m.regex.MatchName(curId.Name) {
+		return false
+	}
+	return m.regex.MatchGvk(r.GetGvk())
+}
+
+// ErrIfVersionAmbiguous returns an error if any two of the
+// given resources, e.g. those a selector selected, differ
+// only by version, listing each such pair.  A selector that
+// omits the version selects every version of a resource,
+// which, if there's more than one, is rarely what's meant.
+func ErrIfVersionAmbiguous(selected []*resource.Resource) error {
+	var problems []string
+	for i, r := range selected {
+		for _, o := range selected[:i] {
+			if differOnlyByVersion(o.GetCurIdFast(), r.GetCurIdFast()) {
+				problems = append(problems,
+					fmt.Sprintf("%s and %s", o.GetCurIdFast(), r.GetCurIdFast()))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"selector is ambiguous; give a version to select just one of"+
+			" the resources that differ only by version:\n  %s",
+		strings.Join(problems, "\n  "))
+}
+
+func differOnlyByVersion(x, y resid.ResId) bool {
+	return x.Version != y.Version &&
+		resid.NormalizeGroup(x.Group) == resid.NormalizeGroup(y.Group) &&
+		x.Kind == y.Kind &&
+		x.Name == y.Name &&
+		x.EffectiveNamespace() == y.EffectiveNamespace()
+}
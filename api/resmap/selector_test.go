@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/resid"
 	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -183,3 +184,250 @@ func TestFindPatchTargets(t *testing.T) {
 			t, testcase.count, len(actual), "test=%s target=%v", n, testcase.target)
 	}
 }
+
+func TestNewSelectorMatcherReportsAllProblems(t *testing.T) {
+	_, err := NewSelectorMatcher(types.Selector{
+		Gvk:                resid.Gvk{Kind: "Kind("},
+		Name:               "name[",
+		LabelSelector:      "app in (a",
+		AnnotationSelector: "foo=bar",
+	})
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, err.Error(), "invalid selector: ")
+	assert.Contains(t, err.Error(), "bad kind regex")
+	assert.Contains(t, err.Error(), "bad name regex")
+	assert.Contains(t, err.Error(), "bad labelSelector")
+	assert.NotContains(t, err.Error(), "annotationSelector")
+}
+
+func TestSelectMatching(t *testing.T) {
+	rm := setupRMForPatchTargets(t)
+	matcher, err := NewSelectorMatcher(types.Selector{
+		Name:               "name.*",
+		LabelSelector:      "app",
+		AnnotationSelector: "foo=bar",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var names []string
+	for _, r := range rm.SelectMatching(matcher) {
+		names = append(names, r.GetName())
+	}
+	assert.Equal(t, []string{"name1", "name2"}, names)
+}
+
+func TestSelectVersionAmbiguity(t *testing.T) {
+	rm, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: db
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	selected, err := rm.Select(types.Selector{
+		Gvk:  resid.Gvk{Group: "apps", Kind: "Deployment"},
+		Name: "db",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(selected))
+
+	selected, err = rm.Select(types.Selector{
+		Gvk: resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(selected))
+
+	_, err = rm.Select(types.Selector{
+		Gvk: resid.Gvk{Kind: "Deployment"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "selector is ambiguous")
+		assert.Contains(t, err.Error(),
+			"apps_v1_Deployment|~X|web and apps_v1beta2_Deployment|~X|web")
+		assert.NotContains(t, err.Error(), "db")
+	}
+}
+
+func TestSelectMatchOriginalIds(t *testing.T) {
+	rm, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: db
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	web := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"}, "web")
+	assert.NoError(t, rm.Rename(web, func(r *resource.Resource) error {
+		r.SetOriginalName(r.GetName(), false)
+		r.SetName("prod-web")
+		r.SetLabels(map[string]string{"app": "prod-web"})
+		return nil
+	}))
+	db := resid.NewResId(
+		resid.Gvk{Group: "apps", Version: "v1beta2", Kind: "Deployment"}, "db")
+	assert.NoError(t, rm.Rename(db, func(r *resource.Resource) error {
+		r.SetGvk(resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"})
+		return nil
+	}))
+
+	names := func(s types.Selector) []string {
+		selected, err := rm.Select(s)
+		assert.NoError(t, err)
+		var result []string
+		for _, r := range selected {
+			result = append(result, r.GetName())
+		}
+		return result
+	}
+	testCases := map[string]struct {
+		selector types.Selector
+		expected []string
+	}{
+		"original name": {
+			selector: types.Selector{Name: "web", MatchOriginalIds: true},
+			expected: []string{"prod-web"},
+		},
+		"current name": {
+			selector: types.Selector{Name: "prod-web", MatchOriginalIds: true},
+		},
+		"current name by default": {
+			selector: types.Selector{Name: "prod-web"},
+			expected: []string{"prod-web"},
+		},
+		"original version": {
+			selector: types.Selector{
+				Gvk:              resid.Gvk{Version: "v1beta2", Kind: "Deployment"},
+				MatchOriginalIds: true,
+			},
+			expected: []string{"db"},
+		},
+		"original version not by default": {
+			selector: types.Selector{
+				Gvk: resid.Gvk{Version: "v1beta2", Kind: "Deployment"},
+			},
+		},
+		"current labels": {
+			selector: types.Selector{
+				Name:             "web",
+				LabelSelector:    "app=prod-web",
+				MatchOriginalIds: true,
+			},
+			expected: []string{"prod-web"},
+		},
+		"original labels": {
+			selector: types.Selector{
+				Name:             "web",
+				LabelSelector:    "app=web",
+				MatchOriginalIds: true,
+			},
+		},
+	}
+	for n, tc := range testCases {
+		assert.Equal(t, tc.expected, names(tc.selector), n)
+	}
+}
+
+func TestSelectFieldSelectors(t *testing.T) {
+	rm, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+  ports:
+  - port: 80
+    protocol: TCP
+  - port: 53
+    protocol: UDP
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  type: ClusterIP
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  paused: true
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	names := func(fields ...types.FieldValueSelector) []string {
+		selected, err := rm.Select(types.Selector{FieldSelectors: fields})
+		assert.NoError(t, err)
+		var result []string
+		for _, r := range selected {
+			result = append(result, r.CurId().String())
+		}
+		return result
+	}
+	lb := types.FieldValueSelector{Path: "spec.type", Value: "LoadBalancer"}
+	assert.Equal(t, []string{"~G_v1_Service|~X|web"}, names(lb))
+	assert.Equal(t, []string{"~G_v1_Service|~X|web"}, names(
+		lb, types.FieldValueSelector{Path: "spec.ports[*].protocol", Value: "UDP"}))
+	assert.Empty(t, names(
+		lb, types.FieldValueSelector{Path: "spec.ports[*].protocol", Value: "SCTP"}))
+
+	// Numbers and booleans compare by value.
+	deployment := []string{"apps_v1_Deployment|~X|web"}
+	assert.Equal(t, deployment, names(
+		types.FieldValueSelector{Path: "spec.replicas", Value: 3}))
+	assert.Equal(t, deployment, names(
+		types.FieldValueSelector{Path: "spec.replicas", Value: 3.0}))
+	assert.Equal(t, deployment, names(
+		types.FieldValueSelector{Path: "spec.paused", Value: true}))
+	assert.Empty(t, names(
+		types.FieldValueSelector{Path: "spec.replicas", Value: "3"}))
+	assert.Empty(t, names(
+		types.FieldValueSelector{Path: "spec.paused", Value: "true"}))
+
+	// A missing field doesn't match.
+	assert.Empty(t, names(
+		types.FieldValueSelector{Path: "spec.replicas", Value: 0}))
+}
+
+func TestSelectFieldSelectorsProblems(t *testing.T) {
+	_, err := NewSelectorMatcher(types.Selector{
+		FieldSelectors: []types.FieldValueSelector{
+			{Value: "a"},
+			{Path: "spec.template", Value: map[string]interface{}{}},
+		},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "bad fieldSelector: no path")
+		assert.Contains(t, err.Error(),
+			"bad fieldSelector: value of spec.template isn't a scalar")
+	}
+}
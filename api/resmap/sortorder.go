@@ -0,0 +1,79 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// SortOrderAnnotation places a resource in the output
+// where the sort order of the build can't, e.g. a smoke
+// test Job that must be last.  Its value is first, last
+// or an integer; see SortByOrderAnnotation.
+const SortOrderAnnotation = "kustomize.config.k8s.io/sort-order"
+
+const (
+	firstSortOrder = "first"
+	lastSortOrder  = "last"
+)
+
+// SortByOrderAnnotation moves the resources having the
+// SortOrderAnnotation as it says, on top of the order the
+// build's sort left them in, and removes the annotation.
+// Resources with first come before all others, and those
+// with last after all others.  Integers place resources
+// relative to those lacking the annotation, which count as
+// 0, so -1 is before them and 1 after.  The sort is stable,
+// so resources with the same value, e.g. two with last or
+// two with 5, keep the order they were in; that isn't an
+// error.  A value that's none of these is.
+func SortByOrderAnnotation(m ResMap) error {
+	ranks := make(map[*resource.Resource]int64)
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		value, found := annotations[SortOrderAnnotation]
+		if !found {
+			continue
+		}
+		rank, err := sortOrderRank(value)
+		if err != nil {
+			return fmt.Errorf("resource %s: %v", r.CurId(), err)
+		}
+		ranks[r] = rank
+		delete(annotations, SortOrderAnnotation)
+		if err = r.SetAnnotationsE(annotations); err != nil {
+			return err
+		}
+	}
+	if len(ranks) == 0 {
+		return nil
+	}
+	return m.Sort(ResourceOrderingFunc(func(a, b *resource.Resource) bool {
+		return ranks[a] < ranks[b]
+	}))
+}
+
+// sortOrderRank returns the rank of a SortOrderAnnotation
+// value; the integers are limited to 32 bits so that first
+// and last rank beyond them.
+func sortOrderRank(value string) (int64, error) {
+	switch value = strings.TrimSpace(value); value {
+	case firstSortOrder:
+		return math.MinInt64, nil
+	case lastSortOrder:
+		return math.MaxInt64, nil
+	}
+	rank, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"annotation %s is %q; it must be %s, %s or an integer",
+			SortOrderAnnotation, value, firstSortOrder, lastSortOrder)
+	}
+	return rank, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/resmap"
+)
+
+func TestSortByOrderAnnotation(t *testing.T) {
+	m := New()
+	add := func(name, order string) {
+		metadata := map[string]interface{}{"name": name}
+		if order != "" {
+			metadata["annotations"] = map[string]interface{}{
+				SortOrderAnnotation: order,
+			}
+		}
+		doAppend(t, m, rf.FromMap(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   metadata,
+		}))
+	}
+	add("smoke", "last")
+	add("a", "")
+	add("late1", "5")
+	add("early", "-1")
+	add("b", "")
+	add("late2", "5")
+	add("crd", "first")
+	add("zero", "0")
+	add("final", "last")
+
+	assert.NoError(t, SortByOrderAnnotation(m))
+	var names []string
+	for _, r := range m.Resources() {
+		names = append(names, r.GetName())
+		assert.Empty(t, r.GetAnnotations())
+	}
+	// Equal values keep their order, e.g. late1 and late2.
+	assert.Equal(t, []string{
+		"crd", "early", "a", "b", "zero", "late1", "late2",
+		"smoke", "final"}, names)
+}
+
+func TestSortByOrderAnnotationErrors(t *testing.T) {
+	for _, value := range []string{"soon", "1.5", "99999999999"} {
+		m := New()
+		doAppend(t, m, rf.FromMap(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "a",
+				"annotations": map[string]interface{}{
+					SortOrderAnnotation: value,
+				},
+			},
+		}))
+		err := SortByOrderAnnotation(m)
+		if assert.Error(t, err, value) {
+			assert.Contains(t, err.Error(), "must be first, last or an integer")
+		}
+	}
+}
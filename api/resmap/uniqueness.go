@@ -0,0 +1,206 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// DefaultUniquenessRules are the uniqueness rules checked
+// when no others are given: a nodePort may be claimed by
+// one Service only, and a host and path by one Ingress.
+var DefaultUniquenessRules = []types.UniquenessRule{
+	{
+		Name:  "service-node-port",
+		Gvk:   resid.Gvk{Kind: "Service"},
+		Paths: []string{"spec/ports/nodePort"},
+		Scope: types.ClusterUniquenessScope,
+	},
+	{
+		Name: "ingress-host-path",
+		Gvk:  resid.Gvk{Kind: "Ingress"},
+		Paths: []string{
+			"spec/rules/host",
+			"spec/rules/http/paths/path",
+		},
+		Scope: types.ClusterUniquenessScope,
+	},
+}
+
+// UniquenessViolation is a group of resources sharing
+// values that a UniquenessRule says must be unique.
+type UniquenessViolation struct {
+	Rule string
+
+	// Namespace is the namespace the resources share,
+	// if the rule has the namespace scope.
+	Namespace string
+
+	// Paths are those of the rule, and Values the
+	// values, one per path, that the resources share.
+	Paths  []string
+	Values []string
+
+	// Ids are the ids of the resources, in the order
+	// of the ResMap.
+	Ids []resid.ResId
+}
+
+func (v UniquenessViolation) String() string {
+	var ids, fields []string
+	for _, id := range v.Ids {
+		s := id.Kind + "/" + id.Name
+		if v.Namespace == "" && id.Namespace != "" {
+			s += " in namespace '" + id.Namespace + "'"
+		}
+		ids = append(ids, s)
+	}
+	for i, p := range v.Paths {
+		fields = append(fields, p+"="+v.Values[i])
+	}
+	where := ""
+	if v.Namespace != "" {
+		where = " in namespace '" + v.Namespace + "'"
+	}
+	return fmt.Sprintf("rule '%s': %s%s share %s",
+		v.Rule, strings.Join(ids, ", "), where, strings.Join(fields, ", "))
+}
+
+// FindUniquenessViolations returns, rule by rule, every
+// group of resources in m that share the values the rule
+// says must be unique.  The error, if any, is that of an
+// invalid rule.
+func FindUniquenessViolations(
+	m ResMap, rules []types.UniquenessRule) ([]UniquenessViolation, error) {
+	var result []UniquenessViolation
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		result = append(result, findUniquenessViolations(m, rule)...)
+	}
+	return result, nil
+}
+
+func findUniquenessViolations(
+	m ResMap, rule types.UniquenessRule) []UniquenessViolation {
+	paths := rule.PathSlices()
+	var keys []string
+	groups := make(map[string]*UniquenessViolation)
+	for _, r := range m.Resources() {
+		if !r.GetGvk().IsSelected(&rule.Gvk) {
+			continue
+		}
+		id := r.CurId()
+		ns := ""
+		if rule.Scope != types.ClusterUniquenessScope {
+			ns = id.EffectiveNamespace()
+		}
+		seen := make(map[string]bool)
+		for _, values := range valuesAt(r.Map(), paths) {
+			key := ns + "\x00" + strings.Join(values, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			g, found := groups[key]
+			if !found {
+				g = &UniquenessViolation{
+					Rule:      rule.Name,
+					Namespace: ns,
+					Paths:     rule.Paths,
+					Values:    values,
+				}
+				groups[key] = g
+				keys = append(keys, key)
+			}
+			g.Ids = append(g.Ids, id)
+		}
+	}
+	var result []UniquenessViolation
+	for _, key := range keys {
+		if g := groups[key]; len(g.Ids) > 1 {
+			result = append(result, *g)
+		}
+	}
+	return result
+}
+
+// valuesAt returns the values that the given paths have
+// in obj, as tuples holding a value per path.  Lists on
+// the way are gone into, the paths sharing a list taking
+// their values from the same element.
+func valuesAt(obj interface{}, paths [][]string) [][]string {
+	if list, ok := obj.([]interface{}); ok {
+		var result [][]string
+		for _, e := range list {
+			result = append(result, valuesAt(e, paths)...)
+		}
+		return result
+	}
+	tuples := [][]string{make([]string, len(paths))}
+	var fields []string
+	byField := make(map[string][]int)
+	for i, p := range paths {
+		if len(p) > 0 {
+			if _, found := byField[p[0]]; !found {
+				fields = append(fields, p[0])
+			}
+			byField[p[0]] = append(byField[p[0]], i)
+			continue
+		}
+		v, ok := scalarString(obj)
+		if !ok {
+			return nil
+		}
+		for _, t := range tuples {
+			t[i] = v
+		}
+	}
+	if len(fields) == 0 {
+		return tuples
+	}
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, f := range fields {
+		child, found := m[f]
+		if !found {
+			return nil
+		}
+		var rest [][]string
+		for _, i := range byField[f] {
+			rest = append(rest, paths[i][1:])
+		}
+		sub := valuesAt(child, rest)
+		var product [][]string
+		for _, t := range tuples {
+			for _, s := range sub {
+				u := append([]string(nil), t...)
+				for k, i := range byField[f] {
+					u[i] = s[k]
+				}
+				product = append(product, u)
+			}
+		}
+		if len(product) == 0 {
+			return nil
+		}
+		tuples = product
+	}
+	return tuples
+}
+
+func scalarString(obj interface{}) (string, bool) {
+	switch obj.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return "", false
+	}
+	return fmt.Sprintf("%v", obj), true
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resmap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/resid"
+	. "sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const uniquenessInput = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: a
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30080
+  - port: 443
+    nodePort: 30443
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: b
+spec:
+  type: NodePort
+  ports:
+  - port: 8080
+    nodePort: 30080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: internal
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: a
+spec:
+  rules:
+  - host: example.com
+    http:
+      paths:
+      - path: /
+      - path: /api
+  - host: www.example.com
+    http:
+      paths:
+      - path: /
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+  namespace: a
+spec:
+  rules:
+  - host: www.example.com
+    http:
+      paths:
+      - path: /api
+  - host: example.com
+    http:
+      paths:
+      - path: /api
+`
+
+func TestFindUniquenessViolations(t *testing.T) {
+	m, err := rmF.NewResMapFromBytes([]byte(uniquenessInput))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	service := resid.Gvk{Version: "v1", Kind: "Service"}
+	ingress := resid.Gvk{
+		Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+
+	violations, err := FindUniquenessViolations(m, DefaultUniquenessRules)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []UniquenessViolation{
+		{
+			Rule:   "service-node-port",
+			Paths:  []string{"spec/ports/nodePort"},
+			Values: []string{"30080"},
+			Ids: []resid.ResId{
+				resid.NewResIdWithNamespace(service, "web", "a"),
+				resid.NewResIdWithNamespace(service, "api", "b"),
+			},
+		},
+		{
+			Rule:   "ingress-host-path",
+			Paths:  []string{"spec/rules/host", "spec/rules/http/paths/path"},
+			Values: []string{"example.com", "/api"},
+			Ids: []resid.ResId{
+				resid.NewResIdWithNamespace(ingress, "web", "a"),
+				resid.NewResIdWithNamespace(ingress, "api", "a"),
+			},
+		},
+	}, violations)
+	assert.Equal(t,
+		"rule 'service-node-port': Service/web in namespace 'a', "+
+			"Service/api in namespace 'b' share spec/ports/nodePort=30080",
+		violations[0].String())
+
+	// In the namespace scope, the nodePorts don't collide.
+	violations, err = FindUniquenessViolations(m, []types.UniquenessRule{{
+		Name:  "port",
+		Gvk:   resid.Gvk{Kind: "Service"},
+		Paths: []string{"spec/ports/nodePort"},
+	}})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = FindUniquenessViolations(m, []types.UniquenessRule{{
+		Name:  "host",
+		Gvk:   resid.Gvk{Kind: "Ingress"},
+		Paths: []string{"spec/rules/host"},
+	}})
+	assert.NoError(t, err)
+	if assert.Len(t, violations, 2) {
+		assert.Equal(t,
+			"rule 'host': Ingress/web, Ingress/api in namespace 'a' "+
+				"share spec/rules/host=example.com",
+			violations[0].String())
+	}
+}
+
+func TestFindUniquenessViolationsBadRule(t *testing.T) {
+	_, err := FindUniquenessViolations(New(), []types.UniquenessRule{{
+		Name:  "port",
+		Paths: []string{"spec/ports/nodePort"},
+		Scope: "world",
+	}})
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t,
+		"uniqueness rule 'port' has scope 'world'; it must be namespace or cluster",
+		err.Error())
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// GetDirectives returns the YAML directives, e.g.
+// %YAML 1.1, of the document the resource was read
+// from, or nil if it had none.
+func (r *Resource) GetDirectives() []string {
+	return r.directives
+}
+
+// SetDirectives sets the YAML directives of the resource,
+// which ResMap.AsYamlWithDirectives writes before it.
+func (r *Resource) SetDirectives(d []string) {
+	r.directives = d
+}
+
+// document is a YAML document of a stream, and the
+// directives preceding it.
+type document struct {
+	directives []string
+	body       []byte
+}
+
+// hasDirectives returns true if any line of the
+// input starts with %, as a directive does.
+func hasDirectives(in []byte) bool {
+	return bytes.HasPrefix(in, []byte("%")) ||
+		bytes.Contains(in, []byte("\n%"))
+}
+
+// splitDocuments splits a YAML stream into its documents,
+// each with the directives preceding its --- marker.  The
+// directives are checked, and removed from the documents,
+// which are rewritten, if they use %TAG handles, to use
+// the tags the handles stand for.  Lines starting with %
+// that aren't followed by a --- marker aren't directives,
+// and are left for the YAML parser to judge.
+func splitDocuments(in []byte) ([]document, error) {
+	var docs []document
+	var body, pending []string
+	var directives []string
+	started := false
+	finish := func() {
+		if started || strings.TrimSpace(strings.Join(body, "")) != "" {
+			docs = append(docs, document{
+				directives: directives,
+				body:       []byte(strings.Join(body, "\n") + "\n"),
+			})
+		}
+		body, directives, started = nil, nil, false
+	}
+	for _, line := range strings.Split(string(in), "\n") {
+		trimmed := strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "%"):
+			pending = append(pending, trimmed)
+		case isDocumentStart(trimmed):
+			finish()
+			directives, pending = pending, nil
+			started = true
+			body = append(body, line)
+		case isDocumentEnd(trimmed) && len(pending) == 0:
+			finish()
+		default:
+			body = append(body, pending...)
+			pending = nil
+			body = append(body, line)
+		}
+	}
+	body = append(body, pending...)
+	finish()
+	for i := range docs {
+		if err := docs[i].resolveDirectives(i); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func isDocumentStart(line string) bool {
+	return line == "---" || strings.HasPrefix(line, "--- ") ||
+		strings.HasPrefix(line, "---\t")
+}
+
+func isDocumentEnd(line string) bool {
+	return line == "..." || strings.HasPrefix(line, "... ") ||
+		strings.HasPrefix(line, "...\t")
+}
+
+var tagHandleRegexp = regexp.MustCompile(`^!([0-9A-Za-z-]*!)?$`)
+
+// resolveDirectives checks the directives of the document,
+// the index-th of its stream, keeping them normalized,
+// and rewrites the body to use the tags %TAG directives
+// give handles to.
+func (d *document) resolveDirectives(index int) error {
+	if len(d.directives) == 0 {
+		return nil
+	}
+	var normalized, tags []string
+	seenVersion := false
+	for _, raw := range d.directives {
+		fields := strings.Fields(raw)
+		for i, f := range fields {
+			if strings.HasPrefix(f, "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		directive := strings.Join(fields, " ")
+		fail := func(format string, args ...interface{}) error {
+			return kusterr.DirectiveError{
+				Index:     index,
+				Directive: directive,
+				Reason:    fmt.Sprintf(format, args...),
+			}
+		}
+		switch fields[0] {
+		case "%YAML":
+			if len(fields) != 2 {
+				return fail("expected %%YAML and a version, e.g. %%YAML 1.1")
+			}
+			if seenVersion {
+				return fail("a document may have only one %%YAML directive")
+			}
+			seenVersion = true
+			if fields[1] != "1.1" && fields[1] != "1.2" {
+				return fail("only YAML 1.1 and 1.2 are supported")
+			}
+		case "%TAG":
+			if len(fields) != 3 {
+				return fail("expected %%TAG, a handle and a prefix, " +
+					"e.g. %%TAG !e! tag:example.com,2021:")
+			}
+			if !tagHandleRegexp.MatchString(fields[1]) {
+				return fail("'%s' is not a tag handle, e.g. !e!", fields[1])
+			}
+			tags = append(tags, directive)
+		default:
+			return fail("only %%YAML and %%TAG directives are supported")
+		}
+		normalized = append(normalized, directive)
+	}
+	d.directives = normalized
+	if len(tags) == 0 {
+		return nil
+	}
+	// The parsers of resources don't take directives, so
+	// have the tags written in full instead of by handle.
+	var n yaml.Node
+	err := yaml.NewDecoder(bytes.NewReader([]byte(
+		strings.Join(tags, "\n") + "\n" + string(d.body)))).Decode(&n)
+	if err == io.EOF {
+		// An empty document.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s, err := yaml.NewRNode(&n).String()
+	if err != nil {
+		return err
+	}
+	d.body = []byte("---\n" + s)
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+var factories = map[string]*resource.Factory{
+	"kyaml":   provider.NewDepProvider(true).GetResourceFactory(),
+	"k8sdeps": provider.NewDepProvider(false).GetResourceFactory(),
+}
+
+func TestSliceFromBytesDirectives(t *testing.T) {
+	in := []byte(`%YAML 1.1
+%TAG !e! tag:example.com,2021:
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  x: !e!text hello
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+...
+%YAML 1.2   # a comment
+--- !!map
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: c
+`)
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			rs, err := factory.SliceFromBytes(in)
+			if !assert.NoError(t, err) || !assert.Len(t, rs, 3) {
+				t.FailNow()
+			}
+			assert.Equal(t, []string{
+				"%YAML 1.1", "%TAG !e! tag:example.com,2021:"},
+				rs[0].GetDirectives())
+			v, err := rs[0].GetString("data.x")
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", v)
+			assert.Nil(t, rs[1].GetDirectives())
+			assert.Equal(t, []string{"%YAML 1.2"}, rs[2].GetDirectives())
+			assert.Equal(t, "c", rs[2].GetName())
+			assert.Equal(t, rs[0].GetDirectives(),
+				rs[0].DeepCopy().GetDirectives())
+		})
+	}
+}
+
+func TestSliceFromBytesBadDirectives(t *testing.T) {
+	testCases := map[string]struct {
+		in       string
+		expected kusterr.DirectiveError
+	}{
+		"unknown": {
+			in: "%FOO bar\n---\nkind: ConfigMap\n",
+			expected: kusterr.DirectiveError{
+				Directive: "%FOO bar",
+				Reason:    "only %YAML and %TAG directives are supported",
+			},
+		},
+		"version": {
+			in: "kind: ConfigMap\nmetadata:\n  name: a\n---\n" +
+				"kind: ConfigMap\nmetadata:\n  name: b\n...\n" +
+				"%YAML 2.0\n---\nkind: ConfigMap\n",
+			expected: kusterr.DirectiveError{
+				Index:     2,
+				Directive: "%YAML 2.0",
+				Reason:    "only YAML 1.1 and 1.2 are supported",
+			},
+		},
+		"two versions": {
+			in: "%YAML 1.1\n%YAML 1.2\n---\nkind: ConfigMap\n",
+			expected: kusterr.DirectiveError{
+				Directive: "%YAML 1.2",
+				Reason:    "a document may have only one %YAML directive",
+			},
+		},
+		"tag handle": {
+			in: "%TAG e tag:example.com,2021:\n---\nkind: ConfigMap\n",
+			expected: kusterr.DirectiveError{
+				Directive: "%TAG e tag:example.com,2021:",
+				Reason:    "'e' is not a tag handle, e.g. !e!",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		for fName, factory := range factories {
+			_, err := factory.SliceFromBytes([]byte(tc.in))
+			assert.Equal(t, tc.expected, err, name+" "+fName)
+		}
+	}
+	assert.EqualError(t,
+		kusterr.WithSource(testCases["version"].expected, "cm.yaml"),
+		"document 2 in cm.yaml has the unsupported directive "+
+			"'%YAML 2.0': only YAML 1.1 and 1.2 are supported")
+}
@@ -12,11 +12,14 @@ import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 )
 
 // Factory makes instances of Resource.
 type Factory struct {
-	kf ifc.KunstructuredFactory
+	kf      ifc.KunstructuredFactory
+	limits  types.InputLimits
+	schemas *openapi.Schemas
 }
 
 // NewFactory makes an instance of Factory.
@@ -71,6 +74,7 @@ func (rf *Factory) makeOne(
 	r := &Resource{
 		kunStr:  u,
 		options: o,
+		schemas: rf.schemas,
 	}
 	return r
 }
@@ -108,8 +112,19 @@ func (rf *Factory) FromBytes(in []byte) (*Resource, error) {
 }
 
 // SliceFromBytes unmarshals bytes into a Resource slice.
+// A resource lacking a metadata.name is an error, a
+// kusterr.MissingNameError, unless it has a
+// metadata.generateName.  Input exceeding the factory's
+// limits, see SetInputLimits, is a kusterr.LimitError.
+// The %YAML and %TAG directives of a document, if any,
+// are recorded on its resources, see GetDirectives; they
+// don't change how it's parsed.  Any other directive is
+// a kusterr.DirectiveError.
 func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
-	kunStructs, err := rf.kf.SliceFromBytes(in)
+	if err := checkInputLimits(in, rf.limits); err != nil {
+		return nil, err
+	}
+	kunStructs, docIndex, docDirectives, err := rf.kunStructsFromBytes(in)
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +132,10 @@ func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
 	for len(kunStructs) > 0 {
 		u := kunStructs[0]
 		kunStructs = kunStructs[1:]
+		index := docIndex[0]
+		docIndex = docIndex[1:]
+		directives := docDirectives[0]
+		docDirectives = docDirectives[1:]
 		if strings.HasSuffix(u.GetKind(), "List") {
 			items := u.Map()["items"]
 			itemsSlice, ok := items.([]interface{})
@@ -138,14 +157,77 @@ func (rf *Factory) SliceFromBytes(in []byte) ([]*Resource, error) {
 				}
 				// append innerU to kunStructs so nested Lists can be handled
 				kunStructs = append(kunStructs, innerU...)
+				for range innerU {
+					docIndex = append(docIndex, index)
+					docDirectives = append(docDirectives, directives)
+				}
 			}
 		} else {
-			result = append(result, rf.FromKunstructured(u))
+			if !hasIdentifyingName(u) {
+				return nil, kusterr.MissingNameError{
+					Index: index,
+					Kind:  u.GetKind(),
+				}
+			}
+			res := rf.FromKunstructured(u)
+			res.SetDirectives(directives)
+			result = append(result, res)
 		}
 	}
 	return result, nil
 }
 
+// kunStructsFromBytes unmarshals bytes into objects,
+// returning, for each, the index of its document in
+// the input, and the directives of that document.
+// The items of a List, unmarshalled later, are
+// attributed to the List's document.
+func (rf *Factory) kunStructsFromBytes(in []byte) (
+	[]ifc.Kunstructured, []int, [][]string, error) {
+	var kunStructs []ifc.Kunstructured
+	var docIndex []int
+	var docDirectives [][]string
+	if !hasDirectives(in) {
+		var err error
+		kunStructs, err = rf.kf.SliceFromBytes(in)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		docIndex = make([]int, len(kunStructs))
+		for i := range docIndex {
+			docIndex[i] = i
+		}
+		docDirectives = make([][]string, len(kunStructs))
+		return kunStructs, docIndex, docDirectives, nil
+	}
+	docs, err := splitDocuments(in)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for i, d := range docs {
+		objects, err := rf.kf.SliceFromBytes(d.body)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, u := range objects {
+			kunStructs = append(kunStructs, u)
+			docIndex = append(docIndex, i)
+			docDirectives = append(docDirectives, d.directives)
+		}
+	}
+	return kunStructs, docIndex, docDirectives, nil
+}
+
+// hasIdentifyingName returns true if u has a metadata.name,
+// or a metadata.generateName from which the server makes one.
+func hasIdentifyingName(u ifc.Kunstructured) bool {
+	if u.GetName() != "" {
+		return true
+	}
+	generateName, _ := u.GetString("metadata.generateName")
+	return generateName != ""
+}
+
 // SliceFromBytesWithNames unmarshals bytes into a Resource slice with specified original
 // name.
 func (rf *Factory) SliceFromBytesWithNames(names []string, in []byte) ([]*Resource, error) {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/loader"
 	. "sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -188,6 +189,38 @@ spec:
 	}
 }
 
+func TestSliceFromBytesMissingName(t *testing.T) {
+	_, err := factory.SliceFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: winnie
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: pooh
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    labels:
+      app: honey
+`))
+	assert.Equal(t, kusterr.MissingNameError{Index: 1, Kind: "Secret"}, err)
+
+	result, err := factory.SliceFromBytes([]byte(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  generateName: migrate-
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+}
+
 func TestSliceFromPatches(t *testing.T) {
 	patchGood1 := types.PatchStrategicMerge("patch1.yaml")
 	patch1 := `
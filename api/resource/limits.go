@@ -0,0 +1,152 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"bytes"
+
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// SetInputLimits sets the limits that SliceFromBytes
+// enforces.  The zero value, the initial one, means
+// the defaults; see types.InputLimits.
+func (rf *Factory) SetInputLimits(l types.InputLimits) {
+	rf.limits = l
+}
+
+// checkInputLimits returns a kusterr.LimitError if the given
+// YAML exceeds any of the given limits.  It's meant to run
+// before the YAML is converted to maps, where aliases are
+// expanded, and so does not itself follow aliases, other
+// than to count the nodes they'd expand to.  YAML that
+// can't be decoded passes; the caller's parser reports it.
+func checkInputLimits(in []byte, l types.InputLimits) error {
+	l = l.Effective()
+	if l.MaxFileSize >= 0 && int64(len(in)) > l.MaxFileSize {
+		return kusterr.LimitError{
+			Limit:    "maxFileSize",
+			Observed: int64(len(in)),
+			Max:      l.MaxFileSize,
+		}
+	}
+	c := limitChecker{
+		limits:   l,
+		expanded: make(map[*yaml.Node]int64),
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(in))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			// Either io.EOF, or an error for
+			// the caller's parser to report.
+			return nil
+		}
+		c.documents++
+		if l.MaxDocuments >= 0 && c.documents > l.MaxDocuments {
+			return kusterr.LimitError{
+				Limit:    "maxDocuments",
+				Observed: int64(c.documents),
+				AtLeast:  true,
+				Max:      int64(l.MaxDocuments),
+			}
+		}
+		if err := c.checkDepth(&doc, 0); err != nil {
+			return err
+		}
+		if err := c.checkAliases(&doc); err != nil {
+			return err
+		}
+	}
+}
+
+type limitChecker struct {
+	limits    types.InputLimits
+	documents int
+	// aliasNodes counts the nodes that aliases
+	// have expanded to so far, over all documents.
+	aliasNodes int64
+	// expanded memoizes the size of a node with
+	// its aliases expanded, for nodes that are
+	// the target of an alias.
+	expanded map[*yaml.Node]int64
+}
+
+func (c *limitChecker) checkDepth(n *yaml.Node, depth int) error {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		depth++
+		if c.limits.MaxNodeDepth >= 0 && depth > c.limits.MaxNodeDepth {
+			return kusterr.LimitError{
+				Limit:    "maxNodeDepth",
+				Observed: int64(depth),
+				AtLeast:  true,
+				Max:      int64(c.limits.MaxNodeDepth),
+			}
+		}
+	}
+	for _, child := range n.Content {
+		if err := c.checkDepth(child, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *limitChecker) checkAliases(n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		c.aliasNodes = saturatingAdd(c.aliasNodes, c.expandedSize(n.Alias))
+		if c.limits.MaxAliasExpansion >= 0 &&
+			c.aliasNodes > int64(c.limits.MaxAliasExpansion) {
+			return kusterr.LimitError{
+				Limit:    "maxAliasExpansion",
+				Observed: c.aliasNodes,
+				AtLeast:  true,
+				Max:      int64(c.limits.MaxAliasExpansion),
+			}
+		}
+		return nil
+	}
+	for _, child := range n.Content {
+		if err := c.checkAliases(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandedSize returns the number of nodes in the tree at n,
+// were its aliases expanded.  The decoder rejects an alias
+// inside the node it refers to, but should one get through,
+// it counts as a single node.
+func (c *limitChecker) expandedSize(n *yaml.Node) int64 {
+	if n == nil {
+		return 0
+	}
+	if size, ok := c.expanded[n]; ok {
+		return size
+	}
+	c.expanded[n] = 1
+	var size int64 = 1
+	if n.Kind == yaml.AliasNode {
+		size = c.expandedSize(n.Alias)
+	} else {
+		for _, child := range n.Content {
+			size = saturatingAdd(size, c.expandedSize(child))
+		}
+	}
+	c.expanded[n] = size
+	return size
+}
+
+// saturatingAdd adds two non-negative
+// sizes, without overflowing.
+func saturatingAdd(a, b int64) int64 {
+	const max = int64(^uint64(0) >> 1)
+	if a > max-b {
+		return max
+	}
+	return a + b
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const configMap = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`
+
+func TestSliceFromBytesInputLimits(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		limits   types.InputLimits
+		expected kusterr.LimitError
+	}{
+		"fileSize": {
+			input:  configMap,
+			limits: types.InputLimits{MaxFileSize: 10},
+			expected: kusterr.LimitError{
+				Limit: "maxFileSize", Observed: int64(len(configMap)), Max: 10,
+			},
+		},
+		"documents": {
+			input:  configMap + "---" + configMap + "---" + configMap,
+			limits: types.InputLimits{MaxDocuments: 2},
+			expected: kusterr.LimitError{
+				Limit: "maxDocuments", Observed: 3, AtLeast: true, Max: 2,
+			},
+		},
+		"depth": {
+			input: configMap + "data:\n  x: " +
+				strings.Repeat("[", 10) + strings.Repeat("]", 10),
+			limits: types.InputLimits{MaxNodeDepth: 8},
+			expected: kusterr.LimitError{
+				Limit: "maxNodeDepth", Observed: 9, AtLeast: true, Max: 8,
+			},
+		},
+		"aliases": {
+			// Each alias expands to three nodes, the list and its items.
+			input:  configMap + "data:\n  a: &a [x, y]\n  b: [*a, *a, *a, *a]\n",
+			limits: types.InputLimits{MaxAliasExpansion: 10},
+			expected: kusterr.LimitError{
+				Limit: "maxAliasExpansion", Observed: 12, AtLeast: true, Max: 10,
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			rf := provider.NewDefaultDepProvider().GetResourceFactory()
+			rf.SetInputLimits(tc.limits)
+			_, err := rf.SliceFromBytes([]byte(tc.input))
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestSliceFromBytesNoInputLimits(t *testing.T) {
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	rf.SetInputLimits(types.InputLimits{
+		MaxFileSize:  -1,
+		MaxDocuments: -1,
+		MaxNodeDepth: -1,
+	})
+	result, err := rf.SliceFromBytes([]byte(
+		configMap + "---" + configMap[:len(configMap)-3] + "cm2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+}
+
+func TestSliceFromBytesAliasLimitBoundsMap(t *testing.T) {
+	// Expanded, f holds 7^6 scalars.
+	input := configMap + `data:
+  a: &a [x, x, x, x, x, x, x]
+  b: &b [*a, *a, *a, *a, *a, *a, *a]
+  c: &c [*b, *b, *b, *b, *b, *b, *b]
+  d: &d [*c, *c, *c, *c, *c, *c, *c]
+  e: &e [*d, *d, *d, *d, *d, *d, *d]
+  f: [*e, *e, *e, *e, *e, *e, *e]
+`
+	rf := provider.NewDefaultDepProvider().GetResourceFactory()
+	_, err := rf.SliceFromBytes([]byte(input))
+	if assert.IsType(t, kusterr.LimitError{}, err) {
+		assert.Equal(t, "maxAliasExpansion", err.(kusterr.LimitError).Limit)
+	}
+
+	// Allowed, the expansion is left to Map, which has no
+	// error to return, so doesn't bound it again.
+	rf.SetInputLimits(types.InputLimits{MaxAliasExpansion: 1000000})
+	result, err := rf.SliceFromBytes([]byte(input))
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(result)) {
+		data := result[0].Map()["data"].(map[string]interface{})
+		assert.Equal(t, 7, len(data["f"].([]interface{})))
+		assert.Empty(t, result[0].Warnings())
+	}
+}
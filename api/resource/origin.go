@@ -0,0 +1,63 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+// Origin records where a resource was loaded from, if not
+// from a local file: a remote file, or stdin.
+type Origin struct {
+	// Repo is the url of the repository, e.g.
+	// https://github.com/someOrg/someRepo.git
+	Repo string
+
+	// Ref is the branch, tag or commit the file was read
+	// at, or empty for the repository's default branch.
+	Ref string
+
+	// Path is the path of the file in the repository,
+	// or types.StdinResourcesPath for stdin.
+	Path string
+}
+
+// GetOrigin returns the origin of the resource, or nil
+// if it was loaded from a local file, or generated.
+func (r *Resource) GetOrigin() *Origin {
+	return r.origin
+}
+
+// SetOrigin sets the origin of the resource.
+func (r *Resource) SetOrigin(o *Origin) {
+	r.origin = o
+}
+
+// FilePosition is the place of a resource in the input of
+// a build: the number of the file it was read from, or of
+// the generator that made it, counting the files and
+// generators of the build in the order they were read
+// and run, and the index of the resource in that file or
+// in the output of that generator.
+type FilePosition struct {
+	File     int
+	Document int
+}
+
+// Less returns true if the position comes before the other.
+func (p FilePosition) Less(other FilePosition) bool {
+	if p.File != other.File {
+		return p.File < other.File
+	}
+	return p.Document < other.Document
+}
+
+// GetFilePosition returns the position of the resource in
+// the input of the build, or nil if it wasn't recorded,
+// e.g. for a resource a transformer made.
+func (r *Resource) GetFilePosition() *FilePosition {
+	return r.filePosition
+}
+
+// SetFilePosition sets the position of the resource in
+// the input of the build.
+func (r *Resource) SetFilePosition(p *FilePosition) {
+	r.filePosition = p
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+// ProvenanceRecord records the fields of a resource that
+// one run of a transformer changed, and which
+// kustomization configured it.  Records are kept only if a
+// build tracks provenance, and never in the resource's
+// YAML, so they can't leak into the output.
+type ProvenanceRecord struct {
+	// Transformer is the kind of the transformer, e.g.
+	// NamespaceTransformer.
+	Transformer string
+
+	// PatchFile is the file holding the patch, if the
+	// transformer applied one; see resmap.Source.
+	PatchFile string
+
+	// KustomizationRoot is the directory of the
+	// kustomization that configured the transformer.
+	KustomizationRoot string
+
+	// Fields are the sorted, slash separated paths of the
+	// fields the run added or changed, e.g.
+	// metadata/namespace.
+	Fields []string
+}
+
+// Provenance returns the records of the transformer runs
+// that changed the resource, in the order they ran, e.g.
+// to tell which layer of an overlay set a label.
+func (r *Resource) Provenance() []ProvenanceRecord {
+	if len(r.provenance) == 0 {
+		return nil
+	}
+	result := make([]ProvenanceRecord, len(r.provenance))
+	copy(result, r.provenance)
+	return result
+}
+
+// AppendProvenance appends a record of a transformer run
+// that changed the resource.
+func (r *Resource) AppendProvenance(rec ProvenanceRecord) {
+	r.provenance = append(r.provenance, rec)
+}
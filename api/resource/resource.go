@@ -5,8 +5,10 @@ package resource
 
 import (
 	"fmt"
+	"log"
 	"reflect"
 	"strings"
+	"sync/atomic"
 
 	"sigs.k8s.io/kustomize/api/filters/patchstrategicmerge"
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -15,6 +17,7 @@ import (
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
 )
@@ -27,6 +30,39 @@ type Resource struct {
 	options     *types.GenArgs
 	refBy       []resid.ResId
 	refVarNames []string
+	origin      *Origin
+	// filePosition is the place of the resource in the
+	// input, for sorting by it.
+	filePosition *FilePosition
+	// orgGvk is the Gvk the resource had before a rename
+	// changed it, e.g. an upgrade of its apiVersion, or nil
+	// if its Gvk is the original one.
+	orgGvk *resid.Gvk
+	// directives are the YAML directives, e.g. %YAML 1.1,
+	// of the document the resource was read from.
+	directives []string
+	// provenance records the transformer runs that changed
+	// the resource, if the build tracks provenance.
+	provenance []ProvenanceRecord
+	// curId caches the id CurId computes, or is nil if a
+	// change to the resource may have changed its id.
+	curId *resid.ResId
+	// watches count the changes to the id of the resource,
+	// for the ResMaps indexing it; see Watch.
+	watches []*IdWatch
+	// idVersion counts the changes to the id of the
+	// resource.
+	idVersion uint64
+	// warnings are the warnings made about changes to the
+	// resource, e.g. by the patches applied to it.
+	warnings []string
+	// schemas are the OpenAPI definitions of the build the
+	// resource is in, or nil for the global ones.
+	schemas *openapi.Schemas
+	// shared is true if kunStr may be held by another
+	// resource, as after LazyCopy, so must be copied
+	// before it's changed; see mutable.
+	shared bool
 }
 
 const (
@@ -38,6 +74,19 @@ const (
 
 func (r *Resource) ResetPrimaryData(incoming *Resource) {
 	r.kunStr = incoming.Copy()
+	r.shared = false
+	r.forgetCurId()
+}
+
+// mutable returns the data of the resource to change,
+// first copying it if it's shared with another resource.
+// Every change to kunStr must go through it.
+func (r *Resource) mutable() ifc.Kunstructured {
+	if r.shared {
+		r.kunStr = r.kunStr.Copy()
+		r.shared = false
+	}
+	return r.kunStr
 }
 
 func (r *Resource) GetAnnotations() map[string]string {
@@ -48,10 +97,68 @@ func (r *Resource) Copy() ifc.Kunstructured {
 	return r.kunStr.Copy()
 }
 
+// Warnings returns the warnings made about the resource:
+// about its content while reading it, e.g. that a map key
+// that isn't a string was converted to one, if its
+// Kunstructured records any, then about changes to it,
+// e.g. that a value a patch deletes from a list wasn't in it.
+func (r *Resource) Warnings() []string {
+	var result []string
+	if w, ok := r.kunStr.(interface{ Warnings() []string }); ok {
+		result = w.Warnings()
+	}
+	return append(result, r.warnings...)
+}
+
 func (r *Resource) GetFieldValue(f string) (interface{}, error) {
 	return r.kunStr.GetFieldValue(f)
 }
 
+// SetFieldValue sets the value, a scalar, map or list, at
+// the given field path, in the syntax of GetFieldValue, as
+// WNode.SetFieldValue does, e.g. spec.replicas or
+// spec.template.spec.containers[*].image.  Comments and the
+// order of fields are kept.  If create is false, fields
+// missing above the last segment of the path aren't
+// created, and where one is missing nothing is set.
+func (r *Resource) SetFieldValue(
+	path string, value interface{}, create bool) error {
+	return r.ApplyFilter(kio.FilterAll(kyaml.FilterFunc(
+		func(node *kyaml.RNode) (*kyaml.RNode, error) {
+			wn := wrappy.FromRNode(node)
+			if create {
+				return node, wn.SetFieldValue(path, value)
+			}
+			return node, wn.SetFieldValueNoCreate(path, value)
+		})))
+}
+
+// GetDataValue returns the value of the key in the data,
+// or else binaryData, of the resource, e.g. of a ConfigMap,
+// as WNode.GetDataValue does.  Unlike a field path, the key
+// may hold dots and slashes, e.g. nginx.conf.
+func (r *Resource) GetDataValue(key string) (string, error) {
+	wn, ok := r.kunStr.(*wrappy.WNode)
+	if !ok {
+		node, err := filtersutil.GetRNode(r)
+		if err != nil {
+			return "", err
+		}
+		wn = wrappy.FromRNode(node)
+	}
+	return wn.GetDataValue(key)
+}
+
+// SetDataValue sets the key in the data of the resource,
+// or in its binaryData if the value isn't valid UTF-8, as
+// WNode.SetDataValue does.
+func (r *Resource) SetDataValue(key, value string) error {
+	return r.ApplyFilter(kio.FilterAll(kyaml.FilterFunc(
+		func(node *kyaml.RNode) (*kyaml.RNode, error) {
+			return node, wrappy.FromRNode(node).SetDataValue(key, value)
+		})))
+}
+
 func (r *Resource) GetDataMap() map[string]string {
 	return r.kunStr.GetDataMap()
 }
@@ -80,6 +187,14 @@ func (r *Resource) GetString(p string) (string, error) {
 	return r.kunStr.GetString(p)
 }
 
+func (r *Resource) GetBool(p string) (bool, error) {
+	return r.kunStr.GetBool(p)
+}
+
+func (r *Resource) GetInt(p string) (int64, error) {
+	return r.kunStr.GetInt(p)
+}
+
 func (r *Resource) IsEmpty() bool {
 	return len(r.kunStr.Map()) == 0
 }
@@ -103,47 +218,93 @@ func (r *Resource) MatchesAnnotationSelector(selector string) (bool, error) {
 func (r *Resource) SetAnnotations(m map[string]string) {
 	if len(m) == 0 {
 		// Force field erasure.
-		r.kunStr.SetAnnotations(nil)
+		r.mutable().SetAnnotations(nil)
 		return
 	}
-	r.kunStr.SetAnnotations(m)
+	r.mutable().SetAnnotations(m)
+}
+
+// SetAnnotationsE is SetAnnotations, returning an error
+// if the annotations can't be set, e.g. as metadata isn't
+// a map, rather than exiting.
+func (r *Resource) SetAnnotationsE(m map[string]string) error {
+	if len(m) == 0 {
+		// Force field erasure.
+		m = nil
+	}
+	return r.mutable().SetAnnotationsE(m)
 }
 
 func (r *Resource) SetDataMap(m map[string]string) {
-	r.kunStr.SetDataMap(m)
+	r.mutable().SetDataMap(m)
 }
 
 func (r *Resource) SetGvk(gvk resid.Gvk) {
-	r.kunStr.SetGvk(gvk)
+	r.mutable().SetGvk(gvk)
+	r.forgetCurId()
+}
+
+// SetGvkE is SetGvk, returning an error rather than
+// exiting.
+func (r *Resource) SetGvkE(gvk resid.Gvk) error {
+	defer r.forgetCurId()
+	return r.mutable().SetGvkE(gvk)
 }
 
 func (r *Resource) SetLabels(m map[string]string) {
 	if len(m) == 0 {
 		// Force field erasure.
-		r.kunStr.SetLabels(nil)
+		r.mutable().SetLabels(nil)
 		return
 	}
-	r.kunStr.SetLabels(m)
+	r.mutable().SetLabels(m)
+}
+
+// SetLabelsE is SetLabels, returning an error rather
+// than exiting.
+func (r *Resource) SetLabelsE(m map[string]string) error {
+	if len(m) == 0 {
+		// Force field erasure.
+		m = nil
+	}
+	return r.mutable().SetLabelsE(m)
 }
 
 func (r *Resource) SetName(n string) {
-	r.kunStr.SetName(n)
+	r.mutable().SetName(n)
+	r.forgetCurId()
 }
 
 func (r *Resource) SetNamespace(n string) {
-	r.kunStr.SetNamespace(n)
+	r.mutable().SetNamespace(n)
+	r.forgetCurId()
+}
+
+// SetNameE is SetName, returning an error rather than
+// exiting.
+func (r *Resource) SetNameE(n string) error {
+	defer r.forgetCurId()
+	return r.mutable().SetNameE(n)
+}
+
+// SetNamespaceE is SetNamespace, returning an error
+// rather than exiting.
+func (r *Resource) SetNamespaceE(n string) error {
+	defer r.forgetCurId()
+	return r.mutable().SetNamespaceE(n)
 }
 
 func (r *Resource) UnmarshalJSON(s []byte) error {
-	return r.kunStr.UnmarshalJSON(s)
+	defer r.forgetCurId()
+	return r.mutable().UnmarshalJSON(s)
 }
 
 // ResCtx is an interface describing the contextual added
 // kept kustomize in the context of each Resource object.
 // Currently mainly the name prefix and name suffix are added.
 type ResCtx interface {
-	AddNamePrefix(p string)
-	AddNameSuffix(s string)
+	AddNamePrefix(p string) error
+	AddNameSuffix(s string) error
 	GetOutermostNamePrefix() string
 	GetOutermostNameSuffix() string
 	GetNamePrefixes() []string
@@ -163,21 +324,66 @@ func (r *Resource) DeepCopy() *Resource {
 	return rc
 }
 
+// LazyCopy returns a copy of the resource, as DeepCopy
+// does, but one that shares the data of the resource
+// until either of them changes it, and only then copies
+// it.  It's much cheaper than DeepCopy when most copies
+// are never changed.  As it marks the data of r shared,
+// it's a change to r.
+func (r *Resource) LazyCopy() *Resource {
+	r.shared = true
+	rc := &Resource{
+		kunStr: r.kunStr,
+		shared: true,
+	}
+	rc.copyOtherFields(r)
+	return rc
+}
+
 // CopyMergeMetaDataFields copies everything but the non-metadata in
 // the ifc.Kunstructured map, merging labels and annotations.
-func (r *Resource) CopyMergeMetaDataFieldsFrom(other *Resource) {
-	r.SetLabels(mergeStringMaps(other.GetLabels(), r.GetLabels()))
-	r.SetAnnotations(
-		mergeStringMaps(other.GetAnnotations(), r.GetAnnotations()))
-	r.SetName(other.GetName())
-	r.SetNamespace(other.GetNamespace())
+func (r *Resource) CopyMergeMetaDataFieldsFrom(other *Resource) error {
+	if err := r.SetLabelsE(
+		mergeStringMaps(other.GetLabels(), r.GetLabels())); err != nil {
+		return err
+	}
+	if err := r.SetAnnotationsE(
+		mergeStringMaps(other.GetAnnotations(), r.GetAnnotations())); err != nil {
+		return err
+	}
+	if err := r.SetNameE(other.GetName()); err != nil {
+		return err
+	}
+	if err := r.SetNamespaceE(other.GetNamespace()); err != nil {
+		return err
+	}
 	r.copyOtherFields(other)
+	return nil
 }
 
 func (r *Resource) copyOtherFields(other *Resource) {
 	r.options = other.options
 	r.refBy = other.copyRefBy()
 	r.refVarNames = copyStringSlice(other.refVarNames)
+	r.origin = other.origin
+	r.filePosition = other.filePosition
+	r.orgGvk = other.orgGvk
+	r.directives = copyStringSlice(other.directives)
+	r.warnings = copyStringSlice(other.warnings)
+	r.schemas = other.schemas
+	r.provenance = other.Provenance()
+}
+
+// NewLike returns a new resource with the given JSON
+// content, held as r holds its own, but with none of the
+// bookkeeping of r, e.g. its options or origin.
+func (r *Resource) NewLike(json []byte) (*Resource, error) {
+	k := r.kunStr.Copy()
+	if err := k.UnmarshalJSON(json); err != nil {
+		return nil, err
+	}
+	return &Resource{
+		kunStr: k, options: types.NewGenArgs(nil), schemas: r.schemas}, nil
 }
 
 func (r *Resource) MergeDataMapFrom(o *Resource) {
@@ -242,19 +448,25 @@ func copyStringSlice(s []string) []string {
 	return c
 }
 
-// Implements ResCtx AddNamePrefix
-func (r *Resource) AddNamePrefix(p string) {
-	r.addAdditiveAnnotation(prefixAnnotation, p)
+// AddNamePrefix implements ResCtx, recording that the
+// given prefix was put before the resource's name.  A
+// plugin that renames resources itself, rather than with
+// the PrefixSuffixTransformer, should record its prefix
+// here, and its suffix with AddNameSuffix, so that the
+// references to the resource are fixed as for any other.
+// An empty prefix isn't recorded.
+func (r *Resource) AddNamePrefix(p string) error {
+	return r.addAdditiveAnnotation(prefixAnnotation, p)
 }
 
-// Implements ResCtx AddNameSuffix
-func (r *Resource) AddNameSuffix(s string) {
-	r.addAdditiveAnnotation(suffixAnnotation, s)
+// AddNameSuffix implements ResCtx; see AddNamePrefix.
+func (r *Resource) AddNameSuffix(s string) error {
+	return r.addAdditiveAnnotation(suffixAnnotation, s)
 }
 
-func (r *Resource) addAdditiveAnnotation(name, value string) {
+func (r *Resource) addAdditiveAnnotation(name, value string) error {
 	if value == "" {
-		return
+		return nil
 	}
 	annotations := r.GetAnnotations()
 	if annotations == nil {
@@ -265,7 +477,7 @@ func (r *Resource) addAdditiveAnnotation(name, value string) {
 	} else {
 		annotations[name] = value
 	}
-	r.SetAnnotations(annotations)
+	return r.SetAnnotationsE(annotations)
 }
 
 // Implements ResCtx GetOutermostNamePrefix
@@ -306,7 +518,12 @@ func sameEndingSubarray(a, b []string) bool {
 	return true
 }
 
-// Implements ResCtx GetNamePrefixes
+// GetNamePrefixes implements ResCtx, returning the name
+// prefixes recorded by AddNamePrefix, in the order they
+// were put before the name, so the innermost first, e.g.
+// those of the namePrefix of a base, then of an overlay.
+// They're kept in build annotations, so they survive
+// DeepCopy, and a generator's merge into the resource.
 func (r *Resource) GetNamePrefixes() []string {
 	annotations := r.GetAnnotations()
 	if _, ok := annotations[prefixAnnotation]; !ok {
@@ -315,7 +532,9 @@ func (r *Resource) GetNamePrefixes() []string {
 	return strings.Split(annotations[prefixAnnotation], ",")
 }
 
-// Implements ResCtx GetNameSuffixes
+// GetNameSuffixes implements ResCtx; see GetNamePrefixes.
+// The suffixes are in the order they were put after the
+// name, so the innermost first.
 func (r *Resource) GetNameSuffixes() []string {
 	annotations := r.GetAnnotations()
 	if _, ok := annotations[suffixAnnotation]; !ok {
@@ -345,18 +564,64 @@ func (r *Resource) PrefixesSuffixesEquals(o ResCtx) bool {
 	return sameEndingSubarray(r.GetNamePrefixes(), o.GetNamePrefixes()) && sameEndingSubarray(r.GetNameSuffixes(), o.GetNameSuffixes())
 }
 
-func (r *Resource) RemoveIdAnnotations() {
+// RemoveBuildAnnotations removes the annotations kustomize
+// uses to track the resource's identity during a build, and
+// the annotations field itself if that leaves it empty, so
+// that the output is the same as if the resource never had
+// annotations.  It's idempotent.
+func (r *Resource) RemoveBuildAnnotations() {
+	// GetAnnotations exits on the metadata that setting them
+	// fails on, and only removing them can't make it so.
+	_ = r.SetBuildAnnotations(nil)
+}
+
+// buildAnnotations are the keys of the annotations that
+// RemoveBuildAnnotations removes.
+var buildAnnotations = []string{
+	nameAnnotation, prefixAnnotation, suffixAnnotation, namespaceAnnotation,
+}
+
+// BuildAnnotations returns the annotations kustomize uses
+// to track the resource's identity during a build, those
+// that RemoveBuildAnnotations removes.
+func (r *Resource) BuildAnnotations() map[string]string {
+	result := make(map[string]string)
 	annotations := r.GetAnnotations()
-	if len(annotations) == 0 {
-		return
+	for _, k := range buildAnnotations {
+		if v, found := annotations[k]; found {
+			result[k] = v
+		}
 	}
-	delete(annotations, nameAnnotation)
-	delete(annotations, prefixAnnotation)
-	delete(annotations, suffixAnnotation)
-	delete(annotations, namespaceAnnotation)
-	r.SetAnnotations(annotations)
+	return result
 }
 
+// SetBuildAnnotations replaces the build annotations of
+// the resource, as BuildAnnotations returns them, with
+// the given ones, e.g. to restore them on a copy that was
+// changed outside the build.
+func (r *Resource) SetBuildAnnotations(m map[string]string) error {
+	annotations := r.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for _, k := range buildAnnotations {
+		delete(annotations, k)
+		if v, found := m[k]; found {
+			annotations[k] = v
+		}
+	}
+	return r.SetAnnotationsE(annotations)
+}
+
+// RemoveIdAnnotations is RemoveBuildAnnotations.
+func (r *Resource) RemoveIdAnnotations() {
+	r.RemoveBuildAnnotations()
+}
+
+// GetOriginalName returns the name the resource had
+// before any transformer renamed it, e.g. before the
+// prefixes of GetNamePrefixes were put before it.
+// It's the name of OrgId.
 func (r *Resource) GetOriginalName() string {
 	annotations := r.GetAnnotations()
 	if name, ok := annotations[nameAnnotation]; ok {
@@ -366,6 +631,15 @@ func (r *Resource) GetOriginalName() string {
 }
 
 func (r *Resource) SetOriginalName(n string, overwrite bool) *Resource {
+	if err := r.SetOriginalNameE(n, overwrite); err != nil {
+		log.Fatal(err) // the chaining doesn't allow error.
+	}
+	return r
+}
+
+// SetOriginalNameE is SetOriginalName, returning an error
+// rather than exiting.
+func (r *Resource) SetOriginalNameE(n string, overwrite bool) error {
 	annotations := r.GetAnnotations()
 	if annotations == nil {
 		annotations = make(map[string]string)
@@ -373,10 +647,12 @@ func (r *Resource) SetOriginalName(n string, overwrite bool) *Resource {
 	if _, ok := annotations[nameAnnotation]; !ok || overwrite {
 		annotations[nameAnnotation] = n
 	}
-	r.kunStr.SetAnnotations(annotations)
-	return r
+	return r.mutable().SetAnnotationsE(annotations)
 }
 
+// GetOriginalNs returns the namespace the resource had
+// before any transformer changed it.  It's the namespace
+// of OrgId.
 func (r *Resource) GetOriginalNs() string {
 	annotations := r.GetAnnotations()
 	if ns, ok := annotations[namespaceAnnotation]; ok {
@@ -390,6 +666,15 @@ func (r *Resource) GetOriginalNs() string {
 }
 
 func (r *Resource) SetOriginalNs(n string, overwrite bool) *Resource {
+	if err := r.SetOriginalNsE(n, overwrite); err != nil {
+		log.Fatal(err) // the chaining doesn't allow error.
+	}
+	return r
+}
+
+// SetOriginalNsE is SetOriginalNs, returning an error
+// rather than exiting.
+func (r *Resource) SetOriginalNsE(n string, overwrite bool) error {
 	if n == "" {
 		n = "default"
 	}
@@ -400,8 +685,7 @@ func (r *Resource) SetOriginalNs(n string, overwrite bool) *Resource {
 	if _, ok := annotations[namespaceAnnotation]; !ok || overwrite {
 		annotations[namespaceAnnotation] = n
 	}
-	r.SetAnnotations(annotations)
-	return r
+	return r.SetAnnotationsE(annotations)
 }
 
 // String returns resource as JSON.
@@ -423,6 +707,11 @@ func (r *Resource) AsYAML() ([]byte, error) {
 	return yaml.JSONToYAML(json)
 }
 
+// IsGenerated returns true if the resource was made by a generator.
+func (r *Resource) IsGenerated() bool {
+	return r.options.IsGenerated()
+}
+
 // SetOptions updates the generator options for the resource.
 func (r *Resource) SetOptions(o *types.GenArgs) {
 	r.options = o
@@ -439,6 +728,24 @@ func (r *Resource) NeedHashSuffix() bool {
 	return r.options != nil && r.options.ShouldAddHashSuffixToName()
 }
 
+// LabelsToPropagate returns those labels of a generated
+// resource that its generator options ask to add to the
+// resources that refer to it.
+func (r *Resource) LabelsToPropagate() map[string]string {
+	keys := r.options.LabelKeysToPropagate()
+	if len(keys) == 0 {
+		return nil
+	}
+	labels := r.GetLabels()
+	result := make(map[string]string)
+	for _, k := range keys {
+		if v, ok := labels[k]; ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
 // GetNamespace returns the namespace the resource thinks it's in.
 func (r *Resource) GetNamespace() string {
 	namespace, _ := r.GetString("metadata.namespace")
@@ -454,12 +761,144 @@ func (r *Resource) OrgId() resid.ResId {
 		r.GetGvk(), r.GetOriginalName(), r.GetOriginalNs())
 }
 
+// OrgGvk returns the Gvk the resource was loaded or
+// generated with, which differs from GetGvk if a rename
+// or a patch changed it, e.g. an upgrade of its apiVersion.
+func (r *Resource) OrgGvk() resid.Gvk {
+	if r.orgGvk != nil {
+		return *r.orgGvk
+	}
+	return r.GetGvk()
+}
+
+// SetOrgGvk records the Gvk the resource had originally,
+// unless one is already recorded.  ResMap.Rename calls it
+// when a rename changes the Gvk.
+func (r *Resource) SetOrgGvk(gvk resid.Gvk) {
+	if r.orgGvk == nil && !gvk.ExactlyEquals(r.GetGvk()) {
+		r.orgGvk = &gvk
+	}
+}
+
 // CurId returns a ResId for the resource using the
 // mutable parts of the resource.
 // This should be unique in any ResMap.
+// It computes the id afresh, refreshing the one
+// GetCurIdFast returns.
 func (r *Resource) CurId() resid.ResId {
-	return resid.NewResIdWithNamespace(
+	id := resid.NewResIdWithNamespace(
 		r.GetGvk(), r.GetName(), r.GetNamespace())
+	if r.curId != nil && *r.curId != id {
+		// The map returned by Map was changed.
+		r.idChanged()
+	}
+	r.curId = &id
+	return id
+}
+
+// GetCurIdFast returns the same id as CurId, computing
+// it only if a change to the resource may have changed
+// it since it was last computed, e.g. for the lookups
+// by id that a build makes many times over.
+//
+// The methods of Resource that can change its id forget
+// the cached id.  So it can't be stale unless the map
+// returned by Map is changed; after doing that, call
+// CurId.
+func (r *Resource) GetCurIdFast() resid.ResId {
+	if r.curId != nil {
+		return *r.curId
+	}
+	return r.CurId()
+}
+
+// maxIdWatches is the most IdWatches a resource keeps.
+// Watching it with another evicts the oldest, so the
+// watches of ResMaps that were dropped don't pile up.
+const maxIdWatches = 8
+
+// IdWatch counts the changes to the ids of the resources
+// watching it, for an index of them by id, such as a
+// ResMap keeps, that's current while the count holds.
+type IdWatch struct {
+	changes uint64
+	closed  uint32
+}
+
+// NewIdWatch returns an IdWatch no resource watches yet.
+func NewIdWatch() *IdWatch {
+	return &IdWatch{}
+}
+
+// Changes returns the count of changes to the ids of the
+// resources watching w.  It also grows when a resource
+// stops watching w to watch another, as after that w
+// misses its changes.
+func (w *IdWatch) Changes() uint64 {
+	return atomic.LoadUint64(&w.changes)
+}
+
+// Close tells the resources watching w to stop, when
+// they next change or are told to watch another.
+func (w *IdWatch) Close() {
+	atomic.StoreUint32(&w.closed, 1)
+}
+
+func (w *IdWatch) isClosed() bool {
+	return atomic.LoadUint32(&w.closed) == 1
+}
+
+// Watch has the resource count the changes to its id in
+// w, until w is closed or evicted by other watches.
+func (r *Resource) Watch(w *IdWatch) {
+	// Cache the id, so a change can be told from the
+	// id it had.
+	r.GetCurIdFast()
+	kept := r.watches[:0]
+	for _, x := range r.watches {
+		if x == w {
+			return
+		}
+		if !x.isClosed() {
+			kept = append(kept, x)
+		}
+	}
+	if len(kept) == maxIdWatches {
+		atomic.AddUint64(&kept[0].changes, 1)
+		kept = kept[1:]
+	}
+	r.watches = append(kept, w)
+}
+
+// IdVersion returns the count of changes to the id of
+// the resource since it was made.
+func (r *Resource) IdVersion() uint64 {
+	return r.idVersion
+}
+
+// idChanged counts a change to the id of the resource.
+func (r *Resource) idChanged() {
+	r.idVersion++
+	kept := r.watches[:0]
+	for _, w := range r.watches {
+		if !w.isClosed() {
+			atomic.AddUint64(&w.changes, 1)
+			kept = append(kept, w)
+		}
+	}
+	r.watches = kept
+}
+
+// forgetCurId forgets the cached id, after a change to
+// the resource that may have changed it.  If watched,
+// the resource finds its id at once, to tell the watches
+// if it changed.
+func (r *Resource) forgetCurId() {
+	if len(r.watches) == 0 {
+		r.curId = nil
+		return
+	}
+	r.CurId()
 }
 
 // GetRefBy returns the ResIds that referred to current resource
@@ -483,33 +922,91 @@ func (r *Resource) AppendRefVarName(variable types.Var) {
 }
 
 // ApplySmPatch applies the provided strategic merge patch.
+// A list element the patch deletes, by the merge key of
+// the list, or by name if the list has none, e.g. in a
+// resource of an unknown kind, needn't be there.
 func (r *Resource) ApplySmPatch(patch *Resource) error {
+	return r.applySmPatch(patch, false)
+}
+
+// MergeSmPatch merges the provided strategic merge patch
+// into the resource, itself such a patch, as ApplySmPatch
+// does, but keeping the deletions that match nothing in
+// it, to apply along with it.
+func (r *Resource) MergeSmPatch(patch *Resource) error {
+	return r.applySmPatch(patch, true)
+}
+
+func (r *Resource) applySmPatch(patch *Resource, keepDeletes bool) error {
 	node, err := filtersutil.GetRNode(patch)
 	if err != nil {
 		return err
 	}
 	n, ns := r.GetName(), r.GetNamespace()
+	var warnings []string
 	err = r.ApplyFilter(patchstrategicmerge.Filter{
-		Patch: node,
+		Patch:                node,
+		Schemas:              r.schemas,
+		KeepUnmatchedDeletes: keepDeletes,
+		Warnings:             &warnings,
 	})
 	if err != nil {
 		return err
 	}
-	if !r.IsEmpty() {
-		r.SetName(n)
-		r.SetNamespace(ns)
+	r.warnings = append(r.warnings, warnings...)
+	if r.IsEmpty() {
+		return nil
 	}
-	return err
+	if err = r.SetNameE(n); err != nil {
+		return err
+	}
+	return r.SetNamespaceE(ns)
+}
+
+// LookupListElement returns the element of the list at
+// the given path whose field key has the given value, e.g.
+// the container named sidecar, as WNode.LookupListElement
+// does.  If the resource isn't held as a yaml.RNode, i.e.
+// kyaml isn't in use, the element is a copy, so changing
+// it doesn't change the resource.
+func (r *Resource) LookupListElement(
+	path string, key string, value string) (*kyaml.RNode, error) {
+	// The element may be changed in place.
+	wn, ok := r.mutable().(*wrappy.WNode)
+	if !ok {
+		node, err := filtersutil.GetRNode(r)
+		if err != nil {
+			return nil, err
+		}
+		wn = wrappy.FromRNode(node)
+	}
+	return wn.LookupListElement(path, key, value)
 }
 
 func (r *Resource) ApplyFilter(f kio.Filter) error {
-	if wn, ok := r.kunStr.(*wrappy.WNode); ok {
+	// Forget the id after filtering, in case the filter
+	// looked the id up while changing it.
+	before := r.GetCurIdFast()
+	defer func() {
+		r.curId = nil
+		if r.CurId() != before {
+			r.idChanged()
+			// E.g. a patch upgraded the apiVersion.
+			r.SetOrgGvk(before.Gvk)
+		}
+	}()
+	if wn, ok := r.mutable().(*wrappy.WNode); ok {
 		l, err := f.Filter([]*kyaml.RNode{wn.AsRNode()})
+		if err != nil {
+			// No nodes come back from a failed filter, which
+			// isn't a deletion.
+			return err
+		}
 		if len(l) == 0 {
 			// Hack to deal with deletion.
 			r.kunStr = wrappy.NewWNode()
 		}
-		return err
+		return nil
 	}
 	return filtersutil.ApplyToJSON(f, r)
 }
@@ -6,6 +6,7 @@ package resource_test
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,6 +14,8 @@ import (
 	"sigs.k8s.io/kustomize/api/resid"
 	. "sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 var factory = provider.NewDefaultDepProvider().GetResourceFactory()
@@ -126,6 +129,65 @@ func TestDeepCopy(t *testing.T) {
 	}
 }
 
+func TestLazyCopy(t *testing.T) {
+	r, err := factory.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pooh
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+`))
+	assert.NoError(t, err)
+	original, err := r.AsYAML()
+	assert.NoError(t, err)
+	r.AppendRefBy(resid.NewResId(resid.Gvk{Kind: "MyKind"}, "random"))
+
+	c := r.LazyCopy()
+	assert.Equal(t, r.GetRefBy(), c.GetRefBy())
+	c.SetName("tigger")
+	assert.Equal(t, "pooh", r.GetName())
+	assert.Equal(t, "tigger", c.GetName())
+	r.SetLabels(map[string]string{"a": "b"})
+	assert.Empty(t, c.GetLabels())
+
+	// Changes in place, by a patch or to a list element,
+	// don't reach the other copies either.
+	r, err = factory.FromBytes(original)
+	assert.NoError(t, err)
+	patched := r.LazyCopy()
+	patch, err := factory.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pooh
+spec:
+  replicas: 3
+`))
+	assert.NoError(t, err)
+	assert.NoError(t, patched.ApplySmPatch(patch))
+	elementChanged := r.LazyCopy()
+	container, err := elementChanged.LookupListElement(
+		"spec.template.spec.containers", "name", "app")
+	assert.NoError(t, err)
+	assert.NoError(t, container.PipeE(
+		kyaml.SetField("image", kyaml.NewScalarRNode("app:v2"))))
+	actual, err := r.AsYAML()
+	assert.NoError(t, err)
+	assert.Equal(t, string(original), string(actual))
+	replicas, err := patched.GetInt("spec.replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), replicas)
+	image, err := elementChanged.GetString(
+		"spec.template.spec.containers[0].image")
+	assert.NoError(t, err)
+	assert.Equal(t, "app:v2", image)
+}
+
 func TestApplySmPatch_1(t *testing.T) {
 	resource, err := factory.FromBytes([]byte(`
 apiVersion: apps/v1
@@ -695,6 +757,120 @@ spec:
 	}
 }
 
+func TestApplySmPatchDeleteListElements(t *testing.T) {
+	// The containers of a Widget, of no known schema, are
+	// deleted by name.
+	kinds := []struct{ apiVersion, kind string }{
+		{"apps/v1", "Deployment"},
+		{"example.com/v1", "Widget"},
+	}
+	for _, k := range kinds {
+		apiVersion, kind := k.apiVersion, k.kind
+		resource, err := factory.FromBytes([]byte(`
+apiVersion: ` + apiVersion + `
+kind: ` + kind + `
+metadata:
+  name: web
+  finalizers:
+  - a
+  - b
+spec:
+  template:
+    spec:
+      containers:
+      - name: one
+        image: one
+      - name: two
+        image: two
+      - name: three
+        image: three
+`))
+		assert.NoError(t, err)
+		// Neither container four nor env of three is there
+		// to delete.
+		patch, err := factory.FromBytes([]byte(`
+apiVersion: ` + apiVersion + `
+kind: ` + kind + `
+metadata:
+  name: web
+  $deleteFromPrimitiveList/finalizers:
+  - a
+spec:
+  template:
+    spec:
+      containers:
+      - name: two
+        $patch: delete
+      - name: four
+        $patch: delete
+`))
+		assert.NoError(t, err)
+		assert.NoError(t, resource.ApplySmPatch(patch), kind)
+		bytes, err := resource.AsYAML()
+		assert.NoError(t, err)
+		assert.Equal(t, `apiVersion: `+apiVersion+`
+kind: `+kind+`
+metadata:
+  finalizers:
+  - b
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: one
+        name: one
+      - image: three
+        name: three
+`, string(bytes), kind)
+	}
+}
+
+func TestMergeSmPatchKeepsUnmatchedDeletes(t *testing.T) {
+	patch1, err := factory.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: one
+        image: one:2
+`))
+	assert.NoError(t, err)
+	patch2, err := factory.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: two
+        $patch: delete
+`))
+	assert.NoError(t, err)
+	assert.NoError(t, patch1.MergeSmPatch(patch2))
+	bytes, err := patch1.AsYAML()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - $patch: delete
+        name: two
+      - image: one:2
+        name: one
+`, string(bytes))
+}
+
 func TestSetOriginalNameAndNs(t *testing.T) {
 	input := `apiVersion: apps/v1
 kind: Secret
@@ -1177,3 +1353,347 @@ spec:
         name: nginx
 `, imagename)
 }
+
+func TestRemoveBuildAnnotations(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"onlyBuildAnnotations": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  annotations:
+    config.kubernetes.io/originalName: cm
+    config.kubernetes.io/originalNs: default
+    config.kubernetes.io/prefixes: p-
+    config.kubernetes.io/suffixes: -s
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+		},
+		"otherAnnotationsKept": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  annotations:
+    config.kubernetes.io/originalName: cm
+    team: blue
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  annotations:
+    team: blue
+  name: cm
+`,
+		},
+		"emptyAnnotations": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  annotations: {}
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+		},
+		"noAnnotations": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+			expected: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r, err := factory.FromBytes([]byte(tc.input))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			r.RemoveBuildAnnotations()
+			once, err := r.AsYAML()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(once))
+			r.RemoveBuildAnnotations()
+			twice, err := r.AsYAML()
+			assert.NoError(t, err)
+			assert.Equal(t, string(once), string(twice))
+		})
+	}
+}
+
+func TestLookupListElement(t *testing.T) {
+	for _, useKyaml := range []bool{true, false} {
+		rf := provider.NewDepProvider(useKyaml).GetResourceFactory()
+		r, err := rf.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+      - name: sidecar
+        image: proxy:2
+`))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		e, err := r.LookupListElement("spec.template.spec.containers", "", "sidecar")
+		if assert.NoError(t, err, useKyaml) {
+			assert.Equal(t, "proxy:2", e.Field("image").Value.YNode().Value)
+		}
+		_, err = r.LookupListElement("spec.template.spec.containers", "name", "none")
+		assert.EqualError(t, err,
+			"no element of list 'spec.template.spec.containers' has name=none")
+	}
+}
+
+func TestSetFieldValue(t *testing.T) {
+	for _, useKyaml := range []bool{true, false} {
+		rf := provider.NewDepProvider(useKyaml).GetResourceFactory()
+		r, err := rf.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+      - name: sidecar
+        image: proxy:2
+`))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.NoError(t, r.SetFieldValue(
+			"spec.template.spec.containers[*].image", "nginx", false))
+		y, err := r.AsYAML()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(y), "image: nginx"), useKyaml)
+
+		assert.NoError(t, r.SetFieldValue("spec.strategy.type", "Recreate", false))
+		_, err = r.GetFieldValue("spec.strategy")
+		assert.Error(t, err)
+		assert.NoError(t, r.SetFieldValue("spec.strategy.type", "Recreate", true))
+		strategy, err := r.GetFieldValue("spec.strategy")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"type": "Recreate"}, strategy)
+	}
+}
+
+func TestSetFieldValueFailureKeepsResource(t *testing.T) {
+	for _, useKyaml := range []bool{true, false} {
+		rf := provider.NewDepProvider(useKyaml).GetResourceFactory()
+		r, err := rf.FromBytes([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		before, err := r.AsYAML()
+		assert.NoError(t, err)
+		assert.Error(t, r.SetFieldValue("spec.replicas.min", 1, true), useKyaml)
+		after, err := r.AsYAML()
+		assert.NoError(t, err)
+		assert.Equal(t, string(before), string(after), useKyaml)
+	}
+}
+
+func TestDataValue(t *testing.T) {
+	for _, useKyaml := range []bool{true, false} {
+		rf := provider.NewDepProvider(useKyaml).GetResourceFactory()
+		r, err := rf.FromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  nginx.conf: worker_processes 1;
+`))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		v, err := r.GetDataValue("nginx.conf")
+		assert.NoError(t, err, useKyaml)
+		assert.Equal(t, "worker_processes 1;", v, useKyaml)
+
+		assert.NoError(t, r.SetDataValue("settings/prod.yaml", "a: b"))
+		assert.NoError(t, r.SetDataValue("logo.png", "\x89PNG"))
+		assert.Equal(t, map[string]string{
+			"nginx.conf":         "worker_processes 1;",
+			"settings/prod.yaml": "a: b",
+		}, r.GetDataMap(), useKyaml)
+		v, err = r.GetDataValue("logo.png")
+		assert.NoError(t, err, useKyaml)
+		assert.Equal(t, "\x89PNG", v, useKyaml)
+	}
+}
+
+// After any change to a resource, GetCurIdFast must
+// agree with CurId, which always computes the id afresh.
+func TestGetCurIdFastIsNeverStale(t *testing.T) {
+	tests := map[string]func(*Resource) error{
+		"SetName": func(r *Resource) error {
+			r.SetName("piglet")
+			return nil
+		},
+		"SetNamespace": func(r *Resource) error {
+			r.SetNamespace("forest")
+			return nil
+		},
+		"SetGvk": func(r *Resource) error {
+			r.SetGvk(resid.Gvk{Group: "apps", Version: "v1", Kind: "Deployment"})
+			return nil
+		},
+		"UnmarshalJSON": func(r *Resource) error {
+			return r.UnmarshalJSON([]byte(deploymentAsString))
+		},
+		"ApplySmPatch": func(r *Resource) error {
+			patch, err := factory.FromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: winnie
+  namespace: hundred-acre-wood
+$patch: delete
+`))
+			if err != nil {
+				return err
+			}
+			return r.ApplySmPatch(patch)
+		},
+		"SetFieldValue": func(r *Resource) error {
+			return r.SetFieldValue("metadata.name", "piglet", false)
+		},
+		"ApplyFilter": func(r *Resource) error {
+			return r.ApplyFilter(kio.FilterFunc(
+				func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+					for _, n := range nodes {
+						if err := n.PipeE(kyaml.SetK8sName("piglet")); err != nil {
+							return nil, err
+						}
+					}
+					return nodes, nil
+				}))
+		},
+		"ResetPrimaryData": func(r *Resource) error {
+			r.ResetPrimaryData(testDeployment)
+			return nil
+		},
+		"CopyMergeMetaDataFieldsFrom": func(r *Resource) error {
+			r.CopyMergeMetaDataFieldsFrom(testDeployment)
+			return nil
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := testConfigMap.DeepCopy()
+			before := r.GetCurIdFast()
+			if !assert.NoError(t, mutate(r)) {
+				t.FailNow()
+			}
+			assert.NotEqual(t, before, r.CurId())
+			assert.Equal(t, r.CurId(), r.GetCurIdFast())
+
+			// A watched resource tells its watch.
+			r = testConfigMap.DeepCopy()
+			w := NewIdWatch()
+			r.Watch(w)
+			if !assert.NoError(t, mutate(r)) {
+				t.FailNow()
+			}
+			assert.NotEqual(t, uint64(0), w.Changes())
+			assert.Equal(t, w.Changes(), r.IdVersion())
+			assert.Equal(t, r.CurId(), r.GetCurIdFast())
+			assert.Equal(t, w.Changes(), r.IdVersion())
+		})
+	}
+}
+
+func TestIdWatch(t *testing.T) {
+	r := testConfigMap.DeepCopy()
+	other := testDeployment.DeepCopy()
+	w, ow := NewIdWatch(), NewIdWatch()
+	r.Watch(w)
+	r.Watch(w)
+	other.Watch(ow)
+
+	// Only changes to the id count.
+	r.SetName(r.GetName())
+	r.SetNamespace(r.GetNamespace())
+	r.SetLabels(map[string]string{"a": "b"})
+	assert.Equal(t, uint64(0), w.Changes())
+	r.SetName("piglet")
+	assert.Equal(t, uint64(1), w.Changes())
+	assert.Equal(t, uint64(1), r.IdVersion())
+	assert.Equal(t, uint64(0), ow.Changes())
+
+	// Copies aren't watched.
+	c := r.DeepCopy()
+	c.SetName("pooh")
+	assert.Equal(t, uint64(1), w.Changes())
+
+	w.Close()
+	r.SetName("tigger")
+	assert.Equal(t, uint64(1), w.Changes())
+	assert.Equal(t, uint64(2), r.IdVersion())
+
+	// Too many watches evict the oldest, counting a
+	// change in it, as it misses those after.
+	var ws []*IdWatch
+	for i := 0; i < 9; i++ {
+		ws = append(ws, NewIdWatch())
+		r.Watch(ws[i])
+	}
+	assert.Equal(t, uint64(1), ws[0].Changes())
+	r.SetName("eeyore")
+	assert.Equal(t, uint64(1), ws[0].Changes())
+	for _, x := range ws[1:] {
+		assert.Equal(t, uint64(1), x.Changes())
+	}
+}
+
+func BenchmarkCurId(b *testing.B) {
+	r := testConfigMap.DeepCopy()
+	for i := 0; i < b.N; i++ {
+		r.CurId()
+	}
+}
+
+func BenchmarkGetCurIdFast(b *testing.B) {
+	r := testConfigMap.DeepCopy()
+	for i := 0; i < b.N; i++ {
+		r.GetCurIdFast()
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+)
+
+// SetOpenAPISchemas sets the OpenAPI definitions that the
+// Resources the Factory makes are patched and scoped with,
+// e.g. to give each of several builds running at once
+// definitions of its own.  Nil, the initial value, means the
+// global ones of the openapi package.
+func (rf *Factory) SetOpenAPISchemas(s *openapi.Schemas) {
+	rf.schemas = s
+}
+
+// OpenAPISchemas returns the schemas set by
+// SetOpenAPISchemas.
+func (rf *Factory) OpenAPISchemas() *openapi.Schemas {
+	return rf.schemas
+}
+
+// OpenAPISchemas returns the OpenAPI definitions of the
+// build the Resource is in, or nil for the global ones.
+func (r *Resource) OpenAPISchemas() *openapi.Schemas {
+	return r.schemas
+}
+
+// IsNamespaceableKind is the IsNamespaceableKind of the
+// Gvk of the Resource, per its OpenAPI definitions.
+func (r *Resource) IsNamespaceableKind() bool {
+	return r.GetGvk().IsNamespaceableKindIn(r.schemas)
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ifctest_test has fakes of the interfaces in package
+// ifc, for unit tests of code that uses PluginHelpers.
+package ifctest_test
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+)
+
+// fakeLoader is an ifc.Loader over an in-memory tree of files.
+type fakeLoader struct {
+	root  string
+	files map[string]string
+}
+
+// NewFakeLoader returns a Loader rooted at the given directory,
+// that loads the given files, a map of path to content.  Paths
+// that aren't absolute, in the map or given to Load or New, are
+// taken relative to the root.  Loaders made via New share the
+// files.  Remote locations aren't supported.
+func NewFakeLoader(root string, files map[string]string) ifc.Loader {
+	root = absPath("/", root)
+	tree := make(map[string]string, len(files))
+	for p, content := range files {
+		tree[absPath(root, p)] = content
+	}
+	return &fakeLoader{root: root, files: tree}
+}
+
+func absPath(root, p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	return path.Join(root, p)
+}
+
+func isRemote(location string) bool {
+	return strings.Contains(location, "://") ||
+		strings.HasPrefix(location, "github.com/")
+}
+
+// Root implements ifc.Loader.
+func (l *fakeLoader) Root() string {
+	return l.root
+}
+
+// New implements ifc.Loader.  It's an error if
+// no file is found under the new root.
+func (l *fakeLoader) New(newRoot string) (ifc.Loader, error) {
+	if isRemote(newRoot) {
+		return nil, fmt.Errorf(
+			"fake loader cannot load remote root '%s'", newRoot)
+	}
+	root := absPath(l.root, newRoot)
+	for p := range l.files {
+		if strings.HasPrefix(p, root+"/") {
+			return &fakeLoader{root: root, files: l.files}, nil
+		}
+	}
+	return nil, fmt.Errorf("fake loader has no directory '%s'", root)
+}
+
+// Load implements ifc.Loader.
+func (l *fakeLoader) Load(location string) ([]byte, error) {
+	if isRemote(location) {
+		return nil, fmt.Errorf(
+			"fake loader cannot load remote file '%s'", location)
+	}
+	p := absPath(l.root, location)
+	content, ok := l.files[p]
+	if !ok {
+		return nil, fmt.Errorf("fake loader has no file '%s'", p)
+	}
+	return []byte(content), nil
+}
+
+// Cleanup implements ifc.Loader; there's nothing to clean.
+func (l *fakeLoader) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ifctest_test
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validatorMethods are the names of the methods of ifc.Validator.
+var validatorMethods = map[string]bool{
+	"MakeAnnotationValidator":     true,
+	"MakeAnnotationNameValidator": true,
+	"MakeLabelValidator":          true,
+	"MakeLabelNameValidator":      true,
+	"ValidateNamespace":           true,
+	"ErrIfInvalidKey":             true,
+	"IsEnvVarName":                true,
+}
+
+// FakeValidator is an ifc.Validator that allows everything,
+// except what it's told to Deny.  It counts the calls to each
+// of its methods; for the Make methods, the calls to the
+// functions they return.
+type FakeValidator struct {
+	denied map[string]error
+	calls  map[string]int
+}
+
+// NewFakeValidator returns a FakeValidator that allows everything.
+func NewFakeValidator() *FakeValidator {
+	return &FakeValidator{
+		denied: make(map[string]error),
+		calls:  make(map[string]int),
+	}
+}
+
+// Deny makes the named method of ifc.Validator, e.g.
+// "IsEnvVarName", report the given error, whatever its
+// argument.  It panics if there's no method by that name.
+func (v *FakeValidator) Deny(method string, err error) *FakeValidator {
+	mustBeValidatorMethod(method)
+	v.denied[method] = err
+	return v
+}
+
+// Allow undoes Deny.
+func (v *FakeValidator) Allow(method string) *FakeValidator {
+	mustBeValidatorMethod(method)
+	delete(v.denied, method)
+	return v
+}
+
+// Calls returns the number of calls to the named method.
+func (v *FakeValidator) Calls(method string) int {
+	mustBeValidatorMethod(method)
+	return v.calls[method]
+}
+
+func mustBeValidatorMethod(method string) {
+	if !validatorMethods[method] {
+		panic(fmt.Sprintf("ifc.Validator has no method %s", method))
+	}
+}
+
+func (v *FakeValidator) check(method string) error {
+	v.calls[method]++
+	return v.denied[method]
+}
+
+func (v *FakeValidator) MakeAnnotationValidator() func(map[string]string) error {
+	return func(map[string]string) error {
+		return v.check("MakeAnnotationValidator")
+	}
+}
+
+func (v *FakeValidator) MakeAnnotationNameValidator() func([]string) error {
+	return func([]string) error {
+		return v.check("MakeAnnotationNameValidator")
+	}
+}
+
+func (v *FakeValidator) MakeLabelValidator() func(map[string]string) error {
+	return func(map[string]string) error {
+		return v.check("MakeLabelValidator")
+	}
+}
+
+func (v *FakeValidator) MakeLabelNameValidator() func([]string) error {
+	return func([]string) error {
+		return v.check("MakeLabelNameValidator")
+	}
+}
+
+func (v *FakeValidator) ValidateNamespace(string) []string {
+	if err := v.check("ValidateNamespace"); err != nil {
+		return strings.Split(err.Error(), "\n")
+	}
+	return nil
+}
+
+func (v *FakeValidator) ErrIfInvalidKey(string) error {
+	return v.check("ErrIfInvalidKey")
+}
+
+func (v *FakeValidator) IsEnvVarName(string) error {
+	return v.check("IsEnvVarName")
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package ifctest_test
+
+import (
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// NewPluginHelpers returns PluginHelpers holding the given
+// loader and validator, e.g. a NewFakeLoader and a
+// NewFakeValidator, and the given factory, or, if it's nil,
+// the default one.
+func NewPluginHelpers(
+	ldr ifc.Loader, v ifc.Validator, rf *resmap.Factory) *resmap.PluginHelpers {
+	if rf == nil {
+		p := provider.NewDefaultDepProvider()
+		rf = resmap.NewFactory(
+			p.GetResourceFactory(), p.GetConflictDetectorFactory())
+	}
+	return resmap.NewPluginHelpers(
+		ldr, v, rf, resmap.NewSchema(rf.RF().OpenAPISchemas()))
+}
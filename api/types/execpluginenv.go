@@ -0,0 +1,44 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "strings"
+
+// ExecPluginEnv says which variables of kustomize's own
+// environment exec plugins inherit.  By default, they
+// inherit all of them.  The variables kustomize sets for
+// them, e.g. KUSTOMIZE_PLUGIN_CONFIG_ROOT, are set either
+// way.  A name ending in * stands for every variable
+// starting with what comes before, e.g. AWS_*.
+type ExecPluginEnv struct {
+	// Allow, if not empty, names the only variables
+	// that exec plugins inherit.
+	Allow []string
+
+	// Deny names variables that exec plugins don't
+	// inherit, even if allowed.
+	Deny []string
+}
+
+// Inherits returns true if exec plugins inherit
+// the variable with the given name.
+func (e ExecPluginEnv) Inherits(name string) bool {
+	if len(e.Allow) > 0 && !matchesEnvName(e.Allow, name) {
+		return false
+	}
+	return !matchesEnvName(e.Deny, name)
+}
+
+func matchesEnvName(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if p == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecPluginEnvInherits(t *testing.T) {
+	testCases := map[string]struct {
+		env      ExecPluginEnv
+		inherits []string
+		denied   []string
+	}{
+		"default": {
+			inherits: []string{"PATH", "CI_TOKEN"},
+		},
+		"deny": {
+			env:      ExecPluginEnv{Deny: []string{"CI_*", "AWS_SECRET_ACCESS_KEY"}},
+			inherits: []string{"PATH", "AWS_REGION", "CI"},
+			denied:   []string{"CI_TOKEN", "CI_", "AWS_SECRET_ACCESS_KEY"},
+		},
+		"allow": {
+			env:      ExecPluginEnv{Allow: []string{"PATH", "HOME", "LC_*"}},
+			inherits: []string{"PATH", "HOME", "LC_ALL"},
+			denied:   []string{"CI_TOKEN", "PATHS", "LANG"},
+		},
+		"deny wins": {
+			env: ExecPluginEnv{
+				Allow: []string{"*"},
+				Deny:  []string{"GITHUB_TOKEN"},
+			},
+			inherits: []string{"PATH", "GITHUB_SHA"},
+			denied:   []string{"GITHUB_TOKEN"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, v := range tc.inherits {
+				assert.True(t, tc.env.Inherits(v), v)
+			}
+			for _, v := range tc.denied {
+				assert.False(t, tc.env.Inherits(v), v)
+			}
+		})
+	}
+}
@@ -32,6 +32,28 @@ type FieldSpec struct {
 	resid.Gvk          `json:",inline,omitempty" yaml:",inline,omitempty"`
 	Path               string `json:"path,omitempty" yaml:"path,omitempty"`
 	CreateIfNotPresent bool   `json:"create,omitempty" yaml:"create,omitempty"`
+
+	// The fields below are used only in nameReference
+	// field specs whose path leads to a map, or a list of
+	// maps, that refers to an object by its name and type,
+	// e.g. an ownerReferences item or a roleRef:
+	//
+	// {
+	//   path: metadata/ownerReferences
+	//   nameField: name
+	//   kindField: kind
+	//   apiVersionField: apiVersion
+	// }
+	//
+	// NameField is the field in the map holding the name.
+	// When it's set, a map's name is updated only if the
+	// map's own fields, per KindField, ApiVersionField and
+	// ApiGroupField, match the referred object, and other
+	// fields of the map, e.g. a uid, are left as they are.
+	NameField       string `json:"nameField,omitempty" yaml:"nameField,omitempty"`
+	KindField       string `json:"kindField,omitempty" yaml:"kindField,omitempty"`
+	ApiVersionField string `json:"apiVersionField,omitempty" yaml:"apiVersionField,omitempty"`
+	ApiGroupField   string `json:"apiGroupField,omitempty" yaml:"apiGroupField,omitempty"`
 }
 
 const (
@@ -49,6 +71,13 @@ func (fs FieldSpec) effectivelyEquals(other FieldSpec) bool {
 	return fs.IsSelected(&other.Gvk) && fs.Path == other.Path
 }
 
+func (fs FieldSpec) sameReferenceFields(other FieldSpec) bool {
+	return fs.NameField == other.NameField &&
+		fs.KindField == other.KindField &&
+		fs.ApiVersionField == other.ApiVersionField &&
+		fs.ApiGroupField == other.ApiGroupField
+}
+
 // PathSlice converts the path string to a slice of strings,
 // separated by a '/'. Forward slash can be contained in a
 // fieldname. such as ingress.kubernetes.io/auth-secret in
@@ -108,7 +137,8 @@ func (s FsSlice) MergeOne(x FieldSpec) (FsSlice, error) {
 	i := s.index(x)
 	if i > -1 {
 		// It's already there.
-		if s[i].CreateIfNotPresent != x.CreateIfNotPresent {
+		if s[i].CreateIfNotPresent != x.CreateIfNotPresent ||
+			!s[i].sameReferenceFields(x) {
 			return nil, fmt.Errorf("conflicting fieldspecs")
 		}
 		return s, nil
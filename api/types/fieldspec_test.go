@@ -143,6 +143,26 @@ var mergeTests = []struct {
 		fmt.Errorf("hey"),
 		FsSlice{},
 	},
+	{
+		"error on conflicting reference fields",
+		FsSlice{
+			{
+				Path:      "roleRef",
+				Gvk:       resid.Gvk{Kind: "RoleBinding"},
+				NameField: "name",
+				KindField: "kind",
+			},
+		},
+		FsSlice{
+			{
+				Path:      "roleRef",
+				Gvk:       resid.Gvk{Kind: "RoleBinding"},
+				NameField: "name",
+			},
+		},
+		fmt.Errorf("hey"),
+		FsSlice{},
+	},
 }
 
 func TestFsSlice_MergeAll(t *testing.T) {
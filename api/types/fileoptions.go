@@ -0,0 +1,168 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// FileOptions normalize the text files a generator makes
+// keys of, before the keys are made and hashed, so that
+// e.g. a checkout on Windows, with CRLF line endings,
+// generates the same data, and so the same name suffix
+// hash, as a checkout elsewhere.  Enabling them changes,
+// once, the data and hash of each generated resource
+// whose files they change.
+//
+// Binary files are never normalized.  In a ConfigMap,
+// they go in binaryData, even if they're valid UTF-8.
+type FileOptions struct {
+	// NewlineNormalization is none, the default, to leave
+	// line endings as they are, lf to make each a \n, or
+	// crlf to make each a \r\n.
+	NewlineNormalization NewlineNormalization `json:"newlineNormalization,omitempty" yaml:"newlineNormalization,omitempty"`
+
+	// EnsureTrailingNewline is keep, the default, to leave
+	// the end of each file as it is, true to end each file
+	// that isn't empty with a newline, or false to remove
+	// any newlines ending each file.
+	EnsureTrailingNewline TrailingNewline `json:"ensureTrailingNewline,omitempty" yaml:"ensureTrailingNewline,omitempty"`
+
+	// BinaryFiles are the paths of binary files, as given
+	// in files or matched by a glob there, or patterns
+	// matching them, e.g. img/*.png.  A file holding a null
+	// byte is binary, whether listed or not.
+	BinaryFiles []string `json:"binaryFiles,omitempty" yaml:"binaryFiles,omitempty"`
+}
+
+// NewlineNormalization names a line ending to give
+// the lines of text files.
+type NewlineNormalization string
+
+const (
+	NoNewlineNormalization   NewlineNormalization = "none"
+	LfNewlineNormalization   NewlineNormalization = "lf"
+	CrlfNewlineNormalization NewlineNormalization = "crlf"
+)
+
+// TrailingNewline says what to do with the newlines, if
+// any, ending a text file.  In YAML, it's a boolean or
+// keep.
+type TrailingNewline string
+
+const (
+	KeepTrailingNewline   TrailingNewline = "keep"
+	EnsureTrailingNewline TrailingNewline = "true"
+	RemoveTrailingNewline TrailingNewline = "false"
+)
+
+// UnmarshalJSON accepts a boolean, as well as a string.
+func (t *TrailingNewline) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*t = RemoveTrailingNewline
+		if b {
+			*t = EnsureTrailingNewline
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(
+			"ensureTrailingNewline should be true, false or keep")
+	}
+	*t = TrailingNewline(s)
+	return nil
+}
+
+// MarshalJSON writes true and false as booleans.
+func (t TrailingNewline) MarshalJSON() ([]byte, error) {
+	switch t {
+	case EnsureTrailingNewline:
+		return []byte("true"), nil
+	case RemoveTrailingNewline:
+		return []byte("false"), nil
+	}
+	return json.Marshal(string(t))
+}
+
+// validate reports each problem with the options,
+// naming the field with the given prefix.
+func (fo *FileOptions) validate(
+	prefix string, report func(field, format string, args ...interface{})) {
+	switch fo.NewlineNormalization {
+	case "", NoNewlineNormalization,
+		LfNewlineNormalization, CrlfNewlineNormalization:
+	default:
+		report(prefix+".newlineNormalization",
+			"%q is not one of none, lf or crlf", fo.NewlineNormalization)
+	}
+	switch fo.EnsureTrailingNewline {
+	case "", KeepTrailingNewline,
+		EnsureTrailingNewline, RemoveTrailingNewline:
+	default:
+		report(prefix+".ensureTrailingNewline",
+			"%q is not one of true, false or keep", fo.EnsureTrailingNewline)
+	}
+	for i, p := range fo.BinaryFiles {
+		if _, err := path.Match(p, ""); err != nil {
+			report(fmt.Sprintf("%s.binaryFiles[%d]", prefix, i),
+				"%q is not a valid pattern", p)
+		}
+	}
+}
+
+// IsBinary returns true if the file at the given path,
+// with the given content, is binary: it's listed in
+// BinaryFiles, or holds a null byte.
+func (fo *FileOptions) IsBinary(filePath string, content []byte) bool {
+	for _, b := range content {
+		if b == 0 {
+			return true
+		}
+	}
+	if fo == nil {
+		return false
+	}
+	filePath = path.Clean(filePath)
+	for _, p := range fo.BinaryFiles {
+		if ok, _ := path.Match(path.Clean(p), filePath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize returns the content of a text file,
+// normalized as the options say.
+func (fo *FileOptions) Normalize(content string) string {
+	if fo == nil {
+		return content
+	}
+	eol := "\n"
+	switch fo.NewlineNormalization {
+	case LfNewlineNormalization:
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	case CrlfNewlineNormalization:
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+		eol = "\r\n"
+	default:
+		if strings.Contains(content, "\r\n") {
+			eol = "\r\n"
+		}
+	}
+	switch fo.EnsureTrailingNewline {
+	case EnsureTrailingNewline:
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += eol
+		}
+	case RemoveTrailingNewline:
+		content = strings.TrimRight(content, "\r\n")
+	}
+	return content
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestFileOptionsNormalize(t *testing.T) {
+	testCases := map[string]struct {
+		opts     FileOptions
+		content  string
+		expected string
+	}{
+		"none": {
+			content:  "a\r\nb",
+			expected: "a\r\nb",
+		},
+		"lf": {
+			opts:     FileOptions{NewlineNormalization: LfNewlineNormalization},
+			content:  "a\r\nb\nc\r\n",
+			expected: "a\nb\nc\n",
+		},
+		"crlf": {
+			opts:     FileOptions{NewlineNormalization: CrlfNewlineNormalization},
+			content:  "a\r\nb\nc",
+			expected: "a\r\nb\r\nc",
+		},
+		"ensure": {
+			opts:     FileOptions{EnsureTrailingNewline: EnsureTrailingNewline},
+			content:  "a\nb",
+			expected: "a\nb\n",
+		},
+		"ensure crlf": {
+			opts: FileOptions{
+				NewlineNormalization:  CrlfNewlineNormalization,
+				EnsureTrailingNewline: EnsureTrailingNewline,
+			},
+			content:  "a\nb",
+			expected: "a\r\nb\r\n",
+		},
+		"ensure keeps the line ending": {
+			opts:     FileOptions{EnsureTrailingNewline: EnsureTrailingNewline},
+			content:  "a\r\nb",
+			expected: "a\r\nb\r\n",
+		},
+		"ensure leaves empty files": {
+			opts:     FileOptions{EnsureTrailingNewline: EnsureTrailingNewline},
+			content:  "",
+			expected: "",
+		},
+		"remove": {
+			opts:     FileOptions{EnsureTrailingNewline: RemoveTrailingNewline},
+			content:  "a\r\nb\n\r\n\n",
+			expected: "a\r\nb",
+		},
+		"keep": {
+			opts:     FileOptions{EnsureTrailingNewline: KeepTrailingNewline},
+			content:  "a\n\n",
+			expected: "a\n\n",
+		},
+	}
+	for name, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.opts.Normalize(tc.content), name)
+	}
+	var nilOpts *FileOptions
+	assert.Equal(t, "a\r\n", nilOpts.Normalize("a\r\n"))
+}
+
+func TestFileOptionsIsBinary(t *testing.T) {
+	opts := &FileOptions{BinaryFiles: []string{"img/*.png", "./cert.der"}}
+	assert.True(t, opts.IsBinary("img/logo.png", []byte("PNG")))
+	assert.True(t, opts.IsBinary("cert.der", []byte("DER")))
+	assert.True(t, opts.IsBinary("data.bin", []byte("a\x00b")))
+	assert.False(t, opts.IsBinary("img/sub/logo.png", []byte("PNG")))
+	assert.False(t, opts.IsBinary("app.conf", []byte("a=b")))
+}
+
+func TestTrailingNewlineYaml(t *testing.T) {
+	for y, expected := range map[string]TrailingNewline{
+		"ensureTrailingNewline: true":   EnsureTrailingNewline,
+		"ensureTrailingNewline: false":  RemoveTrailingNewline,
+		"ensureTrailingNewline: keep":   KeepTrailingNewline,
+		`ensureTrailingNewline: "true"`: EnsureTrailingNewline,
+	} {
+		var opts FileOptions
+		if assert.NoError(t, yaml.Unmarshal([]byte(y), &opts), y) {
+			assert.Equal(t, expected, opts.EnsureTrailingNewline, y)
+		}
+		out, err := yaml.Marshal(opts)
+		assert.NoError(t, err)
+		if expected == KeepTrailingNewline {
+			assert.Equal(t, y+"\n", string(out))
+		} else {
+			assert.Equal(t, "ensureTrailingNewline: "+string(expected)+"\n",
+				string(out))
+		}
+	}
+	var opts FileOptions
+	err := yaml.Unmarshal([]byte("ensureTrailingNewline: [a]"), &opts)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"ensureTrailingNewline should be true, false or keep")
+	}
+}
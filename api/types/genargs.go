@@ -30,6 +30,12 @@ func (g *GenArgs) String() string {
 		"}"
 }
 
+// IsGenerated returns true if there are generator args,
+// i.e. the resource holding them was made by a generator.
+func (g *GenArgs) IsGenerated() bool {
+	return g != nil && g.args != nil
+}
+
 // ShouldAddHashSuffixToName returns true if a resource
 // content hash should be appended to the name of the resource.
 func (g *GenArgs) ShouldAddHashSuffixToName() bool {
@@ -37,6 +43,15 @@ func (g *GenArgs) ShouldAddHashSuffixToName() bool {
 		(g.args.Options == nil || !g.args.Options.DisableNameSuffixHash)
 }
 
+// LabelKeysToPropagate returns the keys of the labels
+// to add to the resources that refer to the generated one.
+func (g *GenArgs) LabelKeysToPropagate() []string {
+	if g == nil || g.args == nil || g.args.Options == nil {
+		return nil
+	}
+	return g.args.Options.PropagateLabelsToConsumers
+}
+
 // Behavior returns Behavior field of GeneratorArgs
 func (g *GenArgs) Behavior() GenerationBehavior {
 	if g.args == nil {
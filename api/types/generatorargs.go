@@ -3,6 +3,12 @@
 
 package types
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
 // GeneratorArgs contains arguments common to ConfigMap and Secret generators.
 type GeneratorArgs struct {
 	// Namespace for the configmap, optional
@@ -25,3 +31,104 @@ type GeneratorArgs struct {
 	// Local overrides to global generatorOptions field.
 	Options *GeneratorOptions `json:"options,omitempty" yaml:"options,omitempty"`
 }
+
+// GeneratorArgsError lists every problem Validate
+// found in a GeneratorArgs, each naming the field,
+// e.g. files[1], and quoting the offending value.
+type GeneratorArgsError struct {
+	Problems []string
+}
+
+func (e *GeneratorArgsError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// maxSubdomainLength is the most characters
+// an RFC 1123 subdomain may have.
+const maxSubdomainLength = 253
+
+var (
+	rfc1123Label       = `[a-z0-9]([-a-z0-9]*[a-z0-9])?`
+	rfc1123LabelRegexp = regexp.MustCompile(
+		"^" + rfc1123Label + "$")
+	rfc1123SubdomainRegexp = regexp.MustCompile(
+		"^" + rfc1123Label + `(\.` + rfc1123Label + ")*$")
+)
+
+// Validate checks all the fields of the args, without
+// reading any files, returning a *GeneratorArgsError
+// listing every problem found, or nil.  The name must
+// be an RFC 1123 subdomain before any hash suffix is
+// added, and the namespace, if any, an RFC 1123 label.
+func (ga GeneratorArgs) Validate() error {
+	var problems []string
+	report := func(field, format string, args ...interface{}) {
+		problems = append(problems,
+			field+": "+fmt.Sprintf(format, args...))
+	}
+	switch {
+	case ga.Name == "":
+		report("name", "must not be empty")
+	case len(ga.Name) > maxSubdomainLength:
+		report("name", "%q is longer than %d characters",
+			ga.Name, maxSubdomainLength)
+	case !rfc1123SubdomainRegexp.MatchString(ga.Name):
+		report("name", "%q is not an RFC 1123 subdomain; use "+
+			"lower case alphanumerics, '-' and '.', starting and "+
+			"ending with an alphanumeric", ga.Name)
+	}
+	if ga.Namespace != "" &&
+		!rfc1123LabelRegexp.MatchString(ga.Namespace) {
+		report("namespace", "%q is not an RFC 1123 label", ga.Namespace)
+	}
+	switch ga.Behavior {
+	case "", "create", "replace", "merge":
+	default:
+		report("behavior", "%q is not one of create, replace or merge",
+			ga.Behavior)
+	}
+	for i, s := range ga.LiteralSources {
+		field := fmt.Sprintf("literals[%d]", i)
+		if k := strings.SplitN(s, "=", 2); len(k) != 2 {
+			report(field, "%q is not of the form key=value", s)
+		} else if k[0] == "" {
+			report(field, "%q has no key", s)
+		}
+	}
+	for i, s := range ga.FileSources {
+		field := fmt.Sprintf("files[%d]", i)
+		switch n := strings.Count(s, "="); {
+		case s == "":
+			report(field, "must not be empty")
+		case n > 1:
+			report(field, "%q has %d '='; neither key names nor "+
+				"file paths may contain '='", s, n)
+		case strings.HasPrefix(s, "="):
+			report(field, "%q has no key name before the '='", s)
+		case strings.HasSuffix(s, "="):
+			report(field, "%q has no file path after the '='", s)
+		case n == 1 && hasGlobMeta(strings.SplitN(s, "=", 2)[1]):
+			report(field, "%q gives a key name to a glob", s)
+		}
+	}
+	for i, s := range ga.EnvSources {
+		field := fmt.Sprintf("envs[%d]", i)
+		switch {
+		case s == "":
+			report(field, "must not be empty")
+		case hasGlobMeta(s):
+			report(field, "%q is a glob, which envs don't support", s)
+		}
+	}
+	if ga.FileOptions != nil {
+		ga.FileOptions.validate("fileOptions", report)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &GeneratorArgsError{Problems: problems}
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[`)
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	. "sigs.k8s.io/kustomize/api/types"
+)
+
+func TestGeneratorArgsValidate(t *testing.T) {
+	testCases := map[string]struct {
+		args     GeneratorArgs
+		problems []string
+	}{
+		"valid": {
+			args: GeneratorArgs{
+				Name:      "app-config.v1",
+				Namespace: "shop",
+				Behavior:  "merge",
+				KvPairSources: KvPairSources{
+					LiteralSources: []string{"a=b", "c="},
+					FileSources:    []string{"k=app.properties", "conf/*"},
+					EnvSources:     []string{"a.env"},
+				},
+			},
+		},
+		"emptyName": {
+			args:     GeneratorArgs{},
+			problems: []string{"name: must not be empty"},
+		},
+		"longName": {
+			args: GeneratorArgs{Name: strings.Repeat("a", 254)},
+			problems: []string{
+				`name: "` + strings.Repeat("a", 254) +
+					`" is longer than 253 characters`},
+		},
+		"everythingWrong": {
+			args: GeneratorArgs{
+				Name:      "appConfig",
+				Namespace: "my.ns",
+				Behavior:  "upsert",
+				KvPairSources: KvPairSources{
+					LiteralSources: []string{"ab", "=b"},
+					FileSources: []string{
+						"a=b=c", "=path", "key=", "",
+						"k=conf/*.properties"},
+					EnvSources: []string{"*.env", ""},
+					FileOptions: &FileOptions{
+						NewlineNormalization:  "cr",
+						EnsureTrailingNewline: "yes",
+						BinaryFiles:           []string{"img/*.png", "[a"},
+					},
+				},
+			},
+			problems: []string{
+				`name: "appConfig" is not an RFC 1123 subdomain; use lower ` +
+					`case alphanumerics, '-' and '.', starting and ending ` +
+					`with an alphanumeric`,
+				`namespace: "my.ns" is not an RFC 1123 label`,
+				`behavior: "upsert" is not one of create, replace or merge`,
+				`literals[0]: "ab" is not of the form key=value`,
+				`literals[1]: "=b" has no key`,
+				`files[0]: "a=b=c" has 2 '='; neither key names nor ` +
+					`file paths may contain '='`,
+				`files[1]: "=path" has no key name before the '='`,
+				`files[2]: "key=" has no file path after the '='`,
+				`files[3]: must not be empty`,
+				`files[4]: "k=conf/*.properties" gives a key name to a glob`,
+				`envs[0]: "*.env" is a glob, which envs don't support`,
+				`envs[1]: must not be empty`,
+				`fileOptions.newlineNormalization: "cr" is not one of none, lf or crlf`,
+				`fileOptions.ensureTrailingNewline: "yes" is not one of true, false or keep`,
+				`fileOptions.binaryFiles[1]: "[a" is not a valid pattern`,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.args.Validate()
+			if tc.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.problems,
+					err.(*GeneratorArgsError).Problems)
+			}
+		})
+	}
+	// Promoted to the args of both generators.
+	assert.Error(t, ConfigMapArgs{}.Validate())
+	assert.Error(t, SecretArgs{}.Validate())
+}
@@ -6,6 +6,9 @@ package types
 // GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 type GeneratorOptions struct {
 	// Labels to add to all generated resources.
+	// Like the annotations, they don't count toward the
+	// name suffix hash, so changing them alone causes no
+	// rollout of the resources using a generated resource.
 	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 
 	// Annotations to add to all generated resources.
@@ -15,6 +18,12 @@ type GeneratorOptions struct {
 	// suffix to the names of generated resources that is a hash of the
 	// resource contents.
 	DisableNameSuffixHash bool `json:"disableNameSuffixHash,omitempty" yaml:"disableNameSuffixHash,omitempty"`
+
+	// PropagateLabelsToConsumers lists the keys of labels of
+	// generated resources to add to the metadata of the
+	// resources in the build that refer to them by name,
+	// e.g. the Deployments that mount a generated ConfigMap.
+	PropagateLabelsToConsumers []string `json:"propagateLabelsToConsumers,omitempty" yaml:"propagateLabelsToConsumers,omitempty"`
 }
 
 // MergeGlobalOptionsIntoLocal merges two instances of GeneratorOptions.
@@ -42,9 +51,31 @@ func MergeGlobalOptionsIntoLocal(
 	if globalOpts.DisableNameSuffixHash {
 		localOpts.DisableNameSuffixHash = true
 	}
+	localOpts.PropagateLabelsToConsumers = unionOfKeys(
+		localOpts.PropagateLabelsToConsumers,
+		globalOpts.PropagateLabelsToConsumers)
 	return localOpts
 }
 
+// unionOfKeys returns the local keys followed by
+// the global keys that aren't among them.
+func unionOfKeys(local, global []string) []string {
+	result := local
+	for _, k := range global {
+		found := false
+		for _, l := range local {
+			if l == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
 func overrideMap(localMap *map[string]string, globalMap map[string]string) {
 	if *localMap == nil {
 		if globalMap != nil {
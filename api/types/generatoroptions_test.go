@@ -102,6 +102,18 @@ func TestMergeGlobalOptionsIntoLocal(t *testing.T) {
 				DisableNameSuffixHash: true,
 			},
 		},
+		{
+			name: "propagated label keys are combined",
+			local: &GeneratorOptions{
+				PropagateLabelsToConsumers: []string{"pet", "fruit"},
+			},
+			global: &GeneratorOptions{
+				PropagateLabelsToConsumers: []string{"fruit", "simpson"},
+			},
+			expected: &GeneratorOptions{
+				PropagateLabelsToConsumers: []string{"pet", "fruit", "simpson"},
+			},
+		},
 		{
 			name: "everyone wants disable",
 			local: &GeneratorOptions{
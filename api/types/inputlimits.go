@@ -0,0 +1,61 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+const (
+	// DefaultMaxFileSize is the default of InputLimits.MaxFileSize.
+	DefaultMaxFileSize = 64 << 20
+
+	// DefaultMaxDocuments is the default of InputLimits.MaxDocuments.
+	DefaultMaxDocuments = 10000
+
+	// DefaultMaxNodeDepth is the default of InputLimits.MaxNodeDepth.
+	DefaultMaxNodeDepth = 256
+
+	// DefaultMaxAliasExpansion is the default of
+	// InputLimits.MaxAliasExpansion.
+	DefaultMaxAliasExpansion = 100000
+)
+
+// InputLimits bound the size and complexity of the YAML
+// that a build reads, from files or from the output of
+// plugins, so that an input, e.g. in a remote base, that
+// is broken or crafted to exhaust memory fails the build
+// instead.  A zero field takes its default value; a
+// negative one means no limit.
+type InputLimits struct {
+	// MaxFileSize is the size, in bytes, of the
+	// largest file, or plugin output, to accept.
+	MaxFileSize int64 `json:"maxFileSize,omitempty" yaml:"maxFileSize,omitempty"`
+
+	// MaxDocuments is the largest number of
+	// YAML documents to accept in one input.
+	MaxDocuments int `json:"maxDocuments,omitempty" yaml:"maxDocuments,omitempty"`
+
+	// MaxNodeDepth is the deepest nesting of maps
+	// and lists to accept in a document.
+	MaxNodeDepth int `json:"maxNodeDepth,omitempty" yaml:"maxNodeDepth,omitempty"`
+
+	// MaxAliasExpansion is the largest number of nodes
+	// the aliases in one input may expand to.
+	MaxAliasExpansion int `json:"maxAliasExpansion,omitempty" yaml:"maxAliasExpansion,omitempty"`
+}
+
+// Effective returns the limits with the
+// defaults in place of any zero fields.
+func (l InputLimits) Effective() InputLimits {
+	if l.MaxFileSize == 0 {
+		l.MaxFileSize = DefaultMaxFileSize
+	}
+	if l.MaxDocuments == 0 {
+		l.MaxDocuments = DefaultMaxDocuments
+	}
+	if l.MaxNodeDepth == 0 {
+		l.MaxNodeDepth = DefaultMaxNodeDepth
+	}
+	if l.MaxAliasExpansion == 0 {
+		l.MaxAliasExpansion = DefaultMaxAliasExpansion
+	}
+	return l
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// InputRole describes why a build reads a given input.
+type InputRole string
+
+const (
+	// A kustomization file (kustomization.yaml or similar).
+	InputRoleKustomization InputRole = "kustomization"
+
+	// A file listed in the resources (or bases) field.
+	InputRoleResource InputRole = "resource"
+
+	// A component directory, or the kustomization file in it.
+	InputRoleComponent InputRole = "component"
+
+	// A strategic merge or json patch file.
+	InputRolePatch InputRole = "patch"
+
+	// A file or env source of a ConfigMap or Secret generator.
+	InputRoleGeneratorSource InputRole = "generatorSource"
+
+	// A transformer configuration file.
+	InputRoleConfiguration InputRole = "configuration"
+
+	// A custom resource definition file.
+	InputRoleCrd InputRole = "crd"
+
+	// A generator plugin config file.
+	InputRoleGenerator InputRole = "generator"
+
+	// A transformer plugin config file.
+	InputRoleTransformer InputRole = "transformer"
+
+	// A validator plugin config file.
+	InputRoleValidator InputRole = "validator"
+)
+
+// InputRef is a reference to something a build would read.
+type InputRef struct {
+	// Path is relative to the root of the build, unless
+	// Remote is true, in which case it's the remote ref
+	// exactly as written in the kustomization file.
+	Path string `json:"path" yaml:"path"`
+
+	// Role says why the input is read.
+	Role InputRole `json:"role" yaml:"role"`
+
+	// Remote is true for git repos and http(s) urls.
+	// These are reported but never fetched.
+	Remote bool `json:"remote,omitempty" yaml:"remote,omitempty"`
+
+	// Glob is true if Path is a glob, e.g. a generator file
+	// source like conf/*.properties.  The files it matches
+	// are reported too, but a file created later that it
+	// matches would be read as well.
+	Glob bool `json:"glob,omitempty" yaml:"glob,omitempty"`
+}
@@ -18,6 +18,12 @@ const (
 	MetadataNamespacePath = "metadata/namespace"
 )
 
+// StdinResourcesPath is the resources entry that stands for
+// the resources the build reads on stdin.  Only the top-level
+// kustomization of a build may list it, and only once, so the
+// place of those resources in the output is never ambiguous.
+const StdinResourcesPath = "-"
+
 // Kustomization holds the information needed to generate customized k8s api resources.
 type Kustomization struct {
 	TypeMeta `json:",inline" yaml:",inline"`
@@ -25,6 +31,10 @@ type Kustomization struct {
 	// MetaData is a pointer to avoid marshalling empty struct
 	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 
+	// Requires lists the capabilities the build needs to
+	// have for the kustomization, e.g. helm.
+	Requires *Requirements `json:"requires,omitempty" yaml:"requires,omitempty"`
+
 	//
 	// Operators - what kustomize can do.
 	//
@@ -40,12 +50,50 @@ type Kustomization struct {
 	// Namespace to add to all objects.
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
+	// NamespacePrefix and NamespaceSuffix are added to the
+	// names of the Namespaces in the build, once the
+	// namespace above is set, and wherever those namespaces
+	// are named, e.g. in the namespaces of resources and of
+	// RoleBinding subjects.  The names of namespaces the
+	// build doesn't create are left as they are, unless
+	// the NamespacePrefixSuffixOptions say otherwise.
+	NamespacePrefix string `json:"namespacePrefix,omitempty" yaml:"namespacePrefix,omitempty"`
+	NamespaceSuffix string `json:"namespaceSuffix,omitempty" yaml:"namespaceSuffix,omitempty"`
+
+	NamespacePrefixSuffixOptions *NamespacePrefixSuffixOptions `json:"namespacePrefixSuffixOptions,omitempty" yaml:"namespacePrefixSuffixOptions,omitempty"`
+
+	// EnforceClusterScopedOnly, for packages of
+	// cluster-scoped components, e.g. CRDs and
+	// ClusterRoles, fails the build of the kustomization
+	// if any of its resources is of a namespaced kind or
+	// has a namespace, or if it, or any kustomization it
+	// includes, sets namespace or the fields above.
+	EnforceClusterScopedOnly bool `json:"enforceClusterScopedOnly,omitempty" yaml:"enforceClusterScopedOnly,omitempty"`
+
 	// CommonLabels to add to all objects and selectors.
 	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
 
 	// CommonAnnotations to add to all objects.
 	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
 
+	// RemoveCommonLabels are keys of the commonLabels of
+	// the bases and components to remove from all objects
+	// and selectors, where those commonLabels put them,
+	// before the transformers of this kustomization run.
+	// Removing a label from the selector of, say, a
+	// Deployment changes a field the cluster won't let
+	// change in place.
+	RemoveCommonLabels []string `json:"removeCommonLabels,omitempty" yaml:"removeCommonLabels,omitempty"`
+
+	// RemoveCommonAnnotations are keys of the
+	// commonAnnotations of the bases and components to
+	// remove from all objects, as RemoveCommonLabels does.
+	RemoveCommonAnnotations []string `json:"removeCommonAnnotations,omitempty" yaml:"removeCommonAnnotations,omitempty"`
+
+	// MetadataValidation modify how the labels and
+	// annotations above are checked.
+	MetadataValidation *MetadataValidation `json:"metadataValidation,omitempty" yaml:"metadataValidation,omitempty"`
+
 	// PatchesStrategicMerge specifies the relative path to a file
 	// containing a strategic merge patch.  Format documented at
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/strategic-merge-patch.md
@@ -60,8 +108,14 @@ type Kustomization struct {
 	// Patches is a list of patches, where each one can be either a
 	// Strategic Merge Patch or a JSON patch.
 	// Each patch can be applied to multiple target objects.
+	// The patches apply in list order, each to the resources
+	// as the patches before it left them, unless PatchOptions
+	// say otherwise.
 	Patches []Patch `json:"patches,omitempty" yaml:"patches,omitempty"`
 
+	// PatchOptions say how the patches apply.
+	PatchOptions *PatchOptions `json:"patchOptions,omitempty" yaml:"patchOptions,omitempty"`
+
 	// Images is a list of (image name, new name, new tag or digest)
 	// for changing image names, tags or digests. This can also be achieved with a
 	// patch, but this operator is simpler to specify.
@@ -84,9 +138,18 @@ type Kustomization struct {
 	// Operands - what kustomize operates on.
 	//
 
+	// Repositories name remote locations by alias, so that
+	// the resources, components and patches entries of the
+	// kustomization can read alias:subpath, e.g.
+	// infra:base/rbac.  Aliases are local to the file that
+	// declares them; its bases and components don't see
+	// them.
+	Repositories map[string]Repository `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+
 	// Resources specifies relative paths to files holding YAML representations
 	// of kubernetes API objects, or specifications of other kustomizations
 	// via relative paths, absolute paths, or URLs.
+	// The entry StdinResourcesPath stands for the resources read on stdin.
 	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
 
 	// Components specifies relative paths to specifications of other Components
@@ -104,6 +167,11 @@ type Kustomization struct {
 	// be specified in the Resources field instead.
 	Bases []string `json:"bases,omitempty" yaml:"bases,omitempty"`
 
+	// MultiInstance specifies bases built several times,
+	// e.g. once per tenant, each instance with its own name
+	// prefix, namespace and patches.
+	MultiInstance []MultiInstance `json:"multiInstance,omitempty" yaml:"multiInstance,omitempty"`
+
 	//
 	// Generators (operators that create operands)
 	//
@@ -145,6 +213,25 @@ type Kustomization struct {
 	// Inventory appends an object that contains the record
 	// of all other objects, which can be used in apply, prune and delete
 	Inventory *Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
+
+	// IgnorePatterns, in the syntax of a .krmignore file, name
+	// the files that generator file globs and the loading of
+	// remote directories lacking a kustomization skip.  They
+	// apply as though they ended the .krmignore file, if any,
+	// beside the kustomization file.
+	IgnorePatterns []string `json:"ignorePatterns,omitempty" yaml:"ignorePatterns,omitempty"`
+
+	// SortOptions order the output of the build.  Only
+	// those of the top-level kustomization count, and they
+	// take precedence over the build's options.
+	SortOptions *SortOptions `json:"sortOptions,omitempty" yaml:"sortOptions,omitempty"`
+
+	// UnknownFields holds the top level fields of a
+	// kustomization file that this version doesn't know,
+	// e.g. ones added by a newer kustomize, as read by
+	// UnmarshalPreservingUnknownFields, so that they're
+	// written back when the kustomization is marshalled.
+	UnknownFields map[string]interface{} `json:"-" yaml:"-"`
 }
 
 // FixKustomizationPostUnmarshalling fixes things
@@ -188,6 +275,23 @@ func (k *Kustomization) EnforceFields() []string {
 	if k.APIVersion != "" && k.APIVersion != requiredVersion {
 		errs = append(errs, "apiVersion for "+k.Kind+" should be "+requiredVersion)
 	}
+	if k.SortOptions != nil {
+		switch k.SortOptions.Order {
+		case "", LegacySortOrder, FIFOSortOrder, PreserveFileOrderSortOrder:
+		default:
+			errs = append(errs, "sortOptions.order should be "+
+				string(LegacySortOrder)+", "+string(FIFOSortOrder)+
+				" or "+string(PreserveFileOrderSortOrder))
+		}
+	}
+	if k.PatchOptions != nil {
+		switch k.PatchOptions.Order {
+		case "", ListPatchOrder, DependencyPatchOrder:
+		default:
+			errs = append(errs, "patchOptions.order should be "+
+				string(ListPatchOrder)+" or "+string(DependencyPatchOrder))
+		}
+	}
 	return errs
 }
 
@@ -104,6 +104,22 @@ func TestEnforceFields_InvalidVersion(t *testing.T) {
 	}
 }
 
+func TestEnforceFields_InvalidSortOrder(t *testing.T) {
+	k := Kustomization{
+		SortOptions: &SortOptions{Order: "byName"},
+	}
+
+	errs := k.EnforceFields()
+	if len(errs) != 1 {
+		t.Fatalf("number of errors should be 1 but got: %v", errs)
+	}
+
+	expected := "sortOptions.order should be legacy, fifo or preserveFileOrder"
+	if errs[0] != expected {
+		t.Fatalf("error should be %v but got: %v", expected, errs[0])
+	}
+}
+
 func TestEnforceFields_ComponentKind(t *testing.T) {
 	k := Kustomization{
 		TypeMeta: TypeMeta{
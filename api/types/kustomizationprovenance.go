@@ -0,0 +1,18 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// KustomizationProvenance records which kustomization
+// files contributed the entries of a kustomization made
+// by flattening a kustomization graph into one.
+//
+// Keys name entries as a field, a list item or a map
+// key, e.g. namespace, resources[2] or commonLabels.app.
+// Values list kustomization file paths, relative to the
+// root of the flattened kustomization.  There's one path
+// per entry, naming the file whose value won, except for
+// namePrefix, nameSuffix, namespacePrefix and
+// namespaceSuffix, whose values are composed from every
+// layer that sets them; those are listed innermost first.
+type KustomizationProvenance map[string][]string
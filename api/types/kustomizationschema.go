@@ -0,0 +1,395 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// KustomizationSchemaVersion is the JSON Schema draft the
+// schema returned by KustomizationSchema follows.
+const KustomizationSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// schemaField holds what the Go types don't say about a
+// field in the schema.
+type schemaField struct {
+	description string
+	enum        []interface{}
+}
+
+// schemaFields describe and constrain the fields of the
+// schema, keyed by the name of the struct and the json
+// name of the field.  Every field of Kustomization has a
+// description, and every field whose value this version
+// checks against a list has an enum.
+var schemaFields = map[string]schemaField{
+	"Kustomization.apiVersion": {
+		description: "The version of the kustomization format; " +
+			KustomizationVersion + " for a Kustomization, " +
+			ComponentVersion + " for a Component.",
+		enum: []interface{}{KustomizationVersion, ComponentVersion},
+	},
+	"Kustomization.kind": {
+		description: "Kustomization, the default, or Component.",
+		enum:        []interface{}{KustomizationKind, ComponentKind},
+	},
+	"Kustomization.metadata": {
+		description: "The metadata of the kustomization.",
+	},
+	"Kustomization.requires": {
+		description: "The capabilities the build needs to have " +
+			"for the kustomization, e.g. helm.",
+	},
+	"Kustomization.namePrefix": {
+		description: "Prefixed to the names of all resources, " +
+			"including generated ones.",
+	},
+	"Kustomization.nameSuffix": {
+		description: "Suffixed to the names of all resources, " +
+			"including generated ones.",
+	},
+	"Kustomization.namespace": {
+		description: "The namespace to give all objects.",
+	},
+	"Kustomization.namespacePrefix": {
+		description: "Prefixed to the names of the Namespaces " +
+			"in the build, wherever they're named.",
+	},
+	"Kustomization.namespaceSuffix": {
+		description: "Suffixed to the names of the Namespaces " +
+			"in the build, wherever they're named.",
+	},
+	"Kustomization.namespacePrefixSuffixOptions": {
+		description: "Options of namespacePrefix and namespaceSuffix.",
+	},
+	"Kustomization.enforceClusterScopedOnly": {
+		description: "Fail the build if any resource is namespaced, " +
+			"or any kustomization sets a namespace.",
+	},
+	"Kustomization.commonLabels": {
+		description: "Labels to add to all objects and selectors.",
+	},
+	"Kustomization.commonAnnotations": {
+		description: "Annotations to add to all objects.",
+	},
+	"Kustomization.removeCommonLabels": {
+		description: "Keys of the commonLabels of the bases " +
+			"and components to remove where those put them.",
+	},
+	"Kustomization.removeCommonAnnotations": {
+		description: "Keys of the commonAnnotations of the bases " +
+			"and components to remove where those put them.",
+	},
+	"Kustomization.metadataValidation": {
+		description: "How commonLabels and commonAnnotations are checked.",
+	},
+	"Kustomization.patchesStrategicMerge": {
+		description: "Strategic merge patches, as paths to " +
+			"files holding them or inline.",
+	},
+	"Kustomization.patchesJson6902": {
+		description: "JSON patches (RFC 6902) and their targets.",
+	},
+	"Kustomization.patches": {
+		description: "Strategic merge or JSON patches, each " +
+			"applied to the resources its target selects.",
+	},
+	"Kustomization.patchOptions": {
+		description: "How the patches apply.",
+	},
+	"Kustomization.images": {
+		description: "New names, tags or digests of images.",
+	},
+	"Kustomization.replicas": {
+		description: "New replica counts of resources.",
+	},
+	"Kustomization.vars": {
+		description: "Values of fields to substitute for " +
+			"$(NAME) in the resources.",
+	},
+	"Kustomization.repositories": {
+		description: "Aliases of remote locations, for entries " +
+			"of resources, components and patches to use " +
+			"as alias:subpath.",
+	},
+	"Kustomization.resources": {
+		description: "Paths or URLs of files of resources " +
+			"and of other kustomizations.",
+	},
+	"Kustomization.components": {
+		description: "Paths or URLs of Components.",
+	},
+	"Kustomization.crds": {
+		description: "Paths of Custom Resource Definition files.",
+	},
+	"Kustomization.bases": {
+		description: "Deprecated; list bases in resources instead.",
+	},
+	"Kustomization.multiInstance": {
+		description: "Bases built several times, each instance " +
+			"with its own name prefix, namespace and patches.",
+	},
+	"Kustomization.configMapGenerator": {
+		description: "ConfigMaps to generate, one per entry.",
+	},
+	"Kustomization.secretGenerator": {
+		description: "Secrets to generate, one per entry.",
+	},
+	"Kustomization.helmChartInflationGenerator": {
+		description: "Helm charts to render into resources.",
+	},
+	"Kustomization.generatorOptions": {
+		description: "Options of all the ConfigMap and Secret generators.",
+	},
+	"Kustomization.configurations": {
+		description: "Paths of transformer configuration files.",
+	},
+	"Kustomization.generators": {
+		description: "Paths of generator configurations.",
+	},
+	"Kustomization.transformers": {
+		description: "Paths of transformer configurations.",
+	},
+	"Kustomization.validators": {
+		description: "Paths of validator configurations.",
+	},
+	"Kustomization.inventory": {
+		description: "An object recording all the others, " +
+			"for apply, prune and delete.",
+	},
+	"Kustomization.ignorePatterns": {
+		description: "Patterns, in .krmignore syntax, of files " +
+			"for globs and remote loading to skip.",
+	},
+	"Kustomization.sortOptions": {
+		description: "The order of the output of the build.",
+	},
+	"SortOptions.order": {
+		enum: []interface{}{
+			LegacySortOrder, FIFOSortOrder, PreserveFileOrderSortOrder},
+	},
+	"PatchOptions.order": {
+		enum: []interface{}{ListPatchOrder, DependencyPatchOrder},
+	},
+	"GeneratorArgs.behavior": {
+		enum: []interface{}{"create", "replace", "merge"},
+	},
+	"FileOptions.newlineNormalization": {
+		enum: []interface{}{
+			NoNewlineNormalization, LfNewlineNormalization,
+			CrlfNewlineNormalization},
+	},
+}
+
+// schemaTypes are the schemas of the types that unmarshal
+// themselves, as reflection can't tell what they accept.
+var schemaTypes = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(TrailingNewline("")): {
+		"enum": []interface{}{true, false, string(KeepTrailingNewline)},
+	},
+}
+
+// KustomizationSchema returns a JSON Schema of the
+// kustomization file format that this version reads,
+// e.g. for editors to validate kustomization files.  It's
+// generated from the Kustomization type, so it lists the
+// same fields as Kustomization.Unmarshal accepts, though
+// it matches their names exactly, while the decoder also
+// accepts them in other cases, e.g. fieldpath for fieldPath.
+func KustomizationSchema() ([]byte, error) {
+	g := schemaGenerator{
+		definitions: make(map[string]interface{}),
+		types:       make(map[string]reflect.Type),
+	}
+	schema, err := g.structSchema(reflect.TypeOf(Kustomization{}))
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = KustomizationSchemaVersion
+	schema["title"] = KustomizationKind
+	schema["definitions"] = g.definitions
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type schemaGenerator struct {
+	// definitions holds the schemas of the structs other
+	// than Kustomization, keyed by type name.
+	definitions map[string]interface{}
+	// types holds the type of each definition, to detect
+	// two types of one name.
+	types map[string]reflect.Type
+}
+
+// stringOrStructTypes are the structs that unmarshal
+// themselves from a string too, e.g. a name.
+var stringOrStructTypes = map[reflect.Type]bool{
+	reflect.TypeOf(ServiceAccountAttachment{}): true,
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) (map[string]interface{}, error) {
+	if s, found := schemaTypes[t]; found {
+		return s, nil
+	}
+	if stringOrStructTypes[t] {
+		s, err := g.definition(t)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"}, s},
+		}, nil
+	}
+	if t.Implements(jsonUnmarshaler) ||
+		reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+		return nil, fmt.Errorf(
+			"type %s unmarshals itself, but has no schema", t)
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json reads []byte as base64.
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map %s doesn't have string keys", t)
+		}
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return g.definition(t)
+	}
+	return nil, fmt.Errorf("type %s has no schema", t)
+}
+
+// definition returns a reference to the definition of the
+// given struct, adding it if it's the first.
+func (g *schemaGenerator) definition(t reflect.Type) (map[string]interface{}, error) {
+	ref := map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	if other, found := g.types[t.Name()]; found {
+		if other != t {
+			return nil, fmt.Errorf(
+				"types %s and %s have one definition name", other, t)
+		}
+		return ref, nil
+	}
+	g.types[t.Name()] = t
+	s, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	g.definitions[t.Name()] = s
+	return ref, nil
+}
+
+// structSchema returns the schema of an object holding the
+// fields of the given struct.  As Kustomization.Unmarshal
+// disallows unknown fields, no others are allowed.
+func (g *schemaGenerator) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	if err := g.addProperties(properties, t, t.Name()); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, nil
+}
+
+// addProperties adds the schemas of the fields of the given
+// struct to properties, with the fields of embedded structs
+// without a json name, e.g. TypeMeta, added in place as
+// encoding/json reads them.  The registry is consulted by
+// the name of the owner, the struct the properties are
+// of, then by that of the struct declaring the field, so
+// that e.g. GeneratorArgs.behavior applies to the
+// ConfigMapArgs and SecretArgs embedding it.
+func (g *schemaGenerator) addProperties(
+	properties map[string]interface{}, t reflect.Type, owner string) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.addProperties(properties, ft, owner); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s, err := g.schema(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %v", owner, name, err)
+		}
+		if field, found := schemaFields[owner+"."+name]; found {
+			s = withField(s, field)
+		} else if field, found := schemaFields[t.Name()+"."+name]; found {
+			s = withField(s, field)
+		}
+		properties[name] = s
+	}
+	return nil
+}
+
+// withField returns a copy of the given schema with the
+// description and enum of the given field.  A reference is
+// wrapped, as the keywords beside a $ref are ignored.
+func withField(
+	s map[string]interface{}, field schemaField) map[string]interface{} {
+	result := make(map[string]interface{}, len(s)+2)
+	if _, found := s["$ref"]; found {
+		result["allOf"] = []interface{}{s}
+	} else {
+		for k, v := range s {
+			result[k] = v
+		}
+	}
+	if field.description != "" {
+		result["description"] = field.description
+	}
+	if field.enum != nil {
+		result["enum"] = field.enum
+	}
+	return result
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
@@ -0,0 +1,212 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func loadKustomizationSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := KustomizationSchema()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var schema map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(data, &schema)) {
+		t.FailNow()
+	}
+	return schema
+}
+
+// validate returns the problems of the given value against
+// the given schema, using the keywords KustomizationSchema
+// writes.
+func validate(root, schema map[string]interface{},
+	path string, value interface{}) (problems []string) {
+	if ref, found := schema["$ref"]; found {
+		name := strings.TrimPrefix(ref.(string), "#/definitions/")
+		definitions := root["definitions"].(map[string]interface{})
+		return validate(root, definitions[name].(map[string]interface{}), path, value)
+	}
+	if allOf, found := schema["allOf"]; found {
+		for _, s := range allOf.([]interface{}) {
+			problems = append(problems,
+				validate(root, s.(map[string]interface{}), path, value)...)
+		}
+	}
+	if enum, found := schema["enum"]; found {
+		match := false
+		for _, v := range enum.([]interface{}) {
+			match = match || reflect.DeepEqual(v, value)
+		}
+		if !match {
+			return append(problems, fmt.Sprintf("%s: %v isn't one of %v", path, value, enum))
+		}
+	}
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return append(problems, path+": isn't a string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(problems, path+": isn't a boolean")
+		}
+	case "integer":
+		if f, ok := value.(float64); !ok || f != float64(int64(f)) {
+			return append(problems, path+": isn't an integer")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(problems, path+": isn't an array")
+		}
+		for i, item := range items {
+			problems = append(problems, validate(root,
+				schema["items"].(map[string]interface{}),
+				fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(problems, path+": isn't an object")
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for k, v := range object {
+			s, found := properties[k]
+			if !found {
+				s = schema["additionalProperties"]
+			}
+			switch s := s.(type) {
+			case bool:
+				if !s {
+					problems = append(problems, path+"."+k+": isn't allowed")
+				}
+			case map[string]interface{}:
+				problems = append(problems, validate(root, s, path+"."+k, v)...)
+			}
+		}
+	}
+	return problems
+}
+
+func validateKustomization(
+	t *testing.T, schema map[string]interface{}, y []byte) []string {
+	t.Helper()
+	j, err := yaml.YAMLToJSON(y)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var value interface{}
+	if !assert.NoError(t, json.Unmarshal(j, &value)) {
+		t.FailNow()
+	}
+	return validate(schema, schema, "", value)
+}
+
+func TestKustomizationSchemaAcceptsTestdata(t *testing.T) {
+	schema := loadKustomizationSchema(t)
+	files, err := filepath.Glob("testdata/kustomizations/*.yaml")
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, files) {
+		t.FailNow()
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			y, err := ioutil.ReadFile(f)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var k Kustomization
+			assert.NoError(t, k.Unmarshal(y))
+			assert.Empty(t, validateKustomization(t, schema, y))
+		})
+	}
+}
+
+func TestKustomizationSchemaRejects(t *testing.T) {
+	schema := loadKustomizationSchema(t)
+	testCases := map[string]struct {
+		y        string
+		decoding bool
+	}{
+		"unknownField": {
+			y:        "namePrefix: a-\nnameprefixes: b-\n",
+			decoding: true,
+		},
+		"unknownNestedField": {
+			y:        "patches:\n- path: p.yaml\n  targets: {}\n",
+			decoding: true,
+		},
+		"wrongType": {
+			y:        "resources: a.yaml\n",
+			decoding: true,
+		},
+		"wrongEnum": {
+			y: "sortOptions:\n  order: random\n",
+		},
+		"wrongEmbeddedEnum": {
+			y: "configMapGenerator:\n- name: a\n  behavior: update\n",
+		},
+		"wrongTrailingNewline": {
+			y:        "secretGenerator:\n- fileOptions:\n    ensureTrailingNewline: 3\n",
+			decoding: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			assert.NotEmpty(t, validateKustomization(t, schema, []byte(tc.y)))
+			var k Kustomization
+			if tc.decoding {
+				assert.Error(t, k.Unmarshal([]byte(tc.y)))
+			}
+		})
+	}
+}
+
+func TestKustomizationSchemaDescribesEveryField(t *testing.T) {
+	schema := loadKustomizationSchema(t)
+	properties := schema["properties"].(map[string]interface{})
+	for n, p := range properties {
+		assert.NotEmpty(t, p.(map[string]interface{})["description"], n)
+	}
+	assert.Equal(t, len(properties), reflect.TypeOf(Kustomization{}).NumField()-
+		2 /* TypeMeta and UnknownFields */ +
+		reflect.TypeOf(TypeMeta{}).NumField())
+}
+
+// Every entry of the registry names a field in the schema.
+func TestKustomizationSchemaUsesEveryField(t *testing.T) {
+	schema := loadKustomizationSchema(t)
+	objects := []interface{}{schema}
+	for _, d := range schema["definitions"].(map[string]interface{}) {
+		objects = append(objects, d)
+	}
+	for key, field := range schemaFields {
+		name := key[strings.Index(key, ".")+1:]
+		used := false
+		for _, o := range objects {
+			properties := o.(map[string]interface{})["properties"]
+			p, found := properties.(map[string]interface{})[name].(map[string]interface{})
+			if !found {
+				continue
+			}
+			enum, _ := p["enum"].([]interface{})
+			if (field.description == "" || p["description"] == field.description) &&
+				len(enum) == len(field.enum) {
+				used = true
+			}
+		}
+		assert.True(t, used, key)
+	}
+}
@@ -28,4 +28,8 @@ type KvPairSources struct {
 	// or npm ".env" file or a ".ini" file
 	// (wikipedia.org/wiki/INI_file)
 	EnvSources []string `json:"envs,omitempty" yaml:"envs,omitempty"`
+
+	// FileOptions, if set, normalize the text files of
+	// FileSources, and say which of them are binary.
+	FileOptions *FileOptions `json:"fileOptions,omitempty" yaml:"fileOptions,omitempty"`
 }
@@ -0,0 +1,21 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// MetadataValidation modify how the labels and annotations
+// a kustomization adds are checked against the syntax and
+// length rules of Kubernetes.  The labels and annotations
+// it adds are always checked, so that a build fails rather
+// than yielding resources the API server refuses.
+type MetadataValidation struct {
+	// Strict, if true, checks all the labels and annotations
+	// of the resources the kustomization adds some to, not
+	// just those it adds.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+
+	// IgnoreKeys are label and annotation keys never
+	// checked, e.g. for clusters whose admission webhooks
+	// relax the rules.
+	IgnoreKeys []string `json:"ignoreKeys,omitempty" yaml:"ignoreKeys,omitempty"`
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// MultiInstance builds several instances of one base, e.g.
+// one per tenant, each customized on its own before its
+// resources join those of the kustomization.
+type MultiInstance struct {
+	// Resource is the base, as in the resources field: a
+	// relative path, absolute path or URL of a kustomization.
+	Resource string `json:"resource,omitempty" yaml:"resource,omitempty"`
+
+	// Instances are the copies of the base to build.  No
+	// two may have the same namePrefix and namespace.
+	Instances []Instance `json:"instances,omitempty" yaml:"instances,omitempty"`
+}
+
+// Instance customizes one copy of a MultiInstance's base.
+// Name references in the copy are fixed among its own
+// resources, so that each instance refers to its own
+// ConfigMaps, Services, etc.
+type Instance struct {
+	// NamePrefix is prepended to the names of the
+	// instance's resources.
+	NamePrefix string `json:"namePrefix,omitempty" yaml:"namePrefix,omitempty"`
+
+	// Namespace is the namespace of the instance's
+	// namespaced resources.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Patches apply to the instance's resources only.
+	// Paths are relative to the kustomization.
+	Patches []Patch `json:"patches,omitempty" yaml:"patches,omitempty"`
+}
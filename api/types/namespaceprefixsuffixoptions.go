@@ -0,0 +1,15 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// NamespacePrefixSuffixOptions modify how a kustomization's
+// namespacePrefix and namespaceSuffix are applied.
+type NamespacePrefixSuffixOptions struct {
+	// IncludeExternal, if true, adds the prefix and suffix
+	// to the names of namespaces the build doesn't create
+	// as well, e.g. to those of Namespaces made elsewhere
+	// for each tenant.  Resources without a namespace are
+	// still left without one.
+	IncludeExternal bool `json:"includeExternal,omitempty" yaml:"includeExternal,omitempty"`
+}
@@ -7,4 +7,7 @@ package types
 type Pair struct {
 	Key   string
 	Value string
+	// Binary is true if the value is the content of a
+	// binary file, which a ConfigMap holds in binaryData.
+	Binary bool
 }
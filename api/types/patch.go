@@ -3,6 +3,8 @@
 
 package types
 
+import "reflect"
+
 // Patch represent either a Strategic Merge Patch or a JSON patch
 // and its targets.
 // The content of the patch can either be from a file
@@ -16,13 +18,29 @@ type Patch struct {
 
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Options is a list of options for the patch.
+	// The option "strict" makes it an error for Target to
+	// select no resources.  The option "exactGroup"
+	// compares the Target's group exactly, without treating
+	// "core" as the empty group.  The option "strictGvk"
+	// compares the Target's group, version and kind
+	// exactly, without treating empty ones as wildcards
+	// (see Selector), so the Target must give a version and
+	// kind.  The option "allowOverwrite" turns off the
+	// warnings that a label, annotation, name or namespace
+	// the patch sets is then overwritten by the
+	// commonLabels, commonAnnotations, namePrefix,
+	// nameSuffix or namespace of the kustomization.
+	Options map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 // Equals return true if p equals o.
 func (p *Patch) Equals(o Patch) bool {
 	targetEqual := (p.Target == o.Target) ||
-		(p.Target != nil && o.Target != nil && *p.Target == *o.Target)
+		(p.Target != nil && o.Target != nil && reflect.DeepEqual(*p.Target, *o.Target))
 	return p.Path == o.Path &&
 		p.Patch == o.Patch &&
-		targetEqual
+		targetEqual &&
+		reflect.DeepEqual(p.Options, o.Options)
 }
@@ -115,6 +115,17 @@ func TestPatchEquals(t *testing.T) {
 			},
 			expect: false,
 		},
+		{
+			name: "different options",
+			patch1: Patch{
+				Path:    "foo",
+				Options: map[string]bool{"strict": true},
+			},
+			patch2: Patch{
+				Path: "foo",
+			},
+			expect: false,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -0,0 +1,34 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// PatchOptions say how the entries of a kustomization's
+// patches field apply.
+type PatchOptions struct {
+	// Order is the order the entries apply in.  If empty,
+	// it's ListPatchOrder.
+	Order PatchOrder `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// PatchOrder names an order for the entries of a
+// kustomization's patches field to apply in.
+type PatchOrder string
+
+const (
+	// Apply the entries strictly in list order, each
+	// selecting its targets among the resources as the
+	// entries before it left them.  An entry addressing a
+	// resource by a name an entry after it gives the
+	// resource selects nothing.
+	ListPatchOrder PatchOrder = "list"
+
+	// Select the targets of all the entries among the
+	// resources as they are before any entry applies, so
+	// that entries find resources that earlier entries
+	// rename, then apply the entries in list order.  An
+	// entry selecting nothing then is applied after the
+	// others, selecting its targets among the resources as
+	// the others left them, with a warning.
+	DependencyPatchOrder PatchOrder = "dependency"
+)
@@ -32,4 +32,13 @@ type PluginConfig struct {
 
 	// FnpLoadingOptions sets the way function-based plugin behaviors.
 	FnpLoadingOptions FnPluginLoadingOptions
+
+	// ExecPluginEnv limits the environment variables that
+	// exec plugins inherit, e.g. to keep CI credentials
+	// from third party plugins.
+	ExecPluginEnv ExecPluginEnv
+
+	// Limits bound the resources that exec plugins, and
+	// container and exec functions, may use.
+	Limits PluginLimits
 }
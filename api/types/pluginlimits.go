@@ -0,0 +1,54 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "time"
+
+const (
+	// DefaultPluginTimeout is the default of PluginLimits.Timeout.
+	DefaultPluginTimeout = 10 * time.Minute
+
+	// DefaultMaxPluginOutputBytes is the default of
+	// PluginLimits.MaxOutputBytes.
+	DefaultMaxPluginOutputBytes = DefaultMaxFileSize
+)
+
+// PluginLimits bound the resources that each run of an
+// exec plugin, or of a container or exec function, may
+// use, so that a plugin that hangs, or writes without end,
+// fails the build instead.  A plugin that exceeds them is
+// killed, with any processes it started.  A zero field
+// takes its default value; a negative one means no limit.
+type PluginLimits struct {
+	// Timeout is the most wall-clock time a run may take.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// MaxOutputBytes is the most bytes a run may write
+	// to stdout, and the most it may write to stderr.
+	MaxOutputBytes int64 `json:"maxOutputBytes,omitempty" yaml:"maxOutputBytes,omitempty"`
+
+	// Memory, if not empty, is the most memory a container
+	// function may use, in the syntax of the docker run
+	// --memory flag, e.g. 512m.  It has no default.
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// Effective returns the limits with the defaults in
+// place of any zero fields, and zero, meaning no limit,
+// in place of any negative ones.
+func (l PluginLimits) Effective() PluginLimits {
+	switch {
+	case l.Timeout == 0:
+		l.Timeout = DefaultPluginTimeout
+	case l.Timeout < 0:
+		l.Timeout = 0
+	}
+	switch {
+	case l.MaxOutputBytes == 0:
+		l.MaxOutputBytes = DefaultMaxPluginOutputBytes
+	case l.MaxOutputBytes < 0:
+		l.MaxOutputBytes = 0
+	}
+	return l
+}
@@ -14,6 +14,8 @@ type Replacement struct {
 // It can from two different kinds of sources
 //  - from a field of one resource
 //  - from a string
+// A fieldref of the form dataKey:<key> names a key of the
+// data of a ConfigMap, e.g. dataKey:nginx.conf.
 type ReplSource struct {
 	ObjRef   *Target `json:"objref,omitempty" yaml:"objref,omitempty"`
 	FieldRef string  `json:"fieldref,omitempty" yaml:"fiedldref,omitempty"`
@@ -21,6 +23,7 @@ type ReplSource struct {
 }
 
 // ReplTarget defines where a substitution is to.
+// As in ReplSource, fieldrefs may have the form dataKey:<key>.
 type ReplTarget struct {
 	ObjRef    *Selector `json:"objref,omitempty" yaml:"objref,omitempty"`
 	FieldRefs []string  `json:"fieldrefs,omitempty" yaml:"fieldrefs,omitempty"`
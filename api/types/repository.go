@@ -0,0 +1,17 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Repository is a remote location that a kustomization
+// names under an alias in its repositories field, so that
+// the ref of many entries can be bumped in one place.
+type Repository struct {
+	// URL is that of the repository, as a resources entry
+	// would give it but without a ref, e.g.
+	// https://github.com/someOrg/someRepo.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Ref is the branch, tag or commit to use, if any.
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Names of the builtin capabilities of a build.
+const (
+	// The build may inflate helm charts.
+	CapabilityHelm = "helm"
+
+	// Plugins other than the builtin ones may run.
+	CapabilityPlugins = "plugins"
+)
+
+// Requirements are the capabilities a kustomization needs
+// the build to have, e.g. because it inflates a helm chart.
+// A build lacking one fails as soon as it reaches the
+// kustomization, naming it and what's missing, rather than
+// deep in the build.
+type Requirements struct {
+	// Helm, if true, requires CapabilityHelm.
+	Helm bool `json:"helm,omitempty" yaml:"helm,omitempty"`
+
+	// Plugins, if true, requires CapabilityPlugins.
+	Plugins bool `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+
+	// MinApiVersion, if not empty, is the lowest version of
+	// kustomize, e.g. "v4.1.0", that builds the kustomization
+	// as intended.  It's not checked by builds whose version
+	// is unknown, e.g. those of development binaries.
+	MinApiVersion string `json:"minApiVersion,omitempty" yaml:"minApiVersion,omitempty"`
+
+	// Capabilities are the names of other capabilities
+	// required, e.g. those a platform running kustomize as a
+	// library defines for its own prerequisites.
+	Capabilities []string `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+}
@@ -3,6 +3,8 @@
 
 package types
 
+import "encoding/json"
+
 // SecretArgs contains the metadata of how to generate a secret.
 type SecretArgs struct {
 	// GeneratorArgs for the secret.
@@ -16,4 +18,39 @@ type SecretArgs struct {
 	// If type is "kubernetes.io/tls", then "literals" or "files" must have exactly two
 	// keys: "tls.key" and "tls.crt"
 	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// AttachToServiceAccounts lists the ServiceAccounts of
+	// the build that use the secret, e.g. a pull secret of
+	// type kubernetes.io/dockerconfigjson, to pull images.
+	// The secret's name, with any hash, is added to their
+	// imagePullSecrets, if not already there.
+	AttachToServiceAccounts []ServiceAccountAttachment `json:"attachToServiceAccounts,omitempty" yaml:"attachToServiceAccounts,omitempty"`
+}
+
+// ServiceAccountAttachment selects the ServiceAccounts, in
+// the namespace of a generated secret, that use it.  It may
+// be written as just the name of a ServiceAccount.
+type ServiceAccountAttachment struct {
+	// Name is the name of a ServiceAccount.  It's an
+	// error if the build has none of that name, unless
+	// Optional is true.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Selector selects ServiceAccounts, e.g. by label.
+	// Selecting none isn't an error.
+	Selector *Selector `json:"selector,omitempty" yaml:"selector,omitempty"`
+
+	// Optional, if true, lets the ServiceAccount of Name
+	// be missing from the build.
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+// UnmarshalJSON accepts the name of a ServiceAccount, as
+// well as the fields of a ServiceAccountAttachment.
+func (a *ServiceAccountAttachment) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Name); err == nil {
+		return nil
+	}
+	type plain ServiceAccountAttachment
+	return json.Unmarshal(data, (*plain)(a))
 }
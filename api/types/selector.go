@@ -4,7 +4,9 @@
 package types
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 
 	"sigs.k8s.io/kustomize/api/resid"
 )
@@ -12,6 +14,18 @@ import (
 // Selector specifies a set of resources.
 // Any resource that matches intersection of all conditions
 // is included in this set.
+//
+// The group, version and kind are each optional; an empty
+// one is a wildcard.  So a selector that gives only a kind
+// selects that kind in every group and version, and one
+// that omits only the version selects every version, e.g.
+// a Deployment left at apps/v1beta2 as well as apps/v1.
+// The group "core" is the same as the empty group, when
+// given.  These rules are the same for Select, and for
+// the targets of strategic merge and JSON 6902 patches,
+// unless matching is made exact; see the patch options.
+// A selector that selects two resources differing only
+// by version is an error, as it's rarely what's meant.
 type Selector struct {
 	resid.Gvk `json:",inline,omitempty" yaml:",inline,omitempty"`
 	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
@@ -26,6 +40,37 @@ type Selector struct {
 	// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#api
 	// It matches with the resource labels.
 	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+
+	// MatchOriginalIds, if true, matches the group, version,
+	// kind, name and namespace against those the resource
+	// had originally, before any prefix, suffix, namespace
+	// or apiVersion change, e.g. to patch a base resource by
+	// the name it has in the base, in an overlay that
+	// renames it.  The label and annotation selectors still
+	// match the current labels and annotations.
+	MatchOriginalIds bool `json:"matchOriginalIds,omitempty" yaml:"matchOriginalIds,omitempty"`
+
+	// FieldSelectors match the values of fields of the
+	// resource, e.g. spec.type of Services of type
+	// LoadBalancer.  A resource must match all of them.
+	FieldSelectors []FieldValueSelector `json:"fieldSelectors,omitempty" yaml:"fieldSelectors,omitempty"`
+}
+
+// FieldValueSelector matches the resources whose field
+// at Path has Value.
+type FieldValueSelector struct {
+	// Path is the path of the field, in the syntax of
+	// WNode.GetFieldValue, e.g. spec.type or
+	// spec.template.spec.containers[name=app].image.  A
+	// resource lacking the field doesn't match.  With a
+	// wildcard, e.g. spec.ports[*].protocol, it's enough
+	// that one of the fields has the value.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Value is a scalar.  Numbers and booleans are compared
+	// as such, not as strings, so 3 matches a field of 3 or
+	// 3.0, and true one of true.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
 // SelectorRegex is a Selector with regex in GVK
@@ -33,6 +78,7 @@ type Selector struct {
 // is included in this set.
 type SelectorRegex struct {
 	selector       *Selector
+	opts           resid.GvkMatchOptions
 	groupRegex     *regexp.Regexp
 	versionRegex   *regexp.Regexp
 	kindRegex      *regexp.Regexp
@@ -43,28 +89,37 @@ type SelectorRegex struct {
 // NewSelectorRegex returns a pointer to a new SelectorRegex
 // which uses the same condition as s.
 func NewSelectorRegex(s *Selector) (*SelectorRegex, error) {
+	return NewSelectorRegexWithOptions(s, resid.GvkMatchOptions{})
+}
+
+// NewSelectorRegexWithOptions is like NewSelectorRegex, but
+// matches Gvks per the given options.  The error, if any,
+// reports every malformed regex in the selector.
+func NewSelectorRegexWithOptions(
+	s *Selector, opts resid.GvkMatchOptions) (*SelectorRegex, error) {
 	sr := new(SelectorRegex)
-	var err error
 	sr.selector = s
-	sr.groupRegex, err = regexp.Compile(anchorRegex(s.Gvk.Group))
-	if err != nil {
-		return nil, err
-	}
-	sr.versionRegex, err = regexp.Compile(anchorRegex(s.Gvk.Version))
-	if err != nil {
-		return nil, err
-	}
-	sr.kindRegex, err = regexp.Compile(anchorRegex(s.Gvk.Kind))
-	if err != nil {
-		return nil, err
+	sr.opts = opts
+	var problems []string
+	compile := func(field, pattern string) *regexp.Regexp {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			problems = append(problems,
+				fmt.Sprintf("bad %s regex: %v", field, err))
+		}
+		return r
 	}
-	sr.nameRegex, err = regexp.Compile(anchorRegex(s.Name))
-	if err != nil {
-		return nil, err
+	sr.groupRegex = compile("group", anchorRegex(s.Gvk.Group))
+	sr.versionRegex = compile("version", anchorRegex(s.Gvk.Version))
+	kindPattern := anchorRegex(s.Gvk.Kind)
+	if opts.CaseInsensitiveKind && kindPattern != "" {
+		kindPattern = "(?i)" + kindPattern
 	}
-	sr.namespaceRegex, err = regexp.Compile(anchorRegex(s.Namespace))
-	if err != nil {
-		return nil, err
+	sr.kindRegex = compile("kind", kindPattern)
+	sr.nameRegex = compile("name", anchorRegex(s.Name))
+	sr.namespaceRegex = compile("namespace", anchorRegex(s.Namespace))
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
 	}
 	return sr, nil
 }
@@ -77,9 +132,16 @@ func anchorRegex(pattern string) string {
 }
 
 // MatchGvk return true if gvk can be matched by s.
+// Unless the options are strict, a selector group of
+// resid.LegacyCoreGroup also matches the empty group,
+// and vice versa.  With the ExactGvk option, the group,
+// version and kind are compared as plain strings.
 func (s *SelectorRegex) MatchGvk(gvk resid.Gvk) bool {
+	if s.opts.ExactGvk {
+		return gvk.ExactlyEquals(s.selector.Gvk)
+	}
 	if len(s.selector.Gvk.Group) > 0 {
-		if !s.groupRegex.MatchString(gvk.Group) {
+		if !s.matchGroup(gvk.Group) {
 			return false
 		}
 	}
@@ -96,6 +158,16 @@ func (s *SelectorRegex) MatchGvk(gvk resid.Gvk) bool {
 	return true
 }
 
+func (s *SelectorRegex) matchGroup(g string) bool {
+	if s.groupRegex.MatchString(g) {
+		return true
+	}
+	if s.opts.Strict {
+		return false
+	}
+	return s.opts.GroupMatches(g, s.selector.Gvk.Group)
+}
+
 // MatchName returns true if the name in selector is
 // empty or the n can be matches by the name in selector
 func (s *SelectorRegex) MatchName(n string) bool {
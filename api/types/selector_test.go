@@ -117,6 +117,36 @@ func TestSelectorRegexMatchGvk(t *testing.T) {
 	}
 }
 
+func TestSelectorRegexMatchGvkWithOptions(t *testing.T) {
+	v1Service := resid.Gvk{Version: "v1", Kind: "Service"}
+	coreService := resid.Gvk{Group: "core", Version: "v1", Kind: "Service"}
+	testcases := []struct {
+		S        resid.Gvk
+		G        resid.Gvk
+		Opts     resid.GvkMatchOptions
+		Expected bool
+	}{
+		{resid.Gvk{Group: "core"}, v1Service, resid.GvkMatchOptions{}, true},
+		{resid.Gvk{Group: "core"}, v1Service, resid.GvkMatchOptions{Strict: true}, false},
+		{resid.Gvk{Group: "core"}, coreService, resid.GvkMatchOptions{Strict: true}, true},
+		{resid.Gvk{Group: "c.*"}, coreService, resid.GvkMatchOptions{}, true},
+		{resid.Gvk{Group: "c.*"}, v1Service, resid.GvkMatchOptions{}, false},
+		{resid.Gvk{Kind: "service"}, v1Service, resid.GvkMatchOptions{}, false},
+		{resid.Gvk{Kind: "service"}, v1Service, resid.GvkMatchOptions{CaseInsensitiveKind: true}, true},
+		{resid.Gvk{Kind: "serv.*"}, v1Service, resid.GvkMatchOptions{CaseInsensitiveKind: true}, true},
+	}
+	for _, tc := range testcases {
+		sr, err := NewSelectorRegexWithOptions(&Selector{Gvk: tc.S}, tc.Opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sr.MatchGvk(tc.G) != tc.Expected {
+			t.Fatalf("unexpected result for selector gvk %s and gvk %s with %+v",
+				tc.S.String(), tc.G.String(), tc.Opts)
+		}
+	}
+}
+
 func TestSelectorRegexMatchName(t *testing.T) {
 	testcases := []struct {
 		S        Selector
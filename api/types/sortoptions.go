@@ -0,0 +1,33 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// SortOptions say how the top-level kustomization of a
+// build orders its output.
+type SortOptions struct {
+	// Order is the order of the output.  If empty, the
+	// order is as the build's options say.
+	Order SortOrder `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// SortOrder names an order of the output of a build.
+type SortOrder string
+
+const (
+	// Sort by kind, so that e.g. Namespaces and CRDs
+	// come before the resources that need them.
+	LegacySortOrder SortOrder = "legacy"
+
+	// Keep the resources in the order the build left
+	// them in.
+	FIFOSortOrder SortOrder = "fifo"
+
+	// Keep the resources of each file together, in the
+	// order of their documents in the file, and the files
+	// in the order the kustomizations refer to them, bases
+	// and components in place.  The resources made by a
+	// generator are kept together, after the resources of
+	// the kustomization holding it.
+	PreserveFileOrderSortOrder SortOrder = "preserveFileOrder"
+)
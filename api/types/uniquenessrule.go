@@ -0,0 +1,90 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// UniquenessRule says that no two resources of a kind
+// may have the same values in some fields, e.g. that no
+// two Services may claim the same nodePort.
+//
+// The paths are like those of a FieldSpec, and go into
+// each element of the lists on the way, so a resource may
+// have many values for a path.  When there are several
+// paths, the values are taken together, those under the
+// same list element paired up, e.g. for an Ingress
+//
+// {
+//   kind: Ingress
+//   paths:
+//   - spec/rules/host
+//   - spec/rules/http/paths/path
+// }
+//
+// gives a host and path pair per path of each of its
+// rules.  A resource lacking any of the fields has no
+// values to compare.
+type UniquenessRule struct {
+	// Name names the rule in the problems reported.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// The group, version and kind of the resources to
+	// compare; empty ones are wildcards, as in a Selector.
+	resid.Gvk `json:",inline,omitempty" yaml:",inline,omitempty"`
+
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+
+	// Scope is namespace, the default, if resources in
+	// different namespaces may share values, or cluster
+	// if they may not.
+	Scope UniquenessScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
+// UniquenessScope is where the values a
+// UniquenessRule names must be unique.
+type UniquenessScope string
+
+const (
+	NamespaceUniquenessScope UniquenessScope = "namespace"
+	ClusterUniquenessScope   UniquenessScope = "cluster"
+)
+
+// Validate returns an error if the rule lacks a name
+// or paths, or has an unknown scope.
+func (r UniquenessRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("uniqueness rule for kind '%s' has no name", r.Kind)
+	}
+	if len(r.Paths) == 0 {
+		return fmt.Errorf("uniqueness rule '%s' has no paths", r.Name)
+	}
+	for i, p := range r.Paths {
+		if p == "" {
+			return fmt.Errorf(
+				"uniqueness rule '%s' has an empty paths[%d]", r.Name, i)
+		}
+	}
+	switch r.Scope {
+	case "", NamespaceUniquenessScope, ClusterUniquenessScope:
+	default:
+		return fmt.Errorf(
+			"uniqueness rule '%s' has scope '%s'; it must be %s or %s",
+			r.Name, r.Scope, NamespaceUniquenessScope, ClusterUniquenessScope)
+	}
+	return nil
+}
+
+// PathSlices returns the paths of the rule, each
+// split into fields as FieldSpec.PathSlice does.
+func (r UniquenessRule) PathSlices() [][]string {
+	var result [][]string
+	for _, p := range r.Paths {
+		result = append(result, FieldSpec{Path: p}.PathSlice())
+	}
+	return result
+}
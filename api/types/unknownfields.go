@@ -0,0 +1,109 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// knownFields holds the lowercased names of the top level
+// fields of a Kustomization; json decoding, and so Unmarshal,
+// matches field names without regard to case.
+var knownFields = func() map[string]bool {
+	result := make(map[string]bool)
+	addJSONFieldNames(reflect.TypeOf(Kustomization{}), result)
+	return result
+}()
+
+func addJSONFieldNames(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-":
+			continue
+		case f.Anonymous && name == "":
+			addJSONFieldNames(f.Type, names)
+			continue
+		case name == "":
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+}
+
+// UnmarshalPreservingUnknownFields is a lenient Unmarshal.
+// Rather than failing on top level fields it doesn't know,
+// it keeps them in UnknownFields, and returns their names,
+// sorted, e.g. for a warning.  Unknown fields nested in known
+// ones are still errors, as there's nowhere to keep them.
+func (k *Kustomization) UnmarshalPreservingUnknownFields(
+	y []byte) ([]string, error) {
+	j, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(j, &fields); err != nil {
+		return nil, err
+	}
+	unknown := make(map[string]interface{})
+	var names []string
+	for name, raw := range fields {
+		if knownFields[strings.ToLower(name)] {
+			continue
+		}
+		var v interface{}
+		if err = json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		unknown[name] = v
+		names = append(names, name)
+		delete(fields, name)
+	}
+	if j, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+	var nk Kustomization
+	if err = nk.Unmarshal(j); err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		nk.UnknownFields = unknown
+	}
+	*k = nk
+	sort.Strings(names)
+	return names, nil
+}
+
+// MarshalJSON marshals the kustomization's fields
+// and, alongside them, its UnknownFields, if any.
+func (k Kustomization) MarshalJSON() ([]byte, error) {
+	type plain Kustomization
+	j, err := json.Marshal(plain(k))
+	if err != nil || len(k.UnknownFields) == 0 {
+		return j, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(j, &fields); err != nil {
+		return nil, err
+	}
+	for name, v := range k.UnknownFields {
+		if knownFields[strings.ToLower(name)] {
+			// A known field always wins.
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = raw
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestUnmarshalPreservingUnknownFields(t *testing.T) {
+	y := []byte(`
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namePrefix: cat-
+futureField:
+  enabled: true
+resources:
+- foo
+anotherFutureField: [a, b]
+`)
+	var k Kustomization
+	unknown, err := k.UnmarshalPreservingUnknownFields(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"anotherFutureField", "futureField"}
+	if !reflect.DeepEqual(unknown, expected) {
+		t.Fatalf("expected unknown fields %v, but got %v", expected, unknown)
+	}
+	if k.NamePrefix != "cat-" || len(k.Resources) != 1 {
+		t.Fatalf("wrong unmarshal result: %v", k)
+	}
+
+	k.NameSuffix = "-dog"
+	out, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedOut := `anotherFutureField:
+- a
+- b
+apiVersion: kustomize.config.k8s.io/v1beta1
+futureField:
+  enabled: true
+kind: Kustomization
+namePrefix: cat-
+nameSuffix: -dog
+resources:
+- foo
+`
+	if string(out) != expectedOut {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expectedOut, out)
+	}
+
+	// The strict Unmarshal still rejects them.
+	if err = k.Unmarshal(out); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestUnmarshalPreservingUnknownFields_NestedUnknownField(t *testing.T) {
+	y := []byte(`
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+NamePrefix: cat-
+generatorOptions:
+  futureOption: true
+`)
+	var k Kustomization
+	_, err := k.UnmarshalPreservingUnknownFields(y)
+	expect := "json: unknown field \"futureOption\""
+	if err == nil || err.Error() != expect {
+		t.Fatalf("expect %v but got: %v", expect, err)
+	}
+}
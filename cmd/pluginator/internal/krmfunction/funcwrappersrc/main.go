@@ -19,7 +19,7 @@ func main() {
 	resmapFactory := resmap.NewFactory(
 		p.GetResourceFactory(), p.GetConflictDetectorFactory())
 	pluginHelpers := resmap.NewPluginHelpers(
-		nil, p.GetFieldValidator(), resmapFactory)
+		nil, p.GetFieldValidator(), resmapFactory, resmap.NewSchema(nil))
 
 	resourceList := &framework.ResourceList{}
 	resourceList.FunctionConfig = map[string]interface{}{}
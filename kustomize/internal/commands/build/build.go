@@ -4,8 +4,10 @@
 package build
 
 import (
+	"bufio"
 	"io"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -51,6 +53,11 @@ with a kustomization.yaml file, e.g.
 
 The URL should be formulated as described at
 https://github.com/hashicorp/go-getter#url-format
+
+If 'someDir/kustomization.yaml' lists '-' in its resources,
+the resources piped to the build are read there, e.g.
+
+  helm template someChart | kustomize build someDir
 `
 
 // NewCmdBuild creates a new build command.
@@ -96,10 +103,17 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 
 	addFlagLoadRestrictor(cmd.Flags())
 	addFlagEnablePlugins(cmd.Flags())
+	addFlagExecPluginEnv(cmd.Flags())
+	addFlagPluginLimits(cmd.Flags())
 	addFlagReorderOutput(cmd.Flags())
 	addFlagEnableManagedbyLabel(cmd.Flags())
 	addFlagEnableKyaml(cmd.Flags())
 	addFlagAllowResourceIdChanges(cmd.Flags())
+	addFlagCompatibilityLevel(cmd.Flags())
+	addFlagCheckNamespaces(cmd.Flags())
+	addFlagCheckUniqueness(cmd.Flags())
+	addFlagEmitYamlDirectives(cmd.Flags())
+	addFlagErrorOnEmptyOutput(cmd.Flags())
 
 	return cmd
 }
@@ -120,6 +134,14 @@ func (o *Options) Validate(args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	err = validateFlagCheckNamespaces()
+	if err != nil {
+		return err
+	}
+	err = validateFlagCheckUniqueness()
+	if err != nil {
+		return err
+	}
 	o.outOrder, err = validateFlagReorderOutput()
 	return
 }
@@ -134,11 +156,18 @@ func (o *Options) makeOptions() *krusty.Options {
 			log.Fatal(err)
 		}
 		c.FnpLoadingOptions = o.fnOptions
+		c.ExecPluginEnv = getFlagExecPluginEnvValue()
+		c.Limits = getFlagPluginLimitsValue()
 		opts.PluginConfig = c
 	}
 	opts.AddManagedbyLabel = isManagedbyLabelEnabled()
 	opts.UseKyaml = flagEnableKyamlValue
 	opts.AllowResourceIdChanges = flagAllowResourceIdChangesValue
+	opts.CompatibilityLevel = flagCompatibilityLevelValue
+	opts.NamespaceCheck = getFlagCheckNamespacesValue()
+	opts.UniquenessCheck = getFlagCheckUniquenessValue()
+	opts.ErrorOnEmptyOutput = flagErrorOnEmptyOutputValue
+	opts.StdinResources = os.Stdin
 	return opts
 }
 
@@ -149,6 +178,12 @@ func (o *Options) RunBuild(out io.Writer) error {
 	if err != nil {
 		return err
 	}
+	for _, w := range k.Warnings() {
+		log.Printf("Warning: %s", w)
+	}
+	for _, r := range k.Results() {
+		log.Printf("Result: %s", r)
+	}
 	return o.emitResources(out, fSys, m)
 }
 
@@ -157,15 +192,24 @@ func (o *Options) emitResources(
 	if o.outputPath != "" && fSys.IsDir(o.outputPath) {
 		return writeIndividualFiles(fSys, o.outputPath, m)
 	}
-	res, err := m.AsYaml()
-	if err != nil {
-		return err
+	writeYaml := m.WriteYaml
+	if flagEmitYamlDirectivesValue {
+		writeYaml = m.WriteYamlWithDirectives
 	}
 	if o.outputPath != "" {
-		return fSys.WriteFile(o.outputPath, res)
+		f, err := fSys.Create(o.outputPath)
+		if err != nil {
+			return err
+		}
+		err = writeYaml(bufio.NewWriter(f))
+		if errClose := f.Close(); err == nil {
+			err = errClose
+		}
+		return err
 	}
-	_, err = out.Write(res)
-	return err
+	// The output is streamed, a resource at a time, as
+	// holding all of a large build's YAML doubles its memory.
+	return writeYaml(bufio.NewWriter(out))
 }
 
 func writeIndividualFiles(
@@ -203,5 +247,8 @@ func writeFile(
 	if err != nil {
 		return err
 	}
+	if d := res.GetDirectives(); flagEmitYamlDirectivesValue && len(d) > 0 {
+		out = append([]byte(strings.Join(d, "\n")+"\n---\n"), out...)
+	}
 	return fSys.WriteFile(filepath.Join(path, fName), out)
 }
@@ -0,0 +1,61 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagCheckNamespacesName    = "check_namespaces"
+	flagExternalNamespacesName = "external_namespace"
+	checkNamespacesOff         = "off"
+	checkNamespacesWarn        = "warn"
+	checkNamespacesStrict      = "strict"
+)
+
+var (
+	flagCheckNamespacesValue    = checkNamespacesOff
+	flagExternalNamespacesValue []string
+)
+
+func addFlagCheckNamespaces(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagCheckNamespacesValue, flagCheckNamespacesName,
+		checkNamespacesOff,
+		"if '"+checkNamespacesWarn+"' or '"+checkNamespacesStrict+
+			"', report namespaces used by the output that it doesn't "+
+			"create, as warnings or as an error")
+	set.StringSliceVar(
+		&flagExternalNamespacesValue, flagExternalNamespacesName, nil,
+		fmt.Sprintf(
+			"a namespace known to exist outside the build; "+
+				"may be repeated; defaults to %v",
+			krusty.DefaultExternalNamespaces))
+}
+
+func validateFlagCheckNamespaces() error {
+	switch flagCheckNamespacesValue {
+	case checkNamespacesOff, checkNamespacesWarn, checkNamespacesStrict:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagCheckNamespacesName, flagCheckNamespacesValue,
+			[]string{checkNamespacesOff, checkNamespacesWarn, checkNamespacesStrict})
+	}
+}
+
+func getFlagCheckNamespacesValue() *krusty.NamespaceCheck {
+	if flagCheckNamespacesValue == checkNamespacesOff {
+		return nil
+	}
+	return &krusty.NamespaceCheck{
+		ExternalNamespaces: flagExternalNamespacesValue,
+		Strict:             flagCheckNamespacesValue == checkNamespacesStrict,
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagCheckUniquenessName = "check_uniqueness"
+	checkUniquenessOff      = "off"
+	checkUniquenessWarn     = "warn"
+	checkUniquenessStrict   = "strict"
+)
+
+var flagCheckUniquenessValue = checkUniquenessOff
+
+func addFlagCheckUniqueness(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagCheckUniquenessValue, flagCheckUniquenessName,
+		checkUniquenessOff,
+		"if '"+checkUniquenessWarn+"' or '"+checkUniquenessStrict+
+			"', report Services sharing a nodePort, and Ingresses "+
+			"sharing a host and path, as warnings or as an error")
+}
+
+func validateFlagCheckUniqueness() error {
+	switch flagCheckUniquenessValue {
+	case checkUniquenessOff, checkUniquenessWarn, checkUniquenessStrict:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagCheckUniquenessName, flagCheckUniquenessValue,
+			[]string{checkUniquenessOff, checkUniquenessWarn, checkUniquenessStrict})
+	}
+}
+
+func getFlagCheckUniquenessValue() *krusty.UniquenessCheck {
+	if flagCheckUniquenessValue == checkUniquenessOff {
+		return nil
+	}
+	return &krusty.UniquenessCheck{
+		Strict: flagCheckUniquenessValue == checkUniquenessStrict,
+	}
+}
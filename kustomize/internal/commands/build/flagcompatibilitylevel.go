@@ -0,0 +1,28 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagCompatibilityLevelName = "compatibility_level"
+)
+
+var (
+	flagCompatibilityLevelValue = ""
+)
+
+func addFlagCompatibilityLevel(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagCompatibilityLevelValue, flagCompatibilityLevelName, "",
+		fmt.Sprintf(
+			"reproduce the output of this kustomize version, one of %s",
+			strings.Join(krusty.SupportedCompatibilityLevels(), ", ")))
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEmitYamlDirectivesName = "emit_yaml_directives"
+	flagEmitYamlDirectivesHelp = `write the YAML directives, e.g. %YAML 1.1, of each input document before the resources read from it`
+)
+
+var (
+	flagEmitYamlDirectivesValue = false
+)
+
+func addFlagEmitYamlDirectives(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagEmitYamlDirectivesValue, flagEmitYamlDirectivesName,
+		false, flagEmitYamlDirectivesHelp)
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagErrorOnEmptyOutputName = "error_on_empty_output"
+	flagErrorOnEmptyOutputHelp = `fail the build if it makes no resources`
+)
+
+var (
+	flagErrorOnEmptyOutputValue = false
+)
+
+func addFlagErrorOnEmptyOutput(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagErrorOnEmptyOutputValue, flagErrorOnEmptyOutputName,
+		false, flagErrorOnEmptyOutputHelp)
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagExecPluginEnvAllowName = "exec_plugin_env_allow"
+	flagExecPluginEnvDenyName  = "exec_plugin_env_deny"
+)
+
+var (
+	flagExecPluginEnvAllowValue []string
+	flagExecPluginEnvDenyValue  []string
+)
+
+func addFlagExecPluginEnv(set *pflag.FlagSet) {
+	set.StringSliceVar(
+		&flagExecPluginEnvAllowValue, flagExecPluginEnvAllowName, nil,
+		"an environment variable that exec plugins inherit, or a prefix "+
+			"ending in *; if given, they inherit no others; may be repeated")
+	set.StringSliceVar(
+		&flagExecPluginEnvDenyValue, flagExecPluginEnvDenyName, nil,
+		"an environment variable that exec plugins don't inherit, or a "+
+			"prefix ending in *, e.g. AWS_*; may be repeated")
+}
+
+func getFlagExecPluginEnvValue() types.ExecPluginEnv {
+	return types.ExecPluginEnv{
+		Allow: flagExecPluginEnvAllowValue,
+		Deny:  flagExecPluginEnvDenyValue,
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagPluginTimeoutName        = "plugin_timeout"
+	flagPluginMaxOutputBytesName = "plugin_max_output_bytes"
+	flagPluginMemoryName         = "plugin_memory"
+)
+
+var (
+	flagPluginTimeoutValue        time.Duration
+	flagPluginMaxOutputBytesValue int64
+	flagPluginMemoryValue         string
+)
+
+func addFlagPluginLimits(set *pflag.FlagSet) {
+	set.DurationVar(
+		&flagPluginTimeoutValue, flagPluginTimeoutName, 0,
+		"the most time each run of an exec plugin or function may "+
+			"take, e.g. 2m; 0 means the default, 10m, and -1s no limit")
+	set.Int64Var(
+		&flagPluginMaxOutputBytesValue, flagPluginMaxOutputBytesName, 0,
+		"the most bytes each run of an exec plugin or function may write "+
+			"to stdout, and to stderr; 0 means the default, 64MiB, "+
+			"and -1 no limit")
+	set.StringVar(
+		&flagPluginMemoryValue, flagPluginMemoryName, "",
+		"the most memory each container function may use, e.g. 512m")
+}
+
+func getFlagPluginLimitsValue() types.PluginLimits {
+	return types.PluginLimits{
+		Timeout:        flagPluginTimeoutValue,
+		MaxOutputBytes: flagPluginMaxOutputBytesValue,
+		Memory:         flagPluginMemoryValue,
+	}
+}
@@ -6,6 +6,7 @@ package add
 import (
 	"errors"
 	"log"
+	"reflect"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -82,7 +83,7 @@ func (o *addPatchOptions) RunAddPatch(fSys filesys.FileSystem) error {
 
 	// Omit target if it's empty
 	emptyTarget := types.Selector{}
-	if o.Patch.Target != nil && *o.Patch.Target == emptyTarget {
+	if o.Patch.Target != nil && reflect.DeepEqual(*o.Patch.Target, emptyTarget) {
 		o.Patch.Target = nil
 	}
 	for _, p := range m.Patches {
@@ -5,6 +5,7 @@ package remove
 
 import (
 	"log"
+	"reflect"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -74,7 +75,7 @@ func (o *removePatchOptions) RunRemovePatch(fSys filesys.FileSystem) error {
 
 	// Omit target if it's empty
 	emptyTarget := types.Selector{}
-	if o.Patch.Target != nil && *o.Patch.Target == emptyTarget {
+	if o.Patch.Target != nil && reflect.DeepEqual(*o.Patch.Target, emptyTarget) {
 		o.Patch.Target = nil
 	}
 
@@ -85,7 +86,7 @@ func (o *removePatchOptions) RunRemovePatch(fSys filesys.FileSystem) error {
 		}
 	}
 	if len(patches) == len(m.Patches) {
-		log.Printf("patch %s doesn't exist in kustomization file", o.Patch)
+		log.Printf("patch %v doesn't exist in kustomization file", o.Patch)
 		return nil
 	}
 	m.Patches = patches
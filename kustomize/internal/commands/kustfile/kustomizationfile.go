@@ -235,6 +235,15 @@ func (mf *kustomizationFile) marshal(kustomization *types.Kustomization) ([]byte
 		}
 		output = append(output, content...)
 	}
+	if len(kustomization.UnknownFields) > 0 {
+		// Fields read by a lenient unmarshal that
+		// this version doesn't know; keep them.
+		content, err := yaml.Marshal(kustomization.UnknownFields)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, content...)
+	}
 	return output, nil
 }
 
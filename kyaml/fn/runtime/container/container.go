@@ -152,6 +152,9 @@ func (c *Filter) setupExec() {
 	path, args := c.getCommand()
 	c.Exec.Path = path
 	c.Exec.Args = args
+	if c.Exec.Name == "" {
+		c.Exec.Name = c.Image
+	}
 }
 
 // getArgs returns the command + args to run to spawn the container
@@ -174,6 +177,16 @@ func (c *Filter) getCommand() (string, []string) {
 		// note: don't make fs readonly because things like heredoc rely on writing tmp files
 	}
 
+	// The limits of the exec filter apply to the docker client.  With
+	// an init process, the container stops when the client is sent
+	// SIGTERM for exceeding them, as the client passes it on.
+	if l := c.Exec.Limits; l.Timeout > 0 || l.MaxOutputBytes > 0 {
+		args = append(args, "--init")
+	}
+	if c.Exec.Limits.Memory != "" {
+		args = append(args, "--memory", c.Exec.Limits.Memory)
+	}
+
 	// TODO(joncwong): Allow StorageMount fields to have default values.
 	for _, storageMount := range c.StorageMounts {
 		args = append(args, "--mount", storageMount.String())
@@ -7,8 +7,10 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -21,6 +23,7 @@ func TestFilter_setupExec(t *testing.T) {
 		expectedArgs   []string
 		containerSpec  runtimeutil.ContainerSpec
 		UIDGID         string
+		limits         runtimeexec.Limits
 	}{
 		{
 			name: "command",
@@ -115,6 +118,32 @@ metadata:
 			},
 			UIDGID: "1:2",
 		},
+		{
+			name: "limits",
+			functionConfig: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`,
+			expectedArgs: []string{
+				"run",
+				"--rm",
+				"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none",
+				"--user", "nobody",
+				"--security-opt=no-new-privileges",
+				"--init",
+				"--memory", "512m",
+			},
+			containerSpec: runtimeutil.ContainerSpec{
+				Image: "example.com:version",
+			},
+			UIDGID: "nobody",
+			limits: runtimeexec.Limits{
+				Timeout: time.Minute,
+				Memory:  "512m",
+			},
+		},
 	}
 
 	for i := range tests {
@@ -127,6 +156,7 @@ metadata:
 
 			instance := NewContainer(tt.containerSpec, tt.UIDGID)
 			instance.Exec.FunctionConfig = cfg
+			instance.Exec.Limits = tt.limits
 			instance.Env = append(instance.Env, "KYAML_TEST=FOO")
 			instance.setupExec()
 
@@ -137,6 +167,9 @@ metadata:
 			if !assert.Equal(t, "docker", instance.Exec.Path) {
 				t.FailNow()
 			}
+			if !assert.Equal(t, instance.Image, instance.Exec.Name) {
+				t.FailNow()
+			}
 			if !assert.Equal(t, tt.expectedArgs, instance.Exec.Args) {
 				t.FailNow()
 			}
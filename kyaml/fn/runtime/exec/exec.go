@@ -4,6 +4,7 @@
 package exec
 
 import (
+	"context"
 	"io"
 	"os"
 	"os/exec"
@@ -19,6 +20,16 @@ type Filter struct {
 	// Args are the arguments to the executable
 	Args []string `yaml:"args,omitempty"`
 
+	// Name identifies the function in errors.
+	// It defaults to Path.
+	Name string `yaml:"-"`
+
+	// Context, if not nil, stops the executable when done.
+	Context context.Context `yaml:"-"`
+
+	// Limits bound the resources the executable may use.
+	Limits Limits `yaml:"-"`
+
 	runtimeutil.FunctionFilter
 }
 
@@ -32,5 +43,9 @@ func (c *Filter) Run(reader io.Reader, writer io.Writer) error {
 	cmd.Stdin = reader
 	cmd.Stdout = writer
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	name := c.Name
+	if name == "" {
+		name = c.Path
+	}
+	return Run(c.Context, cmd, name, c.Limits)
 }
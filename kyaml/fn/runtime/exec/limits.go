@@ -0,0 +1,196 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	// tailSize is the most output a LimitError quotes.
+	tailSize = 2048
+
+	// killGracePeriod is how long a process group has to
+	// exit after SIGTERM, before it's sent SIGKILL.
+	killGracePeriod = 5 * time.Second
+)
+
+// Limits bound the resources a function process may use.
+// A zero field means no limit.
+type Limits struct {
+	// Timeout is the most wall-clock time the process
+	// may run.
+	Timeout time.Duration
+
+	// MaxOutputBytes is the most bytes the process may
+	// write to stdout, and the most it may write to stderr.
+	MaxOutputBytes int64
+
+	// Memory is the most memory a container may use, in
+	// the syntax of the docker run --memory flag, e.g.
+	// 512m.  It doesn't apply to other functions.
+	Memory string
+}
+
+// LimitError reports a function process that
+// exceeded a limit, and so was killed.
+type LimitError struct {
+	// Name identifies the process, e.g. by the
+	// path of its executable.
+	Name string
+
+	// Limit describes the limit exceeded.
+	Limit string
+
+	// Tail is the end of the output of the process,
+	// stdout and stderr interleaved.
+	Tail string
+}
+
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("%s exceeded its %s, and was killed",
+		e.Name, e.Limit)
+	if e.Tail == "" {
+		return msg + "; it wrote no output"
+	}
+	return msg + "; its output ended with:\n" + e.Tail
+}
+
+// Run starts the command, and waits for it to exit.  If
+// the process exceeds the limits, or the context is done
+// first, its process group is killed, and Run returns a
+// *LimitError, or the error of the context.  The name
+// identifies the process in errors.  The command's
+// Stdout and Stderr, if not nil, get the output up to
+// the limits.
+func Run(ctx context.Context, cmd *exec.Cmd, name string, l Limits) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	runCtx := ctx
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+	tail := &tailBuffer{}
+	exceeded := make(chan struct{})
+	var once sync.Once
+	wrap := func(w io.Writer) io.Writer {
+		return &limitWriter{
+			w:    w,
+			tail: tail,
+			max:  l.MaxOutputBytes,
+			exceeded: func() {
+				once.Do(func() { close(exceeded) })
+			},
+		}
+	}
+	cmd.Stdout = wrap(cmd.Stdout)
+	cmd.Stderr = wrap(cmd.Stderr)
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	outputLimitError := func() error {
+		return &LimitError{
+			Name:  name,
+			Limit: fmt.Sprintf("output limit of %d bytes", l.MaxOutputBytes),
+			Tail:  tail.String(),
+		}
+	}
+	select {
+	case err := <-done:
+		select {
+		case <-exceeded:
+			// The process exited before it could be killed.
+			return outputLimitError()
+		default:
+			return err
+		}
+	case <-exceeded:
+		killProcessGroup(cmd, done)
+		return outputLimitError()
+	case <-runCtx.Done():
+		killProcessGroup(cmd, done)
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		}
+		return &LimitError{
+			Name:  name,
+			Limit: fmt.Sprintf("timeout of %s", l.Timeout),
+			Tail:  tail.String(),
+		}
+	}
+}
+
+// killProcessGroup stops the process group of the command,
+// and waits for the command to exit, as reported on done.
+// The group is sent SIGTERM, where there's such a thing,
+// so that e.g. the docker client can stop its container,
+// then SIGKILL if it's still running after a grace period.
+func killProcessGroup(cmd *exec.Cmd, done <-chan error) {
+	if terminateProcessGroup(cmd) == nil {
+		select {
+		case <-done:
+			return
+		case <-time.After(killGracePeriod):
+		}
+	}
+	_ = forceKillProcessGroup(cmd)
+	<-done
+}
+
+// limitWriter writes to w, if it's not nil, until more
+// than max bytes have been written, recording the tail
+// of the output, and calls exceeded once max is passed.
+type limitWriter struct {
+	w        io.Writer
+	tail     *tailBuffer
+	max      int64
+	n        int64
+	exceeded func()
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	lw.tail.Write(p)
+	lw.n += int64(len(p))
+	if lw.max > 0 && lw.n > lw.max {
+		lw.exceeded()
+		return 0, fmt.Errorf("output limit of %d bytes exceeded", lw.max)
+	}
+	if lw.w == nil {
+		return len(p), nil
+	}
+	return lw.w.Write(p)
+}
+
+// tailBuffer keeps the last tailSize bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > tailSize {
+		t.buf = append([]byte(nil), t.buf[len(t.buf)-tailSize:]...)
+	}
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !windows
+
+package exec_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	osexec "os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+)
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	cmd := osexec.Command("echo", "hello")
+	cmd.Stdout = &out
+	err := exec.Run(context.Background(), cmd, "echo", exec.Limits{
+		Timeout:        time.Minute,
+		MaxOutputBytes: 100,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", out.String())
+}
+
+func TestRunTimeout(t *testing.T) {
+	// The sleep in the background must be killed too,
+	// or Run would wait for it to close stdout.
+	cmd := osexec.Command("sh", "-c", "echo started; sleep 30 & wait")
+	start := time.Now()
+	err := exec.Run(context.Background(), cmd, "sleeper", exec.Limits{
+		Timeout: 100 * time.Millisecond,
+	})
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+	var le *exec.LimitError
+	if !assert.True(t, errors.As(err, &le), err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "sleeper exceeded its timeout of 100ms, "+
+		"and was killed; its output ended with:\nstarted\n", err.Error())
+}
+
+func TestRunMaxOutputBytes(t *testing.T) {
+	var out bytes.Buffer
+	cmd := osexec.Command("yes")
+	cmd.Stdout = &out
+	err := exec.Run(context.Background(), cmd, "yes", exec.Limits{
+		MaxOutputBytes: 1 << 16,
+	})
+	var le *exec.LimitError
+	if !assert.True(t, errors.As(err, &le), err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "yes", le.Name)
+	assert.Equal(t, "output limit of 65536 bytes", le.Limit)
+	assert.Equal(t, 2048, len(le.Tail))
+	assert.True(t, strings.HasSuffix(le.Tail, "y\n"))
+	assert.LessOrEqual(t, out.Len(), 1<<16)
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	cmd := osexec.Command("sleep", "30")
+	err := exec.Run(ctx, cmd, "sleeper", exec.Limits{Timeout: time.Minute})
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Equal(t, "sleeper: context canceled", err.Error())
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// setProcessGroup does nothing; on Windows only the
+// command's own process is killed.
+func setProcessGroup(_ *exec.Cmd) {}
+
+func terminateProcessGroup(_ *exec.Cmd) error {
+	return errors.New("not supported on windows")
+}
+
+func forceKillProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -32,10 +32,71 @@ type openapiData struct {
 	noUseBuiltInSchema             bool
 }
 
+// Schemas is an index of OpenAPI definitions by resource
+// type, like the global one the package functions use.
+// Programs running several builds at once, each with
+// definitions of its own, e.g. of custom resources, can
+// give each build its own Schemas rather than changing the
+// global one.  Parsing the built-in schema is costly, so
+// builds using the same definitions should share one.
+// Schemas are safe for concurrent use once the definitions
+// are added, but AddSchema mustn't run concurrently with
+// anything else.
+type Schemas struct {
+	data openapiData
+}
+
+// NewSchemas returns Schemas holding the built-in
+// definitions, which are parsed on first use.
+func NewSchemas() *Schemas {
+	return &Schemas{}
+}
+
+// SuppressBuiltInSchemaUse keeps the built-in definitions
+// out of the Schemas; it must be called before they're used.
+func (s *Schemas) SuppressBuiltInSchemaUse() {
+	s.data.noUseBuiltInSchema = true
+}
+
+// AddSchema parses b, and adds its definitions to the Schemas.
+func (s *Schemas) AddSchema(b []byte) error {
+	return s.data.parse(b)
+}
+
+// SchemaForResourceType returns the Schema for the given
+// Resource, or nil if there's none.
+func (s *Schemas) SchemaForResourceType(t yaml.TypeMeta) *ResourceSchema {
+	return s.data.schemaForResourceType(t)
+}
+
+// IsNamespaceScoped is the package function IsNamespaceScoped
+// for the Schemas.
+func (s *Schemas) IsNamespaceScoped(typeMeta yaml.TypeMeta) (bool, bool) {
+	return s.data.isNamespaceScoped(typeMeta)
+}
+
+// Schema returns the root schema of the Schemas.
+func (s *Schemas) Schema() *spec.Schema {
+	return s.data.rootSchema()
+}
+
 // ResourceSchema wraps the OpenAPI Schema.
 type ResourceSchema struct {
 	// Schema is the OpenAPI schema for a Resource or field
 	Schema *spec.Schema
+
+	// data holds the definitions that references within
+	// Schema resolve to; the global ones if nil.
+	data *openapiData
+}
+
+// definitions returns the definitions that references
+// within the ResourceSchema resolve to.
+func (rs *ResourceSchema) definitions() *openapiData {
+	if rs.data == nil {
+		return &globalSchema
+	}
+	return rs.data
 }
 
 // IsEmpty returns true if the ResourceSchema is empty
@@ -51,12 +112,16 @@ func (rs *ResourceSchema) IsMissingOrNull() bool {
 // which can be used for duck-typed Resources -- e.g. contains common fields such
 // as metadata, replicas and spec.template.spec
 func SchemaForResourceType(t yaml.TypeMeta) *ResourceSchema {
-	initSchema()
-	rs, found := globalSchema.schemaByResourceType[t]
+	return globalSchema.schemaForResourceType(t)
+}
+
+func (d *openapiData) schemaForResourceType(t yaml.TypeMeta) *ResourceSchema {
+	d.init()
+	rs, found := d.schemaByResourceType[t]
 	if !found {
 		return nil
 	}
-	return &ResourceSchema{Schema: rs}
+	return &ResourceSchema{Schema: rs, data: d}
 }
 
 // SupplementaryOpenAPIFieldName is the conventional field name (JSON/YAML) containing
@@ -157,7 +222,7 @@ func schemaUsingField(object *yaml.RNode, field string) (*spec.Schema, error) {
 
 // AddSchema parses s, and adds definitions from s to the global schema.
 func AddSchema(s []byte) error {
-	return parse(s)
+	return globalSchema.parse(s)
 }
 
 // ResetOpenAPI resets the openapi data to empty
@@ -167,23 +232,27 @@ func ResetOpenAPI() {
 
 // AddDefinitions adds the definitions to the global schema.
 func AddDefinitions(definitions spec.Definitions) {
+	globalSchema.addDefinitions(definitions)
+}
+
+func (d *openapiData) addDefinitions(definitions spec.Definitions) {
 	// initialize values if they have not yet been set
-	if globalSchema.schemaByResourceType == nil {
-		globalSchema.schemaByResourceType = map[yaml.TypeMeta]*spec.Schema{}
+	if d.schemaByResourceType == nil {
+		d.schemaByResourceType = map[yaml.TypeMeta]*spec.Schema{}
 	}
-	if globalSchema.schema.Definitions == nil {
-		globalSchema.schema.Definitions = spec.Definitions{}
+	if d.schema.Definitions == nil {
+		d.schema.Definitions = spec.Definitions{}
 	}
 
 	// index the schema definitions so we can lookup them up for Resources
 	for k := range definitions {
 		// index by GVK, if no GVK is found then it is the schema for a subfield
 		// of a Resource
-		d := definitions[k]
+		def := definitions[k]
 
 		// copy definitions to the schema
-		globalSchema.schema.Definitions[k] = d
-		gvk, found := d.VendorExtensible.Extensions[kubernetesGVKExtensionKey]
+		d.schema.Definitions[k] = def
+		gvk, found := def.VendorExtensible.Extensions[kubernetesGVKExtensionKey]
 		if !found {
 			continue
 		}
@@ -197,7 +266,7 @@ func AddDefinitions(definitions spec.Definitions) {
 		if !ok {
 			continue
 		}
-		globalSchema.schemaByResourceType[typeMeta] = &d
+		d.schemaByResourceType[typeMeta] = &def
 	}
 }
 
@@ -251,8 +320,12 @@ func GetSchema(s string, schema *spec.Schema) (*ResourceSchema, error) {
 // be true if the resource is namespace-scoped, and false if the type is
 // cluster-scoped.
 func IsNamespaceScoped(typeMeta yaml.TypeMeta) (bool, bool) {
-	initSchema()
-	isNamespaceScoped, found := globalSchema.namespaceabilityByResourceType[typeMeta]
+	return globalSchema.isNamespaceScoped(typeMeta)
+}
+
+func (d *openapiData) isNamespaceScoped(typeMeta yaml.TypeMeta) (bool, bool) {
+	d.init()
+	isNamespaceScoped, found := d.namespaceabilityByResourceType[typeMeta]
 	return isNamespaceScoped, found
 }
 
@@ -266,7 +339,8 @@ func SuppressBuiltInSchemaUse() {
 // Elements returns the Schema for the elements of an array.
 func (rs *ResourceSchema) Elements() *ResourceSchema {
 	// load the schema from swagger.json
-	initSchema()
+	data := rs.definitions()
+	data.init()
 
 	if len(rs.Schema.Type) != 1 || rs.Schema.Type[0] != "array" {
 		// either not an array, or array has multiple types
@@ -278,13 +352,13 @@ func (rs *ResourceSchema) Elements() *ResourceSchema {
 	}
 	s := *rs.Schema.Items.Schema
 	for s.Ref.String() != "" {
-		sc, e := Resolve(&s.Ref, Schema())
+		sc, e := Resolve(&s.Ref, data.rootSchema())
 		if e != nil {
 			return nil
 		}
 		s = *sc
 	}
-	return &ResourceSchema{Schema: &s}
+	return &ResourceSchema{Schema: &s, data: rs.data}
 }
 
 const Elements = "[]"
@@ -312,7 +386,8 @@ func (rs *ResourceSchema) Lookup(path ...string) *ResourceSchema {
 // Field returns the Schema for a field.
 func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 	// load the schema from swagger.json
-	initSchema()
+	data := rs.definitions()
+	data.init()
 
 	// locate the Schema
 	s, found := rs.Schema.Properties[field]
@@ -330,7 +405,7 @@ func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 
 	// resolve the reference to the Schema if the Schema has one
 	for s.Ref.String() != "" {
-		sc, e := Resolve(&s.Ref, Schema())
+		sc, e := Resolve(&s.Ref, data.rootSchema())
 		if e != nil {
 			return nil
 		}
@@ -338,7 +413,7 @@ func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 	}
 
 	// return the merged Schema
-	return &ResourceSchema{Schema: &s}
+	return &ResourceSchema{Schema: &s, data: rs.data}
 }
 
 // PatchStrategyAndKeyList returns the patch strategy and complete merge key list
@@ -419,10 +494,10 @@ const (
 	kindKey = "kind"
 )
 
-// initSchema parses the json schema
-func initSchema() {
-	globalSchema.setup.Do(func() {
-		if globalSchema.noUseBuiltInSchema {
+// init parses the json schema
+func (d *openapiData) init() {
+	d.setup.Do(func() {
+		if d.noUseBuiltInSchema {
 			// don't parse the built in schema
 			return
 		}
@@ -432,12 +507,12 @@ func initSchema() {
 			"kubernetesapi",
 			kubernetesAPIDefaultVersion,
 			"swagger.json")
-		if err := parse(kubernetesapi.OpenApiMustAsset[kubernetesAPIDefaultVersion](assetName)); err != nil {
+		if err := d.parse(kubernetesapi.OpenApiMustAsset[kubernetesAPIDefaultVersion](assetName)); err != nil {
 			// this should never happen
 			panic(err)
 		}
 
-		if err := parse(kustomizationapi.MustAsset(kustomizationAPIAssetName)); err != nil {
+		if err := d.parse(kustomizationapi.MustAsset(kustomizationAPIAssetName)); err != nil {
 			// this should never happen
 			panic(err)
 		}
@@ -445,14 +520,14 @@ func initSchema() {
 }
 
 // parse parses and indexes a single json schema
-func parse(b []byte) error {
+func (d *openapiData) parse(b []byte) error {
 	var swagger spec.Swagger
 
 	if err := swagger.UnmarshalJSON(b); err != nil {
 		return errors.Wrap(err)
 	}
-	AddDefinitions(swagger.Definitions)
-	findNamespaceability(swagger.Paths)
+	d.addDefinitions(swagger.Definitions)
+	d.findNamespaceability(swagger.Paths)
 
 	return nil
 }
@@ -462,9 +537,9 @@ func parse(b []byte) error {
 // for each path is found by looking at the x-kubernetes-group-version-kind
 // extension. If a path exists for the resource that contains a namespace path
 // parameter, the resource is namespace-scoped.
-func findNamespaceability(paths *spec.Paths) {
-	if globalSchema.namespaceabilityByResourceType == nil {
-		globalSchema.namespaceabilityByResourceType = make(map[yaml.TypeMeta]bool)
+func (d *openapiData) findNamespaceability(paths *spec.Paths) {
+	if d.namespaceabilityByResourceType == nil {
+		d.namespaceabilityByResourceType = make(map[yaml.TypeMeta]bool)
 	}
 
 	if paths == nil {
@@ -487,11 +562,11 @@ func findNamespaceability(paths *spec.Paths) {
 		if strings.Contains(path, "namespaces/{namespace}") {
 			// if we find a namespace path parameter, we just update the map
 			// directly
-			globalSchema.namespaceabilityByResourceType[typeMeta] = true
-		} else if _, found := globalSchema.namespaceabilityByResourceType[typeMeta]; !found {
+			d.namespaceabilityByResourceType[typeMeta] = true
+		} else if _, found := d.namespaceabilityByResourceType[typeMeta]; !found {
 			// if the resource doesn't have the namespace path parameter, we
 			// only add it to the map if it doesn't already exist.
-			globalSchema.namespaceabilityByResourceType[typeMeta] = false
+			d.namespaceabilityByResourceType[typeMeta] = false
 		}
 	}
 }
@@ -522,6 +597,10 @@ func resolve(root interface{}, ref *spec.Ref) (*spec.Schema, error) {
 }
 
 func rootSchema() *spec.Schema {
-	initSchema()
-	return &globalSchema.schema
+	return globalSchema.rootSchema()
+}
+
+func (d *openapiData) rootSchema() *spec.Schema {
+	d.init()
+	return &d.schema
 }
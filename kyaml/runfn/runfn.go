@@ -4,6 +4,7 @@
 package runfn
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -101,6 +102,14 @@ type RunFns struct {
 	// If it is true, the empty result will be provided as input to the next
 	// function in the list.
 	ContinueOnEmptyResult bool
+
+	// Context, if not nil, stops running container and exec
+	// functions when done.
+	Context context.Context
+
+	// Limits bound the resources that each container or
+	// exec function may use.
+	Limits exec.Limits
 }
 
 // Execute runs the command
@@ -469,6 +478,8 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 			uidgid,
 		)
 		cf := &c
+		cf.Exec.Context = r.Context
+		cf.Exec.Limits = r.Limits
 		cf.Exec.FunctionConfig = api
 		cf.Exec.GlobalScope = r.GlobalScope
 		cf.Exec.ResultsFile = resultsFile
@@ -508,7 +519,11 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 	}
 
 	if r.EnableExec && spec.Exec.Path != "" {
-		ef := &exec.Filter{Path: spec.Exec.Path}
+		ef := &exec.Filter{
+			Path:    spec.Exec.Path,
+			Context: r.Context,
+			Limits:  r.Limits,
+		}
 
 		ef.FunctionConfig = api
 		ef.GlobalScope = r.GlobalScope
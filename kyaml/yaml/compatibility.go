@@ -90,3 +90,33 @@ func IsValueNonString(value string) bool {
 }
 
 var stringType = reflect.TypeOf("string")
+
+// KeepStyle gives the value node the style of the existing
+// node it replaces, e.g. double quoted or a literal block,
+// so that replacing a value doesn't restyle it, unless the
+// value would then read as another type.  E.g. a string
+// value "on" replacing a plain string keeps its quotes,
+// or gains them, since a plain on is a bool in yaml 1.1,
+// and an int value replacing a quoted one isn't quoted.
+// A value with no tag is taken to be of the type it reads
+// as in the existing style.
+func KeepStyle(existing, value *Node) {
+	stringStyles := DoubleQuotedStyle | SingleQuotedStyle |
+		LiteralStyle | FoldedStyle
+	isString := value.Tag == NodeTagString || value.Style&stringStyles != 0
+	if existing.Style&stringStyles == 0 {
+		if isString && IsYaml1_1NonString(value) {
+			if value.Style&(DoubleQuotedStyle|SingleQuotedStyle) == 0 {
+				value.Style = DoubleQuotedStyle
+			}
+			return
+		}
+		value.Style = existing.Style
+		return
+	}
+	if value.Tag != "" && value.Tag != NodeTagString {
+		// A value of another type can't be quoted.
+		return
+	}
+	value.Style = existing.Style
+}
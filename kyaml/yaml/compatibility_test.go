@@ -135,3 +135,55 @@ var valueToTagMap = func() map[string]string {
 
 	return val
 }()
+
+func TestKeepStyle(t *testing.T) {
+	testCases := map[string]struct {
+		existing yaml.Node
+		value    yaml.Node
+		expected yaml.Style
+	}{
+		"plain replaced": {
+			existing: yaml.Node{Tag: yaml.NodeTagString},
+			value:    yaml.Node{Value: "b", Style: yaml.DoubleQuotedStyle},
+			expected: 0,
+		},
+		"quoted": {
+			existing: yaml.Node{Tag: yaml.NodeTagString, Style: yaml.SingleQuotedStyle},
+			value:    yaml.Node{Value: "b", Tag: yaml.NodeTagString},
+			expected: yaml.SingleQuotedStyle,
+		},
+		"literal": {
+			existing: yaml.Node{Tag: yaml.NodeTagString, Style: yaml.LiteralStyle},
+			value:    yaml.Node{Value: "b\nc\n", Tag: yaml.NodeTagString},
+			expected: yaml.LiteralStyle,
+		},
+		"quoted string value stays quoted": {
+			existing: yaml.Node{Tag: yaml.NodeTagString},
+			value:    yaml.Node{Value: "on", Style: yaml.SingleQuotedStyle},
+			expected: yaml.SingleQuotedStyle,
+		},
+		"string value gains quotes": {
+			existing: yaml.Node{Tag: yaml.NodeTagString},
+			value:    yaml.Node{Value: "1.0", Tag: yaml.NodeTagString},
+			expected: yaml.DoubleQuotedStyle,
+		},
+		"int value isn't quoted": {
+			existing: yaml.Node{Tag: yaml.NodeTagString, Style: yaml.DoubleQuotedStyle},
+			value:    yaml.Node{Value: "3", Tag: yaml.NodeTagInt},
+			expected: 0,
+		},
+		"untagged value takes the style": {
+			existing: yaml.Node{Tag: yaml.NodeTagString, Style: yaml.DoubleQuotedStyle},
+			value:    yaml.Node{Value: "3"},
+			expected: yaml.DoubleQuotedStyle,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.existing.Kind = yaml.ScalarNode
+			tc.value.Kind = yaml.ScalarNode
+			yaml.KeepStyle(&tc.existing, &tc.value)
+			assert.Equal(t, tc.expected, tc.value.Style)
+		})
+	}
+}
@@ -35,20 +35,41 @@ func (rn *RNode) LoadMapIntoConfigMapData(m map[string]string) error {
 	return nil
 }
 
+// LoadMapIntoConfigMapBinaryData loads the given map, e.g.
+// of the contents of binary files, into the binaryData of
+// a ConfigMap, whether or not the values are valid UTF-8.
+func (rn *RNode) LoadMapIntoConfigMapBinaryData(m map[string]string) error {
+	for _, k := range SortedMapKeys(m) {
+		if _, err := rn.Pipe(
+			LookupCreate(MappingNode, BinaryDataField),
+			SetField(k, makeBinaryDataValueRNode(m[k]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func makeConfigMapValueRNode(s string) (field string, rN *RNode) {
+	if !utf8.ValidString(s) {
+		return BinaryDataField, makeBinaryDataValueRNode(s)
+	}
 	yN := &Node{Kind: ScalarNode}
 	yN.Tag = NodeTagString
-	if utf8.ValidString(s) {
-		field = DataField
-		yN.Value = s
-	} else {
-		field = BinaryDataField
-		yN.Value = encodeBase64(s)
+	yN.Value = s
+	if strings.Contains(yN.Value, "\n") {
+		yN.Style = LiteralStyle
 	}
+	return DataField, NewRNode(yN)
+}
+
+func makeBinaryDataValueRNode(s string) *RNode {
+	yN := &Node{Kind: ScalarNode}
+	yN.Tag = NodeTagString
+	yN.Value = encodeBase64(s)
 	if strings.Contains(yN.Value, "\n") {
 		yN.Style = LiteralStyle
 	}
-	return field, NewRNode(yN)
+	return NewRNode(yN)
 }
 
 func (rn *RNode) LoadMapIntoSecretData(m map[string]string) error {
@@ -36,3 +36,28 @@ func TestSortedKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadMapIntoConfigMapBinaryData(t *testing.T) {
+	rn := yaml.MustParse(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	assert.NoError(t, rn.LoadMapIntoConfigMapData(map[string]string{
+		"text": "hello", "bytes": "\xff\xfe"}))
+	assert.NoError(t, rn.LoadMapIntoConfigMapBinaryData(map[string]string{
+		"logo": "PNG\x00"}))
+	s, err := rn.String()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+binaryData:
+  bytes: //4=
+  logo: UE5HAA==
+data:
+  text: hello
+`, s)
+}
@@ -620,7 +620,7 @@ func (s FieldSetter) Filter(rn *RNode) (*RNode, error) {
 		// or we want to override it
 		if !s.OverrideStyle || s.Value.YNode().Style == 0 {
 			// keep the original style if it exists
-			s.Value.YNode().Style = rn.YNode().Style
+			KeepStyle(rn.YNode(), s.Value.YNode())
 		}
 		rn.SetYNode(s.Value.YNode())
 		return rn, nil
@@ -640,7 +640,7 @@ func (s FieldSetter) Filter(rn *RNode) (*RNode, error) {
 		// or we want to override it
 		if !s.OverrideStyle || field.YNode().Style == 0 {
 			// keep the original style if it exists
-			s.Value.YNode().Style = field.YNode().Style
+			KeepStyle(field.YNode(), s.Value.YNode())
 		}
 		// need to def ref the Node since field is ephemeral
 		field.SetYNode(s.Value.YNode())
@@ -0,0 +1,34 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/yaml/internal/k8sgen/pkg/labels"
+)
+
+// LabelSelector is a parsed label selection expression, see
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
+// Parsing it once allows matching many objects without
+// reparsing it, and reporting a malformed expression
+// before any object is matched.
+type LabelSelector struct {
+	selector labels.Selector
+}
+
+// ParseLabelSelector parses the given label selection
+// expression, e.g. "app=web,tier!=db".  The empty
+// expression matches everything.
+func ParseLabelSelector(selector string) (*LabelSelector, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	return &LabelSelector{selector: s}, nil
+}
+
+// Matches returns true if the given labels, or
+// annotations, satisfy the selector.
+func (s *LabelSelector) Matches(m map[string]string) bool {
+	return s.selector.Matches(labels.Set(m))
+}
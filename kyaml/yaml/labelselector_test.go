@@ -0,0 +1,30 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelSelector(t *testing.T) {
+	s, err := ParseLabelSelector("app=web,tier!=db")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, s.Matches(map[string]string{"app": "web"}))
+	assert.True(t, s.Matches(map[string]string{"app": "web", "tier": "front"}))
+	assert.False(t, s.Matches(map[string]string{"app": "web", "tier": "db"}))
+	assert.False(t, s.Matches(nil))
+
+	s, err = ParseLabelSelector("")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, s.Matches(nil))
+
+	_, err = ParseLabelSelector("app=(web")
+	assert.Error(t, err)
+}
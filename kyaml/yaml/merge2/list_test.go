@@ -482,6 +482,210 @@ items:
 		expected: `
 kind: Deployment
 items: []
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	{description: `delete from primitive list`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        $deleteFromPrimitiveList/args:
+        - --verbose
+        - --log-format=plain text
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        args:
+        - --verbose
+        - --port=8080
+        - --log-format=plain text
+        - --verbose
+        - --verbose=2
+      - name: bar
+        args:
+        - --verbose
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo
+        args:
+        - --port=8080
+        - --verbose=2
+      - name: bar
+        args:
+        - --verbose
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+	{description: `delete from primitive list only exact values`,
+		source: `
+kind: Deployment
+spec:
+  $deleteFromPrimitiveList/args:
+  - --verbose=2
+`,
+		dest: `
+kind: Deployment
+spec:
+  args:
+  - --verbose
+  - --verbose=2
+  - --verbose=20
+`,
+		expected: `
+kind: Deployment
+spec:
+  args:
+  - --verbose
+  - --verbose=20
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+	{description: `delete from missing primitive list`,
+		source: `
+kind: Deployment
+spec:
+  $deleteFromPrimitiveList/args:
+  - --verbose
+  replicas: 2
+`,
+		dest: `
+kind: Deployment
+spec:
+  replicas: 1
+`,
+		expected: `
+kind: Deployment
+spec:
+  replicas: 2
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+	{description: `delete by name from list of unknown kind`,
+		source: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: b
+    $patch: delete
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: a
+    size: 1
+  - name: b
+    size: 2
+  - name: c
+    size: 3
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: a
+    size: 1
+  - name: c
+    size: 3
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection:    yaml.MergeOptionsListAppend,
+			DeleteListElementsByName: true,
+		},
+	},
+	{description: `list of unknown kind without deletes is replaced`,
+		source: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: b
+    size: 4
+`,
+		dest: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: a
+    size: 1
+  - name: b
+    size: 2
+`,
+		expected: `
+apiVersion: example.com/v1
+kind: Widget
+spec:
+  parts:
+  - name: b
+    size: 4
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection:    yaml.MergeOptionsListAppend,
+			DeleteListElementsByName: true,
+		},
+	},
+	{description: `delete elements of missing list`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        env:
+        - name: DEBUG
+          $patch: delete
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
 `,
 		mergeOptions: yaml.MergeOptions{
 			ListIncreaseDirection: yaml.MergeOptionsListAppend,
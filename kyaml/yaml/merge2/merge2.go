@@ -20,6 +20,36 @@ func Merge(src, dest *yaml.RNode, mergeOptions yaml.MergeOptions) (*yaml.RNode,
 	}.Walk()
 }
 
+// MergeWithSchemas is Merge, finding the schemas of the
+// resources merged in the given Schemas rather than the
+// global ones.
+func MergeWithSchemas(src, dest *yaml.RNode, mergeOptions yaml.MergeOptions,
+	schemas *openapi.Schemas) (*yaml.RNode, error) {
+	return walk.Walker{
+		Sources:      []*yaml.RNode{dest, src},
+		Visitor:      Merger{},
+		MergeOptions: mergeOptions,
+		Schemas:      schemas,
+	}.Walk()
+}
+
+// MergeWithSchemasAndWarnings is MergeWithSchemas, also
+// returning the warnings about the merge, e.g. that a value
+// of a $deleteFromPrimitiveList directive was equal to no
+// element of its list.  The other merges ignore those.
+func MergeWithSchemasAndWarnings(src, dest *yaml.RNode,
+	mergeOptions yaml.MergeOptions, schemas *openapi.Schemas) (
+	*yaml.RNode, []string, error) {
+	var warnings []string
+	result, err := walk.Walker{
+		Sources:      []*yaml.RNode{dest, src},
+		Visitor:      Merger{warnings: &warnings},
+		MergeOptions: mergeOptions,
+		Schemas:      schemas,
+	}.Walk()
+	return result, warnings, err
+}
+
 // Merge parses the arguments, and merges fields from srcStr into destStr.
 func MergeStrings(srcStr, destStr string, infer bool, mergeOptions yaml.MergeOptions) (string, error) {
 	src, err := yaml.Parse(srcStr)
@@ -46,6 +76,10 @@ func MergeStrings(srcStr, destStr string, infer bool, mergeOptions yaml.MergeOpt
 
 type Merger struct {
 	// for forwards compatibility when new functions are added to the interface
+
+	// warnings, if not nil, collects the warnings about
+	// the merge.
+	warnings *[]string
 }
 
 var _ walk.Visitor = Merger{}
@@ -57,6 +91,14 @@ func (m Merger) VisitMap(nodes walk.Sources, s *openapi.ResourceSchema) (*yaml.R
 	if err := m.SetStyle(nodes); err != nil {
 		return nil, err
 	}
+	warnings, err := applyDeleteFromPrimitiveListDirectives(
+		nodes.Origin(), nodes.Dest())
+	if err != nil {
+		return nil, err
+	}
+	if m.warnings != nil {
+		*m.warnings = append(*m.warnings, warnings...)
+	}
 	if yaml.IsMissingOrNull(nodes.Dest()) {
 		// Add
 		return nodes.Origin(), nil
@@ -114,6 +156,10 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 
 	// Add
 	if yaml.IsMissingOrNull(nodes.Dest()) {
+		if onlyDeletesElements(nodes.Origin()) {
+			// Nothing to delete from, nor to add.
+			return walk.ClearNode, nil
+		}
 		return nodes.Origin(), nil
 	}
 	// Clear
@@ -136,6 +182,21 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 	}
 }
 
+// onlyDeletesElements returns true if every element of the
+// given list is a map with the directive $patch: delete.
+func onlyDeletesElements(list *yaml.RNode) bool {
+	if yaml.IsMissingOrNull(list) || len(list.Content()) == 0 {
+		return false
+	}
+	for _, elem := range list.Content() {
+		d := yaml.NewRNode(elem).Field(strategicMergePatchDirectiveKey)
+		if d == nil || d.Value.YNode().Value != smpDelete.String() {
+			return false
+		}
+	}
+	return true
+}
+
 func (m Merger) SetStyle(sources walk.Sources) error {
 	source := sources.Origin()
 	dest := sources.Dest()
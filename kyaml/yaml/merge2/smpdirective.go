@@ -5,6 +5,7 @@ package merge2
 
 import (
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -99,3 +100,77 @@ func elideSequencePatchDirective(patch *yaml.RNode, value string) error {
 		Values:  []string{value},
 	})
 }
+
+// A patch directive, keyed by the name of a sibling field holding a list
+// of scalars, e.g.
+//
+//	$deleteFromPrimitiveList/args:
+//	- --verbose
+//
+// that deletes every element of that list equal to one of the given values.
+// Such a list has no merge key, so its elements can't otherwise be deleted
+// one at a time.
+const deleteFromPrimitiveListDirectivePrefix = "$deleteFromPrimitiveList/"
+
+// Examine patch for deleteFromPrimitiveList directives.
+// Apply each found to dest, and remove it from the patch.
+// A value equal to no element of the list isn't an error,
+// since the patch may be meant for more than one resource,
+// but it's returned as a warning.
+func applyDeleteFromPrimitiveListDirectives(patch, dest *yaml.RNode) (
+	[]string, error) {
+	if yaml.IsMissingOrNull(patch) || patch.YNode().Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	fields, err := patch.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, key := range fields {
+		if !strings.HasPrefix(key, deleteFromPrimitiveListDirectivePrefix) {
+			continue
+		}
+		field := strings.TrimPrefix(key, deleteFromPrimitiveListDirectivePrefix)
+		values := patch.Field(key).Value
+		if values.YNode().Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("%s must be a list of scalars", key)
+		}
+		var list *yaml.RNode
+		if !yaml.IsMissingOrNull(dest) {
+			if f := dest.Field(field); f != nil {
+				list = f.Value
+			}
+		}
+		for _, v := range values.Content() {
+			if v.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%s must be a list of scalars", key)
+			}
+			if deleteScalarFromList(list, v.Value) == 0 {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s: no element equal to %q to delete", key, v.Value))
+			}
+		}
+		if err := patch.PipeE(yaml.Clear(key)); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// deleteScalarFromList deletes every scalar element of list
+// equal to value, returning how many were deleted.
+func deleteScalarFromList(list *yaml.RNode, value string) int {
+	if yaml.IsMissingOrNull(list) || list.YNode().Kind != yaml.SequenceNode {
+		return 0
+	}
+	var kept []*yaml.Node
+	for _, elem := range list.Content() {
+		if elem.Kind != yaml.ScalarNode || elem.Value != value {
+			kept = append(kept, elem)
+		}
+	}
+	deleted := len(list.Content()) - len(kept)
+	list.YNode().Content = kept
+	return deleted
+}
@@ -149,3 +149,91 @@ veggie: carrot
 		})
 	}
 }
+
+func Test_applyDeleteFromPrimitiveListDirectives(t *testing.T) {
+	var cases = map[string]struct {
+		patch       string
+		dest        string
+		expected    string
+		warning     string
+		errExpected string
+	}{
+		`all matches`: {
+			patch: `
+$deleteFromPrimitiveList/args:
+- --flag
+- with some spaces
+`,
+			dest: `
+args:
+- --flag
+- with some spaces
+- --flag=value
+- with  some  spaces
+- --flag
+`,
+			expected: `args:
+- --flag=value
+- with  some  spaces
+`,
+		},
+		`no match`: {
+			patch: `
+$deleteFromPrimitiveList/args:
+- --flag
+`,
+			dest: `
+args:
+- --flag=value
+`,
+			expected: `args:
+- --flag=value
+`,
+			warning: `$deleteFromPrimitiveList/args: no element equal to "--flag" to delete`,
+		},
+		`not a list`: {
+			patch: `
+$deleteFromPrimitiveList/args: --flag
+`,
+			dest: `
+args:
+- --flag
+`,
+			errExpected: "must be a list of scalars",
+		},
+	}
+
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			patch := yaml.MustParse(tc.patch)
+			dest := yaml.MustParse(tc.dest)
+			warnings, err := applyDeleteFromPrimitiveListDirectives(patch, dest)
+			if err != nil {
+				if tc.errExpected == "" {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.errExpected) {
+					t.Fatalf("expected some error other than:  %v", err)
+				}
+				return
+			}
+			if tc.errExpected != "" {
+				t.Fatalf("should have seen an error")
+			}
+			if tc.expected != dest.MustString() {
+				t.Fatalf("expected %s, got %s", tc.expected, dest.MustString())
+			}
+			if patch.MustString() != "{}\n" {
+				t.Fatalf("directive not elided from patch: %s", patch.MustString())
+			}
+			if tc.warning == "" && len(warnings) != 0 {
+				t.Fatalf("unexpected warnings: %v", warnings)
+			}
+			if tc.warning != "" &&
+				(len(warnings) != 1 || warnings[0] != tc.warning) {
+				t.Fatalf("expected warning %s, got %v", tc.warning, warnings)
+			}
+		})
+	}
+}
@@ -13,7 +13,6 @@ import (
 
 	"gopkg.in/yaml.v3"
 	"sigs.k8s.io/kustomize/kyaml/errors"
-	"sigs.k8s.io/kustomize/kyaml/yaml/internal/k8sgen/pkg/labels"
 )
 
 // MakeNullNode returns an RNode that represents an empty document.
@@ -723,7 +722,7 @@ func (rn *RNode) GetValidatedMetadata() (ResourceMeta, error) {
 
 // MatchesAnnotationSelector implements ifc.Kunstructured.
 func (rn *RNode) MatchesAnnotationSelector(selector string) (bool, error) {
-	s, err := labels.Parse(selector)
+	s, err := ParseLabelSelector(selector)
 	if err != nil {
 		return false, err
 	}
@@ -731,12 +730,12 @@ func (rn *RNode) MatchesAnnotationSelector(selector string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return s.Matches(labels.Set(slice)), nil
+	return s.Matches(slice), nil
 }
 
 // MatchesLabelSelector implements ifc.Kunstructured.
 func (rn *RNode) MatchesLabelSelector(selector string) (bool, error) {
-	s, err := labels.Parse(selector)
+	s, err := ParseLabelSelector(selector)
 	if err != nil {
 		return false, err
 	}
@@ -744,7 +743,7 @@ func (rn *RNode) MatchesLabelSelector(selector string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return s.Matches(labels.Set(slice)), nil
+	return s.Matches(slice), nil
 }
 
 // HasNilEntryInList returns true if the RNode contains a list which has
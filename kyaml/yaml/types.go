@@ -234,4 +234,12 @@ type MergeOptions struct {
 	// ListIncreaseDirection indicates should merge function prepend the items from
 	// source list to destination or append.
 	ListIncreaseDirection MergeOptionsListIncreaseDirection
+
+	// DeleteListElementsByName, if true, merges by the name
+	// of their elements the lists that have no schema, e.g.
+	// those of resources of unknown kinds, when the patch
+	// deletes elements of them with $patch: delete, so only
+	// those are deleted.  Otherwise such lists are replaced
+	// whole.  Every element of such a list must have a name.
+	DeleteListElementsByName bool
 }
@@ -88,6 +88,7 @@ func (l *Walker) setPrimitiveSequenceElements(values []string, key string, dest
 			InferAssociativeLists: l.InferAssociativeLists,
 			Visitor:               l,
 			Schema:                schema,
+			Schemas:               l.Schemas,
 			Sources:               l.elementValue(key, value),
 			MergeOptions:          l.MergeOptions,
 		}.Walk()
@@ -181,6 +182,7 @@ func (l *Walker) setAssociativeSequenceElements(values [][]string, keys []string
 			InferAssociativeLists: l.InferAssociativeLists,
 			Visitor:               l,
 			Schema:                schema,
+			Schemas:               l.Schemas,
 			Sources:               l.elementValueList(validKeys, validValues),
 			MergeOptions:          l.MergeOptions,
 		}.Walk()
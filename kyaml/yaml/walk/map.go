@@ -67,6 +67,7 @@ func (l Walker) walkMap() (*yaml.RNode, error) {
 			InferAssociativeLists: l.InferAssociativeLists,
 			Visitor:               l,
 			Schema:                s,
+			Schemas:               l.Schemas,
 			Sources:               fv,
 			MergeOptions:          l.MergeOptions,
 			Path:                  append(l.Path, key)}.Walk()
@@ -113,7 +114,7 @@ func (l Walker) valueIfPresent(node *yaml.MapNode) (*yaml.RNode, *openapi.Resour
 	if err = fm.Read(node.Value); err == nil {
 		s = &openapi.ResourceSchema{Schema: &fm.Schema}
 		if fm.Schema.Ref.String() != "" {
-			r, err := openapi.Resolve(&fm.Schema.Ref, openapi.Schema())
+			r, err := openapi.Resolve(&fm.Schema.Ref, l.rootSchema())
 			if err == nil && r != nil {
 				s.Schema = r
 			}
@@ -127,7 +128,7 @@ func (l Walker) valueIfPresent(node *yaml.MapNode) (*yaml.RNode, *openapi.Resour
 			s = &openapi.ResourceSchema{Schema: &fm.Schema}
 		}
 		if fm.Schema.Ref.String() != "" {
-			r, err := openapi.Resolve(&fm.Schema.Ref, openapi.Schema())
+			r, err := openapi.Resolve(&fm.Schema.Ref, l.rootSchema())
 			if err == nil && r != nil {
 				s.Schema = r
 			}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/go-openapi/spec"
 	"sigs.k8s.io/kustomize/kyaml/fieldmeta"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -21,6 +22,10 @@ type Walker struct {
 
 	Schema *openapi.ResourceSchema
 
+	// Schemas, if set, are the definitions to find the
+	// schemas of resources in, rather than the global ones.
+	Schemas *openapi.Schemas
+
 	// Source is the RNode to walk.  All Source fields and associative list elements
 	// will be visited.
 	Sources Sources
@@ -69,7 +74,10 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 		}
 		// AssociativeSequence means the items in the sequence are associative. They can be merged
 		// according to merge key.
-		if schema.IsAssociative(l.Schema, l.Sources, l.InferAssociativeLists) {
+		infer := l.InferAssociativeLists ||
+			(l.MergeOptions.DeleteListElementsByName &&
+				deletesElements(l.Sources.Origin()))
+		if schema.IsAssociative(l.Schema, l.Sources, infer) {
 			return l.walkAssociativeSequence()
 		}
 		return l.walkNonAssociativeSequence()
@@ -87,6 +95,21 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 	}
 }
 
+// deletesElements returns true if the given list, of a
+// patch, has an element with the directive $patch: delete.
+func deletesElements(list *yaml.RNode) bool {
+	if yaml.IsMissingOrNull(list) {
+		return false
+	}
+	for _, elem := range list.Content() {
+		d := yaml.NewRNode(elem).Field("$patch")
+		if d != nil && d.Value.YNode().Value == "delete" {
+			return true
+		}
+	}
+	return false
+}
+
 func (l Walker) GetSchema() *openapi.ResourceSchema {
 	for i := range l.Sources {
 		r := l.Sources[i]
@@ -99,7 +122,7 @@ func (l Walker) GetSchema() *openapi.ResourceSchema {
 			// per-field schema, this is fine
 			if fm.Schema.Ref.String() != "" {
 				// resolve the reference
-				s, err := openapi.Resolve(&fm.Schema.Ref, openapi.Schema())
+				s, err := openapi.Resolve(&fm.Schema.Ref, l.rootSchema())
 				if err == nil && s != nil {
 					fm.Schema = *s
 				}
@@ -122,7 +145,13 @@ func (l Walker) GetSchema() *openapi.ResourceSchema {
 			continue
 		}
 
-		s := openapi.SchemaForResourceType(yaml.TypeMeta{Kind: m.Kind, APIVersion: m.APIVersion})
+		t := yaml.TypeMeta{Kind: m.Kind, APIVersion: m.APIVersion}
+		var s *openapi.ResourceSchema
+		if l.Schemas != nil {
+			s = l.Schemas.SchemaForResourceType(t)
+		} else {
+			s = openapi.SchemaForResourceType(t)
+		}
 		if s != nil {
 			return s
 		}
@@ -130,6 +159,15 @@ func (l Walker) GetSchema() *openapi.ResourceSchema {
 	return nil
 }
 
+// rootSchema returns the schema that references in field
+// comments resolve against.
+func (l Walker) rootSchema() *spec.Schema {
+	if l.Schemas != nil {
+		return l.Schemas.Schema()
+	}
+	return openapi.Schema()
+}
+
 const (
 	DestIndex = iota
 	OriginIndex
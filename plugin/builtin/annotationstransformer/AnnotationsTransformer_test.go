@@ -4,6 +4,7 @@
 package main_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -52,3 +53,40 @@ func TestAnnotationsTransformer(t *testing.T) {
 
 	th.RunTransformerAndCheckResult(config, input, expectedOutput)
 }
+
+func TestAnnotationsTransformerStrict(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("AnnotationsTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: AnnotationsTransformer
+metadata:
+  name: notImportantHere
+annotations:
+  owner: team-a
+fieldSpecs:
+- path: metadata/annotations
+  create: true
+metadataValidation:
+  strict: true
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: ns
+  annotations:
+    Example.com/note: hi
+`, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			`annotation "Example.com/note" of ConfigMap ns/cm: `+
+				`key prefix "Example.com" must be a lowercase DNS subdomain`) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
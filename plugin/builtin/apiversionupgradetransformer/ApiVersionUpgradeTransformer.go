@@ -0,0 +1,76 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/apiversionupgrade"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Upgrade resources using deprecated API versions to their
+// replacements, per apiversionupgrade.DefaultMigrations.
+type plugin struct {
+	// Target selects the resources to upgrade;
+	// all resources if omitted.
+	Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// FailOnDeprecated reports resources using deprecated
+	// API versions as errors instead of upgrading them.
+	FailOnDeprecated bool `json:"failOnDeprecated,omitempty" yaml:"failOnDeprecated,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) error {
+	p.Target = nil
+	p.FailOnDeprecated = false
+	return yaml.Unmarshal(c, p)
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	if p.Target != nil {
+		var err error
+		resources, err = m.Select(*p.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		migration, found := apiversionupgrade.Find(
+			apiversionupgrade.DefaultMigrations, r.GetGvk())
+		if !found {
+			continue
+		}
+		if migration.Unsupported {
+			return fmt.Errorf(
+				"unsupported migration of %s '%s' from %s to %s",
+				r.GetKind(), r.GetName(),
+				migration.From.ApiVersion(), migration.To.ApiVersion())
+		}
+		if p.FailOnDeprecated {
+			return fmt.Errorf(
+				"%s '%s' uses deprecated %s; upgrade it to %s",
+				r.GetKind(), r.GetName(),
+				migration.From.ApiVersion(), migration.To.ApiVersion())
+		}
+		// The Gvk, and so the CurId, comes from the object,
+		// so later transformers see the upgraded Gvk.
+		err := m.Rename(r.CurId(), func(r *resource.Resource) error {
+			return r.ApplyFilter(apiversionupgrade.Filter{Migration: migration})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
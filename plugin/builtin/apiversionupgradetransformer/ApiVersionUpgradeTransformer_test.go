@@ -0,0 +1,157 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const deprecatedResources = `
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestApiVersionUpgradeTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ApiVersionUpgradeTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: notImportantHere
+`, deprecatedResources)
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	if rm.GetByIndex(0).CurId().Group != "apps" {
+		t.Fatalf("CurId not upgraded: %s", rm.GetByIndex(0).CurId())
+	}
+}
+
+func TestApiVersionUpgradeTransformerTarget(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ApiVersionUpgradeTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: notImportantHere
+target:
+  kind: PodDisruptionBudget
+`, deprecatedResources, `
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func TestApiVersionUpgradeTransformerFail(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ApiVersionUpgradeTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: notImportantHere
+failOnDeprecated: true
+`, deprecatedResources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"Deployment 'web' uses deprecated extensions/v1beta1; upgrade it to apps/v1") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestApiVersionUpgradeTransformerUnsupported(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ApiVersionUpgradeTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: ApiVersionUpgradeTransformer
+metadata:
+  name: notImportantHere
+`, `
+apiVersion: autoscaling/v2beta1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+`, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"unsupported migration of HorizontalPodAutoscaler 'web' "+
+				"from autoscaling/v2beta1 to autoscaling/v2") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
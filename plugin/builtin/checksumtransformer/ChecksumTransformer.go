@@ -0,0 +1,98 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/checksum"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotate workloads with a checksum of the ConfigMaps and
+// Secrets they refer to, so that changing the content of
+// these objects triggers a rollout.
+//
+// Run this before the hash suffixes are added to generated
+// names (e.g. as an entry of a kustomization's transformers
+// field); the checksum is then the same whether or not the
+// referenced objects get a name suffix.
+type plugin struct {
+	// AnnotationName is the annotation to set; defaults
+	// to checksum.DefaultAnnotationName.
+	AnnotationName string `json:"annotationName,omitempty" yaml:"annotationName,omitempty"`
+
+	// Target selects the workloads to annotate;
+	// all workloads if omitted.
+	Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// FieldSpecs locate the annotations to update;
+	// defaults to the pod template annotations.
+	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	hasher     ifc.KunstructuredHasher
+	references map[string]types.FsSlice
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.AnnotationName = ""
+	p.Target = nil
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.FieldSpecs) == 0 {
+		p.FieldSpecs = checksum.DefaultFsSlice
+	}
+	p.hasher = h.ResmapFactory().RF().Hasher()
+	p.references, err = checksum.DefaultReferenceFsSlices()
+	return err
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	// Hashes of the referable objects, by namespace.
+	hashes := make(map[string]map[string]string)
+	for _, r := range m.Resources() {
+		for _, k := range checksum.ReferableKinds {
+			if r.GetKind() != k {
+				continue
+			}
+			h, err := p.hasher.Hash(r)
+			if err != nil {
+				return err
+			}
+			ns := r.CurId().EffectiveNamespace()
+			if hashes[ns] == nil {
+				hashes[ns] = make(map[string]string)
+			}
+			hashes[ns][checksum.RefKey(k, r.GetName())] = h
+		}
+	}
+	targets := m.Resources()
+	if p.Target != nil {
+		var err error
+		targets, err = m.Select(*p.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range targets {
+		err := r.ApplyFilter(checksum.Filter{
+			AnnotationName: p.AnnotationName,
+			Hashes:         hashes[r.CurId().EffectiveNamespace()],
+			References:     p.references,
+			FsSlice:        p.FieldSpecs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
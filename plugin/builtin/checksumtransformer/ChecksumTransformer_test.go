@@ -0,0 +1,138 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: other
+data:
+  mode: slow
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    tier: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - configMapRef:
+            name: app-config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker
+`
+
+func TestChecksumTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ChecksumTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ChecksumTransformer
+metadata:
+  name: notImportantHere
+annotationName: checksum/config
+`, resources)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: app-config
+---
+apiVersion: v1
+data:
+  mode: slow
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: other
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: d9239904e6ae319269e8bd6e4d358fa435ba7182227b8634d7fd81f5920bd599
+    spec:
+      containers:
+      - envFrom:
+        - configMapRef:
+            name: app-config
+        image: app
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      containers:
+      - image: worker
+        name: worker
+`)
+}
+
+func TestChecksumTransformerWithTarget(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ChecksumTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ChecksumTransformer
+metadata:
+  name: notImportantHere
+target:
+  labelSelector: tier!=web
+`, resources)
+
+	// The worker is selected, but refers to nothing.
+	for _, r := range rm.Resources() {
+		if _, err := r.GetFieldValue(
+			"spec.template.metadata.annotations"); err == nil {
+			t.Fatalf("unexpected annotation on %s", r.CurId())
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/cleansource"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Remove server-populated fields, e.g. metadata.managedFields
+// and status, from objects exported from a live cluster.
+type plugin struct {
+	// Profile is "export" (the default) or "minimal".
+	Profile cleansource.CleanProfile `json:"profile,omitempty" yaml:"profile,omitempty"`
+
+	// Paths are removed in addition to those of the profile.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+
+	// KeepStatus selects resources whose status is source
+	// content, e.g. some custom resources, and must be kept.
+	KeepStatus []types.Selector `json:"keepStatus,omitempty" yaml:"keepStatus,omitempty"`
+
+	paths []string
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Profile = ""
+	p.Paths = nil
+	p.KeepStatus = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if p.Profile == "" {
+		p.Profile = cleansource.ProfileExport
+	}
+	p.paths, err = p.Profile.Paths()
+	if err != nil {
+		return err
+	}
+	p.paths = append(p.paths, p.Paths...)
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	keep := make(map[*resource.Resource]bool)
+	for _, s := range p.KeepStatus {
+		selected, err := m.Select(s)
+		if err != nil {
+			return err
+		}
+		for _, r := range selected {
+			keep[r] = true
+		}
+	}
+	var withoutStatus []string
+	for _, path := range p.paths {
+		if path != cleansource.StatusPath {
+			withoutStatus = append(withoutStatus, path)
+		}
+	}
+	for _, r := range m.Resources() {
+		paths := p.paths
+		if keep[r] {
+			paths = withoutStatus
+		}
+		if err := r.ApplyFilter(cleansource.Filter{Paths: paths}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
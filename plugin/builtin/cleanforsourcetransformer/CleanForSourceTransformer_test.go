@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const liveResources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  uid: 0b4e3f2a-1c1d-4e5f-8a9b-0c1d2e3f4a5b
+  resourceVersion: "12345"
+  managedFields:
+  - manager: kubectl
+    operation: Update
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1"}
+    team: web
+spec:
+  replicas: 2
+status:
+  replicas: 2
+---
+apiVersion: example.com/v1
+kind: Inventory
+metadata:
+  name: inv
+  uid: 1b4e3f2a-1c1d-4e5f-8a9b-0c1d2e3f4a5b
+status:
+  items: 3
+`
+
+func TestCleanForSourceTransformerExport(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("CleanForSourceTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: CleanForSourceTransformer
+metadata:
+  name: notImportantHere
+paths:
+- metadata/annotations/team
+keepStatus:
+- group: example.com
+  kind: Inventory
+`, liveResources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+---
+apiVersion: example.com/v1
+kind: Inventory
+metadata:
+  name: inv
+status:
+  items: 3
+`)
+}
+
+func TestCleanForSourceTransformerMinimal(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("CleanForSourceTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: CleanForSourceTransformer
+metadata:
+  name: notImportantHere
+profile: minimal
+`, liveResources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: |
+      {"apiVersion":"apps/v1"}
+    team: web
+  name: web
+spec:
+  replicas: 2
+status:
+  replicas: 2
+---
+apiVersion: example.com/v1
+kind: Inventory
+metadata:
+  name: inv
+status:
+  items: 3
+`)
+}
+
+func TestCleanForSourceTransformerBadProfile(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("CleanForSourceTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: CleanForSourceTransformer
+metadata:
+  name: notImportantHere
+profile: everything
+`, liveResources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(), `unknown clean profile "everything"`) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
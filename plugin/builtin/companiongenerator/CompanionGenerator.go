@@ -0,0 +1,138 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Generate a companion resource, e.g. a ServiceMonitor or a
+// NetworkPolicy, for each of the resources accumulated so far
+// that's selected by Target.
+//
+// The companion is made from Template, in whose string values
+//   $(TARGET_NAME) is replaced by the target's name,
+//   $(TARGET_NAMESPACE) by the target's namespace, and
+//   $(TARGET_LABEL:key) by the value of the target's label key;
+// it's an error if the target has no such label.
+type plugin struct {
+	// Target selects the resources to make companions for.
+	Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Template is the companion resource.
+	Template map[string]interface{} `json:"template,omitempty" yaml:"template,omitempty"`
+
+	h *resmap.PluginHelpers
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+var companionToken = regexp.MustCompile(
+	`\$\((TARGET_NAME|TARGET_NAMESPACE|TARGET_LABEL:([^)]*))\)`)
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.Target = nil
+	p.Template = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if p.Target == nil {
+		return fmt.Errorf("must specify a target for the companions")
+	}
+	if len(p.Template) == 0 {
+		return fmt.Errorf("must specify a template for the companions")
+	}
+	p.h = h
+	return nil
+}
+
+// Generate makes no companions, as there's nothing to select.
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	return p.GenerateWithContext(nil)
+}
+
+// GenerateWithContext implements resmap.ContextualGenerator.
+func (p *plugin) GenerateWithContext(
+	m resmap.ResMap) (resmap.ResMap, error) {
+	result := resmap.New()
+	if m == nil {
+		return result, nil
+	}
+	targets, err := m.Select(*p.Target)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		obj, err := substituteTokens(p.Template, t)
+		if err != nil {
+			return nil, err
+		}
+		r := p.h.ResmapFactory().RF().FromMap(
+			obj.(map[string]interface{}))
+		if err = result.Append(r); err != nil {
+			return nil, fmt.Errorf(
+				"companion of %s: %v", t.CurId(), err)
+		}
+	}
+	return result, nil
+}
+
+// substituteTokens returns a copy of v, with the
+// tokens in its strings resolved from the target t.
+func substituteTokens(
+	v interface{}, t *resource.Resource) (interface{}, error) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			y, err := substituteTokens(item, t)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = y
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i, item := range x {
+			y, err := substituteTokens(item, t)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = y
+		}
+		return result, nil
+	case string:
+		var err error
+		s := companionToken.ReplaceAllStringFunc(x, func(token string) string {
+			match := companionToken.FindStringSubmatch(token)
+			switch match[1] {
+			case "TARGET_NAME":
+				return t.GetName()
+			case "TARGET_NAMESPACE":
+				return t.GetNamespace()
+			}
+			value, found := t.GetLabels()[match[2]]
+			if !found && err == nil {
+				err = fmt.Errorf(
+					"cannot resolve %s in companion template; "+
+						"target %s has no label '%s'",
+					token, t.CurId(), match[2])
+			}
+			return value
+		})
+		return s, err
+	default:
+		return v, nil
+	}
+}
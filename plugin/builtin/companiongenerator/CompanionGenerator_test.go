@@ -0,0 +1,139 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/resmap"
+	ifctest_test "sigs.k8s.io/kustomize/api/testutils/ifctest"
+)
+
+const services = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+  labels:
+    monitoring: "on"
+    app: storefront
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+  namespace: shop
+  labels:
+    app: postgres
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    monitoring: "on"
+`
+
+func generateCompanions(
+	t *testing.T, config string) (resmap.ResMap, error) {
+	h := ifctest_test.NewPluginHelpers(
+		ifctest_test.NewFakeLoader("/app", nil),
+		ifctest_test.NewFakeValidator(), nil)
+	m, err := h.ResmapFactory().NewResMapFromBytes([]byte(services))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	p := builtins.NewCompanionGeneratorPlugin()
+	err = p.Config(h, []byte(config))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return p.(resmap.ContextualGenerator).GenerateWithContext(m)
+}
+
+func TestCompanionGenerator(t *testing.T) {
+	rm, err := generateCompanions(t, `
+apiVersion: builtin
+kind: CompanionGenerator
+metadata:
+  name: serviceMonitors
+target:
+  kind: Service
+  labelSelector: monitoring=on
+template:
+  apiVersion: monitoring.coreos.com/v1
+  kind: ServiceMonitor
+  metadata:
+    name: $(TARGET_NAME)-monitor
+    namespace: $(TARGET_NAMESPACE)
+  spec:
+    selector:
+      matchLabels:
+        app: $(TARGET_LABEL:app)
+    endpoints:
+    - port: metrics
+`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	yml, err := rm.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimLeft(`
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: web-monitor
+  namespace: shop
+spec:
+  endpoints:
+  - port: metrics
+  selector:
+    matchLabels:
+      app: storefront
+`, "\n"), string(yml))
+}
+
+func TestCompanionGeneratorMissingLabel(t *testing.T) {
+	_, err := generateCompanions(t, `
+apiVersion: builtin
+kind: CompanionGenerator
+metadata:
+  name: networkPolicies
+target:
+  kind: Service
+template:
+  apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata:
+    name: $(TARGET_NAME)
+  spec:
+    podSelector:
+      matchLabels:
+        tier: $(TARGET_LABEL:tier)
+`)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "$(TARGET_LABEL:tier)")
+		assert.Contains(t, err.Error(), "web")
+		assert.Contains(t, err.Error(), "has no label 'tier'")
+	}
+}
+
+func TestCompanionGeneratorNoTarget(t *testing.T) {
+	p := builtins.NewCompanionGeneratorPlugin()
+	err := p.Config(nil, []byte(`
+apiVersion: builtin
+kind: CompanionGenerator
+metadata:
+  name: noTarget
+template:
+  kind: ConfigMap
+`))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must specify a target")
+	}
+}
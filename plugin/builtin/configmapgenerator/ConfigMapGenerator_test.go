@@ -4,24 +4,17 @@
 package main_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
-	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/resmap"
+	ifctest_test "sigs.k8s.io/kustomize/api/testutils/ifctest"
 )
 
-func TestConfigMapGenerator(t *testing.T) {
-	th := kusttest_test.MakeEnhancedHarness(t).
-		PrepBuiltin("ConfigMapGenerator")
-	defer th.Reset()
-
-	th.WriteF("devops.env", `
-SERVICE_PORT=32
-`)
-	th.WriteF("uxteam.env", `
-COLOR=red
-`)
-
-	rm := th.LoadAndRunGenerator(`
+const configMapGeneratorConfig = `
 apiVersion: builtin
 kind: ConfigMapGenerator
 metadata:
@@ -32,9 +25,36 @@ envs:
 literals:
 - FRUIT=apple
 - VEGETABLE=carrot
-`)
+`
+
+func makeConfigMapGeneratorHelpers(
+	v *ifctest_test.FakeValidator) *resmap.PluginHelpers {
+	return ifctest_test.NewPluginHelpers(
+		ifctest_test.NewFakeLoader("/app", map[string]string{
+			"devops.env": `
+SERVICE_PORT=32
+`,
+			"uxteam.env": `
+COLOR=red
+`,
+		}), v, nil)
+}
 
-	th.AssertActualEqualsExpected(rm, `
+func TestConfigMapGenerator(t *testing.T) {
+	p := builtins.NewConfigMapGeneratorPlugin()
+	err := p.Config(
+		makeConfigMapGeneratorHelpers(ifctest_test.NewFakeValidator()),
+		[]byte(configMapGeneratorConfig))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rm, err := p.Generate()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	yml, err := rm.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimLeft(`
 apiVersion: v1
 data:
   COLOR: red
@@ -44,5 +64,21 @@ data:
 kind: ConfigMap
 metadata:
   name: myMap
-`)
+`, "\n"), string(yml))
+}
+
+func TestConfigMapGeneratorInvalidEnvVarName(t *testing.T) {
+	v := ifctest_test.NewFakeValidator().
+		Deny("IsEnvVarName", fmt.Errorf("not a valid env var name"))
+	p := builtins.NewConfigMapGeneratorPlugin()
+	err := p.Config(
+		makeConfigMapGeneratorHelpers(v), []byte(configMapGeneratorConfig))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = p.Generate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not a valid env var name")
+	}
+	assert.Equal(t, 1, v.Calls("IsEnvVarName"))
 }
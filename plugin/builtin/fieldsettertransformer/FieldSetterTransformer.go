@@ -0,0 +1,91 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Set a field of the resources the target selects to the
+// given value, e.g. spec.replicas of every Deployment
+// labelled tier=web, without writing a patch per resource.
+type plugin struct {
+	// Target selects the resources to change; without
+	// one, every resource is changed.
+	Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// FieldPath is the path of the field, in the syntax of
+	// Resource.GetFieldValue, e.g. spec.replicas or
+	// spec.template.spec.containers[name=app].image.
+	FieldPath string `json:"fieldPath,omitempty" yaml:"fieldPath,omitempty"`
+
+	// Value is the value to give the field: a scalar, a
+	// map or a list.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Create, if true, creates the maps missing above the
+	// field.  Otherwise, a resource missing one is left
+	// as it is.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Target = nil
+	p.FieldPath = ""
+	p.Value = nil
+	p.Create = false
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if p.FieldPath == "" {
+		return fmt.Errorf("must specify fieldPath in\n%s", string(c))
+	}
+	if p.Value == nil {
+		return fmt.Errorf("must specify value in\n%s", string(c))
+	}
+	if p.Target != nil {
+		// Report a bad selector now, rather than on the
+		// first transform.
+		if _, err = resmap.NewSelectorMatcher(*p.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	resources := m.Resources()
+	if p.Target != nil {
+		var err error
+		resources, err = m.Select(*p.Target)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		if err := p.set(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) set(r *resource.Resource) error {
+	id := r.CurId()
+	if err := r.SetFieldValue(p.FieldPath, p.Value, p.Create); err != nil {
+		return fmt.Errorf(
+			"setting field '%s' of %s: %v", p.FieldPath, id, err)
+	}
+	return nil
+}
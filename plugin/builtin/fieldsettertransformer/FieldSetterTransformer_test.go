@@ -0,0 +1,186 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    tier: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+`
+
+func TestFieldSetterTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldSetterTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: notImportantHere
+target:
+  labelSelector: tier=web
+fieldPath: spec.template.spec.containers[name=app].resources
+value:
+  limits:
+    cpu: 500m
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: app:1
+        name: app
+        resources:
+          limits:
+            cpu: 500m
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+`)
+}
+
+func TestFieldSetterTransformerCreate(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldSetterTransformer")
+	defer th.Reset()
+
+	// Without create, the worker, lacking a spec, is
+	// left as it is.
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: notImportantHere
+fieldPath: spec.replicas
+value: 3
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: app:1
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+`)
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: notImportantHere
+target:
+  name: worker
+fieldPath: spec.replicas
+value: 3
+create: true
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: app:1
+        name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  replicas: 3
+`)
+}
+
+func TestFieldSetterTransformerIntoScalar(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldSetterTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: notImportantHere
+target:
+  name: web
+fieldPath: spec.replicas.count
+value: 3
+create: true
+`, resources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+		if !strings.Contains(err.Error(),
+			"setting field 'spec.replicas.count' of apps_v1_Deployment|~X|web") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestFieldSetterTransformerNoValue(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("FieldSetterTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: FieldSetterTransformer
+metadata:
+  name: notImportantHere
+fieldPath: spec.replicas
+`, resources, func(t *testing.T, err error) {
+		if err == nil || !strings.Contains(err.Error(), "must specify value") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -9,4 +9,6 @@ require (
 	sigs.k8s.io/yaml v1.2.0
 )
 
+replace sigs.k8s.io/kustomize/kyaml => ../../../kyaml
+
 replace sigs.k8s.io/kustomize/api => ../../../api
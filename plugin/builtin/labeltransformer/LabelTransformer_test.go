@@ -4,6 +4,7 @@
 package main_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -119,3 +120,58 @@ spec:
         name: nginx
 `)
 }
+
+func TestLabelTransformerChecksLabels(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("LabelTransformer")
+	defer th.Reset()
+
+	const input = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: myService
+`
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: notImportantHere
+labels:
+  release: very-long-release-name-made-by-a-pipeline-from-a-branch-name-and-a-sha
+fieldSpecs:
+- path: metadata/labels
+  create: true
+`, input, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			`label "release" of Service myService: `+
+				"value is 70 characters, over the limit of 63") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: LabelTransformer
+metadata:
+  name: notImportantHere
+labels:
+  release: very-long-release-name-made-by-a-pipeline-from-a-branch-name-and-a-sha
+fieldSpecs:
+- path: metadata/labels
+  create: true
+metadataValidation:
+  ignoreKeys:
+  - release
+`, input, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    release: very-long-release-name-made-by-a-pipeline-from-a-branch-name-and-a-sha
+  name: myService
+`)
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/filters/namespaceprefixsuffix"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Add the given prefix and suffix to the names of the
+// Namespaces in the build, and to the fields naming
+// them, e.g. the namespaces of resources and the
+// namespaces of the subjects of role bindings.
+type plugin struct {
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+
+	// IncludeExternal, if true, changes the names of the
+	// namespaces the build doesn't create as well.
+	IncludeExternal bool `json:"includeExternal,omitempty" yaml:"includeExternal,omitempty"`
+
+	// FieldSpecs locate the fields naming namespaces;
+	// defaults to namespaceprefixsuffix.DefaultFsSlice.
+	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Prefix = ""
+	p.Suffix = ""
+	p.IncludeExternal = false
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.FieldSpecs) == 0 {
+		p.FieldSpecs, err = namespaceprefixsuffix.DefaultFsSlice()
+	}
+	return err
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	if p.Prefix == "" && p.Suffix == "" {
+		return nil
+	}
+	var namespaces []string
+	for _, r := range m.Resources() {
+		if isNamespace(r) {
+			namespaces = append(namespaces, r.GetName())
+		}
+	}
+	f := namespaceprefixsuffix.Filter{
+		Prefix:          p.Prefix,
+		Suffix:          p.Suffix,
+		Namespaces:      namespaces,
+		IncludeExternal: p.IncludeExternal,
+		FsSlice:         p.FieldSpecs,
+	}
+	resources := m.Resources()
+	// Rename resources in longer namespaces first, so that
+	// a new namespace never collides with the old one of a
+	// resource yet to be renamed.
+	sort.SliceStable(resources, func(i, j int) bool {
+		return len(namespaceOf(resources[i])) > len(namespaceOf(resources[j]))
+	})
+	for _, r := range resources {
+		if r.IsEmpty() {
+			continue
+		}
+		err := m.Rename(r.CurId(), func(r *resource.Resource) error {
+			var err error
+			if isNamespace(r) {
+				err = r.SetOriginalNameE(r.GetName(), false)
+			} else {
+				err = r.SetOriginalNsE(r.GetNamespace(), false)
+			}
+			if err != nil {
+				return err
+			}
+			return r.ApplyFilter(f)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespaceOf returns the name of a Namespace, or
+// else the namespace of a resource.
+func namespaceOf(r *resource.Resource) string {
+	if isNamespace(r) {
+		return r.GetName()
+	}
+	return r.GetNamespace()
+}
+
+func isNamespace(r *resource.Resource) bool {
+	gvk := r.GetGvk()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+  namespace: app
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app
+- kind: ServiceAccount
+  name: monitor
+  namespace: monitoring
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: monitoring
+`
+
+func TestNamespacePrefixSuffixTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NamespacePrefixSuffixTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: NamespacePrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+suffix: -staging
+`, resources, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app-staging
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+  namespace: app-staging
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: app-staging
+- kind: ServiceAccount
+  name: monitor
+  namespace: monitoring
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: monitoring
+`)
+}
+
+func TestNamespacePrefixSuffixTransformerIncludeExternal(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NamespacePrefixSuffixTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: NamespacePrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefix: t1-
+includeExternal: true
+`, resources, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: t1-app
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: reader
+  namespace: t1-app
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: read
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: reader
+  namespace: t1-app
+- kind: ServiceAccount
+  name: monitor
+  namespace: t1-monitoring
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: t1-monitoring
+`)
+}
@@ -5,10 +5,9 @@
 package main
 
 import (
-	"fmt"
-
 	"sigs.k8s.io/kustomize/api/filters/namespace"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -17,15 +16,18 @@ import (
 type plugin struct {
 	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 	FieldSpecs       []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	summaries *resmap.ChangeSummaries
 }
 
 //noinspection GoUnusedGlobalVariable
 var KustomizePlugin plugin
 
 func (p *plugin) Config(
-	_ *resmap.PluginHelpers, c []byte) (err error) {
+	h *resmap.PluginHelpers, c []byte) (err error) {
 	p.Namespace = ""
 	p.FieldSpecs = nil
+	p.summaries = h.ResmapFactory().ChangeSummaries()
 	return yaml.Unmarshal(c, p)
 }
 
@@ -33,24 +35,38 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	if len(p.Namespace) == 0 {
 		return nil
 	}
+	return p.summaries.Summarize("NamespaceTransformer", m.Resources(),
+		namespaceKey, func() error { return p.transform(m) })
+}
+
+func (p *plugin) transform(m resmap.ResMap) error {
 	for _, r := range m.Resources() {
 		if r.IsEmpty() {
 			// Don't mutate empty objects?
 			continue
 		}
-		r.SetOriginalNs(r.GetNamespace(), false)
-		err := r.ApplyFilter(namespace.Filter{
-			Namespace: p.Namespace,
-			FsSlice:   p.FieldSpecs,
+		err := m.Rename(r.CurId(), func(r *resource.Resource) error {
+			if err := r.SetOriginalNsE(r.GetNamespace(), false); err != nil {
+				return err
+			}
+			return r.ApplyFilter(namespace.Filter{
+				Namespace: p.Namespace,
+				FsSlice:   p.FieldSpecs,
+				Schemas:   r.OpenAPISchemas(),
+			})
 		})
 		if err != nil {
 			return err
 		}
-		matches := m.GetMatchingResourcesByCurrentId(r.CurId().Equals)
-		if len(matches) != 1 {
-			return fmt.Errorf(
-				"namespace transformation produces ID conflict: %+v", matches)
-		}
+	}
+	return nil
+}
+
+// namespaceKey maps "namespace" to the namespace of
+// the resource, if it has one.
+func namespaceKey(r *resource.Resource) map[string]string {
+	if ns := r.GetNamespace(); ns != "" {
+		return map[string]string{"namespace": ns}
 	}
 	return nil
 }
@@ -0,0 +1,51 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/normalizelists"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+// Sort lists whose order Kubernetes ignores, e.g. env
+// vars and volume mounts, so that the output is canonical.
+// List it in the transformers of the top kustomization,
+// so that it runs after all patches.
+type plugin struct {
+	// Lists are the lists to sort, replacing the default
+	// ones, normalizelists.DefaultLists, if not empty.
+	Lists []normalizelists.List `json:"lists,omitempty" yaml:"lists,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Lists = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if len(p.Lists) == 0 {
+		p.Lists = normalizelists.DefaultLists()
+	}
+	for _, l := range p.Lists {
+		if err = l.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	f := normalizelists.Filter{Lists: p.Lists}
+	for _, r := range m.Resources() {
+		if err := r.ApplyFilter(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const pod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: web
+    args:
+    - --verbose
+    - --port=80
+    env:
+    - name: B
+      value: b
+    - name: A
+      value: a
+  volumes:
+  - name: data
+  - name: cache
+`
+
+func TestNormalizeListsTransformerDefaults(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NormalizeListsTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: NormalizeListsTransformer
+metadata:
+  name: notImportantHere
+`, pod, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - args:
+    - --verbose
+    - --port=80
+    env:
+    - name: A
+      value: a
+    - name: B
+      value: b
+    image: web
+    name: web
+  volumes:
+  - name: cache
+  - name: data
+`)
+}
+
+func TestNormalizeListsTransformerLists(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NormalizeListsTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: NormalizeListsTransformer
+metadata:
+  name: notImportantHere
+lists:
+- kind: Pod
+  path: spec/containers/args
+  force: true
+`, pod, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - args:
+    - --port=80
+    - --verbose
+    env:
+    - name: B
+      value: b
+    - name: A
+      value: a
+    image: web
+    name: web
+  volumes:
+  - name: data
+  - name: cache
+`)
+}
+
+func TestNormalizeListsTransformerOrderedList(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("NormalizeListsTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: NormalizeListsTransformer
+metadata:
+  name: notImportantHere
+lists:
+- kind: Pod
+  path: spec/containers
+  sortKeys:
+  - name
+`, pod, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"the order of containers in spec/containers is meaningful") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
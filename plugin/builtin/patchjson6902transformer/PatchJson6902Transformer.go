@@ -11,7 +11,9 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/filters/patchjson6902"
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -19,9 +21,13 @@ import (
 type plugin struct {
 	ldr          ifc.Loader
 	decodedPatch jsonpatch.Patch
+	provenance   *resmap.ProvenanceTable
+	source       resmap.Source
+	matcher      *resmap.Matcher
 	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
 	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
 	JsonOp       string          `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+	Options      map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -37,9 +43,25 @@ func (p *plugin) Config(
 	if p.Target.Name == "" {
 		return fmt.Errorf("must specify the target name")
 	}
+	// As for PatchTransformer, strictGvk makes the
+	// Target's group, version and kind exact.
+	opts := resid.GvkMatchOptions{ExactGvk: p.Options["strictGvk"]}
+	if opts.ExactGvk && (p.Target.Version == "" || p.Target.Kind == "") {
+		return fmt.Errorf(
+			"the strictGvk option needs a target with a version and kind")
+	}
+	p.matcher, err = resmap.NewSelectorMatcherWithOptions(*p.Target, opts)
+	if err != nil {
+		return err
+	}
 	if p.Path == "" && p.JsonOp == "" {
 		return fmt.Errorf("empty file path and empty jsonOp")
 	}
+	p.provenance = h.ResmapFactory().ProvenanceTable()
+	p.source = resmap.Source{
+		PatchFile:         p.Path,
+		KustomizationRoot: p.ldr.Root(),
+	}
 	if p.Path != "" {
 		if p.JsonOp != "" {
 			return fmt.Errorf("must specify a file path or jsonOp, not both")
@@ -77,14 +99,17 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	if p.Target == nil {
 		return fmt.Errorf("must specify a target for patch %s", p.JsonOp)
 	}
-	resources, err := m.Select(*p.Target)
-	if err != nil {
+	selected := m.SelectMatching(p.matcher)
+	if err := resmap.ErrIfVersionAmbiguous(selected); err != nil {
 		return err
 	}
-	for _, res := range resources {
-		err = res.ApplyFilter(patchjson6902.Filter{
-			Patch: p.JsonOp,
-		})
+	for _, res := range selected {
+		err := p.provenance.RecordChanges(
+			[]*resource.Resource{res}, p.source, func() error {
+				return res.ApplyFilter(patchjson6902.Filter{
+					Patch: p.JsonOp,
+				})
+			})
 		if err != nil {
 			return err
 		}
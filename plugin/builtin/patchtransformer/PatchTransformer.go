@@ -10,18 +10,26 @@ import (
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"sigs.k8s.io/kustomize/api/filters/patchjson6902"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/yaml"
 )
 
 type plugin struct {
 	loadedPatch  *resource.Resource
 	decodedPatch jsonpatch.Patch
+	provenance   *resmap.ProvenanceTable
+	conflicts    *resmap.PatchConflicts
+	source       resmap.Source
+	matcher      *resmap.Matcher
+	normalized   *resmap.Matcher
 	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
 	Patch        string          `json:"patch,omitempty" yaml:"patch,omitempty"`
 	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Options      map[string]bool `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -42,6 +50,15 @@ func (p *plugin) Config(
 		return fmt.Errorf(
 			"patch and path can't be set at the same time\n%s", string(c))
 	}
+	p.provenance = h.ResmapFactory().ProvenanceTable()
+	p.conflicts = h.ResmapFactory().PatchConflicts()
+	p.source = resmap.Source{
+		PatchFile:         p.Path,
+		KustomizationRoot: h.Loader().Root(),
+	}
+	if err = p.compileTarget(); err != nil {
+		return err
+	}
 	if p.Path != "" {
 		loaded, loadErr := h.Loader().Load(p.Path)
 		if loadErr != nil {
@@ -52,6 +69,11 @@ func (p *plugin) Config(
 
 	patchSM, errSM := h.ResmapFactory().RF().FromBytes([]byte(p.Patch))
 	patchJson, errJson := jsonPatchFromBytes([]byte(p.Patch))
+	if errSM != nil && errJson != nil && p.Target != nil {
+		// A strategic merge patch applied to a target needn't
+		// have a name, as each match supplies its own.
+		patchSM, errSM = namelessSmPatchFromBytes(h, []byte(p.Patch))
+	}
 	if (errSM == nil && errJson == nil) ||
 		(patchSM != nil && patchJson != nil) {
 		return fmt.Errorf(
@@ -71,47 +93,78 @@ func (p *plugin) Config(
 }
 
 func (p *plugin) Transform(m resmap.ResMap) error {
-	if p.loadedPatch == nil {
-		return p.transformJson6902(m, p.decodedPatch)
-	} else {
-		// The patch was a strategic merge patch
-		return p.transformStrategicMerge(m, p.loadedPatch)
+	targets, err := p.Targets(m)
+	if err != nil {
+		return err
 	}
+	return p.TransformTargets(m, targets)
 }
 
-// transformStrategicMerge applies the provided strategic merge patch
-// to all the resources in the ResMap that match either the Target or
-// the identifier of the patch.
-func (p *plugin) transformStrategicMerge(m resmap.ResMap, patch *resource.Resource) error {
-	if p.Target == nil {
-		target, err := m.GetById(patch.OrgId())
-		if err != nil {
-			return err
-		}
-		return target.ApplySmPatch(patch)
+// Targets returns the resources of the ResMap that the
+// patch applies to: those the Target selects, or, for a
+// strategic merge patch without a Target, the one with the
+// patch's id.
+func (p *plugin) Targets(m resmap.ResMap) ([]*resource.Resource, error) {
+	if p.Target != nil {
+		return p.selectTargets(m)
+	}
+	if p.loadedPatch == nil {
+		return nil, fmt.Errorf("must specify a target for patch %s", p.Patch)
 	}
-	selected, err := m.Select(*p.Target)
+	target, err := m.GetById(p.loadedPatch.OrgId())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return m.ApplySmPatch(resource.MakeIdSet(selected), patch)
+	return []*resource.Resource{target}, nil
 }
 
-// transformJson6902 applies the provided json6902 patch
-// to all the resources in the ResMap that match the Target.
-func (p *plugin) transformJson6902(m resmap.ResMap, patch jsonpatch.Patch) error {
-	if p.Target == nil {
-		return fmt.Errorf("must specify a target for patch %s", p.Patch)
+// TransformTargets applies the patch to the given resources
+// of the ResMap, e.g. those Targets returned before other
+// patches changed the ResMap.
+func (p *plugin) TransformTargets(
+	m resmap.ResMap, targets []*resource.Resource) error {
+	if p.loadedPatch == nil {
+		return p.transformJson6902(targets)
 	}
-	resources, err := m.Select(*p.Target)
-	if err != nil {
+	// The patch was a strategic merge patch
+	return p.transformStrategicMerge(m, targets, p.loadedPatch)
+}
+
+// transformStrategicMerge applies the provided strategic
+// merge patch to the targets.
+func (p *plugin) transformStrategicMerge(
+	m resmap.ResMap, targets []*resource.Resource, patch *resource.Resource) error {
+	if err := p.conflicts.Check(targets, patch, p.source); err != nil {
 		return err
 	}
-	for _, res := range resources {
-		res.SetOriginalName(res.GetName(), false)
-		err = res.ApplyFilter(patchjson6902.Filter{
-			Patch: p.Patch,
+	if p.Target == nil {
+		return p.provenance.RecordChanges(targets, p.source, func() error {
+			for _, target := range targets {
+				if err := target.ApplySmPatch(patch); err != nil {
+					return err
+				}
+			}
+			return nil
 		})
+	}
+	return p.provenance.RecordChanges(targets, p.source, func() error {
+		return m.ApplySmPatch(resource.MakeIdSet(targets), patch)
+	})
+}
+
+// transformJson6902 applies the json6902 patch
+// to the targets.
+func (p *plugin) transformJson6902(targets []*resource.Resource) error {
+	for _, res := range targets {
+		if err := res.SetOriginalNameE(res.GetName(), false); err != nil {
+			return err
+		}
+		err := p.provenance.RecordChanges(
+			[]*resource.Resource{res}, p.source, func() error {
+				return res.ApplyFilter(patchjson6902.Filter{
+					Patch: p.Patch,
+				})
+			})
 		if err != nil {
 			return err
 		}
@@ -119,6 +172,90 @@ func (p *plugin) transformJson6902(m resmap.ResMap, patch jsonpatch.Patch) error
 	return nil
 }
 
+// compileTarget compiles the Target, if any, once, so
+// that a malformed one is reported before any matching.
+// With the strictGvk option, the Target's group, version
+// and kind are compared exactly, so it must give a
+// version and kind.  With the exactGroup option, only the
+// group is compared exactly, so "core" doesn't select the
+// empty group.
+func (p *plugin) compileTarget() (err error) {
+	p.matcher, p.normalized = nil, nil
+	if p.Target == nil {
+		return nil
+	}
+	opts := resid.GvkMatchOptions{
+		ExactGvk: p.Options["strictGvk"],
+		Strict:   p.Options["exactGroup"],
+	}
+	if opts.ExactGvk && (p.Target.Version == "" || p.Target.Kind == "") {
+		return fmt.Errorf(
+			"the strictGvk option needs a target with a version and kind")
+	}
+	p.matcher, err = resmap.NewSelectorMatcherWithOptions(*p.Target, opts)
+	if err != nil || !opts.Strict {
+		return err
+	}
+	// To explain what comparing groups exactly missed.
+	opts.Strict = false
+	p.normalized, err = resmap.NewSelectorMatcherWithOptions(*p.Target, opts)
+	return err
+}
+
+// selectTargets returns the resources selected by the Target.
+// Selecting resources that differ only by version is an error.
+// With the strict option, selecting nothing is an error.
+func (p *plugin) selectTargets(m resmap.ResMap) ([]*resource.Resource, error) {
+	selected := m.SelectMatching(p.matcher)
+	if len(selected) > 0 || !p.Options["strict"] {
+		return selected, resmap.ErrIfVersionAmbiguous(selected)
+	}
+	t, _ := yaml.Marshal(p.Target)
+	msg := fmt.Sprintf(
+		"strict patch target matches no resources:\n%s", string(t))
+	// Explain any matches lost by the exactGroup option.
+	if p.normalized != nil {
+		for _, r := range m.SelectMatching(p.normalized) {
+			msg += fmt.Sprintf(
+				"%s is only selected when group %q is normalized to %q\n",
+				r.CurId(), p.Target.Group, resid.NormalizeGroup(p.Target.Group))
+		}
+	}
+	return nil, fmt.Errorf("%s", msg)
+}
+
+// namelessSmPatchFromBytes loads a strategic merge patch
+// that lacks metadata.name, giving it a placeholder name.
+// Only the patch's kind and apiVersion are checked here;
+// its labels are patch content, never a selector.
+// The name is set on the parsed node, so that the rest of
+// the patch, e.g. its comments and scalar styles, is kept.
+func namelessSmPatchFromBytes(
+	h *resmap.PluginHelpers, in []byte) (*resource.Resource, error) {
+	node, err := kyaml.Parse(string(in))
+	if err != nil {
+		return nil, err
+	}
+	if node.IsNilOrEmpty() {
+		return nil, fmt.Errorf("empty patch")
+	}
+	name, err := node.Pipe(kyaml.Lookup(kyaml.MetadataField, kyaml.NameField))
+	if err != nil {
+		return nil, err
+	}
+	if name != nil && name.YNode().Value != "" {
+		return nil, fmt.Errorf("patch already has name %q", name.YNode().Value)
+	}
+	if err = node.PipeE(kyaml.SetK8sName("patch-without-name")); err != nil {
+		return nil, err
+	}
+	out, err := node.String()
+	if err != nil {
+		return nil, err
+	}
+	return h.ResmapFactory().RF().FromBytes([]byte(out))
+}
+
 // jsonPatchFromBytes loads a Json 6902 patch from
 // a bytes input
 func jsonPatchFromBytes(
@@ -4,6 +4,7 @@
 package main_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -130,6 +131,31 @@ patch: '[{"op": "add", "path": "/spec/template/spec/dnsPolicy", "value": "Cluste
 	})
 }
 
+func TestPatchTransformerBadSelector(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+patch: '[{"op": "add", "path": "/spec/template/spec/dnsPolicy", "value": "ClusterFirst"}]'
+target:
+  name: myDeploy(
+  labelSelector: "app in (a"
+`, someDeploymentResources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(), "bad name regex") ||
+			!strings.Contains(err.Error(), "bad labelSelector") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
 func TestPatchTransformerBlankPatch(t *testing.T) {
 	th := kusttest_test.MakeEnhancedHarness(t).
 		PrepBuiltin("PatchTransformer")
@@ -827,3 +853,302 @@ spec:
           protocol: TCP
 `)
 }
+
+func TestPatchTransformerSmpWithoutName(t *testing.T) {
+	const resources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  labels:
+    tier: web
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b
+  labels:
+    tier: web
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+  labels:
+    tier: db
+spec:
+  replicas: 1
+`
+	const patch = `
+patch: |-
+  apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    labels:
+      tier: db
+  spec:
+    replicas: 3
+`
+	testCases := map[string]struct {
+		selector string
+		strict   bool
+		expected string
+		errMsg   string
+	}{
+		"no match": {
+			selector: "tier=cache",
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: a
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: b
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: c
+spec:
+  replicas: 1
+`,
+		},
+		"no match strict": {
+			selector: "tier=cache",
+			strict:   true,
+			errMsg:   "strict patch target matches no resources",
+		},
+		"one match": {
+			selector: "tier=db",
+			strict:   true,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: a
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: web
+  name: b
+spec:
+  replicas: 1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: c
+spec:
+  replicas: 3
+`,
+		},
+		"many matches": {
+			selector: "tier=web",
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: a
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: b
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    tier: db
+  name: c
+spec:
+  replicas: 1
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			th := kusttest_test.MakeEnhancedHarness(t).
+				PrepBuiltin("PatchTransformer")
+			defer th.Reset()
+
+			config := fmt.Sprintf(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+target:
+  kind: Deployment
+  labelSelector: %s
+options:
+  strict: %t
+`, tc.selector, tc.strict) + patch
+			if tc.errMsg != "" {
+				th.RunTransformerAndCheckError(config, resources,
+					func(t *testing.T, err error) {
+						if err == nil {
+							t.Fatalf("expected error")
+						}
+						if !strings.Contains(err.Error(), tc.errMsg) {
+							t.Fatalf("unexpected err: %v", err)
+						}
+					})
+				return
+			}
+			th.RunTransformerAndCheckResult(config, resources, tc.expected)
+		})
+	}
+}
+
+func TestPatchTransformerSmpWithoutNameNeedsTarget(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+patch: |-
+  apiVersion: apps/v1
+  kind: Deployment
+  spec:
+    replicas: 3
+`, someDeploymentResources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"unable to parse SM or JSON patch from ") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestPatchTransformerCoreGroupTarget(t *testing.T) {
+	const resources = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+`
+	const config = `
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+target:
+  group: core
+  kind: Service
+options:
+  exactGroup: %t
+  strict: %t
+patch: |-
+  apiVersion: v1
+  kind: Service
+  metadata:
+    annotations:
+      patched: by-core-group
+`
+	const patched = `
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    patched: by-core-group
+  name: svc
+`
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(
+		fmt.Sprintf(config, false, false), resources, patched)
+	// The strict option alone keeps the group normalized.
+	th.RunTransformerAndCheckResult(
+		fmt.Sprintf(config, false, true), resources, patched)
+	th.RunTransformerAndCheckResult(
+		fmt.Sprintf(config, true, false), resources, resources)
+	th.RunTransformerAndCheckError(fmt.Sprintf(config, true, true), resources,
+		func(t *testing.T, err error) {
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if !strings.Contains(err.Error(),
+				`~G_v1_Service|~X|svc is only selected when group "core" is normalized to ""`) {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		})
+}
+
+// The placeholder name is set on the patch as parsed, so
+// scalars are read as in any other patch, e.g. yes stays a
+// string rather than becoming YAML 1.1's true.
+func TestPatchTransformerSmpWithoutNameKeepsScalars(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PatchTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PatchTransformer
+metadata:
+  name: notImportantHere
+target:
+  kind: ConfigMap
+patch: |-
+  apiVersion: v1
+  kind: ConfigMap
+  # Set the flags of every ConfigMap.
+  data:
+    enabled: yes
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  enabled: "no"
+`, `
+apiVersion: v1
+data:
+  enabled: "yes"
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+}
@@ -161,3 +161,41 @@ metadata:
   name: cm
 `)
 }
+
+// A new name may be the old name of another resource,
+// as long as that resource is renamed too.
+func TestPrefixSuffixTransformerNameTakenByAnother(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("PrefixSuffixTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: PrefixSuffixTransformer
+metadata:
+  name: notImportantHere
+prefix: p-
+fieldSpecs:
+  - path: metadata/name
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-cm
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-cm
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: p-p-cm
+`)
+}
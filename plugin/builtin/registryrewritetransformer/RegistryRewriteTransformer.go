@@ -0,0 +1,108 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/filters/registryrewrite"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Move images from the registries they name to mirrors,
+// e.g. docker.io/library/nginx:1.19 to
+// registry.corp/dockerhub/library/nginx:1.19, keeping
+// the tag or digest of each image.
+type plugin struct {
+	// Mirrors move the images under a registry, or a path
+	// in one, to a mirror; the longest matching from wins.
+	Mirrors []registryrewrite.Mirror `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+
+	// DefaultMirror, if set, is prepended to images that
+	// name no registry, e.g. nginx or bitnami/redis.
+	DefaultMirror string `json:"defaultMirror,omitempty" yaml:"defaultMirror,omitempty"`
+
+	// AllowedRegistries name registries, maybe with a path,
+	// whose images are left as they are.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty" yaml:"allowedRegistries,omitempty"`
+
+	// Audit leaves every image as it is, and reports each
+	// rewrite that would have been made as a plugin result.
+	Audit bool `json:"audit,omitempty" yaml:"audit,omitempty"`
+
+	// FieldSpecs locate the images; defaults to the image
+	// fields of the default transformer configuration.
+	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	rmF *resmap.Factory
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.Mirrors = nil
+	p.DefaultMirror = ""
+	p.AllowedRegistries = nil
+	p.Audit = false
+	p.FieldSpecs = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	for i, m := range p.Mirrors {
+		if m.From == "" || m.To == "" {
+			return fmt.Errorf("mirrors[%d] needs both a from and a to", i)
+		}
+	}
+	if len(p.FieldSpecs) == 0 {
+		if p.FieldSpecs, err = registryrewrite.DefaultFsSlice(); err != nil {
+			return err
+		}
+	}
+	p.rmF = h.ResmapFactory()
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		err := r.ApplyFilter(registryrewrite.Filter{
+			Rewriter: registryrewrite.Rewriter{
+				Mirrors:           p.Mirrors,
+				DefaultMirror:     p.DefaultMirror,
+				AllowedRegistries: p.AllowedRegistries,
+			},
+			FsSlice:   p.FieldSpecs,
+			Audit:     p.Audit,
+			OnRewrite: p.reportFn(r),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportFn returns the function that, in audit mode,
+// records the rewrites of the images of the resource.
+func (p *plugin) reportFn(
+	r *resource.Resource) func(*kyaml.RNode, string, string) {
+	if !p.Audit {
+		return nil
+	}
+	return func(_ *kyaml.RNode, from, to string) {
+		p.rmF.AddPluginResult(resmap.PluginResult{
+			Plugin:         "RegistryRewriteTransformer",
+			Resource:       r.CurId(),
+			Message:        fmt.Sprintf("would rewrite image '%s' to '%s'", from, to),
+			CurrentValue:   from,
+			SuggestedValue: to,
+		})
+	}
+}
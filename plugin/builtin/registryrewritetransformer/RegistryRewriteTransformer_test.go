@@ -0,0 +1,146 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const pods = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: app
+    image: docker.io/library/nginx:1.19
+  - name: cache
+    image: redis@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+  - name: proxy
+    image: registry.corp/envoy:1.17
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backup
+spec:
+  template:
+    spec:
+      containers:
+      - name: backup
+        image: quay.io/org/backup:2
+`
+
+func TestRegistryRewriteTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RegistryRewriteTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: RegistryRewriteTransformer
+metadata:
+  name: notImportantHere
+mirrors:
+- from: docker.io
+  to: registry.corp/dockerhub
+- from: quay.io/org
+  to: registry.corp/quay
+defaultMirror: registry.corp/dockerhub/library
+allowedRegistries:
+- registry.corp
+`, pods, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - image: registry.corp/dockerhub/library/nginx:1.19
+    name: app
+  - image: registry.corp/dockerhub/library/redis@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+    name: cache
+  - image: registry.corp/envoy:1.17
+    name: proxy
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backup
+spec:
+  template:
+    spec:
+      containers:
+      - image: registry.corp/quay/backup:2
+        name: backup
+`)
+}
+
+func TestRegistryRewriteTransformerAudit(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RegistryRewriteTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: RegistryRewriteTransformer
+metadata:
+  name: notImportantHere
+mirrors:
+- from: docker.io
+  to: registry.corp/dockerhub
+audit: true
+`, pods, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - image: docker.io/library/nginx:1.19
+    name: app
+  - image: redis@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+    name: cache
+  - image: registry.corp/envoy:1.17
+    name: proxy
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backup
+spec:
+  template:
+    spec:
+      containers:
+      - image: quay.io/org/backup:2
+        name: backup
+`)
+}
+
+func TestRegistryRewriteTransformerBadMirror(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RegistryRewriteTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: RegistryRewriteTransformer
+metadata:
+  name: notImportantHere
+mirrors:
+- from: docker.io
+`, pods, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"mirrors[0] needs both a from and a to") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Remove the resources the selectors select, e.g. all
+// NetworkPolicies, or everything labelled environment=dev.
+type plugin struct {
+	Selectors []types.Selector `json:"selectors,omitempty" yaml:"selectors,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Selectors = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	// Report a bad selector now, rather than on the
+	// first transform.
+	for _, s := range p.Selectors {
+		if _, err = resmap.NewSelectorMatcher(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, s := range p.Selectors {
+		if _, err := m.RemoveBySelector(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
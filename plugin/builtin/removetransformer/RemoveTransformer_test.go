@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const resources = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    environment: dev
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    environment: prod
+`
+
+func TestRemoveTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RemoveTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: RemoveTransformer
+metadata:
+  name: notImportantHere
+selectors:
+- kind: NetworkPolicy
+- labelSelector: environment=dev
+- kind: Secret
+`, resources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    environment: prod
+  name: web
+`)
+}
+
+func TestRemoveTransformerBadSelector(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("RemoveTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: RemoveTransformer
+metadata:
+  name: notImportantHere
+selectors:
+- labelSelector: environment in dev
+`, resources, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(), "bad labelSelector") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
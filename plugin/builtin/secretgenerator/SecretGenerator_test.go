@@ -5,29 +5,32 @@ package main_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/builtins"
 	"sigs.k8s.io/kustomize/api/konfig"
-	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	ifctest_test "sigs.k8s.io/kustomize/api/testutils/ifctest"
 )
 
 func TestSecretGenerator(t *testing.T) {
-	th := kusttest_test.MakeEnhancedHarness(t).
-		PrepBuiltin("SecretGenerator")
-	defer th.Reset()
-
-	th.WriteF("a.env", `
+	ldr := ifctest_test.NewFakeLoader("/app", map[string]string{
+		"a.env": `
 ROUTER_PASSWORD=admin
-`)
-	th.WriteF("b.env", `
+`,
+		"b.env": `
 DB_PASSWORD=iloveyou
-`)
-	th.WriteF("longsecret.txt", `
+`,
+		"longsecret.txt": `
 Lorem ipsum dolor sit amet,
 consectetur adipiscing elit.
-`)
-
-	rm := th.LoadAndRunGenerator(`
+`,
+	})
+	p := builtins.NewSecretGeneratorPlugin()
+	err := p.Config(
+		ifctest_test.NewPluginHelpers(
+			ldr, ifctest_test.NewFakeValidator(), nil), []byte(`
 apiVersion: builtin
 kind: SecretGenerator
 metadata:
@@ -42,7 +45,14 @@ files:
 literals:
 - FRUIT=apple
 - VEGETABLE=carrot
-`)
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rm, err := p.Generate()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
 
 	obscure := `obscure: CkxvcmVtIGlwc3VtIGRvbG9yIHNpdCBhbWV0LApjb25zZWN0ZXR1ciBhZGlwaXNjaW5nIGVsaXQuCg==`
 	if konfig.FlagEnableKyamlDefaultValue {
@@ -52,7 +62,9 @@ literals:
     VsaXQuCg==`
 	}
 
-	th.AssertActualEqualsExpected(rm, fmt.Sprintf(`
+	yml, err := rm.AsYaml()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimLeft(fmt.Sprintf(`
 apiVersion: v1
 data:
   DB_PASSWORD: aWxvdmV5b3U=
@@ -65,5 +77,27 @@ metadata:
   name: mySecret
   namespace: whatever
 type: Opaque
-`, obscure))
+`, obscure), "\n"), string(yml))
+}
+
+func TestSecretGeneratorMissingFile(t *testing.T) {
+	p := builtins.NewSecretGeneratorPlugin()
+	err := p.Config(
+		ifctest_test.NewPluginHelpers(
+			ifctest_test.NewFakeLoader("/app", nil),
+			ifctest_test.NewFakeValidator(), nil), []byte(`
+apiVersion: builtin
+kind: SecretGenerator
+metadata:
+  name: mySecret
+files:
+- obscure=longsecret.txt
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = p.Generate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "/app/longsecret.txt")
+	}
 }
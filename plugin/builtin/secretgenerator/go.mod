@@ -3,6 +3,7 @@ module sigs.k8s.io/kustomize/plugin/builtin/secretgenerator
 go 1.15
 
 require (
+	github.com/stretchr/testify v1.4.0
 	sigs.k8s.io/kustomize/api v0.7.1
 	sigs.k8s.io/yaml v1.2.0
 )
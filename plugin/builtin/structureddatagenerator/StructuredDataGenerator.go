@@ -0,0 +1,330 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/kv"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Generate a ConfigMap, or a Secret, from structured
+// config files, e.g. JSON or TOML, either each embedded
+// whole under one key, or flattened into a key per value,
+// the keys of the nested values joined with Separator.
+// E.g. flattening
+//
+//	[server]
+//	port = 8080
+//
+// with KeyPrefix app_ gives the key app_server.port.
+//
+// Content that isn't UTF-8 goes in the binaryData of a
+// ConfigMap.  The name gets the usual hash suffix, unless
+// the options disable it.
+type plugin struct {
+	h                *resmap.PluginHelpers
+	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Secret is true to generate a Secret, not a ConfigMap.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Type is that of the Secret, e.g. Opaque.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Behavior is that of a generator entry, e.g. merge.
+	Behavior string `json:"behavior,omitempty" yaml:"behavior,omitempty"`
+
+	// Options are those of a generator entry.
+	Options *types.GeneratorOptions `json:"options,omitempty" yaml:"options,omitempty"`
+
+	// Separator joins the keys of nested values when
+	// flattening.  It defaults to a dot.
+	Separator string `json:"separator,omitempty" yaml:"separator,omitempty"`
+
+	// Sources are the files to read.
+	Sources []StructuredSource `json:"sources,omitempty" yaml:"sources,omitempty"`
+}
+
+// StructuredSource is a config file to read.
+type StructuredSource struct {
+	// File is the path of the file.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Format is json, toml or env.  It defaults to the
+	// extension of the file.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Flatten is true to give a key per value of the file,
+	// rather than one key holding the whole file.  Empty
+	// maps and lists give no keys.
+	Flatten bool `json:"flatten,omitempty" yaml:"flatten,omitempty"`
+
+	// KeyPrefix is put before every key of the file.
+	KeyPrefix string `json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty"`
+
+	// Key is the key of the whole file, if not flattened.
+	// It defaults to the base name of the file.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+const defaultSeparator = "."
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.Secret = false
+	p.Type = ""
+	p.Behavior = ""
+	p.Options = nil
+	p.Separator = ""
+	p.Sources = nil
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	if p.Separator == "" {
+		p.Separator = defaultSeparator
+	}
+	if len(p.Sources) == 0 {
+		return fmt.Errorf("must specify at least one source")
+	}
+	for i, s := range p.Sources {
+		if s.File == "" {
+			return fmt.Errorf("sources[%d] must specify a file", i)
+		}
+		if _, err = sourceFormat(s); err != nil {
+			return err
+		}
+	}
+	p.h = h
+	return nil
+}
+
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	pl := &pairLoader{validator: p.h.Validator()}
+	origins := make(map[string]string)
+	for _, s := range p.Sources {
+		pairs, err := p.sourcePairs(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range pairs {
+			if other, found := origins[sp.Key]; found {
+				return nil, fmt.Errorf(
+					"%s and %s both give the key %q", other, sp.origin, sp.Key)
+			}
+			origins[sp.Key] = sp.origin
+			pl.pairs = append(pl.pairs, sp.Pair)
+		}
+	}
+	args := types.GeneratorArgs{
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Behavior:  p.Behavior,
+		Options:   p.Options,
+	}
+	if p.Secret {
+		return p.h.ResmapFactory().FromSecretArgs(
+			pl, types.SecretArgs{GeneratorArgs: args, Type: p.Type})
+	}
+	return p.h.ResmapFactory().FromConfigMapArgs(
+		pl, types.ConfigMapArgs{GeneratorArgs: args})
+}
+
+// sourcePair is a pair, with a description of where
+// in its source it came from, for errors.
+type sourcePair struct {
+	types.Pair
+	origin string
+}
+
+// sourcePairs returns the pairs of the source.
+func (p *plugin) sourcePairs(s StructuredSource) ([]sourcePair, error) {
+	format, _ := sourceFormat(s)
+	if !s.Flatten {
+		content, err := p.h.Loader().Load(s.File)
+		if err != nil {
+			return nil, err
+		}
+		key := s.Key
+		if key == "" {
+			key = path.Base(filepath.ToSlash(s.File))
+		}
+		return []sourcePair{{
+			Pair: types.Pair{
+				Key:    s.KeyPrefix + key,
+				Value:  string(content),
+				Binary: !utf8.Valid(content),
+			},
+			origin: s.File,
+		}}, nil
+	}
+	var value interface{}
+	switch format {
+	case "env":
+		pairs, err := kv.NewLoader(p.h.Loader(), p.h.Validator()).Load(
+			types.KvPairSources{EnvSources: []string{s.File}})
+		if err != nil {
+			return nil, err
+		}
+		var result []sourcePair
+		for _, pair := range pairs {
+			result = append(result, sourcePair{
+				Pair: types.Pair{
+					Key:    s.KeyPrefix + pair.Key,
+					Value:  pair.Value,
+					Binary: !utf8.ValidString(pair.Value),
+				},
+				origin: fmt.Sprintf("%s in %s", pair.Key, s.File),
+			})
+		}
+		return result, nil
+	case "json":
+		content, err := p.h.Loader().Load(s.File)
+		if err != nil {
+			return nil, err
+		}
+		d := json.NewDecoder(bytes.NewReader(content))
+		d.UseNumber()
+		if err = d.Decode(&value); err != nil {
+			return nil, fmt.Errorf("reading %s as json: %v", s.File, err)
+		}
+	case "toml":
+		content, err := p.h.Loader().Load(s.File)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]interface{}
+		if _, err = toml.Decode(string(content), &m); err != nil {
+			return nil, fmt.Errorf("reading %s as toml: %v", s.File, err)
+		}
+		value = m
+	}
+	var result []sourcePair
+	err := p.flatten(value, nil, func(segments []string, v string) {
+		result = append(result, sourcePair{
+			Pair: types.Pair{
+				Key:    s.KeyPrefix + strings.Join(segments, p.Separator),
+				Value:  v,
+				Binary: !utf8.ValidString(v),
+			},
+			origin: fmt.Sprintf("%s in %s", strings.Join(segments, "/"), s.File),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("flattening %s: %v", s.File, err)
+	}
+	return result, nil
+}
+
+// flatten calls add with the path of each scalar in the
+// given value, in order of the keys of maps, and its value
+// as a string.
+func (p *plugin) flatten(
+	value interface{}, segments []string,
+	add func(segments []string, v string)) error {
+	child := func(key string, v interface{}) error {
+		return p.flatten(
+			v, append(segments[:len(segments):len(segments)], key), add)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := child(k, v[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, e := range v {
+			if err := child(strconv.Itoa(i), e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []map[string]interface{}:
+		// A toml array of tables.
+		for i, e := range v {
+			if err := child(strconv.Itoa(i), e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("the file must hold a map, not a single value")
+	}
+	switch v := value.(type) {
+	case nil:
+		add(segments, "")
+	case string:
+		add(segments, v)
+	case json.Number:
+		add(segments, v.String())
+	case bool:
+		add(segments, strconv.FormatBool(v))
+	case int64:
+		add(segments, strconv.FormatInt(v, 10))
+	case float64:
+		add(segments, strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		add(segments, v.Format(time.RFC3339Nano))
+	default:
+		return fmt.Errorf(
+			"%s has a value of unexpected type %T",
+			strings.Join(segments, "/"), value)
+	}
+	return nil
+}
+
+// sourceFormat returns the format of the source, as
+// given or as the extension of its file says.
+func sourceFormat(s StructuredSource) (string, error) {
+	format := s.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(s.File), ".")
+	}
+	switch format {
+	case "json", "toml", "env":
+		return format, nil
+	}
+	return "", fmt.Errorf(
+		"the format of %s must be json, toml or env, not %q", s.File, format)
+}
+
+// pairLoader is the KvLoader of pairs already read.
+type pairLoader struct {
+	pairs     []types.Pair
+	validator ifc.Validator
+}
+
+func (l *pairLoader) Validator() ifc.Validator {
+	return l.validator
+}
+
+func (l *pairLoader) Load(types.KvPairSources) ([]types.Pair, error) {
+	return l.pairs, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/resmap"
+	ifctest_test "sigs.k8s.io/kustomize/api/testutils/ifctest"
+)
+
+func generateStructuredData(
+	t *testing.T, config string) (resmap.ResMap, error) {
+	h := ifctest_test.NewPluginHelpers(
+		ifctest_test.NewFakeLoader("/app", map[string]string{
+			"config.toml": `
+title = "shop"
+[server]
+port = 8080
+tls = true
+[[backends]]
+host = "a"
+[[backends]]
+host = "b"
+`,
+			"config.json": `
+{"server": {"port": 8080, "ratio": 1.50}, "tags": ["x", "y"]}
+`,
+			"clash.json": `
+{"server.port": 1, "server": {"port": 2}}
+`,
+			"app.env": `
+COLOR=red
+`,
+			"logo.png": "\x89PNG\x00\xff",
+		}),
+		ifctest_test.NewFakeValidator(), nil)
+	p := builtins.NewStructuredDataGeneratorPlugin()
+	err := p.Config(h, []byte(config))
+	if err != nil {
+		return nil, err
+	}
+	return p.Generate()
+}
+
+func TestStructuredDataGenerator(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config   string
+		expected string
+		err      string
+	}{
+		"flattened toml": {
+			config: `
+sources:
+- file: config.toml
+  flatten: true
+  keyPrefix: app_
+`,
+			expected: `
+apiVersion: v1
+data:
+  app_backends.0.host: a
+  app_backends.1.host: b
+  app_server.port: "8080"
+  app_server.tls: "true"
+  app_title: shop
+kind: ConfigMap
+metadata:
+  name: app
+`,
+		},
+		"flattened json with separator": {
+			config: `
+separator: _
+sources:
+- file: config.json
+  flatten: true
+- file: app.env
+  flatten: true
+`,
+			expected: `
+apiVersion: v1
+data:
+  COLOR: red
+  server_port: "8080"
+  server_ratio: "1.50"
+  tags_0: x
+  tags_1: "y"
+kind: ConfigMap
+metadata:
+  name: app
+`,
+		},
+		"embedded": {
+			config: `
+sources:
+- file: config.json
+- file: logo.png
+  format: json
+  key: logo
+`,
+			expected: `
+apiVersion: v1
+binaryData:
+  logo: iVBORwD/
+data:
+  config.json: |2
+
+    {"server": {"port": 8080, "ratio": 1.50}, "tags": ["x", "y"]}
+kind: ConfigMap
+metadata:
+  name: app
+`,
+		},
+		"secret": {
+			config: `
+secret: true
+sources:
+- file: app.env
+  flatten: true
+`,
+			expected: `
+apiVersion: v1
+data:
+  COLOR: cmVk
+kind: Secret
+metadata:
+  name: app
+type: Opaque
+`,
+		},
+		"collision": {
+			config: `
+sources:
+- file: clash.json
+  flatten: true
+`,
+			err: `server/port in clash.json and server.port in clash.json ` +
+				`both give the key "server.port"`,
+		},
+		"collision across sources": {
+			config: `
+sources:
+- file: config.json
+- file: config.toml
+  key: config.json
+`,
+			err: `config.json and config.toml both give the key "config.json"`,
+		},
+		"unknown format": {
+			config: `
+sources:
+- file: config.ini
+`,
+			err: `the format of config.ini must be json, toml or env, not "ini"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rm, err := generateStructuredData(t, `
+apiVersion: builtin
+kind: StructuredDataGenerator
+metadata:
+  name: app
+`+tc.config)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			yml, err := rm.AsYaml()
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimLeft(tc.expected, "\n"), string(yml))
+		})
+	}
+}
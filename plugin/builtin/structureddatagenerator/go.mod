@@ -0,0 +1,14 @@
+module sigs.k8s.io/kustomize/plugin/builtin/structureddatagenerator
+
+go 1.15
+
+require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/stretchr/testify v1.4.0
+	sigs.k8s.io/kustomize/api v0.7.1
+	sigs.k8s.io/yaml v1.2.0
+)
+
+replace sigs.k8s.io/kustomize/api => ../../../api
+
+replace sigs.k8s.io/kustomize/kyaml => ../../../kyaml
@@ -0,0 +1,71 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Fail the build if resources share values that must
+// be unique, e.g. the nodePort of a Service, reporting
+// every group of resources sharing values.  List it
+// under validators.
+type plugin struct {
+	// Rules are the rules to check.
+	Rules []types.UniquenessRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+
+	// DefaultRules, if true, checks the default rules,
+	// for Service nodePorts and Ingress hosts and paths,
+	// as well as the rules given.
+	DefaultRules bool `json:"defaultRules,omitempty" yaml:"defaultRules,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Rules = nil
+	p.DefaultRules = false
+	if err = yaml.Unmarshal(c, p); err != nil {
+		return err
+	}
+	for _, r := range p.Rules {
+		if err = r.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(p.Rules) == 0 && !p.DefaultRules {
+		return fmt.Errorf("no rules given, and defaultRules not set")
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	rules := p.Rules
+	if p.DefaultRules {
+		rules = append(
+			append([]types.UniquenessRule(nil),
+				resmap.DefaultUniquenessRules...), rules...)
+	}
+	violations, err := resmap.FindUniquenessViolations(m, rules)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	var problems []string
+	for _, v := range violations {
+		problems = append(problems, v.String())
+	}
+	return fmt.Errorf("values that must be unique are shared:\n  %s",
+		strings.Join(problems, "\n  "))
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const services = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    team: a
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  labels:
+    team: a
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+    nodePort: 30081
+`
+
+func TestUniquenessValidator(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("UniquenessValidator")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: UniquenessValidator
+metadata:
+  name: notImportantHere
+defaultRules: true
+`, services, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: a
+  name: web
+spec:
+  ports:
+  - nodePort: 30080
+    port: 80
+  type: NodePort
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: a
+  name: api
+spec:
+  ports:
+  - nodePort: 30081
+    port: 80
+  type: NodePort
+`)
+}
+
+func TestUniquenessValidatorSharedValues(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("UniquenessValidator")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: UniquenessValidator
+metadata:
+  name: notImportantHere
+rules:
+- name: team-label
+  kind: Service
+  paths:
+  - metadata/labels/team
+`, services, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"rule 'team-label': Service/web, Service/api in namespace "+
+				"'default' share metadata/labels/team=a") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestUniquenessValidatorBadRule(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("UniquenessValidator")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckError(`
+apiVersion: builtin
+kind: UniquenessValidator
+metadata:
+  name: notImportantHere
+rules:
+- name: team-label
+  kind: Service
+`, services, func(t *testing.T, err error) {
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(),
+			"uniqueness rule 'team-label' has no paths") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
@@ -34,5 +34,6 @@ metadata:
 		t.Error(err)
 	}
 	shouldContain(t, a, "kustomize_plugin_config_root: /theAppRoot")
+	shouldContain(t, a, `kustomize_plugin_protocol_version: "2"`)
 	shouldContain(t, a, "plugin/someteam.example.com/v1/printpluginenv")
 }
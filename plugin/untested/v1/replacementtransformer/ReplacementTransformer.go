@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -18,10 +19,20 @@ var (
 	pattern = regexp.MustCompile(`(\S+)\[(\S+)=(\S+)\]`)
 )
 
+// A fieldref starting with dataKeyPrefix names a key of
+// the data, or binaryData, of a ConfigMap, which may hold
+// dots and slashes, e.g. dataKey:nginx.conf.
+const dataKeyPrefix = "dataKey:"
+
 // Find matching image declarations and replace
 // the name, tag and/or digest.
 type plugin struct {
 	Replacements []types.Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+
+	// Compiled selectors of each replacement's source
+	// (nil for a literal value) and target.
+	sources []*resmap.Matcher
+	targets []*resmap.Matcher
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -30,6 +41,8 @@ var KustomizePlugin plugin
 func (p *plugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Replacements = []types.Replacement{}
+	p.sources = nil
+	p.targets = nil
 	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return err
@@ -51,15 +64,33 @@ func (p *plugin) Config(
 		if count > 1 {
 			return fmt.Errorf("only one of fieldref and value is allowed in one replacement")
 		}
+		var source *resmap.Matcher
+		if r.Source.ObjRef != nil {
+			source, err = resmap.NewSelectorMatcher(types.Selector{
+				Gvk:       r.Source.ObjRef.Gvk,
+				Name:      r.Source.ObjRef.Name,
+				Namespace: r.Source.ObjRef.Namespace,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		target, err := resmap.NewSelectorMatcher(*r.Target.ObjRef)
+		if err != nil {
+			return err
+		}
+		p.sources = append(p.sources, source)
+		p.targets = append(p.targets, target)
 	}
 	return nil
 }
 
 func (p *plugin) Transform(m resmap.ResMap) (err error) {
-	for _, r := range p.Replacements {
+	for i, r := range p.Replacements {
 		var replacement interface{}
 		if r.Source.ObjRef != nil {
-			replacement, err = getReplacement(m, r.Source.ObjRef, r.Source.FieldRef)
+			replacement, err = getReplacement(
+				m, p.sources[i], r.Source.ObjRef, r.Source.FieldRef)
 			if err != nil {
 				return err
 			}
@@ -68,7 +99,7 @@ func (p *plugin) Transform(m resmap.ResMap) (err error) {
 			replacement = r.Source.Value
 		}
 		fmt.Printf("The replacement is %s\n", replacement)
-		err = substitute(m, r.Target, replacement)
+		err = substitute(m, p.targets[i], r.Target, replacement)
 		if err != nil {
 			return err
 		}
@@ -76,16 +107,9 @@ func (p *plugin) Transform(m resmap.ResMap) (err error) {
 	return nil
 }
 
-func getReplacement(m resmap.ResMap, objRef *types.Target, fieldRef string) (interface{}, error) {
-	s := types.Selector{
-		Gvk:       objRef.Gvk,
-		Name:      objRef.Name,
-		Namespace: objRef.Namespace,
-	}
-	resources, err := m.Select(s)
-	if err != nil {
-		return "", err
-	}
+func getReplacement(m resmap.ResMap, matcher *resmap.Matcher,
+	objRef *types.Target, fieldRef string) (interface{}, error) {
+	resources := m.SelectMatching(matcher)
 	if len(resources) > 1 {
 		return "", fmt.Errorf("found more than one resources matching from %v", resources)
 	}
@@ -95,16 +119,23 @@ func getReplacement(m resmap.ResMap, objRef *types.Target, fieldRef string) (int
 	if fieldRef == "" {
 		fieldRef = ".metadata.name"
 	}
+	if strings.HasPrefix(fieldRef, dataKeyPrefix) {
+		return resources[0].GetDataValue(
+			strings.TrimPrefix(fieldRef, dataKeyPrefix))
+	}
 	return resources[0].GetFieldValue(fieldRef)
 }
 
-func substitute(m resmap.ResMap, to *types.ReplTarget, replacement interface{}) error {
-	resources, err := m.Select(*to.ObjRef)
-	if err != nil {
-		return err
-	}
-	for _, r := range resources {
+func substitute(m resmap.ResMap, matcher *resmap.Matcher,
+	to *types.ReplTarget, replacement interface{}) error {
+	for _, r := range m.SelectMatching(matcher) {
 		for _, p := range to.FieldRefs {
+			if strings.HasPrefix(p, dataKeyPrefix) {
+				if err := setDataValue(r, p, replacement); err != nil {
+					return err
+				}
+				continue
+			}
 			pathSlice := strings.Split(p, ".")
 			if err := updateField(r.Map(), pathSlice, replacement); err != nil {
 				return err
@@ -114,6 +145,17 @@ func substitute(m resmap.ResMap, to *types.ReplTarget, replacement interface{})
 	return nil
 }
 
+func setDataValue(
+	r *resource.Resource, fieldRef string, replacement interface{}) error {
+	key := strings.TrimPrefix(fieldRef, dataKeyPrefix)
+	switch replacement.(type) {
+	case map[string]interface{}, []interface{}:
+		return fmt.Errorf(
+			"%#v can't be the value of data key '%s'", replacement, key)
+	}
+	return r.SetDataValue(key, fmt.Sprint(replacement))
+}
+
 func getFirstPathSegment(path string) (field string, key string, value string, array bool) {
 	groups := pattern.FindStringSubmatch(path)
 	if len(groups) != 4 {
@@ -382,3 +382,58 @@ spec:
         name: nginx
 `)
 }
+
+func TestReplacementTransformerDataKey(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		BuildGoPlugin("someteam.example.com", "v1", "ReplacementTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: someteam.example.com/v1
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+replacements:
+- source:
+    objref:
+      kind: ConfigMap
+      name: source
+    fieldref: dataKey:nginx.conf
+  target:
+    objref:
+      kind: ConfigMap
+      name: target
+    fieldrefs:
+    - dataKey:settings/nginx.conf
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: source
+data:
+  nginx.conf: worker_processes 1;
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: target
+data:
+  settings/nginx.conf: worker_processes 4;
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  nginx.conf: worker_processes 1;
+kind: ConfigMap
+metadata:
+  name: source
+---
+apiVersion: v1
+data:
+  settings/nginx.conf: worker_processes 1;
+kind: ConfigMap
+metadata:
+  name: target
+`)
+}